@@ -12,7 +12,8 @@ var (
 		Short: "Authentication management commands",
 		Long: `Manage authentication for Zvuk.
 
-Use 'auth login' to log in via browser and automatically extract your authentication token.`,
+Use 'auth login' to log in via browser and automatically extract your authentication token.
+Use 'auth status' to check that the token works and when the subscription expires.`,
 	}
 
 	authLoginCmd = &cobra.Command{
@@ -28,23 +29,53 @@ The login process:
 5. Enter the 5-digit SMS code you receive
 6. Wait for authentication to complete
 
+With --phone, the phone number is filled in automatically, and the SMS code
+is asked for in the terminal, so only the anti-bot checks (if any) are left to the browser:
+zvuk-grabber auth login --phone +71488251742
+
 After successful login, the authentication token will be automatically
-extracted from your profile and saved to the configuration file.
+extracted from your profile, checked against the Zvuk API,
+and saved to the configuration file. The auth_token setting may be empty before the first login.
 
 You can then use the token to download music:
 zvuk-grabber https://zvuk.com/album/123456`,
-		PersistentPreRun: initConfig,
+		PersistentPreRun: initLoginConfig,
 		Run: func(cmd *cobra.Command, args []string) {
 			app.ExecuteAuthLoginCommand(cmd.Context(), appConfig)
 		},
 	}
+
+	authStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Check the authentication token and the subscription expiry",
+		Long: `Checks the configured authentication token against the Zvuk API and prints
+the active subscription and the number of days before it expires.
+
+The command exits with a non-zero code when the token is missing or rejected,
+so it can be used in scheduled checks:
+zvuk-grabber auth status || echo "Run 'zvuk-grabber auth login' again"`,
+		Args:             cobra.NoArgs,
+		SilenceUsage:     true,
+		PersistentPreRun: initLoginConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.ExecuteAuthStatusCommand(cmd.Context(), appConfig, cmd.OutOrStdout())
+		},
+	}
 )
 
 //nolint:gochecknoinits // Cobra requires the init function to set up commands.
 func init() {
+	authLoginCmd.Flags().String(
+		"phone",
+		"",
+		"fill in the phone number in the browser and ask for the SMS code in the terminal, for example: +71488251742.")
+
 	// Add login subcommand to auth command.
 	authCmd.AddCommand(authLoginCmd)
 
+	// Add status subcommand to auth command.
+	authCmd.AddCommand(authStatusCmd)
+
 	// Add auth command to root command.
 	rootCmd.AddCommand(authCmd)
 }
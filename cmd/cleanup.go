@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup [dir]",
+	Short: "Delete temporary files left behind by interrupted runs",
+	Long: `Scans the folder (output_path by default) and its subfolders for the temporary files
+interrupted runs leave behind:
+- partial downloads of tracks, covers, and descriptions (*.part-<number>),
+- partial normalized and portable copies (*.part),
+- covers and descriptions with a UUID in their names (cover_<uuid>.jpg, description_<uuid>.txt).
+
+The files found are listed and deleted once you confirm; pass --yes to delete them without asking.
+The output path is locked meanwhile, so the files of a run in progress are never touched.
+
+Examples:
+zvuk-grabber cleanup
+zvuk-grabber cleanup --yes ~/Music/zvuk`,
+	Args:             cobra.MaximumNArgs(1),
+	SilenceUsage:     true,
+	PersistentPreRun: initLoginConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assumeYes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+
+		var dir string
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		return app.ExecuteCleanupCommand(cmd.Context(), appConfig, cmd.InOrStdin(), cmd.OutOrStdout(), dir, assumeYes)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	cleanupCmdFlags := cleanupCmd.Flags()
+
+	cleanupCmdFlags.BoolP(
+		"yes",
+		"y",
+		false,
+		"delete the files found without asking for confirmation.")
+
+	// The output path is locked while the files are deleted, like during a download.
+	addNoLockFlag(cleanupCmdFlags)
+
+	// Add cleanup command to root command.
+	rootCmd.AddCommand(cleanupCmd)
+}
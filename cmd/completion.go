@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// recentURLsCompletionLimit is the number of recently downloaded items offered by shell completion.
+const recentURLsCompletionLimit = 50
+
+// registerCompletions wires the dynamic shell completion of the download command:
+// the URLs of recently downloaded items, the valid quality values, and the download orders.
+// It must run after the flags of the root command are defined.
+func registerCompletions() {
+	rootCmd.ValidArgsFunction = completeRecentURLs
+
+	qualities := []cobra.Completion{
+		cobra.CompletionWithDesc("1", "MP3, 128 Kbps"),
+		cobra.CompletionWithDesc("2", "MP3, 320 Kbps"),
+		cobra.CompletionWithDesc("3", "FLAC"),
+	}
+
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("quality", cobra.FixedCompletions(
+		qualities, cobra.ShellCompDirectiveNoFileComp)))
+
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("min-quality", cobra.FixedCompletions(
+		append([]cobra.Completion{cobra.CompletionWithDesc("0", "no filtering")}, qualities...),
+		cobra.ShellCompDirectiveNoFileComp)))
+
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("order", cobra.FixedCompletions(
+		[]cobra.Completion{
+			cobra.CompletionWithDesc(config.DownloadOrderPosition, "order of the collection (default)"),
+			cobra.CompletionWithDesc(config.DownloadOrderAlphabetical, "ordered by title"),
+			cobra.CompletionWithDesc(config.DownloadOrderShuffle, "random order"),
+			cobra.CompletionWithDesc(config.DownloadOrderSmallestFirst, "shortest tracks first"),
+		},
+		cobra.ShellCompDirectiveNoFileComp)))
+}
+
+// completeRecentURLs offers the URLs of the items in the download history, most recent first.
+// Completion must stay silent, so a missing or broken configuration falls back to the default history file.
+func completeRecentURLs(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := loadCompletionConfig()
+	if err != nil || strings.TrimSpace(cfg.HistoryPath) == "" {
+		cfg = &config.Config{HistoryPath: config.DefaultHistoryPath}
+	}
+
+	// Warnings about damaged history lines would be taken for completions.
+	ctx := logger.ToContext(context.Background(), zap.NewNop().Sugar())
+
+	items, err := zvuk_service.RecentHistoryItems(ctx, cfg, recentURLsCompletionLimit)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]cobra.Completion, 0, len(items))
+
+	for _, item := range items {
+		if strings.HasPrefix(item.URL, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(item.URL, item.Title))
+		}
+	}
+
+	// Shells would sort the URLs alphabetically and lose the most recent first order.
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// loadCompletionConfig loads the configuration with the state paths resolved, without validating the rest of it.
+func loadCompletionConfig() (*config.Config, error) {
+	location, err := resolveConfigLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	// Completions must not wait for a passphrase typed in the terminal.
+	config.SetPassphraseSource(configKeyFileFromFlag, nil)
+
+	cfg, err := config.LoadConfigProfile(location.ConfigFilename, profileFromFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.StateDir = location.StateDir
+	config.ResolveStatePaths(cfg)
+
+	return cfg, nil
+}
+
+// isCompletionRequest reports whether cmd is the hidden command shells call to request completions.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
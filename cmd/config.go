@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Configuration management commands",
+		Long: `Manage the configuration file.
+
+Use 'config validate' to check the configuration before starting a download.
+Use 'config encrypt' and 'config decrypt' to keep the file, auth token included, encrypted with a passphrase.`,
+	}
+
+	configValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and report every problem found",
+		Long: `Checks the configuration file together with the command-line flags:
+the settings, the auth token, the syntax and variables of every template,
+and whether files can be saved to the output path.
+
+All problems are reported at once, and the command exits with a non-zero code if any is found.
+Nothing is downloaded or created.
+
+Example:
+zvuk-grabber config validate --config ~/music/.zvuk-grabber.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		// The configuration is validated by the command itself, so every problem is reported.
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			loadAppConfig(cmd, applyFlagsToConfig)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return app.ExecuteConfigValidateCommand(appConfig, cmd.OutOrStdout())
+		},
+	}
+
+	configEncryptCmd = &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the configuration file with a passphrase",
+		Long: `Encrypts the configuration file in place with AES-256-GCM,
+using a key derived from a passphrase, so the auth token is not stored in clear text.
+
+The passphrase is asked for twice in the terminal, unless it is given
+with --config-key-file, ZVUK_CONFIG_KEY_FILE, or ZVUK_CONFIG_PASSPHRASE.
+Every later run reads it from the same places, asking for it in the terminal last,
+and 'auth login' saves the new token into the encrypted file.
+
+Example:
+zvuk-grabber config encrypt --config ~/music/.zvuk-grabber.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		// The file is read by the command itself, encrypted or not.
+		PersistentPreRun: func(*cobra.Command, []string) {},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			location, err := resolveConfigLocation()
+			if err != nil {
+				return err
+			}
+
+			setConfigPassphraseSource()
+
+			return app.ExecuteConfigEncryptCommand(location.ConfigFilename, cmd.OutOrStdout())
+		},
+	}
+
+	configDecryptCmd = &cobra.Command{
+		Use:   "decrypt",
+		Short: "Write the encrypted configuration file back in clear text",
+		Long: `Decrypts the configuration file encrypted by 'config encrypt' in place.
+
+Example:
+zvuk-grabber config decrypt --config ~/music/.zvuk-grabber.yaml`,
+		Args:             cobra.NoArgs,
+		SilenceUsage:     true,
+		PersistentPreRun: func(*cobra.Command, []string) {},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			location, err := resolveConfigLocation()
+			if err != nil {
+				return err
+			}
+
+			setConfigPassphraseSource()
+
+			return app.ExecuteConfigDecryptCommand(location.ConfigFilename, cmd.OutOrStdout())
+		},
+	}
+)
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Add validate subcommand to config command.
+	configCmd.AddCommand(configValidateCmd)
+
+	// Add encrypt and decrypt subcommands to config command.
+	configCmd.AddCommand(configEncryptCmd)
+	configCmd.AddCommand(configDecryptCmd)
+
+	// Add config command to root command.
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration, token, disk, tools, and network before downloading",
+	Long: `Diagnoses the environment a download depends on:
+the configuration file, the auth token, whether files can be saved to the output path,
+the free disk space, ffmpeg and rclone when the settings that use them are on,
+the browser used by 'auth login', and the connection to Zvuk.
+
+The result of every check is printed with a hint for the failed ones,
+and the command exits with a non-zero code if any check fails.
+
+Example:
+zvuk-grabber doctor --config ~/music/.zvuk-grabber.yaml`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	// The configuration is loaded by the command itself, so a broken file is reported instead of stopping it.
+	PersistentPreRun: func(*cobra.Command, []string) {},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		location, err := resolveConfigLocation()
+		if err != nil {
+			return err
+		}
+
+		setConfigPassphraseSource()
+
+		return app.ExecuteDoctorCommand(cmd.Context(), location, profileFromFlag, cmd.OutOrStdout())
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [urls]",
+	Short: "Write M3U playlists of downloaded albums and playlists from the download history",
+	Long: `Writes an M3U playlist for every album and playlist recorded in history_path,
+listing the saved tracks in their order in the collection and pointing at the files they were saved to.
+Tracks whose files no longer exist are left out.
+
+Without URLs every album and playlist of the history is exported; with URLs only those.
+By default every playlist is written into the folder holding its tracks, named after the folder.
+--dir writes all of them into one folder instead, named after the collections.
+--format chooses the extension: m3u8 (default) or m3u; the content is UTF-8 either way.
+
+Examples:
+zvuk-grabber export
+zvuk-grabber export https://zvuk.com/playlist/9876543
+zvuk-grabber export --dir ~/Music/Playlists --format m3u`,
+	Args:             cobra.ArbitraryArgs,
+	SilenceUsage:     true,
+	PersistentPreRun: initLoginConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		return app.ExecuteExportCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), args, dir, format)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	exportCmd.Flags().String("dir", "", "folder to write all the playlists into, instead of the track folders.")
+	exportCmd.Flags().String("format", "m3u8", "playlist file format: m3u8 or m3u.")
+
+	// Add export command to root command.
+	rootCmd.AddCommand(exportCmd)
+}
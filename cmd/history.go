@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the tracks recorded in the download history",
+	Long: `Shows the tracks saved by earlier runs, newest first, with their quality, size, and path.
+Every saved track is recorded in history_path; with skip_downloaded_tracks enabled,
+the tracks found there are not downloaded again while their files exist.
+
+--since accepts a number of days or weeks (7d, 2w), a duration (12h), or a date (2026-01-31).
+--artist keeps the tracks with an artist whose name contains the text, ignoring case.
+
+Examples:
+zvuk-grabber history --since 7d
+zvuk-grabber history --artist "Rammstein"
+zvuk-grabber history --since 2026-01-01 --artist "Земфира"`,
+	Args:             cobra.NoArgs,
+	SilenceUsage:     true,
+	PersistentPreRun: initLoginConfig,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		since, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		artist, err := cmd.Flags().GetString("artist")
+		if err != nil {
+			return err
+		}
+
+		return app.ExecuteHistoryCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), since, artist)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	historyCmd.Flags().String("since", "", "show only the tracks saved within the period or since the date.")
+	historyCmd.Flags().String("artist", "", "show only the tracks of artists whose names contain the text.")
+
+	// Add history command to root command.
+	rootCmd.AddCommand(historyCmd)
+}
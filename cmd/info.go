@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info {urls}",
+	Short: "Show metadata of tracks, albums, playlists, audiobooks, or podcasts without downloading",
+	Long: `Resolves the URLs and prints their metadata: the tracklist with durations
+and available qualities, the label, and the release date.
+
+Nothing is downloaded, so it is a quick way to check what you would get.
+URLs are accepted in the same forms as the download command, including text files with URLs.
+
+Examples:
+zvuk-grabber info https://zvuk.com/release/38858441
+zvuk-grabber info album:38858441 track:125474570`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return app.ExecuteInfoCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), args)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Add info command to root command.
+	rootCmd.AddCommand(infoCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List artist releases or playlist, audiobook, and podcast contents",
+	Long: `Lists what an artist, playlist, audiobook, or podcast contains without downloading anything,
+so you can choose a subset before downloading it with --ids.
+
+Examples:
+zvuk-grabber list artist https://zvuk.com/artist/211963
+zvuk-grabber list playlist playlist:8045542
+zvuk-grabber --ids album:38858441,album:11302771`,
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Add a subcommand for every listable item type.
+	listCmd.AddCommand(
+		newListSubcommand("artist", "List the releases of artists with their year and type",
+			zvuk_service.DownloadCategoryArtist),
+		newListSubcommand("playlist", "List the tracks of playlists",
+			zvuk_service.DownloadCategoryPlaylist),
+		newListSubcommand("audiobook", "List the chapters of audiobooks",
+			zvuk_service.DownloadCategoryAudiobook),
+		newListSubcommand("podcast", "List the episodes of podcasts",
+			zvuk_service.DownloadCategoryPodcast),
+	)
+
+	// Add list command to root command.
+	rootCmd.AddCommand(listCmd)
+}
+
+// newListSubcommand creates the list subcommand for items of the category.
+func newListSubcommand(name, short string, category zvuk_service.DownloadCategory) *cobra.Command {
+	return &cobra.Command{
+		Use:   name + " {urls or ids}",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.ExecuteListCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), category, args)
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [dir]",
+	Short: "Move downloaded files to the paths of the current templates",
+	Long: `Reorganizes a library after album_folder_template, track_filename_template,
+or playlist_filename_template is changed.
+
+Walks the folder (output_path by default), fetches the metadata of every FLAC and MP3 file
+by its TRACK_ID tag, and computes its path with the current templates, as a download would.
+Lyrics move with their tracks, and covers and other files move with their folder
+when all of its tracks move to the same folder. Folders left empty are removed,
+and the download history is updated with the new paths.
+
+The moves are listed and applied once you confirm; pass --yes to apply them without asking,
+or --dry-run to only list them. If any move fails, the files moved before it are moved back.
+Files whose new path is taken by another file are kept in place.
+
+Examples:
+zvuk-grabber migrate --dry-run
+zvuk-grabber migrate --yes ~/Music/zvuk`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assumeYes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+
+		var dir string
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		return app.ExecuteMigrateCommand(cmd.Context(), appConfig, cmd.InOrStdin(), cmd.OutOrStdout(), dir, assumeYes)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	migrateCmdFlags := migrateCmd.Flags()
+
+	migrateCmdFlags.BoolP(
+		"yes",
+		"y",
+		false,
+		"move the files without asking for confirmation.")
+
+	migrateCmdFlags.BoolP(
+		"dry-run",
+		"n",
+		false,
+		"list the moves without moving anything.")
+
+	// The output path is locked while the files are moved, like during a download.
+	addNoLockFlag(migrateCmdFlags)
+
+	// Add migrate command to root command.
+	rootCmd.AddCommand(migrateCmd)
+}
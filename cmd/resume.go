@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Re-download the items that failed in the last run",
+	Long: `Downloads again exactly the items that failed in the last run with errors.
+
+When a run ends with errors, the failed albums, playlists, artists, and tracks
+are saved to resume_state_path together with the phase and the error.
+Failed tracks of playlists, audiobooks, and podcasts are downloaded through their collection,
+so they keep their folder and number; the tracks saved before are skipped.
+
+The state is replaced by the failures of the resume run, or removed once nothing fails.
+
+Example:
+zvuk-grabber resume`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return app.ExecuteResumeCommand(cmd.Context(), appConfig)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Resumed items are written to the output path like regular downloads.
+	addNoLockFlag(resumeCmd.Flags())
+
+	// Add resume command to root command.
+	rootCmd.AddCommand(resumeCmd)
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,17 +15,36 @@ import (
 
 	"github.com/oshokin/zvuk-grabber/internal/app"
 	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 	"github.com/oshokin/zvuk-grabber/internal/version"
 )
 
+// ErrFormatWithoutDryRun indicates that --format is given without --dry-run, the only mode it applies to.
+var ErrFormatWithoutDryRun = errors.New("--format requires --dry-run")
+
 var (
 	// configFilenameFromFlag stores the config filename provided via command-line flag.
 	//
 	//nolint:gochecknoglobals // It is required for configuration initialization before the application starts.
 	configFilenameFromFlag string
 
+	// configDirFromFlag stores the configuration directory provided via command-line flag.
+	//
+	//nolint:gochecknoglobals // It is required for configuration initialization before the application starts.
+	configDirFromFlag string
+
+	// configKeyFileFromFlag stores the file holding the passphrase of an encrypted configuration file.
+	//
+	//nolint:gochecknoglobals // It is required for configuration initialization before the application starts.
+	configKeyFileFromFlag string
+
+	// profileFromFlag stores the name of the configuration profile provided via command-line flag.
+	//
+	//nolint:gochecknoglobals // It is required for configuration initialization before the application starts.
+	profileFromFlag string
+
 	// appConfig stores the application configuration loaded from file and flags.
 	//
 	//nolint:gochecknoglobals,lll // It is initialized once during the application's startup and shared across the command execution logic.
@@ -33,7 +54,7 @@ var (
 	//
 	//nolint:gochecknoglobals,lll // Cobra command requires a global definition for proper command-line parsing and execution.
 	rootCmd = &cobra.Command{
-		Use:   "zvuk-grabber [flags] {urls}",
+		Use:   "zvuk-grabber [flags] {urls or ids}",
 		Short: "Download tracks, albums, playlists, or an entire artist's catalog.",
 		Long: `Zvuk Grabber is a CLI tool for downloading audio content from specified URLs.
 It supports downloading:
@@ -42,22 +63,44 @@ It supports downloading:
 - Playlists
 - Complete catalogs of an artist
 
+Besides links, items can be given as identifiers: track:123, album:456, playlist:789,
+artist:101, abook:202, podcast:303, or a plain number for a track.
+Long lists can be read with --input-file from a file or, with '-', from standard input.
+
 The application provides flexible naming templates, quality selection, and download speed limits.`,
-		Args:             cobra.MinimumNArgs(1),
+		Args:             validateRootArgs,
 		PersistentPreRun: initConfig,
-		Run: func(cmd *cobra.Command, urls []string) {
+		// Errors are printed by Execute, which also picks the exit code.
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, urls []string) error {
+			// The arguments are valid, so an error from here on is not about the usage.
+			cmd.SilenceUsage = true
+
 			// If ZVUK_GRABBER_DUMP_CONFIG is set, dump config as JSON and exit (for E2E tests).
 			if os.Getenv("ZVUK_GRABBER_DUMP_CONFIG") == "1" {
 				dumpConfig(appConfig)
-				return
+				return nil
 			}
 
-			app.ExecuteRootCommand(cmd.Context(), appConfig, urls)
+			// Identifiers from --ids are handled by the URL processor together with links.
+			ids, err := cmd.Flags().GetStringSlice("ids")
+			if err != nil {
+				logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+			}
+
+			// Lists from --input-file are read before the download starts.
+			inputFiles, err := cmd.Flags().GetStringSlice("input-file")
+			if err != nil {
+				logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+			}
+
+			return app.ExecuteRootCommand(cmd.Context(), appConfig, append(urls, ids...), inputFiles)
 		},
 	}
 )
 
-// Execute executes the root command.
+// Execute executes the root command and exits with the code matching its outcome,
+// see the constants.ExitCode values.
 func Execute() {
 	signals := []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM}
 	ctx, stop := signal.NotifyContext(context.Background(), signals...)
@@ -71,6 +114,8 @@ func Execute() {
 	// We need to wait for the goroutine to finish so defers can run!
 	done := make(chan struct{})
 
+	exitCode := constants.ExitCodeOK
+
 	go func() {
 		defer stop()
 
@@ -78,7 +123,11 @@ func Execute() {
 		defer close(done)
 
 		err := rootCmd.ExecuteContext(ctx)
-		cobra.CheckErr(err)
+
+		// The signal context is checked before the deferred stop cancels it.
+		exitCode = app.ExitCode(ctx, err)
+
+		printCommandError(os.Stderr, err)
 	}()
 
 	// Wait for CTRL+C or signal.
@@ -86,6 +135,12 @@ func Execute() {
 
 	// Wait for goroutine to finish (including ALL defers!).
 	<-done
+
+	if exitCode != constants.ExitCodeOK {
+		_ = logger.Logger().Sync() //nolint:errcheck // The application exits anyway.
+
+		os.Exit(exitCode) //nolint:gocritic // The logger is synced above, the deferred stop is not needed on exit.
+	}
 }
 
 //nolint:gochecknoinits // Cobra requires the init function to set up flags before the command is executed.
@@ -101,6 +156,33 @@ func init() {
 		fmt.Sprintf("path to the configuration file (default is '%s')",
 			config.DefaultConfigFilename))
 
+	rootCmd.PersistentFlags().StringVar(
+		&configDirFromFlag,
+		"config-dir",
+		"",
+		fmt.Sprintf("directory holding '%s' and the state files, instead of the XDG directories",
+			config.DirConfigFilename))
+
+	rootCmd.PersistentFlags().StringVar(
+		&configKeyFileFromFlag,
+		"config-key-file",
+		"",
+		fmt.Sprintf("file holding the passphrase of an encrypted configuration file (or set %s or %s)",
+			config.ConfigKeyFileEnvVar, config.ConfigPassphraseEnvVar))
+
+	rootCmd.PersistentFlags().StringVar(
+		&profileFromFlag,
+		"profile",
+		"",
+		"name of the profile from the 'profiles' section of the configuration file to apply over the base settings")
+
+	// For demos and tests only: the flag is left out of the help.
+	rootCmd.PersistentFlags().Bool(
+		"mock-server",
+		false,
+		"serve every request from the built-in mock server with a synthetic catalog instead of Zvuk.")
+	cobra.CheckErr(rootCmd.PersistentFlags().MarkHidden("mock-server"))
+
 	rootCmdFlags := rootCmd.Flags()
 
 	rootCmdFlags.IntP(
@@ -139,28 +221,266 @@ func init() {
 		"n",
 		false,
 		"preview what would be downloaded without actually downloading files.")
+
+	rootCmdFlags.Int64(
+		"per-artist-limit",
+		0,
+		"download at most this many releases of each artist, newest first (0 = all releases).")
+
+	rootCmdFlags.String(
+		"max-duration",
+		"",
+		"stop starting new tracks after this much time, for example: 2h, 90m (in-flight tracks are finished).")
+
+	rootCmdFlags.String(
+		"download-window",
+		"",
+		"local hours when audio files may be downloaded, for example: 02:00-07:00 (the queue waits outside them).")
+
+	rootCmdFlags.Bool(
+		"skip-downloaded",
+		false,
+		"skip the tracks saved by earlier runs according to the download history, while their files exist.")
+
+	rootCmdFlags.StringArray(
+		"exclude-regex",
+		nil,
+		"skip the tracks whose title or artist matches this regular expression, added to exclude_patterns "+
+			"(repeatable).")
+
+	rootCmdFlags.String(
+		"order",
+		"",
+		"order in which the tracks of collections are downloaded: position, alphabetical, shuffle, smallest-first.")
+
+	rootCmdFlags.String(
+		"progress",
+		"",
+		"how the download progress is shown: bar, or plain for one line per track event.")
+
+	rootCmdFlags.String(
+		"tracks",
+		"",
+		"positions of the album or playlist tracks to download, for example: 1-3,7,12- (the default is all).")
+
+	rootCmdFlags.Bool(
+		"interactive",
+		false,
+		"choose the tracks of every album and playlist in a checkbox list before they are downloaded.")
+
+	rootCmdFlags.Bool(
+		"json",
+		false,
+		"print the results as a single JSON document to standard output instead of the summary (the log goes to standard error).")
+
+	rootCmdFlags.String(
+		"format",
+		"",
+		"how --dry-run prints the tracks of every collection: table, json, or csv "+
+			"(only with --dry-run; with json and csv, the log goes to standard error).")
+
+	rootCmdFlags.Bool(
+		"fail-fast",
+		false,
+		"cancel the whole run on the first error and exit with a non-zero code.")
+
+	addNoLockFlag(rootCmdFlags)
+
+	rootCmdFlags.StringSlice(
+		"ids",
+		nil,
+		"comma-separated identifiers to download, e.g. track:123,album:456 (a plain number is a track ID).")
+
+	rootCmdFlags.StringSliceP(
+		"input-file",
+		"i",
+		nil,
+		"file with URLs or identifiers to download, one per line ('-' reads standard input).")
+
+	registerCompletions()
+}
+
+// addNoLockFlag adds the flag disabling the output path lock to the flags of a downloading command.
+func addNoLockFlag(flags *pflag.FlagSet) {
+	flags.Bool(
+		"no-lock",
+		false,
+		"do not lock the output path, allowing several runs to write to it at the same time.")
+}
+
+// validateRootArgs checks the arguments and the flags of the download command before the configuration is loaded.
+func validateRootArgs(cmd *cobra.Command, args []string) error {
+	if err := requireDryRunForFormat(cmd); err != nil {
+		return err
+	}
+
+	return requireURLsOrIDs(cmd, args)
+}
+
+// requireDryRunForFormat checks that --format, which only changes how the dry-run plan is printed, comes with --dry-run.
+func requireDryRunForFormat(cmd *cobra.Command) error {
+	format := cmd.Flags().Lookup("format")
+	if format == nil || !format.Changed {
+		return nil
+	}
+
+	if isDryRun, err := cmd.Flags().GetBool("dry-run"); err != nil || !isDryRun {
+		return ErrFormatWithoutDryRun
+	}
+
+	return nil
+}
+
+// requireURLsOrIDs checks that there is something to download: links as arguments or identifiers in --ids.
+func requireURLsOrIDs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return nil
+	}
+
+	for _, name := range []string{"ids", "input-file"} {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			return nil
+		}
+	}
+
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// printCommandError prints the error a command returned. Cobra does not print it (SilenceErrors),
+// since the error is printed outside the logger and has to be redacted here.
+func printCommandError(w io.Writer, err error) {
+	if err == nil || err.Error() == "" {
+		return
+	}
+
+	fmt.Fprintln(w, "Error:", logger.Redact(err.Error())) //nolint:errcheck // The application exits anyway.
 }
 
 func initConfig(cmd *cobra.Command, _ []string) {
-	var err error
+	loadAppConfig(cmd, bindFlagsToConfig)
+}
+
+// initLoginConfig loads the configuration for the auth login command, which runs before a token exists.
+func initLoginConfig(cmd *cobra.Command, _ []string) {
+	loadAppConfig(cmd, func(flags *pflag.FlagSet, cfg *config.Config) error {
+		if err := applyFlagsToConfig(flags, cfg); err != nil {
+			return err
+		}
+
+		return config.ValidateConfigWithoutAuthToken(cfg)
+	})
+}
+
+// resolveConfigLocation finds the configuration file and the state directory from the --config and --config-dir flags.
+func resolveConfigLocation() (*config.Location, error) {
+	return config.ResolveLocation(configFilenameFromFlag, configDirFromFlag)
+}
+
+// setConfigPassphraseSource tells where the passphrase of an encrypted configuration file is taken from,
+// asking for it in the terminal when it is not given otherwise.
+func setConfigPassphraseSource() {
+	config.SetPassphraseSource(configKeyFileFromFlag, app.PromptConfigPassphrase)
+}
+
+// loadAppConfig migrates and loads the configuration file, then applies and validates the flags with bindFlags.
+func loadAppConfig(cmd *cobra.Command, bindFlags func(flags *pflag.FlagSet, cfg *config.Config) error) {
+	// Shell completion parses the flags itself, and the completion functions load what they need quietly.
+	if isCompletionRequest(cmd) {
+		return
+	}
+
+	location, err := resolveConfigLocation()
+	if err != nil {
+		logger.FatalCodef(cmd.Context(), constants.ExitCodeInvalidConfig, "Failed to find configuration: %v", err)
+	}
+
+	setConfigPassphraseSource()
+
+	// Bring config files written for older versions up to the current schema.
+	migrationResult, err := config.MigrateConfig(location.ConfigFilename)
+	if err != nil {
+		logger.FatalCodef(cmd.Context(), constants.ExitCodeInvalidConfig,
+			"Failed to migrate configuration: %v", err)
+	}
+
+	for _, change := range migrationResult.Changes {
+		logger.Warnf(cmd.Context(), "Config migrated: %s", change)
+	}
 
-	appConfig, err = config.LoadConfig(configFilenameFromFlag)
+	if migrationResult.BackupPath != "" {
+		logger.Infof(cmd.Context(), "Original configuration saved to '%s'", migrationResult.BackupPath)
+	}
+
+	appConfig, err = config.LoadConfigProfile(location.ConfigFilename, profileFromFlag)
 	if err != nil {
-		logger.Fatalf(cmd.Context(), "Failed to load configuration: %v", err)
+		logger.FatalCodef(cmd.Context(), constants.ExitCodeInvalidConfig, "Failed to load configuration: %v", err)
+	}
+
+	appConfig.StateDir = location.StateDir
+	appConfig.ConfigFilename = location.ConfigFilename
+
+	if appConfig.Profile != "" {
+		logger.Infof(cmd.Context(), "Using configuration profile '%s'", appConfig.Profile)
 	}
 
 	// Bind flags to config before validation.
-	if err = bindFlagsToConfig(cmd.Flags(), appConfig); err != nil {
-		logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+	if err = bindFlags(cmd.Flags(), appConfig); err != nil {
+		logger.FatalCodef(cmd.Context(), constants.ExitCodeInvalidConfig, "Failed to parse flags: %v", err)
 	}
 
 	logger.SetLevel(appConfig.ParsedLogLevel)
+
+	if appConfig.StateDir != "" {
+		if err = os.MkdirAll(appConfig.StateDir, constants.DefaultFolderPermissions); err != nil {
+			logger.Fatalf(cmd.Context(), "Failed to create state directory: %v", err)
+		}
+	}
+
+	// Standard output is kept for the JSON document and for the dry-run plan read by scripts.
+	if appConfig.JSONOutput || (appConfig.DryRun && appConfig.DryRunFormat != config.DryRunFormatTable) {
+		logger.SetOutput(os.Stderr)
+	}
+
+	if appConfig.MockServer {
+		if err = app.StartMockServer(cmd.Context(), appConfig); err != nil {
+			logger.Fatalf(cmd.Context(), "Failed to start mock server: %v", err)
+		}
+	}
+
+	// Keep the tokens and the remote storage password out of debug logs and error reports.
+	logger.AddSecret(appConfig.AuthToken)
+	logger.AddSecret(appConfig.RemotePassword)
+	logger.AddSecret(appConfig.ServeToken)
 }
 
-//nolint:gocognit // This function handles all flag overrides, high complexity is expected.
+// bindFlagsToConfig applies the command-line flags to the configuration and validates it.
+// The token must be known before the configuration is validated, so it is read from the keyring here;
+// the commands working without a token do not touch the keyring.
 func bindFlagsToConfig(flags *pflag.FlagSet, cfg *config.Config) error {
+	if err := applyFlagsToConfig(flags, cfg); err != nil {
+		return err
+	}
+
+	if err := config.ResolveAuthToken(cfg); err != nil {
+		return fmt.Errorf("failed to get auth token: %w", err)
+	}
+
+	return config.ValidateConfig(cfg)
+}
+
+// applyFlagsToConfig overrides the configuration with the command-line flags that were set.
+//
+//nolint:gocognit // This function handles all flag overrides, high complexity is expected.
+func applyFlagsToConfig(flags *pflag.FlagSet, cfg *config.Config) error {
 	var err error
 
+	if flag := flags.Lookup("mock-server"); flag != nil && flag.Changed {
+		cfg.MockServer, err = flags.GetBool("mock-server")
+		if err != nil {
+			return fmt.Errorf("failed to get mock-server value: %w", err)
+		}
+	}
+
 	if flag := flags.Lookup("quality"); flag != nil && flag.Changed {
 		var qualityValue int
 
@@ -211,7 +531,123 @@ func bindFlagsToConfig(flags *pflag.FlagSet, cfg *config.Config) error {
 		}
 	}
 
-	return config.ValidateConfig(cfg)
+	if flag := flags.Lookup("no-lock"); flag != nil && flag.Changed {
+		cfg.NoLock, err = flags.GetBool("no-lock")
+		if err != nil {
+			return fmt.Errorf("failed to get no-lock value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("fail-fast"); flag != nil && flag.Changed {
+		cfg.FailFast, err = flags.GetBool("fail-fast")
+		if err != nil {
+			return fmt.Errorf("failed to get fail-fast value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("max-duration"); flag != nil && flag.Changed {
+		cfg.MaxRunDuration, err = flags.GetString("max-duration")
+		if err != nil {
+			return fmt.Errorf("failed to get max-duration value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("download-window"); flag != nil && flag.Changed {
+		cfg.DownloadWindow, err = flags.GetString("download-window")
+		if err != nil {
+			return fmt.Errorf("failed to get download-window value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("per-artist-limit"); flag != nil && flag.Changed {
+		cfg.PerArtistLimit, err = flags.GetInt64("per-artist-limit")
+		if err != nil {
+			return fmt.Errorf("failed to get per-artist-limit value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("skip-downloaded"); flag != nil && flag.Changed {
+		cfg.SkipDownloadedTracks, err = flags.GetBool("skip-downloaded")
+		if err != nil {
+			return fmt.Errorf("failed to get skip-downloaded value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("exclude-regex"); flag != nil && flag.Changed {
+		var excludePatterns []string
+
+		excludePatterns, err = flags.GetStringArray("exclude-regex")
+		if err != nil {
+			return fmt.Errorf("failed to get exclude-regex value: %w", err)
+		}
+
+		cfg.ExcludePatterns = append(cfg.ExcludePatterns, excludePatterns...)
+	}
+
+	if flag := flags.Lookup("order"); flag != nil && flag.Changed {
+		cfg.DownloadOrder, err = flags.GetString("order")
+		if err != nil {
+			return fmt.Errorf("failed to get order value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("progress"); flag != nil && flag.Changed {
+		cfg.Progress, err = flags.GetString("progress")
+		if err != nil {
+			return fmt.Errorf("failed to get progress value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("tracks"); flag != nil && flag.Changed {
+		cfg.TrackRanges, err = flags.GetString("tracks")
+		if err != nil {
+			return fmt.Errorf("failed to get tracks value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("json"); flag != nil && flag.Changed {
+		cfg.JSONOutput, err = flags.GetBool("json")
+		if err != nil {
+			return fmt.Errorf("failed to get json value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("format"); flag != nil && flag.Changed {
+		cfg.DryRunFormat, err = flags.GetString("format")
+		if err != nil {
+			return fmt.Errorf("failed to get format value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("interactive"); flag != nil && flag.Changed {
+		cfg.Interactive, err = flags.GetBool("interactive")
+		if err != nil {
+			return fmt.Errorf("failed to get interactive value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("address"); flag != nil && flag.Changed {
+		cfg.ServeAddress, err = flags.GetString("address")
+		if err != nil {
+			return fmt.Errorf("failed to get address value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("insecure"); flag != nil && flag.Changed {
+		cfg.ServeInsecure, err = flags.GetBool("insecure")
+		if err != nil {
+			return fmt.Errorf("failed to get insecure value: %w", err)
+		}
+	}
+
+	if flag := flags.Lookup("phone"); flag != nil && flag.Changed {
+		cfg.LoginPhone, err = flags.GetString("phone")
+		if err != nil {
+			return fmt.Errorf("failed to get phone value: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // dumpConfig dumps the configuration as JSON for E2E testing.
@@ -106,7 +106,7 @@ func TestE2E_FlagOverrides_Format(t *testing.T) {
 	baseConfig := `
 auth_token: "test_token_123"
 quality: 1
-output_path: "/tmp/test-output"
+output_path: "/config/output"
 download_lyrics: false
 download_speed_limit: "500KB"
 log_level: "info"
@@ -294,7 +294,7 @@ func TestE2E_FlagOverrides_InvalidValues(t *testing.T) {
 	baseConfig := `
 auth_token: "test_token_123"
 quality: 1
-output_path: "/tmp/test-output"
+output_path: "/config/output"
 download_lyrics: false
 download_speed_limit: "500KB"
 log_level: "info"
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,8 +13,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/oshokin/zvuk-grabber/internal/app"
 	"github.com/oshokin/zvuk-grabber/internal/config"
 	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
 
 const testBaseConfigContent = `
@@ -126,6 +130,18 @@ func TestFlagOverrides(t *testing.T) {
 				assert.Equal(t, "2MB", cfg.DownloadSpeedLimit)
 			},
 		},
+		{
+			name: "fail-fast flag only - enable fail-fast mode",
+			flags: map[string]any{
+				"fail-fast": true,
+			},
+			expectedConfig: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+				assert.True(t, cfg.FailFast)
+				assert.Equal(t, uint8(1), cfg.Quality)
+				assert.Equal(t, "/config/output", cfg.OutputPath)
+			},
+		},
 		{
 			name: "quality and output flags - partial override",
 			flags: map[string]any{
@@ -281,6 +297,7 @@ func TestFlagOverrides(t *testing.T) {
 			testCmd.Flags().StringP("output", "o", "", "output directory")
 			testCmd.Flags().BoolP("lyrics", "l", false, "include lyrics")
 			testCmd.Flags().StringP("speed-limit", "s", "", "download speed limit")
+			testCmd.Flags().Bool("fail-fast", false, "cancel the run on the first error")
 
 			// Set flag values.
 			for flagName, flagValue := range tt.flags {
@@ -612,3 +629,96 @@ func TestBindFlagsToConfig_EmptyFlagSet(t *testing.T) {
 	err := bindFlagsToConfig(emptyFlags, cfg)
 	require.NoError(t, err)
 }
+
+// TestRequireURLsOrIDs tests that the root command accepts either arguments or the --ids flag.
+func TestRequireURLsOrIDs(t *testing.T) {
+	t.Parallel()
+
+	newCommand := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().StringSlice("ids", nil, "")
+
+		return cmd
+	}
+
+	require.NoError(t, requireURLsOrIDs(newCommand(), []string{"https://zvuk.com/track/1"}))
+	require.Error(t, requireURLsOrIDs(newCommand(), nil))
+
+	cmd := newCommand()
+	require.NoError(t, cmd.Flags().Set("ids", "track:1,album:2"))
+	require.NoError(t, requireURLsOrIDs(cmd, nil))
+}
+
+// TestRequireDryRunForFormat tests that --format is only accepted together with --dry-run.
+func TestRequireDryRunForFormat(t *testing.T) {
+	t.Parallel()
+
+	newCommand := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.Flags().String("format", "", "")
+
+		return cmd
+	}
+
+	require.NoError(t, requireDryRunForFormat(newCommand()))
+
+	cmd := newCommand()
+	require.NoError(t, cmd.Flags().Set("format", "json"))
+	require.ErrorIs(t, requireDryRunForFormat(cmd), ErrFormatWithoutDryRun)
+
+	require.NoError(t, cmd.Flags().Set("dry-run", "true"))
+	require.NoError(t, requireDryRunForFormat(cmd))
+}
+
+// TestCompleteRecentURLs tests that the download command completes the URLs recorded in the download history.
+//
+//nolint:paralleltest // Cannot run in parallel due to Viper global state.
+func TestCompleteRecentURLs(t *testing.T) {
+	var (
+		dir         = t.TempDir()
+		historyPath = filepath.Join(dir, "history.jsonl")
+		configPath  = filepath.Join(dir, "config.yaml")
+	)
+
+	history := `{"track_id":"1","title":"Links","category":"album","parent_id":"100","parent_title":"Mutter"}
+{"track_id":"2","title":"Sonne","category":"track"}
+`
+	require.NoError(t, os.WriteFile(historyPath, []byte(history), constants.DefaultFilePermissions))
+	require.NoError(t, os.WriteFile(configPath, []byte("history_path: "+strconv.Quote(historyPath)+"\n"),
+		constants.DefaultFilePermissions))
+
+	previousConfigFilename := configFilenameFromFlag
+	configFilenameFromFlag = configPath
+
+	t.Cleanup(func() { configFilenameFromFlag = previousConfigFilename })
+
+	completions, directive := completeRecentURLs(rootCmd, nil, "")
+	assert.Equal(t, []cobra.Completion{
+		"https://zvuk.com/track/2\tSonne",
+		"https://zvuk.com/release/100\tMutter",
+	}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveKeepOrder, directive)
+
+	completions, _ = completeRecentURLs(rootCmd, nil, "https://zvuk.com/release")
+	assert.Equal(t, []cobra.Completion{"https://zvuk.com/release/100\tMutter"}, completions)
+}
+
+// TestPrintCommandError tests that cobra leaves the command errors to printCommandError, which redacts them.
+func TestPrintCommandError(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, rootCmd.SilenceErrors, "cobra must not print the unredacted error")
+
+	logger.AddSecret("print_command_error_token")
+
+	var output bytes.Buffer
+
+	printCommandError(&output, errors.New("request with token print_command_error_token failed"))
+	assert.Equal(t, "Error: request with token [REDACTED] failed\n", output.String())
+
+	output.Reset()
+	printCommandError(&output, nil)
+	printCommandError(&output, &app.ExitError{Code: constants.ExitCodePartialSuccess})
+	assert.Empty(t, output.String())
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+)
+
+const (
+	// defaultSearchLimit is the default number of search results.
+	defaultSearchLimit = 20
+)
+
+var (
+	// searchTypeFromFlag stores the kind of items to search for.
+	//
+	//nolint:gochecknoglobals // Cobra flags are bound to package-level variables.
+	searchTypeFromFlag string
+
+	// searchLimitFromFlag stores the maximum number of search results.
+	//
+	//nolint:gochecknoglobals // Cobra flags are bound to package-level variables.
+	searchLimitFromFlag int
+
+	// searchFormatFromFlag stores the output format of search results.
+	//
+	//nolint:gochecknoglobals // Cobra flags are bound to package-level variables.
+	searchFormatFromFlag string
+
+	searchCmd = &cobra.Command{
+		Use:   `search "query"`,
+		Short: "Search for tracks, albums, artists, or playlists",
+		Long: `Searches Zvuk and prints the matching items with their IDs and URLs.
+
+The printed URLs can be passed straight to the download command.
+
+Examples:
+zvuk-grabber search "Rammstein" --type artist
+zvuk-grabber search "Mutter" --type album --limit 5
+zvuk-grabber search "Sonne" --format json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			searchType := zvuk_client.SearchType(strings.ToLower(strings.TrimSpace(searchTypeFromFlag)))
+
+			switch searchType {
+			case zvuk_client.SearchTypeTrack,
+				zvuk_client.SearchTypeAlbum,
+				zvuk_client.SearchTypeArtist,
+				zvuk_client.SearchTypePlaylist:
+			default:
+				return fmt.Errorf("%w: %s (expected track, album, artist, or playlist)",
+					zvuk_client.ErrUnknownSearchType, searchTypeFromFlag)
+			}
+
+			return app.ExecuteSearchCommand(
+				cmd.Context(),
+				appConfig,
+				cmd.OutOrStdout(),
+				strings.Join(args, " "),
+				searchType,
+				searchLimitFromFlag,
+				searchFormatFromFlag)
+		},
+	}
+)
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	searchCmdFlags := searchCmd.Flags()
+
+	searchCmdFlags.StringVarP(
+		&searchTypeFromFlag,
+		"type",
+		"t",
+		string(zvuk_client.SearchTypeTrack),
+		"kind of items to search for: track, album, artist, or playlist.")
+
+	searchCmdFlags.IntVar(
+		&searchLimitFromFlag,
+		"limit",
+		defaultSearchLimit,
+		"maximum number of results.")
+
+	searchCmdFlags.StringVar(
+		&searchFormatFromFlag,
+		"format",
+		app.SearchFormatTable,
+		"output format: table or json.")
+
+	// Add search command to root command.
+	rootCmd.AddCommand(searchCmd)
+}
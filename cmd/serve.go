@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run as a service driven by a REST API",
+	Long: `Runs zvuk-grabber as a long-lived service with a REST API,
+for example on a NAS, to start downloads from a phone or a script.
+
+Submitted URLs and identifiers become jobs, which are downloaded one at a time:
+POST   /jobs              submit {"urls": ["https://zvuk.com/release/123"]}
+GET    /jobs              list the jobs
+GET    /jobs/{id}         status and live counters of a job
+DELETE /jobs/{id}         cancel a queued job or interrupt the running one
+GET    /jobs/{id}/logs    stream the log of a job until it finishes
+GET    /jobs/{id}/summary summary of a finished job, as printed by --json
+
+The API listens on serve_address (127.0.0.1:8080 by default, only reachable from this computer).
+Set serve_token before exposing it: requests must then send "Authorization: Bearer <token>".
+Listening on any other address without serve_token is refused unless --insecure is passed.
+
+Example:
+zvuk-grabber serve --address 0.0.0.0:8080
+curl -H "Authorization: Bearer $TOKEN" -H "Content-Type: application/json" \
+  -d '{"urls":["https://zvuk.com/release/123"]}' http://nas:8080/jobs`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		app.ExecuteServeCommand(cmd.Context(), appConfig)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	serveCmd.Flags().String(
+		"address",
+		"",
+		"host and port the REST API listens on, overriding serve_address (e.g., 0.0.0.0:8080).")
+	serveCmd.Flags().Bool(
+		"insecure",
+		false,
+		"listen beyond this computer without serve_token, overriding serve_insecure.")
+
+	// Jobs are written to the output path like regular downloads.
+	addNoLockFlag(serveCmd.Flags())
+
+	// Add serve command to root command.
+	rootCmd.AddCommand(serveCmd)
+}
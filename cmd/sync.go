@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync {playlist urls or ids}",
+	Short: "Download the tracks added to playlists since their last sync",
+	Long: `Mirrors playlists incrementally.
+
+The track IDs fetched from every playlist are kept in sync_state_path,
+so the next sync downloads only the tracks added since then,
+without requesting the tracks it already has.
+Tracks removed from the playlist are reported; pass --delete-removed to delete their files.
+
+Run it periodically, for example from cron:
+0 6 * * * zvuk-grabber sync https://zvuk.com/playlist/123`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deleteRemoved, err := cmd.Flags().GetBool("delete-removed")
+		if err != nil {
+			logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+		}
+
+		return app.ExecuteSyncCommand(cmd.Context(), appConfig, args, deleteRemoved)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	syncCmdFlags := syncCmd.Flags()
+
+	syncCmdFlags.Bool(
+		"delete-removed",
+		false,
+		"delete the files of tracks removed from the playlist since the last sync.")
+
+	// Synced tracks are written to the output path like regular downloads.
+	addNoLockFlag(syncCmdFlags)
+
+	// Add sync command to root command.
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag {dir}",
+	Short: "Rewrite the tags of downloaded files from fresh metadata",
+	Long: `Walks the folder and rewrites the tags, covers, and lyrics of every FLAC and MP3 file
+saved by zvuk-grabber, without downloading the audio again.
+
+The metadata is fetched from Zvuk by the TRACK_ID tag of every file.
+Files saved with a playlist keep their playlist tags and track numbers,
+and their covers are embedded only if embed_playlist_covers is on.
+Lyrics are fetched and saved next to the files if download_lyrics is on.
+Files without a track ID, audiobook chapters, and podcast episodes are skipped.
+
+The tags are written to a copy of every file, which replaces it once they are written,
+so a file is never left half-written. With --dry-run nothing is changed.
+
+Examples:
+zvuk-grabber tag ~/Music/zvuk
+zvuk-grabber tag --dry-run ~/Music/zvuk/Rammstein`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return app.ExecuteTagCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), args[0])
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	tagCmd.Flags().BoolP(
+		"dry-run",
+		"n",
+		false,
+		"list the files that would be re-tagged without changing them.")
+
+	// Add tag command to root command.
+	rootCmd.AddCommand(tagCmd)
+}
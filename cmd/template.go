@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var (
+	templateCmd = &cobra.Command{
+		Use:   "template",
+		Short: "Filename and folder template helpers",
+		Long: `Helpers for writing filename and folder templates.
+
+Use 'template vars' to list every placeholder you can use in the *_template settings.`,
+	}
+
+	templateVarsCmd = &cobra.Command{
+		Use:   "vars",
+		Short: "List template variables with example values",
+		Long: `Prints every variable supported by each template setting
+(track, album, playlist, audiobook, and podcast) with an example value.
+
+The list is produced by the same code that fills tags during downloads,
+so it always matches the running version.
+
+Example:
+zvuk-grabber template vars`,
+		// Template variables do not depend on the configuration file.
+		PersistentPreRun: func(*cobra.Command, []string) {},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return app.ExecuteTemplateVarsCommand(cmd.OutOrStdout())
+		},
+	}
+)
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Add vars subcommand to template command.
+	templateCmd.AddCommand(templateVarsCmd)
+
+	// Add template command to root command.
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Re-download watched tracks that have become available in FLAC",
+	Long: `Checks the tracks on the upgrade watch list (upgrade_watch_path)
+and downloads the ones that have become available in FLAC.
+
+Tracks saved below FLAC are added to the watch list during regular downloads.
+Once a FLAC version is saved, the lower-quality file is deleted,
+or moved to upgrade_quarantine_path if it is set.
+
+Run it periodically, for example from cron:
+0 6 * * 1 zvuk-grabber upgrade`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return app.ExecuteUpgradeCommand(cmd.Context(), appConfig)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	// Upgrades write to the output path like regular downloads.
+	addNoLockFlag(upgradeCmd.Flags())
+
+	// Add upgrade command to root command.
+	rootCmd.AddCommand(upgradeCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify {dir}",
+	Short: "Audit downloaded files for corruption, truncation, and wrong tags",
+	Long: `Walks the folder and checks every FLAC and MP3 file:
+- the file can be parsed and its audio starts with a valid frame,
+- the audio is not shorter than the track (truncated download),
+- the title, artists, and release in the tags match the track metadata.
+
+The tags are compared with the metadata fetched from Zvuk by the TRACK_ID tag,
+so the auth token is needed for that part; without it only the file structure is checked.
+With --check-sizes the audio size is also compared with the size of the stream,
+which takes two extra requests per file.
+
+The report ends with the command that downloads the broken tracks again,
+and the command exits with a non-zero code if any problem is found.
+
+Examples:
+zvuk-grabber verify ~/Music/zvuk
+zvuk-grabber verify --check-sizes ~/Music/zvuk/Rammstein`,
+	Args:             cobra.ExactArgs(1),
+	SilenceUsage:     true,
+	PersistentPreRun: initLoginConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checkSizes, err := cmd.Flags().GetBool("check-sizes")
+		if err != nil {
+			return err
+		}
+
+		return app.ExecuteVerifyCommand(cmd.Context(), appConfig, cmd.OutOrStdout(), args[0], checkSizes)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	verifyCmd.Flags().Bool(
+		"check-sizes",
+		false,
+		"compare the audio size with the size of the stream (two extra requests per file).")
+
+	// Add verify command to root command.
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oshokin/zvuk-grabber/internal/app"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// defaultWatchInterval is the default pause between the checks of the watch command.
+	defaultWatchInterval = 6 * time.Hour
+	// minWatchInterval is the shortest pause between the checks, to avoid hammering the API.
+	minWatchInterval = time.Minute
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch {playlist or artist urls or ids}",
+	Short: "Check playlists and artists periodically and download what was added",
+	Long: `Keeps running and checks the items every --interval until interrupted (CTRL+C).
+
+Every check downloads only what was added since the previous one:
+the new tracks of playlists, kept in sync_state_path like the sync command does,
+and the new releases of artists, also kept in sync_state_path.
+A release that fails is retried by the next check. Other URLs are downloaded
+on every check, skipping the files that exist. On the first check, everything is new.
+
+The pause between the checks is randomly shortened or extended by up to 10%,
+so several watchers do not hit the API at the same time.
+
+Example:
+zvuk-grabber watch --interval 6h https://zvuk.com/playlist/123 https://zvuk.com/artist/456`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+
+		interval, err := flags.GetDuration("interval")
+		if err != nil {
+			logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+		}
+
+		if interval < minWatchInterval {
+			logger.Fatalf(cmd.Context(), "The interval must be at least %s, got %s", minWatchInterval, interval)
+		}
+
+		deleteRemoved, err := flags.GetBool("delete-removed")
+		if err != nil {
+			logger.Fatalf(cmd.Context(), "Failed to parse flags: %v", err)
+		}
+
+		app.ExecuteWatchCommand(cmd.Context(), appConfig, args, interval, deleteRemoved)
+	},
+}
+
+//nolint:gochecknoinits // Cobra requires the init function to set up commands.
+func init() {
+	watchCmdFlags := watchCmd.Flags()
+
+	watchCmdFlags.Duration(
+		"interval",
+		defaultWatchInterval,
+		"pause between the checks (at least 1m), e.g. 30m, 6h, or 24h.")
+
+	watchCmdFlags.Bool(
+		"delete-removed",
+		false,
+		"delete the files of tracks removed from the playlists since the previous check.")
+
+	// Every check writes to the output path like regular downloads.
+	addNoLockFlag(watchCmdFlags)
+
+	// Add watch command to root command.
+	rootCmd.AddCommand(watchCmd)
+}
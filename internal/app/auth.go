@@ -2,15 +2,27 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
 
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
 	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/service/auth"
+	http_transport "github.com/oshokin/zvuk-grabber/internal/transport/http"
 )
 
+// ErrAuthTokenInvalid is returned by the auth status command when Zvuk rejects the configured token.
+var ErrAuthTokenInvalid = errors.New("auth token is invalid")
+
 // ExecuteAuthLoginCommand executes the auth login command.
 // It opens a browser, waits for the user to log in, extracts the token,
-// and saves it to the configuration file.
+// checks it against the user profile API, and saves it to the configuration file or the keyring.
 func ExecuteAuthLoginCommand(ctx context.Context, cfg *config.Config) {
 	logger.Info(ctx, "Starting authentication process")
 
@@ -24,12 +36,28 @@ func ExecuteAuthLoginCommand(ctx context.Context, cfg *config.Config) {
 	// Perform login and extract token.
 	token, err := authService.LoginAndExtractToken(ctx)
 	if err != nil {
-		logger.Fatalf(ctx, "Authentication failed: %v", err)
+		logger.FatalCodef(ctx, constants.ExitCodeAuthFailure, "Authentication failed: %v", err)
 		return
 	}
 
 	// Update configuration with new token.
 	cfg.AuthToken = token
+	logger.AddSecret(token)
+
+	// Make sure Zvuk accepts the token before it replaces the one in the configuration file.
+	userProfile, err := fetchUserProfile(ctx, cfg, authService)
+	if err != nil {
+		logger.FatalCodef(ctx, constants.ExitCodeAuthFailure, "Extracted token was rejected by Zvuk: %v", err)
+		return
+	}
+
+	if userProfile.Subscription == nil {
+		logger.Warn(ctx, "Token is valid, but the account has no active subscription: downloads will fail")
+	} else {
+		expiration := time.UnixMilli(userProfile.Subscription.Expiration).Format(time.RFC1123)
+		logger.Infof(ctx, "Token is valid. Active subscription: '%s', expires on %s",
+			userProfile.Subscription.Title, expiration)
+	}
 
 	// Save configuration to file.
 	if err = config.SaveConfig(cfg); err != nil {
@@ -37,8 +65,18 @@ func ExecuteAuthLoginCommand(ctx context.Context, cfg *config.Config) {
 		return
 	}
 
+	// The variable wins over the file, so the new token would be ignored.
+	if envVarName := config.EnvVarName("auth_token"); os.Getenv(envVarName) != "" {
+		logger.Warnf(ctx, "%s is set and overrides the saved token: unset it to use the new one", envVarName)
+	}
+
 	// Print success message.
-	logger.Info(ctx, "Configuration updated successfully!")
+	if config.IsKeyringStorage(cfg) {
+		logger.Info(ctx, "Token saved to the keyring!")
+	} else {
+		logger.Info(ctx, "Configuration updated successfully!")
+	}
+
 	logger.Info(ctx, "Authentication complete! You can now download music.")
 	logger.Info(ctx, "")
 	logger.Info(ctx, "Try downloading an album:")
@@ -47,3 +85,76 @@ func ExecuteAuthLoginCommand(ctx context.Context, cfg *config.Config) {
 	logger.Info(ctx, "Or a playlist:")
 	logger.Info(ctx, "zvuk-grabber https://zvuk.com/playlist/9037842")
 }
+
+// ExecuteAuthStatusCommand executes the auth status command.
+// It checks the configured token against the user profile API and prints the active subscription
+// and the number of days before it expires. An error is returned if the token is missing or rejected.
+func ExecuteAuthStatusCommand(ctx context.Context, cfg *config.Config, w io.Writer) error {
+	if err := config.ResolveAuthToken(cfg); err != nil {
+		fmt.Fprintln(w, "Token: unavailable") //nolint:errcheck // Output errors are not actionable here.
+
+		return fmt.Errorf("failed to get auth token: %w", err)
+	}
+
+	if cfg.AuthToken == "" {
+		fmt.Fprintln(w, "Token: missing") //nolint:errcheck // Output errors are not actionable here.
+
+		return fmt.Errorf("%w: auth_token is not set, run 'zvuk-grabber auth login'", ErrAuthTokenInvalid)
+	}
+
+	userProfile, err := fetchUserProfile(ctx, cfg, nil)
+	if err != nil {
+		fmt.Fprintln(w, "Token: invalid") //nolint:errcheck // Output errors are not actionable here.
+
+		return fmt.Errorf("%w: %w", ErrAuthTokenInvalid, err)
+	}
+
+	if _, err = fmt.Fprintln(w, "Token: valid"); err != nil {
+		return err
+	}
+
+	if userProfile.Subscription == nil {
+		_, err = fmt.Fprintln(w, "Subscription: none")
+
+		return err
+	}
+
+	expiration := time.UnixMilli(userProfile.Subscription.Expiration)
+
+	_, err = fmt.Fprintf(w, "Subscription: %s\nExpires: %s (%s)\n",
+		userProfile.Subscription.Title,
+		expiration.Format(time.DateOnly),
+		formatDaysLeft(time.Until(expiration)))
+
+	return err
+}
+
+// formatDaysLeft describes the time left before the subscription expires in whole days, rounded up.
+func formatDaysLeft(left time.Duration) string {
+	const day = 24 * time.Hour
+
+	if left <= 0 {
+		return "expired"
+	}
+
+	daysLeft := int64(math.Ceil(float64(left) / float64(day)))
+	if daysLeft == 1 {
+		return "1 day left"
+	}
+
+	return fmt.Sprintf("%d days left", daysLeft)
+}
+
+// fetchUserProfile requests the profile of the user the configured token belongs to.
+func fetchUserProfile(
+	ctx context.Context,
+	cfg *config.Config,
+	challengeSolver http_transport.ChallengeSolver,
+) (*zvuk_client.UserProfile, error) {
+	zvukClient, err := zvuk_client.NewClient(cfg, challengeSolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize zvuk client: %w", err)
+	}
+
+	return zvukClient.GetUserProfile(ctx)
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteCleanupCommand executes the cleanup command.
+// It lists the temporary files interrupted runs left in the folder (output_path when dir is empty)
+// and deletes them once the user confirms, or right away when assumeYes is set.
+func ExecuteCleanupCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	r io.Reader,
+	w io.Writer,
+	dir string,
+	assumeYes bool,
+) error {
+	if strings.TrimSpace(dir) == "" {
+		dir = cfg.OutputPath
+	}
+
+	var writeErr error
+
+	report, err := zvuk_service.CleanupOrphanedFiles(ctx, cfg, &zvuk_service.CleanupRequest{
+		Dir: dir,
+		Confirm: func(files []*zvuk_service.OrphanedFile) bool {
+			if writeErr = writeOrphanedFiles(w, files); writeErr != nil {
+				return false
+			}
+
+			if assumeYes {
+				return true
+			}
+
+			var isConfirmed bool
+
+			isConfirmed, writeErr = confirm(r, w, fmt.Sprintf("Delete %d file(s)?", len(files)))
+
+			return isConfirmed
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	switch {
+	case len(report.Files) == 0:
+		_, err = fmt.Fprintf(w, "No temporary files found in '%s'\n", dir)
+	case report.RemovedCount == 0:
+		_, err = fmt.Fprintln(w, "Nothing was deleted")
+	default:
+		_, err = fmt.Fprintf(w, "Deleted %d file(s), freed %s\n",
+			report.RemovedCount, humanize.IBytes(uint64(max(report.RemovedBytes, 0))))
+	}
+
+	return err
+}
+
+// writeOrphanedFiles prints the temporary files found with their kind and size.
+func writeOrphanedFiles(w io.Writer, files []*zvuk_service.OrphanedFile) error {
+	var totalBytes int64
+
+	for _, file := range files {
+		totalBytes += file.Size
+
+		_, err := fmt.Fprintf(w, "%-22s %10s  %s\n",
+			file.Kind, humanize.IBytes(uint64(max(file.Size, 0))), file.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Found %d temporary file(s), %s\n", len(files), humanize.IBytes(uint64(max(totalBytes, 0))))
+
+	return err
+}
+
+// confirm asks a yes/no question and reports whether it was answered with yes.
+// Anything else, including the end of the input, is a no.
+func confirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	if _, err := fmt.Fprintf(w, "%s [y/N]: ", question); err != nil {
+		return false, err
+	}
+
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		_, err = fmt.Fprintln(w)
+
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ErrInvalidConfiguration is returned by the config validate command when problems are found.
+var ErrInvalidConfiguration = errors.New("configuration is invalid")
+
+// configCheck is a single check of the config validate command.
+type configCheck struct {
+	// name is the checked part of the configuration.
+	name string
+	// check returns the problems found, nil if there are none.
+	check func() error
+	// hint suggests how to fix the problems.
+	hint string
+}
+
+// ExecuteConfigValidateCommand executes the config validate command.
+// It checks the settings, the auth token, the template syntax and variables, and the output path,
+// and reports the problems of every check instead of stopping at the first one.
+func ExecuteConfigValidateCommand(cfg *config.Config, w io.Writer) error {
+	checks := []configCheck{
+		{
+			name: "auth_token",
+			check: func() error {
+				if err := config.ResolveAuthToken(cfg); err != nil {
+					return fmt.Errorf("failed to get auth token: %w", err)
+				}
+
+				if strings.TrimSpace(cfg.AuthToken) == "" {
+					return config.ErrEmptyAuthToken
+				}
+
+				return nil
+			},
+			hint: "run 'zvuk-grabber auth login' or set auth_token manually",
+		},
+		{
+			name:  "settings",
+			check: func() error { return config.ValidateConfigWithoutAuthToken(cfg) },
+			hint:  "see the Configuration section of the README for the accepted values",
+		},
+		{
+			name:  "templates",
+			check: func() error { return zvuk_service.ValidateTemplates(cfg) },
+			hint:  "run 'zvuk-grabber template vars' to list the available variables",
+		},
+		{
+			name:  "output_path",
+			check: func() error { return zvuk_service.CheckOutputPath(cfg) },
+			hint:  "choose a writable folder or fix its permissions",
+		},
+	}
+
+	var problemsCount int
+
+	for _, c := range checks {
+		err := c.check()
+		if err == nil {
+			if _, err = fmt.Fprintf(w, "OK    %s\n", c.name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		problems := strings.Split(err.Error(), "\n")
+		problemsCount += len(problems)
+
+		for _, problem := range problems {
+			if _, err = fmt.Fprintf(w, "FAIL  %s: %s\n", c.name, problem); err != nil {
+				return err
+			}
+		}
+
+		if _, err = fmt.Fprintf(w, "      hint: %s\n", c.hint); err != nil {
+			return err
+		}
+	}
+
+	if problemsCount > 0 {
+		return fmt.Errorf("%w: %d problem(s) found", ErrInvalidConfiguration, problemsCount)
+	}
+
+	_, err := fmt.Fprintln(w, "Configuration is valid")
+
+	return err
+}
+
+// ExecuteConfigEncryptCommand executes the config encrypt command.
+// It encrypts the configuration file in place with a new passphrase.
+func ExecuteConfigEncryptCommand(configFilename string, w io.Writer) error {
+	if err := config.EncryptConfigFile(configFilename); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "Configuration file '%s' is encrypted\n", configFilename)
+
+	return err
+}
+
+// ExecuteConfigDecryptCommand executes the config decrypt command.
+// It writes the configuration file back in clear text.
+func ExecuteConfigDecryptCommand(configFilename string, w io.Writer) error {
+	if err := config.DecryptConfigFile(configFilename); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "Configuration file '%s' is decrypted\n", configFilename)
+
+	return err
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// configReloadInterval is how often the configuration file is checked for changes with reload_config.
+const configReloadInterval = 2 * time.Second
+
+// watchConfigChanges applies the changes of download_speed_limit, max_concurrent_downloads, and log_level
+// made to the configuration file while the download runs (reload_config).
+// A setting is applied only when its value in the file changes, so the command-line flags stay in force
+// until then. A file that fails to load or validate is reported and its changes are ignored.
+func watchConfigChanges(ctx context.Context, cfg *config.Config, s zvuk_service.Service) {
+	if !cfg.ReloadConfig || cfg.ConfigFilename == "" {
+		return
+	}
+
+	fileInfo, err := os.Stat(cfg.ConfigFilename)
+	if err != nil {
+		logger.Warnf(ctx, "Configuration changes will not be applied during the run: %v", err)
+
+		return
+	}
+
+	fileConfig, err := loadReloadedConfig(cfg)
+	if err != nil {
+		logger.Warnf(ctx, "Configuration changes will not be applied during the run: %v", err)
+
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(configReloadInterval)
+		defer ticker.Stop()
+
+		modTime := fileInfo.ModTime()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			fileInfo, err := os.Stat(cfg.ConfigFilename)
+			if err != nil || fileInfo.ModTime().Equal(modTime) {
+				continue
+			}
+
+			modTime = fileInfo.ModTime()
+
+			reloadedConfig, err := loadReloadedConfig(cfg)
+			if err != nil {
+				logger.Warnf(ctx, "Configuration changes are not applied: %v", err)
+
+				continue
+			}
+
+			applyConfigChanges(ctx, s, fileConfig, reloadedConfig)
+
+			fileConfig = reloadedConfig
+		}
+	}()
+}
+
+// loadReloadedConfig loads and validates the configuration file of the run with its profile.
+func loadReloadedConfig(cfg *config.Config) (*config.Config, error) {
+	reloadedConfig, err := config.LoadConfigProfile(cfg.ConfigFilename, cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	reloadedConfig.StateDir = cfg.StateDir
+	reloadedConfig.ConfigFilename = cfg.ConfigFilename
+
+	if err = config.ValidateConfigWithoutAuthToken(reloadedConfig); err != nil {
+		return nil, err
+	}
+
+	return reloadedConfig, nil
+}
+
+// applyConfigChanges applies the settings that differ between the previous and the reloaded configuration file.
+func applyConfigChanges(ctx context.Context, s zvuk_service.Service, previous, reloaded *config.Config) {
+	if reloaded.ParsedDownloadSpeedLimit != previous.ParsedDownloadSpeedLimit {
+		s.SetDownloadSpeedLimit(reloaded.ParsedDownloadSpeedLimit)
+
+		speedLimit := "unlimited"
+		if reloaded.ParsedDownloadSpeedLimit > 0 {
+			speedLimit = humanize.Bytes(uint64(reloaded.ParsedDownloadSpeedLimit)) + "/s"
+		}
+
+		logger.Infof(ctx, "Configuration reloaded: download speed limit is %s", speedLimit)
+	}
+
+	if reloaded.MaxConcurrentDownloads != previous.MaxConcurrentDownloads {
+		s.SetMaxConcurrentDownloads(reloaded.MaxConcurrentDownloads)
+
+		logger.Infof(ctx, "Configuration reloaded: up to %d track(s) are downloaded at once, starting with the next one",
+			reloaded.MaxConcurrentDownloads)
+	}
+
+	if reloaded.ParsedLogLevel != previous.ParsedLogLevel {
+		// Logged before the change, so the message is not hidden by a higher level.
+		logger.Infof(ctx, "Configuration reloaded: log level is %s", reloaded.ParsedLogLevel)
+		logger.SetLevel(reloaded.ParsedLogLevel)
+	}
+}
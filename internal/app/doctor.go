@@ -0,0 +1,331 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/service/auth"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+	http_transport "github.com/oshokin/zvuk-grabber/internal/transport/http"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// ErrDoctorFailed is returned by the doctor command when any check fails.
+var ErrDoctorFailed = errors.New("environment check failed")
+
+const (
+	// doctorMinFreeSpace is the free disk space below which the disk space check fails.
+	doctorMinFreeSpace = 1 << 30
+	// doctorNetworkTimeout limits the network reachability request.
+	doctorNetworkTimeout = 15 * time.Second
+)
+
+// Statuses of the doctor checks.
+const (
+	// doctorStatusOK means the check passed.
+	doctorStatusOK = "OK"
+	// doctorStatusWarn means the check found something that may cause trouble but does not stop a download.
+	doctorStatusWarn = "WARN"
+	// doctorStatusFail means the check found a problem that stops a download.
+	doctorStatusFail = "FAIL"
+	// doctorStatusSkip means the check was not needed or could not run.
+	doctorStatusSkip = "SKIP"
+)
+
+// doctorResult is the outcome of a single check of the doctor command.
+type doctorResult struct {
+	// status is one of the doctorStatus constants.
+	status string
+	// message describes what was found.
+	message string
+	// hint suggests how to fix a failed check.
+	hint string
+}
+
+// doctorCheck is a single check of the doctor command.
+type doctorCheck struct {
+	// name is the checked part of the environment.
+	name string
+	// check runs the check against the loaded configuration.
+	check func(ctx context.Context, cfg *config.Config) *doctorResult
+}
+
+// ExecuteDoctorCommand executes the doctor command.
+// It loads the configuration file and checks the token, the output path, the free disk space,
+// the external tools, the browser used to log in, and the connection to Zvuk,
+// printing the result of every check with a hint for the failed ones.
+func ExecuteDoctorCommand(ctx context.Context, location *config.Location, profileName string, w io.Writer) error {
+	cfg, configResult := checkDoctorConfig(location, profileName)
+
+	checks := []doctorCheck{
+		{name: "auth_token", check: checkDoctorToken},
+		{name: "output_path", check: checkDoctorOutputPath},
+		{name: "disk_space", check: checkDoctorDiskSpace},
+		{name: "ffmpeg", check: checkDoctorFFmpeg},
+		{name: "rclone", check: checkDoctorRclone},
+		{name: "browser", check: checkDoctorBrowser},
+		{name: "network", check: checkDoctorNetwork},
+	}
+
+	failedCount, err := printDoctorResult(w, "config", configResult)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range checks {
+		result := &doctorResult{status: doctorStatusSkip, message: "the configuration could not be loaded"}
+		if cfg != nil {
+			result = c.check(ctx, cfg)
+		}
+
+		failed, printErr := printDoctorResult(w, c.name, result)
+		if printErr != nil {
+			return printErr
+		}
+
+		failedCount += failed
+	}
+
+	if failedCount > 0 {
+		return fmt.Errorf("%w: %d check(s) failed", ErrDoctorFailed, failedCount)
+	}
+
+	_, err = fmt.Fprintln(w, "Everything is ready to download")
+
+	return err
+}
+
+// printDoctorResult prints the result of a check and returns 1 if the check failed.
+func printDoctorResult(w io.Writer, name string, result *doctorResult) (int, error) {
+	if _, err := fmt.Fprintf(w, "%-6s%s: %s\n", result.status, name, result.message); err != nil {
+		return 0, err
+	}
+
+	if result.hint != "" && result.status != doctorStatusOK && result.status != doctorStatusSkip {
+		if _, err := fmt.Fprintf(w, "      hint: %s\n", result.hint); err != nil {
+			return 0, err
+		}
+	}
+
+	if result.status == doctorStatusFail {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// checkDoctorConfig loads and validates the configuration file with the profile applied.
+// The configuration is returned when it could be loaded, even if some settings are invalid,
+// so the other checks still run.
+func checkDoctorConfig(location *config.Location, profileName string) (*config.Config, *doctorResult) {
+	configFilename := location.ConfigFilename
+
+	cfg, err := config.LoadConfigProfile(configFilename, profileName)
+	if err != nil {
+		hint := "copy .zvuk-grabber.yaml from the release archive or pass its path with --config"
+		if errors.Is(err, config.ErrUnknownProfile) || errors.Is(err, config.ErrInvalidProfile) {
+			hint = "pass the name of a section under 'profiles' in the configuration file with --profile"
+		} else if errors.Is(err, config.ErrUnknownKey) {
+			hint = "fix the spelling of the key or remove it from the configuration file"
+		}
+
+		return nil, &doctorResult{status: doctorStatusFail, message: err.Error(), hint: hint}
+	}
+
+	cfg.StateDir = location.StateDir
+
+	if err = config.ValidateConfigWithoutAuthToken(cfg); err != nil {
+		return cfg, &doctorResult{
+			status:  doctorStatusFail,
+			message: strings.ReplaceAll(err.Error(), "\n", "; "),
+			hint:    "run 'zvuk-grabber config validate' for the details of every setting",
+		}
+	}
+
+	message := "loaded from " + configFilename
+	if profileName != "" {
+		message += " with profile '" + profileName + "'"
+	}
+
+	if cfg.StateDir != "" {
+		message += ", state kept in " + cfg.StateDir
+	}
+
+	return cfg, &doctorResult{status: doctorStatusOK, message: message}
+}
+
+// checkDoctorToken checks that Zvuk accepts the configured auth token.
+func checkDoctorToken(ctx context.Context, cfg *config.Config) *doctorResult {
+	const hint = "run 'zvuk-grabber auth login' or set auth_token manually"
+
+	if err := config.ResolveAuthToken(cfg); err != nil {
+		return &doctorResult{
+			status:  doctorStatusFail,
+			message: err.Error(),
+			hint:    "unlock the keyring or set auth_token_storage to config",
+		}
+	}
+
+	if strings.TrimSpace(cfg.AuthToken) == "" {
+		return &doctorResult{status: doctorStatusFail, message: "auth_token is not set", hint: hint}
+	}
+
+	userProfile, err := fetchUserProfile(ctx, cfg, nil)
+	if err != nil {
+		return &doctorResult{status: doctorStatusFail, message: err.Error(), hint: hint}
+	}
+
+	if userProfile.Subscription == nil {
+		return &doctorResult{
+			status:  doctorStatusWarn,
+			message: "the token is valid, but there is no subscription",
+			hint:    "only previews and MP3 in low quality may be available without a subscription",
+		}
+	}
+
+	expiration := time.UnixMilli(userProfile.Subscription.Expiration)
+
+	return &doctorResult{
+		status:  doctorStatusOK,
+		message: "the token is valid, subscription " + formatDaysLeft(time.Until(expiration)),
+	}
+}
+
+// checkDoctorOutputPath checks that files can be saved to the output path.
+func checkDoctorOutputPath(_ context.Context, cfg *config.Config) *doctorResult {
+	if err := zvuk_service.CheckOutputPath(cfg); err != nil {
+		return &doctorResult{
+			status:  doctorStatusFail,
+			message: err.Error(),
+			hint:    "choose a writable folder or fix its permissions",
+		}
+	}
+
+	return &doctorResult{status: doctorStatusOK, message: cfg.OutputPath + " is writable"}
+}
+
+// checkDoctorDiskSpace checks the free space on the disk of the output path.
+// The output path may not exist yet, so its nearest existing parent folder is checked.
+func checkDoctorDiskSpace(_ context.Context, cfg *config.Config) *doctorResult {
+	path, err := filepath.Abs(cfg.OutputPath)
+	if err != nil {
+		return &doctorResult{status: doctorStatusSkip, message: err.Error()}
+	}
+
+	for {
+		if _, err = os.Stat(path); err == nil {
+			break
+		}
+
+		parentPath := filepath.Dir(path)
+		if parentPath == path {
+			return &doctorResult{status: doctorStatusSkip, message: "no existing folder found on the output path"}
+		}
+
+		path = parentPath
+	}
+
+	freeBytes, err := utils.FreeDiskSpace(path)
+	if err != nil {
+		return &doctorResult{status: doctorStatusWarn, message: "free disk space is unknown: " + err.Error()}
+	}
+
+	message := humanize.IBytes(freeBytes) + " free on the disk of " + path
+	if freeBytes < doctorMinFreeSpace {
+		return &doctorResult{
+			status:  doctorStatusFail,
+			message: message,
+			hint: fmt.Sprintf("free up at least %s or choose output_path on another disk",
+				humanize.IBytes(doctorMinFreeSpace)),
+		}
+	}
+
+	return &doctorResult{status: doctorStatusOK, message: message}
+}
+
+// checkDoctorFFmpeg checks that ffmpeg can be found when a setting that uses it is enabled.
+func checkDoctorFFmpeg(_ context.Context, cfg *config.Config) *doctorResult {
+	if !cfg.NormalizeLoudness && cfg.PortableOutputPath == "" && cfg.FLACCompressionLevel == "" {
+		return &doctorResult{
+			status:  doctorStatusSkip,
+			message: "not needed, normalize_loudness, portable_output_path, and flac_compression_level are off",
+		}
+	}
+
+	return lookPathResult(cfg.FFmpegPath, "install ffmpeg or set ffmpeg_path to its executable")
+}
+
+// checkDoctorRclone checks that rclone can be found when rclone_remote is set.
+func checkDoctorRclone(_ context.Context, cfg *config.Config) *doctorResult {
+	if cfg.RcloneRemote == "" {
+		return &doctorResult{status: doctorStatusSkip, message: "not needed, rclone_remote is not set"}
+	}
+
+	return lookPathResult(cfg.RclonePath, "install rclone or set rclone_path to its executable")
+}
+
+// lookPathResult checks that the executable can be found.
+func lookPathResult(executable, hint string) *doctorResult {
+	path, err := exec.LookPath(executable)
+	if err != nil {
+		return &doctorResult{status: doctorStatusFail, message: err.Error(), hint: hint}
+	}
+
+	return &doctorResult{status: doctorStatusOK, message: "found at " + path}
+}
+
+// checkDoctorBrowser checks that a browser for 'auth login' is installed.
+// Login works without one by downloading Chromium, so a missing browser is only a warning.
+func checkDoctorBrowser(_ context.Context, _ *config.Config) *doctorResult {
+	path, exists := auth.FindBrowser()
+	if !exists {
+		return &doctorResult{
+			status:  doctorStatusWarn,
+			message: "Chrome or Chromium is not installed",
+			hint:    "install Chrome, or let 'zvuk-grabber auth login' download Chromium on its first run",
+		}
+	}
+
+	return &doctorResult{status: doctorStatusOK, message: "found at " + path}
+}
+
+// checkDoctorNetwork checks that Zvuk can be reached. Any HTTP response counts, whatever its status.
+func checkDoctorNetwork(ctx context.Context, cfg *config.Config) *doctorResult {
+	const hint = "check the internet connection, the proxy, and zvuk_base_url"
+
+	ctx, cancel := context.WithTimeout(ctx, doctorNetworkTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.ZvukBaseURL, nil)
+	if err != nil {
+		return &doctorResult{status: doctorStatusFail, message: err.Error(), hint: hint}
+	}
+
+	startTime := time.Now()
+
+	client := &http.Client{Transport: http_transport.NewProxyTransport(cfg.ProxyURL, cfg.NoProxy)}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return &doctorResult{status: doctorStatusFail, message: err.Error(), hint: hint}
+	}
+
+	_ = response.Body.Close()
+
+	return &doctorResult{
+		status: doctorStatusOK,
+		message: fmt.Sprintf("%s responded with %d in %s",
+			cfg.ZvukBaseURL, response.StatusCode, time.Since(startTime).Round(time.Millisecond)),
+	}
+}
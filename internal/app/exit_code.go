@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExitError is returned by a command that ends with a specific process exit code.
+// An ExitError without Err has nothing to print, e.g., the download summary has already been shown.
+type ExitError struct {
+	// Code is the process exit code, one of the constants.ExitCode values.
+	Code int
+	// Err is the cause of the exit code, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the exit code.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for a command that returned err.
+// A run stopped by a signal is interrupted whatever it returned.
+func ExitCode(ctx context.Context, err error) int {
+	var exitErr *ExitError
+
+	switch {
+	case ctx.Err() != nil:
+		return constants.ExitCodeInterrupted
+	case err == nil:
+		return constants.ExitCodeOK
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.Is(err, ErrAuthTokenInvalid):
+		return constants.ExitCodeAuthFailure
+	case errors.Is(err, ErrInvalidConfiguration):
+		return constants.ExitCodeInvalidConfig
+	default:
+		return constants.ExitCodeFailure
+	}
+}
+
+// downloadOutcome returns the error matching the outcome of a finished download:
+// nil when something was downloaded or there was nothing to do,
+// and an ExitError when the run stopped before downloading anything, some items failed, or every track was skipped.
+// The error that stopped the run has already been logged.
+func downloadOutcome(runErr error, stats *zvuk_service.DownloadStatistics) error {
+	switch {
+	case errors.Is(runErr, zvuk_service.ErrOutputPathLocked):
+		return &ExitError{Code: constants.ExitCodeOutputLocked}
+	case runErr != nil:
+		return &ExitError{Code: constants.ExitCodeFailure}
+	case len(stats.Errors) > 0 || stats.TracksFailed > 0:
+		return &ExitError{Code: constants.ExitCodePartialSuccess}
+	case stats.TracksSkipped > 0 && stats.TracksDownloaded+stats.TracksLinked+stats.TracksUpgraded == 0:
+		return &ExitError{Code: constants.ExitCodeAllSkipped}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+var (
+	// ErrInvalidExportFormat is returned when the --format value of the export command is not supported.
+	ErrInvalidExportFormat = errors.New("invalid --format value")
+	// ErrExportCategoryMismatch is returned when an item passed to the export command is not an album or playlist.
+	ErrExportCategoryMismatch = errors.New("only albums and playlists can be exported")
+)
+
+// exportFormatExtensions maps the --format values of the export command to the playlist file extensions.
+//
+//nolint:gochecknoglobals // This is a static lookup table.
+var exportFormatExtensions = map[string]string{
+	"m3u8": ".m3u8",
+	"m3u":  ".m3u",
+}
+
+// ExecuteExportCommand executes the export command.
+// It writes an M3U playlist for every album and playlist of the download history,
+// or only for the given ones, and prints where they were saved.
+func ExecuteExportCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	w io.Writer,
+	urls []string,
+	dir string,
+	format string,
+) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+
+	extension, ok := exportFormatExtensions[format]
+	if !ok {
+		return fmt.Errorf("%w '%s': must be m3u8 or m3u", ErrInvalidExportFormat, format)
+	}
+
+	query := &zvuk_service.PlaylistExportQuery{
+		Extension:  extension,
+		OutputPath: strings.TrimSpace(dir),
+	}
+
+	if len(urls) > 0 {
+		items, err := zvuk_service.NewURLProcessor().ExtractDownloadItems(ctx, urls)
+		if err != nil {
+			return fmt.Errorf("failed to extract download items: %w", err)
+		}
+
+		for _, item := range slices.Concat(items.Tracks, items.StandaloneItems, items.Artists) {
+			if item.Category != zvuk_service.DownloadCategoryAlbum &&
+				item.Category != zvuk_service.DownloadCategoryPlaylist {
+				return fmt.Errorf("%w: '%s' is %s", ErrExportCategoryMismatch, item.URL, item.Category.ToLowerCase())
+			}
+
+			query.Items = append(query.Items, zvuk_service.ShortDownloadItem{
+				Category: item.Category,
+				ItemID:   item.ItemID,
+			})
+		}
+
+		// Unrecognized URLs are reported by the URL processor and must not widen the export to everything.
+		if len(query.Items) == 0 {
+			return zvuk_service.ErrNothingToExport
+		}
+	}
+
+	playlists, err := zvuk_service.ExportPlaylists(ctx, cfg, query)
+	for _, playlist := range playlists {
+		_, printErr := fmt.Fprintf(w, "%s '%s': %d track(s) saved to %s\n",
+			playlist.Category.ToTitleCase(), playlist.Title, playlist.TracksCount, playlist.Path)
+		if printErr != nil {
+			return printErr
+		}
+	}
+
+	return err
+}
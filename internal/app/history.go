@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// historyDateFormat is the format of the dates accepted by --since and printed by the history command.
+const historyDateFormat = "2006-01-02"
+
+// ErrInvalidHistorySince is returned when the --since value of the history command cannot be parsed.
+var ErrInvalidHistorySince = errors.New("invalid --since value")
+
+// ExecuteHistoryCommand executes the history command.
+// It prints the tracks recorded in the download history, newest first,
+// optionally limited to a period and to an artist.
+func ExecuteHistoryCommand(ctx context.Context, cfg *config.Config, w io.Writer, since, artist string) error {
+	query := &zvuk_service.HistoryQuery{Artist: artist}
+
+	if strings.TrimSpace(since) != "" {
+		sinceTime, err := parseHistorySince(since, time.Now())
+		if err != nil {
+			return err
+		}
+
+		query.Since = sinceTime
+	}
+
+	entries, err := zvuk_service.QueryHistory(ctx, cfg, query)
+	if err != nil {
+		return err
+	}
+
+	return writeHistoryEntries(w, entries)
+}
+
+// parseHistorySince converts a --since value into the earliest time to show.
+// It accepts a number of days or weeks (7d, 2w), a Go duration (12h, 90m), or a date (2026-01-31).
+func parseHistorySince(value string, now time.Time) (time.Time, error) {
+	const (
+		day  = 24 * time.Hour
+		week = 7 * day
+	)
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if date, err := time.ParseInLocation(historyDateFormat, value, time.Local); err == nil {
+		return date, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": day, "w": week} {
+		count, isCut := strings.CutSuffix(value, suffix)
+		if !isCut {
+			continue
+		}
+
+		number, err := strconv.Atoi(count)
+		if err != nil || number < 0 {
+			break
+		}
+
+		return now.Add(-time.Duration(number) * unit), nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("%w '%s': use a period like 7d, 2w, or 12h, or a date like 2026-01-31",
+			ErrInvalidHistorySince, value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+// writeHistoryEntries prints the history entries as a table followed by their count and total size.
+func writeHistoryEntries(w io.Writer, entries []*zvuk_service.HistoryEntry) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No downloads found")
+
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, minColumnWidth, tabWidth, padding, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "DOWNLOADED\tID\tQUALITY\tSIZE\tARTISTS\tTITLE\tPATH"); err != nil {
+		return err
+	}
+
+	var totalBytes int64
+
+	for _, entry := range entries {
+		totalBytes += entry.Bytes
+
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.DownloadedAt.Local().Format(time.DateTime),
+			entry.TrackID,
+			entry.Quality,
+			humanize.IBytes(uint64(max(entry.Bytes, 0))),
+			strings.Join(entry.Artists, ", "),
+			entry.Title,
+			entry.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d track(s), %s\n", len(entries), humanize.IBytes(uint64(max(totalBytes, 0))))
+
+	return err
+}
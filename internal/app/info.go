@@ -0,0 +1,371 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+var (
+	// ErrInfoItemNotFound is returned when the metadata of a requested item is not found.
+	ErrInfoItemNotFound = errors.New("item not found")
+	// ErrUnsupportedInfoCategory is returned when the info command cannot describe an item of the category.
+	ErrUnsupportedInfoCategory = errors.New("unsupported item category")
+)
+
+// infoCollection is the metadata of an album, playlist, audiobook, or podcast printed by the info command.
+type infoCollection struct {
+	// kind is the human-readable collection type.
+	kind string
+	// title is the collection name.
+	title string
+	// fields are the additional "name: value" lines printed under the title, in order.
+	fields [][2]string
+	// trackIDs is the ordered list of tracks in the collection.
+	trackIDs []int64
+	// tracks maps a track ID to its metadata.
+	tracks map[string]*zvuk_client.Track
+}
+
+// ExecuteInfoCommand executes the info command.
+// It resolves the URLs and prints the metadata of every item, including the tracklist,
+// durations, and available qualities, without downloading anything.
+func ExecuteInfoCommand(ctx context.Context, cfg *config.Config, w io.Writer, urls []string) error {
+	zvukClient, err := zvuk_client.NewClient(cfg, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize zvuk client: %w", err)
+	}
+
+	items, err := zvuk_service.NewURLProcessor().ExtractDownloadItems(ctx, urls)
+	if err != nil {
+		return fmt.Errorf("failed to extract download items: %w", err)
+	}
+
+	for _, item := range items.Artists {
+		logger.Warnf(ctx, "Artist '%s' is skipped: use 'zvuk-grabber list artist' to list artist releases", item.URL)
+	}
+
+	if len(items.Tracks) > 0 {
+		collection, fetchErr := fetchInfoTracks(ctx, zvukClient, items.Tracks)
+		if fetchErr != nil {
+			return fetchErr
+		}
+
+		if err = writeInfoCollection(w, collection); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range items.StandaloneItems {
+		collection, fetchErr := fetchInfoCollection(ctx, zvukClient, item)
+		if errors.Is(fetchErr, ErrInfoItemNotFound) {
+			logger.Errorf(ctx, "%s with ID '%s' is not found", item.Category.ToTitleCase(), item.ItemID)
+
+			continue
+		}
+
+		if fetchErr != nil {
+			return fetchErr
+		}
+
+		if err = writeInfoCollection(w, collection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchInfoTracks fetches the metadata of standalone tracks.
+func fetchInfoTracks(
+	ctx context.Context,
+	zvukClient zvuk_client.Client,
+	items []*zvuk_service.DownloadItem,
+) (*infoCollection, error) {
+	trackIDs := make([]string, 0, len(items))
+	collection := &infoCollection{kind: "Tracks", trackIDs: make([]int64, 0, len(items))}
+
+	for _, item := range items {
+		trackID, err := strconv.ParseInt(item.ItemID, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		trackIDs = append(trackIDs, item.ItemID)
+		collection.trackIDs = append(collection.trackIDs, trackID)
+	}
+
+	tracks, err := zvukClient.GetTracksMetadata(ctx, trackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks metadata: %w", err)
+	}
+
+	collection.tracks = tracks
+
+	return collection, nil
+}
+
+// fetchInfoCollection fetches the metadata of an album, playlist, audiobook, or podcast.
+func fetchInfoCollection(
+	ctx context.Context,
+	zvukClient zvuk_client.Client,
+	item *zvuk_service.DownloadItem,
+) (*infoCollection, error) {
+	//nolint:exhaustive // Tracks and artists are not standalone items.
+	switch item.Category {
+	case zvuk_service.DownloadCategoryAlbum:
+		return fetchInfoAlbum(ctx, zvukClient, item)
+	case zvuk_service.DownloadCategoryPlaylist:
+		response, err := zvukClient.GetPlaylistsMetadata(ctx, []string{item.ItemID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get playlist metadata: %w", err)
+		}
+
+		playlist, ok := response.Playlists[item.ItemID]
+		if !ok || playlist == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInfoItemNotFound, item.URL)
+		}
+
+		return &infoCollection{
+			kind:     "Playlist",
+			title:    playlist.Title,
+			fields:   [][2]string{{"URL", item.URL}},
+			trackIDs: playlist.TrackIDs,
+			tracks:   response.Tracks,
+		}, nil
+	case zvuk_service.DownloadCategoryAudiobook:
+		response, err := zvukClient.GetAudiobooksMetadata(ctx, []string{item.ItemID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get audiobook metadata: %w", err)
+		}
+
+		audiobook, ok := response.Audiobooks[item.ItemID]
+		if !ok || audiobook == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInfoItemNotFound, item.URL)
+		}
+
+		return &infoCollection{
+			kind:  "Audiobook",
+			title: audiobook.Title,
+			fields: [][2]string{
+				{"Authors", strings.Join(audiobook.ArtistNames, ", ")},
+				{"Narrators", strings.Join(audiobook.PerformerNames, ", ")},
+				{"Publisher", audiobook.PublisherName},
+				{"Publication date", formatInfoPublicationDate(audiobook.PublicationDate)},
+				{"Genres", strings.Join(audiobook.Genres, ", ")},
+				{"URL", item.URL},
+			},
+			trackIDs: audiobook.TrackIDs,
+			tracks:   response.Tracks,
+		}, nil
+	case zvuk_service.DownloadCategoryPodcast:
+		response, err := zvukClient.GetPodcastsMetadata(ctx, []string{item.ItemID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get podcast metadata: %w", err)
+		}
+
+		podcast, ok := response.Podcasts[item.ItemID]
+		if !ok || podcast == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInfoItemNotFound, item.URL)
+		}
+
+		return &infoCollection{
+			kind:  "Podcast",
+			title: podcast.Title,
+			fields: [][2]string{
+				{"Authors", strings.Join(podcast.ArtistNames, ", ")},
+				{"Category", podcast.Category},
+				{"URL", item.URL},
+			},
+			trackIDs: podcast.TrackIDs,
+			tracks:   response.Tracks,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedInfoCategory, item.URL)
+	}
+}
+
+// fetchInfoAlbum fetches the metadata of an album, including its label.
+func fetchInfoAlbum(
+	ctx context.Context,
+	zvukClient zvuk_client.Client,
+	item *zvuk_service.DownloadItem,
+) (*infoCollection, error) {
+	response, err := zvukClient.GetAlbumsMetadata(ctx, []string{item.ItemID}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album metadata: %w", err)
+	}
+
+	album, ok := response.Releases[item.ItemID]
+	if !ok || album == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInfoItemNotFound, item.URL)
+	}
+
+	var labelTitle string
+
+	labelID := strconv.FormatInt(album.LabelID, 10)
+
+	labels, err := zvukClient.GetLabelsMetadata(ctx, []string{labelID})
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get label of album '%s': %v", album.Title, err)
+	} else if label, isFound := labels[labelID]; isFound && label != nil {
+		labelTitle = label.Title
+	}
+
+	return &infoCollection{
+		kind:  "Album",
+		title: album.Title,
+		fields: [][2]string{
+			{"Artists", strings.Join(album.ArtistNames, ", ")},
+			{"Type", album.Type},
+			{"Release date", formatInfoReleaseDate(album.Date)},
+			{"Label", labelTitle},
+			{"URL", item.URL},
+		},
+		trackIDs: album.TrackIDs,
+		tracks:   response.Tracks,
+	}, nil
+}
+
+// writeInfoCollection prints the collection header and its tracklist.
+func writeInfoCollection(w io.Writer, collection *infoCollection) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	header := collection.kind
+	if collection.title != "" {
+		header += ": " + collection.title
+	}
+
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	for _, field := range collection.fields {
+		if field[1] == "" {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s\n", field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	var totalDuration int64
+
+	for _, trackID := range collection.trackIDs {
+		if track := collection.tracks[strconv.FormatInt(trackID, 10)]; track != nil {
+			totalDuration += track.Duration
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Tracks: %d (total %s)\n",
+		len(collection.trackIDs), formatInfoDuration(totalDuration))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, minColumnWidth, tabWidth, padding, ' ', 0)
+	if _, err = fmt.Fprintln(tw, "  #\tID\tTITLE\tARTISTS\tDURATION\tQUALITIES"); err != nil {
+		return err
+	}
+
+	for i, trackID := range collection.trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+
+		track := collection.tracks[trackIDString]
+		if track == nil {
+			_, err = fmt.Fprintf(tw, "  %d\t%s\t(unavailable)\t\t\t\n", i+1, trackIDString)
+		} else {
+			_, err = fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\t%s\n",
+				i+1,
+				trackIDString,
+				track.Title,
+				strings.Join(track.ArtistNames, ", "),
+				formatInfoDuration(track.Duration),
+				strings.Join(availableQualities(track), ", "))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w)
+
+	return err
+}
+
+// availableQualities lists the qualities a track can be downloaded in, from lowest to highest.
+func availableQualities(track *zvuk_client.Track) []string {
+	highestQuality := zvuk_service.ParseQuality(track.HighestQuality)
+	if track.HasFLAC {
+		highestQuality = zvuk_service.TrackQualityFLAC
+	}
+
+	qualities := []string{
+		zvuk_service.TrackQualityMP3MidString,
+		zvuk_service.TrackQualityMP3HighString,
+		zvuk_service.TrackQualityFLACString,
+	}
+
+	if highestQuality == zvuk_service.TrackQualityUnknown {
+		return []string{"unknown"}
+	}
+
+	return slices.Clone(qualities[:highestQuality])
+}
+
+// formatInfoDuration formats a duration in seconds as M:SS or H:MM:SS.
+func formatInfoDuration(seconds int64) string {
+	const secondsPerMinute, secondsPerHour = 60, 3600
+
+	if seconds >= secondsPerHour {
+		return fmt.Sprintf("%d:%02d:%02d",
+			seconds/secondsPerHour, seconds%secondsPerHour/secondsPerMinute, seconds%secondsPerMinute)
+	}
+
+	return fmt.Sprintf("%d:%02d", seconds/secondsPerMinute, seconds%secondsPerMinute)
+}
+
+// formatInfoReleaseDate formats a release date stored as a YYYYMMDD number.
+func formatInfoReleaseDate(date int64) string {
+	if date == 0 {
+		return ""
+	}
+
+	parsedDate, err := time.Parse("20060102", strconv.FormatInt(date, 10))
+	if err != nil {
+		return strconv.FormatInt(date, 10)
+	}
+
+	return parsedDate.Format(time.DateOnly)
+}
+
+// formatInfoPublicationDate trims a publication timestamp down to its date.
+func formatInfoPublicationDate(date string) string {
+	parsedDate, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return date
+	}
+
+	return parsedDate.Format(time.DateOnly)
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// artistReleasesPageSize is the number of release IDs requested per page of artist releases.
+const artistReleasesPageSize = 50
+
+// ErrListCategoryMismatch is returned when an item passed to the list command is of another type.
+var ErrListCategoryMismatch = errors.New("item does not match the list type")
+
+// ExecuteListCommand executes the list command.
+// It prints the releases of artists, or the tracks of playlists, audiobooks, or podcasts,
+// with their IDs, so a subset can be chosen before downloading.
+func ExecuteListCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	w io.Writer,
+	category zvuk_service.DownloadCategory,
+	urls []string,
+) error {
+	zvukClient, err := zvuk_client.NewClient(cfg, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize zvuk client: %w", err)
+	}
+
+	items, err := zvuk_service.NewURLProcessor().ExtractDownloadItems(ctx, urls)
+	if err != nil {
+		return fmt.Errorf("failed to extract download items: %w", err)
+	}
+
+	listItems := slices.Concat(items.Tracks, items.StandaloneItems, items.Artists)
+	for _, item := range listItems {
+		if item.Category != category {
+			return fmt.Errorf("%w: '%s' is %s, expected %s",
+				ErrListCategoryMismatch, item.URL, item.Category.ToLowerCase(), category.ToLowerCase())
+		}
+	}
+
+	for _, item := range listItems {
+		if category == zvuk_service.DownloadCategoryArtist {
+			err = listArtistReleases(ctx, zvukClient, w, item, int(cfg.MetadataBatchSize))
+		} else {
+			err = listCollectionTracks(ctx, zvukClient, w, item)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listCollectionTracks prints the tracks of a playlist, audiobook, or podcast.
+func listCollectionTracks(
+	ctx context.Context,
+	zvukClient zvuk_client.Client,
+	w io.Writer,
+	item *zvuk_service.DownloadItem,
+) error {
+	collection, err := fetchInfoCollection(ctx, zvukClient, item)
+	if errors.Is(err, ErrInfoItemNotFound) {
+		logger.Errorf(ctx, "%s with ID '%s' is not found", item.Category.ToTitleCase(), item.ItemID)
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeInfoCollection(w, collection)
+}
+
+// listArtistReleases pages through the releases of an artist and prints them with their year and type.
+// The release metadata is requested in batches of batchSize IDs.
+func listArtistReleases(
+	ctx context.Context,
+	zvukClient zvuk_client.Client,
+	w io.Writer,
+	item *zvuk_service.DownloadItem,
+	batchSize int,
+) error {
+	var releaseIDs []string
+
+	for offset := 0; ; offset += artistReleasesPageSize {
+		pageIDs, err := zvukClient.GetArtistReleaseIDs(ctx, item.ItemID, offset, artistReleasesPageSize)
+		if errors.Is(err, zvuk_client.ErrArtistNotFound) {
+			logger.Errorf(ctx, "Artist with ID '%s' is not found", item.ItemID)
+
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to get artist releases: %w", err)
+		}
+
+		if len(pageIDs) == 0 {
+			break
+		}
+
+		releaseIDs = append(releaseIDs, pageIDs...)
+	}
+
+	releaseIDs = slices.Compact(releaseIDs)
+
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataBatchSize
+	}
+
+	releases := make(map[string]*zvuk_client.Release, len(releaseIDs))
+
+	for batch := range slices.Chunk(releaseIDs, batchSize) {
+		response, err := zvukClient.GetAlbumsMetadata(ctx, batch, false)
+		if err != nil {
+			return fmt.Errorf("failed to get album metadata: %w", err)
+		}
+
+		maps.Copy(releases, response.Releases)
+	}
+
+	return writeArtistReleases(w, item, releaseIDs, releases)
+}
+
+// writeArtistReleases prints the artist header and the releases in the order Zvuk lists them (newest first).
+func writeArtistReleases(
+	w io.Writer,
+	item *zvuk_service.DownloadItem,
+	releaseIDs []string,
+	releases map[string]*zvuk_client.Release,
+) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	_, err := fmt.Fprintf(w, "Artist ID: %s\nURL: %s\nReleases: %d\n", item.ItemID, item.URL, len(releaseIDs))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, minColumnWidth, tabWidth, padding, ' ', 0)
+	if _, err = fmt.Fprintln(tw, "  #\tID\tYEAR\tTYPE\tTITLE\tARTISTS\tTRACKS"); err != nil {
+		return err
+	}
+
+	for i, releaseID := range releaseIDs {
+		release := releases[releaseID]
+		if release == nil {
+			_, err = fmt.Fprintf(tw, "  %d\t%s\t\t\t(unavailable)\t\t\n", i+1, releaseID)
+		} else {
+			_, err = fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				i+1,
+				releaseID,
+				formatReleaseYear(release.Date),
+				release.Type,
+				release.Title,
+				strings.Join(release.ArtistNames, ", "),
+				len(release.TrackIDs))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w)
+
+	return err
+}
+
+// formatReleaseYear returns the year of a release date stored as a YYYYMMDD number.
+func formatReleaseYear(date int64) string {
+	const yearDivisor = 10000
+
+	if date <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(date/yearDivisor, 10)
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteMigrateCommand executes the migrate command.
+// It prints the moves that bring the files in the folder (output_path when dir is empty)
+// to the paths computed from the current templates, and applies them once the user confirms,
+// or right away when assumeYes is set.
+func ExecuteMigrateCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	r io.Reader,
+	w io.Writer,
+	dir string,
+	assumeYes bool,
+) error {
+	if strings.TrimSpace(dir) == "" {
+		dir = cfg.OutputPath
+	}
+
+	s, err := buildDownloadService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+
+	report, err := s.MigrateLibrary(ctx, &zvuk_service.MigrateLibraryRequest{
+		Dir: dir,
+		Confirm: func(moves []*zvuk_service.LibraryMove) bool {
+			if assumeYes {
+				return true
+			}
+
+			var isConfirmed bool
+
+			isConfirmed, writeErr = confirm(r, w, fmt.Sprintf("Move %d file(s)?", len(moves)))
+
+			return isConfirmed
+		},
+	})
+	if report != nil {
+		if printErr := writeMigratePlan(w, dir, report); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	switch {
+	case len(report.Moves) == 0:
+		_, err = fmt.Fprintln(w, "Every file is already in place")
+	case report.IsApplied:
+		_, err = fmt.Fprintf(w, "Moved %d file(s)\n", len(report.Moves))
+	default:
+		_, err = fmt.Fprintln(w, "Nothing was moved")
+	}
+
+	return err
+}
+
+// writeMigratePlan prints the planned moves relative to the folder, followed by the files left in place.
+func writeMigratePlan(w io.Writer, dir string, report *zvuk_service.MigrateReport) error {
+	relativePath := func(path string) string {
+		if relative, err := filepath.Rel(dir, path); err == nil {
+			return relative
+		}
+
+		return path
+	}
+
+	for _, move := range report.Moves {
+		if _, err := fmt.Fprintf(w, "MOVE  %s -> %s\n", relativePath(move.From), relativePath(move.To)); err != nil {
+			return err
+		}
+	}
+
+	for _, problems := range []struct {
+		label    string
+		problems []*zvuk_service.LibraryFileProblem
+	}{
+		{label: "SKIP", problems: report.Skipped},
+		{label: "KEEP", problems: report.Conflicts},
+	} {
+		for _, problem := range problems.problems {
+			if _, err := fmt.Fprintf(w, "%s  %s: %s\n", problems.label, relativePath(problem.Path), problem.Reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Found %d file(s): %d in place, %d to move, %d skipped, %d kept because of conflicts\n",
+		report.FilesFound, report.FilesInPlace, len(report.Moves), len(report.Skipped), len(report.Conflicts))
+
+	return err
+}
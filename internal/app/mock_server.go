@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// StartMockServer starts the mock Zvuk server with its synthetic catalog on a free local port
+// and points the configuration to it, so the command runs offline.
+// The server stops when the context is canceled.
+func StartMockServer(ctx context.Context, cfg *config.Config) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start mock server: %w", err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           zvuk_client.NewMockServer(cfg.ZvukGraphQLPath).Handler(),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+	}
+
+	go func() {
+		if serveErr := httpServer.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logger.Errorf(ctx, "Mock server failed: %v", serveErr)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		_ = httpServer.Close() //nolint:errcheck // The run is over, nothing to report to.
+	}()
+
+	cfg.ZvukBaseURL = "http://" + listener.Addr().String()
+	// There are no anti-bot challenges to solve, and no browser should be opened for them.
+	cfg.SolveAntiBotChallenges = false
+
+	logger.Warnf(ctx, "Using the mock server at %s: the catalog is synthetic, nothing comes from Zvuk", cfg.ZvukBaseURL)
+
+	return nil
+}
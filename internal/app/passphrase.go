@@ -0,0 +1,56 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// ErrPassphraseMismatch indicates that the passphrase was typed differently the second time.
+var ErrPassphraseMismatch = errors.New("passphrases do not match")
+
+// PromptConfigPassphrase asks for the passphrase of the configuration file in the terminal without echoing it,
+// twice when confirm is set. The prompts are written to the standard error, so the output stays clean.
+func PromptConfigPassphrase(confirm bool) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) { //nolint:gosec // File descriptors fit in int.
+		return nil, fmt.Errorf("%w: use --config-key-file, %s, or %s",
+			config.ErrConfigPassphraseRequired, config.ConfigKeyFileEnvVar, config.ConfigPassphraseEnvVar)
+	}
+
+	passphrase, err := readPassphrase("Configuration passphrase: ")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+
+	repeated, err := readPassphrase("Repeat the passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(passphrase, repeated) {
+		return nil, ErrPassphraseMismatch
+	}
+
+	return passphrase, nil
+}
+
+// readPassphrase prints the prompt and reads a line from the terminal without echoing it.
+func readPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd())) //nolint:gosec // File descriptors fit in int.
+
+	// The line break typed by the user is not echoed either.
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return passphrase, nil
+}
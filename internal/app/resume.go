@@ -0,0 +1,16 @@
+package app
+
+import (
+	"context"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteResumeCommand executes the resume command.
+// It downloads again the items that failed in the last run with errors.
+func ExecuteResumeCommand(ctx context.Context, cfg *config.Config) error {
+	return runWithSummary(ctx, cfg, func(s zvuk_service.Service) {
+		s.ResumeFailedItems(ctx)
+	})
+}
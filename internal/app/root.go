@@ -2,36 +2,165 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
 
 	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
 	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/service/auth"
 	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+	http_transport "github.com/oshokin/zvuk-grabber/internal/transport/http"
 )
 
+// stdinInputFile is the input file name that reads the URL list from standard input.
+const stdinInputFile = "-"
+
 // ExecuteRootCommand is the entry point for the application.
 // It initializes the Zvuk client, sets up the necessary service components,
-// and starts the download process for the provided URLs.
-func ExecuteRootCommand(ctx context.Context, cfg *config.Config, urls []string) {
-	zvukClient, err := zvuk_client.NewClient(cfg)
+// and starts the download process for the provided URLs and the URLs listed in the input files.
+// The returned ExitError tells that some items failed or every track was skipped.
+func ExecuteRootCommand(ctx context.Context, cfg *config.Config, urls, inputFiles []string) error {
+	// The track picker reads the answers from the terminal.
+	if cfg.Interactive && (!isTerminal(os.Stdin) || slices.Contains(inputFiles, stdinInputFile)) {
+		logger.Fatal(ctx, "Interactive mode needs a terminal on standard input")
+	}
+
+	inputURLs, err := readInputFiles(ctx, inputFiles)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to read input file: %v", err)
+	}
+
+	urls = append(urls, inputURLs...)
+	if len(urls) == 0 {
+		logger.Warn(ctx, "Nothing to download: the input files contain no URLs")
+		return nil
+	}
+
+	return runWithSummary(ctx, cfg, func(s zvuk_service.Service) {
+		s.DownloadURLs(ctx, urls)
+	})
+}
+
+// readInputFiles reads the URL lists of the input files, '-' standing for standard input.
+func readInputFiles(ctx context.Context, inputFiles []string) ([]string, error) {
+	var urls []string
+
+	for i, inputFile := range inputFiles {
+		// Standard input can only be read once.
+		if slices.Contains(inputFiles[:i], inputFile) {
+			continue
+		}
+
+		if inputFile == stdinInputFile {
+			lines, err := zvuk_service.ReadURLList(ctx, os.Stdin, "standard input")
+			if err != nil {
+				return nil, err
+			}
+
+			urls = append(urls, lines...)
+
+			continue
+		}
+
+		file, err := os.Open(inputFile) //nolint:gosec // The input file is chosen by the user.
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input file: %w", err)
+		}
+
+		lines, err := zvuk_service.ReadURLList(ctx, file, inputFile)
+		_ = file.Close() //nolint:errcheck // The file is only read.
+
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, lines...)
+	}
+
+	return urls, nil
+}
+
+// newDownloadService initializes the Zvuk client and the download service components.
+func newDownloadService(ctx context.Context, cfg *config.Config) zvuk_service.Service {
+	s, err := buildDownloadService(ctx, cfg)
 	if err != nil {
-		logger.Fatalf(ctx, "Failed to initialize zvuk client: %v", err)
+		logger.Fatalf(ctx, "Failed to start the download service: %v", err)
+	}
+
+	return s
+}
+
+// buildDownloadService initializes the Zvuk client and the download service components,
+// returning an error instead of exiting.
+func buildDownloadService(ctx context.Context, cfg *config.Config) (zvuk_service.Service, error) {
+	var challengeSolver http_transport.ChallengeSolver
+	if cfg.SolveAntiBotChallenges {
+		authService, err := auth.NewService(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize authentication service: %w", err)
+		}
+
+		challengeSolver = authService
+	}
+
+	zvukClient, err := zvuk_client.NewClient(cfg, challengeSolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize zvuk client: %w", err)
+	}
+
+	if cfg.StrictTemplates {
+		if err = zvuk_service.ValidateTemplates(cfg); err != nil {
+			return nil, fmt.Errorf("template validation failed: %w", err)
+		}
 	}
 
 	urlProcessor := zvuk_service.NewURLProcessor()
 	templateManager := zvuk_service.NewTemplateManager(ctx, cfg)
 	tagProcessor := zvuk_service.NewTagProcessor()
 
-	s := zvuk_service.NewService(cfg, zvukClient, urlProcessor, templateManager, tagProcessor)
+	return zvuk_service.NewService(cfg, zvukClient, urlProcessor, templateManager, tagProcessor), nil
+}
+
+// runWithSummary creates the download service and runs the command with it,
+// watching for status requests and configuration changes meanwhile.
+// The statistics are always printed when the command ends, even on panic,
+// and the returned ExitError tells the outcome of the download.
+func runWithSummary(ctx context.Context, cfg *config.Config, run func(s zvuk_service.Service)) (err error) {
+	s := newDownloadService(ctx, cfg)
 
-	// Ensure statistics are ALWAYS printed, even on panic or os.Exit bypass.
-	defer func() {
-		if r := recover(); r != nil {
-			logger.Errorf(ctx, "Panic recovered: %v", r)
-		}
+	defer printSummaryOnExit(ctx, s, &err)
+
+	watchStatusRequests(ctx, cfg, s)
+	watchConfigChanges(ctx, cfg, s)
+
+	run(s)
 
-		s.PrintDownloadSummary(ctx)
-	}()
+	return nil
+}
+
+// printSummaryOnExit prints the download summary, recovering from a panic first,
+// and sets errp to the outcome of the download.
+// It must be deferred directly, so recover can stop the panic.
+func printSummaryOnExit(ctx context.Context, s zvuk_service.Service, errp *error) {
+	r := recover()
+	if r != nil {
+		logger.Errorf(ctx, "Panic recovered: %v", r)
+	}
+
+	s.PrintDownloadSummary(ctx)
+
+	if err := s.AbortReason(); err != nil {
+		logger.Fatalf(ctx, "Download aborted: %v", err)
+	}
+
+	if r != nil {
+		*errp = &ExitError{Code: constants.ExitCodeFailure}
+
+		return
+	}
 
-	s.DownloadURLs(ctx, urls)
+	*errp = downloadOutcome(s.RunError(), s.Statistics())
 }
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+const (
+	// SearchFormatTable prints search results as an aligned table.
+	SearchFormatTable = "table"
+	// SearchFormatJSON prints search results as a JSON array.
+	SearchFormatJSON = "json"
+)
+
+// ErrUnknownSearchFormat is returned when the search output format is not supported.
+var ErrUnknownSearchFormat = errors.New("unknown search output format")
+
+// ExecuteSearchCommand executes the search command.
+// It searches Zvuk for items of the given type and prints them with their IDs and URLs,
+// so the URLs can be passed to the download command as is.
+func ExecuteSearchCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	w io.Writer,
+	query string,
+	searchType zvuk_client.SearchType,
+	limit int,
+	format string,
+) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != SearchFormatTable && format != SearchFormatJSON {
+		return fmt.Errorf("%w: %s (expected %s or %s)", ErrUnknownSearchFormat, format, SearchFormatTable, SearchFormatJSON)
+	}
+
+	zvukClient, err := zvuk_client.NewClient(cfg, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize zvuk client: %w", err)
+	}
+
+	results, err := zvukClient.Search(ctx, query, searchType, limit)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	if format == SearchFormatJSON {
+		return writeSearchResultsJSON(w, results)
+	}
+
+	return writeSearchResultsTable(w, results)
+}
+
+// writeSearchResultsJSON prints the search results as an indented JSON array.
+func writeSearchResultsJSON(w io.Writer, results []*zvuk_client.SearchResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(results)
+}
+
+// writeSearchResultsTable prints the search results as an aligned table.
+func writeSearchResultsTable(w io.Writer, results []*zvuk_client.SearchResult) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing found")
+
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, minColumnWidth, tabWidth, padding, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "ID\tTITLE\tARTISTS\tURL"); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		title := result.Title
+		if result.Date != "" {
+			title += " (" + result.Date + ")"
+		}
+
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			result.ID, title, strings.Join(result.ArtistNames, ", "), result.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/server"
+)
+
+const (
+	// serveReadHeaderTimeout is the maximum time to read the headers of an API request.
+	serveReadHeaderTimeout = 10 * time.Second
+	// serveShutdownTimeout is the maximum time to wait for the open API requests on shutdown.
+	serveShutdownTimeout = 5 * time.Second
+)
+
+// ErrServeTokenRequired is returned when the REST API would be reachable from other computers without a token.
+var ErrServeTokenRequired = errors.New("serve_token is required to listen on a non-loopback address")
+
+// ExecuteServeCommand executes the serve command.
+// It serves the REST API on serve_address until the context is canceled (CTRL+C),
+// running the submitted jobs one at a time. The running job is interrupted on shutdown.
+func ExecuteServeCommand(ctx context.Context, cfg *config.Config) {
+	listener, err := net.Listen("tcp", cfg.ServeAddress)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to listen on %s: %v", cfg.ServeAddress, err)
+	}
+
+	if err = checkServeAccess(ctx, cfg, listener.Addr()); err != nil {
+		_ = listener.Close() //nolint:errcheck // The command fails anyway.
+
+		logger.Fatalf(ctx, "Refusing to start the REST API on %s: %v", listener.Addr(), err)
+	}
+
+	jobServer := server.New(cfg, buildDownloadService)
+
+	httpServer := &http.Server{
+		Handler:           jobServer.Handler(),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	var workers sync.WaitGroup
+
+	workers.Go(func() { jobServer.Run(ctx) })
+
+	workers.Go(func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serveShutdownTimeout)
+		defer cancel()
+
+		if shutdownErr := httpServer.Shutdown(shutdownCtx); shutdownErr != nil {
+			_ = httpServer.Close() //nolint:errcheck // The server is stopping anyway.
+		}
+	})
+
+	logger.Infof(ctx, "REST API is listening on http://%s (press CTRL+C to stop)", listener.Addr())
+
+	if err = httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatalf(ctx, "REST API failed: %v", err)
+	}
+
+	workers.Wait()
+	logger.Info(ctx, "REST API stopped")
+}
+
+// checkServeAccess refuses to serve the REST API beyond this computer without serve_token,
+// unless serve_insecure allows it.
+func checkServeAccess(ctx context.Context, cfg *config.Config, address net.Addr) error {
+	if cfg.ServeToken != "" || isLoopbackAddress(address) {
+		return nil
+	}
+
+	if !cfg.ServeInsecure {
+		return fmt.Errorf("%w: set serve_token or pass --insecure", ErrServeTokenRequired)
+	}
+
+	logger.Warn(ctx, "serve_token is not set: anyone who can reach the REST API can start downloads")
+
+	return nil
+}
+
+// isLoopbackAddress reports whether the address is reachable only from this computer.
+func isLoopbackAddress(address net.Addr) bool {
+	tcpAddress, ok := address.(*net.TCPAddr)
+
+	return ok && tcpAddress.IP.IsLoopback()
+}
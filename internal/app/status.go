@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// statusCommand is the line typed into the terminal to request a status dump.
+const statusCommand = "status"
+
+// watchStatusRequests prints a live status dump on SIGQUIT (CTRL+\ on Unix-like systems)
+// or when "status" is typed into the terminal. It stops when the context is done.
+// Catching SIGQUIT also disables the default Go behavior of dumping goroutines and exiting.
+// In interactive mode the terminal answers the track picker, so only SIGQUIT is watched.
+func watchStatusRequests(ctx context.Context, cfg *config.Config, s zvuk_service.Service) {
+	requests := make(chan struct{}, 1)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGQUIT)
+
+	go func() {
+		for range signals {
+			requestStatus(requests)
+		}
+	}()
+
+	if isTerminal(os.Stdin) && !cfg.Interactive {
+		go readStatusCommands(os.Stdin, requests)
+	}
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-requests:
+				s.PrintStatus(ctx)
+			}
+		}
+	}()
+}
+
+// readStatusCommands requests a status dump for every "status" line read from r.
+// Input is line-buffered by the terminal, so the command must be followed by Enter.
+func readStatusCommands(r io.Reader, requests chan<- struct{}) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if strings.EqualFold(strings.TrimSpace(scanner.Text()), statusCommand) {
+			requestStatus(requests)
+		}
+	}
+}
+
+// requestStatus queues a status dump unless one is already waiting.
+func requestStatus(requests chan<- struct{}) {
+	select {
+	case requests <- struct{}{}:
+	default:
+	}
+}
+
+// isTerminal reports whether the file is an interactive terminal rather than a pipe or a regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
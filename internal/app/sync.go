@@ -0,0 +1,16 @@
+package app
+
+import (
+	"context"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteSyncCommand executes the sync command.
+// It downloads the tracks added to the playlists since their last sync.
+func ExecuteSyncCommand(ctx context.Context, cfg *config.Config, urls []string, deleteRemoved bool) error {
+	return runWithSummary(ctx, cfg, func(s zvuk_service.Service) {
+		s.SyncPlaylists(ctx, urls, deleteRemoved)
+	})
+}
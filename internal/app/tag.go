@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ErrRetagFailed is returned by the tag command when the tags of some files cannot be rewritten.
+var ErrRetagFailed = errors.New("failed to re-tag files")
+
+// ExecuteTagCommand executes the tag command.
+// It rewrites the tags, covers, and lyrics of the downloaded files in the folder
+// from the metadata fetched by their track IDs, and prints the files left as they are.
+func ExecuteTagCommand(ctx context.Context, cfg *config.Config, w io.Writer, dir string) error {
+	s, err := buildDownloadService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.RetagLibrary(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	if err = writeRetagReport(w, report); err != nil {
+		return err
+	}
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%w: %d file(s)", ErrRetagFailed, len(report.Failed))
+	}
+
+	return nil
+}
+
+// writeRetagReport prints the skipped and failed files of the report, followed by the totals.
+func writeRetagReport(w io.Writer, report *zvuk_service.RetagReport) error {
+	for _, problems := range []struct {
+		label    string
+		problems []*zvuk_service.LibraryFileProblem
+	}{
+		{label: "SKIP", problems: report.Skipped},
+		{label: "FAIL", problems: report.Failed},
+	} {
+		for _, problem := range problems.problems {
+			if _, err := fmt.Fprintf(w, "%s  %s: %s\n", problems.label, problem.Path, problem.Reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Found %d file(s): %d re-tagged, %d skipped, %d failed\n",
+		report.FilesFound, report.FilesRetagged, len(report.Skipped), len(report.Failed))
+
+	return err
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteTemplateVarsCommand executes the template vars command.
+// It prints every template variable available for each template setting along with an example value.
+func ExecuteTemplateVarsCommand(w io.Writer) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	for i, group := range zvuk_service.GetTemplateVariableGroups() {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s (%s):\n", group.Category.ToTitleCase(), group.ConfigKey); err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(w, minColumnWidth, tabWidth, padding, ' ', 0)
+		for _, variable := range group.Variables {
+			if _, err := fmt.Fprintf(tw, "  {{.%s}}\t%q\n", variable.Name, variable.Example); err != nil {
+				return err
+			}
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
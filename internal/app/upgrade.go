@@ -0,0 +1,19 @@
+package app
+
+import (
+	"context"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ExecuteUpgradeCommand executes the upgrade command.
+// It re-checks the tracks on the upgrade watch list and downloads the ones
+// that have become available in FLAC, regardless of the configured quality.
+func ExecuteUpgradeCommand(ctx context.Context, cfg *config.Config) error {
+	cfg.Quality = uint8(zvuk_service.TrackQualityFLAC)
+
+	return runWithSummary(ctx, cfg, func(s zvuk_service.Service) {
+		s.UpgradeWatchedTracks(ctx)
+	})
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// ErrLibraryProblems is returned by the verify command when problems are found.
+var ErrLibraryProblems = errors.New("library has problems")
+
+// ExecuteVerifyCommand executes the verify command.
+// It audits the audio files in the folder and prints every problem found,
+// followed by the command that downloads the affected tracks again.
+// Without an auth token only the file structure is checked.
+func ExecuteVerifyCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	w io.Writer,
+	dir string,
+	checkSizes bool,
+) error {
+	req := &zvuk_service.VerifyLibraryRequest{
+		Dir:        dir,
+		CheckSizes: checkSizes,
+	}
+
+	if strings.TrimSpace(cfg.AuthToken) != "" {
+		zvukClient, err := zvuk_client.NewClient(cfg, nil)
+		if err != nil {
+			return fmt.Errorf("failed to initialize zvuk client: %w", err)
+		}
+
+		req.Client = zvukClient
+	} else {
+		logger.Warn(ctx, "No auth token is configured: tags and sizes are not compared with the track metadata")
+	}
+
+	report, err := zvuk_service.VerifyLibrary(ctx, cfg, req)
+	if err != nil {
+		return err
+	}
+
+	if err = writeLibraryReport(w, report); err != nil {
+		return err
+	}
+
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("%w: %d problem(s) found", ErrLibraryProblems, len(report.Issues))
+	}
+
+	return nil
+}
+
+// writeLibraryReport prints the problems of the report and how to repair them.
+func writeLibraryReport(w io.Writer, report *zvuk_service.LibraryReport) error {
+	for _, issue := range report.Issues {
+		if _, err := fmt.Fprintf(w, "FAIL  %s: %s: %s\n", issue.Path, issue.Kind, issue.Detail); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "Checked %d file(s), found %d problem(s)\n",
+		report.FilesChecked, len(report.Issues)); err != nil {
+		return err
+	}
+
+	trackIDs := report.RepairTrackIDs()
+	if len(trackIDs) > 0 {
+		ids := make([]string, 0, len(trackIDs))
+		for _, trackID := range trackIDs {
+			ids = append(ids, "track:"+trackID)
+		}
+
+		if _, err := fmt.Fprintf(w, "\nTo repair, delete the files above and download them again:\n"+
+			"  zvuk-grabber --ids %s\n", strings.Join(ids, ",")); err != nil {
+			return err
+		}
+	}
+
+	unidentifiedPaths := report.UnidentifiedPaths()
+	if len(unidentifiedPaths) > 0 {
+		if _, err := fmt.Fprintln(w, "\nThese files have no track ID and must be replaced manually:"); err != nil {
+			return err
+		}
+
+		for _, path := range unidentifiedPaths {
+			if _, err := fmt.Fprintf(w, "  %s\n", path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// watchIntervalJitter is the share of the interval by which every pause between checks is randomly
+// shortened or extended, so the checks of many watchers do not hit the API at the same time.
+const watchIntervalJitter = 0.1
+
+// ExecuteWatchCommand executes the watch command.
+// It checks the playlists and artists every interval until interrupted, downloading only
+// the tracks and releases added since the previous check.
+func ExecuteWatchCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	urls []string,
+	interval time.Duration,
+	deleteRemoved bool,
+) {
+	for checkNumber := 1; ; checkNumber++ {
+		logger.Infof(ctx, "Check %d started", checkNumber)
+
+		runWatchCheck(ctx, cfg, urls, deleteRemoved)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		pause := jitteredInterval(interval)
+		logger.Infof(ctx, "Next check in %s, at %s",
+			pause.Round(time.Second), time.Now().Add(pause).Format(time.DateTime))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pause):
+		}
+	}
+}
+
+// runWatchCheck checks the items once with a new download service, so every check has its own summary.
+// A failed check is reported and retried by the next one instead of stopping the watch.
+func runWatchCheck(ctx context.Context, cfg *config.Config, urls []string, deleteRemoved bool) {
+	s, err := buildDownloadService(ctx, cfg)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to start the download service: %v", err)
+
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(ctx, "Panic recovered: %v", r)
+		}
+
+		s.PrintDownloadSummary(ctx)
+
+		if abortErr := s.AbortReason(); abortErr != nil {
+			logger.Errorf(ctx, "Check aborted: %v", abortErr)
+		}
+	}()
+
+	s.WatchItems(ctx, urls, deleteRemoved)
+}
+
+// jitteredInterval returns the interval randomly shortened or extended by up to watchIntervalJitter.
+func jitteredInterval(interval time.Duration) time.Duration {
+	jitter := time.Duration(float64(interval) * watchIntervalJitter)
+	if jitter <= 0 {
+		return interval
+	}
+
+	//nolint:gosec // math/rand/v2 is fine for spreading the checks.
+	return interval - jitter + rand.N(2*jitter+1)
+}
@@ -10,7 +10,10 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/machinebox/graphql"
@@ -31,6 +34,8 @@ type Client interface {
 	GetAlbumsMetadata(ctx context.Context, releaseIDs []string, withTracks bool) (*GetAlbumsMetadataResponse, error)
 	// GetAlbumURL constructs the URL for a specific album.
 	GetAlbumURL(releaseID string) (string, error)
+	// GetAPIStatistics returns a snapshot of API request counters and cache hit rates.
+	GetAPIStatistics() *APIStatistics
 	// GetArtistReleaseIDs retrieves release IDs for a specific artist.
 	GetArtistReleaseIDs(ctx context.Context, artistID string, offset int, limit int) ([]string, error)
 	// GetAudiobooksMetadata retrieves metadata for the specified audiobook IDs.
@@ -39,11 +44,14 @@ type Client interface {
 	GetPodcastsMetadata(ctx context.Context, podcastIDs []string) (*GetPodcastsMetadataResponse, error)
 	// GetBaseURL returns the base URL of the Zvuk API.
 	GetBaseURL() string
+	// GetFileSize returns the size of the file at the specified URL without downloading its content.
+	GetFileSize(ctx context.Context, fileURL string) (int64, error)
 	// GetLabelsMetadata retrieves metadata for the specified label IDs.
 	GetLabelsMetadata(ctx context.Context, labelIDs []string) (map[string]*Label, error)
 	// GetPlaylistsMetadata retrieves metadata for the specified playlist IDs.
 	GetPlaylistsMetadata(ctx context.Context, playlistIDs []string) (*GetPlaylistsMetadataResponse, error)
 	// GetStreamMetadata retrieves streaming metadata for a specific track and quality.
+	// An empty result means that the track has no stream in the quality, reported as ErrNoStreamAvailable.
 	GetStreamMetadata(ctx context.Context, trackID, quality string) (*StreamMetadata, error)
 	// GetStreamQualities retrieves streaming metadata for audiobook chapters and podcasts episodes.
 	GetStreamQualities(ctx context.Context, streamIDs []string) (map[string]*StreamQualities, error)
@@ -53,6 +61,10 @@ type Client interface {
 	GetTracksMetadata(ctx context.Context, trackIDs []string) (map[string]*Track, error)
 	// GetUserProfile retrieves the user's profile information.
 	GetUserProfile(ctx context.Context) (*UserProfile, error)
+	// GetWave retrieves up to limit tracks of a radio station, or of the personal wave if stationID is empty.
+	GetWave(ctx context.Context, stationID string, limit int) (*Wave, error)
+	// Search finds items of the specified type matching the query.
+	Search(ctx context.Context, query string, searchType SearchType, limit int) ([]*SearchResult, error)
 }
 
 // ClientImpl implements the Client interface for interacting with Zvuk's API.
@@ -61,6 +73,8 @@ type ClientImpl struct {
 	cfg *config.Config
 	// baseURL is the base URL for API requests.
 	baseURL string
+	// graphQLPath is the path of the GraphQL endpoint relative to baseURL.
+	graphQLPath string
 	// httpClient is the HTTP client for making requests.
 	httpClient *http.Client
 	// graphQLClient is the GraphQL client for making queries.
@@ -77,11 +91,35 @@ type ClientImpl struct {
 	audiobooksCache *lru.Cache[string, *Audiobook]
 	// podcastsCache caches podcast metadata to reduce duplicate API calls for the same podcasts.
 	podcastsCache *lru.Cache[string, *Podcast]
+	// apiStats accumulates per-endpoint request counters and cache hit rates.
+	apiStats *apiStatisticsCollector
+}
+
+// bodyLogRules returns the debug logging rules of the API endpoints.
+// Stream metadata bodies contain signed stream URLs and are never logged,
+// GraphQL errors are logged in full to make schema problems easy to diagnose.
+func bodyLogRules(baseURL *url.URL, graphQLPath string) []http_transport.BodyLogRule {
+	// The paths are matched against the whole request path, including the path of a mirror base URL.
+	basePath := strings.TrimRight(baseURL.Path, "/")
+
+	return []http_transport.BodyLogRule{
+		{PathPrefix: basePath + "/" + zvukAPIStreamMetadataURI, IsBodyOmitted: true},
+		{PathPrefix: basePath + "/" + graphQLPath, MaxLength: graphQLBodyLogLength, IsErrorLoggedInFull: true},
+		{PathPrefix: basePath + "/" + zvukAPILyricsURI, MaxLength: lyricsBodyLogLength},
+	}
 }
 
 // NewClient creates and returns a new instance of ClientImpl.
-// It initializes the HTTP and GraphQL clients with the provided configuration.
-func NewClient(cfg *config.Config) (Client, error) {
+// It initializes the HTTP and GraphQL clients with the provided configuration,
+// reading the auth token from the keyring when auth_token_storage is "keyring".
+// The challenge solver is optional: without it, anti-bot challenges fail the request
+// with http_transport.ErrAntiBotChallenge.
+func NewClient(cfg *config.Config, challengeSolver http_transport.ChallengeSolver) (Client, error) {
+	// Read the token from the keyring if it is kept there (auth_token_storage).
+	if err := config.ResolveAuthToken(cfg); err != nil {
+		return nil, fmt.Errorf("failed to get auth token: %w", err)
+	}
+
 	// Create a cookie jar to manage cookies for the HTTP client.
 	cookies, err := cookiejar.New(nil)
 	if err != nil {
@@ -94,6 +132,11 @@ func NewClient(cfg *config.Config) (Client, error) {
 		return nil, fmt.Errorf("invalid host URL: %w", err)
 	}
 
+	graphQLPath := cfg.ZvukGraphQLPath
+	if graphQLPath == "" {
+		graphQLPath = config.DefaultZvukGraphQLPath
+	}
+
 	// Set the authentication cookie.
 	cookie := &http.Cookie{
 		Name:  "auth",
@@ -101,17 +144,33 @@ func NewClient(cfg *config.Config) (Client, error) {
 	}
 	cookies.SetCookies(baseURL, []*http.Cookie{cookie})
 
+	// Restore the cookies of anti-bot challenges solved in previous runs.
+	var cookieStore *http_transport.CookieStore
+	if cfg.AntiBotCookiesPath != "" {
+		cookieStore = http_transport.NewCookieStore(cfg.AntiBotCookiesPath)
+		if err = restoreChallengeCookies(cookieStore, cookies, baseURL.Scheme); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the HTTP client with custom transport and timeout.
 	httpClient := &http.Client{
 		Transport: http_transport.NewUserAgentInjector(
-			http_transport.NewLogTransport(http.DefaultTransport, 0),
+			http_transport.NewChallengeDetector(
+				http_transport.NewLogTransport(
+					http_transport.NewProxyTransport(cfg.ProxyURL, cfg.NoProxy),
+					0,
+					bodyLogRules(baseURL, graphQLPath)...),
+				cookies,
+				cookieStore,
+				challengeSolver),
 			utils.NewSimpleUserAgentProvider(http_transport.DefaultUserAgent)),
 		Jar:     cookies,
 		Timeout: http_transport.DefaultTimeout,
 	}
 
 	// Initialize the GraphQL client.
-	graphQLURL := baseURL.JoinPath(zvukAPIGraphQLURI)
+	graphQLURL := baseURL.JoinPath(graphQLPath)
 	graphqlClient := graphql.NewClient(graphQLURL.String(), graphql.WithHTTPClient(httpClient))
 
 	// Initialize LRU caches for metadata to reduce redundant API calls.
@@ -149,6 +208,7 @@ func NewClient(cfg *config.Config) (Client, error) {
 	client := &ClientImpl{
 		cfg:             cfg,
 		baseURL:         baseURL.String(),
+		graphQLPath:     graphQLPath,
 		httpClient:      httpClient,
 		graphQLClient:   graphqlClient,
 		labelsCache:     labelsCache,
@@ -157,11 +217,26 @@ func NewClient(cfg *config.Config) (Client, error) {
 		playlistsCache:  playlistsCache,
 		audiobooksCache: audiobooksCache,
 		podcastsCache:   podcastsCache,
+		apiStats:        newAPIStatisticsCollector(),
 	}
 
 	return client, nil
 }
 
+// restoreChallengeCookies puts the persisted anti-bot cookies of every host into the cookie jar.
+func restoreChallengeCookies(store *http_transport.CookieStore, jar http.CookieJar, scheme string) error {
+	storedCookies, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load anti-bot cookies: %w", err)
+	}
+
+	for host, hostCookies := range storedCookies {
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: "/"}, hostCookies)
+	}
+
+	return nil
+}
+
 // DownloadFromURL downloads content from the specified URL.
 func (c *ClientImpl) DownloadFromURL(ctx context.Context, url string) (io.ReadCloser, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
@@ -169,7 +244,11 @@ func (c *ClientImpl) DownloadFromURL(ctx context.Context, url string) (io.ReadCl
 		return nil, err
 	}
 
+	startTime := time.Now()
 	response, err := c.httpClient.Do(request)
+
+	c.apiStats.recordRequest(endpointFileDownload, startTime)
+
 	if err != nil {
 		return nil, err
 	}
@@ -193,7 +272,11 @@ func (c *ClientImpl) FetchTrack(ctx context.Context, trackURL string) (*FetchTra
 	// Add a Range header to request partial content.
 	request.Header.Add("Range", "bytes=0-")
 
+	startTime := time.Now()
 	response, err := c.httpClient.Do(request)
+
+	c.apiStats.recordRequest(endpointTrackStream, startTime)
+
 	if err != nil {
 		return nil, err
 	}
@@ -230,11 +313,99 @@ func (c *ClientImpl) GetAlbumURL(releaseID string) (string, error) {
 	return url.JoinPath(c.baseURL, zvukAPIReleaseURIPath, releaseID)
 }
 
+// GetAPIStatistics returns a snapshot of API request counters and cache hit rates.
+func (c *ClientImpl) GetAPIStatistics() *APIStatistics {
+	return c.apiStats.snapshot()
+}
+
 // GetBaseURL returns the base URL of the Zvuk API.
 func (c *ClientImpl) GetBaseURL() string {
 	return c.baseURL
 }
 
+// GetFileSize returns the size of the file at the specified URL without downloading its content.
+// It sends a HEAD request first and falls back to a single-byte ranged GET
+// when the server does not report the content length for HEAD.
+func (c *ClientImpl) GetFileSize(ctx context.Context, fileURL string) (int64, error) {
+	size, err := c.getFileSizeWithHead(ctx, fileURL)
+	if err == nil && size > 0 {
+		return size, nil
+	}
+
+	return c.getFileSizeWithRange(ctx, fileURL)
+}
+
+// getFileSizeWithHead reads the content length from a HEAD response.
+func (c *ClientImpl) getFileSizeWithHead(ctx context.Context, fileURL string) (int64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, fileURL, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+
+	startTime := time.Now()
+	response, err := c.httpClient.Do(request)
+
+	c.apiStats.recordRequest(endpointFileSize, startTime)
+
+	if err != nil {
+		return 0, err
+	}
+
+	response.Body.Close() //nolint:gosec // Error on close is not critical here.
+
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%w: %d", ErrUnexpectedHTTPStatus, response.StatusCode)
+	}
+
+	return response.ContentLength, nil
+}
+
+// getFileSizeWithRange requests the first byte of the file and reads the total size from Content-Range.
+func (c *ClientImpl) getFileSizeWithRange(ctx context.Context, fileURL string) (int64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Add("Range", "bytes=0-0")
+
+	startTime := time.Now()
+	response, err := c.httpClient.Do(request)
+
+	c.apiStats.recordRequest(endpointFileSize, startTime)
+
+	if err != nil {
+		return 0, err
+	}
+
+	response.Body.Close() //nolint:gosec // Error on close is not critical here.
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range has the "bytes 0-0/12345" format.
+		_, totalSize, found := strings.Cut(response.Header.Get("Content-Range"), "/")
+		if !found {
+			return 0, ErrFileSizeUnknown
+		}
+
+		size, parseErr := strconv.ParseInt(strings.TrimSpace(totalSize), 10, 64)
+		if parseErr != nil || size <= 0 {
+			return 0, ErrFileSizeUnknown
+		}
+
+		return size, nil
+	case http.StatusOK:
+		// The server ignored the Range header and reported the full length.
+		if response.ContentLength <= 0 {
+			return 0, ErrFileSizeUnknown
+		}
+
+		return response.ContentLength, nil
+	default:
+		return 0, fmt.Errorf("%w: %d", ErrUnexpectedHTTPStatus, response.StatusCode)
+	}
+}
+
 // GetLabelsMetadata retrieves metadata for the specified label IDs.
 // Uses an LRU cache to avoid redundant API calls for the same labels.
 func (c *ClientImpl) GetLabelsMetadata(ctx context.Context, labelIDs []string) (map[string]*Label, error) {
@@ -243,7 +414,10 @@ func (c *ClientImpl) GetLabelsMetadata(ctx context.Context, labelIDs []string) (
 
 	// Check cache first for each label ID.
 	for _, id := range labelIDs {
-		if cached, ok := c.labelsCache.Get(id); ok {
+		cached, ok := c.labelsCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNameLabels, ok)
+
+		if ok {
 			result[id] = cached
 			logger.Debugf(ctx, "Label cache hit for ID: %s", id)
 		} else {
@@ -285,7 +459,10 @@ func (c *ClientImpl) GetPlaylistsMetadata(
 
 	// Check cache first for each playlist ID.
 	for _, id := range playlistIDs {
-		if cached, ok := c.playlistsCache.Get(id); ok {
+		cached, ok := c.playlistsCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNamePlaylists, ok)
+
+		if ok {
 			playlists[id] = cached
 			logger.Debugf(ctx, "Playlist cache hit for ID: %s", id)
 		} else {
@@ -340,7 +517,10 @@ func (c *ClientImpl) GetAudiobooksMetadata(
 
 	// Check cache first for each audiobook ID.
 	for _, id := range audiobookIDs {
-		if cached, ok := c.audiobooksCache.Get(id); ok {
+		cached, ok := c.audiobooksCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNameAudiobooks, ok)
+
+		if ok {
 			audiobooks[id] = cached
 			logger.Debugf(ctx, "Audiobook cache hit for ID: %s", id)
 		} else {
@@ -393,7 +573,10 @@ func (c *ClientImpl) GetPodcastsMetadata(
 
 	// Check cache first for each podcast ID.
 	for _, id := range podcastIDs {
-		if cached, ok := c.podcastsCache.Get(id); ok {
+		cached, ok := c.podcastsCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNamePodcasts, ok)
+
+		if ok {
 			podcasts[id] = cached
 			logger.Debugf(ctx, "Podcast cache hit for ID: %s", id)
 		} else {
@@ -451,6 +634,9 @@ func (c *ClientImpl) GetStreamMetadata(ctx context.Context, trackID, quality str
 		)
 		if err == nil {
 			result = fetchResult.Data.Result
+			if result == nil || result.Stream == "" {
+				return nil, fmt.Errorf("%w: track %s in %s", ErrNoStreamAvailable, trackID, quality)
+			}
 
 			break
 		}
@@ -494,7 +680,10 @@ func (c *ClientImpl) GetTracksMetadata(ctx context.Context, trackIDs []string) (
 
 	// Check cache first for each track ID.
 	for _, id := range trackIDs {
-		if cached, ok := c.tracksCache.Get(id); ok {
+		cached, ok := c.tracksCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNameTracks, ok)
+
+		if ok {
 			result[id] = cached
 			logger.Debugf(ctx, "Track cache hit for ID: %s", id)
 		} else {
@@ -534,24 +723,78 @@ func (c *ClientImpl) GetUserProfile(ctx context.Context) (*UserProfile, error) {
 	return result.Data.Result, nil
 }
 
+// getEntitiesMetadata fetches metadata of the entities, splitting long ID lists
+// into batches of metadata_batch_size so the query string stays within URL limits.
+// The results of all batches are merged into one.
 func (c *ClientImpl) getEntitiesMetadata(
 	ctx context.Context,
 	entityURI string,
 	entityIDs []string,
 	query url.Values,
 ) (*Metadata, error) {
-	if len(query) == 0 {
-		query = url.Values{}
+	batchSize := int(c.cfg.MetadataBatchSize)
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataBatchSize
 	}
 
-	query.Set("ids", strings.Join(entityIDs, ","))
+	var result *Metadata
 
-	result, err := fetchJSONWithQuery[GetMetadataResponse](c, ctx, entityURI, query)
-	if err != nil {
-		return nil, err
+	for batch := range slices.Chunk(entityIDs, batchSize) {
+		batchQuery := maps.Clone(query)
+		if batchQuery == nil {
+			batchQuery = url.Values{}
+		}
+
+		batchQuery.Set("ids", strings.Join(batch, ","))
+
+		response, err := fetchJSONWithQuery[GetMetadataResponse](c, ctx, entityURI, batchQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		result = mergeMetadata(result, response.Data.Result)
 	}
 
-	return result.Data.Result, nil
+	if result == nil {
+		result = new(Metadata)
+	}
+
+	return result, nil
+}
+
+// mergeMetadata adds the entities of src to dst and returns dst (or src if dst is nil).
+func mergeMetadata(dst, src *Metadata) *Metadata {
+	if dst == nil {
+		return src
+	}
+
+	if src == nil {
+		return dst
+	}
+
+	dst.Tracks = mergeEntityMaps(dst.Tracks, src.Tracks)
+	dst.Playlists = mergeEntityMaps(dst.Playlists, src.Playlists)
+	dst.Releases = mergeEntityMaps(dst.Releases, src.Releases)
+	dst.Audiobooks = mergeEntityMaps(dst.Audiobooks, src.Audiobooks)
+	dst.Podcasts = mergeEntityMaps(dst.Podcasts, src.Podcasts)
+	dst.Labels = mergeEntityMaps(dst.Labels, src.Labels)
+
+	return dst
+}
+
+// mergeEntityMaps copies src into dst, creating dst if needed.
+func mergeEntityMaps[V any](dst, src map[string]*V) map[string]*V {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		return src
+	}
+
+	maps.Copy(dst, src)
+
+	return dst
 }
 
 // getAlbumsMetadataWithTracks fetches album metadata including tracks without caching.
@@ -585,7 +828,10 @@ func (c *ClientImpl) getAlbumsMetadataFromCache(
 
 	// Check cache first for each album ID.
 	for _, id := range releaseIDs {
-		if cached, ok := c.albumsCache.Get(id); ok {
+		cached, ok := c.albumsCache.Get(id)
+		c.apiStats.recordCacheLookup(cacheNameAlbums, ok)
+
+		if ok {
 			releases[id] = cached
 			logger.Debugf(ctx, "Album cache hit for ID: %s", id)
 		} else {
@@ -3,6 +3,7 @@ package zvuk
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -316,7 +317,7 @@ func TestNewClient(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			client, err := NewClient(tt.config)
+			client, err := NewClient(tt.config, nil)
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -565,3 +566,289 @@ func TestModels(t *testing.T) {
 	assert.NotNil(t, profile.Subscription)
 	assert.Equal(t, "Premium", profile.Subscription.Title)
 }
+
+// TestNewClient_CustomEndpoint tests that requests go to the configured base URL and GraphQL path.
+func TestNewClient_CustomEndpoint(t *testing.T) {
+	t.Parallel()
+
+	var requestedPaths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/mirror/gql" {
+			//nolint:errcheck // Test mock handler, error is not critical.
+			w.Write([]byte(`{"data":{"search":{"releases":{"items":[]}}}}`))
+
+			return
+		}
+
+		//nolint:errcheck // Test mock handler, error is not critical.
+		w.Write([]byte(`{"result":{"labels":{}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{ZvukBaseURL: server.URL + "/mirror", ZvukGraphQLPath: "gql"}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.Search(ctx, "mutter", SearchTypeAlbum, 5)
+	require.NoError(t, err)
+
+	_, err = client.GetLabelsMetadata(ctx, []string{"label1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/mirror/gql", "/mirror/" + zvukAPILabelURI}, requestedPaths)
+	assert.Contains(t, client.GetAPIStatistics().Endpoints, "gql (search)")
+}
+
+// TestClientImpl_GetAPIStatistics tests that requests and cache lookups are counted.
+func TestClientImpl_GetAPIStatistics(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck // Test mock handler, error is not critical.
+		w.Write([]byte(`{"result":{"labels":{"label1":{"title":"Test Label"}}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{ZvukBaseURL: server.URL}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// The first call misses the cache, the second one is served from it.
+	_, err = client.GetLabelsMetadata(ctx, []string{"label1"})
+	require.NoError(t, err)
+
+	_, err = client.GetLabelsMetadata(ctx, []string{"label1"})
+	require.NoError(t, err)
+
+	stats := client.GetAPIStatistics()
+	require.NotNil(t, stats)
+
+	require.Contains(t, stats.Endpoints, zvukAPILabelURI)
+	assert.Equal(t, int64(1), stats.Endpoints[zvukAPILabelURI].Requests)
+	assert.Equal(t, int64(1), stats.TotalRequests())
+
+	require.Contains(t, stats.Caches, cacheNameLabels)
+	assert.Equal(t, int64(1), stats.Caches[cacheNameLabels].Hits)
+	assert.Equal(t, int64(1), stats.Caches[cacheNameLabels].Misses)
+	assert.InDelta(t, 50.0, stats.Caches[cacheNameLabels].HitRate(), 0.001)
+}
+
+// TestClientImpl_GetFileSize tests that file sizes are probed via HEAD with a ranged GET fallback.
+func TestClientImpl_GetFileSize(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/head":
+			assert.Equal(t, http.MethodHead, r.Method)
+			w.Header().Set("Content-Length", "4096")
+		case "/range":
+			// HEAD is not supported, only a ranged GET reports the size.
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+			w.Header().Set("Content-Range", "bytes 0-0/8192")
+			w.WriteHeader(http.StatusPartialContent)
+			//nolint:errcheck // Test mock handler, error is not critical.
+			w.Write([]byte("x"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{ZvukBaseURL: server.URL}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	size, err := client.GetFileSize(ctx, server.URL+"/head")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4096), size)
+
+	size, err = client.GetFileSize(ctx, server.URL+"/range")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8192), size)
+
+	_, err = client.GetFileSize(ctx, server.URL+"/missing")
+	require.ErrorIs(t, err, ErrUnexpectedHTTPStatus)
+
+	stats := client.GetAPIStatistics()
+	require.Contains(t, stats.Endpoints, endpointFileSize)
+	assert.Zero(t, stats.TotalAPIDuration())
+}
+
+// TestClientImpl_GetLabelsMetadata_Batching verifies that long ID lists are split
+// into metadata_batch_size chunks and the results are merged.
+func TestClientImpl_GetLabelsMetadata_Batching(t *testing.T) {
+	t.Parallel()
+
+	var requestedIDs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query().Get("ids")
+		requestedIDs = append(requestedIDs, ids)
+
+		labels := make(map[string]*Label)
+		for id := range strings.SplitSeq(ids, ",") {
+			labels[id] = &Label{Title: "Label " + id}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"labels": labels}})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{
+		AuthToken:           "test_token",
+		ZvukBaseURL:         server.URL,
+		MetadataBatchSize:   2,
+		RetryAttemptsCount:  1,
+		ParsedMaxRetryPause: 1000000,
+		ParsedMinRetryPause: 1000000,
+	}, nil)
+	require.NoError(t, err)
+
+	labels, err := client.GetLabelsMetadata(context.Background(), []string{"1", "2", "3", "4", "5"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1,2", "3,4", "5"}, requestedIDs)
+	assert.Len(t, labels, 5)
+	assert.Equal(t, "Label 5", labels["5"].Title)
+}
+
+// TestClientImpl_Search verifies that search results are parsed and linked to their pages.
+func TestClientImpl_Search(t *testing.T) {
+	t.Parallel()
+
+	var requestBody struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&requestBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":{"search":{"releases":{"items":[
+			{"id":"123","title":"Mutter","date":"2001-04-02","artists":[{"title":"Rammstein"}]},
+			{"title":"Without ID"}
+		]}}}}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{
+		AuthToken:   "test_token",
+		ZvukBaseURL: server.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	results, err := client.Search(context.Background(), "mutter", SearchTypeAlbum, 5)
+	require.NoError(t, err)
+
+	assert.Contains(t, requestBody.Query, "releases(limit: $limit)")
+	assert.Equal(t, "mutter", requestBody.Variables["query"])
+	assert.InDelta(t, 5, requestBody.Variables["limit"], 0)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, &SearchResult{
+		Type:        SearchTypeAlbum,
+		ID:          "123",
+		Title:       "Mutter",
+		ArtistNames: []string{"Rammstein"},
+		Date:        "2001-04-02",
+		URL:         server.URL + "/release/123",
+	}, results[0])
+
+	_, err = client.Search(context.Background(), "mutter", SearchType("genre"), 5)
+	require.ErrorIs(t, err, ErrUnknownSearchType)
+}
+
+// TestClientImpl_GetWave tests that the wave is requested until it stops handing out new tracks.
+func TestClientImpl_GetWave(t *testing.T) {
+	t.Parallel()
+
+	pages := []string{
+		`[{"id":"1"},{"id":"2"},{"id":"3"}]`,
+		`[{"id":"3"},{"id":"4"}]`,
+		`[{"id":"4"}]`,
+	}
+
+	var (
+		requestsCount int
+		stationIDs    []any
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestBody struct {
+			Variables map[string]any `json:"variables"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&requestBody)
+		stationIDs = append(stationIDs, requestBody.Variables["stationId"])
+
+		page := pages[min(requestsCount, len(pages)-1)]
+		requestsCount++
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":{"wave":{"title":"My Wave","tracks":`+page+`}}}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{
+		AuthToken:   "test_token",
+		ZvukBaseURL: server.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	wave, err := client.GetWave(context.Background(), "", 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Wave{Title: "My Wave", TrackIDs: []int64{1, 2, 3, 4}}, wave)
+	assert.Equal(t, 3, requestsCount)
+	assert.Equal(t, []any{nil, nil, nil}, stationIDs)
+}
+
+// TestClientImpl_GetUserProfile_RejectedToken tests that only 401 and 403 answers are reported as a rejected token.
+func TestClientImpl_GetUserProfile_RejectedToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		isRejected bool
+	}{
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, isRejected: true},
+		{name: "forbidden", statusCode: http.StatusForbidden, isRejected: true},
+		{name: "server error", statusCode: http.StatusBadGateway, isRejected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(&config.Config{ZvukBaseURL: server.URL}, nil)
+			require.NoError(t, err)
+
+			_, err = client.GetUserProfile(context.Background())
+			require.ErrorIs(t, err, ErrUnexpectedHTTPStatus)
+			assert.Equal(t, tt.isRejected, errors.Is(err, ErrAuthTokenRejected))
+		})
+	}
+}
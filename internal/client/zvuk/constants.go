@@ -7,8 +7,6 @@ const (
 )
 
 const (
-	// zvukAPIGraphQLURI is the URI path for GraphQL API endpoint.
-	zvukAPIGraphQLURI = "api/v1/graphql"
 	// zvukAPILabelURI is the URI path for label metadata endpoint.
 	zvukAPILabelURI = "api/tiny/labels"
 	// zvukAPILyricsURI is the URI path for lyrics endpoint.
@@ -25,6 +23,18 @@ const (
 	zvukAPIUserProfileURI = "api/v2/tiny/profile"
 )
 
+// maxWavePages is the largest number of wave requests made to collect a sample.
+// A wave hands out new tracks on every request, but may run out of them.
+const maxWavePages = 20
+
+const (
+	// graphQLBodyLogLength is the maximum logged length of successful GraphQL bodies.
+	// Responses with GraphQL errors are logged in full.
+	graphQLBodyLogLength = 4 * 1024
+	// lyricsBodyLogLength is the maximum logged length of lyrics bodies.
+	lyricsBodyLogLength = 512
+)
+
 const (
 	// labelsCacheSize defines the maximum number of label entries to cache.
 	// Approximately 500 unique labels exist globally across all music.
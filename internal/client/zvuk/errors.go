@@ -1,10 +1,19 @@
 package zvuk
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
+	// ErrAPIFormatChanged is returned when an API response no longer has the format zvuk-grabber expects.
+	ErrAPIFormatChanged = errors.New("zvuk API response format changed, please update zvuk-grabber")
+	// ErrResponseFieldMissing is returned when a field read from an API response is missing.
+	ErrResponseFieldMissing = errors.New("response field is missing")
 	// ErrUnexpectedHTTPStatus indicates an unexpected HTTP status code was received.
 	ErrUnexpectedHTTPStatus = errors.New("unexpected HTTP status")
+	// ErrAuthTokenRejected is returned when Zvuk answers 401 or 403, rejecting the auth token.
+	ErrAuthTokenRejected = errors.New("auth token was rejected")
 	// ErrTrackIDMissing is returned when track data does not contain an ID.
 	ErrTrackIDMissing = errors.New("track ID is missing")
 	// ErrTrackReleaseDataMissing is returned when track data does not contain release object.
@@ -14,21 +23,34 @@ var (
 	// ErrArtistNotFound indicates that the requested artist was not found.
 	ErrArtistNotFound = errors.New("artist not found")
 	// ErrUnexpectedArtistResponseFormat indicates an unexpected artist API response format.
-	ErrUnexpectedArtistResponseFormat = errors.New("unexpected artist response format")
+	ErrUnexpectedArtistResponseFormat = fmt.Errorf("%w: unexpected artist response format", ErrAPIFormatChanged)
 	// ErrUnexpectedReleasesResponseFormat indicates an unexpected releases API response format.
-	ErrUnexpectedReleasesResponseFormat = errors.New("unexpected releases response format")
+	ErrUnexpectedReleasesResponseFormat = fmt.Errorf("%w: unexpected releases response format", ErrAPIFormatChanged)
 	// ErrFailedToFetchStreamMetadata indicates failure to fetch stream metadata after all retry attempts.
 	ErrFailedToFetchStreamMetadata = errors.New("failed to fetch stream metadata after retries")
+	// ErrNoStreamAvailable is returned when Zvuk answers the stream request with an empty result:
+	// the track has no stream in the requested quality.
+	ErrNoStreamAvailable = errors.New("no stream is available")
 	// ErrAudiobookNotFound is returned when audiobook is not found in GraphQL response.
 	ErrAudiobookNotFound = errors.New("audiobook not found or unexpected response format")
 	// ErrUnexpectedAudiobookFormat is returned when audiobook response has unexpected format.
-	ErrUnexpectedAudiobookFormat = errors.New("unexpected audiobook response format")
+	ErrUnexpectedAudiobookFormat = fmt.Errorf("%w: unexpected audiobook response format", ErrAPIFormatChanged)
 	// ErrUnexpectedMediaContentsFormat is returned when mediaContents response has unexpected format.
-	ErrUnexpectedMediaContentsFormat = errors.New("unexpected mediaContents response format")
+	ErrUnexpectedMediaContentsFormat = fmt.Errorf("%w: unexpected mediaContents response format", ErrAPIFormatChanged)
 	// ErrUnexpectedTracksResponseFormat is returned when getTracks response has unexpected format.
-	ErrUnexpectedTracksResponseFormat = errors.New("unexpected tracks response format")
+	ErrUnexpectedTracksResponseFormat = fmt.Errorf("%w: unexpected tracks response format", ErrAPIFormatChanged)
 	// ErrPodcastNotFound is returned when podcast is not found in GraphQL response.
 	ErrPodcastNotFound = errors.New("podcast not found or unexpected response format")
 	// ErrUnexpectedPodcastFormat is returned when podcast response has unexpected format.
-	ErrUnexpectedPodcastFormat = errors.New("unexpected podcast response format")
+	ErrUnexpectedPodcastFormat = fmt.Errorf("%w: unexpected podcast response format", ErrAPIFormatChanged)
+	// ErrUnexpectedJSONToken is returned when a streamed JSON response does not have the expected structure.
+	ErrUnexpectedJSONToken = errors.New("unexpected JSON token")
+	// ErrUnknownSearchType is returned when the search type is not supported.
+	ErrUnknownSearchType = errors.New("unknown search type")
+	// ErrUnexpectedSearchResponseFormat is returned when search response has unexpected format.
+	ErrUnexpectedSearchResponseFormat = fmt.Errorf("%w: unexpected search response format", ErrAPIFormatChanged)
+	// ErrUnexpectedWaveResponseFormat is returned when wave response has unexpected format.
+	ErrUnexpectedWaveResponseFormat = fmt.Errorf("%w: unexpected wave response format", ErrAPIFormatChanged)
+	// ErrFileSizeUnknown is returned when the server does not report the size of a file.
+	ErrFileSizeUnknown = errors.New("file size is unknown")
 )
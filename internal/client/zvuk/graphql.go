@@ -1,9 +1,12 @@
 package zvuk
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/machinebox/graphql"
 
@@ -33,7 +36,7 @@ func (c *ClientImpl) GetArtistReleaseIDs(ctx context.Context, artistID string, o
 	graphqlRequest.Var("limit", limit)
 
 	var graphQLResponse map[string]any
-	if err := c.graphQLClient.Run(ctx, graphqlRequest, &graphQLResponse); err != nil {
+	if err := c.runGraphQL(ctx, "getArtistReleases", graphqlRequest, &graphQLResponse); err != nil {
 		return nil, err
 	}
 
@@ -69,6 +72,179 @@ func (c *ClientImpl) GetArtistReleaseIDs(ctx context.Context, artistID string, o
 	return releaseIDs, nil
 }
 
+// GetWave retrieves up to limit tracks of a radio station, or of the personal wave if stationID is empty.
+// The wave is requested again until it hands out enough tracks or stops handing out new ones.
+func (c *ClientImpl) GetWave(ctx context.Context, stationID string, limit int) (*Wave, error) {
+	var (
+		result = new(Wave)
+		seen   = make(map[int64]struct{}, limit)
+	)
+
+	for range maxWavePages {
+		title, trackIDs, err := c.getWavePage(ctx, stationID, limit-len(result.TrackIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		result.Title = cmp.Or(result.Title, title)
+
+		added := 0
+
+		for _, trackID := range trackIDs {
+			if _, ok := seen[trackID]; ok || len(result.TrackIDs) >= limit {
+				continue
+			}
+
+			seen[trackID] = struct{}{}
+			result.TrackIDs = append(result.TrackIDs, trackID)
+			added++
+		}
+
+		if added == 0 || len(result.TrackIDs) >= limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// getWavePage requests the next tracks of the wave.
+func (c *ClientImpl) getWavePage(ctx context.Context, stationID string, limit int) (string, []int64, error) {
+	graphqlRequest := graphql.NewRequest(`
+		query getWave($stationId: ID, $limit: Int!) {
+			wave(stationId: $stationId) {
+				title
+				tracks(limit: $limit) {
+					id
+				}
+			}
+		}
+	`)
+
+	graphqlRequest.Header.Add("X-Auth-Token", c.cfg.AuthToken)
+	graphqlRequest.Var("limit", limit)
+
+	// The personal wave is requested without a station.
+	if stationID != "" {
+		graphqlRequest.Var("stationId", stationID)
+	}
+
+	var graphQLResponse map[string]any
+	if err := c.runGraphQL(ctx, "getWave", graphqlRequest, &graphQLResponse); err != nil {
+		return "", nil, err
+	}
+
+	waveData, ok := graphQLResponse["wave"].(map[string]any)
+	if !ok {
+		return "", nil, ErrUnexpectedWaveResponseFormat
+	}
+
+	title, _ := waveData["title"].(string)
+	tracks, _ := waveData["tracks"].([]any)
+	trackIDs := make([]int64, 0, len(tracks))
+
+	for _, trackData := range tracks {
+		trackMap, hasExpectedFormat := trackData.(map[string]any)
+		if !hasExpectedFormat {
+			continue
+		}
+
+		id, _ := trackMap["id"].(string)
+		if trackID, err := strconv.ParseInt(id, 10, 64); err == nil {
+			trackIDs = append(trackIDs, trackID)
+		}
+	}
+
+	return title, trackIDs, nil
+}
+
+// searchQuery describes how items of a search type are requested and linked.
+type searchQuery struct {
+	// field is the search response field holding the items.
+	field string
+	// selection lists the item fields to request.
+	selection string
+	// pathSegment is the site path segment of the item pages.
+	pathSegment string
+}
+
+// searchQueries maps every supported search type to its query.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var searchQueries = map[SearchType]searchQuery{
+	SearchTypeTrack:    {field: "tracks", selection: "id title artists { title }", pathSegment: "track"},
+	SearchTypeAlbum:    {field: "releases", selection: "id title date artists { title }", pathSegment: "release"},
+	SearchTypeArtist:   {field: "artists", selection: "id title", pathSegment: "artist"},
+	SearchTypePlaylist: {field: "playlists", selection: "id title", pathSegment: "playlist"},
+}
+
+// Search finds items of the specified type matching the query.
+func (c *ClientImpl) Search(
+	ctx context.Context,
+	query string,
+	searchType SearchType,
+	limit int,
+) ([]*SearchResult, error) {
+	searchQuery, ok := searchQueries[searchType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSearchType, searchType)
+	}
+
+	graphqlRequest := graphql.NewRequest(fmt.Sprintf(`
+		query search($query: String, $limit: Int) {
+			search(query: $query) {
+				%s(limit: $limit) {
+					items {
+						%s
+					}
+				}
+			}
+		}
+	`, searchQuery.field, searchQuery.selection))
+
+	graphqlRequest.Header.Add("X-Auth-Token", c.cfg.AuthToken)
+	graphqlRequest.Var("query", query)
+	graphqlRequest.Var("limit", limit)
+
+	var graphQLResponse map[string]any
+	if err := c.runGraphQL(ctx, "search", graphqlRequest, &graphQLResponse); err != nil {
+		return nil, err
+	}
+
+	searchData, ok := graphQLResponse["search"].(map[string]any)
+	if !ok {
+		return nil, ErrUnexpectedSearchResponseFormat
+	}
+
+	// A query without matches may return null instead of an empty list.
+	itemsData, _ := searchData[searchQuery.field].(map[string]any)
+	items, _ := itemsData["items"].([]any)
+
+	results := make([]*SearchResult, 0, len(items))
+
+	for _, itemData := range items {
+		itemMap, hasExpectedFormat := itemData.(map[string]any)
+		if !hasExpectedFormat {
+			continue
+		}
+
+		result := parseSearchResult(itemMap, searchType)
+		if result == nil {
+			continue
+		}
+
+		itemURL, err := url.JoinPath(c.baseURL, searchQuery.pathSegment, result.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result.URL = itemURL
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // getAudiobookViaGraphQL fetches a single audiobook with its tracks.
 //
 //nolint:funlen // GraphQL query requires length.
@@ -126,7 +302,7 @@ func (c *ClientImpl) getAudiobookViaGraphQL(
 	graphqlRequest.Var("ids", []string{audiobookID})
 
 	var graphQLResponse map[string]any
-	if err := c.graphQLClient.Run(ctx, graphqlRequest, &graphQLResponse); err != nil {
+	if err := c.runGraphQL(ctx, "getBookChapters", graphqlRequest, &graphQLResponse); err != nil {
 		return nil, err
 	}
 
@@ -222,7 +398,7 @@ func (c *ClientImpl) GetStreamQualities(
 	graphqlRequest.Var("includeFlacDrm", true)
 
 	var graphQLResponse map[string]any
-	if err := c.graphQLClient.Run(ctx, graphqlRequest, &graphQLResponse); err != nil {
+	if err := c.runGraphQL(ctx, "getStream", graphqlRequest, &graphQLResponse); err != nil {
 		return nil, err
 	}
 
@@ -324,7 +500,7 @@ func (c *ClientImpl) getTracksViaGraphQL(ctx context.Context, trackIDs []string)
 	graphqlRequest.Var("ids", trackIDs)
 
 	var graphQLResponse map[string]any
-	if err := c.graphQLClient.Run(ctx, graphqlRequest, &graphQLResponse); err != nil {
+	if err := c.runGraphQL(ctx, "getTracks", graphqlRequest, &graphQLResponse); err != nil {
 		return nil, err
 	}
 
@@ -413,7 +589,7 @@ func (c *ClientImpl) getPodcastViaGraphQL(
 	graphqlRequest.Var("ids", []string{podcastID})
 
 	var graphQLResponse map[string]any
-	if err := c.graphQLClient.Run(ctx, graphqlRequest, &graphQLResponse); err != nil {
+	if err := c.runGraphQL(ctx, "getPodcastEpisodes", graphqlRequest, &graphQLResponse); err != nil {
 		return nil, err
 	}
 
@@ -460,3 +636,18 @@ func (c *ClientImpl) getPodcastViaGraphQL(
 		Tracks:  tracks,
 	}, nil
 }
+
+// runGraphQL executes the GraphQL request and records it in the API statistics under the operation name.
+func (c *ClientImpl) runGraphQL(
+	ctx context.Context,
+	operationName string,
+	request *graphql.Request,
+	response any,
+) error {
+	startTime := time.Now()
+	err := c.graphQLClient.Run(ctx, request, response)
+
+	c.apiStats.recordRequest(c.graphQLPath+" ("+operationName+")", startTime)
+
+	return err
+}
@@ -1,11 +1,14 @@
 package zvuk
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // fetchJSON fetches JSON from the specified URI.
@@ -38,7 +41,11 @@ func fetchJSONWithQuery[T any](
 		request.URL.RawQuery = query.Encode()
 	}
 
+	startTime := time.Now()
 	response, err := c.httpClient.Do(request)
+
+	c.apiStats.recordRequest(uri, startTime)
+
 	if err != nil {
 		return nil, err
 	}
@@ -46,14 +53,53 @@ func fetchJSONWithQuery[T any](
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("%w: %d", ErrUnexpectedHTTPStatus, response.StatusCode)
+		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+			err = fmt.Errorf("%w: %w", ErrAuthTokenRejected, err)
+		}
+
 		return &FetchJSONResult[T]{
 			Data:       nil,
 			StatusCode: response.StatusCode,
-		}, fmt.Errorf("%w: %d", ErrUnexpectedHTTPStatus, response.StatusCode)
+		}, err
+	}
+
+	var (
+		result  T
+		body    io.Reader = response.Body
+		payload *bytes.Buffer
+	)
+
+	// The raw response is only kept when it may have to be dumped.
+	if c.cfg.APIDumpPath != "" {
+		payload = new(bytes.Buffer)
+		body = io.TeeReader(response.Body, payload)
+	}
+
+	decoder := json.NewDecoder(body)
+
+	// Large metadata responses are decoded incrementally to keep peak memory low.
+	if streamed, ok := any(&result).(streamDecoder); ok {
+		err = streamed.decodeStream(decoder)
+	} else {
+		err = decoder.Decode(&result)
+	}
+
+	if checker, ok := any(&result).(schemaChecker); ok && err == nil {
+		err = checker.checkSchema(c.cfg.StrictAPIDecoding)
 	}
 
-	var result T
-	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err != nil && isFormatChange(err) {
+		err = fmt.Errorf("%w: %s: %w", ErrAPIFormatChanged, uri, err)
+
+		if payload != nil {
+			// Read the rest of the body, so the whole response is dumped.
+			_, _ = io.Copy(io.Discard, body) //nolint:errcheck // Whatever was read is dumped.
+			c.dumpResponse(ctx, uri, payload)
+		}
+	}
+
+	if err != nil {
 		return &FetchJSONResult[T]{
 			Data:       nil,
 			StatusCode: response.StatusCode,
@@ -0,0 +1,637 @@
+package zvuk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"maps"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// mockAudioPath is the path of the synthetic audio files, followed by the stream type.
+	mockAudioPath = "/mock/"
+	// mockCoverPath is the path of the synthetic covers, followed by the ID of the release or playlist.
+	mockCoverPath = "/mock/covers/"
+	// mockCoverSize is the width and the height of the synthetic covers.
+	mockCoverSize = 300
+
+	// mockFLACSampleRate is the sample rate of the synthetic FLAC files.
+	mockFLACSampleRate = 44100
+	// mockFLACBlockSize is the block size of the synthetic FLAC files.
+	mockFLACBlockSize = 4096
+	// mockFLACAudioSize is the size of the audio frames of the synthetic FLAC files.
+	mockFLACAudioSize = 64 * 1024
+	// mockMP3FrameSamples is the number of samples of an MPEG-1 Layer III frame.
+	mockMP3FrameSamples = 1152
+
+	// mockQualityHigh is the quality parameter requesting MP3 320 kbps.
+	mockQualityHigh = "high"
+	// mockQualityFLAC is the quality parameter requesting FLAC.
+	mockQualityFLAC = "flac"
+)
+
+// mockGraphQLOperationPattern extracts the operation name of a GraphQL query.
+var mockGraphQLOperationPattern = regexp.MustCompile(`query\s+(\w+)`)
+
+// mockMP3Frames describes the synthetic MP3 frames of every stream quality: the MPEG-1 Layer III
+// frame header at 44.1 kHz and the frame size.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var mockMP3Frames = map[string]struct {
+	header []byte
+	size   int
+}{
+	"stream":   {header: []byte{0xFF, 0xFB, 0x90, 0x64}, size: 417},
+	"streamhq": {header: []byte{0xFF, 0xFB, 0xE0, 0x64}, size: 1044},
+}
+
+// MockServer serves a small synthetic catalog through the endpoints of the Zvuk API used by zvuk-grabber,
+// so the whole pipeline can be exercised offline, in demos and tests.
+// Every request is accepted, whatever the auth token.
+type MockServer struct {
+	// graphQLPath is the path of the GraphQL endpoint.
+	graphQLPath string
+	// catalog is the synthetic catalog.
+	catalog *mockCatalog
+	// cover is the JPEG served for every cover.
+	cover []byte
+}
+
+// mockArtist is an artist of the synthetic catalog.
+type mockArtist struct {
+	// id is the unique identifier of the artist.
+	id int64
+	// name is the name of the artist.
+	name string
+	// releaseIDs are the releases of the artist, newest first.
+	releaseIDs []int64
+}
+
+// mockCatalog is the synthetic catalog of the mock server.
+type mockCatalog struct {
+	// artists maps artist IDs to artists.
+	artists map[string]*mockArtist
+	// releases maps release IDs to releases.
+	releases map[string]*Release
+	// tracks maps track IDs to tracks.
+	tracks map[string]*Track
+	// playlists maps playlist IDs to playlists.
+	playlists map[string]*Playlist
+	// labels maps label IDs to labels.
+	labels map[string]*Label
+}
+
+// NewMockServer creates a mock server with the GraphQL endpoint at graphQLPath.
+//
+// The catalog has two artists (IDs 100 and 200), three releases (1001, 1002, and 2001),
+// their tracks (the release ID followed by the two-digit position, e.g. 100101), and a playlist (3001).
+// Tracks of release 1002 are only available in MP3.
+func NewMockServer(graphQLPath string) *MockServer {
+	return &MockServer{
+		graphQLPath: "/" + strings.Trim(graphQLPath, "/"),
+		catalog:     newMockCatalog(),
+		cover:       newMockCover(),
+	}
+}
+
+// Handler returns the HTTP handler of the mock server.
+func (m *MockServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /"+zvukAPIReleaseMetadataURI, m.handleReleases)
+	mux.HandleFunc("GET /"+zvukAPIPlaylistURI, m.handlePlaylists)
+	mux.HandleFunc("GET /"+zvukAPILabelURI, m.handleLabels)
+	mux.HandleFunc("GET /"+zvukAPIStreamMetadataURI, m.handleStreamMetadata)
+	mux.HandleFunc("GET /"+zvukAPILyricsURI, m.handleLyrics)
+	mux.HandleFunc("GET /"+zvukAPIUserProfileURI, m.handleUserProfile)
+	mux.HandleFunc("POST "+m.graphQLPath, m.handleGraphQL)
+	mux.HandleFunc("GET "+mockCoverPath+"{file}", m.handleCover)
+	mux.HandleFunc("GET "+mockAudioPath+"{stream}", m.handleAudio)
+
+	return mux
+}
+
+// newMockCatalog builds the synthetic catalog.
+func newMockCatalog() *mockCatalog {
+	catalog := &mockCatalog{
+		artists:   make(map[string]*mockArtist),
+		releases:  make(map[string]*Release),
+		tracks:    make(map[string]*Track),
+		playlists: make(map[string]*Playlist),
+		labels:    map[string]*Label{"4001": {Title: "Mock Records"}},
+	}
+
+	releases := []struct {
+		id         int64
+		artistID   int64
+		artistName string
+		kind       string
+		title      string
+		date       int64
+		hasFLAC    bool
+		trackNames []string
+	}{
+		{
+			id: 1001, artistID: 100, artistName: "The Mock Band", kind: "album", title: "First Light",
+			date: 20240315, hasFLAC: true, trackNames: []string{"Dawn", "Static Bloom", "Paper Kites", "Long Exposure"},
+		},
+		{
+			id: 1002, artistID: 100, artistName: "The Mock Band", kind: "single", title: "Second Wind",
+			date: 20250601, trackNames: []string{"Second Wind"},
+		},
+		{
+			id: 2001, artistID: 200, artistName: "Test Pattern", kind: "ep", title: "Signals",
+			date: 20231110, hasFLAC: true, trackNames: []string{"Carrier", "Noise Floor", "Handshake"},
+		},
+	}
+
+	for _, r := range releases {
+		release := &Release{
+			ID:          r.id,
+			Type:        r.kind,
+			ArtistIDs:   []int64{r.artistID},
+			Title:       r.title,
+			Image:       &Image{SourceURL: mockCoverPath + strconv.FormatInt(r.id, 10) + ".jpg"},
+			ArtistNames: []string{r.artistName},
+			LabelID:     4001,
+			Date:        r.date,
+			Barcode:     "0000000" + strconv.FormatInt(r.id, 10),
+		}
+
+		for i, name := range r.trackNames {
+			position := int64(i + 1)
+			track := &Track{
+				ID:             r.id*100 + position,
+				HasFLAC:        r.hasFLAC,
+				ReleaseID:      r.id,
+				Lyrics:         true,
+				Duration:       20 + 5*position,
+				HighestQuality: mockQualityHigh,
+				Genres:         []string{"Electronic"},
+				Title:          name,
+				ReleaseTitle:   r.title,
+				Availability:   2,
+				ArtistNames:    []string{r.artistName},
+				Position:       position,
+				Image:          release.Image,
+			}
+
+			if r.hasFLAC {
+				track.HighestQuality = mockQualityFLAC
+			}
+
+			release.TrackIDs = append(release.TrackIDs, track.ID)
+			catalog.tracks[strconv.FormatInt(track.ID, 10)] = track
+		}
+
+		catalog.releases[strconv.FormatInt(r.id, 10)] = release
+
+		artistID := strconv.FormatInt(r.artistID, 10)
+		if catalog.artists[artistID] == nil {
+			catalog.artists[artistID] = &mockArtist{id: r.artistID, name: r.artistName}
+		}
+
+		catalog.artists[artistID].releaseIDs = append(catalog.artists[artistID].releaseIDs, r.id)
+	}
+
+	// Zvuk lists the newest releases of an artist first.
+	for _, artist := range catalog.artists {
+		slices.SortFunc(artist.releaseIDs, func(a, b int64) int {
+			return int(catalog.releases[strconv.FormatInt(b, 10)].Date - catalog.releases[strconv.FormatInt(a, 10)].Date)
+		})
+	}
+
+	catalog.playlists["3001"] = &Playlist{
+		ID:          3001,
+		BigImageURL: mockCoverPath + "3001.jpg",
+		Title:       "Mock Mix",
+		TrackIDs:    []int64{100101, 200102, 100201, 100103},
+	}
+
+	return catalog
+}
+
+// newMockCover draws the JPEG served for every cover.
+func newMockCover() []byte {
+	cover := image.NewRGBA(image.Rect(0, 0, mockCoverSize, mockCoverSize))
+
+	for x := range mockCoverSize {
+		for y := range mockCoverSize {
+			cover.Set(x, y, color.RGBA{R: uint8(x * 255 / mockCoverSize), G: 96, B: uint8(y * 255 / mockCoverSize), A: 255})
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	// Encoding an in-memory image into a buffer cannot fail.
+	_ = jpeg.Encode(&buffer, cover, nil) //nolint:errcheck // See above.
+
+	return buffer.Bytes()
+}
+
+// handleReleases serves the metadata of the requested releases, with their tracks if included.
+func (m *MockServer) handleReleases(w http.ResponseWriter, r *http.Request) {
+	metadata := &Metadata{Releases: pickMockEntities(m.catalog.releases, r.URL.Query().Get("ids"))}
+
+	if r.URL.Query().Get("include") == "track" {
+		metadata.Tracks = make(map[string]*Track)
+
+		for _, release := range metadata.Releases {
+			m.addMockTracks(metadata.Tracks, release.TrackIDs)
+		}
+	}
+
+	writeMockJSON(w, &GetMetadataResponse{Result: metadata})
+}
+
+// handlePlaylists serves the metadata of the requested playlists, with their tracks if included.
+func (m *MockServer) handlePlaylists(w http.ResponseWriter, r *http.Request) {
+	metadata := &Metadata{Playlists: pickMockEntities(m.catalog.playlists, r.URL.Query().Get("ids"))}
+
+	if r.URL.Query().Get("include") == "track" {
+		metadata.Tracks = make(map[string]*Track)
+
+		for _, playlist := range metadata.Playlists {
+			m.addMockTracks(metadata.Tracks, playlist.TrackIDs)
+		}
+	}
+
+	writeMockJSON(w, &GetMetadataResponse{Result: metadata})
+}
+
+// handleLabels serves the metadata of the requested labels.
+func (m *MockServer) handleLabels(w http.ResponseWriter, r *http.Request) {
+	metadata := &Metadata{Labels: pickMockEntities(m.catalog.labels, r.URL.Query().Get("ids"))}
+
+	writeMockJSON(w, &GetMetadataResponse{Result: metadata})
+}
+
+// handleStreamMetadata serves the stream URL of a track in the requested quality,
+// or in MP3 if the track has no FLAC version.
+func (m *MockServer) handleStreamMetadata(w http.ResponseWriter, r *http.Request) {
+	trackID := r.URL.Query().Get("id")
+
+	track := m.catalog.tracks[trackID]
+	if track == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	stream := "stream"
+
+	switch r.URL.Query().Get("quality") {
+	case mockQualityFLAC:
+		stream = "streamhq"
+		if track.HasFLAC {
+			stream = "streamfl"
+		}
+	case mockQualityHigh:
+		stream = "streamhq"
+	}
+
+	streamURL := fmt.Sprintf("http://%s%s%s?id=%s", r.Host, mockAudioPath, stream, trackID)
+
+	writeMockJSON(w, &GetStreamMetadataResponse{Result: &StreamMetadata{Stream: streamURL}})
+}
+
+// handleLyrics serves synthetic synchronized lyrics of a track.
+func (m *MockServer) handleLyrics(w http.ResponseWriter, r *http.Request) {
+	track := m.catalog.tracks[r.URL.Query().Get("track_id")]
+	if track == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	lyrics := fmt.Sprintf("[00:00.00]%s\n[00:05.00]la la la\n[00:10.00]%s\n", track.Title, track.ArtistNames[0])
+
+	writeMockJSON(w, &GetLyricsResponse{Result: &Lyrics{Type: LyricsTypeLRC, Lyrics: lyrics}})
+}
+
+// handleUserProfile serves a profile with a subscription expiring in a year.
+func (m *MockServer) handleUserProfile(w http.ResponseWriter, _ *http.Request) {
+	writeMockJSON(w, &GetUserProfileResponse{
+		Result: &UserProfile{
+			Subscription: &UserSubscription{
+				Title:      "Mock Premium",
+				Expiration: time.Now().AddDate(1, 0, 0).UnixMilli(),
+			},
+		},
+	})
+}
+
+// handleGraphQL serves the GraphQL operations used for tracks, artists, search, and waves.
+func (m *MockServer) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var operation string
+	if match := mockGraphQLOperationPattern.FindStringSubmatch(request.Query); match != nil {
+		operation = match[1]
+	}
+
+	var data map[string]any
+
+	switch operation {
+	case "getArtistReleases":
+		data = m.graphQLArtistReleases(request.Variables)
+	case "getTracks":
+		data = m.graphQLTracks(request.Variables)
+	case "search":
+		data = m.graphQLSearch(request.Query, request.Variables)
+	case "getWave":
+		data = m.graphQLWave(request.Variables)
+	default:
+		writeMockJSON(w, map[string]any{
+			"errors": []any{map[string]any{"message": "operation '" + operation + "' is not supported by the mock server"}},
+		})
+
+		return
+	}
+
+	writeMockJSON(w, map[string]any{"data": data})
+}
+
+// graphQLArtistReleases returns a page of the releases of an artist.
+func (m *MockServer) graphQLArtistReleases(variables map[string]any) map[string]any {
+	artist := m.catalog.artists[fmt.Sprint(variables["id"])]
+	if artist == nil {
+		return map[string]any{"getArtists": []any{}}
+	}
+
+	offset, _ := variables["offset"].(float64)
+	limit, _ := variables["limit"].(float64)
+
+	releases := make([]any, 0, len(artist.releaseIDs))
+
+	for i, releaseID := range artist.releaseIDs {
+		if i >= int(offset) && (limit <= 0 || i < int(offset+limit)) {
+			releases = append(releases, map[string]any{"id": strconv.FormatInt(releaseID, 10)})
+		}
+	}
+
+	return map[string]any{"getArtists": []any{map[string]any{"releases": releases}}}
+}
+
+// graphQLTracks returns the requested tracks in the format of the getTracks query.
+func (m *MockServer) graphQLTracks(variables map[string]any) map[string]any {
+	ids, _ := variables["ids"].([]any)
+	tracks := make([]any, 0, len(ids))
+
+	for _, id := range ids {
+		track := m.catalog.tracks[fmt.Sprint(id)]
+		if track == nil {
+			continue
+		}
+
+		release := m.catalog.releases[strconv.FormatInt(track.ReleaseID, 10)]
+		artists := mockGraphQLArtists(track.ArtistNames)
+
+		tracks = append(tracks, map[string]any{
+			"id":           strconv.FormatInt(track.ID, 10),
+			"title":        track.Title,
+			"lyrics":       track.Lyrics,
+			"duration":     track.Duration,
+			"availability": track.Availability,
+			"position":     track.Position,
+			"hasFlac":      track.HasFLAC,
+			"artists":      artists,
+			"image":        map[string]any{"src": track.Image.SourceURL},
+			"genres":       []any{map[string]any{"id": "1", "name": track.Genres[0]}},
+			"release": map[string]any{
+				"id":      strconv.FormatInt(release.ID, 10),
+				"type":    release.Type,
+				"title":   release.Title,
+				"date":    strconv.FormatInt(release.Date, 10),
+				"artists": artists,
+				"image":   map[string]any{"src": release.Image.SourceURL},
+				"label":   map[string]any{"id": "4001", "title": m.catalog.labels["4001"].Title},
+			},
+		})
+	}
+
+	return map[string]any{"getTracks": tracks}
+}
+
+// graphQLWave returns the first tracks of the catalog as a wave, the same ones on every request.
+func (m *MockServer) graphQLWave(variables map[string]any) map[string]any {
+	title := "My Wave"
+	if stationID, ok := variables["stationId"]; ok {
+		title = fmt.Sprintf("Mock Radio %v", stationID)
+	}
+
+	limit, _ := variables["limit"].(float64)
+	trackIDs := slices.Sorted(maps.Keys(m.catalog.tracks))
+	tracks := make([]any, 0, len(trackIDs))
+
+	for _, trackID := range trackIDs {
+		if len(tracks) < int(limit) {
+			tracks = append(tracks, map[string]any{"id": trackID})
+		}
+	}
+
+	return map[string]any{"wave": map[string]any{"title": title, "tracks": tracks}}
+}
+
+// graphQLSearch returns the catalog items of the searched type whose title or artist contains the query.
+func (m *MockServer) graphQLSearch(query string, variables map[string]any) map[string]any {
+	searched := strings.ToLower(fmt.Sprint(variables["query"]))
+	matches := func(names ...string) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			return strings.Contains(strings.ToLower(name), searched)
+		})
+	}
+
+	var (
+		field string
+		items []any
+	)
+
+	switch {
+	case strings.Contains(query, "tracks("):
+		field = "tracks"
+
+		for _, id := range slices.Sorted(maps.Keys(m.catalog.tracks)) {
+			if track := m.catalog.tracks[id]; matches(append([]string{track.Title}, track.ArtistNames...)...) {
+				items = append(items, map[string]any{
+					"id": id, "title": track.Title, "artists": mockGraphQLArtists(track.ArtistNames),
+				})
+			}
+		}
+	case strings.Contains(query, "releases("):
+		field = "releases"
+
+		for _, id := range slices.Sorted(maps.Keys(m.catalog.releases)) {
+			if release := m.catalog.releases[id]; matches(append([]string{release.Title}, release.ArtistNames...)...) {
+				items = append(items, map[string]any{
+					"id": id, "title": release.Title, "date": strconv.FormatInt(release.Date, 10),
+					"artists": mockGraphQLArtists(release.ArtistNames),
+				})
+			}
+		}
+	case strings.Contains(query, "artists("):
+		field = "artists"
+
+		for _, id := range slices.Sorted(maps.Keys(m.catalog.artists)) {
+			if artist := m.catalog.artists[id]; matches(artist.name) {
+				items = append(items, map[string]any{"id": id, "title": artist.name})
+			}
+		}
+	default:
+		field = "playlists"
+
+		for _, id := range slices.Sorted(maps.Keys(m.catalog.playlists)) {
+			if playlist := m.catalog.playlists[id]; matches(playlist.Title) {
+				items = append(items, map[string]any{"id": id, "title": playlist.Title})
+			}
+		}
+	}
+
+	return map[string]any{"search": map[string]any{field: map[string]any{"items": items}}}
+}
+
+// handleCover serves the synthetic cover.
+func (m *MockServer) handleCover(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, r.PathValue("file"), time.Time{}, bytes.NewReader(m.cover))
+}
+
+// handleAudio serves a synthetic audio file of the track in the quality of the stream type.
+// Ranges are supported, so interrupted downloads can be resumed.
+func (m *MockServer) handleAudio(w http.ResponseWriter, r *http.Request) {
+	track := m.catalog.tracks[r.URL.Query().Get("id")]
+	if track == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	var (
+		stream = r.PathValue("stream")
+		audio  []byte
+	)
+
+	if stream == "streamfl" {
+		audio = newMockFLAC(track.Duration)
+	} else {
+		frame, ok := mockMP3Frames[stream]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		audio = newMockMP3(track.Duration, frame.header, frame.size)
+	}
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(audio))
+}
+
+// addMockTracks adds the tracks with the IDs to the map.
+func (m *MockServer) addMockTracks(tracks map[string]*Track, trackIDs []int64) {
+	for _, trackID := range trackIDs {
+		id := strconv.FormatInt(trackID, 10)
+		if track := m.catalog.tracks[id]; track != nil {
+			tracks[id] = track
+		}
+	}
+}
+
+// newMockFLAC returns a FLAC file of the given length: a STREAMINFO block followed by silent frames.
+// The minimum frame size is left unknown, so the file is not reported as truncated.
+func newMockFLAC(seconds int64) []byte {
+	// STREAMINFO: block sizes (16+16), frame sizes (24+24), sample rate (20),
+	// channels (3), bits per sample (5), total samples (36), and MD5 (128).
+	streamInfo := new(big.Int)
+	for _, field := range []struct {
+		value int64
+		bits  uint
+	}{
+		{mockFLACBlockSize, 16},
+		{mockFLACBlockSize, 16},
+		{0, 24},
+		{0, 24},
+		{mockFLACSampleRate, 20},
+		{1, 3},
+		{15, 5},
+		{seconds * mockFLACSampleRate, 36},
+	} {
+		streamInfo.Lsh(streamInfo, field.bits).Or(streamInfo, big.NewInt(field.value))
+	}
+
+	streamInfo.Lsh(streamInfo, 128)
+
+	const streamInfoSize = 34
+
+	audio := make([]byte, 0, 8+streamInfoSize+mockFLACAudioSize)
+	audio = append(audio, "fLaC"...)
+	// The last metadata block flag (0x80) with the STREAMINFO type (0) and its 24-bit length.
+	audio = append(audio, 0x80, 0, 0, streamInfoSize)
+	audio = append(audio, streamInfo.FillBytes(make([]byte, streamInfoSize))...)
+	audio = append(audio, 0xFF, 0xF8)
+
+	return append(audio, make([]byte, mockFLACAudioSize-2)...)
+}
+
+// newMockMP3 returns an MP3 file of the given length made of silent frames with the header.
+func newMockMP3(seconds int64, header []byte, frameSize int) []byte {
+	frameCount := int(seconds) * mockFLACSampleRate / mockMP3FrameSamples
+	audio := make([]byte, 0, frameCount*frameSize)
+
+	for range frameCount {
+		audio = append(audio, header...)
+		audio = append(audio, make([]byte, frameSize-len(header))...)
+	}
+
+	return audio
+}
+
+// pickMockEntities returns the entities with the comma-separated IDs, leaving out the unknown ones like Zvuk does.
+func pickMockEntities[V any](entities map[string]*V, ids string) map[string]*V {
+	result := make(map[string]*V)
+
+	for id := range strings.SplitSeq(ids, ",") {
+		if entity := entities[id]; entity != nil {
+			result[id] = entity
+		}
+	}
+
+	return result
+}
+
+// mockGraphQLArtists converts the artist names to the artists of a GraphQL response.
+func mockGraphQLArtists(names []string) []any {
+	artists := make([]any, 0, len(names))
+	for _, name := range names {
+		artists = append(artists, map[string]any{"title": name})
+	}
+
+	return artists
+}
+
+// writeMockJSON writes the value as a JSON response.
+func writeMockJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	//nolint:errcheck,errchkjson // The client has gone away, nothing to report to.
+	json.NewEncoder(w).Encode(value)
+}
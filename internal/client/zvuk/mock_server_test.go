@@ -0,0 +1,102 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-flac/go-flac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestMockServer tests that the client reads the synthetic catalog of the mock server.
+func TestMockServer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(NewMockServer(config.DefaultZvukGraphQLPath).Handler())
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&config.Config{ZvukBaseURL: server.URL, RetryAttemptsCount: 1}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("profile has a subscription", func(t *testing.T) {
+		t.Parallel()
+
+		profile, profileErr := client.GetUserProfile(ctx)
+		require.NoError(t, profileErr)
+		require.NotNil(t, profile.Subscription)
+		assert.Positive(t, profile.Subscription.Expiration)
+	})
+
+	t.Run("artist releases are listed newest first", func(t *testing.T) {
+		t.Parallel()
+
+		releaseIDs, releasesErr := client.GetArtistReleaseIDs(ctx, "100", 0, 50)
+		require.NoError(t, releasesErr)
+		assert.Equal(t, []string{"1002", "1001"}, releaseIDs)
+	})
+
+	t.Run("release tracks are streamed as FLAC", func(t *testing.T) {
+		t.Parallel()
+
+		albums, albumsErr := client.GetAlbumsMetadata(ctx, []string{"1001", "9999"}, true)
+		require.NoError(t, albumsErr)
+		require.Len(t, albums.Releases, 1)
+		assert.Len(t, albums.Tracks, 4)
+		assert.Equal(t, "Static Bloom", albums.Tracks["100102"].Title)
+
+		stream, streamErr := client.GetStreamMetadata(ctx, "100102", mockQualityFLAC)
+		require.NoError(t, streamErr)
+		assert.Contains(t, stream.Stream, "/streamfl?")
+
+		body, downloadErr := client.DownloadFromURL(ctx, stream.Stream)
+		require.NoError(t, downloadErr)
+
+		defer body.Close()
+
+		audio, readErr := io.ReadAll(body)
+		require.NoError(t, readErr)
+
+		file, parseErr := flac.ParseBytes(bytes.NewReader(audio))
+		require.NoError(t, parseErr)
+		assert.Len(t, file.Meta, 1)
+	})
+
+	t.Run("tracks without FLAC fall back to MP3", func(t *testing.T) {
+		t.Parallel()
+
+		stream, streamErr := client.GetStreamMetadata(ctx, "100201", mockQualityFLAC)
+		require.NoError(t, streamErr)
+		assert.Contains(t, stream.Stream, "/streamhq?")
+
+		size, sizeErr := client.GetFileSize(ctx, stream.Stream)
+		require.NoError(t, sizeErr)
+		assert.Positive(t, size)
+	})
+
+	t.Run("tracks are read through GraphQL", func(t *testing.T) {
+		t.Parallel()
+
+		tracks, tracksErr := client.GetTracksMetadata(ctx, []string{"200103"})
+		require.NoError(t, tracksErr)
+		require.Contains(t, tracks, "200103")
+		assert.Equal(t, "Handshake", tracks["200103"].Title)
+		assert.Equal(t, int64(2001), tracks["200103"].ReleaseID)
+	})
+
+	t.Run("search matches titles and artists", func(t *testing.T) {
+		t.Parallel()
+
+		results, searchErr := client.Search(ctx, "mock", SearchTypeArtist, 10)
+		require.NoError(t, searchErr)
+		require.Len(t, results, 1)
+		assert.Equal(t, "The Mock Band", results[0].Title)
+	})
+}
@@ -72,6 +72,20 @@ func (mr *MockClientMockRecorder) FetchTrack(ctx, trackURL any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchTrack", reflect.TypeOf((*MockClient)(nil).FetchTrack), ctx, trackURL)
 }
 
+// GetAPIStatistics mocks base method.
+func (m *MockClient) GetAPIStatistics() *zvuk.APIStatistics {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIStatistics")
+	ret0, _ := ret[0].(*zvuk.APIStatistics)
+	return ret0
+}
+
+// GetAPIStatistics indicates an expected call of GetAPIStatistics.
+func (mr *MockClientMockRecorder) GetAPIStatistics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIStatistics", reflect.TypeOf((*MockClient)(nil).GetAPIStatistics))
+}
+
 // GetAlbumURL mocks base method.
 func (m *MockClient) GetAlbumURL(releaseID string) (string, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +160,21 @@ func (mr *MockClientMockRecorder) GetBaseURL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBaseURL", reflect.TypeOf((*MockClient)(nil).GetBaseURL))
 }
 
+// GetFileSize mocks base method.
+func (m *MockClient) GetFileSize(ctx context.Context, fileURL string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileSize", ctx, fileURL)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileSize indicates an expected call of GetFileSize.
+func (mr *MockClientMockRecorder) GetFileSize(ctx, fileURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileSize", reflect.TypeOf((*MockClient)(nil).GetFileSize), ctx, fileURL)
+}
+
 // GetLabelsMetadata mocks base method.
 func (m *MockClient) GetLabelsMetadata(ctx context.Context, labelIDs []string) (map[string]*zvuk.Label, error) {
 	m.ctrl.T.Helper()
@@ -265,3 +294,33 @@ func (mr *MockClientMockRecorder) GetUserProfile(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockClient)(nil).GetUserProfile), ctx)
 }
+
+// GetWave mocks base method.
+func (m *MockClient) GetWave(ctx context.Context, stationID string, limit int) (*zvuk.Wave, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWave", ctx, stationID, limit)
+	ret0, _ := ret[0].(*zvuk.Wave)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWave indicates an expected call of GetWave.
+func (mr *MockClientMockRecorder) GetWave(ctx, stationID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWave", reflect.TypeOf((*MockClient)(nil).GetWave), ctx, stationID, limit)
+}
+
+// Search mocks base method.
+func (m *MockClient) Search(ctx context.Context, query string, searchType zvuk.SearchType, limit int) ([]*zvuk.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, searchType, limit)
+	ret0, _ := ret[0].([]*zvuk.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockClientMockRecorder) Search(ctx, query, searchType, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockClient)(nil).Search), ctx, query, searchType, limit)
+}
@@ -233,6 +233,10 @@ type Release struct {
 	Date int64 `json:"date"`
 	// GenreIDs is the list of genre IDs for the release.
 	GenreIDs []int64 `json:"genre_ids"`
+	// Barcode is the UPC/EAN barcode of the release (empty if not provided).
+	Barcode string `json:"upc"`
+	// CatalogNumber is the label catalog number of the release (empty if not provided).
+	CatalogNumber string `json:"catalog_number"`
 }
 
 // Track represents metadata for a music track.
@@ -287,6 +291,44 @@ type Label struct {
 	Title string `json:"title"`
 }
 
+// SearchType is the kind of items returned by a search.
+type SearchType string
+
+const (
+	// SearchTypeTrack searches for tracks.
+	SearchTypeTrack SearchType = "track"
+	// SearchTypeAlbum searches for albums (releases).
+	SearchTypeAlbum SearchType = "album"
+	// SearchTypeArtist searches for artists.
+	SearchTypeArtist SearchType = "artist"
+	// SearchTypePlaylist searches for playlists.
+	SearchTypePlaylist SearchType = "playlist"
+)
+
+// Wave represents a sample of the tracks of a wave: the personal one or a radio station.
+type Wave struct {
+	// Title is the wave name.
+	Title string `json:"title"`
+	// TrackIDs is the list of track IDs handed out by the wave.
+	TrackIDs []int64 `json:"track_ids"`
+}
+
+// SearchResult represents a single item found by a search.
+type SearchResult struct {
+	// Type is the kind of the item.
+	Type SearchType `json:"type"`
+	// ID is the unique identifier of the item.
+	ID string `json:"id"`
+	// Title is the item title (the name for artists).
+	Title string `json:"title"`
+	// ArtistNames contains the artists of tracks and albums.
+	ArtistNames []string `json:"artist_names,omitempty"`
+	// Date is the release date of albums.
+	Date string `json:"date,omitempty"`
+	// URL is the item page, accepted by the download command.
+	URL string `json:"url"`
+}
+
 // LyricsTypeSubtitle represents subtitle lyrics type.
 const LyricsTypeSubtitle = "subtitle"
 
@@ -341,6 +341,31 @@ func parseTrackFromGraphQL(data map[string]any) (*Track, error) {
 	return track, nil
 }
 
+// parseSearchResult converts a GraphQL search item to SearchResult.
+// It returns nil for items without an ID.
+func parseSearchResult(data map[string]any, searchType SearchType) *SearchResult {
+	id, ok := data["id"].(string)
+	if !ok || id == "" {
+		return nil
+	}
+
+	result := &SearchResult{
+		Type:        searchType,
+		ID:          id,
+		ArtistNames: parseArtistTitles(data["artists"]),
+	}
+
+	if title, titleOk := data["title"].(string); titleOk {
+		result.Title = title
+	}
+
+	if date, dateOk := data["date"].(string); dateOk {
+		result.Date = date
+	}
+
+	return result
+}
+
 func parseArtistTitles(data any) []string {
 	artistsData, ok := data.([]any)
 	if !ok {
@@ -0,0 +1,154 @@
+package zvuk
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// apiDumpFolderPermissions sets the permissions of the API dump folder: (rwx------),
+	// because the responses may contain personal data.
+	apiDumpFolderPermissions os.FileMode = 0o700
+	// apiDumpTimeLayout is the timestamp layout of the API dump filenames.
+	apiDumpTimeLayout = "2006-01-02_15-04-05.000"
+)
+
+// schemaChecker is implemented by responses that verify the fields they are read for
+// after decoding, so a changed API fails with ErrAPIFormatChanged instead of a nil pointer later on.
+type schemaChecker interface {
+	// checkSchema returns an error wrapping ErrResponseFieldMissing for the first missing field.
+	// In strict mode, every field that is read is required, not only the ones downloads cannot work without.
+	checkSchema(isStrict bool) error
+}
+
+// checkSchema requires the profile.
+func (r *GetUserProfileResponse) checkSchema(_ bool) error {
+	if r.Result == nil {
+		return missingFieldError("result")
+	}
+
+	return nil
+}
+
+// checkSchema requires the titles and the links between the entities in strict mode.
+// The entities are optional otherwise, since unknown IDs are simply left out by the API.
+func (r *GetMetadataResponse) checkSchema(isStrict bool) error {
+	if !isStrict || r.Result == nil {
+		return nil
+	}
+
+	return cmp.Or(
+		checkEntities("tracks", r.Result.Tracks, func(track *Track) string {
+			switch {
+			case track.ID == 0:
+				return "id"
+			case track.Title == "":
+				return "title"
+			case len(track.ArtistNames) == 0:
+				return "artist_names"
+			case track.ReleaseID == 0:
+				return "release_id"
+			}
+
+			return ""
+		}),
+		checkEntities("releases", r.Result.Releases, func(release *Release) string {
+			switch {
+			case release.ID == 0:
+				return "id"
+			case release.Title == "":
+				return "title"
+			case len(release.TrackIDs) == 0:
+				return "track_ids"
+			}
+
+			return ""
+		}),
+		checkEntities("playlists", r.Result.Playlists, func(playlist *Playlist) string {
+			switch {
+			case playlist.ID == 0:
+				return "id"
+			case playlist.Title == "":
+				return "title"
+			}
+
+			return ""
+		}),
+		checkEntities("labels", r.Result.Labels, func(label *Label) string {
+			if label.Title == "" {
+				return "title"
+			}
+
+			return ""
+		}),
+	)
+}
+
+// checkEntities returns an error for the first entity, in ID order, that misses a field.
+// missingField returns the name of the missing field, or an empty string.
+func checkEntities[V any](name string, entities map[string]*V, missingField func(entity *V) string) error {
+	for _, id := range slices.Sorted(maps.Keys(entities)) {
+		entity := entities[id]
+		if entity == nil {
+			continue
+		}
+
+		if field := missingField(entity); field != "" {
+			return missingFieldError(fmt.Sprintf("result.%s['%s'].%s", name, id, field))
+		}
+	}
+
+	return nil
+}
+
+// missingFieldError returns the error for a missing response field.
+func missingFieldError(field string) error {
+	return fmt.Errorf("%w: %s", ErrResponseFieldMissing, field)
+}
+
+// isFormatChange reports whether the decoding error means that the response has a different format,
+// rather than a truncated or non-JSON body.
+func isFormatChange(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+
+	return errors.As(err, &typeErr) ||
+		errors.Is(err, ErrResponseFieldMissing) ||
+		errors.Is(err, ErrUnexpectedJSONToken)
+}
+
+// dumpResponse saves the raw response of the URI to a timestamped file of api_dump_path,
+// so it can be attached to a bug report. Known secrets are masked.
+func (c *ClientImpl) dumpResponse(ctx context.Context, uri string, payload *bytes.Buffer) {
+	err := os.MkdirAll(c.cfg.APIDumpPath, apiDumpFolderPermissions)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to create the API dump folder: %v", err)
+
+		return
+	}
+
+	dumpPath := filepath.Join(c.cfg.APIDumpPath, fmt.Sprintf("%s-%s.json",
+		strings.ReplaceAll(uri, "/", "_"), time.Now().Format(apiDumpTimeLayout)))
+
+	err = os.WriteFile(dumpPath, []byte(logger.Redact(payload.String())), constants.DefaultFilePermissions)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to save the API response: %v", err)
+
+		return
+	}
+
+	logger.Errorf(ctx, "The unexpected API response is saved to %s, "+
+		"attach it when reporting the problem, it may contain personal data", dumpPath)
+}
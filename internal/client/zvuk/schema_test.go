@@ -0,0 +1,114 @@
+package zvuk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestClientImpl_ResponseSchema tests that responses in a changed format fail with ErrAPIFormatChanged.
+func TestClientImpl_ResponseSchema(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+
+		switch r.URL.Path {
+		case "/" + zvukAPIUserProfileURI:
+			// The profile is renamed.
+			body = `{"profile": {"id": 1}}`
+		case "/" + zvukAPIStreamMetadataURI:
+			// No stream in the requested quality.
+			body = `{"result": null}`
+		case "/" + zvukAPIReleaseMetadataURI:
+			// The track title is renamed.
+			body = `{"result": {"releases": {"7": {"id": 7, "title": "Mutter", "track_ids": [1]}},
+				"tracks": {"1": {"id": 1, "name": "Sonne", "artist_names": ["Rammstein"], "release_id": 7}}}}`
+		case "/" + zvukAPILabelURI:
+			// The labels have become a list.
+			body = `{"result": {"labels": {"1": [{"title": "Universal"}]}}}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		//nolint:errcheck // Test mock handler, error is not critical.
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	newClient := func(t *testing.T, isStrict bool, dumpPath string) Client {
+		t.Helper()
+
+		client, err := NewClient(&config.Config{
+			ZvukBaseURL:        server.URL,
+			RetryAttemptsCount: 1,
+			StrictAPIDecoding:  isStrict,
+			APIDumpPath:        dumpPath,
+		}, nil)
+		require.NoError(t, err)
+
+		return client
+	}
+
+	t.Run("missing required field is reported and dumped", func(t *testing.T) {
+		t.Parallel()
+
+		dumpPath := t.TempDir()
+
+		_, err := newClient(t, false, dumpPath).GetUserProfile(context.Background())
+		require.ErrorIs(t, err, ErrAPIFormatChanged)
+		require.ErrorIs(t, err, ErrResponseFieldMissing)
+		assert.Contains(t, err.Error(), "result")
+
+		dumps, err := filepath.Glob(filepath.Join(dumpPath, "api_v2_tiny_profile-*.json"))
+		require.NoError(t, err)
+		require.Len(t, dumps, 1)
+
+		payload, err := os.ReadFile(dumps[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(payload), "profile")
+	})
+
+	t.Run("empty stream result means no stream", func(t *testing.T) {
+		t.Parallel()
+
+		dumpPath := t.TempDir()
+
+		_, err := newClient(t, false, dumpPath).GetStreamMetadata(context.Background(), "1", "flac")
+		require.ErrorIs(t, err, ErrNoStreamAvailable)
+		require.NotErrorIs(t, err, ErrAPIFormatChanged)
+
+		dumps, err := os.ReadDir(dumpPath)
+		require.NoError(t, err)
+		assert.Empty(t, dumps)
+	})
+
+	t.Run("missing optional field is required in strict mode", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := newClient(t, false, "").GetAlbumsMetadata(context.Background(), []string{"7"}, true)
+		require.NoError(t, err)
+		assert.Empty(t, result.Tracks["1"].Title)
+
+		_, err = newClient(t, true, "").GetAlbumsMetadata(context.Background(), []string{"7"}, true)
+		require.ErrorIs(t, err, ErrAPIFormatChanged)
+		assert.Contains(t, err.Error(), "result.tracks['1'].title")
+	})
+
+	t.Run("changed field type is reported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newClient(t, false, "").GetLabelsMetadata(context.Background(), []string{"1"})
+		require.ErrorIs(t, err, ErrAPIFormatChanged)
+	})
+}
@@ -0,0 +1,165 @@
+package zvuk
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// endpointTrackStream is the statistics key for audio stream downloads.
+	endpointTrackStream = "track stream"
+	// endpointFileDownload is the statistics key for generic file downloads (covers, etc.).
+	endpointFileDownload = "file download"
+	// endpointFileSize is the statistics key for HEAD and ranged requests used to probe file sizes.
+	endpointFileSize = "file size"
+)
+
+const (
+	// cacheNameLabels is the statistics key for the labels cache.
+	cacheNameLabels = "labels"
+	// cacheNameAlbums is the statistics key for the albums cache.
+	cacheNameAlbums = "albums"
+	// cacheNameTracks is the statistics key for the tracks cache.
+	cacheNameTracks = "tracks"
+	// cacheNamePlaylists is the statistics key for the playlists cache.
+	cacheNamePlaylists = "playlists"
+	// cacheNameAudiobooks is the statistics key for the audiobooks cache.
+	cacheNameAudiobooks = "audiobooks"
+	// cacheNamePodcasts is the statistics key for the podcasts cache.
+	cacheNamePodcasts = "podcasts"
+)
+
+// APIStatistics is a point-in-time snapshot of the client's API activity.
+type APIStatistics struct {
+	// Endpoints maps an endpoint name to its request statistics.
+	Endpoints map[string]*EndpointStatistics
+	// Caches maps a cache name to its hit and miss counters.
+	Caches map[string]*CacheStatistics
+}
+
+// TotalAPIDuration returns the cumulative time spent in metadata API requests,
+// excluding audio stream downloads, file downloads, and file size probes.
+func (s *APIStatistics) TotalAPIDuration() time.Duration {
+	var total time.Duration
+
+	for name, stats := range s.Endpoints {
+		if name == endpointTrackStream || name == endpointFileDownload || name == endpointFileSize {
+			continue
+		}
+
+		total += stats.TotalDuration
+	}
+
+	return total
+}
+
+// TotalRequests returns the number of requests sent to every endpoint,
+// including audio stream downloads, file downloads, and file size probes.
+func (s *APIStatistics) TotalRequests() int64 {
+	var total int64
+
+	for _, stats := range s.Endpoints {
+		total += stats.Requests
+	}
+
+	return total
+}
+
+// EndpointStatistics holds request counters for a single API endpoint.
+type EndpointStatistics struct {
+	// Requests is the number of requests sent to the endpoint.
+	Requests int64
+	// TotalDuration is the cumulative time spent waiting for the endpoint to respond.
+	TotalDuration time.Duration
+}
+
+// CacheStatistics holds lookup counters for a single metadata cache.
+type CacheStatistics struct {
+	// Hits is the number of lookups served from the cache.
+	Hits int64
+	// Misses is the number of lookups that required an API call.
+	Misses int64
+}
+
+// HitRate returns the share of cache lookups served from the cache, in percent.
+func (s *CacheStatistics) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total) * 100
+}
+
+// apiStatisticsCollector accumulates API activity counters in a thread-safe manner.
+type apiStatisticsCollector struct {
+	mutex     sync.Mutex
+	endpoints map[string]*EndpointStatistics
+	caches    map[string]*CacheStatistics
+}
+
+// newAPIStatisticsCollector creates an empty API statistics collector.
+func newAPIStatisticsCollector() *apiStatisticsCollector {
+	return &apiStatisticsCollector{
+		endpoints: make(map[string]*EndpointStatistics),
+		caches:    make(map[string]*CacheStatistics),
+	}
+}
+
+// recordRequest registers a completed request to the endpoint that started at startTime.
+func (c *apiStatisticsCollector) recordRequest(endpoint string, startTime time.Time) {
+	elapsed := time.Since(startTime)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats, ok := c.endpoints[endpoint]
+	if !ok {
+		stats = new(EndpointStatistics)
+		c.endpoints[endpoint] = stats
+	}
+
+	stats.Requests++
+	stats.TotalDuration += elapsed
+}
+
+// recordCacheLookup registers the outcome of a cache lookup.
+func (c *apiStatisticsCollector) recordCacheLookup(cacheName string, isHit bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats, ok := c.caches[cacheName]
+	if !ok {
+		stats = new(CacheStatistics)
+		c.caches[cacheName] = stats
+	}
+
+	if isHit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+}
+
+// snapshot returns a deep copy of the collected counters.
+func (c *apiStatisticsCollector) snapshot() *APIStatistics {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := &APIStatistics{
+		Endpoints: make(map[string]*EndpointStatistics, len(c.endpoints)),
+		Caches:    make(map[string]*CacheStatistics, len(c.caches)),
+	}
+
+	for name, stats := range c.endpoints {
+		statsCopy := *stats
+		result.Endpoints[name] = &statsCopy
+	}
+
+	for name, stats := range c.caches {
+		statsCopy := *stats
+		result.Caches[name] = &statsCopy
+	}
+
+	return result
+}
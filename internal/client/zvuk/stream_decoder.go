@@ -0,0 +1,136 @@
+package zvuk
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// streamDecoder is implemented by responses that are decoded entity by entity
+// instead of buffering the whole body: json.Decoder.Decode reads the complete value
+// into memory first, which for a 10k-track playlist is tens of megabytes of raw JSON
+// held next to the decoded maps.
+type streamDecoder interface {
+	// decodeStream reads the response from the decoder token by token.
+	decodeStream(decoder *json.Decoder) error
+}
+
+// decodeStream decodes the {"result": {...}} envelope without buffering it.
+func (r *GetMetadataResponse) decodeStream(decoder *json.Decoder) error {
+	return decodeObject(decoder, func(key string) error {
+		if key != "result" {
+			return skipValue(decoder)
+		}
+
+		result := new(Metadata)
+
+		isNull, err := result.decodeStreamValue(decoder)
+		if err != nil {
+			return err
+		}
+
+		if !isNull {
+			r.Result = result
+		}
+
+		return nil
+	})
+}
+
+// decodeStreamValue decodes the metadata maps one entity at a time and reports whether the value was null.
+func (m *Metadata) decodeStreamValue(decoder *json.Decoder) (bool, error) {
+	isObject := false
+
+	err := decodeObject(decoder, func(key string) error {
+		isObject = true
+
+		var err error
+
+		switch key {
+		case "tracks":
+			m.Tracks, err = decodeEntityMap[Track](decoder, key)
+		case "playlists":
+			m.Playlists, err = decodeEntityMap[Playlist](decoder, key)
+		case "releases":
+			m.Releases, err = decodeEntityMap[Release](decoder, key)
+		case "abooks":
+			m.Audiobooks, err = decodeEntityMap[Audiobook](decoder, key)
+		case "podcasts":
+			m.Podcasts, err = decodeEntityMap[Podcast](decoder, key)
+		case "labels":
+			m.Labels, err = decodeEntityMap[Label](decoder, key)
+		default:
+			err = skipValue(decoder)
+		}
+
+		return err
+	})
+
+	return !isObject, err
+}
+
+// decodeEntityMap decodes a JSON object of ID to entity, keeping only one raw entity in memory at a time.
+func decodeEntityMap[V any](decoder *json.Decoder, name string) (map[string]*V, error) {
+	var result map[string]*V
+
+	err := decodeObject(decoder, func(id string) error {
+		entity := new(V)
+		if err := decoder.Decode(entity); err != nil {
+			return fmt.Errorf("failed to decode %s entry '%s': %w", name, id, err)
+		}
+
+		if result == nil {
+			result = make(map[string]*V)
+		}
+
+		result[id] = entity
+
+		return nil
+	})
+
+	return result, err
+}
+
+// decodeObject reads a JSON object and calls decodeField for every key with the decoder
+// positioned at the value, which decodeField must consume. A null value is accepted as an empty object.
+func decodeObject(decoder *json.Decoder, decodeField func(key string) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if delimiter, ok := token.(json.Delim); !ok || delimiter != '{' {
+		return fmt.Errorf("%w: expected an object, got %v", ErrUnexpectedJSONToken, token)
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("%w: expected an object key, got %v", ErrUnexpectedJSONToken, token)
+		}
+
+		if err = decodeField(key); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing brace.
+	_, err = decoder.Token()
+
+	return err
+}
+
+// skipValue consumes the next JSON value of any type.
+func skipValue(decoder *json.Decoder) error {
+	var skipped json.RawMessage
+
+	return decoder.Decode(&skipped)
+}
@@ -0,0 +1,74 @@
+package zvuk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetMetadataResponse_DecodeStream tests that streamed decoding matches regular decoding.
+func TestGetMetadataResponse_DecodeStream(t *testing.T) {
+	t.Parallel()
+
+	var tracks []string
+	for i := range 100 {
+		tracks = append(tracks, fmt.Sprintf(`"%d": {"id": %d, "title": "Track %d", "release_id": 7}`, i+1, i+1, i+1))
+	}
+
+	body := `{
+		"result": {
+			"tracks": {` + strings.Join(tracks, ",") + `},
+			"playlists": {"42": {"id": 42, "title": "Big Playlist", "track_ids": [1, 2, 3]}},
+			"releases": null,
+			"unknown": [{"nested": {"value": true}}]
+		},
+		"meta": {"version": 1}
+	}`
+
+	var expected GetMetadataResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &expected))
+
+	var actual GetMetadataResponse
+	require.NoError(t, actual.decodeStream(json.NewDecoder(strings.NewReader(body))))
+
+	require.NotNil(t, actual.Result)
+	assert.Len(t, actual.Result.Tracks, 100)
+	assert.Equal(t, expected.Result.Tracks, actual.Result.Tracks)
+	assert.Equal(t, expected.Result.Playlists, actual.Result.Playlists)
+	assert.Nil(t, actual.Result.Releases)
+}
+
+// TestGetMetadataResponse_DecodeStreamErrors tests streamed decoding of malformed responses.
+func TestGetMetadataResponse_DecodeStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "array instead of object", body: `[]`},
+		{name: "result is a string", body: `{"result": "oops"}`},
+		{name: "truncated body", body: `{"result": {"tracks": {"1": {"id": 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var response GetMetadataResponse
+			require.Error(t, response.decodeStream(json.NewDecoder(strings.NewReader(tt.body))))
+		})
+	}
+
+	t.Run("null result", func(t *testing.T) {
+		t.Parallel()
+
+		var response GetMetadataResponse
+		require.NoError(t, response.decodeStream(json.NewDecoder(strings.NewReader(`{"result": null}`))))
+		assert.Nil(t, response.Result)
+	})
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidBlocklistEntry indicates that an entry of the blocklist cannot be parsed.
+var ErrInvalidBlocklistEntry = errors.New("invalid blocklist entry")
+
+// Blocklist holds the tracks, releases, and artists that are never downloaded.
+type Blocklist struct {
+	// TrackIDs are the blocked tracks.
+	TrackIDs map[int64]struct{}
+	// ReleaseIDs are the releases whose tracks are blocked.
+	ReleaseIDs map[int64]struct{}
+	// ArtistNames are the lowercased names of the artists whose tracks are blocked.
+	ArtistNames map[string]struct{}
+}
+
+// Match returns the blocklist entry matching the track, its release, or one of its artists,
+// or an empty string if the track is not blocked.
+func (b *Blocklist) Match(trackID, releaseID int64, artistNames []string) string {
+	if _, ok := b.TrackIDs[trackID]; ok {
+		return "track:" + strconv.FormatInt(trackID, 10)
+	}
+
+	if _, ok := b.ReleaseIDs[releaseID]; ok {
+		return "album:" + strconv.FormatInt(releaseID, 10)
+	}
+
+	for _, name := range artistNames {
+		if _, ok := b.ArtistNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return "artist:" + name
+		}
+	}
+
+	return ""
+}
+
+// ParseBlocklist parses the blocklist entries: "track:<ID>" or a plain track ID, "album:<ID>",
+// and "artist:<name>" (matched ignoring case). It returns nil if there are no entries.
+func ParseBlocklist(entries []string) (*Blocklist, error) {
+	blocklist := &Blocklist{
+		TrackIDs:    make(map[int64]struct{}),
+		ReleaseIDs:  make(map[int64]struct{}),
+		ArtistNames: make(map[string]struct{}),
+	}
+
+	count := 0
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, value, hasKind := strings.Cut(entry, ":")
+		if !hasKind {
+			kind, value = "track", entry
+		}
+
+		kind, value = strings.ToLower(strings.TrimSpace(kind)), strings.TrimSpace(value)
+
+		switch kind {
+		case "artist":
+			if value == "" {
+				return nil, fmt.Errorf("%w '%s': the artist name is empty", ErrInvalidBlocklistEntry, entry)
+			}
+
+			blocklist.ArtistNames[strings.ToLower(value)] = struct{}{}
+		case "track", "album":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("%w '%s': the ID must be a positive number", ErrInvalidBlocklistEntry, entry)
+			}
+
+			if kind == "album" {
+				blocklist.ReleaseIDs[id] = struct{}{}
+			} else {
+				blocklist.TrackIDs[id] = struct{}{}
+			}
+		default:
+			return nil, fmt.Errorf("%w '%s': must start with track:, album:, or artist:", ErrInvalidBlocklistEntry, entry)
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return nil, nil //nolint:nilnil // An empty blocklist blocks nothing.
+	}
+
+	return blocklist, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseBlocklist tests the parsing and the matching of the blocklist entries.
+func TestParseBlocklist(t *testing.T) {
+	t.Parallel()
+
+	blocklist, err := ParseBlocklist([]string{"123", " track:124 ", "album:7", "Artist: Karaoke Stars", ""})
+	require.NoError(t, err)
+
+	assert.Equal(t, "track:123", blocklist.Match(123, 1, nil))
+	assert.Equal(t, "track:124", blocklist.Match(124, 1, nil))
+	assert.Equal(t, "album:7", blocklist.Match(1, 7, nil))
+	assert.Equal(t, "artist:KARAOKE STARS", blocklist.Match(1, 1, []string{"Rammstein", "KARAOKE STARS"}))
+	assert.Empty(t, blocklist.Match(1, 1, []string{"Rammstein"}))
+
+	empty, err := ParseBlocklist([]string{" "})
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+
+	for _, entry := range []string{"track:abc", "album:0", "artist:", "label:5"} {
+		_, err = ParseBlocklist([]string{entry})
+		require.ErrorIs(t, err, ErrInvalidBlocklistEntry, entry)
+	}
+}
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +18,6 @@ import (
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v3"
 
-	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 )
@@ -21,19 +26,60 @@ import (
 type Config struct {
 	// AuthToken is the authentication token for API access.
 	AuthToken string `mapstructure:"auth_token"`
+	// AuthTokenStorage defines where auth login saves the token: "config" for the configuration file,
+	// "keyring" for the credential store of the operating system, where it is read from when auth_token is empty.
+	AuthTokenStorage string `mapstructure:"auth_token_storage"`
 	// Quality specifies the preferred audio quality (1=MP3 128k, 2=MP3 320k, 3=FLAC).
 	Quality uint8 `mapstructure:"quality"`
 	// MinQuality specifies the minimum acceptable quality (1=MP3 128k, 2=MP3 320k, 3=FLAC).
 	// Tracks below this quality will be skipped. Set to 0 to disable filtering.
 	MinQuality uint8 `mapstructure:"min_quality"`
+	// QualityFallback indicates whether a track whose stream keeps failing is retried in the next lower quality,
+	// down to min_quality, instead of failing outright.
+	QualityFallback bool `mapstructure:"quality_fallback"`
 	// MinDuration specifies the minimum acceptable track duration (e.g., "30s", "1m").
 	// Tracks shorter than this will be skipped. Empty string disables filtering.
 	MinDuration string `mapstructure:"min_duration"`
 	// MaxDuration specifies the maximum acceptable track duration (e.g., "10m", "1h").
 	// Tracks longer than this will be skipped. Empty string disables filtering.
 	MaxDuration string `mapstructure:"max_duration"`
+	// ExcludePatterns are regular expressions matched against the track titles and artists;
+	// the matching tracks are skipped.
+	ExcludePatterns []string `mapstructure:"exclude_patterns"`
+	// Blocklist are the tracks ("track:<ID>"), releases ("album:<ID>"), and artists ("artist:<name>")
+	// that are never downloaded.
+	Blocklist []string `mapstructure:"blocklist"`
+	// PerArtistLimit is the maximum number of releases downloaded for each artist URL (0 disables the limit).
+	PerArtistLimit int64 `mapstructure:"per_artist_limit"`
+	// MaxRunDuration is the wall-clock budget of a run (e.g., "2h"), after which no new tracks are started.
+	// Empty string disables the limit.
+	MaxRunDuration string `mapstructure:"max_run_duration"`
+	// MaxAPIRequests is the number of API requests a run may send, after which it stops or pauses
+	// according to api_quota_action (0 disables the limit).
+	MaxAPIRequests int64 `mapstructure:"max_api_requests"`
+	// APIQuotaAction defines what happens once max_api_requests is used up: the run stops or pauses.
+	APIQuotaAction string `mapstructure:"api_quota_action"`
+	// APIQuotaPause is how long the run pauses once max_api_requests is used up (e.g., "1h"),
+	// before it may send another max_api_requests requests.
+	APIQuotaPause string `mapstructure:"api_quota_pause"`
+	// DownloadWindow is the comma-separated local hours when audio files may be downloaded (e.g., "02:00-07:00").
+	// Outside of them, the tracks wait for the next window. Empty string allows downloads at any time.
+	DownloadWindow string `mapstructure:"download_window"`
+	// PoliteHours is the comma-separated local hours of the polite mode (e.g., "18:00-23:00"),
+	// when the pause between tracks is longer and fewer tracks are downloaded at once.
+	// Empty string disables the polite mode.
+	PoliteHours string `mapstructure:"polite_hours"`
+	// PolitePauseMultiplier is how many times max_download_pause is multiplied during polite_hours.
+	PolitePauseMultiplier int64 `mapstructure:"polite_pause_multiplier"`
+	// PoliteMaxConcurrentDownloads is the maximum number of tracks downloaded at once during polite_hours.
+	PoliteMaxConcurrentDownloads int64 `mapstructure:"polite_max_concurrent_downloads"`
 	// OutputPath is the directory path where downloaded files will be saved.
 	OutputPath string `mapstructure:"output_path"`
+	// RequireExistingOutputPath indicates whether output_path must already exist instead of being created.
+	RequireExistingOutputPath bool `mapstructure:"require_existing_output_path"`
+	// SummaryErrorLimit is the number of errors above which the console summary is shortened and the full
+	// summary is saved to a text file in output_path (0 always prints the full summary).
+	SummaryErrorLimit int64 `mapstructure:"summary_error_limit"`
 	// TrackFilenameTemplate is the template for naming individual track files.
 	TrackFilenameTemplate string `mapstructure:"track_filename_template"`
 	// AlbumFolderTemplate is the template for naming album folders.
@@ -48,8 +94,42 @@ type Config struct {
 	PodcastFolderTemplate string `mapstructure:"podcast_folder_template"`
 	// PodcastEpisodeFilenameTemplate is the template for naming podcast episode files.
 	PodcastEpisodeFilenameTemplate string `mapstructure:"podcast_episode_filename_template"`
+	// StrictTemplates indicates whether templates referencing unknown variables are rejected at startup.
+	StrictTemplates bool `mapstructure:"strict_templates"`
+	// ArtistJoinStyle defines how several track artists are combined into the display artist tag.
+	ArtistJoinStyle string `mapstructure:"artist_join_style"`
 	// DownloadLyrics indicates whether to download lyrics for tracks.
 	DownloadLyrics bool `mapstructure:"download_lyrics"`
+	// LyricsFilenameTemplate is the template for naming lyrics files, without the extension.
+	LyricsFilenameTemplate string `mapstructure:"lyrics_filename_template"`
+	// LyricsExtension is the file extension of lyrics files.
+	LyricsExtension string `mapstructure:"lyrics_extension"`
+	// DescriptionFilenameTemplate is the template for naming audiobook and podcast description files,
+	// without the extension.
+	DescriptionFilenameTemplate string `mapstructure:"description_filename_template"`
+	// DescriptionExtension is the file extension of description files.
+	DescriptionExtension string `mapstructure:"description_extension"`
+	// PlaylistDuplicates defines how a track repeated within a single playlist is saved.
+	PlaylistDuplicates string `mapstructure:"playlist_duplicates"`
+	// PlaylistLayout defines where playlist tracks are saved: in the playlist folder or in their album folders.
+	PlaylistLayout string `mapstructure:"playlist_layout"`
+	// WritePlaylistFiles indicates whether album and playlist folders get an M3U playlist of their saved tracks.
+	WritePlaylistFiles bool `mapstructure:"write_playlist_files"`
+	// WriteDiscographyPlaylists indicates whether every downloaded artist gets an M3U playlist
+	// of all its saved releases in release date order.
+	WriteDiscographyPlaylists bool `mapstructure:"write_discography_playlists"`
+	// WaveTrackCount is the number of tracks downloaded from a wave or radio station URL.
+	WaveTrackCount int `mapstructure:"wave_track_count"`
+	// EmbedPlaylistCovers indicates whether playlist tracks get the cover of their own album embedded.
+	EmbedPlaylistCovers bool `mapstructure:"embed_playlist_covers"`
+	// GeneratePlaylistCovers indicates whether a playlist without a cover gets one composed
+	// of the covers of its first four albums.
+	GeneratePlaylistCovers bool `mapstructure:"generate_playlist_covers"`
+	// EmbeddedCover defines whether the cover is embedded into the audio files in full size,
+	// as a thumbnail, or not at all. The folder cover is saved either way.
+	EmbeddedCover string `mapstructure:"embedded_cover"`
+	// EmbeddedCoverSize is the largest side in pixels of the embedded thumbnail.
+	EmbeddedCoverSize int `mapstructure:"embedded_cover_size"`
 	// ReplaceTracks indicates whether to replace existing track files.
 	ReplaceTracks bool `mapstructure:"replace_tracks"`
 	// ReplaceCovers indicates whether to replace existing cover art files.
@@ -58,12 +138,16 @@ type Config struct {
 	ReplaceDescriptions bool `mapstructure:"replace_descriptions"`
 	// ReplaceLyrics indicates whether to replace existing lyrics files.
 	ReplaceLyrics bool `mapstructure:"replace_lyrics"`
+	// ReplacePlaylistFiles indicates whether to replace existing M3U playlist files.
+	ReplacePlaylistFiles bool `mapstructure:"replace_playlist_files"`
 	// LogLevel specifies the logging verbosity level.
 	LogLevel string `mapstructure:"log_level"`
 	// DownloadSpeedLimit sets the maximum download speed (e.g., "1MB", "500KB").
 	DownloadSpeedLimit string `mapstructure:"download_speed_limit"`
 	// CreateFolderForSingles indicates whether to create folders for single tracks.
 	CreateFolderForSingles bool `mapstructure:"create_folder_for_singles"`
+	// GroupByArtistInitial indicates whether album folders are grouped under the initial letter of the artist.
+	GroupByArtistInitial bool `mapstructure:"group_by_artist_initial"`
 	// MaxFolderNameLength is the maximum length for folder names.
 	MaxFolderNameLength int64 `mapstructure:"max_folder_name_length"`
 	// RetryAttemptsCount is the number of retry attempts for failed downloads.
@@ -74,16 +158,176 @@ type Config struct {
 	MinRetryPause string `mapstructure:"min_retry_pause"`
 	// MaxRetryPause is the maximum pause duration before retrying.
 	MaxRetryPause string `mapstructure:"max_retry_pause"`
+	// TagWriteTimeout is the maximum time allowed for writing tags to a single track (e.g., "2m").
+	TagWriteTimeout string `mapstructure:"tag_write_timeout"`
+	// UntaggedAudio defines what happens to downloaded audio when writing its tags fails.
+	UntaggedAudio string `mapstructure:"untagged_audio"`
 	// MaxConcurrentDownloads is the maximum number of tracks to download simultaneously.
 	MaxConcurrentDownloads int64 `mapstructure:"max_concurrent_downloads"`
-	// ZvukBaseURL is the base URL for the Zvuk API (set automatically).
-	ZvukBaseURL string
+	// ReloadConfig indicates whether changes of the download speed limit, the number of tracks downloaded at once,
+	// and the log level made to the configuration file are applied to a running download.
+	ReloadConfig bool `mapstructure:"reload_config"`
+	// DownloadOrder defines the order in which the tracks of a collection are downloaded.
+	DownloadOrder string `mapstructure:"download_order"`
+	// Progress defines how the progress of track downloads is shown: progress bars or one line per event.
+	Progress string `mapstructure:"progress"`
+	// MetadataBatchSize is the maximum number of IDs sent in a single metadata request.
+	MetadataBatchSize int64 `mapstructure:"metadata_batch_size"`
+	// MaxConsecutiveFailures is the number of consecutive track failures after which
+	// the rest of the collection is aborted (0 disables the limit).
+	MaxConsecutiveFailures int64 `mapstructure:"max_consecutive_failures"`
+	// NormalizeLoudness indicates whether to produce loudness-normalized copies of downloaded tracks.
+	NormalizeLoudness bool `mapstructure:"normalize_loudness"`
+	// NormalizedOutputPath is the directory where normalized copies are saved, mirroring output_path.
+	NormalizedOutputPath string `mapstructure:"normalized_output_path"`
+	// NormalizationTargetLUFS is the integrated loudness target for normalized copies (e.g., -14).
+	NormalizationTargetLUFS float64 `mapstructure:"normalization_target_lufs"`
+	// FFmpegPath is the path to the ffmpeg executable used for audio processing.
+	FFmpegPath string `mapstructure:"ffmpeg_path"`
+	// FLACCompressionLevel is the compression level (0-12) downloaded FLAC files are re-encoded at with ffmpeg
+	// (empty keeps them as downloaded).
+	FLACCompressionLevel string `mapstructure:"flac_compression_level"`
+	// FLACPadding is the size of the padding block written to FLAC files along with their tags, e.g., "8KB"
+	// (empty keeps the padding of the file).
+	FLACPadding string `mapstructure:"flac_padding"`
+	// PortableOutputPath is the directory of the transcoded portable copies (empty disables them).
+	PortableOutputPath string `mapstructure:"portable_output_path"`
+	// PortableFormat is the audio format of portable copies ("mp3" or "opus").
+	PortableFormat string `mapstructure:"portable_format"`
+	// PortableBitrate is the audio bitrate of portable copies (e.g., "320k").
+	PortableBitrate string `mapstructure:"portable_bitrate"`
+	// PortableTrackFilenameTemplate overrides track_filename_template for portable copies.
+	PortableTrackFilenameTemplate string `mapstructure:"portable_track_filename_template"`
+	// PortableAlbumFolderTemplate overrides album_folder_template for portable copies.
+	PortableAlbumFolderTemplate string `mapstructure:"portable_album_folder_template"`
+	// PortablePlaylistFilenameTemplate overrides playlist_filename_template for portable copies.
+	PortablePlaylistFilenameTemplate string `mapstructure:"portable_playlist_filename_template"`
+	// ZvukBaseURL is the base URL of the Zvuk API, e.g., a mirror, a proxy, or a staging host.
+	ZvukBaseURL string `mapstructure:"zvuk_base_url"`
+	// ZvukGraphQLPath is the path of the GraphQL endpoint relative to zvuk_base_url.
+	ZvukGraphQLPath string `mapstructure:"zvuk_graphql_path"`
+	// ProxyURL is the HTTP, HTTPS, or SOCKS5 proxy requests to Zvuk and the browser of auth login go through.
+	// Empty keeps the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+	ProxyURL string `mapstructure:"proxy_url"`
+	// NoProxy lists the hosts, domains, and IP ranges reached without proxy_url,
+	// in the format of the NO_PROXY environment variable, which is used when the list is empty.
+	NoProxy []string `mapstructure:"no_proxy"`
+	// StrictAPIDecoding rejects API responses missing any of the fields that are read from them,
+	// instead of only the fields downloads cannot work without.
+	StrictAPIDecoding bool `mapstructure:"strict_api_decoding"`
+	// APIDumpPath is the directory where the raw API responses that no longer match the expected format
+	// are saved. Empty disables the dumps.
+	APIDumpPath string `mapstructure:"api_dump_path"`
+	// SolveAntiBotChallenges indicates whether anti-bot challenges are handed off to a browser to be solved.
+	SolveAntiBotChallenges bool `mapstructure:"solve_anti_bot_challenges"`
+	// AntiBotCookiesPath is the file where the cookies of solved anti-bot challenges are kept between runs.
+	AntiBotCookiesPath string `mapstructure:"anti_bot_cookies_path"`
+	// BrowserDiagnosticsPath is the directory where a screenshot and the HTML of the page are saved
+	// when the browser login or an anti-bot challenge fails.
+	BrowserDiagnosticsPath string `mapstructure:"browser_diagnostics_path"`
+	// UpgradeWatchPath is the file listing tracks saved below FLAC, re-checked by the upgrade command
+	// (empty disables the watch list).
+	UpgradeWatchPath string `mapstructure:"upgrade_watch_path"`
+	// UpgradeQuarantinePath is the directory where files replaced by their FLAC versions are moved,
+	// mirroring output_path (empty deletes them).
+	UpgradeQuarantinePath string `mapstructure:"upgrade_quarantine_path"`
+	// ResumeStatePath is the file the failed items of a run are saved to, replayed by the resume command.
+	ResumeStatePath string `mapstructure:"resume_state_path"`
+	// SyncStatePath is the directory where the sync and watch commands keep the fetched tracks
+	// of every synced playlist and the releases of every watched artist.
+	SyncStatePath string `mapstructure:"sync_state_path"`
+	// ArtistCheckpoints indicates whether the releases of an artist completed by a run are recorded
+	// in sync_state_path, so a run started again after an interruption skips them.
+	ArtistCheckpoints bool `mapstructure:"artist_checkpoints"`
+	// HistoryPath is the file every saved track is recorded in, queried by the history command.
+	HistoryPath string `mapstructure:"history_path"`
+	// SkipDownloadedTracks indicates whether tracks recorded in the history are skipped while their files exist.
+	SkipDownloadedTracks bool `mapstructure:"skip_downloaded_tracks"`
+	// RelinkReissues indicates whether the folder of an album saved by an earlier run is moved to the folder
+	// of its re-issue under a new release ID, found by the track IDs in the history, instead of downloading it again.
+	// It requires SkipDownloadedTracks.
+	RelinkReissues bool `mapstructure:"relink_reissues"`
+	// ExistingLibraryPaths are library folders outside output_path scanned at startup,
+	// whose tracks are skipped as already downloaded.
+	ExistingLibraryPaths []string `mapstructure:"existing_library_paths"`
+	// ReadyMarkerFilename is the file created in the folder of a collection once all its tracks are tagged
+	// and its covers are in place, so media server importers can wait for it (empty disables markers).
+	ReadyMarkerFilename string `mapstructure:"ready_marker_filename"`
+	// RemoteStorage is the remote storage completed collections are uploaded to as ZIP archives
+	// ("s3", "webdav", or "sftp", empty disables uploads).
+	RemoteStorage string `mapstructure:"remote_storage"`
+	// RemoteURL is the WebDAV or SFTP folder, or the S3 endpoint with the bucket and an optional prefix,
+	// archives go to.
+	RemoteURL string `mapstructure:"remote_url"`
+	// RemoteUsername is the WebDAV or SSH user name, or the S3 access key ID.
+	RemoteUsername string `mapstructure:"remote_username"`
+	// RemotePassword is the WebDAV password or the S3 secret access key (SFTP uses SSH keys instead).
+	RemotePassword string `mapstructure:"remote_password"`
+	// RemoteRegion is the S3 region requests are signed for.
+	RemoteRegion string `mapstructure:"remote_region"`
+	// RemoteDeleteLocal indicates whether the local folder of a collection is deleted once its archive is uploaded.
+	RemoteDeleteLocal bool `mapstructure:"remote_delete_local"`
+	// RcloneRemote is the rclone destination ("remote:path") the collections saved during a run are copied to
+	// after the run (empty disables rclone).
+	RcloneRemote string `mapstructure:"rclone_remote"`
+	// RcloneMode is the rclone command run for every collection ("copy" or "sync").
+	RcloneMode string `mapstructure:"rclone_mode"`
+	// RclonePath is the path to the rclone executable.
+	RclonePath string `mapstructure:"rclone_path"`
+	// RcloneArgs are extra arguments passed to every rclone command (e.g., "--transfers=8").
+	RcloneArgs []string `mapstructure:"rclone_args"`
+	// ServeAddress is the host and port the REST API of the serve command listens on.
+	ServeAddress string `mapstructure:"serve_address"`
+	// ServeToken is the bearer token required by the REST API of the serve command (empty disables the check).
+	ServeToken string `mapstructure:"serve_token"`
+	// ServeInsecure allows the REST API of the serve command to listen beyond this computer without serve_token.
+	ServeInsecure bool `mapstructure:"serve_insecure"`
 	// DryRun indicates whether to preview downloads without actually downloading files.
 	DryRun bool
+	// FailFast indicates whether to cancel the whole run on the first hard error.
+	FailFast bool
+	// MockServer indicates whether the run is served by the built-in mock server with a synthetic catalog
+	// instead of Zvuk, so no auth token is needed.
+	MockServer bool
+	// StateDir is the directory the relative state paths are resolved against (empty for the working directory),
+	// set from the Location of the configuration file.
+	StateDir string
+	// ConfigFilename is the path of the loaded configuration file, watched for changes with reload_config.
+	ConfigFilename string
+	// Profile is the name of the profile merged over the base settings of the configuration file (empty for none).
+	Profile string
+	// NoLock indicates whether to skip the lock file that keeps concurrent runs out of the output path.
+	NoLock bool
+	// TrackRanges are the positions of the collection tracks to download, e.g., "1-3,7,12-" (empty downloads all).
+	TrackRanges string
+	// JSONOutput indicates whether the summary is printed to standard output as a single JSON document,
+	// with the log sent to standard error.
+	JSONOutput bool
+	// DryRunFormat is how the dry-run plan of every collection is printed: table, json, or csv.
+	DryRunFormat string
+	// Interactive indicates whether the tracks of albums and playlists are chosen in a terminal picker.
+	Interactive bool
+	// LoginPhone is the phone number the browser login fills in, with the SMS code asked for in the terminal
+	// (empty leaves the whole login to the user).
+	LoginPhone string
 	// ParsedMinDuration is the parsed minimum track duration.
 	ParsedMinDuration time.Duration
 	// ParsedMaxDuration is the parsed maximum track duration.
 	ParsedMaxDuration time.Duration
+	// ParsedExcludePatterns are the compiled exclude_patterns.
+	ParsedExcludePatterns []*regexp.Regexp
+	// ParsedBlocklist is the parsed blocklist (nil when it is empty).
+	ParsedBlocklist *Blocklist
+	// ParsedMaxRunDuration is the parsed wall-clock budget of a run.
+	ParsedMaxRunDuration time.Duration
+	// ParsedAPIQuotaPause is the parsed pause taken once max_api_requests is used up.
+	ParsedAPIQuotaPause time.Duration
+	// ParsedDownloadWindows are the parsed download_window hours (nil allows downloads at any time).
+	ParsedDownloadWindows []DownloadWindow
+	// ParsedPoliteHours are the parsed polite_hours (nil disables the polite mode).
+	ParsedPoliteHours []DownloadWindow
+	// ParsedTrackRanges are the parsed track positions to download (nil downloads all).
+	ParsedTrackRanges []TrackRange
 	// ParsedDownloadSpeedLimit is the parsed download speed limit in bytes.
 	ParsedDownloadSpeedLimit int64
 	// ParsedLogLevel is the parsed zap log level.
@@ -94,11 +338,22 @@ type Config struct {
 	ParsedMinRetryPause time.Duration
 	// ParsedMaxRetryPause is the parsed maximum retry pause duration.
 	ParsedMaxRetryPause time.Duration
+	// ParsedTagWriteTimeout is the parsed tag writing timeout.
+	ParsedTagWriteTimeout time.Duration
+	// ParsedFLACCompressionLevel is the parsed flac_compression_level, used when it is set.
+	ParsedFLACCompressionLevel int64
+	// ParsedFLACPadding is the parsed flac_padding in bytes, used when it is set.
+	ParsedFLACPadding int64
 }
 
 const (
 	// ZvukBaseURL is the base URL for the Zvuk service.
 	ZvukBaseURL = "https://zvuk.com"
+	// DefaultZvukGraphQLPath is the default path of the GraphQL endpoint.
+	DefaultZvukGraphQLPath = "api/v1/graphql"
+
+	// isWindows reports whether output paths follow Windows rules (drive letters and UNC shares).
+	isWindows = runtime.GOOS == "windows"
 
 	// DefaultConfigFilename is the default name of the configuration file.
 	DefaultConfigFilename = ".zvuk-grabber.yaml"
@@ -124,6 +379,127 @@ const (
 	// DefaultPodcastEpisodeFilenameTemplate is the default template for naming podcast episode files.
 	DefaultPodcastEpisodeFilenameTemplate = "{{.episodePublicationDate}} - {{.trackTitle}}"
 
+	// DefaultLyricsFilenameTemplate is the default template for naming lyrics files: the name of the track file.
+	DefaultLyricsFilenameTemplate = "{{.trackFilename}}"
+
+	// DefaultLyricsExtension is the default file extension of lyrics files.
+	DefaultLyricsExtension = ".lrc"
+
+	// DefaultDescriptionFilenameTemplate is the default template for naming description files.
+	DefaultDescriptionFilenameTemplate = "description"
+
+	// DefaultDescriptionExtension is the default file extension of description files.
+	DefaultDescriptionExtension = ".txt"
+
+	// DefaultMetadataBatchSize is the default number of IDs per metadata request.
+	// It keeps the query string of a request well below common URL length limits.
+	DefaultMetadataBatchSize = 100
+	// DefaultTagWriteTimeout is the default tag writing timeout.
+	DefaultTagWriteTimeout = 2 * time.Minute
+	// DefaultFFmpegPath is the default ffmpeg executable, looked up in PATH.
+	DefaultFFmpegPath = "ffmpeg"
+	// DefaultAntiBotCookiesPath is the default file for the cookies of solved anti-bot challenges.
+	DefaultAntiBotCookiesPath = ".zvuk-grabber-cookies.json"
+	// DefaultBrowserDiagnosticsPath is the default directory for the diagnostics of failed browser flows.
+	DefaultBrowserDiagnosticsPath = ".zvuk-grabber-diagnostics"
+	// DefaultServeAddress is the default address of the REST API, reachable only from this computer.
+	DefaultServeAddress = "127.0.0.1:8080"
+	// DefaultResumeStatePath is the default file for the failed items replayed by the resume command.
+	DefaultResumeStatePath = ".zvuk-grabber-resume.json"
+	// DefaultSyncStatePath is the default directory for the state of the playlists synced by the sync command.
+	DefaultSyncStatePath = ".zvuk-grabber-sync"
+	// DefaultHistoryPath is the default file every saved track is recorded in.
+	DefaultHistoryPath = ".zvuk-grabber-history.jsonl"
+	// DefaultRemoteRegion is the default S3 region requests are signed for.
+	DefaultRemoteRegion = "us-east-1"
+	// DefaultRclonePath is the default rclone executable, looked up in PATH.
+	DefaultRclonePath = "rclone"
+	// ArtistJoinStyleOriginal keeps the artist names as returned by the API, separated by commas.
+	ArtistJoinStyleOriginal = "original"
+	// ArtistJoinStyleComma lists every credited artist separated by commas.
+	ArtistJoinStyleComma = "comma"
+	// ArtistJoinStyleSemicolon lists every credited artist separated by semicolons.
+	ArtistJoinStyleSemicolon = "semicolon"
+	// ArtistJoinStyleFeat lists the main artists followed by "feat." and the featured artists.
+	ArtistJoinStyleFeat = "feat"
+	// PlaylistDuplicatesSkip saves a repeated playlist track only once.
+	PlaylistDuplicatesSkip = "skip"
+	// PlaylistDuplicatesNumbered saves every occurrence, adding " (2)", " (3)", etc. to repeated filenames.
+	PlaylistDuplicatesNumbered = "numbered"
+	// PlaylistDuplicatesHardlink downloads a repeated track once and hardlinks the other occurrences.
+	PlaylistDuplicatesHardlink = "hardlink"
+	// PlaylistLayoutFolder saves playlist tracks into the playlist folder.
+	PlaylistLayoutFolder = "folder"
+	// PlaylistLayoutLibrary saves playlist tracks into their album folders
+	// and writes an M3U playlist referencing them into the playlist folder.
+	PlaylistLayoutLibrary = "library"
+	// DownloadOrderPosition downloads the tracks of a collection in their order in the collection.
+	DownloadOrderPosition = "position"
+	// DownloadOrderAlphabetical downloads the tracks of a collection ordered by their titles.
+	DownloadOrderAlphabetical = "alphabetical"
+	// DownloadOrderShuffle downloads the tracks of a collection in random order.
+	DownloadOrderShuffle = "shuffle"
+	// DownloadOrderSmallestFirst downloads the shortest tracks of a collection first.
+	DownloadOrderSmallestFirst = "smallest-first"
+	// ProgressBar shows a progress bar for every track downloaded one at a time.
+	ProgressBar = "bar"
+	// ProgressPlain prints one line per track event (start, download, skip, failure) for scripts.
+	ProgressPlain = "plain"
+	// DryRunFormatTable prints the dry-run plan of every collection as an aligned table.
+	DryRunFormatTable = "table"
+	// DryRunFormatJSON prints the dry-run plan of every collection as a JSON object on its own line.
+	DryRunFormatJSON = "json"
+	// DryRunFormatCSV prints the dry-run plan of every collection as CSV rows under a single header.
+	DryRunFormatCSV = "csv"
+	// RcloneModeCopy copies new and changed files, never deleting anything on the remote.
+	RcloneModeCopy = "copy"
+	// RcloneModeSync makes every collection folder on the remote identical to the local one.
+	RcloneModeSync = "sync"
+	// RemoteStorageS3 uploads archives to an S3-compatible object storage.
+	RemoteStorageS3 = "s3"
+	// RemoteStorageWebDAV uploads archives to a WebDAV server.
+	RemoteStorageWebDAV = "webdav"
+	// RemoteStorageSFTP uploads archives to an SSH server with the OpenSSH sftp client.
+	RemoteStorageSFTP = "sftp"
+	// APIQuotaActionStop stops starting new tracks once max_api_requests is used up.
+	APIQuotaActionStop = "stop"
+	// APIQuotaActionPause pauses new tracks for api_quota_pause once max_api_requests is used up.
+	APIQuotaActionPause = "pause"
+	// DefaultAPIQuotaPause is the default pause taken once max_api_requests is used up.
+	DefaultAPIQuotaPause = time.Hour
+	// DefaultPolitePauseMultiplier is the default multiplier of max_download_pause during polite_hours.
+	DefaultPolitePauseMultiplier = 3
+	// DefaultPoliteMaxConcurrentDownloads is the default number of tracks downloaded at once during polite_hours.
+	DefaultPoliteMaxConcurrentDownloads = 1
+	// UntaggedAudioSuffix keeps the audio next to the track with the ".untagged" suffix.
+	UntaggedAudioSuffix = "suffix"
+	// UntaggedAudioMarker saves the audio under the final name with an " [untagged]" marker.
+	UntaggedAudioMarker = "marker"
+	// UntaggedAudioDelete deletes the audio.
+	UntaggedAudioDelete = "delete"
+	// PortableFormatMP3 is the portable copy format encoded with libmp3lame.
+	PortableFormatMP3 = "mp3"
+	// PortableFormatOpus is the portable copy format encoded with libopus.
+	PortableFormatOpus = "opus"
+	// DefaultPortableBitrate is the default bitrate of portable copies.
+	DefaultPortableBitrate = "320k"
+	// DefaultWaveTrackCount is the default number of tracks downloaded from a wave.
+	DefaultWaveTrackCount = 50
+	// MaxWaveTrackCount is the largest number of tracks downloaded from a wave.
+	MaxWaveTrackCount = 500
+	// AuthTokenStorageConfig saves the auth token to the configuration file.
+	AuthTokenStorageConfig = "config"
+	// AuthTokenStorageKeyring saves the auth token to the credential store of the operating system.
+	AuthTokenStorageKeyring = "keyring"
+	// EmbeddedCoverFull embeds the cover into the audio files as downloaded.
+	EmbeddedCoverFull = "full"
+	// EmbeddedCoverThumbnail embeds the cover scaled down to embedded_cover_size.
+	EmbeddedCoverThumbnail = "thumbnail"
+	// EmbeddedCoverNone embeds no cover and removes the pictures already in the audio files.
+	EmbeddedCoverNone = "none"
+	// DefaultEmbeddedCoverSize is the default largest side in pixels of the embedded thumbnail.
+	DefaultEmbeddedCoverSize = 300
+
 	// DefaultMaxLogLength is the default maximum size (in bytes) for log files.
 	DefaultMaxLogLength = 1 * 1024 * 1024 // 1 MB
 
@@ -131,12 +507,26 @@ const (
 	minQuality = 1
 	// maxQuality is the maximum valid quality value.
 	maxQuality = 3
+	// minNormalizationTargetLUFS is the lowest integrated loudness target accepted by ffmpeg loudnorm.
+	minNormalizationTargetLUFS = -70
+	// maxNormalizationTargetLUFS is the highest integrated loudness target accepted by ffmpeg loudnorm.
+	maxNormalizationTargetLUFS = -5
+	// minFLACCompressionLevel is the fastest FLAC compression level.
+	minFLACCompressionLevel = 0
+	// maxFLACCompressionLevel is the strongest FLAC compression level supported by ffmpeg.
+	maxFLACCompressionLevel = 12
+	// maxFLACPadding is the largest FLAC metadata block, whose length is a 24-bit number.
+	maxFLACPadding = 1<<24 - 1
 )
 
 // Static error definitions for better error handling.
 var (
 	// ErrEmptyAuthToken indicates that the authentication token is missing.
 	ErrEmptyAuthToken = errors.New("authentication token cannot be empty")
+	// ErrInvalidZvukBaseURL indicates that the Zvuk API base URL is not an absolute HTTP URL.
+	ErrInvalidZvukBaseURL = errors.New("invalid zvuk_base_url")
+	// ErrInvalidProxyURL indicates that the proxy URL is not an HTTP, HTTPS, or SOCKS5 URL.
+	ErrInvalidProxyURL = errors.New("invalid proxy_url")
 	// ErrInvalidQuality indicates that the quality setting is invalid.
 	ErrInvalidQuality = errors.New("invalid quality")
 	// ErrInvalidMinQuality indicates that the minimum quality setting is invalid.
@@ -149,6 +539,22 @@ var (
 	ErrInvalidMaxDuration = errors.New("max_duration must be positive")
 	// ErrMaxDurationTooLow indicates that max_duration is not greater than min_duration.
 	ErrMaxDurationTooLow = errors.New("max_duration must be greater than min_duration")
+	// ErrInvalidPerArtistLimit indicates that the per-artist release limit is negative.
+	ErrInvalidPerArtistLimit = errors.New("per_artist_limit cannot be negative")
+	// ErrInvalidSummaryErrorLimit indicates that the summary error limit is negative.
+	ErrInvalidSummaryErrorLimit = errors.New("summary_error_limit cannot be negative")
+	// ErrInvalidMaxRunDuration indicates that the run time limit is invalid.
+	ErrInvalidMaxRunDuration = errors.New("max_run_duration must be positive")
+	// ErrInvalidMaxAPIRequests indicates that the API request limit is negative.
+	ErrInvalidMaxAPIRequests = errors.New("max_api_requests cannot be negative")
+	// ErrInvalidAPIQuotaAction indicates that the action taken once the API request limit is used up is not supported.
+	ErrInvalidAPIQuotaAction = errors.New("invalid api_quota_action")
+	// ErrInvalidPolitePauseMultiplier indicates that the polite mode pause multiplier is negative.
+	ErrInvalidPolitePauseMultiplier = errors.New("polite_pause_multiplier must be a positive integer")
+	// ErrInvalidPoliteConcurrentDownloads indicates that the polite mode concurrent downloads count is negative.
+	ErrInvalidPoliteConcurrentDownloads = errors.New("polite_max_concurrent_downloads must be a positive integer")
+	// ErrInvalidAPIQuotaPause indicates that the pause taken once the API request limit is used up is invalid.
+	ErrInvalidAPIQuotaPause = errors.New("api_quota_pause must be positive")
 	// ErrUnknownLogLevel indicates that the log level is not recognized.
 	ErrUnknownLogLevel = errors.New("unknown log level")
 	// ErrInvalidRetryAttempts indicates that the retry attempts count is invalid.
@@ -159,20 +565,105 @@ var (
 	ErrInvalidMinRetryPause = errors.New("min_retry_pause must be positive")
 	// ErrInvalidMaxRetryPause indicates that the max retry pause duration is invalid.
 	ErrInvalidMaxRetryPause = errors.New("max_retry_pause must be positive")
+	// ErrInvalidTagWriteTimeout indicates that the tag writing timeout is invalid.
+	ErrInvalidTagWriteTimeout = errors.New("tag_write_timeout must be positive")
 	// ErrInvalidConcurrentDownloads indicates that the concurrent downloads count is invalid.
 	ErrInvalidConcurrentDownloads = errors.New("max concurrent downloads must be a positive integer")
+	// ErrInvalidMetadataBatchSize indicates that the metadata batch size is negative.
+	ErrInvalidMetadataBatchSize = errors.New("metadata_batch_size cannot be negative")
+	// ErrInvalidMaxConsecutiveFailures indicates that the consecutive failures limit is invalid.
+	ErrInvalidMaxConsecutiveFailures = errors.New("max_consecutive_failures cannot be negative")
+	// ErrInvalidNormalizedOutputPath indicates that the normalized copies directory is invalid.
+	ErrInvalidNormalizedOutputPath = errors.New("normalized_output_path must be set and differ from output_path")
+	// ErrInvalidNormalizationTarget indicates that the loudness target is out of range.
+	ErrInvalidNormalizationTarget = errors.New("invalid normalization_target_lufs")
+	// ErrInvalidArtistJoinStyle indicates that the artist join style is not supported.
+	ErrInvalidArtistJoinStyle = errors.New("invalid artist_join_style")
+	// ErrInvalidPlaylistDuplicates indicates that the duplicate playlist tracks policy is not supported.
+	ErrInvalidPlaylistDuplicates = errors.New("invalid playlist_duplicates")
+	// ErrInvalidDownloadOrder indicates that the track download order is not supported.
+	ErrInvalidDownloadOrder = errors.New("invalid download_order")
+	// ErrInvalidProgress indicates that the progress style is not supported.
+	ErrInvalidProgress = errors.New("invalid progress")
+	// ErrInvalidDryRunFormat indicates that the dry-run plan format is not supported.
+	ErrInvalidDryRunFormat = errors.New("invalid dry-run format")
+	// ErrInvalidPlaylistLayout indicates that the playlist layout is not supported.
+	ErrInvalidPlaylistLayout = errors.New("invalid playlist_layout")
+	// ErrInvalidUntaggedAudio indicates that the untagged audio mode is not supported.
+	ErrInvalidUntaggedAudio = errors.New("invalid untagged_audio")
+	// ErrInvalidSidecarExtension indicates that a lyrics or description file extension is malformed.
+	ErrInvalidSidecarExtension = errors.New("invalid sidecar file extension")
+	// ErrOutputPathInTempDir indicates that the output directory is inside the system temporary directory.
+	ErrOutputPathInTempDir = errors.New("output_path must not be inside the temporary directory")
+	// ErrInvalidOutputPathFormat indicates that an output directory path is malformed for the current OS.
+	ErrInvalidOutputPathFormat = errors.New("invalid output path")
+	// ErrInvalidUpgradeQuarantinePath indicates that the quarantine directory is the output directory.
+	ErrInvalidUpgradeQuarantinePath = errors.New("upgrade_quarantine_path must differ from output_path")
+	// ErrRelinkReissuesWithoutSkip indicates that re-issues are relinked while saved tracks are downloaded again.
+	ErrRelinkReissuesWithoutSkip = errors.New("relink_reissues requires skip_downloaded_tracks")
+	// ErrInvalidReadyMarkerFilename indicates that the ready marker is not a plain file name.
+	ErrInvalidReadyMarkerFilename = errors.New("invalid ready_marker_filename")
+	// ErrInvalidRemoteStorage indicates that the remote storage type is not supported.
+	ErrInvalidRemoteStorage = errors.New("invalid remote_storage")
+	// ErrInvalidRemoteURL indicates that the remote storage URL is malformed.
+	ErrInvalidRemoteURL = errors.New("invalid remote_url")
+	// ErrInvalidRcloneRemote indicates that the rclone destination is not in the remote:path form.
+	ErrInvalidRcloneRemote = errors.New("invalid rclone_remote")
+	// ErrInvalidRcloneMode indicates that the rclone command is not supported.
+	ErrInvalidRcloneMode = errors.New("invalid rclone_mode")
+	// ErrMissingRemoteCredentials indicates that the S3 access keys are not set.
+	ErrMissingRemoteCredentials = errors.New("remote_username and remote_password are required for S3")
+	// ErrUnsupportedRemotePassword indicates that a password is set for SFTP, which authenticates with SSH keys.
+	ErrUnsupportedRemotePassword = errors.New("remote_password is not supported for SFTP, use SSH keys or an agent")
+	// ErrInvalidPortableOutputPath indicates that the portable copies directory collides with another output.
+	ErrInvalidPortableOutputPath = errors.New("portable_output_path must differ from output_path")
+	// ErrInvalidPortableFormat indicates that the portable copy format is not supported.
+	ErrInvalidPortableFormat = errors.New("invalid portable_format")
+	// ErrInvalidFLACCompressionLevel indicates that the FLAC compression level is not a number from 0 to 12.
+	ErrInvalidFLACCompressionLevel = errors.New("invalid flac_compression_level")
+	// ErrInvalidFLACPadding indicates that the FLAC padding is not a size or is too large.
+	ErrInvalidFLACPadding = errors.New("invalid flac_padding")
+	// ErrInvalidAuthTokenStorage indicates that the auth token storage is not supported.
+	ErrInvalidAuthTokenStorage = errors.New("invalid auth_token_storage")
+	// ErrInvalidWaveTrackCount indicates that the number of wave tracks is out of range.
+	ErrInvalidWaveTrackCount = errors.New("invalid wave_track_count")
+	// ErrInvalidEmbeddedCover indicates that the embedded cover mode is not supported.
+	ErrInvalidEmbeddedCover = errors.New("invalid embedded_cover")
+	// ErrInvalidEmbeddedCoverSize indicates that the embedded thumbnail size is negative.
+	ErrInvalidEmbeddedCoverSize = errors.New("invalid embedded_cover_size")
 )
 
-// LoadConfig loads configuration settings from a YAML file.
+// LoadConfig loads configuration settings from a YAML, TOML, or JSON file, told apart by its extension,
+// overridden by the ZVUK_ environment variables.
 func LoadConfig(configFilename string) (*Config, error) {
+	return LoadConfigProfile(configFilename, "")
+}
+
+// LoadConfigProfile loads configuration settings from a file like LoadConfig,
+// merging the settings of the named profile from the profiles section over the base ones.
+// An empty profile name loads the base settings only.
+// The ZVUK_ environment variables override both (see EnvVarName).
+func LoadConfigProfile(configFilename, profileName string) (*Config, error) {
 	if configFilename == "" {
 		configFilename = DefaultConfigFilename
 	}
 
-	viper.SetConfigFile(configFilename)
+	if err := readInConfig(configFilename); err != nil {
+		return nil, err
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config from file: %w", err)
+	if err := checkUnknownKeys(); err != nil {
+		return nil, err
+	}
+
+	if profileName != "" {
+		if err := applyProfile(profileName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bindEnvironment(); err != nil {
+		return nil, err
 	}
 
 	var cfg Config
@@ -180,26 +671,72 @@ func LoadConfig(configFilename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	cfg.Profile = profileName
+
 	return &cfg, nil
 }
 
+// readInConfig reads the configuration file into viper, decrypting it when it is encrypted.
+func readInConfig(configFilename string) error {
+	viper.SetConfigFile(configFilename)
+	viper.SetConfigType(configFormat(configFilename))
+
+	content, isEncrypted, err := readConfigContent(configFilename)
+	if err == nil && !isEncrypted {
+		// Plain files are read by viper itself, which reports their errors as before.
+		err = viper.ReadInConfig()
+	} else if err == nil {
+		err = viper.ReadConfig(bytes.NewReader(content))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read config from file: %w", err)
+	}
+
+	return nil
+}
+
 // ValidateConfig checks the configuration for validity and sets derived fields.
+func ValidateConfig(cfg *Config) error {
+	if strings.TrimSpace(cfg.AuthToken) == "" && !cfg.MockServer {
+		return ErrEmptyAuthToken
+	}
+
+	return ValidateConfigWithoutAuthToken(cfg)
+}
+
+// ValidateConfigWithoutAuthToken checks the configuration like ValidateConfig but allows an empty auth token,
+// so the auth login command can run before a token has been obtained.
+// The settings checked by separate validators do not depend on each other, so the problems of all of them
+// are reported together.
+func ValidateConfigWithoutAuthToken(cfg *Config) error {
+	return errors.Join(
+		validateSettings(cfg),
+		validateZvukAPI(cfg),
+		validateProxy(cfg),
+		validateAPIQuota(cfg),
+		validatePoliteMode(cfg),
+		validatePortableOutput(cfg),
+		validateFLACEncoding(cfg),
+		validateAuthTokenStorage(cfg),
+		validateEmbeddedCover(cfg),
+		validateWaveTrackCount(cfg),
+		validateRemoteStorage(cfg),
+		validateRclone(cfg),
+	)
+}
+
+// validateSettings checks the settings depending on each other in order, stopping at the first problem,
+// and sets the derived fields.
 //
 //nolint:funlen,gocognit,cyclop // Validation functions naturally have high complexity and length due to sequential checks.
-func ValidateConfig(cfg *Config) error {
+func validateSettings(cfg *Config) error {
 	var (
 		downloadSpeedLimit       = strings.TrimSpace(cfg.DownloadSpeedLimit)
 		parsedDownloadSpeedLimit uint64
 		err                      error
 	)
 
-	authToken := strings.TrimSpace(cfg.AuthToken)
-	if authToken == "" {
-		return ErrEmptyAuthToken
-	}
-
-	cfg.ZvukBaseURL = ZvukBaseURL
-
 	if cfg.Quality < minQuality || cfg.Quality > maxQuality {
 		return fmt.Errorf("%w: must be between %d and %d", ErrInvalidQuality, minQuality, maxQuality)
 	}
@@ -245,6 +782,24 @@ func ValidateConfig(cfg *Config) error {
 		}
 	}
 
+	cfg.ParsedExcludePatterns = make([]*regexp.Regexp, 0, len(cfg.ExcludePatterns))
+
+	for _, pattern := range cfg.ExcludePatterns {
+		var parsedPattern *regexp.Regexp
+
+		parsedPattern, err = regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("failed to parse exclude pattern '%s': %w", pattern, err)
+		}
+
+		cfg.ParsedExcludePatterns = append(cfg.ParsedExcludePatterns, parsedPattern)
+	}
+
+	cfg.ParsedBlocklist, err = ParseBlocklist(cfg.Blocklist)
+	if err != nil {
+		return err
+	}
+
 	parsedLogLevel, isLogLevelCorrect := logger.ParseLogLevel(cfg.LogLevel)
 	if !(isLogLevelCorrect) {
 		return fmt.Errorf("%w: '%s'", ErrUnknownLogLevel, cfg.LogLevel)
@@ -293,46 +848,669 @@ func ValidateConfig(cfg *Config) error {
 		return ErrInvalidMaxRetryPause
 	}
 
+	cfg.ParsedTagWriteTimeout = DefaultTagWriteTimeout
+	if strings.TrimSpace(cfg.TagWriteTimeout) != "" {
+		cfg.ParsedTagWriteTimeout, err = time.ParseDuration(cfg.TagWriteTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse tag write timeout: %w", err)
+		}
+
+		if cfg.ParsedTagWriteTimeout <= 0 {
+			return ErrInvalidTagWriteTimeout
+		}
+	}
+
+	cfg.UntaggedAudio = strings.ToLower(strings.TrimSpace(cfg.UntaggedAudio))
+	switch cfg.UntaggedAudio {
+	case "":
+		cfg.UntaggedAudio = UntaggedAudioSuffix
+	case UntaggedAudioSuffix, UntaggedAudioMarker, UntaggedAudioDelete:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s", ErrInvalidUntaggedAudio, cfg.UntaggedAudio,
+			UntaggedAudioSuffix, UntaggedAudioMarker, UntaggedAudioDelete)
+	}
+
 	if cfg.MaxConcurrentDownloads <= 0 {
 		return ErrInvalidConcurrentDownloads
 	}
 
+	switch {
+	case cfg.MetadataBatchSize == 0:
+		cfg.MetadataBatchSize = DefaultMetadataBatchSize
+	case cfg.MetadataBatchSize < 0:
+		return ErrInvalidMetadataBatchSize
+	}
+
+	if cfg.MaxConsecutiveFailures < 0 {
+		return ErrInvalidMaxConsecutiveFailures
+	}
+
+	if cfg.PerArtistLimit < 0 {
+		return ErrInvalidPerArtistLimit
+	}
+
+	if cfg.SummaryErrorLimit < 0 {
+		return ErrInvalidSummaryErrorLimit
+	}
+
+	// Parse max_run_duration if set (empty string means no limit).
+	if cfg.MaxRunDuration != "" {
+		cfg.ParsedMaxRunDuration, err = time.ParseDuration(cfg.MaxRunDuration)
+		if err != nil {
+			return fmt.Errorf("failed to parse max run duration: %w", err)
+		}
+
+		if cfg.ParsedMaxRunDuration <= 0 {
+			return ErrInvalidMaxRunDuration
+		}
+	}
+
+	if strings.TrimSpace(cfg.DownloadWindow) != "" {
+		cfg.ParsedDownloadWindows, err = ParseDownloadWindows(cfg.DownloadWindow)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := validateOutputPathFormat("output_path", cfg.OutputPath, isWindows); err != nil {
+		return err
+	}
+
+	if err := validateOutputPathOutsideTempDir(cfg.OutputPath); err != nil {
+		return err
+	}
+
+	if cfg.NormalizeLoudness {
+		normalizedOutputPath := strings.TrimSpace(cfg.NormalizedOutputPath)
+		if normalizedOutputPath == "" || filepath.Clean(normalizedOutputPath) == filepath.Clean(cfg.OutputPath) {
+			return ErrInvalidNormalizedOutputPath
+		}
+
+		err := validateOutputPathFormat("normalized_output_path", normalizedOutputPath, isWindows)
+		if err != nil {
+			return err
+		}
+
+		if cfg.NormalizationTargetLUFS < minNormalizationTargetLUFS ||
+			cfg.NormalizationTargetLUFS > maxNormalizationTargetLUFS {
+			return fmt.Errorf("%w: must be between %d and %d",
+				ErrInvalidNormalizationTarget, minNormalizationTargetLUFS, maxNormalizationTargetLUFS)
+		}
+	}
+
+	if strings.TrimSpace(cfg.FFmpegPath) == "" {
+		cfg.FFmpegPath = DefaultFFmpegPath
+	}
+
+	if strings.TrimSpace(cfg.AntiBotCookiesPath) == "" {
+		cfg.AntiBotCookiesPath = DefaultAntiBotCookiesPath
+	}
+
+	if strings.TrimSpace(cfg.BrowserDiagnosticsPath) == "" {
+		cfg.BrowserDiagnosticsPath = DefaultBrowserDiagnosticsPath
+	}
+
+	if strings.TrimSpace(cfg.ServeAddress) == "" {
+		cfg.ServeAddress = DefaultServeAddress
+	}
+
+	if strings.TrimSpace(cfg.ResumeStatePath) == "" {
+		cfg.ResumeStatePath = DefaultResumeStatePath
+	}
+
+	if strings.TrimSpace(cfg.SyncStatePath) == "" {
+		cfg.SyncStatePath = DefaultSyncStatePath
+	}
+
+	if strings.TrimSpace(cfg.HistoryPath) == "" {
+		cfg.HistoryPath = DefaultHistoryPath
+	}
+
+	cfg.LyricsExtension, err = normalizeSidecarExtension("lyrics_extension", cfg.LyricsExtension, DefaultLyricsExtension)
+	if err != nil {
+		return err
+	}
+
+	cfg.DescriptionExtension, err = normalizeSidecarExtension(
+		"description_extension", cfg.DescriptionExtension, DefaultDescriptionExtension)
+	if err != nil {
+		return err
+	}
+
+	cfg.UpgradeWatchPath = strings.TrimSpace(cfg.UpgradeWatchPath)
+
+	ResolveStatePaths(cfg)
+
+	cfg.UpgradeQuarantinePath = strings.TrimSpace(cfg.UpgradeQuarantinePath)
+	if cfg.UpgradeQuarantinePath != "" &&
+		filepath.Clean(cfg.UpgradeQuarantinePath) == filepath.Clean(cfg.OutputPath) {
+		return ErrInvalidUpgradeQuarantinePath
+	}
+
+	// The moved tracks are skipped by their new paths in the history, which only skip_downloaded_tracks reads.
+	if cfg.RelinkReissues && !cfg.SkipDownloadedTracks {
+		return ErrRelinkReissuesWithoutSkip
+	}
+
+	cfg.ArtistJoinStyle = strings.ToLower(strings.TrimSpace(cfg.ArtistJoinStyle))
+	switch cfg.ArtistJoinStyle {
+	case "":
+		cfg.ArtistJoinStyle = ArtistJoinStyleOriginal
+	case ArtistJoinStyleOriginal, ArtistJoinStyleComma, ArtistJoinStyleSemicolon, ArtistJoinStyleFeat:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s, %s", ErrInvalidArtistJoinStyle, cfg.ArtistJoinStyle,
+			ArtistJoinStyleOriginal, ArtistJoinStyleComma, ArtistJoinStyleSemicolon, ArtistJoinStyleFeat)
+	}
+
+	cfg.PlaylistDuplicates = strings.ToLower(strings.TrimSpace(cfg.PlaylistDuplicates))
+	switch cfg.PlaylistDuplicates {
+	case "":
+		cfg.PlaylistDuplicates = PlaylistDuplicatesNumbered
+	case PlaylistDuplicatesSkip, PlaylistDuplicatesNumbered, PlaylistDuplicatesHardlink:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s", ErrInvalidPlaylistDuplicates, cfg.PlaylistDuplicates,
+			PlaylistDuplicatesSkip, PlaylistDuplicatesNumbered, PlaylistDuplicatesHardlink)
+	}
+
+	cfg.PlaylistLayout = strings.ToLower(strings.TrimSpace(cfg.PlaylistLayout))
+	switch cfg.PlaylistLayout {
+	case "":
+		cfg.PlaylistLayout = PlaylistLayoutFolder
+	case PlaylistLayoutFolder, PlaylistLayoutLibrary:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s", ErrInvalidPlaylistLayout, cfg.PlaylistLayout,
+			PlaylistLayoutFolder, PlaylistLayoutLibrary)
+	}
+
+	cfg.DownloadOrder = strings.ToLower(strings.TrimSpace(cfg.DownloadOrder))
+	switch cfg.DownloadOrder {
+	case "":
+		cfg.DownloadOrder = DownloadOrderPosition
+	case DownloadOrderPosition, DownloadOrderAlphabetical, DownloadOrderShuffle, DownloadOrderSmallestFirst:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s, %s", ErrInvalidDownloadOrder, cfg.DownloadOrder,
+			DownloadOrderPosition, DownloadOrderAlphabetical, DownloadOrderShuffle, DownloadOrderSmallestFirst)
+	}
+
+	cfg.Progress = strings.ToLower(strings.TrimSpace(cfg.Progress))
+	switch cfg.Progress {
+	case "":
+		cfg.Progress = ProgressBar
+	case ProgressBar, ProgressPlain:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s", ErrInvalidProgress, cfg.Progress,
+			ProgressBar, ProgressPlain)
+	}
+
+	cfg.DryRunFormat = strings.ToLower(strings.TrimSpace(cfg.DryRunFormat))
+	switch cfg.DryRunFormat {
+	case "":
+		cfg.DryRunFormat = DryRunFormatTable
+	case DryRunFormatTable, DryRunFormatJSON, DryRunFormatCSV:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s", ErrInvalidDryRunFormat, cfg.DryRunFormat,
+			DryRunFormatTable, DryRunFormatJSON, DryRunFormatCSV)
+	}
+
+	if strings.TrimSpace(cfg.TrackRanges) != "" {
+		cfg.ParsedTrackRanges, err = ParseTrackRanges(cfg.TrackRanges)
+		if err != nil {
+			return err
+		}
+	}
+
+	existingLibraryPaths := make([]string, 0, len(cfg.ExistingLibraryPaths))
+	for _, libraryPath := range cfg.ExistingLibraryPaths {
+		if libraryPath = strings.TrimSpace(libraryPath); libraryPath != "" {
+			existingLibraryPaths = append(existingLibraryPaths, filepath.Clean(libraryPath))
+		}
+	}
+
+	cfg.ExistingLibraryPaths = existingLibraryPaths
+
+	cfg.ReadyMarkerFilename = strings.TrimSpace(cfg.ReadyMarkerFilename)
+	if strings.ContainsAny(cfg.ReadyMarkerFilename, `/\`) ||
+		cfg.ReadyMarkerFilename == "." || cfg.ReadyMarkerFilename == ".." {
+		return fmt.Errorf("%w '%s': must be a file name without folders, e.g. '.complete'",
+			ErrInvalidReadyMarkerFilename, cfg.ReadyMarkerFilename)
+	}
+
 	return nil
 }
 
-// SaveConfig saves the configuration to the file while preserving the original format and order.
-func SaveConfig(cfg *Config) error {
-	configFile := getConfigFilePath()
+// validateRclone checks the rclone settings and fills in their defaults.
+func validateRclone(cfg *Config) error {
+	cfg.RcloneRemote = strings.TrimSpace(cfg.RcloneRemote)
+	if cfg.RcloneRemote == "" {
+		return nil
+	}
 
-	// Read the original file content.
-	originalContent, err := os.ReadFile(configFile)
+	if remoteName, _, ok := strings.Cut(cfg.RcloneRemote, ":"); !ok || remoteName == "" {
+		return fmt.Errorf("%w '%s': must be a configured rclone remote followed by a path, e.g. 'gdrive:Music'",
+			ErrInvalidRcloneRemote, cfg.RcloneRemote)
+	}
+
+	cfg.RcloneMode = strings.ToLower(strings.TrimSpace(cfg.RcloneMode))
+	switch cfg.RcloneMode {
+	case "":
+		cfg.RcloneMode = RcloneModeCopy
+	case RcloneModeCopy, RcloneModeSync:
+	default:
+		return fmt.Errorf("%w '%s': must be '%s' or '%s'",
+			ErrInvalidRcloneMode, cfg.RcloneMode, RcloneModeCopy, RcloneModeSync)
+	}
+
+	if strings.TrimSpace(cfg.RclonePath) == "" {
+		cfg.RclonePath = DefaultRclonePath
+	}
+
+	return nil
+}
+
+// validateAPIQuota checks the API request limit settings and fills in their defaults.
+func validateAPIQuota(cfg *Config) error {
+	if cfg.MaxAPIRequests < 0 {
+		return ErrInvalidMaxAPIRequests
+	}
+
+	cfg.APIQuotaAction = strings.ToLower(strings.TrimSpace(cfg.APIQuotaAction))
+	switch cfg.APIQuotaAction {
+	case "":
+		cfg.APIQuotaAction = APIQuotaActionStop
+	case APIQuotaActionStop, APIQuotaActionPause:
+	default:
+		return fmt.Errorf("%w '%s': must be '%s' or '%s'",
+			ErrInvalidAPIQuotaAction, cfg.APIQuotaAction, APIQuotaActionStop, APIQuotaActionPause)
+	}
+
+	cfg.ParsedAPIQuotaPause = DefaultAPIQuotaPause
+	if strings.TrimSpace(cfg.APIQuotaPause) != "" {
+		var err error
+
+		cfg.ParsedAPIQuotaPause, err = time.ParseDuration(cfg.APIQuotaPause)
+		if err != nil {
+			return fmt.Errorf("failed to parse API quota pause: %w", err)
+		}
+
+		if cfg.ParsedAPIQuotaPause <= 0 {
+			return ErrInvalidAPIQuotaPause
+		}
+	}
+
+	return nil
+}
+
+// validatePoliteMode checks the polite mode settings and fills in their defaults.
+func validatePoliteMode(cfg *Config) error {
+	if cfg.PolitePauseMultiplier < 0 {
+		return ErrInvalidPolitePauseMultiplier
+	}
+
+	if cfg.PolitePauseMultiplier == 0 {
+		cfg.PolitePauseMultiplier = DefaultPolitePauseMultiplier
+	}
+
+	if cfg.PoliteMaxConcurrentDownloads < 0 {
+		return ErrInvalidPoliteConcurrentDownloads
+	}
+
+	if cfg.PoliteMaxConcurrentDownloads == 0 {
+		cfg.PoliteMaxConcurrentDownloads = DefaultPoliteMaxConcurrentDownloads
+	}
+
+	cfg.ParsedPoliteHours = nil
+	if strings.TrimSpace(cfg.PoliteHours) == "" {
+		return nil
+	}
+
+	var err error
+
+	cfg.ParsedPoliteHours, err = ParseDownloadWindows(cfg.PoliteHours)
 	if err != nil {
-		return handleMissingConfigFile(configFile, cfg.AuthToken, err)
+		return fmt.Errorf("invalid polite_hours: %w", err)
+	}
+
+	return nil
+}
+
+// validateZvukAPI checks the Zvuk API endpoint settings and fills in their defaults.
+func validateZvukAPI(cfg *Config) error {
+	cfg.ZvukBaseURL = strings.TrimRight(strings.TrimSpace(cfg.ZvukBaseURL), "/")
+	if cfg.ZvukBaseURL == "" {
+		cfg.ZvukBaseURL = ZvukBaseURL
+	}
+
+	baseURL, err := url.Parse(cfg.ZvukBaseURL)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return fmt.Errorf("%w '%s': must be an http or https URL", ErrInvalidZvukBaseURL, cfg.ZvukBaseURL)
+	}
+
+	cfg.ZvukGraphQLPath = strings.Trim(strings.TrimSpace(cfg.ZvukGraphQLPath), "/")
+	if cfg.ZvukGraphQLPath == "" {
+		cfg.ZvukGraphQLPath = DefaultZvukGraphQLPath
+	}
+
+	cfg.APIDumpPath = strings.TrimSpace(cfg.APIDumpPath)
+
+	return nil
+}
+
+// validateProxy checks the proxy URL and trims the hosts reached without it.
+func validateProxy(cfg *Config) error {
+	cfg.ProxyURL = strings.TrimSpace(cfg.ProxyURL)
+
+	noProxy := make([]string, 0, len(cfg.NoProxy))
+	for _, host := range cfg.NoProxy {
+		if host = strings.TrimSpace(host); host != "" {
+			noProxy = append(noProxy, host)
+		}
+	}
+
+	cfg.NoProxy = noProxy
+
+	if cfg.ProxyURL == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(cfg.ProxyURL)
+	if err != nil || proxyURL.Host == "" {
+		return fmt.Errorf("%w: must be an absolute URL, e.g., socks5://127.0.0.1:1080", ErrInvalidProxyURL)
+	}
+
+	switch strings.ToLower(proxyURL.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	default:
+		// The URL is left out of the error, since it may hold the proxy password.
+		return fmt.Errorf("%w: the scheme must be http, https, socks5, or socks5h, not '%s'",
+			ErrInvalidProxyURL, proxyURL.Scheme)
+	}
+}
+
+// validateRemoteStorage checks the remote storage settings and fills in their defaults.
+func validateRemoteStorage(cfg *Config) error {
+	cfg.RemoteStorage = strings.ToLower(strings.TrimSpace(cfg.RemoteStorage))
+	switch cfg.RemoteStorage {
+	case "":
+		return nil
+	case RemoteStorageSFTP:
+		return validateSFTPStorage(cfg)
+	case RemoteStorageS3, RemoteStorageWebDAV:
+	default:
+		return fmt.Errorf("%w '%s': must be '%s', '%s', or '%s'",
+			ErrInvalidRemoteStorage, cfg.RemoteStorage, RemoteStorageS3, RemoteStorageWebDAV, RemoteStorageSFTP)
+	}
+
+	cfg.RemoteURL = strings.TrimSpace(cfg.RemoteURL)
+
+	remoteURL, err := url.Parse(cfg.RemoteURL)
+	if err != nil || (remoteURL.Scheme != "http" && remoteURL.Scheme != "https") || remoteURL.Host == "" {
+		return fmt.Errorf("%w '%s': must be an http or https URL", ErrInvalidRemoteURL, cfg.RemoteURL)
+	}
+
+	if cfg.RemoteStorage != RemoteStorageS3 {
+		return nil
+	}
+
+	if strings.Trim(remoteURL.Path, "/") == "" {
+		return fmt.Errorf("%w '%s': must include the bucket, e.g. https://s3.amazonaws.com/bucket",
+			ErrInvalidRemoteURL, cfg.RemoteURL)
+	}
+
+	if cfg.RemoteUsername == "" || cfg.RemotePassword == "" {
+		return ErrMissingRemoteCredentials
+	}
+
+	if strings.TrimSpace(cfg.RemoteRegion) == "" {
+		cfg.RemoteRegion = DefaultRemoteRegion
+	}
+
+	return nil
+}
+
+// validateSFTPStorage checks the SFTP settings: an sftp://[user@]host[:port]/path URL
+// and no password, as the OpenSSH client authenticates with keys or an agent.
+func validateSFTPStorage(cfg *Config) error {
+	cfg.RemoteURL = strings.TrimSpace(cfg.RemoteURL)
+
+	remoteURL, err := url.Parse(cfg.RemoteURL)
+	if err != nil || remoteURL.Scheme != RemoteStorageSFTP || remoteURL.Hostname() == "" {
+		return fmt.Errorf("%w '%s': must be an sftp://[user@]host[:port]/path URL", ErrInvalidRemoteURL, cfg.RemoteURL)
+	}
+
+	if cfg.RemotePassword != "" {
+		return ErrUnsupportedRemotePassword
+	}
+
+	return nil
+}
+
+// validatePortableOutput checks the portable copy settings and fills in their defaults.
+func validatePortableOutput(cfg *Config) error {
+	cfg.PortableOutputPath = strings.TrimSpace(cfg.PortableOutputPath)
+	if cfg.PortableOutputPath == "" {
+		return nil
+	}
+
+	if err := validateOutputPathFormat("portable_output_path", cfg.PortableOutputPath, isWindows); err != nil {
+		return err
+	}
+
+	portableOutputPath := filepath.Clean(cfg.PortableOutputPath)
+	if portableOutputPath == filepath.Clean(cfg.OutputPath) ||
+		(cfg.NormalizeLoudness && portableOutputPath == filepath.Clean(cfg.NormalizedOutputPath)) {
+		return ErrInvalidPortableOutputPath
 	}
 
-	// Parse YAML while preserving order using yaml.Node.
-	var node yaml.Node
-	if err = yaml.Unmarshal(originalContent, &node); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
+	cfg.PortableFormat = strings.ToLower(strings.TrimSpace(cfg.PortableFormat))
+	switch cfg.PortableFormat {
+	case "":
+		cfg.PortableFormat = PortableFormatMP3
+	case PortableFormatMP3, PortableFormatOpus:
+	default:
+		return fmt.Errorf("%w '%s': must be '%s' or '%s'",
+			ErrInvalidPortableFormat, cfg.PortableFormat, PortableFormatMP3, PortableFormatOpus)
 	}
 
-	// Update the auth_token value in the node tree.
-	updateAuthTokenInNode(&node, cfg.AuthToken)
+	if strings.TrimSpace(cfg.PortableBitrate) == "" {
+		cfg.PortableBitrate = DefaultPortableBitrate
+	}
+
+	return nil
+}
+
+// validateFLACEncoding trims and parses the FLAC compression level and padding.
+func validateFLACEncoding(cfg *Config) error {
+	cfg.FLACCompressionLevel = strings.TrimSpace(cfg.FLACCompressionLevel)
+	cfg.FLACPadding = strings.TrimSpace(cfg.FLACPadding)
+
+	if cfg.FLACCompressionLevel != "" {
+		level, err := strconv.ParseInt(cfg.FLACCompressionLevel, 10, 64)
+		if err != nil || level < minFLACCompressionLevel || level > maxFLACCompressionLevel {
+			return fmt.Errorf("%w '%s': must be a number from %d to %d", ErrInvalidFLACCompressionLevel,
+				cfg.FLACCompressionLevel, minFLACCompressionLevel, maxFLACCompressionLevel)
+		}
+
+		cfg.ParsedFLACCompressionLevel = level
+	}
+
+	if cfg.FLACPadding != "" {
+		size, err := humanize.ParseBytes(cfg.FLACPadding)
+		if err != nil || size > maxFLACPadding {
+			return fmt.Errorf("%w '%s': must be a size up to %s, e.g., '8KB'",
+				ErrInvalidFLACPadding, cfg.FLACPadding, humanize.IBytes(maxFLACPadding))
+		}
+
+		cfg.ParsedFLACPadding = utils.SafeUint64ToInt64(size)
+	}
+
+	return nil
+}
+
+// validateAuthTokenStorage checks where the auth token is stored, defaulting to the configuration file.
+func validateAuthTokenStorage(cfg *Config) error {
+	cfg.AuthTokenStorage = strings.ToLower(strings.TrimSpace(cfg.AuthTokenStorage))
+	switch cfg.AuthTokenStorage {
+	case "":
+		cfg.AuthTokenStorage = AuthTokenStorageConfig
+	case AuthTokenStorageConfig, AuthTokenStorageKeyring:
+	default:
+		return fmt.Errorf("%w '%s': must be %s or %s", ErrInvalidAuthTokenStorage, cfg.AuthTokenStorage,
+			AuthTokenStorageConfig, AuthTokenStorageKeyring)
+	}
+
+	return nil
+}
+
+// validateEmbeddedCover checks the embedded cover mode and fills in the thumbnail size.
+func validateEmbeddedCover(cfg *Config) error {
+	cfg.EmbeddedCover = strings.ToLower(strings.TrimSpace(cfg.EmbeddedCover))
+	switch cfg.EmbeddedCover {
+	case "":
+		cfg.EmbeddedCover = EmbeddedCoverFull
+	case EmbeddedCoverFull, EmbeddedCoverThumbnail, EmbeddedCoverNone:
+	default:
+		return fmt.Errorf("%w '%s': must be one of %s, %s, %s", ErrInvalidEmbeddedCover, cfg.EmbeddedCover,
+			EmbeddedCoverFull, EmbeddedCoverThumbnail, EmbeddedCoverNone)
+	}
+
+	switch {
+	case cfg.EmbeddedCoverSize < 0:
+		return fmt.Errorf("%w %d: must be a positive number of pixels, or 0 for the default of %d",
+			ErrInvalidEmbeddedCoverSize, cfg.EmbeddedCoverSize, DefaultEmbeddedCoverSize)
+	case cfg.EmbeddedCoverSize == 0:
+		cfg.EmbeddedCoverSize = DefaultEmbeddedCoverSize
+	}
 
-	// Marshal back to YAML (preserves order).
-	newContent, err := yaml.Marshal(&node)
+	return nil
+}
+
+// validateWaveTrackCount checks the number of tracks downloaded from a wave, filling in the default.
+func validateWaveTrackCount(cfg *Config) error {
+	switch {
+	case cfg.WaveTrackCount < 0 || cfg.WaveTrackCount > MaxWaveTrackCount:
+		return fmt.Errorf("%w %d: must be between 1 and %d, or 0 for the default of %d",
+			ErrInvalidWaveTrackCount, cfg.WaveTrackCount, MaxWaveTrackCount, DefaultWaveTrackCount)
+	case cfg.WaveTrackCount == 0:
+		cfg.WaveTrackCount = DefaultWaveTrackCount
+	}
+
+	return nil
+}
+
+// normalizeSidecarExtension trims a sidecar file extension, adds the leading dot,
+// and falls back to the default when it is empty.
+func normalizeSidecarExtension(key, extension, defaultExtension string) (string, error) {
+	extension = strings.TrimSpace(extension)
+	if extension == "" {
+		return defaultExtension, nil
+	}
+
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+
+	if extension == "." || strings.ContainsAny(extension, `/\<>:"|?*`) {
+		return "", fmt.Errorf("%w %s '%s': must be a file extension like '%s'",
+			ErrInvalidSidecarExtension, key, extension, defaultExtension)
+	}
+
+	return extension, nil
+}
+
+// validateOutputPathOutsideTempDir checks that the output path is not inside the system temporary directory,
+// where the operating system may delete downloaded files without notice.
+func validateOutputPathOutsideTempDir(outputPath string) error {
+	absOutputPath, err := filepath.Abs(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+		return nil //nolint:nilerr // A path that cannot be resolved is reported when the folder is created.
 	}
 
-	// Write the file back with preserved order.
-	if err = os.WriteFile(configFile, newContent, constants.DefaultFilePermissions); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	absTempDir, err := filepath.Abs(os.TempDir())
+	if err != nil {
+		return nil //nolint:nilerr // Without a temporary directory there is nothing to compare with.
+	}
+
+	relativePath, err := filepath.Rel(absTempDir, absOutputPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return nil //nolint:nilerr // Paths on different volumes are not nested.
+	}
+
+	return fmt.Errorf("%w: '%s' is inside '%s', files may be removed by the system",
+		ErrOutputPathInTempDir, absOutputPath, absTempDir)
+}
+
+// validateOutputPathFormat checks drive letters and UNC shares in an output directory path.
+// Drive letters and UNC shares are accepted only on Windows: elsewhere they would silently
+// become a relative folder named "D:" or "\\nas\music" in the working directory.
+func validateOutputPathFormat(key, path string, isWindowsOS bool) error {
+	path = strings.TrimSpace(path)
+	volume := utils.WindowsVolumeName(path)
+
+	if !isWindowsOS {
+		if volume != "" || strings.HasPrefix(path, `\\`) {
+			return fmt.Errorf("%w %s '%s': drive letters and UNC paths are supported only on Windows",
+				ErrInvalidOutputPathFormat, key, path)
+		}
+
+		return nil
+	}
+
+	// Device paths (\\?\ and \\.\) are passed to the OS as is.
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return nil
+	}
+
+	switch {
+	case volume == "" && (strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, "//")):
+		return fmt.Errorf("%w %s '%s': UNC paths must include a server and a share, e.g. \\\\nas\\music",
+			ErrInvalidOutputPathFormat, key, path)
+	case volume == "" && len(path) >= 2 && path[1] == ':':
+		return fmt.Errorf("%w %s '%s': drive must be a letter from A to Z", ErrInvalidOutputPathFormat, key, path)
+	case len(volume) == 2 && (len(path) == 2 || !strings.ContainsAny(path[2:3], `\/`)):
+		// "D:Music" is relative to the current folder of drive D, which is almost never intended.
+		return fmt.Errorf("%w %s '%s': use an absolute path like '%s\\%s'",
+			ErrInvalidOutputPathFormat, key, path, volume, path[2:])
+	}
+
+	if strings.ContainsAny(path[len(volume):], `<>:"|?*`) {
+		return fmt.Errorf("%w %s '%s': characters <>:\"|?* are not allowed outside the drive letter",
+			ErrInvalidOutputPathFormat, key, path)
 	}
 
 	return nil
 }
 
+// SaveConfig saves the auth token to the configuration file while preserving its format (YAML, TOML, or JSON)
+// and the order of its settings, or to the keyring when auth_token_storage is "keyring".
+func SaveConfig(cfg *Config) error {
+	if IsKeyringStorage(cfg) {
+		return saveKeyringAuthToken(cfg)
+	}
+
+	configFile := getConfigFilePath()
+
+	// Read the original file content.
+	originalContent, isEncrypted, err := readConfigContent(configFile)
+	if err != nil {
+		return handleMissingConfigFile(configFile, cfg.AuthToken, err)
+	}
+
+	return writeAuthToken(configFile, originalContent, cfg.AuthToken, isEncrypted)
+}
+
+// writeAuthToken writes the configuration file back with auth_token set in its content,
+// encrypting it again when it was encrypted.
+func writeAuthToken(configFile string, content []byte, authToken string, isEncrypted bool) error {
+	newContent, err := setAuthToken(configFormat(configFile), content, authToken)
+	if err != nil {
+		return err
+	}
+
+	// Write the file back with preserved order.
+	return writeConfigContent(configFile, newContent, isEncrypted)
+}
+
 // getConfigFilePath returns the config file path from viper or the default.
 func getConfigFilePath() string {
 	configFile := viper.ConfigFileUsed()
@@ -349,7 +1527,8 @@ func handleMissingConfigFile(configFile, authToken string, err error) error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// File doesn't exist, create it with viper.
+	// File doesn't exist, create it with viper in the format of its extension.
+	viper.SetConfigType(configFormat(configFile))
 	viper.Set("auth_token", authToken)
 
 	if err = viper.SafeWriteConfigAs(configFile); err != nil {
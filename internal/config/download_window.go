@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDownloadWindow indicates that the download hours in download_window cannot be parsed.
+var ErrInvalidDownloadWindow = errors.New("invalid download window")
+
+// downloadWindowTimeLayout is the layout of the start and the end of a download window.
+const downloadWindowTimeLayout = "15:04"
+
+// DownloadWindow is a range of the local time of day when audio files may be downloaded.
+// A window ending before its start spans midnight, e.g. 23:00-06:00.
+type DownloadWindow struct {
+	// Start is the time since midnight the window opens at.
+	Start time.Duration
+	// End is the time since midnight the window closes at.
+	End time.Duration
+}
+
+// Contains reports whether the moment is within the window.
+// The hours are compared on the wall clock of the day of the moment, so days with a DST shift are handled.
+func (w DownloadWindow) Contains(moment time.Time) bool {
+	var (
+		start = timeOfDay(moment, 0, w.Start)
+		end   = timeOfDay(moment, 0, w.End)
+	)
+
+	if w.Start < w.End {
+		return !moment.Before(start) && moment.Before(end)
+	}
+
+	return !moment.Before(start) || moment.Before(end)
+}
+
+// NextStart returns the first time after the moment the window opens.
+func (w DownloadWindow) NextStart(moment time.Time) time.Time {
+	start := timeOfDay(moment, 0, w.Start)
+	if !start.After(moment) {
+		start = timeOfDay(moment, 1, w.Start)
+	}
+
+	return start
+}
+
+// IsInDownloadWindows reports whether the moment is within any of the windows.
+// Without windows, files may be downloaded at any time.
+func IsInDownloadWindows(windows []DownloadWindow, moment time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	for _, window := range windows {
+		if window.Contains(moment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextDownloadWindowStart returns the first time after the moment any of the windows opens.
+func NextDownloadWindowStart(windows []DownloadWindow, moment time.Time) time.Time {
+	var result time.Time
+
+	for _, window := range windows {
+		if start := window.NextStart(moment); result.IsZero() || start.Before(result) {
+			result = start
+		}
+	}
+
+	return result
+}
+
+// ParseDownloadWindows parses comma-separated download windows, e.g. "02:00-07:00,13:00-14:30".
+func ParseDownloadWindows(value string) ([]DownloadWindow, error) {
+	var result []DownloadWindow
+
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			return nil, fmt.Errorf("%w '%s': must be a range of hours, e.g. 02:00-07:00", ErrInvalidDownloadWindow, part)
+		}
+
+		start, isStartValid := parseTimeOfDay(first)
+		end, isEndValid := parseTimeOfDay(last)
+
+		if !isStartValid || !isEndValid {
+			return nil, fmt.Errorf("%w '%s': the hours must be in the HH:MM format", ErrInvalidDownloadWindow, part)
+		}
+
+		if start == end {
+			return nil, fmt.Errorf("%w '%s': the window must not end when it starts", ErrInvalidDownloadWindow, part)
+		}
+
+		result = append(result, DownloadWindow{Start: start, End: end})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w '%s': no windows given", ErrInvalidDownloadWindow, value)
+	}
+
+	return result, nil
+}
+
+// parseTimeOfDay parses a time of day in the HH:MM format into the time since midnight.
+// 24:00 is the end of the day.
+func parseTimeOfDay(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * time.Hour, true
+	}
+
+	moment, err := time.Parse(downloadWindowTimeLayout, value)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(moment.Hour())*time.Hour + time.Duration(moment.Minute())*time.Minute, true
+}
+
+// timeOfDay returns the wall-clock time sinceMidnight of the day dayOffset days after the day of the moment,
+// in the location of the moment.
+func timeOfDay(moment time.Time, dayOffset int, sinceMidnight time.Duration) time.Time {
+	var (
+		year, month, day = moment.Date()
+		hours            = int(sinceMidnight / time.Hour)
+		minutes          = int(sinceMidnight % time.Hour / time.Minute)
+	)
+
+	return time.Date(year, month, day+dayOffset, hours, minutes, 0, 0, moment.Location())
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDownloadWindows tests the parsing of the download_window hours.
+func TestParseDownloadWindows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      []DownloadWindow
+		expectedError bool
+	}{
+		{
+			name:  "several windows",
+			value: "02:00-07:00, 13:30-24:00",
+			expected: []DownloadWindow{
+				{Start: 2 * time.Hour, End: 7 * time.Hour},
+				{Start: 13*time.Hour + 30*time.Minute, End: 24 * time.Hour},
+			},
+		},
+		{
+			name:     "over midnight",
+			value:    "23:00-06:00",
+			expected: []DownloadWindow{{Start: 23 * time.Hour, End: 6 * time.Hour}},
+		},
+		{name: "empty", value: " , ", expectedError: true},
+		{name: "not a range", value: "02:00", expectedError: true},
+		{name: "invalid hour", value: "25:00-07:00", expectedError: true},
+		{name: "empty window", value: "07:00-07:00", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			windows, err := ParseDownloadWindows(tt.value)
+			if tt.expectedError {
+				require.ErrorIs(t, err, ErrInvalidDownloadWindow)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, windows)
+		})
+	}
+}
+
+// TestDownloadWindows tests the moments within the download windows and when the next one opens.
+func TestDownloadWindows(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.March, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	night := DownloadWindow{Start: 23 * time.Hour, End: 6 * time.Hour}
+	assert.True(t, night.Contains(at(23, 30)))
+	assert.True(t, night.Contains(at(5, 59)))
+	assert.False(t, night.Contains(at(6, 0)))
+	assert.False(t, night.Contains(at(12, 0)))
+
+	windows := []DownloadWindow{{Start: 2 * time.Hour, End: 7 * time.Hour}, {Start: 13 * time.Hour, End: 14 * time.Hour}}
+	assert.True(t, IsInDownloadWindows(windows, at(2, 0)))
+	assert.False(t, IsInDownloadWindows(windows, at(7, 0)))
+	assert.True(t, IsInDownloadWindows(nil, at(7, 0)))
+
+	assert.Equal(t, at(13, 0), NextDownloadWindowStart(windows, at(8, 0)))
+	assert.Equal(t, at(2, 0).AddDate(0, 0, 1), NextDownloadWindowStart(windows, at(15, 0)))
+}
+
+// TestDownloadWindows_DSTShift tests that the hours of the windows are wall-clock hours on days with a DST shift.
+func TestDownloadWindows_DSTShift(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Clocks go from 02:00 to 03:00 on this day.
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.March, 29, hour, minute, 0, 0, berlin)
+	}
+
+	morning := DownloadWindow{Start: 6 * time.Hour, End: 7 * time.Hour}
+	assert.False(t, morning.Contains(at(5, 30)))
+	assert.True(t, morning.Contains(at(6, 30)))
+	assert.False(t, morning.Contains(at(7, 0)))
+
+	assert.Equal(t, at(6, 0), morning.NextStart(at(1, 0)))
+}
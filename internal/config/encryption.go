@@ -0,0 +1,349 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+const (
+	// encryptedConfigBlockType is the type of the PEM block an encrypted configuration file consists of.
+	encryptedConfigBlockType = "ZVUK-GRABBER ENCRYPTED CONFIG"
+	// encryptedConfigKDF names the key derivation of the encrypted configuration files.
+	encryptedConfigKDF = "pbkdf2-sha256"
+	// encryptedConfigIterations is the number of PBKDF2 iterations of newly encrypted files.
+	encryptedConfigIterations = 600000
+	// encryptedConfigMaxIterations bounds the number of iterations read from a file,
+	// so a damaged or crafted header cannot hang the startup in the key derivation.
+	encryptedConfigMaxIterations = 10 * encryptedConfigIterations
+	// encryptedConfigSaltSize is the size of the random salt of the key derivation in bytes.
+	encryptedConfigSaltSize = 16
+	// encryptedConfigKeySize is the size of the AES-256 key in bytes.
+	encryptedConfigKeySize = 32
+	// encryptedConfigKDFHeader is the PEM header naming the key derivation.
+	encryptedConfigKDFHeader = "KDF"
+	// encryptedConfigIterationsHeader is the PEM header holding the number of iterations.
+	encryptedConfigIterationsHeader = "Iterations"
+	// encryptedConfigSaltHeader is the PEM header holding the base64-encoded salt.
+	encryptedConfigSaltHeader = "Salt"
+
+	// ConfigKeyFileEnvVar is the environment variable naming the file the passphrase is read from.
+	ConfigKeyFileEnvVar = "ZVUK_CONFIG_KEY_FILE"
+	// ConfigPassphraseEnvVar is the environment variable holding the passphrase itself.
+	ConfigPassphraseEnvVar = "ZVUK_CONFIG_PASSPHRASE"
+)
+
+var (
+	// ErrInvalidEncryptedConfig indicates that an encrypted configuration file is damaged or of an unknown version.
+	ErrInvalidEncryptedConfig = errors.New("invalid encrypted configuration file")
+	// ErrWrongConfigPassphrase indicates that an encrypted configuration file cannot be decrypted with the passphrase.
+	ErrWrongConfigPassphrase = errors.New("wrong passphrase or damaged configuration file")
+	// ErrConfigPassphraseRequired indicates that the configuration file is encrypted and no passphrase is given.
+	ErrConfigPassphraseRequired = errors.New("configuration file is encrypted, but no passphrase is given")
+	// ErrEmptyConfigPassphrase indicates that the passphrase is empty.
+	ErrEmptyConfigPassphrase = errors.New("passphrase is empty")
+	// ErrConfigAlreadyEncrypted indicates that the file to encrypt is encrypted already.
+	ErrConfigAlreadyEncrypted = errors.New("configuration file is already encrypted")
+	// ErrConfigNotEncrypted indicates that the file to decrypt is not encrypted.
+	ErrConfigNotEncrypted = errors.New("configuration file is not encrypted")
+)
+
+// PassphrasePrompt asks the user for the passphrase of the configuration file,
+// twice when confirm is set, as when a file is encrypted.
+type PassphrasePrompt func(confirm bool) ([]byte, error)
+
+// passphraseSource keeps where the passphrase of an encrypted configuration file is taken from,
+// and the passphrase once it is known, so reloading and saving the file do not ask for it again.
+//
+//nolint:gochecknoglobals // The configuration is loaded through the global viper instance as well.
+var passphraseSource struct {
+	// mutex protects the fields below.
+	mutex sync.Mutex
+	// keyFile is the file given with --config-key-file.
+	keyFile string
+	// prompt asks for the passphrase in the terminal, nil when it cannot be asked for.
+	prompt PassphrasePrompt
+	// passphrase is the passphrase the file was decrypted with.
+	passphrase []byte
+}
+
+// SetPassphraseSource sets where the passphrase of an encrypted configuration file is taken from:
+// the key file (--config-key-file), else ZVUK_CONFIG_KEY_FILE, else ZVUK_CONFIG_PASSPHRASE,
+// else the prompt, which may be nil when there is no terminal to ask in.
+func SetPassphraseSource(keyFile string, prompt PassphrasePrompt) {
+	passphraseSource.mutex.Lock()
+	defer passphraseSource.mutex.Unlock()
+
+	passphraseSource.keyFile = keyFile
+	passphraseSource.prompt = prompt
+	passphraseSource.passphrase = nil
+}
+
+// ConfigPassphrase returns the passphrase of the configuration file from the source set by SetPassphraseSource.
+// A known passphrase is returned again, unless confirm is set to ask for a new one, as when a file is encrypted.
+func ConfigPassphrase(confirm bool) ([]byte, error) {
+	passphraseSource.mutex.Lock()
+	defer passphraseSource.mutex.Unlock()
+
+	if passphraseSource.passphrase != nil && !confirm {
+		return passphraseSource.passphrase, nil
+	}
+
+	passphrase, err := readConfigPassphrase(confirm)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyConfigPassphrase
+	}
+
+	passphraseSource.passphrase = passphrase
+
+	return passphrase, nil
+}
+
+// readConfigPassphrase reads the passphrase from the first source given.
+func readConfigPassphrase(confirm bool) ([]byte, error) {
+	keyFile := passphraseSource.keyFile
+	if keyFile == "" {
+		keyFile = os.Getenv(ConfigKeyFileEnvVar)
+	}
+
+	if keyFile != "" {
+		content, err := os.ReadFile(filepath.Clean(keyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config key file: %w", err)
+		}
+
+		// The key file may end with a line break, as most editors save it.
+		return bytes.TrimRight(content, "\r\n"), nil
+	}
+
+	if passphrase := os.Getenv(ConfigPassphraseEnvVar); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	if passphraseSource.prompt == nil {
+		return nil, fmt.Errorf("%w: use --config-key-file, %s, or %s",
+			ErrConfigPassphraseRequired, ConfigKeyFileEnvVar, ConfigPassphraseEnvVar)
+	}
+
+	return passphraseSource.prompt(confirm)
+}
+
+// IsEncryptedConfig reports whether the content of a configuration file is encrypted.
+func IsEncryptedConfig(content []byte) bool {
+	block, _ := pem.Decode(bytes.TrimSpace(content))
+
+	return block != nil && block.Type == encryptedConfigBlockType
+}
+
+// EncryptConfig encrypts the content of a configuration file with AES-256-GCM,
+// using a key derived from the passphrase with PBKDF2-SHA256 and a random salt.
+// The result is a PEM block, so the file stays readable text.
+func EncryptConfig(content, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyConfigPassphrase
+	}
+
+	salt := make([]byte, encryptedConfigSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newConfigCipher(passphrase, salt, encryptedConfigIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	block := &pem.Block{
+		Type: encryptedConfigBlockType,
+		Headers: map[string]string{
+			encryptedConfigKDFHeader:        encryptedConfigKDF,
+			encryptedConfigIterationsHeader: strconv.Itoa(encryptedConfigIterations),
+			encryptedConfigSaltHeader:       base64.StdEncoding.EncodeToString(salt),
+		},
+		Bytes: aead.Seal(nonce, nonce, content, nil),
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// DecryptConfig decrypts the content of a configuration file encrypted by EncryptConfig.
+func DecryptConfig(content, passphrase []byte) ([]byte, error) {
+	block, _ := pem.Decode(bytes.TrimSpace(content))
+	if block == nil || block.Type != encryptedConfigBlockType {
+		return nil, ErrConfigNotEncrypted
+	}
+
+	if block.Headers[encryptedConfigKDFHeader] != encryptedConfigKDF {
+		return nil, fmt.Errorf("%w: unknown key derivation '%s'",
+			ErrInvalidEncryptedConfig, block.Headers[encryptedConfigKDFHeader])
+	}
+
+	iterations, err := strconv.Atoi(block.Headers[encryptedConfigIterationsHeader])
+	if err != nil || iterations <= 0 || iterations > encryptedConfigMaxIterations {
+		return nil, fmt.Errorf("%w: invalid number of iterations", ErrInvalidEncryptedConfig)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(block.Headers[encryptedConfigSaltHeader])
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("%w: invalid salt", ErrInvalidEncryptedConfig)
+	}
+
+	aead, err := newConfigCipher(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(block.Bytes) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: the content is truncated", ErrInvalidEncryptedConfig)
+	}
+
+	nonce, ciphertext := block.Bytes[:aead.NonceSize()], block.Bytes[aead.NonceSize():]
+
+	result, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongConfigPassphrase
+	}
+
+	return result, nil
+}
+
+// newConfigCipher returns the AES-256-GCM cipher keyed by the passphrase.
+func newConfigCipher(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, encryptedConfigKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// readConfigContent reads the configuration file, decrypting it when it is encrypted.
+func readConfigContent(configFilename string) ([]byte, bool, error) {
+	content, err := os.ReadFile(filepath.Clean(configFilename))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !IsEncryptedConfig(content) {
+		return content, false, nil
+	}
+
+	passphrase, err := ConfigPassphrase(false)
+	if err != nil {
+		return nil, true, err
+	}
+
+	content, err = DecryptConfig(content, passphrase)
+	if err != nil {
+		forgetConfigPassphrase()
+
+		return nil, true, err
+	}
+
+	return content, true, nil
+}
+
+// forgetConfigPassphrase drops the known passphrase, so a wrong one is asked for again.
+func forgetConfigPassphrase() {
+	passphraseSource.mutex.Lock()
+	defer passphraseSource.mutex.Unlock()
+
+	passphraseSource.passphrase = nil
+}
+
+// writeConfigContent writes the configuration file, encrypting it again when it was encrypted.
+func writeConfigContent(configFilename string, content []byte, isEncrypted bool) error {
+	if isEncrypted {
+		passphrase, err := ConfigPassphrase(false)
+		if err != nil {
+			return err
+		}
+
+		if content, err = EncryptConfig(content, passphrase); err != nil {
+			return err
+		}
+	}
+
+	// The file is replaced atomically, so an interrupted encryption or decryption does not destroy it.
+	if err := utils.WriteFileAtomically(configFilename, content); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// EncryptConfigFile encrypts the configuration file in place with a new passphrase.
+func EncryptConfigFile(configFilename string) error {
+	content, err := os.ReadFile(filepath.Clean(configFilename))
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if IsEncryptedConfig(content) {
+		return ErrConfigAlreadyEncrypted
+	}
+
+	if strings.TrimSpace(string(content)) == "" {
+		return fmt.Errorf("failed to encrypt config file: %s is empty", configFilename)
+	}
+
+	// An encrypted file is not migrated when it is loaded, so the deprecated keys are migrated now.
+	if configFormat(configFilename) == configFormatYAML {
+		if content, _, err = migrateConfigContent(content); err != nil {
+			return err
+		}
+	}
+
+	if _, err = ConfigPassphrase(true); err != nil {
+		return err
+	}
+
+	return writeConfigContent(configFilename, content, true)
+}
+
+// DecryptConfigFile decrypts the configuration file in place.
+func DecryptConfigFile(configFilename string) error {
+	content, err := os.ReadFile(filepath.Clean(configFilename))
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if !IsEncryptedConfig(content) {
+		return ErrConfigNotEncrypted
+	}
+
+	content, _, err = readConfigContent(configFilename)
+	if err != nil {
+		return err
+	}
+
+	return writeConfigContent(configFilename, content, false)
+}
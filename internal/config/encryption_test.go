@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestEncryptConfig tests that the content is only decrypted with the passphrase it was encrypted with.
+func TestEncryptConfig(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("auth_token: \"a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2\"\n")
+
+	encrypted, err := EncryptConfig(content, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedConfig(encrypted))
+	assert.NotContains(t, string(encrypted), "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2")
+
+	decrypted, err := DecryptConfig(encrypted, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.Equal(t, content, decrypted)
+
+	_, err = DecryptConfig(encrypted, []byte("battery staple"))
+	require.ErrorIs(t, err, ErrWrongConfigPassphrase)
+
+	_, err = DecryptConfig(content, []byte("correct horse"))
+	require.ErrorIs(t, err, ErrConfigNotEncrypted)
+
+	_, err = EncryptConfig(content, nil)
+	require.ErrorIs(t, err, ErrEmptyConfigPassphrase)
+
+	assert.False(t, IsEncryptedConfig(content))
+}
+
+// TestDecryptConfig_Iterations tests that a file whose number of iterations is out of range is rejected
+// before the key is derived.
+func TestDecryptConfig_Iterations(t *testing.T) {
+	t.Parallel()
+
+	encrypted, err := EncryptConfig([]byte("max_concurrent_downloads: 2\n"), []byte("correct horse"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		iterations string
+	}{
+		{name: "zero", iterations: "0"},
+		{name: "negative", iterations: "-1"},
+		{name: "not a number", iterations: "many"},
+		{name: "too many", iterations: "2000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			block, _ := pem.Decode(encrypted)
+			require.NotNil(t, block)
+
+			block.Headers[encryptedConfigIterationsHeader] = tt.iterations
+
+			_, err := DecryptConfig(pem.EncodeToMemory(block), []byte("correct horse"))
+			require.ErrorIs(t, err, ErrInvalidEncryptedConfig)
+		})
+	}
+}
+
+// TestLoadConfigEncrypted tests that an encrypted file is read with the passphrase of the key file
+// and stays encrypted when the auth token is saved.
+//
+//nolint:paralleltest // Cannot run in parallel due to Viper global state and the passphrase source.
+func TestLoadConfigEncrypted(t *testing.T) {
+	var (
+		configPath = filepath.Join(t.TempDir(), "config.yaml")
+		keyPath    = filepath.Join(t.TempDir(), "config.key")
+	)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("auth_token: \"\"\nquality: 3\noutput_path: \"/music\"\n"),
+		constants.DefaultFilePermissions))
+	require.NoError(t, os.WriteFile(keyPath, []byte("correct horse\n"), constants.DefaultFilePermissions))
+
+	SetPassphraseSource(keyPath, nil)
+	t.Cleanup(func() { SetPassphraseSource("", nil) })
+
+	require.NoError(t, EncryptConfigFile(configPath))
+	require.ErrorIs(t, EncryptConfigFile(configPath), ErrConfigAlreadyEncrypted)
+
+	result, err := MigrateConfig(configPath)
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+
+	// A new run knows nothing but the key file.
+	SetPassphraseSource(keyPath, nil)
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), cfg.Quality)
+
+	cfg.AuthToken = "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2"
+	require.NoError(t, SaveConfig(cfg))
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedConfig(content))
+
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2", cfg.AuthToken)
+
+	// Without a passphrase, the file cannot be read.
+	SetPassphraseSource("", nil)
+
+	_, err = LoadConfig(configPath)
+	require.ErrorIs(t, err, ErrConfigPassphraseRequired)
+
+	SetPassphraseSource(keyPath, nil)
+	require.NoError(t, DecryptConfigFile(configPath))
+
+	content, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `auth_token: "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2"`)
+	require.ErrorIs(t, DecryptConfigFile(configPath), ErrConfigNotEncrypted)
+}
+
+// TestEncryptConfigFile_MigratesDeprecatedKeys tests that deprecated keys are migrated before the file is encrypted.
+//
+//nolint:paralleltest // Cannot run in parallel due to Viper global state and the passphrase source.
+func TestEncryptConfigFile_MigratesDeprecatedKeys(t *testing.T) {
+	var (
+		configPath = filepath.Join(t.TempDir(), "config.yaml")
+		keyPath    = filepath.Join(t.TempDir(), "config.key")
+	)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("format: 2\noutput_path: \"/music\"\n"),
+		constants.DefaultFilePermissions))
+	require.NoError(t, os.WriteFile(keyPath, []byte("correct horse\n"), constants.DefaultFilePermissions))
+
+	SetPassphraseSource(keyPath, nil)
+	t.Cleanup(func() { SetPassphraseSource("", nil) })
+
+	require.NoError(t, EncryptConfigFile(configPath))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(2), cfg.Quality)
+
+	require.NoError(t, DecryptConfigFile(configPath))
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "format:")
+}
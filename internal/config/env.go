@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is the prefix of the environment variables that override the settings of the configuration file.
+const EnvPrefix = "ZVUK"
+
+// EnvVarName returns the environment variable that overrides the key, e.g., ZVUK_AUTH_TOKEN for auth_token.
+func EnvVarName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(key)
+}
+
+// bindEnvironment makes every setting overridable by its environment variable (see EnvVarName),
+// over both the base settings and the profile.
+// Viper only reads the variables of the keys it knows when unmarshaling, so every key is bound,
+// including the ones missing from the configuration file.
+func bindEnvironment() error {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.AutomaticEnv()
+
+	for _, key := range Keys() {
+		if err := viper.BindEnv(key); err != nil {
+			return fmt.Errorf("failed to bind environment variable %s: %w", EnvVarName(key), err)
+		}
+	}
+
+	return nil
+}
+
+// Keys returns the keys of the configuration file in the order of the Config fields.
+func Keys() []string {
+	configType := reflect.TypeFor[Config]()
+	result := make([]string, 0, configType.NumField())
+
+	for field := range configType.Fields() {
+		key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		result = append(result, key)
+	}
+
+	return result
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFormatYAML is the format of configuration files with any extension but .toml and .json.
+	configFormatYAML = "yaml"
+	// configFormatTOML is the format of .toml configuration files.
+	configFormatTOML = "toml"
+	// configFormatJSON is the format of .json configuration files.
+	configFormatJSON = "json"
+	// jsonIndent is the indentation of the JSON configuration files written back.
+	jsonIndent = "  "
+)
+
+// tomlAuthTokenPattern matches the top-level auth_token key of a TOML file, quoted or not.
+var tomlAuthTokenPattern = regexp.MustCompile(`^\s*(auth_token|"auth_token"|'auth_token')\s*=`)
+
+// ErrInvalidJSONConfig indicates that a JSON configuration file is not an object.
+var ErrInvalidJSONConfig = errors.New("configuration must be a JSON object")
+
+// configFormat returns the format of the configuration file inferred from its extension.
+// Files with other extensions, or none, are read as YAML, as in earlier versions.
+func configFormat(configFilename string) string {
+	switch strings.ToLower(filepath.Ext(configFilename)) {
+	case ".toml":
+		return configFormatTOML
+	case ".json":
+		return configFormatJSON
+	default:
+		return configFormatYAML
+	}
+}
+
+// setAuthToken returns the content of the configuration file with auth_token set,
+// keeping the order of the other settings.
+func setAuthToken(format string, content []byte, authToken string) ([]byte, error) {
+	switch format {
+	case configFormatTOML:
+		return setTOMLAuthToken(content, authToken)
+	case configFormatJSON:
+		return setJSONAuthToken(content, authToken)
+	default:
+		return setYAMLAuthToken(content, authToken)
+	}
+}
+
+// setYAMLAuthToken sets auth_token in a YAML file, keeping the order of the keys and their comments.
+func setYAMLAuthToken(content []byte, authToken string) ([]byte, error) {
+	// Parse YAML while preserving order using yaml.Node.
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	// Update the auth_token value in the node tree.
+	updateAuthTokenInNode(&node, authToken)
+
+	// Marshal back to YAML (preserves order).
+	newContent, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return newContent, nil
+}
+
+// setTOMLAuthToken sets auth_token in a TOML file line by line, so comments and formatting are kept.
+// A file without the key gets it on the first line, where it cannot end up in a table.
+func setTOMLAuthToken(content []byte, authToken string) ([]byte, error) {
+	// A JSON string is a valid TOML basic string.
+	quotedToken, err := json.Marshal(authToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode auth_token: %w", err)
+	}
+
+	tokenLine := "auth_token = " + string(quotedToken)
+	lines := strings.Split(string(content), "\n")
+
+	for index, line := range lines {
+		// The top-level keys end where the first table starts.
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			break
+		}
+
+		if tomlAuthTokenPattern.MatchString(line) {
+			lines[index] = tokenLine
+
+			return []byte(strings.Join(lines, "\n")), nil
+		}
+	}
+
+	return []byte(tokenLine + "\n" + string(content)), nil
+}
+
+// jsonField is a key of a JSON object with its value as written in the file.
+type jsonField struct {
+	// key is the name of the field.
+	key string
+	// value is the raw JSON value of the field.
+	value json.RawMessage
+}
+
+// setJSONAuthToken sets auth_token in a JSON file, keeping the order of the keys.
+// The file is written back indented with two spaces.
+func setJSONAuthToken(content []byte, authToken string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if delimiter, ok := token.(json.Delim); !ok || delimiter != '{' {
+		return nil, ErrInvalidJSONConfig
+	}
+
+	var fields []*jsonField
+
+	for decoder.More() {
+		if token, err = decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		field := &jsonField{key: fmt.Sprint(token)}
+		if err = decoder.Decode(&field.value); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		fields = append(fields, field)
+	}
+
+	quotedToken, err := json.Marshal(authToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode auth_token: %w", err)
+	}
+
+	isSet := false
+
+	for _, field := range fields {
+		if field.key == "auth_token" {
+			field.value = quotedToken
+			isSet = true
+		}
+	}
+
+	if !isSet {
+		fields = append([]*jsonField{{key: "auth_token", value: quotedToken}}, fields...)
+	}
+
+	var compact bytes.Buffer
+
+	compact.WriteByte('{')
+
+	for index, field := range fields {
+		if index > 0 {
+			compact.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(field.key)
+		compact.Write(key)
+		compact.WriteByte(':')
+		compact.Write(field.value)
+	}
+
+	compact.WriteByte('}')
+
+	var result bytes.Buffer
+	if err = json.Indent(&result, compact.Bytes(), "", jsonIndent); err != nil {
+		return nil, fmt.Errorf("failed to format JSON: %w", err)
+	}
+
+	result.WriteByte('\n')
+
+	return result.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/oshokin/zvuk-grabber/internal/keyring"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// keyringAuthTokenAccount is the keyring account the auth token is stored under.
+const keyringAuthTokenAccount = "auth_token"
+
+// IsKeyringStorage reports whether the auth token is kept in the keyring (auth_token_storage: keyring).
+func IsKeyringStorage(cfg *Config) bool {
+	return strings.EqualFold(strings.TrimSpace(cfg.AuthTokenStorage), AuthTokenStorageKeyring)
+}
+
+// ResolveAuthToken reads the auth token from the keyring when it is kept there and auth_token is empty,
+// so a token set in the configuration file or in ZVUK_AUTH_TOKEN still wins.
+// A keyring without the token leaves auth_token empty. The token read is kept out of the logs.
+func ResolveAuthToken(cfg *Config) error {
+	if !IsKeyringStorage(cfg) || cfg.AuthToken != "" {
+		return nil
+	}
+
+	authToken, err := keyring.Get(keyringAuthTokenAccount)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	cfg.AuthToken = authToken
+	logger.AddSecret(authToken)
+
+	return nil
+}
+
+// saveKeyringAuthToken stores the auth token in the keyring and clears the plaintext one
+// left in the configuration file, which would otherwise win over it.
+func saveKeyringAuthToken(cfg *Config) error {
+	if err := keyring.Set(keyringAuthTokenAccount, cfg.AuthToken); err != nil {
+		return err
+	}
+
+	if !viper.InConfig("auth_token") {
+		return nil
+	}
+
+	configFile := getConfigFilePath()
+
+	originalContent, isEncrypted, err := readConfigContent(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return writeAuthToken(configFile, originalContent, "", isEncrypted)
+}
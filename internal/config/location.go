@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// appDirName is the name of the application folder in the user configuration and state directories.
+	appDirName = "zvuk-grabber"
+	// DirConfigFilename is the name of the configuration file in a configuration directory.
+	DirConfigFilename = "config.yaml"
+	// dirConfigTOMLFilename is the name of the TOML configuration file in a configuration directory.
+	dirConfigTOMLFilename = "config.toml"
+	// dirConfigJSONFilename is the name of the JSON configuration file in a configuration directory.
+	dirConfigJSONFilename = "config.json"
+)
+
+// ErrUnknownHomeDir is returned when the home directory of the user cannot be determined.
+var ErrUnknownHomeDir = errors.New("home directory is unknown")
+
+// Location is where the configuration file is read from and where the state files are kept.
+type Location struct {
+	// ConfigFilename is the configuration file.
+	ConfigFilename string
+	// StateDir is the absolute directory the relative state paths are resolved against,
+	// empty to keep them relative to the working directory.
+	StateDir string
+}
+
+// ResolveLocation finds the configuration file and the state directory.
+//   - A file given with --config is used as is, and the state paths stay relative to the working directory.
+//   - A directory given with --config-dir holds both config.yaml and the state files.
+//   - Otherwise, .zvuk-grabber.yaml in the working directory is used as before, if it exists.
+//   - Otherwise, $XDG_CONFIG_HOME/zvuk-grabber/config.yaml is used if it exists,
+//     and the state files are kept in $XDG_STATE_HOME/zvuk-grabber.
+//
+// In a configuration directory, config.toml and config.json are used as well when config.yaml is missing.
+//
+// Without any configuration file, .zvuk-grabber.yaml in the working directory is returned,
+// so the error names the file expected by earlier versions.
+func ResolveLocation(configFilename, configDir string) (*Location, error) {
+	if configFilename != "" {
+		return &Location{ConfigFilename: configFilename}, nil
+	}
+
+	if configDir != "" {
+		absoluteDir, err := filepath.Abs(configDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config directory: %w", err)
+		}
+
+		dirConfigFilename, _ := findDirConfigFile(absoluteDir)
+
+		return &Location{ConfigFilename: dirConfigFilename, StateDir: absoluteDir}, nil
+	}
+
+	legacyLocation := &Location{ConfigFilename: DefaultConfigFilename}
+	if _, err := os.Stat(DefaultConfigFilename); err == nil {
+		return legacyLocation, nil
+	}
+
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return legacyLocation, nil //nolint:nilerr // Without a user directory, only the working directory is searched.
+	}
+
+	xdgConfigFilename, ok := findDirConfigFile(filepath.Join(userConfigDir, appDirName))
+	if !ok {
+		return legacyLocation, nil
+	}
+
+	stateDir, err := userStateDir()
+	if err != nil {
+		// The state files are kept next to the configuration file then.
+		stateDir = filepath.Dir(xdgConfigFilename)
+	} else {
+		stateDir = filepath.Join(stateDir, appDirName)
+	}
+
+	return &Location{ConfigFilename: xdgConfigFilename, StateDir: stateDir}, nil
+}
+
+// findDirConfigFile returns the first configuration file found in the directory,
+// trying config.yaml, config.toml, and config.json in this order.
+// Without any of them, config.yaml is returned, so the error names the file expected by earlier versions.
+func findDirConfigFile(dir string) (string, bool) {
+	for _, filename := range []string{DirConfigFilename, dirConfigTOMLFilename, dirConfigJSONFilename} {
+		path := filepath.Join(dir, filename)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+
+	return filepath.Join(dir, DirConfigFilename), false
+}
+
+// userStateDir returns the base directory of the user state files: $XDG_STATE_HOME, or ~/.local/state,
+// or %LocalAppData% on Windows. Relative $XDG_STATE_HOME values are ignored, as the specification requires.
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return os.UserCacheDir()
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnknownHomeDir, err)
+	}
+
+	if home == "" {
+		return "", fmt.Errorf("%w: the home path is empty", ErrUnknownHomeDir)
+	}
+
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// ResolveStatePaths moves the relative state files and folders into StateDir.
+// Absolute paths are kept, so the configuration may still point them anywhere.
+func ResolveStatePaths(cfg *Config) {
+	if cfg.StateDir == "" {
+		return
+	}
+
+	for _, path := range []*string{
+		&cfg.AntiBotCookiesPath,
+		&cfg.BrowserDiagnosticsPath,
+		&cfg.UpgradeWatchPath,
+		&cfg.ResumeStatePath,
+		&cfg.SyncStatePath,
+		&cfg.HistoryPath,
+	} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(cfg.StateDir, *path)
+		}
+	}
+}
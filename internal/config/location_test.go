@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestResolveLocation tests where the configuration file and the state files are looked up.
+//
+//nolint:paralleltest // Cannot run in parallel due to the environment variables.
+func TestResolveLocation(t *testing.T) {
+	var (
+		configHome = t.TempDir()
+		stateHome  = t.TempDir()
+	)
+
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	location, err := ResolveLocation("custom.yaml", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Location{ConfigFilename: "custom.yaml"}, location)
+
+	configDir := t.TempDir()
+	location, err = ResolveLocation("", configDir)
+	require.NoError(t, err)
+	assert.Equal(t, &Location{ConfigFilename: filepath.Join(configDir, DirConfigFilename), StateDir: configDir}, location)
+
+	// Without a file in the XDG directory, the file of the working directory is expected.
+	location, err = ResolveLocation("", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Location{ConfigFilename: DefaultConfigFilename}, location)
+
+	xdgConfigFilename := filepath.Join(configHome, "zvuk-grabber", DirConfigFilename)
+	require.NoError(t, os.MkdirAll(filepath.Dir(xdgConfigFilename), constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(xdgConfigFilename, nil, constants.DefaultFilePermissions))
+
+	location, err = ResolveLocation("", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Location{
+		ConfigFilename: xdgConfigFilename,
+		StateDir:       filepath.Join(stateHome, "zvuk-grabber"),
+	}, location)
+}
+
+// TestResolveLocation_ConfigFormats tests that the TOML and JSON files of the configuration directories are found
+// when config.yaml is missing, in the order config.yaml, config.toml, config.json.
+//
+//nolint:paralleltest // Cannot run in parallel due to the environment variables.
+func TestResolveLocation_ConfigFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{
+			name:     "toml",
+			files:    []string{"config.toml"},
+			expected: "config.toml",
+		},
+		{
+			name:     "json",
+			files:    []string{"config.json"},
+			expected: "config.json",
+		},
+		{
+			name:     "yaml before toml and json",
+			files:    []string{"config.json", "config.toml", DirConfigFilename},
+			expected: DirConfigFilename,
+		},
+		{
+			name:     "toml before json",
+			files:    []string{"config.json", "config.toml"},
+			expected: "config.toml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configHome := t.TempDir()
+
+			t.Setenv("XDG_CONFIG_HOME", configHome)
+			t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+			configDir := t.TempDir()
+			xdgConfigDir := filepath.Join(configHome, "zvuk-grabber")
+			require.NoError(t, os.MkdirAll(xdgConfigDir, constants.DefaultFolderPermissions))
+
+			for _, filename := range tt.files {
+				for _, dir := range []string{configDir, xdgConfigDir} {
+					require.NoError(t, os.WriteFile(filepath.Join(dir, filename), nil, constants.DefaultFilePermissions))
+				}
+			}
+
+			location, err := ResolveLocation("", configDir)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(configDir, tt.expected), location.ConfigFilename)
+
+			location, err = ResolveLocation("", "")
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(xdgConfigDir, tt.expected), location.ConfigFilename)
+		})
+	}
+}
+
+// TestUserStateDir_UnknownHome tests that a missing home directory is reported with ErrUnknownHomeDir.
+//
+//nolint:paralleltest // Cannot run in parallel due to the environment variables.
+func TestUserStateDir_UnknownHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the state directory does not depend on the home directory on Windows")
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "")
+
+	_, err := userStateDir()
+	require.ErrorIs(t, err, ErrUnknownHomeDir)
+}
+
+// TestResolveStatePaths tests that only the relative state paths are moved into the state directory.
+func TestResolveStatePaths(t *testing.T) {
+	t.Parallel()
+
+	stateDir := t.TempDir()
+	absoluteHistoryPath := filepath.Join(t.TempDir(), "history.jsonl")
+
+	cfg := &Config{
+		StateDir:        stateDir,
+		ResumeStatePath: DefaultResumeStatePath,
+		SyncStatePath:   DefaultSyncStatePath,
+		HistoryPath:     absoluteHistoryPath,
+	}
+
+	ResolveStatePaths(cfg)
+
+	assert.Equal(t, filepath.Join(stateDir, DefaultResumeStatePath), cfg.ResumeStatePath)
+	assert.Equal(t, filepath.Join(stateDir, DefaultSyncStatePath), cfg.SyncStatePath)
+	assert.Equal(t, absoluteHistoryPath, cfg.HistoryPath)
+	assert.Empty(t, cfg.UpgradeWatchPath, "disabled state files must stay disabled")
+
+	// Resolving twice changes nothing.
+	ResolveStatePaths(cfg)
+	assert.Equal(t, filepath.Join(stateDir, DefaultResumeStatePath), cfg.ResumeStatePath)
+}
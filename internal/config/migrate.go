@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// configBackupSuffix is appended to the config filename when saving a backup before migration.
+const configBackupSuffix = ".bak"
+
+// MigrationResult describes the changes applied to a configuration file.
+type MigrationResult struct {
+	// Changes contains a human-readable description of every applied change.
+	Changes []string
+	// BackupPath is the path to the copy of the original file (empty if nothing changed).
+	BackupPath string
+}
+
+// configMigration describes a single change of the configuration schema.
+type configMigration struct {
+	// apply modifies the top-level mapping node and returns descriptions of the changes made.
+	apply func(mapNode *yaml.Node) []string
+}
+
+// configMigrations lists all known schema changes in the order they were introduced.
+//
+//nolint:gochecknoglobals // It is a static registry of migrations.
+var configMigrations = []*configMigration{
+	{apply: renameKey("format", "quality")},
+	{apply: renameKey("lyrics", "download_lyrics")},
+	{apply: convertSecondsToDuration("min_duration")},
+	{apply: convertSecondsToDuration("max_duration")},
+}
+
+// MigrateConfig detects deprecated keys and formats in the configuration file,
+// rewrites it to the current schema, and keeps a backup of the original.
+// A missing file is not an error: there is nothing to migrate.
+// TOML and JSON files are never migrated, since they were not supported by the versions with deprecated keys.
+func MigrateConfig(configFilename string) (*MigrationResult, error) {
+	if configFilename == "" {
+		configFilename = DefaultConfigFilename
+	}
+
+	result := new(MigrationResult)
+	if configFormat(configFilename) != configFormatYAML {
+		return result, nil
+	}
+
+	originalContent, err := os.ReadFile(configFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Files are migrated before they are encrypted (see EncryptConfigFile), so encrypted ones hold no deprecated keys.
+	if IsEncryptedConfig(originalContent) {
+		return result, nil
+	}
+
+	newContent, changes, err := migrateConfigContent(originalContent)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Changes = changes
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	result.BackupPath = configFilename + configBackupSuffix
+	if err = os.WriteFile(result.BackupPath, originalContent, constants.DefaultFilePermissions); err != nil {
+		return nil, fmt.Errorf("failed to write config backup: %w", err)
+	}
+
+	if err = os.WriteFile(configFilename, newContent, constants.DefaultFilePermissions); err != nil {
+		return nil, fmt.Errorf("failed to write migrated config file: %w", err)
+	}
+
+	return result, nil
+}
+
+// migrateConfigContent rewrites the YAML content to the current schema.
+// It returns the new content and the changes made, or no changes when the content is already current.
+func migrateConfigContent(content []byte) ([]byte, []string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	// The root node is a document node, content[0] is the actual map.
+	if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return content, nil, nil
+	}
+
+	var changes []string
+	for _, migration := range configMigrations {
+		changes = append(changes, migration.apply(node.Content[0])...)
+	}
+
+	if len(changes) == 0 {
+		return content, nil, nil
+	}
+
+	newContent, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return newContent, changes, nil
+}
+
+// findKeyIndex returns the index of the key node in the mapping node, or -1 if it is absent.
+func findKeyIndex(mapNode *yaml.Node, key string) int {
+	// Iterate through key-value pairs (stored as alternating nodes).
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		if mapNode.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// renameKey returns a migration that renames a deprecated key while keeping its value and position.
+// If both keys are present, the deprecated one is removed and the current one wins.
+func renameKey(oldKey, newKey string) func(*yaml.Node) []string {
+	return func(mapNode *yaml.Node) []string {
+		oldIndex := findKeyIndex(mapNode, oldKey)
+		if oldIndex < 0 {
+			return nil
+		}
+
+		if findKeyIndex(mapNode, newKey) >= 0 {
+			mapNode.Content = append(mapNode.Content[:oldIndex], mapNode.Content[oldIndex+2:]...)
+
+			return []string{
+				fmt.Sprintf("removed deprecated '%s' because '%s' is already set", oldKey, newKey),
+			}
+		}
+
+		mapNode.Content[oldIndex].Value = newKey
+
+		return []string{fmt.Sprintf("renamed '%s' to '%s'", oldKey, newKey)}
+	}
+}
+
+// convertSecondsToDuration returns a migration that converts a plain number of seconds
+// into a Go duration string (e.g., 90 becomes "1m30s").
+func convertSecondsToDuration(key string) func(*yaml.Node) []string {
+	return func(mapNode *yaml.Node) []string {
+		index := findKeyIndex(mapNode, key)
+		if index < 0 {
+			return nil
+		}
+
+		valueNode := mapNode.Content[index+1]
+		if valueNode.Kind != yaml.ScalarNode || valueNode.Tag != "!!int" {
+			return nil
+		}
+
+		seconds, err := strconv.ParseInt(valueNode.Value, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil
+		}
+
+		duration := (time.Duration(seconds) * time.Second).String()
+		oldValue := valueNode.Value
+
+		valueNode.Value = duration
+		valueNode.Tag = "!!str"
+		valueNode.Style = yaml.DoubleQuotedStyle
+
+		return []string{fmt.Sprintf("converted '%s' from %s seconds to \"%s\"", key, oldValue, duration)}
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestMigrateConfig tests that deprecated keys and formats are rewritten with a backup.
+func TestMigrateConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedChanges int
+		expectedKeys    map[string]string
+		absentKeys      []string
+	}{
+		{
+			name:            "current schema - nothing to migrate",
+			content:         "quality: 3\nmin_duration: \"30s\"\n",
+			expectedChanges: 0,
+			expectedKeys:    map[string]string{"quality": "3", "min_duration": "30s"},
+		},
+		{
+			name:            "deprecated keys are renamed",
+			content:         "format: 2\nlyrics: true\noutput_path: \"music\"\n",
+			expectedChanges: 2,
+			expectedKeys:    map[string]string{"quality": "2", "download_lyrics": "true", "output_path": "music"},
+			absentKeys:      []string{"format", "lyrics"},
+		},
+		{
+			name:            "deprecated key is dropped when the current one is set",
+			content:         "format: 1\nquality: 3\n",
+			expectedChanges: 1,
+			expectedKeys:    map[string]string{"quality": "3"},
+			absentKeys:      []string{"format"},
+		},
+		{
+			name:            "durations in seconds are converted",
+			content:         "min_duration: 30\nmax_duration: 900\n",
+			expectedChanges: 2,
+			expectedKeys:    map[string]string{"min_duration": "30s", "max_duration": "15m0s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+			//nolint:gosec // It's a test file.
+			err := os.WriteFile(configPath, []byte(tt.content), constants.DefaultFilePermissions)
+			require.NoError(t, err)
+
+			result, err := MigrateConfig(configPath)
+			require.NoError(t, err)
+			assert.Len(t, result.Changes, tt.expectedChanges)
+
+			if tt.expectedChanges == 0 {
+				assert.Empty(t, result.BackupPath)
+				assert.NoFileExists(t, configPath+configBackupSuffix)
+
+				return
+			}
+
+			// The backup must contain the original content.
+			backupContent, err := os.ReadFile(result.BackupPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(backupContent))
+
+			var migrated map[string]any
+
+			migratedContent, err := os.ReadFile(configPath)
+			require.NoError(t, err)
+			require.NoError(t, yaml.Unmarshal(migratedContent, &migrated))
+
+			for key, expected := range tt.expectedKeys {
+				assert.Contains(t, migrated, key)
+				assert.Equal(t, expected, fmt.Sprint(migrated[key]), "unexpected value for %s", key)
+			}
+
+			for _, key := range tt.absentKeys {
+				assert.NotContains(t, migrated, key)
+			}
+		})
+	}
+}
+
+// TestMigrateConfig_MissingFile tests that a missing config file is not treated as an error.
+func TestMigrateConfig_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	result, err := MigrateConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+	assert.Empty(t, result.BackupPath)
+}
+
+// TestMigrateConfig_TOML tests that a TOML config file is left as it is.
+func TestMigrateConfig_TOML(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("format = 3\n"), constants.DefaultFilePermissions))
+
+	result, err := MigrateConfig(configPath)
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "format = 3\n", string(content))
+}
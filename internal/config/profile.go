@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// profilesKey is the section of the configuration file holding the named profiles.
+const profilesKey = "profiles"
+
+var (
+	// ErrUnknownProfile indicates that the configuration file has no profile with the requested name.
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrInvalidProfile indicates that a profile is not a section of settings.
+	ErrInvalidProfile = errors.New("invalid profile")
+)
+
+// applyProfile merges the settings of the named profile over the base settings read by viper.
+// Profile names are case-insensitive, like every other key of the configuration file.
+func applyProfile(profileName string) error {
+	profiles := viper.GetStringMap(profilesKey)
+
+	profile, ok := profiles[strings.ToLower(profileName)]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+
+		if len(names) == 0 {
+			return fmt.Errorf("%w '%s': the configuration file has no profiles section", ErrUnknownProfile, profileName)
+		}
+
+		slices.Sort(names)
+
+		return fmt.Errorf("%w '%s': available profiles are %s",
+			ErrUnknownProfile, profileName, strings.Join(names, ", "))
+	}
+
+	settings, ok := profile.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w '%s': must be a section of settings", ErrInvalidProfile, profileName)
+	}
+
+	// Profiles are not nested.
+	delete(settings, profilesKey)
+
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to apply profile '%s': %w", profileName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTrackRanges indicates that the track positions passed to --tracks cannot be parsed.
+var ErrInvalidTrackRanges = errors.New("invalid track ranges")
+
+// TrackRange is a range of track positions in a collection, counted from 1.
+type TrackRange struct {
+	// From is the first position of the range.
+	From int64
+	// To is the last position of the range (0 for a range open to the end of the collection).
+	To int64
+}
+
+// Contains reports whether the position is in the range.
+func (r TrackRange) Contains(position int64) bool {
+	return position >= r.From && (r.To == 0 || position <= r.To)
+}
+
+// ParseTrackRanges parses comma-separated track positions and ranges, e.g. "1-3,7,12-".
+func ParseTrackRanges(value string) ([]TrackRange, error) {
+	var result []TrackRange
+
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+
+		from, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+		if err != nil || from < 1 {
+			return nil, fmt.Errorf("%w '%s': positions start from 1", ErrInvalidTrackRanges, part)
+		}
+
+		trackRange := TrackRange{From: from, To: from}
+
+		if isRange {
+			trackRange.To = 0
+
+			if last = strings.TrimSpace(last); last != "" {
+				trackRange.To, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || trackRange.To < from {
+					return nil, fmt.Errorf("%w '%s': a range must end at or after its start",
+						ErrInvalidTrackRanges, part)
+				}
+			}
+		}
+
+		result = append(result, trackRange)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w '%s': no positions given", ErrInvalidTrackRanges, value)
+	}
+
+	return result, nil
+}
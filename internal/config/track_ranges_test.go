@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTrackRanges tests the parsing of the track positions passed to --tracks.
+func TestParseTrackRanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      []TrackRange
+		expectedError bool
+	}{
+		{
+			name:     "positions and ranges",
+			value:    "1-3, 7,12-",
+			expected: []TrackRange{{From: 1, To: 3}, {From: 7, To: 7}, {From: 12}},
+		},
+		{name: "single position", value: "5", expected: []TrackRange{{From: 5, To: 5}}},
+		{name: "empty", value: " , ", expectedError: true},
+		{name: "zero position", value: "0-2", expectedError: true},
+		{name: "reversed range", value: "5-3", expectedError: true},
+		{name: "not a number", value: "b-side", expectedError: true},
+		{name: "open start", value: "-3", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ranges, err := ParseTrackRanges(tt.value)
+			if tt.expectedError {
+				require.ErrorIs(t, err, ErrInvalidTrackRanges)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ranges)
+		})
+	}
+}
+
+// TestTrackRangeContains tests the positions matched by a track range.
+func TestTrackRangeContains(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, TrackRange{From: 2, To: 4}.Contains(2))
+	assert.True(t, TrackRange{From: 2, To: 4}.Contains(4))
+	assert.False(t, TrackRange{From: 2, To: 4}.Contains(5))
+	assert.True(t, TrackRange{From: 12}.Contains(300))
+	assert.False(t, TrackRange{From: 12}.Contains(11))
+}
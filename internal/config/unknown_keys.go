@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// maxKeySuggestionDistance is the largest number of edits between an unknown key and the key suggested for it.
+const maxKeySuggestionDistance = 3
+
+// ErrUnknownKey indicates that the configuration file has a key no setting reads, usually a misspelled one.
+var ErrUnknownKey = errors.New("unknown configuration key")
+
+// checkUnknownKeys reports every key of the configuration file read by viper that is not a setting,
+// including the keys of the profiles, suggesting the closest known key for each.
+// Viper ignores such keys, so a typo would silently leave the setting at its default.
+func checkUnknownKeys() error {
+	knownKeys := Keys()
+
+	var errs []error
+
+	settings := viper.AllSettings()
+
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if key == profilesKey {
+			errs = append(errs, checkUnknownProfileKeys(settings[key], knownKeys)...)
+
+			continue
+		}
+
+		if !slices.Contains(knownKeys, key) {
+			errs = append(errs, unknownKeyError(key, key, knownKeys))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkUnknownProfileKeys reports the unknown keys of every profile. A profile that is not a section
+// is left for applyProfile to report when it is used.
+func checkUnknownProfileKeys(profilesSection any, knownKeys []string) []error {
+	profiles, ok := profilesSection.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+
+	for _, profileName := range slices.Sorted(maps.Keys(profiles)) {
+		profile, isSection := profiles[profileName].(map[string]any)
+		if !isSection {
+			continue
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(profile)) {
+			if !slices.Contains(knownKeys, key) {
+				errs = append(errs, unknownKeyError(profilesKey+"."+profileName+"."+key, key, knownKeys))
+			}
+		}
+	}
+
+	return errs
+}
+
+// unknownKeyError describes the unknown key found at path, with the closest known key if there is one.
+func unknownKeyError(path, key string, knownKeys []string) error {
+	suggestion := suggestKey(key, knownKeys)
+	if suggestion == "" {
+		return fmt.Errorf("%w '%s'", ErrUnknownKey, path)
+	}
+
+	return fmt.Errorf("%w '%s': did you mean '%s'?", ErrUnknownKey, path, suggestion)
+}
+
+// suggestKey returns the known key closest to the given one,
+// or an empty string when none is within maxKeySuggestionDistance edits.
+func suggestKey(key string, knownKeys []string) string {
+	var (
+		result       string
+		bestDistance = maxKeySuggestionDistance + 1
+	)
+
+	for _, knownKey := range knownKeys {
+		if distance := editDistance(key, knownKey); distance < bestDistance {
+			result = knownKey
+			bestDistance = distance
+		}
+	}
+
+	return result
+}
+
+// editDistance returns the Levenshtein distance between two strings:
+// the number of inserted, deleted, or replaced characters turning one into the other.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := range source {
+		current[0] = i + 1
+
+		for j := range target {
+			cost := 1
+			if source[i] == target[j] {
+				cost = 0
+			}
+
+			current[j+1] = min(previous[j+1]+1, current[j]+1, previous[j]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
@@ -0,0 +1,21 @@
+package constants
+
+// Process exit codes, so scripts and schedulers can tell the outcomes of a run apart.
+const (
+	// ExitCodeOK means everything was done.
+	ExitCodeOK = 0
+	// ExitCodeFailure means the command failed for a reason without a code of its own.
+	ExitCodeFailure = 1
+	// ExitCodeInvalidConfig means the configuration file or the flags are invalid.
+	ExitCodeInvalidConfig = 2
+	// ExitCodeAuthFailure means Zvuk rejected the auth token, or the account has no subscription.
+	ExitCodeAuthFailure = 3
+	// ExitCodePartialSuccess means the download finished, but some items failed.
+	ExitCodePartialSuccess = 4
+	// ExitCodeAllSkipped means the download finished without errors, but every track was skipped.
+	ExitCodeAllSkipped = 5
+	// ExitCodeOutputLocked means that nothing was done, as another run holds the lock of the output path.
+	ExitCodeOutputLocked = 6
+	// ExitCodeInterrupted means the run was stopped by a signal, e.g., CTRL+C (128 + SIGINT).
+	ExitCodeInterrupted = 130
+)
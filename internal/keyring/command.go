@@ -0,0 +1,60 @@
+//go:build darwin || linux || freebsd
+
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errCommandFailed indicates that the keyring tool exited with an error.
+var errCommandFailed = errors.New("keyring tool failed")
+
+// commandResult is the outcome of a keyring tool run.
+type commandResult struct {
+	// stdout is the standard output of the tool.
+	stdout string
+	// stderr is the standard error of the tool, trimmed.
+	stderr string
+	// exitCode is the exit code of the tool, 0 on success.
+	exitCode int
+}
+
+// runCommand runs the keyring tool with the input on its standard input.
+// A tool that exits with an error is not an error here: the caller interprets the exit code.
+func runCommand(input, name string, args ...string) (*commandResult, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(name, args...) //nolint:gosec,noctx // Arguments are built by the application.
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	result := &commandResult{}
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run %s: %w", name, err)
+		}
+
+		result.exitCode = exitErr.ExitCode()
+	}
+
+	result.stdout = stdout.String()
+	result.stderr = strings.TrimSpace(stderr.String())
+
+	return result, nil
+}
+
+// failure describes the failed run of the keyring tool.
+func (r *commandResult) failure(name string) error {
+	if r.stderr == "" {
+		return fmt.Errorf("%w: %s exited with code %d", errCommandFailed, name, r.exitCode)
+	}
+
+	return fmt.Errorf("%w: %s: %s", errCommandFailed, name, r.stderr)
+}
@@ -0,0 +1,3 @@
+// Package keyring stores secrets in the credential store of the operating system:
+// the macOS Keychain, the Windows Credential Manager, or the Secret Service (libsecret) on Linux and FreeBSD.
+package keyring
@@ -0,0 +1,36 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Service is the name the secrets of the application are stored under.
+const Service = "zvuk-grabber"
+
+var (
+	// ErrNotFound indicates that the keyring has no secret for the account.
+	ErrNotFound = errors.New("secret not found in the keyring")
+	// ErrUnsupported indicates that the platform has no supported keyring.
+	ErrUnsupported = errors.New("keyring is not supported on this platform")
+)
+
+// Get returns the secret stored in the keyring for the account.
+// ErrNotFound is returned when there is none.
+func Get(account string) (string, error) {
+	secret, err := get(Service, account)
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s' from the keyring: %w", account, err)
+	}
+
+	return secret, nil
+}
+
+// Set stores the secret in the keyring for the account, replacing the one stored before.
+func Set(account, secret string) error {
+	if err := set(Service, account, secret); err != nil {
+		return fmt.Errorf("failed to write '%s' to the keyring: %w", account, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package keyring
+
+import "strings"
+
+const (
+	// securityTool is the macOS command-line interface to the Keychain.
+	securityTool = "security"
+	// securityItemNotFoundCode is the exit code of security when the Keychain has no such item.
+	securityItemNotFoundCode = 44
+)
+
+// get reads the generic password of the account from the login Keychain.
+func get(service, account string) (string, error) {
+	result, err := runCommand("", securityTool, "find-generic-password", "-s", service, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+
+	switch result.exitCode {
+	case 0:
+		return strings.TrimSuffix(result.stdout, "\n"), nil
+	case securityItemNotFoundCode:
+		return "", ErrNotFound
+	default:
+		return "", result.failure(securityTool)
+	}
+}
+
+// set adds the generic password of the account to the login Keychain, updating the existing one.
+// security only takes the password as an argument.
+func set(service, account, secret string) error {
+	result, err := runCommand("", securityTool, "add-generic-password", "-U", "-s", service, "-a", account, "-w", secret)
+	if err != nil {
+		return err
+	}
+
+	if result.exitCode != 0 {
+		return result.failure(securityTool)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !freebsd && !windows
+
+package keyring
+
+// get reports that there is no keyring on the platform.
+func get(_, _ string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// set reports that there is no keyring on the platform.
+func set(_, _, _ string) error {
+	return ErrUnsupported
+}
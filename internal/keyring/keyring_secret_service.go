@@ -0,0 +1,41 @@
+//go:build linux || freebsd
+
+package keyring
+
+import "strings"
+
+// secretTool is the libsecret command-line interface to the Secret Service (GNOME Keyring, KWallet).
+const secretTool = "secret-tool"
+
+// get looks up the secret of the account in the Secret Service.
+// secret-tool exits with an error and prints nothing when there is no such secret.
+func get(service, account string) (string, error) {
+	result, err := runCommand("", secretTool, "lookup", "service", service, "account", account)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case result.exitCode == 0:
+		return strings.TrimSuffix(result.stdout, "\n"), nil
+	case result.stderr == "":
+		return "", ErrNotFound
+	default:
+		return "", result.failure(secretTool)
+	}
+}
+
+// set stores the secret of the account in the Secret Service, passing it on the standard input.
+func set(service, account, secret string) error {
+	result, err := runCommand(secret, secretTool, "store", "--label", service+" "+account,
+		"service", service, "account", account)
+	if err != nil {
+		return err
+	}
+
+	if result.exitCode != 0 {
+		return result.failure(secretTool)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build linux || freebsd
+
+package keyring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretTool is a secret-tool keeping a single secret in a file next to it.
+const fakeSecretTool = `#!/bin/sh
+store="$(dirname "$0")/secret"
+case "$1" in
+lookup) [ -f "$store" ] && cat "$store" ;;
+store) cat > "$store" ;;
+*) echo "unknown command" >&2; exit 2 ;;
+esac
+`
+
+// TestSecretService tests that a secret is stored with secret-tool and read back.
+//
+//nolint:paralleltest // Cannot run in parallel because PATH is changed.
+func TestSecretService(t *testing.T) {
+	toolDir := t.TempDir()
+	toolPath := filepath.Join(toolDir, secretTool)
+
+	require.NoError(t, os.WriteFile(toolPath, []byte(fakeSecretTool), 0o700)) //nolint:gosec // The tool must be executable.
+	t.Setenv("PATH", toolDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	_, err := Get("auth_token")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, Set("auth_token", "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2"))
+
+	secret, err := Get("auth_token")
+	require.NoError(t, err)
+	assert.Equal(t, "a3f8e7b2c5d946f1a0b9e8d7c6f5e4a2", secret)
+}
@@ -0,0 +1,122 @@
+//go:build windows
+
+package keyring
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// credTypeGeneric is CRED_TYPE_GENERIC, a credential used by the application itself.
+	credTypeGeneric = 1
+	// credPersistLocalMachine is CRED_PERSIST_LOCAL_MACHINE, a credential kept for the user on this computer.
+	credPersistLocalMachine = 2
+)
+
+var (
+	// advapi32 is the library of the Credential Manager API.
+	advapi32 = windows.NewLazySystemDLL("advapi32.dll")
+	// procCredReadW reads a credential.
+	procCredReadW = advapi32.NewProc("CredReadW")
+	// procCredWriteW creates or replaces a credential.
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	// procCredFree frees a credential returned by CredReadW.
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure of the Credential Manager API.
+type credential struct {
+	// Flags are the CRED_FLAGS_* of the credential.
+	Flags uint32
+	// Type is the CRED_TYPE_* of the credential.
+	Type uint32
+	// TargetName is the name the credential is looked up by.
+	TargetName *uint16
+	// Comment describes the credential.
+	Comment *uint16
+	// LastWritten is the time the credential was last changed.
+	LastWritten windows.Filetime
+	// CredentialBlobSize is the size of the secret in bytes.
+	CredentialBlobSize uint32
+	// CredentialBlob is the secret.
+	CredentialBlob *byte
+	// Persist is the CRED_PERSIST_* of the credential.
+	Persist uint32
+	// AttributeCount is the number of application-defined attributes.
+	AttributeCount uint32
+	// Attributes are the application-defined attributes.
+	Attributes uintptr
+	// TargetAlias is an alias of the target name.
+	TargetAlias *uint16
+	// UserName is the account the credential belongs to.
+	UserName *uint16
+}
+
+// targetName returns the name of the credential of the account.
+func targetName(service, account string) string {
+	return service + ":" + account
+}
+
+// get reads the generic credential of the account from the Credential Manager.
+func get(service, account string) (string, error) {
+	target, err := windows.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return "", err
+	}
+
+	var result *credential
+
+	ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0,
+		uintptr(unsafe.Pointer(&result)))
+	if ret == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return "", ErrNotFound
+		}
+
+		return "", err
+	}
+
+	defer procCredFree.Call(uintptr(unsafe.Pointer(result))) //nolint:errcheck // CredFree returns nothing.
+
+	if result.CredentialBlobSize == 0 {
+		return "", nil
+	}
+
+	return string(unsafe.Slice(result.CredentialBlob, result.CredentialBlobSize)), nil
+}
+
+// set writes the generic credential of the account to the Credential Manager, replacing the existing one.
+func set(service, account, secret string) error {
+	target, err := windows.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return err
+	}
+
+	userName, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(secret)
+
+	cred := &credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		UserName:           userName,
+		CredentialBlobSize: uint32(len(blob)), //nolint:gosec // Tokens are far below the 4 GiB limit.
+		Persist:            credPersistLocalMachine,
+	}
+
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+
+	if ret, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(cred)), 0); ret == 0 {
+		return callErr
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 	"os"
 	"strings"
 	"sync"
@@ -28,6 +29,11 @@ func init() { //nolint:gochecknoinits // If the logging level is not set, the ap
 // New creates a new instance of *zap.SugaredLogger with output in simple console format.
 // If the logging level is not provided, the default level (zap.InfoLevel) will be used.
 func New(level zapcore.LevelEnabler, options ...zap.Option) *zap.SugaredLogger {
+	return newConsoleLogger(os.Stdout, level, options...)
+}
+
+// newConsoleLogger creates a new instance of *zap.SugaredLogger writing in simple console format to w.
+func newConsoleLogger(w io.Writer, level zapcore.LevelEnabler, options ...zap.Option) *zap.SugaredLogger {
 	if level == nil {
 		level = defaultLevel
 	}
@@ -46,15 +52,58 @@ func New(level zapcore.LevelEnabler, options ...zap.Option) *zap.SugaredLogger {
 		ConsoleSeparator: ", ",
 	})
 
-	core := zapcore.NewCore(
+	// Secrets are masked before anything is encoded.
+	core := newRedactingCore(zapcore.NewCore(
 		defaultEncoder,
-		zapcore.AddSync(os.Stdout),
+		zapcore.AddSync(w),
 		level,
-	)
+	))
 
 	return zap.New(core, options...).Sugar()
 }
 
+// NewPlain creates a new instance of *zap.SugaredLogger that writes only the messages to w,
+// without timestamps, levels, or colors. It is used to save reports printed through the logger to files.
+func NewPlain(w io.Writer) *zap.SugaredLogger {
+	//nolint:exhaustruct // Only the message is written.
+	plainEncoder := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
+		MessageKey: "message",
+		LineEnding: zapcore.DefaultLineEnding,
+	})
+
+	core := newRedactingCore(zapcore.NewCore(
+		plainEncoder,
+		zapcore.AddSync(w),
+		defaultLevel,
+	))
+
+	return zap.New(core).Sugar()
+}
+
+// NewTee creates a new instance of *zap.SugaredLogger that writes to the global logger
+// and, in console format without colors, to w. It is used to keep a copy of the log of a single task.
+func NewTee(w io.Writer) *zap.SugaredLogger {
+	//nolint:exhaustruct // I'm okay with default encoder configuration values.
+	teeEncoder := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
+		MessageKey:       "message",
+		LevelKey:         "level",
+		TimeKey:          "time",
+		LineEnding:       zapcore.DefaultLineEnding,
+		EncodeLevel:      zapcore.CapitalLevelEncoder,
+		EncodeTime:       zapcore.ISO8601TimeEncoder,
+		EncodeDuration:   zapcore.StringDurationEncoder,
+		ConsoleSeparator: ", ",
+	})
+
+	core := newRedactingCore(zapcore.NewCore(
+		teeEncoder,
+		zapcore.AddSync(w),
+		defaultLevel,
+	))
+
+	return zap.New(zapcore.NewTee(global.Desugar().Core(), core)).Sugar()
+}
+
 // ParseLogLevel converts string input to zap log level.
 func ParseLogLevel(s string) (zapcore.Level, bool) {
 	s = strings.ToLower(strings.TrimSpace(s))
@@ -99,6 +148,13 @@ func SetLogger(l *zap.SugaredLogger) {
 	global = l
 }
 
+// SetOutput makes the global logger write to w instead of standard output,
+// e.g., to keep standard output for machine-readable results.
+// This function is not thread-safe.
+func SetOutput(w io.Writer) {
+	SetLogger(newConsoleLogger(w, defaultLevel))
+}
+
 // SetFatalHandler overrides fatal behavior for tests.
 func SetFatalHandler(handler func(int)) {
 	fatalHandlerMutex.Lock()
@@ -182,7 +238,7 @@ func ErrorKV(ctx context.Context, message string, kvs ...any) {
 // Fatal writes a fatal error level message
 // using the logger from the context and then calls os.Exit(1).
 func Fatal(ctx context.Context, args ...any) {
-	if handleFatal(ctx, func(l *zap.SugaredLogger) {
+	if handleFatal(ctx, 1, func(l *zap.SugaredLogger) {
 		l.Error(args...)
 	}) {
 		return
@@ -194,7 +250,7 @@ func Fatal(ctx context.Context, args ...any) {
 // Fatalf writes a formatted fatal error level message
 // using the logger from the context and then calls os.Exit(1).
 func Fatalf(ctx context.Context, format string, args ...any) {
-	if handleFatal(ctx, func(l *zap.SugaredLogger) {
+	if handleFatal(ctx, 1, func(l *zap.SugaredLogger) {
 		l.Errorf(format, args...)
 	}) {
 		return
@@ -207,7 +263,7 @@ func Fatalf(ctx context.Context, format string, args ...any) {
 // at the fatal error level using the logger from the context
 // and then calls os.Exit(1).
 func FatalKV(ctx context.Context, message string, kvs ...any) {
-	if handleFatal(ctx, func(l *zap.SugaredLogger) {
+	if handleFatal(ctx, 1, func(l *zap.SugaredLogger) {
 		l.Errorw(message, kvs...)
 	}) {
 		return
@@ -216,11 +272,31 @@ func FatalKV(ctx context.Context, message string, kvs ...any) {
 	FromContext(ctx).Fatalw(message, kvs...)
 }
 
+// FatalCodef writes a formatted fatal error level message
+// using the logger from the context and then calls os.Exit with the code.
+func FatalCodef(ctx context.Context, code int, format string, args ...any) {
+	if handleFatal(ctx, code, func(l *zap.SugaredLogger) {
+		l.Errorf(format, args...)
+	}) {
+		return
+	}
+
+	FromContext(ctx).WithOptions(zap.WithFatalHook(exitHook(code))).Fatalf(format, args...)
+}
+
+// exitHook exits the process with its code once a fatal message is written.
+type exitHook int
+
+// OnWrite implements zapcore.CheckWriteHook.
+func (h exitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	os.Exit(int(h))
+}
+
 // handleFatal manages fatal error handling by checking if a custom fatal handler
 // is registered and executing it instead of the default fatal behavior.
 // This is primarily used for testing to prevent os.Exit calls during tests.
 // Returns true if a custom handler was executed, false if normal fatal behavior should proceed.
-func handleFatal(ctx context.Context, logFunc func(*zap.SugaredLogger)) bool {
+func handleFatal(ctx context.Context, code int, logFunc func(*zap.SugaredLogger)) bool {
 	fatalHandlerMutex.Lock()
 
 	atomicHandler := fatalHandler
@@ -232,7 +308,7 @@ func handleFatal(ctx context.Context, logFunc func(*zap.SugaredLogger)) bool {
 	}
 
 	logFunc(FromContext(ctx))
-	atomicHandler(1)
+	atomicHandler(code)
 
 	return true
 }
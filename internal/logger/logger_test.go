@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
@@ -44,6 +45,33 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestNewPlain tests that the plain logger writes only the messages.
+func TestNewPlain(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	ctx := ToContext(context.Background(), NewPlain(&output))
+
+	Info(ctx, "DOWNLOAD SUMMARY")
+	Errorf(ctx, "ERRORS ENCOUNTERED: %d", 2)
+
+	assert.Equal(t, "DOWNLOAD SUMMARY\nERRORS ENCOUNTERED: 2\n", output.String())
+}
+
+// TestNewTee tests that the tee logger copies the messages with their levels to the writer.
+func TestNewTee(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	ctx := ToContext(context.Background(), NewTee(&output))
+
+	Warnf(ctx, "Track '%s' is skipped", "Sonne")
+
+	assert.Contains(t, output.String(), ", WARN, Track 'Sonne' is skipped\n")
+}
+
 // TestParseLogLevel tests the ParseLogLevel function.
 func TestParseLogLevel(t *testing.T) {
 	t.Parallel()
@@ -263,3 +291,21 @@ func TestLoggerThreadSafety(_ *testing.T) {
 		<-done
 	}
 }
+
+// TestFatalCodef tests that FatalCodef passes its exit code to the fatal handler.
+//
+//nolint:paralleltest // Cannot run in parallel due to the global fatal handler.
+func TestFatalCodef(t *testing.T) {
+	var exitCode int
+
+	SetFatalHandler(func(code int) {
+		exitCode = code
+	})
+	defer SetFatalHandler(nil)
+
+	FatalCodef(context.Background(), 3, "test fatal message: %s", "formatted")
+	assert.Equal(t, 3, exitCode)
+
+	Fatalf(context.Background(), "test fatal message: %s", "formatted")
+	assert.Equal(t, 1, exitCode)
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedPlaceholder replaces secrets in log output.
+const redactedPlaceholder = "[REDACTED]"
+
+// minSecretLength is the length below which registered values are not treated as secrets,
+// so that short strings do not mask unrelated parts of the output.
+const minSecretLength = 8
+
+var (
+	//nolint:gochecknoglobals // Registered secrets are shared by all loggers.
+	secrets []string
+	//nolint:gochecknoglobals // Protects secrets.
+	secretsMutex sync.RWMutex
+
+	// secretHeaderPattern matches HTTP header lines carrying credentials.
+	//nolint:gochecknoglobals // Compiled once.
+	secretHeaderPattern = regexp.MustCompile(`(?im)^((?:x-auth-token|authorization|cookie|set-cookie)\s*:\s*)[^\r\n]+`)
+	// secretJSONFieldPattern matches JSON string fields carrying tokens and stream URLs.
+	//nolint:gochecknoglobals // Compiled once.
+	secretJSONFieldPattern = regexp.MustCompile(`(?i)("(?:token|auth_token|stream)"\s*:\s*")[^"]+`)
+	// signedURLParameterPattern matches query parameters that sign stream URLs.
+	//nolint:gochecknoglobals // Compiled once.
+	signedURLParameterPattern = regexp.MustCompile(
+		`(?i)([?&][^=&\s"']*(?:token|sign|sig|hash|expires|key|policy)[^=&\s"']*=)[^&\s"'\\]+`)
+)
+
+// AddSecret registers a value (e.g., the auth token) that is masked in every log message.
+// Values shorter than a few characters are ignored.
+func AddSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+
+	secrets = append(secrets, secret)
+}
+
+// Redact masks secrets in the text: registered values, credential headers,
+// token and stream URL fields of JSON bodies, and signature parameters of URLs.
+func Redact(text string) string {
+	secretsMutex.RLock()
+
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redactedPlaceholder)
+	}
+
+	secretsMutex.RUnlock()
+
+	text = secretHeaderPattern.ReplaceAllString(text, "${1}"+redactedPlaceholder)
+	text = secretJSONFieldPattern.ReplaceAllString(text, "${1}"+redactedPlaceholder)
+
+	return signedURLParameterPattern.ReplaceAllString(text, "${1}"+redactedPlaceholder)
+}
+
+// redactingCore wraps a zapcore.Core and masks secrets in messages and string fields.
+type redactingCore struct {
+	// Core is the wrapped zapcore.Core.
+	zapcore.Core
+}
+
+// newRedactingCore wraps the core with secret redaction.
+func newRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{core}
+}
+
+// With returns a new core with added fields to the wrapped core, redacting their values.
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{c.Core.With(redactFields(fields))}
+}
+
+// Check adds the core to a checked entry if the log entry level is enabled for logging.
+//
+//nolint:gocritic // AddCore requires ent to be passed by value.
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write redacts the message and the fields before passing them to the wrapped core.
+//
+//nolint:gocritic // Write implements zapcore.Core, which passes ent by value.
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = Redact(ent.Message)
+	ent.Stack = Redact(ent.Stack)
+
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields returns a copy of the fields with redacted string and error values.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+
+	for i, field := range fields {
+		//nolint:exhaustive // Only textual fields can contain secrets.
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = Redact(field.String)
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok && err != nil {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: Redact(err.Error())}
+			}
+		case zapcore.StringerType:
+			if stringer, ok := field.Interface.(interface{ String() string }); ok && stringer != nil {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: Redact(stringer.String())}
+			}
+		}
+
+		redacted[i] = field
+	}
+
+	return redacted
+}
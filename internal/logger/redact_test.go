@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestRedact tests the Redact function.
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	AddSecret("registered-secret-value")
+	AddSecret("short")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "registered secret",
+			input:    "token registered-secret-value was rejected",
+			expected: "token [REDACTED] was rejected",
+		},
+		{
+			name:     "short values are not registered",
+			input:    "a short message",
+			expected: "a short message",
+		},
+		{
+			name:     "credential headers",
+			input:    "GET /api HTTP/1.1\r\nX-Auth-Token: abc\r\nCookie: auth=xyz\r\nAccept: */*\r\n",
+			expected: "GET /api HTTP/1.1\r\nX-Auth-Token: [REDACTED]\r\nCookie: [REDACTED]\r\nAccept: */*\r\n",
+		},
+		{
+			name:     "JSON token and stream fields",
+			input:    `{"result":{"token":"abc","stream":"https://cdn.example.com/a.flac"},"title":"Song"}`,
+			expected: `{"result":{"token":"[REDACTED]","stream":"[REDACTED]"},"title":"Song"}`,
+		},
+		{
+			name:     "signed URL parameters",
+			input:    "GET https://cdn.example.com/a.mp3?id=1&expires=123&signature=abc failed",
+			expected: "GET https://cdn.example.com/a.mp3?id=1&expires=[REDACTED]&signature=[REDACTED] failed",
+		},
+		{
+			name:     "plain text",
+			input:    "Downloading album 'Mutter'",
+			expected: "Downloading album 'Mutter'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, Redact(tt.input))
+		})
+	}
+}
+
+// TestRedactingCore tests that messages and fields are redacted before they are written.
+func TestRedactingCore(t *testing.T) {
+	t.Parallel()
+
+	AddSecret("core-secret-value")
+
+	observedCore, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactingCore(observedCore)).Sugar()
+
+	log.With("token", "core-secret-value").
+		Debugw("Request with core-secret-value", "error", errors.New("bad token core-secret-value"))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, "Request with [REDACTED]", entries[0].Message)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "[REDACTED]", fields["token"])
+	assert.Equal(t, "bad token [REDACTED]", fields["error"])
+}
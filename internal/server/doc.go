@@ -0,0 +1,7 @@
+// Package server provides the REST API of the serve command.
+//
+// Download jobs are submitted as lists of URLs, queued, and run one at a time
+// by the download service. Every job keeps its own log and, once finished,
+// the summary printed by --json, so the downloads can be driven remotely
+// (e.g., from a phone when zvuk-grabber runs on a NAS).
+package server
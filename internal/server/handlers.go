@@ -0,0 +1,289 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// maxRequestBodySize is the maximum size of a job submission.
+const maxRequestBodySize = 1 << 20
+
+// submitJobRequest is the body of a job submission.
+type submitJobRequest struct {
+	// URLs are the URLs or IDs to download, like the arguments of the root command.
+	URLs []string `json:"urls"`
+}
+
+// jobListResponse lists the known jobs.
+type jobListResponse struct {
+	// Jobs are the known jobs in submission order.
+	Jobs []*JobInfo `json:"jobs"`
+}
+
+// errorResponse is the body of a failed request.
+type errorResponse struct {
+	// Error describes what went wrong.
+	Error string `json:"error"`
+}
+
+// Handler returns the HTTP handler of the REST API:
+//
+//	POST   /jobs              submit {"urls": [...]}
+//	GET    /jobs              list the jobs
+//	GET    /jobs/{id}         status and live counters of a job
+//	DELETE /jobs/{id}         cancel a queued job or interrupt the running one
+//	GET    /jobs/{id}/logs    stream the log of a job until it finishes
+//	GET    /jobs/{id}/summary summary of a finished job, as printed by --json
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /jobs", s.handleListJobs)
+	mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("DELETE /jobs/{id}", s.handleCancelJob)
+	mux.HandleFunc("GET /jobs/{id}/logs", s.handleJobLogs)
+	mux.HandleFunc("GET /jobs/{id}/summary", s.handleJobSummary)
+
+	return s.requireToken(rejectForeignOrigins(mux))
+}
+
+// rejectForeignOrigins rejects the requests sent by web pages of other sites,
+// so a page opened in the browser cannot start downloads through the API listening on this computer.
+func rejectForeignOrigins(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			originURL, err := url.Parse(origin)
+			if err != nil || !strings.EqualFold(originURL.Host, r.Host) {
+				writeError(w, http.StatusForbidden, ErrForeignOrigin)
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects the requests without the bearer token when serve_token is set.
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	if s.cfg.ServeToken == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + s.cfg.ServeToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleSubmitJob queues a job downloading the URLs of the request.
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	// A JSON body cannot be sent by a web page without a CORS preflight, which the API never allows.
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, ErrUnsupportedContentType)
+
+		return
+	}
+
+	var request submitJobRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	urls := make([]string, 0, len(request.URLs))
+
+	for _, value := range request.URLs {
+		if value = strings.TrimSpace(value); value != "" {
+			urls = append(urls, value)
+		}
+	}
+
+	j, err := s.submit(urls)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+
+		return
+	}
+
+	logger.Infof(r.Context(), "Job %s queued: %d URL(s)", j.id, len(urls))
+
+	w.Header().Set("Location", "/jobs/"+j.id)
+	s.writeJob(w, r, http.StatusAccepted, j)
+}
+
+// handleListJobs lists the known jobs.
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+
+	response := &jobListResponse{Jobs: make([]*JobInfo, 0, len(s.jobIDs))}
+	for _, id := range s.jobIDs {
+		response.Jobs = append(response.Jobs, s.jobs[id].info(r.Context()))
+	}
+
+	s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleGetJob describes a job.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	j, err := s.findJob(r.PathValue("id"))
+	s.mutex.Unlock()
+
+	if err != nil {
+		writeError(w, statusOf(err), err)
+
+		return
+	}
+
+	s.writeJob(w, r, http.StatusOK, j)
+}
+
+// handleCancelJob cancels a queued job or interrupts the running one.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	j, err := s.cancelJob(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+
+		return
+	}
+
+	logger.Infof(r.Context(), "Job %s canceled", j.id)
+
+	s.writeJob(w, r, http.StatusAccepted, j)
+}
+
+// handleJobLogs streams the log of a job, following it until the job finishes or the client disconnects.
+func (s *Server) handleJobLogs(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	j, err := s.findJob(r.PathValue("id"))
+	s.mutex.Unlock()
+
+	if err != nil {
+		writeError(w, statusOf(err), err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+
+	for offset := 0; ; {
+		chunk, next, changed, isClosed := j.log.readFrom(offset)
+		offset = next
+
+		if len(chunk) > 0 {
+			if _, err = w.Write(chunk); err != nil {
+				return
+			}
+
+			// Flushing is best-effort, the lines are sent with the next chunk otherwise.
+			_ = controller.Flush() //nolint:errcheck // See above.
+		}
+
+		if isClosed {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// handleJobSummary returns the summary of a finished job.
+func (s *Server) handleJobSummary(w http.ResponseWriter, r *http.Request) {
+	var summary *zvuk_service.JSONSummary
+
+	s.mutex.Lock()
+
+	j, err := s.findJob(r.PathValue("id"))
+
+	switch {
+	case err != nil:
+	case j.summary != nil:
+		summary = j.summary
+	case j.isFinished():
+		err = ErrNoSummary
+	default:
+		err = ErrJobNotFinished
+	}
+
+	s.mutex.Unlock()
+
+	if err != nil {
+		writeError(w, statusOf(err), err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// writeJob writes the description of the job.
+func (s *Server) writeJob(w http.ResponseWriter, r *http.Request, status int, j *job) {
+	s.mutex.Lock()
+	info := j.info(r.Context())
+	s.mutex.Unlock()
+
+	writeJSON(w, status, info)
+}
+
+// statusOf returns the HTTP status of an error of the server.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrJobFinished), errors.Is(err, ErrJobNotFinished), errors.Is(err, ErrNoSummary):
+		return http.StatusConflict
+	case errors.Is(err, ErrQueueFull):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// writeError writes the error as a JSON body.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: logger.Redact(err.Error())})
+}
+
+// writeJSON writes the value as a JSON body.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	_ = encoder.Encode(value) //nolint:errcheck // The client has gone away, nothing to report to.
+}
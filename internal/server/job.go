@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+// Job statuses besides the final ones of the summary (completed, completed_with_errors, interrupted, aborted).
+const (
+	// JobStatusQueued means that the job waits for the jobs submitted before it.
+	JobStatusQueued = "queued"
+	// JobStatusRunning means that the job is downloading.
+	JobStatusRunning = "running"
+	// JobStatusCanceled means that the job was canceled before it started.
+	JobStatusCanceled = "canceled"
+	// JobStatusFailed means that the download service could not be started.
+	JobStatusFailed = "failed"
+)
+
+// job is a list of URLs downloaded by a single run of the download service.
+// Its fields, except log, are protected by the mutex of the server.
+type job struct {
+	// id identifies the job in the API.
+	id string
+	// urls are the URLs to download.
+	urls []string
+	// status is the current status of the job.
+	status string
+	// createdAt is when the job was submitted.
+	createdAt time.Time
+	// startedAt is when the job started (zero while queued).
+	startedAt time.Time
+	// finishedAt is when the job finished (zero until then).
+	finishedAt time.Time
+	// service runs the downloads (nil until the job starts).
+	service zvuk_service.Service
+	// summary is the outcome of the job (nil until it finishes).
+	summary *zvuk_service.JSONSummary
+	// err is why the job failed to start.
+	err error
+	// cancel stops the running job (nil unless it runs).
+	cancel context.CancelFunc
+	// log receives the log of the job.
+	log *jobLog
+}
+
+// JobInfo describes a job in the API responses.
+type JobInfo struct {
+	// ID identifies the job.
+	ID string `json:"id"`
+	// Status is "queued", "running", "canceled", "failed", or the final status of the summary.
+	Status string `json:"status"`
+	// URLs are the URLs to download.
+	URLs []string `json:"urls"`
+	// CreatedAt is when the job was submitted.
+	CreatedAt time.Time `json:"created_at"`
+	// StartedAt is when the job started.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// FinishedAt is when the job finished.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Error is why the job failed to start.
+	Error string `json:"error,omitempty"`
+	// Tracks counts the tracks processed so far.
+	Tracks *zvuk_service.JSONTrackCounts `json:"tracks,omitempty"`
+	// BytesDownloaded is the size of the audio downloaded so far.
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+}
+
+// isFinished reports whether the job will not change anymore.
+func (j *job) isFinished() bool {
+	return j.status != JobStatusQueued && j.status != JobStatusRunning
+}
+
+// info describes the job. The caller must hold the mutex of the server.
+func (j *job) info(ctx context.Context) *JobInfo {
+	info := &JobInfo{
+		ID:        j.id,
+		Status:    j.status,
+		URLs:      j.urls,
+		CreatedAt: j.createdAt,
+	}
+
+	if !j.startedAt.IsZero() {
+		info.StartedAt = &j.startedAt
+	}
+
+	if !j.finishedAt.IsZero() {
+		info.FinishedAt = &j.finishedAt
+	}
+
+	if j.err != nil {
+		info.Error = logger.Redact(j.err.Error())
+	}
+
+	summary := j.summary
+	if summary == nil && j.service != nil {
+		// The counters of a running job are read live.
+		summary = j.service.Summary(ctx)
+	}
+
+	if summary != nil {
+		info.Tracks = summary.Tracks
+		info.BytesDownloaded = summary.BytesDownloaded
+	}
+
+	return info
+}
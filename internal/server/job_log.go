@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxJobLogSize is the number of the latest bytes kept of the log of a job.
+const maxJobLogSize = 1 << 20
+
+// jobLog keeps the latest part of the log of a job in a ring buffer and wakes up the readers following it.
+type jobLog struct {
+	// mutex protects the fields below.
+	mutex sync.Mutex
+	// limit is the maximum number of bytes kept.
+	limit int
+	// buffer keeps the latest bytes of the log, growing up to limit and then wrapping around.
+	buffer []byte
+	// start is the index of the oldest byte in buffer.
+	start int
+	// size is the number of bytes ever written to the log, including the ones dropped from buffer.
+	size int
+	// changed is closed and replaced whenever the log grows or is closed.
+	changed chan struct{}
+	// isClosed indicates that the job has finished and nothing more is written.
+	isClosed bool
+}
+
+// newJobLog creates an empty job log keeping up to limit bytes.
+func newJobLog(limit int) *jobLog {
+	return &jobLog{
+		limit:   limit,
+		changed: make(chan struct{}),
+	}
+}
+
+// Write appends p to the log, dropping the oldest bytes beyond the limit.
+// It implements io.Writer for the job logger.
+func (l *jobLog) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.isClosed {
+		return len(p), nil
+	}
+
+	written := len(p)
+	l.size += written
+
+	if len(p) > l.limit {
+		p = p[len(p)-l.limit:]
+	}
+
+	if free := l.limit - len(l.buffer); free > 0 {
+		appended := min(free, len(p))
+		l.buffer = append(l.buffer, p[:appended]...)
+		p = p[appended:]
+	}
+
+	for len(p) > 0 {
+		copied := copy(l.buffer[l.start:], p)
+		l.start = (l.start + copied) % len(l.buffer)
+		p = p[copied:]
+	}
+
+	l.notify()
+
+	return written, nil
+}
+
+// close marks the log as complete, releasing the readers that follow it.
+func (l *jobLog) close() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.isClosed {
+		return
+	}
+
+	l.isClosed = true
+	l.notify()
+}
+
+// readFrom returns a copy of the log after offset, the offset following it, a channel closed on the next change,
+// and whether the log is complete. When the bytes at offset were dropped, the copy starts
+// at the first whole line kept.
+func (l *jobLog) readFrom(offset int) ([]byte, int, <-chan struct{}, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	first := l.size - len(l.buffer)
+
+	isDropped := offset < first
+	if isDropped {
+		offset = first
+	}
+
+	var chunk []byte
+
+	if offset < l.size {
+		chunk = make([]byte, l.size-offset)
+
+		copied := copy(chunk, l.buffer[(l.start+offset-first)%len(l.buffer):])
+		copy(chunk[copied:], l.buffer)
+	}
+
+	if isDropped {
+		if index := bytes.IndexByte(chunk, '\n'); index >= 0 {
+			chunk = chunk[index+1:]
+		}
+	}
+
+	return chunk, l.size, l.changed, l.isClosed
+}
+
+// notify wakes up the readers waiting for a change. The caller must hold the mutex.
+func (l *jobLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
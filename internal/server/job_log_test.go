@@ -0,0 +1,48 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobLog_Limit verifies that the log keeps only the latest bytes and readers skip to the first whole line kept.
+func TestJobLog_Limit(t *testing.T) {
+	t.Parallel()
+
+	log := newJobLog(16)
+
+	_, err := log.Write([]byte("line 1\nline 2\n"))
+	require.NoError(t, err)
+
+	chunk, offset, _, _ := log.readFrom(0)
+	assert.Equal(t, "line 1\nline 2\n", string(chunk))
+	assert.Equal(t, 14, offset)
+
+	_, err = log.Write([]byte("line 3\n"))
+	require.NoError(t, err)
+
+	// The reader following the log gets only the new line.
+	chunk, offset, _, _ = log.readFrom(offset)
+	assert.Equal(t, "line 3\n", string(chunk))
+	assert.Equal(t, 21, offset)
+
+	// A reader from the start gets the whole lines kept after "line 1" was partly dropped.
+	chunk, _, _, _ = log.readFrom(0)
+	assert.Equal(t, "line 2\nline 3\n", string(chunk))
+
+	_, err = log.Write([]byte("a line longer than the limit\n"))
+	require.NoError(t, err)
+
+	// Nothing whole is left of a line longer than the limit.
+	chunk, offset, _, _ = log.readFrom(0)
+	assert.Empty(t, chunk)
+	assert.Equal(t, 50, offset)
+
+	_, err = log.Write([]byte("line 4\n"))
+	require.NoError(t, err)
+
+	chunk, _, _, _ = log.readFrom(offset)
+	assert.Equal(t, "line 4\n", string(chunk))
+}
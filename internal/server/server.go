@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+)
+
+const (
+	// maxQueuedJobs is the maximum number of jobs waiting to run.
+	maxQueuedJobs = 100
+	// maxFinishedJobs is the number of finished jobs kept with their logs and summaries.
+	maxFinishedJobs = 100
+)
+
+var (
+	// ErrJobNotFound is returned when no job has the requested ID.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when a finished job is canceled.
+	ErrJobFinished = errors.New("job is already finished")
+	// ErrJobNotFinished is returned when the summary of a job is requested before it finishes.
+	ErrJobNotFinished = errors.New("job is not finished yet")
+	// ErrQueueFull is returned when too many jobs are waiting to run.
+	ErrQueueFull = errors.New("too many queued jobs")
+	// ErrNoURLs is returned when a job is submitted without URLs.
+	ErrNoURLs = errors.New("no URLs to download")
+	// ErrNoSummary is returned when the summary of a job that never ran is requested.
+	ErrNoSummary = errors.New("job has no summary: it was canceled or failed to start")
+	// ErrInvalidURL is returned when a job is submitted with something other than a Zvuk URL or identifier.
+	ErrInvalidURL = errors.New("not a Zvuk URL or identifier")
+	// ErrUnauthorized is returned when a request has no valid bearer token.
+	ErrUnauthorized = errors.New("missing or invalid token")
+	// ErrForeignOrigin is returned when a web page of another site sends a request.
+	ErrForeignOrigin = errors.New("requests from other sites are not allowed")
+	// ErrUnsupportedContentType is returned when a job submission is not a JSON document.
+	ErrUnsupportedContentType = errors.New("content type must be application/json")
+)
+
+// ServiceFactory creates the download service of a job.
+type ServiceFactory func(ctx context.Context, cfg *config.Config) (zvuk_service.Service, error)
+
+// Server queues download jobs and runs them one at a time, so they never compete for the output path.
+type Server struct {
+	// cfg is the configuration every job starts from.
+	cfg *config.Config
+	// newService creates the download service of a job.
+	newService ServiceFactory
+	// queue passes the submitted jobs to the worker.
+	queue chan *job
+	// mutex protects the fields below and the fields of the jobs.
+	mutex sync.Mutex
+	// jobs maps the ID of every known job to the job.
+	jobs map[string]*job
+	// jobIDs lists the IDs of the known jobs in submission order.
+	jobIDs []string
+	// lastJobID is the number of the last submitted job.
+	lastJobID int64
+}
+
+// New creates a server running the jobs with the services created by newService.
+func New(cfg *config.Config, newService ServiceFactory) *Server {
+	return &Server{
+		cfg:        cfg,
+		newService: newService,
+		queue:      make(chan *job, maxQueuedJobs),
+		jobs:       make(map[string]*job),
+	}
+}
+
+// Run runs the queued jobs until the context is canceled.
+// The running job is interrupted, and the queued ones are canceled.
+func (s *Server) Run(ctx context.Context) {
+	defer s.cancelQueuedJobs()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			s.runJob(ctx, j)
+		}
+	}
+}
+
+// submit queues a job downloading the URLs.
+// Only URLs and identifiers are accepted: unlike the arguments of the root command, they are never read as files.
+func (s *Server) submit(urls []string) (*job, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoURLs
+	}
+
+	for _, url := range urls {
+		if !zvuk_service.IsDownloadItem(url) {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidURL, url)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastJobID++
+
+	j := &job{
+		id:        strconv.FormatInt(s.lastJobID, 10),
+		urls:      urls,
+		status:    JobStatusQueued,
+		createdAt: time.Now(),
+		log:       newJobLog(maxJobLogSize),
+	}
+
+	select {
+	case s.queue <- j:
+	default:
+		s.lastJobID--
+
+		return nil, fmt.Errorf("%w: %d jobs are waiting", ErrQueueFull, maxQueuedJobs)
+	}
+
+	s.jobs[j.id] = j
+	s.jobIDs = append(s.jobIDs, j.id)
+	s.pruneFinishedJobs()
+
+	return j, nil
+}
+
+// runJob downloads the URLs of the job and keeps its summary.
+func (s *Server) runJob(ctx context.Context, j *job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mutex.Lock()
+	if j.status != JobStatusQueued {
+		// Canceled while queued.
+		s.mutex.Unlock()
+
+		return
+	}
+
+	j.status = JobStatusRunning
+	j.startedAt = time.Now()
+	j.cancel = cancel
+	s.mutex.Unlock()
+
+	defer j.log.close()
+
+	jobCtx = logger.ToContext(jobCtx, logger.NewTee(j.log))
+	logger.Infof(jobCtx, "Job %s started: %d URL(s)", j.id, len(j.urls))
+
+	// Every job gets its own copy: nothing can be asked in the terminal of a service.
+	jobConfig := *s.cfg
+	jobConfig.Interactive = false
+	jobConfig.JSONOutput = false
+
+	service, err := s.newService(jobCtx, &jobConfig)
+	if err != nil {
+		logger.Errorf(jobCtx, "Job %s failed to start: %v", j.id, err)
+		s.finishJob(j, JobStatusFailed, nil, err)
+
+		return
+	}
+
+	s.mutex.Lock()
+	j.service = service
+	s.mutex.Unlock()
+
+	downloadURLs(jobCtx, service, j.urls)
+	service.PrintDownloadSummary(jobCtx)
+
+	summary := service.Summary(jobCtx)
+	logger.Infof(jobCtx, "Job %s finished: %s", j.id, summary.Status)
+	s.finishJob(j, summary.Status, summary, nil)
+}
+
+// downloadURLs downloads the URLs, recovering from a panic, so a broken job does not stop the server.
+func downloadURLs(ctx context.Context, service zvuk_service.Service, urls []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(ctx, "Panic recovered: %v", r)
+		}
+	}()
+
+	service.DownloadURLs(ctx, urls)
+}
+
+// finishJob records the outcome of the job.
+func (s *Server) finishJob(j *job, status string, summary *zvuk_service.JSONSummary, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j.status = status
+	j.summary = summary
+	j.err = err
+	j.finishedAt = time.Now()
+	j.cancel = nil
+}
+
+// cancelJob cancels a queued job or interrupts the running one.
+func (s *Server) cancelJob(id string) (*job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, err := s.findJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case j.status == JobStatusQueued:
+		j.status = JobStatusCanceled
+		j.finishedAt = time.Now()
+		j.log.close()
+	case j.cancel != nil:
+		j.cancel()
+	case j.isFinished():
+		return nil, ErrJobFinished
+	}
+
+	return j, nil
+}
+
+// cancelQueuedJobs cancels the jobs that have not started.
+func (s *Server) cancelQueuedJobs() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, j := range s.jobs {
+		if j.status == JobStatusQueued {
+			j.status = JobStatusCanceled
+			j.finishedAt = time.Now()
+			j.log.close()
+		}
+	}
+}
+
+// pruneFinishedJobs forgets the oldest finished jobs beyond maxFinishedJobs.
+// The caller must hold the mutex.
+func (s *Server) pruneFinishedJobs() {
+	finishedCount := 0
+
+	for _, id := range s.jobIDs {
+		if s.jobs[id].isFinished() {
+			finishedCount++
+		}
+	}
+
+	kept := s.jobIDs[:0]
+
+	for _, id := range s.jobIDs {
+		if finishedCount > maxFinishedJobs && s.jobs[id].isFinished() {
+			delete(s.jobs, id)
+
+			finishedCount--
+
+			continue
+		}
+
+		kept = append(kept, id)
+	}
+
+	s.jobIDs = kept
+}
+
+// findJob returns the job with the ID.
+// The caller must hold the mutex.
+func (s *Server) findJob(id string) (*job, error) {
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	return j, nil
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
+	mock_zvuk_service "github.com/oshokin/zvuk-grabber/internal/service/zvuk/mocks"
+)
+
+// testWaitTime is how long the tests wait for a job to change its status.
+const testWaitTime = 5 * time.Second
+
+// newTestServer starts a server running the jobs with the service and returns its address.
+func newTestServer(t *testing.T, token string, service zvuk_service.Service) string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := New(&config.Config{ServeToken: token},
+		func(_ context.Context, _ *config.Config) (zvuk_service.Service, error) {
+			return service, nil
+		})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		server.Run(ctx)
+	}()
+
+	httpServer := httptest.NewServer(server.Handler())
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		httpServer.Close()
+	})
+
+	return httpServer.URL
+}
+
+// doRequest sends an API request and decodes the JSON response into result, if it is not nil.
+func doRequest(t *testing.T, method, url, token, body string, result any) int {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	if result != nil {
+		require.NoError(t, json.NewDecoder(response.Body).Decode(result))
+	}
+
+	return response.StatusCode
+}
+
+// waitForStatus waits until the job has the status.
+func waitForStatus(t *testing.T, baseURL, id, status string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		var info JobInfo
+
+		doRequest(t, http.MethodGet, baseURL+"/jobs/"+id, "", "", &info)
+
+		return info.Status == status
+	}, testWaitTime, 10*time.Millisecond, "job %s never became %s", id, status)
+}
+
+// TestServer_JobLifecycle tests that a submitted job runs and keeps its summary.
+func TestServer_JobLifecycle(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	service := mock_zvuk_service.NewMockService(ctrl)
+
+	summary := &zvuk_service.JSONSummary{
+		Status:          zvuk_service.JSONSummaryStatusCompleted,
+		Tracks:          &zvuk_service.JSONTrackCounts{Processed: 1, Downloaded: 1},
+		BytesDownloaded: 1000,
+	}
+
+	service.EXPECT().DownloadURLs(gomock.Any(), []string{"https://zvuk.com/track/1"})
+	service.EXPECT().PrintDownloadSummary(gomock.Any())
+	service.EXPECT().Summary(gomock.Any()).Return(summary).AnyTimes()
+
+	baseURL := newTestServer(t, "", service)
+
+	var submitted JobInfo
+
+	status := doRequest(t, http.MethodPost, baseURL+"/jobs", "",
+		`{"urls": [" https://zvuk.com/track/1 ", ""]}`, &submitted)
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "1", submitted.ID)
+	assert.Equal(t, []string{"https://zvuk.com/track/1"}, submitted.URLs)
+
+	waitForStatus(t, baseURL, submitted.ID, zvuk_service.JSONSummaryStatusCompleted)
+
+	var result zvuk_service.JSONSummary
+
+	status = doRequest(t, http.MethodGet, baseURL+"/jobs/1/summary", "", "", &result)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(1000), result.BytesDownloaded)
+
+	var list jobListResponse
+
+	status = doRequest(t, http.MethodGet, baseURL+"/jobs", "", "", &list)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, int64(1), list.Jobs[0].Tracks.Downloaded)
+
+	// The log of a finished job is complete, so streaming it ends.
+	response, err := http.Get(baseURL + "/jobs/1/logs") //nolint:noctx // A test request.
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	logs, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(logs), "Job 1 finished: completed")
+
+	status = doRequest(t, http.MethodDelete, baseURL+"/jobs/1", "", "", nil)
+	assert.Equal(t, http.StatusConflict, status)
+}
+
+// TestServer_CancelJobs tests that a queued job is canceled and the running one is interrupted.
+func TestServer_CancelJobs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	service := mock_zvuk_service.NewMockService(ctrl)
+
+	service.EXPECT().DownloadURLs(gomock.Any(), []string{"1"}).Do(func(ctx context.Context, _ []string) {
+		<-ctx.Done()
+	})
+	service.EXPECT().PrintDownloadSummary(gomock.Any())
+	service.EXPECT().Summary(gomock.Any()).DoAndReturn(func(ctx context.Context) *zvuk_service.JSONSummary {
+		if ctx.Err() != nil {
+			return &zvuk_service.JSONSummary{Status: zvuk_service.JSONSummaryStatusInterrupted}
+		}
+
+		return &zvuk_service.JSONSummary{Status: zvuk_service.JSONSummaryStatusCompleted}
+	}).AnyTimes()
+
+	baseURL := newTestServer(t, "", service)
+
+	require.Equal(t, http.StatusAccepted, doRequest(t, http.MethodPost, baseURL+"/jobs", "", `{"urls": ["1"]}`, nil))
+	require.Equal(t, http.StatusAccepted, doRequest(t, http.MethodPost, baseURL+"/jobs", "", `{"urls": ["2"]}`, nil))
+
+	waitForStatus(t, baseURL, "1", JobStatusRunning)
+
+	status := doRequest(t, http.MethodGet, baseURL+"/jobs/1/summary", "", "", nil)
+	assert.Equal(t, http.StatusConflict, status)
+
+	var canceled JobInfo
+
+	status = doRequest(t, http.MethodDelete, baseURL+"/jobs/2", "", "", &canceled)
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, JobStatusCanceled, canceled.Status)
+
+	status = doRequest(t, http.MethodGet, baseURL+"/jobs/2/summary", "", "", nil)
+	assert.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, http.MethodDelete, baseURL+"/jobs/1", "", "", nil)
+	require.Equal(t, http.StatusAccepted, status)
+
+	waitForStatus(t, baseURL, "1", zvuk_service.JSONSummaryStatusInterrupted)
+}
+
+// TestServer_Errors tests the responses to invalid requests.
+func TestServer_Errors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	baseURL := newTestServer(t, "secret", mock_zvuk_service.NewMockService(ctrl))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "missing token",
+			method:         http.MethodGet,
+			path:           "/jobs",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong token",
+			method:         http.MethodGet,
+			path:           "/jobs",
+			token:          "wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid token",
+			method:         http.MethodGet,
+			path:           "/jobs",
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown job",
+			method:         http.MethodGet,
+			path:           "/jobs/42",
+			token:          "secret",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "summary of an unknown job",
+			method:         http.MethodGet,
+			path:           "/jobs/42/summary",
+			token:          "secret",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "no URLs",
+			method:         http.MethodPost,
+			path:           "/jobs",
+			token:          "secret",
+			body:           `{"urls": [" "]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "URL list file",
+			method:         http.MethodPost,
+			path:           "/jobs",
+			token:          "secret",
+			body:           `{"urls": ["/etc/passwords.txt"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not a Zvuk URL",
+			method:         http.MethodPost,
+			path:           "/jobs",
+			token:          "secret",
+			body:           `{"urls": ["1", "https://zvuk.com/profile"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown field",
+			method:         http.MethodPost,
+			path:           "/jobs",
+			token:          "secret",
+			body:           `{"links": ["1"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var response errorResponse
+
+			status := doRequest(t, tt.method, baseURL+tt.path, tt.token, tt.body, &response)
+			assert.Equal(t, tt.expectedStatus, status)
+
+			if tt.expectedStatus != http.StatusOK {
+				assert.NotEmpty(t, response.Error)
+			}
+		})
+	}
+}
+
+// TestServer_CrossSiteRequests tests that web pages of other sites cannot use the API without a token.
+func TestServer_CrossSiteRequests(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	baseURL := newTestServer(t, "", mock_zvuk_service.NewMockService(ctrl))
+
+	tests := []struct {
+		name           string
+		method         string
+		contentType    string
+		origin         string
+		expectedStatus int
+	}{
+		{
+			name:           "form submission",
+			method:         http.MethodPost,
+			contentType:    "application/x-www-form-urlencoded",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "plain text submission",
+			method:         http.MethodPost,
+			contentType:    "text/plain",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "foreign origin",
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			origin:         "https://example.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "foreign origin reading the jobs",
+			method:         http.MethodGet,
+			origin:         "https://example.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "same origin",
+			method:         http.MethodGet,
+			origin:         baseURL,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequestWithContext(context.Background(), tt.method, baseURL+"/jobs",
+				strings.NewReader(`{"urls": ["1"]}`))
+			require.NoError(t, err)
+
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+
+			if tt.origin != "" {
+				request.Header.Set("Origin", tt.origin)
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+
+			defer response.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, response.StatusCode)
+		})
+	}
+}
@@ -3,16 +3,25 @@ package auth
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/stealth"
 
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
 
+// FindBrowser returns the path of the installed Chrome or Chromium used to log in.
+// When none is found, login downloads Chromium on its first run.
+func FindBrowser() (string, bool) {
+	return launcher.LookPath()
+}
+
 // initBrowser initializes the rod browser instance.
 func (s *ServiceImpl) initBrowser(ctx context.Context) error {
 	logger.Debug(ctx, "Initializing browser")
@@ -31,34 +40,26 @@ func (s *ServiceImpl) initBrowser(ctx context.Context) error {
 	// Store tempDir for cleanup.
 	s.tempDir = tempDir
 
-	// Try to find existing Chrome installation first.
-	chromePath, exists := launcher.LookPath()
-
-	var launcherURL string
+	browserLauncher := launcher.New().
+		// User needs to see the browser to log in.
+		Headless(false).
+		// Use temporary directory for incognito-like behavior.
+		UserDataDir(tempDir)
 
-	if exists {
+	// Try to find existing Chrome installation first.
+	if chromePath, exists := FindBrowser(); exists {
 		// Use system Chrome if available.
 		logger.Debugf(ctx, "Using system Chrome installation at: %s", chromePath)
-		launcherURL = launcher.New().
-			// User needs to see the browser to log in.
-			Headless(false).
-			// Use temporary directory for incognito-like behavior.
-			UserDataDir(tempDir).
-			// Use system Chrome.
-			Bin(chromePath).
-			MustLaunch()
+		browserLauncher = browserLauncher.Bin(chromePath)
 	} else {
 		// Fall back to downloading Chromium.
 		logger.Debug(ctx, "System Chrome not found, downloading Chromium")
-
-		launcherURL = launcher.New().
-			// User needs to see the browser to log in.
-			Headless(false).
-			// Use temporary directory for incognito-like behavior.
-			UserDataDir(tempDir).
-			MustLaunch()
 	}
 
+	s.setBrowserProxy(ctx, browserLauncher)
+
+	launcherURL := browserLauncher.MustLaunch()
+
 	logger.Debugf(ctx, "Browser launched at: %s", launcherURL)
 	logger.Debugf(ctx, "User data directory: %s", tempDir)
 
@@ -87,6 +88,44 @@ func (s *ServiceImpl) initBrowser(ctx context.Context) error {
 	return nil
 }
 
+// setBrowserProxy makes the browser go through proxy_url, except for the hosts of no_proxy.
+// Chrome takes no credentials on the command line, so it asks for them itself.
+func (s *ServiceImpl) setBrowserProxy(ctx context.Context, browserLauncher *launcher.Launcher) {
+	proxyServer, bypassList := browserProxyFlags(s.cfg.ProxyURL, s.cfg.NoProxy)
+	if proxyServer == "" {
+		return
+	}
+
+	logger.Infof(ctx, "Browser goes through proxy %s", proxyServer)
+
+	browserLauncher.Proxy(proxyServer)
+
+	if bypassList != "" {
+		browserLauncher.Set(flags.Flag("proxy-bypass-list"), bypassList)
+	}
+}
+
+// browserProxyFlags returns the --proxy-server and --proxy-bypass-list values of Chrome for the proxy URL,
+// both empty without a proxy. The credentials of the URL are left out, and socks5h becomes socks5,
+// since Chrome resolves host names through SOCKS5 proxies anyway.
+func browserProxyFlags(proxyURL string, noProxy []string) (string, string) {
+	if proxyURL == "" {
+		return "", ""
+	}
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return "", ""
+	}
+
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if scheme == "socks5h" {
+		scheme = "socks5"
+	}
+
+	return scheme + "://" + parsedURL.Host, strings.Join(noProxy, ";")
+}
+
 // isBrowserAlive checks if the browser is still running.
 func (s *ServiceImpl) isBrowserAlive(ctx context.Context) bool {
 	defer func() {
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	http_transport "github.com/oshokin/zvuk-grabber/internal/transport/http"
+)
+
+// ErrChallengeTimeout is returned when an anti-bot challenge is not passed in time.
+var ErrChallengeTimeout = errors.New("anti-bot challenge was not passed in time")
+
+// SolveChallenge opens the site of the challenge URL in a browser, waits until the anti-bot page
+// is replaced by the site itself (the user may have to solve a captcha), and returns the site cookies.
+// The browser uses the same User-Agent as the HTTP client, because challenge cookies are bound to it.
+func (s *ServiceImpl) SolveChallenge(ctx context.Context, challengeURL *url.URL) ([]*http.Cookie, error) {
+	if err := s.initBrowser(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize browser: %w", err)
+	}
+
+	defer s.cleanup(ctx)
+
+	err := s.page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent: http_transport.DefaultUserAgent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set user agent: %w", err)
+	}
+
+	siteURL := (&url.URL{Scheme: challengeURL.Scheme, Host: challengeURL.Host, Path: "/"}).String()
+
+	logger.Infof(ctx, "Opening %s, complete the check in the browser if it asks you to", siteURL)
+
+	if err = s.page.Navigate(siteURL); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", siteURL, err)
+	}
+
+	if err = s.waitForChallengePassed(ctx); err != nil {
+		s.saveDiagnostics(ctx, diagnosticsFlowChallenge, err)
+
+		return nil, err
+	}
+
+	// Let the site set the rest of its cookies.
+	time.Sleep(sessionEstablishDelay)
+
+	browserCookies, err := s.page.Cookies([]string{siteURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browser cookies: %w", err)
+	}
+
+	result := make([]*http.Cookie, 0, len(browserCookies))
+
+	for _, cookie := range browserCookies {
+		// The auth cookie comes from the configuration, never from the challenge browser.
+		if cookie.Name == authCookieName {
+			continue
+		}
+
+		httpCookie := &http.Cookie{
+			Name:  cookie.Name,
+			Value: cookie.Value,
+			Path:  cookie.Path,
+		}
+
+		// Session cookies are reported with a negative expiration time; keep them without one.
+		if !cookie.Session && cookie.Expires > 0 {
+			httpCookie.Expires = cookie.Expires.Time()
+		}
+
+		result = append(result, httpCookie)
+	}
+
+	logger.Debugf(ctx, "Collected %d cookies after the anti-bot challenge", len(result))
+
+	return result, nil
+}
+
+// waitForChallengePassed polls the page until it no longer looks like an anti-bot challenge.
+func (s *ServiceImpl) waitForChallengePassed(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, http_transport.MaxChallengeWaitTime)
+	defer cancel()
+
+	ticker := time.NewTicker(loginPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrChallengeTimeout
+			}
+
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if !s.isBrowserAlive(ctx) {
+			return ErrBrowserClosed
+		}
+
+		html, err := s.page.HTML()
+		if err != nil || len(html) < minPageHTMLLength {
+			continue
+		}
+
+		if !http_transport.IsChallengePage([]byte(html)) {
+			return nil
+		}
+	}
+}
@@ -14,7 +14,8 @@ func (s *ServiceImpl) getAuthCookie(ctx context.Context) string {
 		}
 	}()
 
-	cookies, err := s.page.Cookies([]string{s.cfg.ZvukBaseURL})
+	// The browser always logs in on zvuk.com, whatever zvuk_base_url points to.
+	cookies, err := s.page.Cookies([]string{zvukHomeURL})
 	if err != nil {
 		return ""
 	}
@@ -47,6 +48,8 @@ func (s *ServiceImpl) extractTokenFromProfile(ctx context.Context) (string, erro
 		logger.Debug(ctx, "Cookie list:")
 
 		for i, cookie := range cookies {
+			// Cookie values are session secrets, so they are masked in every log message.
+			logger.AddSecret(cookie.Value)
 			logger.Debugf(ctx, "Cookie %d: name=%s, domain=%s, value=%s", i+1, cookie.Name, cookie.Domain, cookie.Value)
 		}
 	}
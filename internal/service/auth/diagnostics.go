@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// diagnosticsFolderPermissions sets the permissions of the diagnostics folders: (rwx------),
+	// because the saved pages may contain personal data.
+	diagnosticsFolderPermissions os.FileMode = 0o700
+
+	// diagnosticsFlowLogin names the diagnostics of the browser login.
+	diagnosticsFlowLogin = "login"
+	// diagnosticsFlowChallenge names the diagnostics of an anti-bot challenge.
+	diagnosticsFlowChallenge = "challenge"
+
+	// diagnosticsTimeLayout is the timestamp layout of the diagnostics folder names.
+	diagnosticsTimeLayout = "2006-01-02_15-04-05"
+
+	// diagnosticsScreenshotFilename is the name of the saved screenshot.
+	diagnosticsScreenshotFilename = "screenshot.png"
+	// diagnosticsHTMLFilename is the name of the saved page HTML.
+	diagnosticsHTMLFilename = "page.html"
+	// diagnosticsInfoFilename is the name of the file describing the failure.
+	diagnosticsInfoFilename = "error.txt"
+)
+
+// pageDiagnostics is what the browser showed when a flow failed.
+type pageDiagnostics struct {
+	// url is the address of the page.
+	url string
+	// screenshot is the PNG screenshot of the page (empty if it could not be taken).
+	screenshot []byte
+	// html is the HTML of the page (empty if it could not be read).
+	html string
+}
+
+// saveDiagnostics captures a screenshot and the HTML of the current page when a browser flow fails,
+// and logs where they are saved, so users can report what the automation saw.
+// Nothing is captured when the user closed the browser or canceled the flow.
+func (s *ServiceImpl) saveDiagnostics(ctx context.Context, flow string, cause error) {
+	if s.page == nil || s.cfg.BrowserDiagnosticsPath == "" ||
+		errors.Is(cause, ErrBrowserClosed) || errors.Is(cause, context.Canceled) || !s.isBrowserAlive(ctx) {
+		return
+	}
+
+	diagnostics := s.capturePage(ctx)
+
+	folderPath, err := writeDiagnostics(s.cfg.BrowserDiagnosticsPath, flow, time.Now(), diagnostics, cause)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to save browser diagnostics: %v", err)
+
+		return
+	}
+
+	logger.Errorf(ctx, "Browser diagnostics (screenshot and page HTML) are saved to %s, "+
+		"attach them when reporting the problem, they may contain personal data", folderPath)
+}
+
+// capturePage takes a screenshot and reads the HTML of the current page.
+// Whatever cannot be captured is left empty.
+func (s *ServiceImpl) capturePage(ctx context.Context) (diagnostics *pageDiagnostics) {
+	diagnostics = new(pageDiagnostics)
+
+	defer func() {
+		// Browser or page was closed while capturing, keep what was captured.
+		if r := recover(); r != nil {
+			logger.Debugf(ctx, "capturePage panic recovered: %v", r)
+		}
+	}()
+
+	diagnostics.url, _ = s.getCurrentURL(ctx)
+
+	screenshot, err := s.page.Screenshot(true, nil)
+	if err != nil {
+		logger.Debugf(ctx, "Failed to take a screenshot: %v", err)
+	} else {
+		diagnostics.screenshot = screenshot
+	}
+
+	html, err := s.page.HTML()
+	if err != nil {
+		logger.Debugf(ctx, "Failed to read the page HTML: %v", err)
+	} else {
+		diagnostics.html = html
+	}
+
+	return diagnostics
+}
+
+// writeDiagnostics saves the captured page into a new timestamped folder of the diagnostics directory
+// and returns the path of the folder. Known secrets are masked in the saved text.
+func writeDiagnostics(
+	diagnosticsPath string,
+	flow string,
+	failedAt time.Time,
+	diagnostics *pageDiagnostics,
+	cause error,
+) (string, error) {
+	folderPath := filepath.Join(diagnosticsPath, flow+"-"+failedAt.Format(diagnosticsTimeLayout))
+
+	if err := os.MkdirAll(folderPath, diagnosticsFolderPermissions); err != nil {
+		return "", fmt.Errorf("failed to create diagnostics folder: %w", err)
+	}
+
+	info := fmt.Sprintf("Flow: %s\nTime: %s\nURL: %s\nError: %v\n",
+		flow, failedAt.Format(time.RFC3339), diagnostics.url, cause)
+
+	files := map[string][]byte{
+		diagnosticsInfoFilename: []byte(logger.Redact(info)),
+	}
+
+	if len(diagnostics.screenshot) > 0 {
+		files[diagnosticsScreenshotFilename] = diagnostics.screenshot
+	}
+
+	if diagnostics.html != "" {
+		files[diagnosticsHTMLFilename] = []byte(logger.Redact(diagnostics.html))
+	}
+
+	for filename, content := range files {
+		err := os.WriteFile(filepath.Join(folderPath, filename), content, constants.DefaultFilePermissions)
+		if err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	return folderPath, nil
+}
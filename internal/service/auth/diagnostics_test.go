@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// TestWriteDiagnostics tests that the captured page is saved into a timestamped folder with secrets masked.
+func TestWriteDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	diagnosticsPath := t.TempDir()
+	failedAt := time.Date(2026, time.March, 1, 12, 30, 45, 0, time.UTC)
+
+	logger.AddSecret("diagnostics-secret-token")
+
+	folderPath, err := writeDiagnostics(diagnosticsPath, diagnosticsFlowLogin, failedAt, &pageDiagnostics{
+		url:        "https://id.zvuk.com/desktop",
+		screenshot: []byte("png"),
+		html:       `<html><body data-token="diagnostics-secret-token">Войти</body></html>`,
+	}, ErrLoginTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(diagnosticsPath, "login-2026-03-01_12-30-45"), folderPath)
+
+	screenshot, err := os.ReadFile(filepath.Join(folderPath, diagnosticsScreenshotFilename))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), screenshot)
+
+	html, err := os.ReadFile(filepath.Join(folderPath, diagnosticsHTMLFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Войти")
+	assert.NotContains(t, string(html), "diagnostics-secret-token")
+
+	info, err := os.ReadFile(filepath.Join(folderPath, diagnosticsInfoFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(info), "URL: https://id.zvuk.com/desktop")
+	assert.Contains(t, string(info), "Error: "+ErrLoginTimeout.Error())
+}
+
+// TestWriteDiagnostics_NothingCaptured tests that a failure is described even when the page could not be captured.
+func TestWriteDiagnostics_NothingCaptured(t *testing.T) {
+	t.Parallel()
+
+	folderPath, err := writeDiagnostics(t.TempDir(), diagnosticsFlowChallenge, time.Now(), new(pageDiagnostics),
+		ErrChallengeTimeout)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(folderPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, diagnosticsInfoFilename, entries[0].Name())
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"strings"
@@ -11,8 +12,8 @@ import (
 )
 
 // waitForUserLogin navigates to the dedicated login page and waits for successful authentication.
-//
-//nolint:funlen // Login instructions require many log statements and monitoring logic.
+// With a login phone number, the login form is filled in automatically,
+// and the manual instructions are printed only if that fails.
 func (s *ServiceImpl) waitForUserLogin(ctx context.Context) (string, error) {
 	logger.Info(ctx, "Opening Zvuk homepage...")
 
@@ -33,6 +34,33 @@ func (s *ServiceImpl) waitForUserLogin(ctx context.Context) (string, error) {
 	currentURL := s.page.MustInfo().URL
 	logger.Debugf(ctx, "Navigation complete. Current URL: %s", currentURL)
 
+	if s.loginPhone == "" {
+		printLoginInstructions(ctx)
+	} else if err := s.fillPhoneLogin(ctx); err != nil {
+		if errors.Is(err, ErrBrowserClosed) || ctx.Err() != nil {
+			return "", err
+		}
+
+		logger.Warnf(ctx, "Failed to fill in the login form automatically: %v", err)
+		printLoginInstructions(ctx)
+	}
+
+	// Wait for login by monitoring the process.
+	token, err := s.waitForLoginComplete(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info(ctx, "Login completed successfully!")
+
+	// Give the session a moment to fully establish.
+	time.Sleep(sessionEstablishDelay)
+
+	return token, nil
+}
+
+// printLoginInstructions explains how to complete the login in the browser.
+func printLoginInstructions(ctx context.Context) {
 	logger.Info(ctx, "")
 	logger.Info(ctx, "╔══════════════════════════════════════════════════════════════════╗")
 	logger.Info(ctx, "║                      LOGIN INSTRUCTIONS                          ║")
@@ -62,19 +90,6 @@ func (s *ServiceImpl) waitForUserLogin(ctx context.Context) (string, error) {
 	logger.Info(ctx, "")
 	logger.Info(ctx, "Waiting for login to complete...")
 	logger.Info(ctx, "")
-
-	// Wait for login by monitoring the process.
-	token, err := s.waitForLoginComplete(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	logger.Info(ctx, "Login completed successfully!")
-
-	// Give the session a moment to fully establish.
-	time.Sleep(sessionEstablishDelay)
-
-	return token, nil
 }
 
 // waitForLoginComplete monitors login process and validates success by checking for avatar button.
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// phoneFormWaitTime is the maximum time to wait for the phone number field of the login form.
+	phoneFormWaitTime = 30 * time.Second
+	// smsCodeFormWaitTime is the maximum time to wait for the SMS code field after the phone number is sent.
+	// It leaves time to pass an anti-bot check shown in between.
+	smsCodeFormWaitTime = 2 * time.Minute
+
+	// phoneInputSelector is the CSS selector for the phone number field of the login form.
+	phoneInputSelector = `input[type="tel"], input[autocomplete="tel"], input[name="phone" i]`
+	// smsCodeInputSelector is the CSS selector for the SMS code field of the login form.
+	smsCodeInputSelector = `input[autocomplete="one-time-code"], input[name*="otp" i], input[name*="code" i]`
+
+	// minPhoneNumberDigits is the minimum number of digits in a phone number with the country code.
+	minPhoneNumberDigits = 10
+	// maxPhoneNumberDigits is the maximum number of digits in a phone number (E.164).
+	maxPhoneNumberDigits = 15
+
+	// minSMSCodeLength is the minimum number of digits in an SMS code.
+	minSMSCodeLength = 4
+	// maxSMSCodeLength is the maximum number of digits in an SMS code.
+	maxSMSCodeLength = 8
+
+	// visiblePhoneNumberDigits is the number of trailing digits left visible in a masked phone number.
+	visiblePhoneNumberDigits = 2
+)
+
+var (
+	// ErrInvalidPhoneNumber is returned when the login phone number is not in the international format.
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+	// ErrSMSCodeNotEntered is returned when the input ends before an SMS code is entered.
+	ErrSMSCodeNotEntered = errors.New("SMS code was not entered")
+)
+
+// normalizePhoneNumber removes the spaces, dashes, and parentheses from a phone number
+// and checks that it has a country code and all the digits.
+func normalizePhoneNumber(phone string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')':
+			return -1
+		default:
+			return r
+		}
+	}, strings.TrimSpace(phone))
+
+	digits, hasPlus := strings.CutPrefix(normalized, "+")
+	if !hasPlus || len(digits) < minPhoneNumberDigits || len(digits) > maxPhoneNumberDigits || !isDigits(digits) {
+		return "", fmt.Errorf("%w '%s': expected the country code and all digits, e.g., +71488251742",
+			ErrInvalidPhoneNumber, phone)
+	}
+
+	return normalized, nil
+}
+
+// maskPhoneNumber hides all but the country code prefix and the last digits of a phone number.
+func maskPhoneNumber(phone string) string {
+	// The plus sign and the first digit of the country code stay visible.
+	const visiblePrefixLength = 2
+
+	if len(phone) <= visiblePrefixLength+visiblePhoneNumberDigits {
+		return phone
+	}
+
+	hiddenLength := len(phone) - visiblePrefixLength - visiblePhoneNumberDigits
+
+	return phone[:visiblePrefixLength] + strings.Repeat("*", hiddenLength) + phone[len(phone)-visiblePhoneNumberDigits:]
+}
+
+// fillPhoneLogin fills in the phone number in the login form, asks for the SMS code in the terminal,
+// and fills it in too. The login is completed by waitForLoginComplete, like the manual one.
+func (s *ServiceImpl) fillPhoneLogin(ctx context.Context) (err error) {
+	defer func() {
+		// Browser or page was closed while the form was filled in.
+		if r := recover(); r != nil {
+			logger.Debugf(ctx, "fillPhoneLogin panic recovered: %v", r)
+
+			err = ErrBrowserClosed
+		}
+	}()
+
+	maskedPhone := maskPhoneNumber(s.loginPhone)
+
+	logger.Infof(ctx, "Logging in with the phone number %s...", maskedPhone)
+
+	loginButton, err := s.page.Context(ctx).Timeout(phoneFormWaitTime).Element(loginButtonSelector)
+	if err != nil {
+		return fmt.Errorf("failed to find the login button: %w", err)
+	}
+
+	randomHumanDelay()
+
+	if err = loginButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("failed to click the login button: %w", err)
+	}
+
+	phoneInput, err := s.page.Context(ctx).Timeout(phoneFormWaitTime).Element(phoneInputSelector)
+	if err != nil {
+		return fmt.Errorf("failed to find the phone number field: %w", err)
+	}
+
+	randomHumanDelay()
+
+	if err = phoneInput.Input(s.loginPhone); err != nil {
+		return fmt.Errorf("failed to fill in the phone number: %w", err)
+	}
+
+	randomHumanDelay()
+
+	if err = phoneInput.Type(input.Enter); err != nil {
+		return fmt.Errorf("failed to send the phone number: %w", err)
+	}
+
+	logger.Info(ctx, "Phone number sent, waiting for the SMS code field...")
+	logger.Info(ctx, "If the browser shows an anti-bot check, please pass it")
+
+	codeInput, err := s.page.Context(ctx).Timeout(smsCodeFormWaitTime).Element(smsCodeInputSelector)
+	if err != nil {
+		return fmt.Errorf("failed to find the SMS code field: %w", err)
+	}
+
+	code, err := s.promptSMSCode(ctx, maskedPhone)
+	if err != nil {
+		return err
+	}
+
+	if err = codeInput.Input(code); err != nil {
+		return fmt.Errorf("failed to fill in the SMS code: %w", err)
+	}
+
+	logger.Info(ctx, "SMS code sent, waiting for the login to complete...")
+	logger.Info(ctx, "If the code was wrong, please enter it again in the browser")
+
+	return nil
+}
+
+// promptSMSCode asks for the SMS code in the terminal. It gives up when the context is canceled.
+func (s *ServiceImpl) promptSMSCode(ctx context.Context, maskedPhone string) (string, error) {
+	type result struct {
+		code string
+		err  error
+	}
+
+	resultChannel := make(chan result, 1)
+
+	// Reading the terminal cannot be canceled, so it is left running if the context is canceled first.
+	go func() {
+		code, err := readSMSCode(s.codeInput, s.codeOutput, maskedPhone)
+		resultChannel <- result{code: code, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-resultChannel:
+		return r.code, r.err
+	}
+}
+
+// readSMSCode prints the prompt for the SMS code sent to maskedPhone and reads it from r,
+// asking again until the answer is a valid code.
+func readSMSCode(r io.Reader, w io.Writer, maskedPhone string) (string, error) {
+	reader := bufio.NewReader(r)
+
+	for {
+		if _, err := fmt.Fprintf(w, "Enter the SMS code sent to %s: ", maskedPhone); err != nil {
+			return "", err
+		}
+
+		answer, err := reader.ReadString('\n')
+
+		code := strings.ReplaceAll(strings.TrimSpace(answer), " ", "")
+		if isValidSMSCode(code) {
+			return code, nil
+		}
+
+		if err != nil {
+			fmt.Fprintln(w) //nolint:errcheck // The input has ended anyway.
+
+			return "", ErrSMSCodeNotEntered
+		}
+
+		if _, err = fmt.Fprintf(w, "The code must consist of %d to %d digits, try again.\n",
+			minSMSCodeLength, maxSMSCodeLength); err != nil {
+			return "", err
+		}
+	}
+}
+
+// isValidSMSCode reports whether the code consists of digits only and has a valid length.
+func isValidSMSCode(code string) bool {
+	return len(code) >= minSMSCodeLength && len(code) <= maxSMSCodeLength && isDigits(code)
+}
+
+// isDigits reports whether the text consists of ASCII digits only.
+func isDigits(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestNormalizePhoneNumber tests the normalizePhoneNumber function.
+func TestNormalizePhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		phone       string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "international format",
+			phone:    "+71488251742",
+			expected: "+71488251742",
+		},
+		{
+			name:     "spaces, dashes, and parentheses",
+			phone:    " +7 (148) 825-17-42 ",
+			expected: "+71488251742",
+		},
+		{
+			name:        "no country code",
+			phone:       "81488251742",
+			expectError: true,
+		},
+		{
+			name:        "too short",
+			phone:       "+7148825",
+			expectError: true,
+		},
+		{
+			name:        "letters",
+			phone:       "+7148825174a",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			phone, err := normalizePhoneNumber(tt.phone)
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrInvalidPhoneNumber)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, phone)
+		})
+	}
+}
+
+// TestMaskPhoneNumber tests the maskPhoneNumber function.
+func TestMaskPhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "+7********42", maskPhoneNumber("+71488251742"))
+}
+
+// TestReadSMSCode tests that the SMS code is asked for again until a valid one is entered.
+func TestReadSMSCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid answers are asked again", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+
+		code, err := readSMSCode(strings.NewReader("abc\n12\n 123 45 \n"), &output, "+7*******42")
+
+		require.NoError(t, err)
+		assert.Equal(t, "12345", code)
+		assert.Equal(t, 3, strings.Count(output.String(), "Enter the SMS code sent to +7*******42: "))
+		assert.Equal(t, 2, strings.Count(output.String(), "try again"))
+	})
+
+	t.Run("last line without a newline", func(t *testing.T) {
+		t.Parallel()
+
+		code, err := readSMSCode(strings.NewReader("54321"), new(bytes.Buffer), "+7*******42")
+
+		require.NoError(t, err)
+		assert.Equal(t, "54321", code)
+	})
+
+	t.Run("input ends", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readSMSCode(strings.NewReader("12\n"), new(bytes.Buffer), "+7*******42")
+
+		require.ErrorIs(t, err, ErrSMSCodeNotEntered)
+	})
+}
+
+// TestLoginAndExtractToken_InvalidPhoneNumber tests that an invalid phone number fails before the browser starts.
+func TestLoginAndExtractToken_InvalidPhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	service, err := NewService(&config.Config{LoginPhone: "12345"})
+	require.NoError(t, err)
+
+	_, err = service.LoginAndExtractToken(context.Background())
+
+	require.ErrorIs(t, err, ErrInvalidPhoneNumber)
+	assert.Nil(t, service.browser)
+}
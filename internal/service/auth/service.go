@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -125,12 +127,20 @@ type ServiceImpl struct {
 	page    *rod.Page
 	// tempDir stores the temporary profile directory for cleanup.
 	tempDir string
+	// loginPhone is the normalized phone number filled in by the login (empty for the manual login).
+	loginPhone string
+	// codeInput is where the SMS code of the phone login is read from.
+	codeInput io.Reader
+	// codeOutput is where the SMS code of the phone login is asked for.
+	codeOutput io.Writer
 }
 
 // NewService creates a new browser authentication service.
 func NewService(cfg *config.Config) (*ServiceImpl, error) {
 	return &ServiceImpl{
-		cfg: cfg,
+		cfg:        cfg,
+		codeInput:  os.Stdin,
+		codeOutput: os.Stdout,
 	}, nil
 }
 
@@ -138,6 +148,17 @@ func NewService(cfg *config.Config) (*ServiceImpl, error) {
 func (s *ServiceImpl) LoginAndExtractToken(ctx context.Context) (string, error) {
 	logger.Info(ctx, "Starting browser-based authentication")
 
+	// Check the phone number before the browser is started.
+	if s.cfg.LoginPhone != "" {
+		phone, err := normalizePhoneNumber(s.cfg.LoginPhone)
+		if err != nil {
+			return "", err
+		}
+
+		s.loginPhone = phone
+		logger.AddSecret(phone)
+	}
+
 	// Initialize browser.
 	if err := s.initBrowser(ctx); err != nil {
 		return "", fmt.Errorf("failed to initialize browser: %w", err)
@@ -148,6 +169,8 @@ func (s *ServiceImpl) LoginAndExtractToken(ctx context.Context) (string, error)
 	// Navigate to login page and wait for user to complete authentication.
 	directToken, err := s.waitForUserLogin(ctx)
 	if err != nil {
+		s.saveDiagnostics(ctx, diagnosticsFlowLogin, err)
+
 		return "", fmt.Errorf("login failed: %w", err)
 	}
 
@@ -160,6 +183,8 @@ func (s *ServiceImpl) LoginAndExtractToken(ctx context.Context) (string, error)
 	// Extract token from browser cookies.
 	token, err := s.extractTokenFromProfile(ctx)
 	if err != nil {
+		s.saveDiagnostics(ctx, diagnosticsFlowLogin, err)
+
 		return "", fmt.Errorf("failed to extract token: %w", err)
 	}
 
@@ -119,6 +119,8 @@ func (h *AlbumCollectionHandler) FillTags(item *zvuk.Release) map[string]string
 		TagAlbumID:          strconv.FormatInt(item.ID, 10),
 		TagAlbumTitle:       item.Title,
 		TagAlbumTrackCount:  strconv.FormatInt(int64(len(item.TrackIDs)), 10),
+		TagBarcode:          strings.TrimSpace(item.Barcode),
+		TagCatalogNumber:    strings.TrimSpace(item.CatalogNumber),
 		TagReleaseDate:      albumDate,
 		TagReleaseTimestamp: releaseTimestamp,
 		TagReleaseYear:      albumYear,
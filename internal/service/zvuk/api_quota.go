@@ -0,0 +1,120 @@
+package zvuk
+
+import (
+	"context"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// apiRequestsCount returns the number of requests the client has sent during the run.
+func (s *ServiceImpl) apiRequestsCount() int64 {
+	if s.zvukClient == nil {
+		return 0
+	}
+
+	apiStats := s.zvukClient.GetAPIStatistics()
+	if apiStats == nil {
+		return 0
+	}
+
+	return apiStats.TotalRequests()
+}
+
+// isAPIQuotaStopping reports whether reaching max_api_requests stops the run.
+// Dry runs never wait, so they stop even if api_quota_action pauses.
+func (s *ServiceImpl) isAPIQuotaStopping() bool {
+	return s.cfg.MaxAPIRequests > 0 && (s.cfg.APIQuotaAction != config.APIQuotaActionPause || s.cfg.DryRun)
+}
+
+// isAPIQuotaReached reports whether the run has sent max_api_requests requests and must stop.
+// As with the run time limit, the tracks in progress are finished, and the items that were not started
+// are recorded as failed, so the resume command can continue from there.
+func (s *ServiceImpl) isAPIQuotaReached(ctx context.Context) bool {
+	if !s.isAPIQuotaStopping() || s.apiRequestsCount() < s.cfg.MaxAPIRequests {
+		return false
+	}
+
+	if s.isAPIQuotaLogged.CompareAndSwap(false, true) {
+		logger.Warnf(ctx, "API request limit of %d is reached, finishing the tracks in progress and skipping the rest",
+			s.cfg.MaxAPIRequests)
+	}
+
+	return true
+}
+
+// isRunLimitReached reports whether the run has used up max_run_duration or max_api_requests.
+func (s *ServiceImpl) isRunLimitReached(ctx context.Context) bool {
+	return s.isRunTimeLimitReached(ctx) || s.isAPIQuotaReached(ctx)
+}
+
+// runLimitError returns the error recorded for the items left out by the limit that was reached.
+func (s *ServiceImpl) runLimitError() error {
+	if s.isAPIQuotaLogged.Load() {
+		return ErrAPIQuotaReached
+	}
+
+	return ErrRunTimeLimitReached
+}
+
+// waitForAPIQuota blocks once the run has sent max_api_requests requests and api_quota_action pauses it.
+// After api_quota_pause, another max_api_requests requests may be sent.
+// The transfers in progress are finished, and only new tracks wait.
+// It returns false if the context is canceled while waiting.
+func (s *ServiceImpl) waitForAPIQuota(ctx context.Context) bool {
+	if s.cfg.MaxAPIRequests <= 0 || s.isAPIQuotaStopping() {
+		return true
+	}
+
+	for {
+		resumeTime := s.checkAPIQuotaPause(ctx, time.Now())
+		if resumeTime.IsZero() {
+			return true
+		}
+
+		// The pause is checked again after waking up, in case the clock was changed.
+		timer := time.NewTimer(time.Until(resumeTime))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// checkAPIQuotaPause returns when the paused tracks resume, or zero if they may proceed.
+// It starts a pause once max_api_requests requests are sent after the previous one,
+// and logs the pause and the resume once for all the waiting tracks.
+func (s *ServiceImpl) checkAPIQuotaPause(ctx context.Context, now time.Time) time.Time {
+	s.apiQuotaMutex.Lock()
+	defer s.apiQuotaMutex.Unlock()
+
+	if !s.apiQuotaResumeTime.IsZero() {
+		if now.Before(s.apiQuotaResumeTime) {
+			return s.apiQuotaResumeTime
+		}
+
+		// The requests sent before the pause no longer count.
+		s.apiQuotaResumeTime = time.Time{}
+		s.apiQuotaRequestsBefore = s.apiRequestsCount()
+
+		logger.Info(ctx, "API request pause is over, resuming downloads")
+
+		return time.Time{}
+	}
+
+	if s.apiRequestsCount()-s.apiQuotaRequestsBefore < s.cfg.MaxAPIRequests {
+		return time.Time{}
+	}
+
+	s.apiQuotaResumeTime = now.Add(s.cfg.ParsedAPIQuotaPause)
+
+	logger.Infof(ctx, "API request limit of %d is reached, downloads are paused until %s",
+		s.cfg.MaxAPIRequests, s.apiQuotaResumeTime.Format(time.DateTime))
+
+	return s.apiQuotaResumeTime
+}
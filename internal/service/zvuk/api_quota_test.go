@@ -0,0 +1,110 @@
+package zvuk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// newTestAPIQuotaService creates a service whose client reports the given number of requests sent.
+func newTestAPIQuotaService(t *testing.T, cfg *config.Config, requests *int64) *ServiceImpl {
+	t.Helper()
+
+	mockClient := mock_zvuk_client.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().GetAPIStatistics().DoAndReturn(func() *zvuk.APIStatistics {
+		return &zvuk.APIStatistics{Endpoints: map[string]*zvuk.EndpointStatistics{
+			"gql (getTracks)": {Requests: *requests},
+		}}
+	}).AnyTimes()
+
+	cfg.MaxConcurrentDownloads = 1
+
+	impl, ok := NewService(cfg, mockClient, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	return impl
+}
+
+// TestAPIQuota_Stop tests that nothing new is started once max_api_requests is used up,
+// and that the items left out are recorded for the resume command.
+func TestAPIQuota_Stop(t *testing.T) {
+	t.Parallel()
+
+	requests := int64(99)
+	impl := newTestAPIQuotaService(t, &config.Config{
+		MaxAPIRequests: 100,
+		APIQuotaAction: config.APIQuotaActionStop,
+	}, &requests)
+
+	assert.False(t, impl.isRunLimitReached(context.Background()))
+
+	requests = 100
+
+	impl.downloadStandaloneItems(context.Background(), []*DownloadItem{
+		{Category: DownloadCategoryAlbum, ItemID: "100", URL: "https://zvuk.com/release/100"},
+	})
+
+	errs := impl.Statistics().Errors
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0].Error, ErrAPIQuotaReached)
+	assert.Equal(t, []string{"album:100"}, (&ResumeState{Items: buildResumeItems(errs)}).refs())
+}
+
+// TestAPIQuota_Pause tests that the tracks wait for api_quota_pause once max_api_requests is used up,
+// and may send another max_api_requests requests after it.
+func TestAPIQuota_Pause(t *testing.T) {
+	t.Parallel()
+
+	requests := int64(10)
+	impl := newTestAPIQuotaService(t, &config.Config{
+		MaxAPIRequests:      10,
+		APIQuotaAction:      config.APIQuotaActionPause,
+		ParsedAPIQuotaPause: time.Hour,
+	}, &requests)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	assert.False(t, impl.isRunLimitReached(ctx), "the pause action must not stop the run")
+
+	resumeTime := impl.checkAPIQuotaPause(ctx, now)
+	assert.Equal(t, now.Add(time.Hour), resumeTime)
+	assert.Equal(t, resumeTime, impl.checkAPIQuotaPause(ctx, now.Add(time.Minute)))
+
+	// After the pause, the requests sent before it no longer count.
+	assert.True(t, impl.checkAPIQuotaPause(ctx, now.Add(time.Hour)).IsZero())
+
+	requests = 19
+	assert.True(t, impl.checkAPIQuotaPause(ctx, now.Add(time.Hour)).IsZero())
+
+	requests = 20
+	assert.False(t, impl.checkAPIQuotaPause(ctx, now.Add(time.Hour)).IsZero())
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.False(t, impl.waitForAPIQuota(canceledCtx))
+}
+
+// TestAPIQuota_DryRunStops tests that dry runs stop instead of pausing.
+func TestAPIQuota_DryRunStops(t *testing.T) {
+	t.Parallel()
+
+	requests := int64(10)
+	impl := newTestAPIQuotaService(t, &config.Config{
+		MaxAPIRequests: 10,
+		APIQuotaAction: config.APIQuotaActionPause,
+		DryRun:         true,
+	}, &requests)
+
+	assert.True(t, impl.waitForAPIQuota(context.Background()))
+	assert.True(t, impl.isRunLimitReached(context.Background()))
+}
@@ -1,7 +1,9 @@
 package zvuk
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"slices"
 
 	"github.com/oshokin/zvuk-grabber/internal/logger"
@@ -26,6 +28,12 @@ func (s *ServiceImpl) fetchArtistAlbums(ctx context.Context, artistItems []*Down
 		default:
 		}
 
+		if s.isRunLimitReached(ctx) {
+			s.recordItemsNotStarted(artistItems[itemIndex:])
+
+			return result
+		}
+
 		logger.Infof(ctx, "Fetching releases for artist with ID %s (%d out of %d)", v.ItemID, itemIndex+1, artistsCount)
 
 		// Get the list of album IDs for the current artist.
@@ -51,6 +59,19 @@ func (s *ServiceImpl) fetchArtistAlbums(ctx context.Context, artistItems []*Down
 			continue
 		}
 
+		// The discography lists every release, including those saved by earlier runs.
+		s.startArtistDiscography(v.ItemID, albumIDs)
+
+		// Only the releases added since the previous check are downloaded for a watched artist.
+		if albumIDs = s.newArtistReleaseIDs(ctx, v.ItemID, albumIDs); len(albumIDs) == 0 {
+			continue
+		}
+
+		// The releases completed by an interrupted run are not checked again.
+		if albumIDs = s.skipCheckpointedReleases(ctx, v.ItemID, albumIDs); len(albumIDs) == 0 {
+			continue
+		}
+
 		// Generate download-ready items for each album.
 		for _, albumID := range albumIDs {
 			var albumURL string
@@ -73,12 +94,15 @@ func (s *ServiceImpl) fetchArtistAlbums(ctx context.Context, artistItems []*Down
 	return result
 }
 
-// getArtistReleaseIDs fetches all release IDs for a given artist.
+// getArtistReleaseIDs fetches all release IDs for a given artist, without duplicates.
+// With per_artist_limit set, only the newest releases by release date are returned.
 func (s *ServiceImpl) getArtistReleaseIDs(ctx context.Context, artistURL string) ([]string, error) {
 	var (
-		limit       = 50
-		allAlbumIDs []string
-		offset      int
+		limit        = 50
+		releaseLimit = int(s.cfg.PerArtistLimit)
+		allAlbumIDs  []string
+		seen         = make(map[string]struct{})
+		offset       int
 	)
 
 	// Fetch albums in batches until no more are returned.
@@ -100,11 +124,48 @@ func (s *ServiceImpl) getArtistReleaseIDs(ctx context.Context, artistURL string)
 			break
 		}
 
-		// Append the fetched album IDs to the result slice.
-		allAlbumIDs = append(allAlbumIDs, albumIDs...)
+		// Append the fetched album IDs not seen on earlier pages to the result slice.
+		for _, albumID := range albumIDs {
+			if _, ok := seen[albumID]; ok {
+				continue
+			}
+
+			seen[albumID] = struct{}{}
+			allAlbumIDs = append(allAlbumIDs, albumID)
+		}
+
 		offset += limit // Move to the next batch
 	}
 
-	// Remove duplicate album IDs and return the result.
-	return slices.Compact(allAlbumIDs), nil
+	if releaseLimit <= 0 || len(allAlbumIDs) <= releaseLimit {
+		return allAlbumIDs, nil
+	}
+
+	logger.Infof(ctx, "Limiting artist with ID %s to the %d newest releases", artistURL, releaseLimit)
+
+	return s.newestReleaseIDs(ctx, allAlbumIDs, releaseLimit)
+}
+
+// newestReleaseIDs returns the count releases with the latest release dates.
+// Releases with the same date, or without one, keep the order Zvuk lists them in.
+func (s *ServiceImpl) newestReleaseIDs(ctx context.Context, releaseIDs []string, count int) ([]string, error) {
+	response, err := s.zvukClient.GetAlbumsMetadata(ctx, releaseIDs, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release dates: %w", err)
+	}
+
+	releaseDate := func(releaseID string) int64 {
+		if release := response.Releases[releaseID]; release != nil {
+			return release.Date
+		}
+
+		return 0
+	}
+
+	sorted := slices.Clone(releaseIDs)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return cmp.Compare(releaseDate(b), releaseDate(a))
+	})
+
+	return sorted[:count], nil
 }
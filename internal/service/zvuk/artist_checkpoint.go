@@ -0,0 +1,186 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// ArtistCheckpoint is the content of the checkpoint file of an artist download:
+// the releases completed by an interrupted or failed run.
+type ArtistCheckpoint struct {
+	// ArtistID is the unique identifier of the artist.
+	ArtistID string `json:"artist_id"`
+	// UpdatedAt is when the last release was completed.
+	UpdatedAt time.Time `json:"updated_at"`
+	// ReleaseIDs are the IDs of the completed releases, sorted.
+	ReleaseIDs []string `json:"release_ids"`
+}
+
+// artistCheckpoint is the checkpoint of an artist being downloaded during the run.
+type artistCheckpoint struct {
+	// path is the location of the checkpoint file.
+	path string
+	// checkpoint holds the completed releases.
+	checkpoint *ArtistCheckpoint
+	// pendingReleaseIDs are the releases of the artist left to download in the run.
+	pendingReleaseIDs map[string]struct{}
+}
+
+// skipCheckpointedReleases returns the releases of the artist that no earlier run has completed,
+// and registers them so every completed one is written to the checkpoint right away (artist_checkpoints).
+func (s *ServiceImpl) skipCheckpointedReleases(ctx context.Context, artistID string, releaseIDs []string) []string {
+	if !s.cfg.ArtistCheckpoints {
+		return releaseIDs
+	}
+
+	path := filepath.Join(s.cfg.SyncStatePath, "artist-checkpoint-"+artistID+".json")
+
+	checkpoint, err := loadArtistCheckpoint(path, artistID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to load checkpoint of artist %s, downloading every release: %v", artistID, err)
+
+		checkpoint = &ArtistCheckpoint{ArtistID: artistID}
+	}
+
+	ac := &artistCheckpoint{
+		path:              path,
+		checkpoint:        checkpoint,
+		pendingReleaseIDs: make(map[string]struct{}, len(releaseIDs)),
+	}
+
+	result := make([]string, 0, len(releaseIDs))
+
+	for _, releaseID := range releaseIDs {
+		if _, isCompleted := slices.BinarySearch(checkpoint.ReleaseIDs, releaseID); isCompleted {
+			continue
+		}
+
+		ac.pendingReleaseIDs[releaseID] = struct{}{}
+		result = append(result, releaseID)
+	}
+
+	if skippedCount := len(releaseIDs) - len(result); skippedCount > 0 {
+		logger.Infof(ctx, "Skipping %d release(s) of artist with ID %s completed by an earlier run",
+			skippedCount, artistID)
+	}
+
+	s.artistCheckpointsMutex.Lock()
+	defer s.artistCheckpointsMutex.Unlock()
+
+	if s.artistCheckpoints == nil {
+		s.artistCheckpoints = make(map[string]*artistCheckpoint)
+	}
+
+	s.artistCheckpoints[artistID] = ac
+
+	return result
+}
+
+// checkpointRelease records the release as completed in the checkpoints of its artists
+// once it was downloaded without errors. An interrupted release is left for the next run.
+func (s *ServiceImpl) checkpointRelease(ctx context.Context, item *DownloadItem) {
+	if s.cfg.DryRun || item.Category != DownloadCategoryAlbum || ctx.Err() != nil {
+		return
+	}
+
+	s.artistCheckpointsMutex.Lock()
+	defer s.artistCheckpointsMutex.Unlock()
+
+	var isFailed, isChecked bool
+
+	for _, ac := range s.artistCheckpoints {
+		if _, isPending := ac.pendingReleaseIDs[item.ItemID]; !isPending {
+			continue
+		}
+
+		if !isChecked {
+			isFailed = s.hasReleaseErrors(item.ItemID)
+			isChecked = true
+		}
+
+		if isFailed {
+			continue
+		}
+
+		delete(ac.pendingReleaseIDs, item.ItemID)
+
+		ac.checkpoint.ReleaseIDs = append(ac.checkpoint.ReleaseIDs, item.ItemID)
+		slices.Sort(ac.checkpoint.ReleaseIDs)
+		ac.checkpoint.UpdatedAt = time.Now()
+
+		if err := writeJSONAtomically(ac.path, ac.checkpoint); err != nil {
+			logger.Warnf(ctx, "Failed to save checkpoint of artist %s: %v", ac.checkpoint.ArtistID, err)
+		}
+	}
+}
+
+// hasReleaseErrors reports whether the release or any of its tracks failed during the run.
+func (s *ServiceImpl) hasReleaseErrors(releaseID string) bool {
+	for _, downloadErr := range s.stats.snapshot().Errors {
+		if downloadErr.Category == DownloadCategoryAlbum && downloadErr.ItemID == releaseID ||
+			downloadErr.ParentCategory == DownloadCategoryAlbum && downloadErr.ParentID == releaseID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeCompletedArtistCheckpoints deletes the checkpoints of the artists whose every release was completed,
+// so the next download of the artist checks the files again. The checkpoints of interrupted
+// or aborted runs are kept.
+func (s *ServiceImpl) removeCompletedArtistCheckpoints(ctx context.Context) {
+	s.artistCheckpointsMutex.Lock()
+	defer s.artistCheckpointsMutex.Unlock()
+
+	checkpoints := s.artistCheckpoints
+	s.artistCheckpoints = nil
+
+	if s.cfg.DryRun || ctx.Err() != nil || s.AbortReason() != nil {
+		return
+	}
+
+	for artistID, ac := range checkpoints {
+		if len(ac.pendingReleaseIDs) > 0 {
+			logger.Infof(ctx, "Checkpoint of artist %s is kept in '%s': %d release(s) left to download",
+				artistID, ac.path, len(ac.pendingReleaseIDs))
+
+			continue
+		}
+
+		if err := os.Remove(ac.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf(ctx, "Failed to remove checkpoint of artist %s: %v", artistID, err)
+		}
+	}
+}
+
+// loadArtistCheckpoint reads the checkpoint file of an artist. A missing file is an empty checkpoint.
+func loadArtistCheckpoint(path, artistID string) (*ArtistCheckpoint, error) {
+	checkpoint := &ArtistCheckpoint{ArtistID: artistID}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	if err = json.Unmarshal(content, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint '%s': %w", path, err)
+	}
+
+	// The releases are searched, so they must stay sorted even if the file was edited.
+	slices.Sort(checkpoint.ReleaseIDs)
+
+	return checkpoint, nil
+}
@@ -0,0 +1,79 @@
+package zvuk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestArtistCheckpoint tests that the releases completed by an interrupted run are skipped by the next one,
+// and that the checkpoint is removed once every release is completed.
+func TestArtistCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{ArtistCheckpoints: true, SyncStatePath: t.TempDir()}
+	checkpointPath := filepath.Join(cfg.SyncStatePath, "artist-checkpoint-1.json")
+
+	impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"10", "20", "30"}, impl.skipCheckpointedReleases(ctx, "1", []string{"10", "20", "30"}))
+
+	impl.recordError(&DownloadError{
+		Category:       DownloadCategoryTrack,
+		ItemID:         "200",
+		ParentCategory: DownloadCategoryAlbum,
+		ParentID:       "20",
+		Phase:          "downloading file",
+		Error:          assert.AnError,
+	})
+
+	impl.checkpointRelease(ctx, &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "10"})
+	impl.checkpointRelease(ctx, &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "20"})
+
+	// The run is interrupted before the last release is completed.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	impl.checkpointRelease(canceledCtx, &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "30"})
+	impl.removeCompletedArtistCheckpoints(canceledCtx)
+
+	saved, err := loadArtistCheckpoint(checkpointPath, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10"}, saved.ReleaseIDs)
+	assert.False(t, saved.UpdatedAt.IsZero())
+
+	// The next run downloads only what was not completed, and forgets the checkpoint once it is done.
+	next, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	assert.Equal(t, []string{"20", "30"}, next.skipCheckpointedReleases(ctx, "1", []string{"10", "20", "30"}))
+
+	next.checkpointRelease(ctx, &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "20"})
+	next.checkpointRelease(ctx, &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "30"})
+	next.removeCompletedArtistCheckpoints(ctx)
+
+	assert.NoFileExists(t, checkpointPath)
+}
+
+// TestArtistCheckpoint_Disabled tests that every release is downloaded when artist_checkpoints is off.
+func TestArtistCheckpoint_Disabled(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{SyncStatePath: t.TempDir()}
+	require.NoError(t, writeJSONAtomically(filepath.Join(cfg.SyncStatePath, "artist-checkpoint-1.json"),
+		&ArtistCheckpoint{ArtistID: "1", ReleaseIDs: []string{"10"}}))
+
+	impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	assert.Equal(t, []string{"10", "20"}, impl.skipCheckpointedReleases(context.Background(), "1", []string{"10", "20"}))
+	assert.Nil(t, impl.artistCheckpoints)
+}
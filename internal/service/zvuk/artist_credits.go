@@ -0,0 +1,115 @@
+package zvuk
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// featuringPattern matches the "feat." marker in credits like "Artist A feat. Artist B" or "Artist A (ft. Artist B)".
+var featuringPattern = regexp.MustCompile(`(?i)\s+[(\[]?(?:feat\.?|ft\.|featuring)\s+`)
+
+// featuredArtistsSeparatorPattern splits the featured part of a credit into individual artists.
+var featuredArtistsSeparatorPattern = regexp.MustCompile(`\s*,\s*|\s+&\s+`)
+
+// artistCredits contains the artists of a track split into main and featured ones.
+type artistCredits struct {
+	// main contains the artists the track is credited to.
+	main []string
+	// featured contains the artists parsed from "feat." credits.
+	featured []string
+}
+
+// parseArtistCredits splits the API artist list into main and featured artists.
+// Every name may itself be a credit like "Artist A feat. Artist B"; duplicates are removed.
+func parseArtistCredits(names []string) *artistCredits {
+	var (
+		result = new(artistCredits)
+		seen   = make(map[string]struct{}, len(names))
+	)
+
+	add := func(target *[]string, name string) {
+		name = trimUnbalancedClosers(name)
+		if name == "" {
+			return
+		}
+
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return
+		}
+
+		seen[key] = struct{}{}
+		*target = append(*target, name)
+	}
+
+	for _, name := range names {
+		parts := featuringPattern.Split(name, 2)
+		add(&result.main, parts[0])
+
+		if len(parts) < 2 {
+			continue
+		}
+
+		for _, featured := range featuredArtistsSeparatorPattern.Split(parts[1], -1) {
+			add(&result.featured, featured)
+		}
+	}
+
+	return result
+}
+
+// trimUnbalancedClosers removes the brackets left at the end of a name by splitting "Artist (feat. Guest)",
+// keeping the ones that close a bracket of the name itself, e.g., "Kino (band)" or "Artist [UK]".
+func trimUnbalancedClosers(name string) string {
+	name = strings.TrimSpace(name)
+
+	for {
+		var opener, closer string
+
+		switch {
+		case strings.HasSuffix(name, ")"):
+			opener, closer = "(", ")"
+		case strings.HasSuffix(name, "]"):
+			opener, closer = "[", "]"
+		default:
+			return name
+		}
+
+		if strings.Count(name, opener) >= strings.Count(name, closer) {
+			return name
+		}
+
+		name = strings.TrimSpace(strings.TrimSuffix(name, closer))
+	}
+}
+
+// all returns the main artists followed by the featured ones.
+func (c *artistCredits) all() []string {
+	result := make([]string, 0, len(c.main)+len(c.featured))
+	result = append(result, c.main...)
+
+	return append(result, c.featured...)
+}
+
+// formatDisplayArtist combines the track artists into a single display string using the configured style.
+func formatDisplayArtist(names []string, style string) string {
+	switch style {
+	case config.ArtistJoinStyleComma:
+		return strings.Join(parseArtistCredits(names).all(), ", ")
+	case config.ArtistJoinStyleSemicolon:
+		return strings.Join(parseArtistCredits(names).all(), "; ")
+	case config.ArtistJoinStyleFeat:
+		credits := parseArtistCredits(names)
+
+		result := strings.Join(credits.main, ", ")
+		if len(credits.featured) > 0 {
+			result += " feat. " + strings.Join(credits.featured, ", ")
+		}
+
+		return result
+	default:
+		return strings.Join(names, ", ")
+	}
+}
@@ -0,0 +1,59 @@
+package zvuk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestFormatDisplayArtist tests splitting of featured artists and every join style.
+func TestFormatDisplayArtist(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"Artist A feat. Artist B & Artist C", "Artist D", "artist b"}
+
+	tests := []struct {
+		name     string
+		style    string
+		expected string
+	}{
+		{
+			name:     "original",
+			style:    config.ArtistJoinStyleOriginal,
+			expected: "Artist A feat. Artist B & Artist C, Artist D, artist b",
+		},
+		{
+			name:     "comma",
+			style:    config.ArtistJoinStyleComma,
+			expected: "Artist A, Artist D, Artist B, Artist C",
+		},
+		{
+			name:     "semicolon",
+			style:    config.ArtistJoinStyleSemicolon,
+			expected: "Artist A; Artist D; Artist B; Artist C",
+		},
+		{
+			name:     "feat",
+			style:    config.ArtistJoinStyleFeat,
+			expected: "Artist A, Artist D feat. Artist B, Artist C",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, formatDisplayArtist(names, tt.style))
+		})
+	}
+
+	credits := parseArtistCredits([]string{"Artist A (ft. Artist B)", "Simon & Garfunkel"})
+	assert.Equal(t, []string{"Artist A", "Simon & Garfunkel", "Artist B"}, credits.all())
+	assert.Equal(t, "Artist A, Simon & Garfunkel feat. Artist B",
+		formatDisplayArtist([]string{"Artist A (ft. Artist B)", "Simon & Garfunkel"}, config.ArtistJoinStyleFeat))
+
+	credits = parseArtistCredits([]string{"Kino (band)", "Artist [UK] [feat. Guest (band)]", "Host (ft. Guest [UK])"})
+	assert.Equal(t, []string{"Kino (band)", "Artist [UK]", "Host", "Guest (band)", "Guest [UK]"}, credits.all())
+}
@@ -0,0 +1,293 @@
+package zvuk
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// discographyPlaylistSuffix ends the name of an artist discography playlist, after the artist name.
+const discographyPlaylistSuffix = " - Discography" + extensionM3U8
+
+// artistDiscography collects the saved tracks of the releases of an artist downloaded during the run.
+type artistDiscography struct {
+	// artistID is the unique identifier of the artist.
+	artistID string
+	// releaseIDs are every release of the artist, as listed by Zvuk.
+	releaseIDs []string
+	// releases maps the IDs of the saved releases to their tracks.
+	releases map[string]*discographyRelease
+}
+
+// discographyRelease is a release listed in a discography playlist.
+type discographyRelease struct {
+	// release is the release metadata.
+	release *zvuk.Release
+	// folder is the folder the tracks of the release are saved in.
+	folder string
+	// entries are the saved tracks of the release, in its order.
+	entries []*playlistFileEntry
+}
+
+// startArtistDiscography registers every release of the artist, so the discography playlist
+// lists all of them once they are downloaded (write_discography_playlists).
+func (s *ServiceImpl) startArtistDiscography(artistID string, releaseIDs []string) {
+	if !s.cfg.WriteDiscographyPlaylists {
+		return
+	}
+
+	s.discographiesMutex.Lock()
+	defer s.discographiesMutex.Unlock()
+
+	if s.discographies == nil {
+		s.discographies = make(map[string]*artistDiscography)
+	}
+
+	s.discographies[artistID] = &artistDiscography{
+		artistID:   artistID,
+		releaseIDs: releaseIDs,
+		releases:   make(map[string]*discographyRelease),
+	}
+}
+
+// recordDiscographyRelease adds the saved tracks of a downloaded album to the discographies of its artists.
+func (s *ServiceImpl) recordDiscographyRelease(metadata *downloadTracksMetadata) {
+	collection := metadata.audioCollection
+	if !s.cfg.WriteDiscographyPlaylists || collection == nil || collection.category != DownloadCategoryAlbum {
+		return
+	}
+
+	release := metadata.albumsMetadata[collection.id]
+	if release == nil {
+		return
+	}
+
+	entries := savedPlaylistFileEntries(collection.trackIDs, metadata)
+	if len(entries) == 0 {
+		return
+	}
+
+	s.discographiesMutex.Lock()
+	defer s.discographiesMutex.Unlock()
+
+	for _, discography := range s.discographies {
+		if !slices.Contains(discography.releaseIDs, collection.id) {
+			continue
+		}
+
+		discography.releases[collection.id] = &discographyRelease{
+			release: release,
+			folder:  collection.tracksPath,
+			entries: entries,
+		}
+	}
+}
+
+// writeArtistDiscographies writes the discography playlist of every artist downloaded during the run
+// into the folder holding the folders of its releases, listing the tracks in release date order.
+// The releases left out of the run because they were saved earlier (watched artists, artist_checkpoints)
+// are listed from the download history.
+func (s *ServiceImpl) writeArtistDiscographies(ctx context.Context) {
+	s.discographiesMutex.Lock()
+	defer s.discographiesMutex.Unlock()
+
+	for _, artistID := range slices.Sorted(maps.Keys(s.discographies)) {
+		discography := s.discographies[artistID]
+
+		s.addHistoryReleases(ctx, discography)
+
+		if len(discography.releases) == 0 {
+			continue
+		}
+
+		s.writeDiscographyPlaylist(ctx, discography)
+	}
+}
+
+// addHistoryReleases adds the releases of the artist saved by earlier runs, as recorded in the download history.
+func (s *ServiceImpl) addHistoryReleases(ctx context.Context, discography *artistDiscography) {
+	if s.history == nil {
+		return
+	}
+
+	var missingReleaseIDs []string
+
+	for _, releaseID := range discography.releaseIDs {
+		if discography.releases[releaseID] == nil {
+			missingReleaseIDs = append(missingReleaseIDs, releaseID)
+		}
+	}
+
+	if len(missingReleaseIDs) == 0 {
+		return
+	}
+
+	entries, err := readHistoryEntries(ctx, s.history.path)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to read the download history for the discography of artist %s: %v",
+			discography.artistID, err)
+
+		return
+	}
+
+	releaseEntries := historyReleaseEntries(entries, missingReleaseIDs)
+	if len(releaseEntries) == 0 {
+		return
+	}
+
+	batchSize := int(s.cfg.MetadataBatchSize)
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataBatchSize
+	}
+
+	for batch := range slices.Chunk(slices.Sorted(maps.Keys(releaseEntries)), batchSize) {
+		response, err := s.zvukClient.GetAlbumsMetadata(ctx, batch, false)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get the releases of the discography of artist %s: %v",
+				discography.artistID, err)
+
+			return
+		}
+
+		for _, releaseID := range batch {
+			release := response.Releases[releaseID]
+			if release == nil {
+				continue
+			}
+
+			playlistEntries := releaseEntries[releaseID]
+
+			discography.releases[releaseID] = &discographyRelease{
+				release: release,
+				folder:  filepath.Dir(playlistEntries[0].path),
+				entries: playlistEntries,
+			}
+		}
+	}
+}
+
+// historyReleaseEntries returns the saved tracks of the releases recorded in the download history
+// whose files still exist, in release order, keyed by release ID.
+func historyReleaseEntries(entries []*HistoryEntry, releaseIDs []string) map[string][]*playlistFileEntry {
+	items := make([]ShortDownloadItem, 0, len(releaseIDs))
+	for _, releaseID := range releaseIDs {
+		items = append(items, ShortDownloadItem{Category: DownloadCategoryAlbum, ItemID: releaseID})
+	}
+
+	collections := groupHistoryCollections(entries, items)
+	result := make(map[string][]*playlistFileEntry, len(collections))
+
+	for _, collection := range collections {
+		for _, entry := range collection.entries {
+			result[collection.item.ItemID] = append(result[collection.item.ItemID], &playlistFileEntry{
+				path:     entry.Path,
+				duration: unknownPlaylistEntryDuration,
+				artists:  entry.Artists,
+				title:    entry.Title,
+			})
+		}
+	}
+
+	return result
+}
+
+// writeDiscographyPlaylist writes the discography playlist of the artist, named after the artist.
+func (s *ServiceImpl) writeDiscographyPlaylist(ctx context.Context, discography *artistDiscography) {
+	releases := slices.Collect(maps.Values(discography.releases))
+
+	// Releases of the same day keep the order of their IDs.
+	slices.SortFunc(releases, func(a, b *discographyRelease) int {
+		return cmp.Or(cmp.Compare(a.release.Date, b.release.Date), cmp.Compare(a.release.ID, b.release.ID))
+	})
+
+	var (
+		entries []*playlistFileEntry
+		folders = make([]string, 0, len(releases))
+	)
+
+	for _, release := range releases {
+		entries = append(entries, release.entries...)
+		folders = append(folders, release.folder)
+	}
+
+	playlistDir := discographyFolder(s.cfg.OutputPath, folders)
+	playlistName := utils.SanitizeFilename(discographyArtistName(discography.artistID, releases))
+	playlistPath := filepath.Join(playlistDir, playlistName+discographyPlaylistSuffix)
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would write discography playlist with %d tracks: %s", len(entries), playlistPath)
+
+		return
+	}
+
+	content := buildPlaylistFile(playlistDir, entries)
+
+	if err := os.WriteFile(playlistPath, []byte(content), constants.DefaultFilePermissions); err != nil {
+		s.recordError(&DownloadError{
+			Category:  DownloadCategoryArtist,
+			ItemID:    discography.artistID,
+			ItemTitle: "Artist ID: " + discography.artistID,
+			Phase:     "writing discography playlist",
+			Error:     err,
+		})
+
+		return
+	}
+
+	logger.Infof(ctx, "Discography playlist with %d tracks saved to: %s", len(entries), playlistPath)
+}
+
+// discographyFolder returns the folder holding the folders of all the releases,
+// such as the artist folder of "{{.albumArtist}}/{{.albumTitle}}" templates.
+// The output path is used when the releases share no folder inside it.
+func discographyFolder(outputPath string, releaseFolders []string) string {
+	if len(releaseFolders) == 0 {
+		return outputPath
+	}
+
+	result := filepath.Dir(filepath.Clean(releaseFolders[0]))
+
+	for _, folder := range releaseFolders[1:] {
+		parent := filepath.Dir(filepath.Clean(folder))
+
+		for !isWithinFolder(parent, result) {
+			next := filepath.Dir(result)
+			if next == result {
+				return outputPath
+			}
+
+			result = next
+		}
+	}
+
+	if !isWithinFolder(result, filepath.Clean(outputPath)) {
+		return outputPath
+	}
+
+	return result
+}
+
+// discographyArtistName returns the name the artist is credited with on the releases.
+func discographyArtistName(artistID string, releases []*discographyRelease) string {
+	id, _ := strconv.ParseInt(artistID, 10, 64)
+
+	for _, release := range releases {
+		index := slices.Index(release.release.ArtistIDs, id)
+		if index >= 0 && index < len(release.release.ArtistNames) {
+			return release.release.ArtistNames[index]
+		}
+	}
+
+	return fmt.Sprintf("Artist %s", artistID)
+}
@@ -0,0 +1,136 @@
+package zvuk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestDiscographyFolder tests that the playlist is written into the folder holding every release folder.
+func TestDiscographyFolder(t *testing.T) {
+	t.Parallel()
+
+	const outputPath = "music"
+
+	tests := []struct {
+		name     string
+		folders  []string
+		expected string
+	}{
+		{
+			name: "artist folder",
+			folders: []string{
+				filepath.Join(outputPath, "Rammstein", "2001 - Mutter"),
+				filepath.Join(outputPath, "Rammstein", "1997 - Sehnsucht"),
+			},
+			expected: filepath.Join(outputPath, "Rammstein"),
+		},
+		{
+			name:     "single release",
+			folders:  []string{filepath.Join(outputPath, "Rammstein", "2001 - Mutter")},
+			expected: filepath.Join(outputPath, "Rammstein"),
+		},
+		{
+			name: "flat layout",
+			folders: []string{
+				filepath.Join(outputPath, "2001 - Rammstein - Mutter"),
+				filepath.Join(outputPath, "1997 - Rammstein - Sehnsucht"),
+			},
+			expected: outputPath,
+		},
+		{
+			name: "outside the output path",
+			folders: []string{
+				filepath.Join("library", "Rammstein", "2001 - Mutter"),
+				filepath.Join(outputPath, "Rammstein", "1997 - Sehnsucht"),
+			},
+			expected: outputPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, discographyFolder(outputPath, tt.folders))
+		})
+	}
+}
+
+// TestWriteArtistDiscographies tests that the releases of the run and those of the download history
+// are listed in release date order.
+func TestWriteArtistDiscographies(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath   = t.TempDir()
+		artistFolder = filepath.Join(outputPath, "Rammstein")
+		mutterPath   = filepath.Join(artistFolder, "2001 - Mutter", "01 - Mein Herz brennt.flac")
+		sehnsuchtDir = filepath.Join(artistFolder, "1997 - Sehnsucht")
+		engelPath    = filepath.Join(sehnsuchtDir, "02 - Engel.flac")
+		historyPath  = filepath.Join(t.TempDir(), "history.jsonl")
+	)
+
+	require.NoError(t, os.MkdirAll(sehnsuchtDir, constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(engelPath, []byte("audio"), constants.DefaultFilePermissions))
+
+	mockClient := mock_zvuk_client.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().GetAlbumsMetadata(gomock.Any(), []string{"20"}, false).
+		Return(&zvuk.GetAlbumsMetadataResponse{Releases: map[string]*zvuk.Release{
+			"20": {ID: 20, Title: "Sehnsucht", Date: 19970822, ArtistIDs: []int64{7}, ArtistNames: []string{"Rammstein"}},
+		}}, nil)
+
+	s := &ServiceImpl{
+		cfg:        &config.Config{OutputPath: outputPath, WriteDiscographyPlaylists: true},
+		zvukClient: mockClient,
+		history:    newDownloadHistory(historyPath),
+		stats:      newStatsCollector(),
+	}
+
+	// Sehnsucht was saved by an earlier run, Mutter is saved now, and Reise, Reise was never saved.
+	require.NoError(t, s.history.add(&HistoryEntry{
+		TrackID: "201", Title: "Engel", Artists: []string{"Rammstein"},
+		Category: DownloadCategoryAlbum.ToLowerCase(), ParentID: "20", Position: 2, Path: engelPath,
+	}))
+
+	s.startArtistDiscography("7", []string{"30", "10", "20"})
+
+	metadata := &downloadTracksMetadata{
+		audioCollection: &audioCollection{
+			category:   DownloadCategoryAlbum,
+			id:         "10",
+			tracksPath: filepath.Dir(mutterPath),
+			trackIDs:   []int64{101},
+		},
+		tracksMetadata: map[string]*zvuk.Track{
+			"101": {ID: 101, Title: "Mein Herz brennt", ArtistNames: []string{"Rammstein"}, Duration: 279},
+		},
+		albumsMetadata: map[string]*zvuk.Release{
+			"10": {ID: 10, Title: "Mutter", Date: 20010402, ArtistIDs: []int64{7}, ArtistNames: []string{"Rammstein"}},
+		},
+		keepSavedTracks: true,
+	}
+	metadata.rememberSavedTrackPath("101", mutterPath, TrackQualityFLAC)
+
+	s.recordDiscographyRelease(metadata)
+	s.writeArtistDiscographies(t.Context())
+
+	content, err := os.ReadFile(filepath.Join(artistFolder, "Rammstein - Discography.m3u8"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "#EXTM3U\n"+
+		"#EXTINF:-1,Rammstein - Engel\n"+
+		"1997 - Sehnsucht/02 - Engel.flac\n"+
+		"#EXTINF:279,Rammstein - Mein Herz brennt\n"+
+		"2001 - Mutter/01 - Mein Herz brennt.flac\n",
+		string(content))
+}
@@ -0,0 +1,66 @@
+package zvuk
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// artistInitialDigits is the group folder of artists whose name starts with a digit.
+	artistInitialDigits = "0-9"
+	// artistInitialOther is the group folder of artists whose name has no letters or digits.
+	artistInitialOther = "#"
+)
+
+// getArtistInitial returns the group folder name for an artist: the first letter in upper case.
+// Latin letters are folded to their base letter (É becomes E), because diacritics there are
+// usually decoration, while letters of other scripts such as Cyrillic Й and Ё are distinct
+// letters of their alphabet and keep their own group.
+func getArtistInitial(artist string) string {
+	for _, r := range artist {
+		switch {
+		case unicode.IsLetter(r):
+			if unicode.Is(unicode.Latin, r) {
+				r = foldLatinLetter(r)
+			}
+
+			return string(unicode.ToUpper(r))
+		case unicode.IsDigit(r):
+			return artistInitialDigits
+		}
+	}
+
+	return artistInitialOther
+}
+
+// foldLatinLetter strips diacritics from a Latin letter by taking the base of its canonical decomposition.
+func foldLatinLetter(r rune) rune {
+	decomposed := norm.NFD.String(string(r))
+
+	for _, base := range decomposed {
+		if unicode.IsLetter(base) {
+			return base
+		}
+	}
+
+	return r
+}
+
+// groupFolderByArtistInitial places an album folder under the initial letter of the album artist
+// when group_by_artist_initial is enabled. Other categories are returned unchanged.
+func (s *ServiceImpl) groupFolderByArtistInitial(
+	category DownloadCategory,
+	tags map[string]string,
+	folderName string,
+) string {
+	if !s.cfg.GroupByArtistInitial || category != DownloadCategoryAlbum {
+		return folderName
+	}
+
+	artist := strings.TrimSpace(tags[TagAlbumArtist])
+
+	return filepath.Join(getArtistInitial(artist), folderName)
+}
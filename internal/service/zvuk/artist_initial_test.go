@@ -0,0 +1,62 @@
+package zvuk
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestGetArtistInitial tests initial letter extraction for different scripts.
+func TestGetArtistInitial(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		artist   string
+		expected string
+	}{
+		{artist: "ABBA", expected: "A"},
+		{artist: "abba", expected: "A"},
+		{artist: "Дора", expected: "Д"},
+		{artist: "ёлка", expected: "Ё"},
+		{artist: "Йорш", expected: "Й"},
+		{artist: "Émilie Simon", expected: "E"},
+		{artist: "Ölüm", expected: "O"},
+		{artist: `"Weird Al" Yankovic`, expected: "W"},
+		{artist: "...And You Will Know Us", expected: "A"},
+		{artist: "2Pac", expected: artistInitialDigits},
+		{artist: "!!!", expected: artistInitialOther},
+		{artist: "", expected: artistInitialOther},
+		{artist: "宇多田ヒカル", expected: "宇"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.artist, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, getArtistInitial(tt.artist))
+		})
+	}
+}
+
+// TestGroupFolderByArtistInitial tests that only album folders are grouped and only when enabled.
+func TestGroupFolderByArtistInitial(t *testing.T) {
+	t.Parallel()
+
+	tags := map[string]string{TagAlbumArtist: "Дора"}
+
+	disabled, ok := NewService(new(config.Config), nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok)
+	assert.Equal(t, "Дора", disabled.groupFolderByArtistInitial(DownloadCategoryAlbum, tags, "Дора"))
+
+	enabled, ok := NewService(&config.Config{GroupByArtistInitial: true}, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok)
+	assert.Equal(t,
+		filepath.Join("Д", "Дора", "2020 - Miss"),
+		enabled.groupFolderByArtistInitial(DownloadCategoryAlbum, tags, filepath.Join("Дора", "2020 - Miss")))
+	assert.Equal(t, "Д", enabled.groupFolderByArtistInitial(DownloadCategoryAlbum, tags, ""))
+	assert.Equal(t, "Book", enabled.groupFolderByArtistInitial(DownloadCategoryAudiobook, tags, "Book"))
+}
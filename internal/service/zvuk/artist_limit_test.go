@@ -0,0 +1,112 @@
+package zvuk
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// releaseIDRange returns the release IDs from first to last inclusive.
+func releaseIDRange(first, last int) []string {
+	result := make([]string, 0, last-first+1)
+	for id := first; id <= last; id++ {
+		result = append(result, strconv.Itoa(id))
+	}
+
+	return result
+}
+
+// TestGetArtistReleaseIDs_PerArtistLimit tests that per_artist_limit keeps the newest releases by release date.
+func TestGetArtistReleaseIDs_PerArtistLimit(t *testing.T) {
+	t.Parallel()
+
+	const artistID = "211963"
+
+	tests := []struct {
+		name        string
+		limit       int64
+		setupMock   func(setup *testDownloadSetup)
+		expectedIDs []string
+	}{
+		{
+			name:  "no limit fetches every page",
+			limit: 0,
+			setupMock: func(setup *testDownloadSetup) {
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 0, 50).
+					Return(releaseIDRange(1, 50), nil)
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 50, 50).
+					Return(releaseIDRange(51, 60), nil)
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 100, 50).
+					Return(nil, nil)
+			},
+			expectedIDs: releaseIDRange(1, 60),
+		},
+		{
+			name:  "limit keeps the newest releases by release date",
+			limit: 3,
+			setupMock: func(setup *testDownloadSetup) {
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 0, 50).
+					Return([]string{"4", "2", "1"}, nil)
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 50, 50).
+					Return([]string{"2", "3", "5"}, nil)
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 100, 50).
+					Return(nil, nil)
+				setup.mockClient.EXPECT().
+					GetAlbumsMetadata(gomock.Any(), []string{"4", "2", "1", "3", "5"}, false).
+					Return(&zvuk.GetAlbumsMetadataResponse{Releases: map[string]*zvuk.Release{
+						"1": {ID: 1, Date: 20240301},
+						"2": {ID: 2, Date: 20240301},
+						"3": {ID: 3, Date: 20250115},
+						"4": {ID: 4, Date: 20190720},
+					}}, nil)
+			},
+			expectedIDs: []string{"3", "2", "1"},
+		},
+		{
+			name:  "limit above the release count keeps every release",
+			limit: 100,
+			setupMock: func(setup *testDownloadSetup) {
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 0, 50).
+					Return(releaseIDRange(1, 3), nil)
+				setup.mockClient.EXPECT().
+					GetArtistReleaseIDs(gomock.Any(), artistID, 50, 50).
+					Return(nil, nil)
+			},
+			expectedIDs: releaseIDRange(1, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+				cfg.PerArtistLimit = tt.limit
+			})
+			defer setup.cleanup()
+
+			tt.setupMock(setup)
+
+			impl, ok := setup.service.(*ServiceImpl)
+			require.True(t, ok, "Service should be of type *ServiceImpl")
+
+			releaseIDs, err := impl.getArtistReleaseIDs(context.Background(), artistID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, releaseIDs)
+		})
+	}
+}
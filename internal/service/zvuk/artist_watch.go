@@ -0,0 +1,195 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// ArtistWatchState is the content of the watch state file of an artist: the releases already downloaded.
+type ArtistWatchState struct {
+	// ArtistID is the unique identifier of the artist.
+	ArtistID string `json:"artist_id"`
+	// CheckedAt is when the artist was last checked.
+	CheckedAt time.Time `json:"checked_at"`
+	// ReleaseIDs are the IDs of the releases downloaded by the previous checks, sorted.
+	ReleaseIDs []string `json:"release_ids"`
+}
+
+// artistWatch is an artist being watched during the run.
+type artistWatch struct {
+	// statePath is the location of the watch state file.
+	statePath string
+	// state is the watch state, protected by mutex.
+	state *ArtistWatchState
+	// newReleaseIDs are the releases added since the previous check, protected by mutex.
+	newReleaseIDs []string
+	// isStarted indicates that the releases were fetched, so the state is saved at the end of the run.
+	isStarted bool
+	// mutex protects the fields above.
+	mutex sync.Mutex
+}
+
+// WatchItems downloads the tracks added to the playlists and the releases added to the artists
+// since the previous check. The other items are downloaded as usual, skipping the files that exist.
+// On the first check of a playlist or an artist, everything is new.
+func (s *ServiceImpl) WatchItems(ctx context.Context, urls []string, deleteRemoved bool) {
+	items, err := s.urlProcessor.ExtractDownloadItems(ctx, urls)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to extract items to watch: %v", err)
+
+		return
+	}
+
+	syncs := make(map[string]*playlistSync)
+
+	for _, item := range items.StandaloneItems {
+		if _, isWave := waveStationID(item.ItemID); item.Category != DownloadCategoryPlaylist || isWave {
+			continue
+		}
+
+		ps, loadErr := s.newPlaylistSync(item.ItemID, deleteRemoved)
+		if loadErr != nil {
+			logger.Errorf(ctx, "Failed to load sync state of playlist %s: %v", item.ItemID, loadErr)
+
+			return
+		}
+
+		syncs[item.ItemID] = ps
+	}
+
+	watches := make(map[string]*artistWatch, len(items.Artists))
+
+	for _, item := range items.Artists {
+		statePath := filepath.Join(s.cfg.SyncStatePath, "artist-"+item.ItemID+".json")
+
+		state, loadErr := loadArtistWatchState(statePath, item.ItemID)
+		if loadErr != nil {
+			logger.Errorf(ctx, "Failed to load watch state of artist %s: %v", item.ItemID, loadErr)
+
+			return
+		}
+
+		watches[item.ItemID] = &artistWatch{
+			statePath: statePath,
+			state:     state,
+		}
+	}
+
+	s.playlistSyncs = syncs
+	s.artistWatches = watches
+
+	s.DownloadURLs(ctx, urls)
+}
+
+// newArtistReleaseIDs returns the releases of a watched artist that the previous checks have not downloaded.
+// All the releases are returned for an artist that is not watched.
+func (s *ServiceImpl) newArtistReleaseIDs(ctx context.Context, artistID string, releaseIDs []string) []string {
+	aw := s.artistWatches[artistID]
+	if aw == nil {
+		return releaseIDs
+	}
+
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	aw.isStarted = true
+	aw.newReleaseIDs = nil
+
+	for _, releaseID := range releaseIDs {
+		if _, isKnown := slices.BinarySearch(aw.state.ReleaseIDs, releaseID); !isKnown {
+			aw.newReleaseIDs = append(aw.newReleaseIDs, releaseID)
+		}
+	}
+
+	logger.Infof(ctx, "Watching artist with ID %s: %d release(s) downloaded before, %d new",
+		artistID, len(releaseIDs)-len(aw.newReleaseIDs), len(aw.newReleaseIDs))
+
+	return slices.Clone(aw.newReleaseIDs)
+}
+
+// saveArtistWatchStates writes the watch state of every artist checked during the run.
+// A new release is recorded only once it was downloaded without errors, so a failed one is retried
+// by the next check. Nothing new is recorded when the run was interrupted or aborted.
+func (s *ServiceImpl) saveArtistWatchStates(ctx context.Context) {
+	if s.cfg.DryRun || len(s.artistWatches) == 0 {
+		return
+	}
+
+	isCompleted := ctx.Err() == nil && s.AbortReason() == nil
+	failedReleaseIDs := make(map[string]struct{})
+
+	for _, downloadErr := range s.stats.snapshot().Errors {
+		switch {
+		case downloadErr.Category == DownloadCategoryAlbum:
+			failedReleaseIDs[downloadErr.ItemID] = struct{}{}
+		case downloadErr.ParentCategory == DownloadCategoryAlbum:
+			failedReleaseIDs[downloadErr.ParentID] = struct{}{}
+		}
+	}
+
+	for _, aw := range s.artistWatches {
+		aw.mutex.Lock()
+
+		if !aw.isStarted {
+			aw.mutex.Unlock()
+
+			continue
+		}
+
+		if isCompleted {
+			for _, releaseID := range aw.newReleaseIDs {
+				if _, isFailed := failedReleaseIDs[releaseID]; !isFailed {
+					aw.state.ReleaseIDs = append(aw.state.ReleaseIDs, releaseID)
+				}
+			}
+
+			slices.Sort(aw.state.ReleaseIDs)
+			aw.state.ReleaseIDs = slices.Compact(aw.state.ReleaseIDs)
+		}
+
+		aw.state.CheckedAt = time.Now()
+		err := writeJSONAtomically(aw.statePath, aw.state)
+
+		aw.mutex.Unlock()
+
+		if err != nil {
+			logger.Warnf(ctx, "Failed to save watch state of artist %s: %v", aw.state.ArtistID, err)
+
+			continue
+		}
+
+		logger.Infof(ctx, "Watch state of artist %s is saved to '%s'", aw.state.ArtistID, aw.statePath)
+	}
+}
+
+// loadArtistWatchState reads the watch state file of an artist. A missing file is an empty state.
+func loadArtistWatchState(path, artistID string) (*ArtistWatchState, error) {
+	state := &ArtistWatchState{ArtistID: artistID}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state '%s': %w", path, err)
+	}
+
+	// The releases are searched, so they must stay sorted even if the file was edited.
+	slices.Sort(state.ReleaseIDs)
+
+	return state, nil
+}
@@ -0,0 +1,70 @@
+package zvuk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestArtistWatch tests that only the new releases of a watched artist are downloaded
+// and that the failed ones are retried by the next check.
+func TestArtistWatch(t *testing.T) {
+	t.Parallel()
+
+	statePath := filepath.Join(t.TempDir(), "artist-1.json")
+	require.NoError(t, writeJSONAtomically(statePath, &ArtistWatchState{
+		ArtistID:   "1",
+		ReleaseIDs: []string{"10", "20"},
+	}))
+
+	state, err := loadArtistWatchState(statePath, "1")
+	require.NoError(t, err)
+
+	impl, ok := NewService(new(config.Config), nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.artistWatches = map[string]*artistWatch{
+		"1": {statePath: statePath, state: state},
+	}
+
+	ctx := context.Background()
+
+	// Artists that are not watched keep all their releases.
+	assert.Equal(t, []string{"10", "30"}, impl.newArtistReleaseIDs(ctx, "2", []string{"10", "30"}))
+
+	newReleaseIDs := impl.newArtistReleaseIDs(ctx, "1", []string{"40", "30", "20", "10"})
+	assert.Equal(t, []string{"40", "30"}, newReleaseIDs)
+
+	impl.recordError(&DownloadError{
+		Category:       DownloadCategoryTrack,
+		ItemID:         "400",
+		ParentCategory: DownloadCategoryAlbum,
+		ParentID:       "40",
+		Phase:          "downloading file",
+		Error:          assert.AnError,
+	})
+
+	impl.saveArtistWatchStates(ctx)
+
+	saved, err := loadArtistWatchState(statePath, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10", "20", "30"}, saved.ReleaseIDs)
+	assert.False(t, saved.CheckedAt.IsZero())
+
+	// An interrupted check records no new releases.
+	impl.newArtistReleaseIDs(ctx, "1", []string{"40", "50", "30", "20", "10"})
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	impl.saveArtistWatchStates(canceledCtx)
+
+	saved, err = loadArtistWatchState(statePath, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10", "20", "30"}, saved.ReleaseIDs)
+}
@@ -0,0 +1,18 @@
+package zvuk
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// writeJSONAtomically writes the value as indented JSON with utils.WriteFileAtomically.
+func writeJSONAtomically(path string, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode: %w", err)
+	}
+
+	return utils.WriteFileAtomically(path, content)
+}
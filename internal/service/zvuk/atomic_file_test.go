@@ -0,0 +1,30 @@
+package zvuk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteJSONAtomically tests that the file is created with its folders, replaced, and no temporary file is left.
+func TestWriteJSONAtomically(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "state")
+	path := filepath.Join(dir, "resume.json")
+
+	require.NoError(t, writeJSONAtomically(path, map[string]int{"tracks": 1}))
+	require.NoError(t, writeJSONAtomically(path, map[string]int{"tracks": 2}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tracks": 2}`, string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "resume.json", entries[0].Name())
+}
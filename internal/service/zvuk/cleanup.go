@@ -0,0 +1,169 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// OrphanedFileKind describes why a file is considered left behind by an interrupted run.
+type OrphanedFileKind string
+
+const (
+	// OrphanedFileKindPartialDownload is a track, cover, or description that was still being downloaded.
+	OrphanedFileKindPartialDownload OrphanedFileKind = "partial download"
+	// OrphanedFileKindPartialEncode is a normalized or portable copy that ffmpeg was still writing.
+	OrphanedFileKindPartialEncode OrphanedFileKind = "partial encode"
+	// OrphanedFileKindTemporaryCover is a downloaded cover that was not moved to its final name.
+	OrphanedFileKindTemporaryCover OrphanedFileKind = "temporary cover"
+	// OrphanedFileKindTemporaryDescription is a saved description that was not moved to its final name.
+	OrphanedFileKindTemporaryDescription OrphanedFileKind = "temporary description"
+)
+
+// uuidPattern matches the UUIDs that make the names of temporary covers and descriptions unique.
+const uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+var (
+	// partialDownloadPattern matches the temporary files of the downloader (os.CreateTemp adds the number).
+	partialDownloadPattern = regexp.MustCompile(`\.part-\d+$`)
+	// partialEncodePattern matches the files ffmpeg writes before they are renamed.
+	partialEncodePattern = regexp.MustCompile(regexp.QuoteMeta(ffmpegTempSuffix) + `$`)
+	// temporaryCoverPattern matches covers named with a UUID while they are downloaded.
+	temporaryCoverPattern = regexp.MustCompile(`^` + defaultCoverFilename + `_` + uuidPattern + `(\.[^.]+)?$`)
+	// temporaryDescriptionPattern matches descriptions named with a UUID while they are saved.
+	temporaryDescriptionPattern = regexp.MustCompile(
+		`^` + defaultDescriptionFilename + `_` + uuidPattern + `(\.[^.]+)?$`)
+)
+
+// OrphanedFile is a temporary file left behind by an interrupted run.
+type OrphanedFile struct {
+	// Path is the location of the file.
+	Path string
+	// Kind describes what the file was.
+	Kind OrphanedFileKind
+	// Size is the size of the file in bytes.
+	Size int64
+}
+
+// CleanupRequest contains the parameters of a cleanup.
+type CleanupRequest struct {
+	// Dir is the folder scanned for temporary files, including its subfolders.
+	Dir string
+	// Confirm is called with the files found and reports whether they are deleted.
+	Confirm func(files []*OrphanedFile) bool
+}
+
+// CleanupReport is the result of a cleanup.
+type CleanupReport struct {
+	// Files are the temporary files found, sorted by path.
+	Files []*OrphanedFile
+	// RemovedCount is the number of files deleted.
+	RemovedCount int
+	// RemovedBytes is the total size of the files deleted.
+	RemovedBytes int64
+}
+
+// CleanupOrphanedFiles finds the temporary files interrupted runs leave in the folder and deletes them
+// once confirmed. The output path is locked meanwhile so the files of a run in progress are not deleted;
+// no run can be in progress in an output path that does not exist yet.
+func CleanupOrphanedFiles(ctx context.Context, cfg *config.Config, req *CleanupRequest) (*CleanupReport, error) {
+	if _, err := os.Stat(cfg.OutputPath); err == nil && !cfg.NoLock {
+		lock, err := acquireRunLock(cfg.OutputPath)
+		if err != nil {
+			return nil, err
+		}
+
+		defer lock.release() //nolint:errcheck // A lock file left behind is taken over by the next run.
+	}
+
+	files, err := findOrphanedFiles(ctx, req.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CleanupReport{Files: files}
+	if len(files) == 0 || !req.Confirm(files) {
+		return report, nil
+	}
+
+	var errs []error
+
+	for _, file := range files {
+		if err = os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to delete '%s': %w", file.Path, err))
+
+			continue
+		}
+
+		report.RemovedCount++
+		report.RemovedBytes += file.Size
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// findOrphanedFiles walks the folder and returns the temporary files in it, sorted by path.
+func findOrphanedFiles(ctx context.Context, dir string) ([]*OrphanedFile, error) {
+	var files []*OrphanedFile
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		kind, ok := orphanedFileKind(entry.Name())
+		if !ok {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, &OrphanedFile{Path: path, Kind: kind, Size: info.Size()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan '%s': %w", dir, err)
+	}
+
+	slices.SortFunc(files, func(a, b *OrphanedFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files, nil
+}
+
+// orphanedFileKind tells whether a file name belongs to a temporary file and what it was.
+func orphanedFileKind(name string) (OrphanedFileKind, bool) {
+	switch {
+	case partialDownloadPattern.MatchString(name):
+		return OrphanedFileKindPartialDownload, true
+	case partialEncodePattern.MatchString(name):
+		return OrphanedFileKindPartialEncode, true
+	case temporaryCoverPattern.MatchString(name):
+		return OrphanedFileKindTemporaryCover, true
+	case temporaryDescriptionPattern.MatchString(name):
+		return OrphanedFileKindTemporaryDescription, true
+	default:
+		return "", false
+	}
+}
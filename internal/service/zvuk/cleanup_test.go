@@ -0,0 +1,114 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestOrphanedFileKind tests which file names are recognized as temporary files.
+func TestOrphanedFileKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		expectedKind OrphanedFileKind
+	}{
+		{name: "01 - Track.flac.part-2849127", expectedKind: OrphanedFileKindPartialDownload},
+		{name: "cover_0f8fad5b-d9cb-469f-a165-70867728950e.jpg.part-17", expectedKind: OrphanedFileKindPartialDownload},
+		{name: "01 - Track.mp3.part", expectedKind: OrphanedFileKindPartialEncode},
+		{name: "cover_0f8fad5b-d9cb-469f-a165-70867728950e.jpg", expectedKind: OrphanedFileKindTemporaryCover},
+		{name: "description_0f8fad5b-d9cb-469f-a165-70867728950e.txt", expectedKind: OrphanedFileKindTemporaryDescription},
+		{name: "01 - Track.flac"},
+		{name: "cover.jpg"},
+		{name: "cover_art.jpg"},
+		{name: "description.txt"},
+		{name: "01 - Track.flac.untagged"},
+		{name: "Part 1.mp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kind, ok := orphanedFileKind(tt.name)
+			assert.Equal(t, tt.expectedKind != "", ok)
+			assert.Equal(t, tt.expectedKind, kind)
+		})
+	}
+}
+
+// TestCleanupOrphanedFiles tests that temporary files are deleted only once confirmed.
+func TestCleanupOrphanedFiles(t *testing.T) {
+	t.Parallel()
+
+	newOutputPath := func(t *testing.T) string {
+		t.Helper()
+
+		outputPath := t.TempDir()
+		albumPath := filepath.Join(outputPath, "Artist", "Album")
+		require.NoError(t, os.MkdirAll(albumPath, constants.DefaultFolderPermissions))
+
+		for _, name := range []string{"01 - Track.flac", "01 - Track.flac.part-123", "cover.jpg"} {
+			err := os.WriteFile(filepath.Join(albumPath, name), []byte("data"), constants.DefaultFilePermissions)
+			require.NoError(t, err)
+		}
+
+		return outputPath
+	}
+
+	t.Run("declined", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := newOutputPath(t)
+
+		report, err := CleanupOrphanedFiles(context.Background(), &config.Config{OutputPath: outputPath},
+			&CleanupRequest{Dir: outputPath, Confirm: func([]*OrphanedFile) bool { return false }})
+		require.NoError(t, err)
+
+		require.Len(t, report.Files, 1)
+		assert.Equal(t, OrphanedFileKindPartialDownload, report.Files[0].Kind)
+		assert.Equal(t, int64(len("data")), report.Files[0].Size)
+		assert.Zero(t, report.RemovedCount)
+		assert.FileExists(t, report.Files[0].Path)
+		assert.NoFileExists(t, filepath.Join(outputPath, runLockFilename), "lock must be released")
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := newOutputPath(t)
+
+		report, err := CleanupOrphanedFiles(context.Background(), &config.Config{OutputPath: outputPath},
+			&CleanupRequest{Dir: outputPath, Confirm: func([]*OrphanedFile) bool { return true }})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.RemovedCount)
+		assert.Equal(t, int64(len("data")), report.RemovedBytes)
+		assert.NoFileExists(t, report.Files[0].Path)
+		assert.FileExists(t, filepath.Join(outputPath, "Artist", "Album", "01 - Track.flac"))
+	})
+
+	t.Run("run in progress", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := newOutputPath(t)
+
+		lock, err := acquireRunLock(outputPath)
+		require.NoError(t, err)
+
+		defer lock.release() //nolint:errcheck // The temporary folder is removed anyway.
+
+		_, err = CleanupOrphanedFiles(context.Background(), &config.Config{OutputPath: outputPath},
+			&CleanupRequest{Dir: outputPath, Confirm: func([]*OrphanedFile) bool { return true }})
+		require.ErrorIs(t, err, ErrOutputPathLocked)
+		assert.FileExists(t, filepath.Join(outputPath, "Artist", "Album", "01 - Track.flac.part-123"))
+	})
+}
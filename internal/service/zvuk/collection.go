@@ -23,6 +23,8 @@ type BaseCollectionHandler struct {
 	SingleFolderHandling bool
 	// DescriptionSupport indicates if the collection should have description support.
 	DescriptionSupport bool
+	// ArtistJoinStyle defines how the track artists are combined for templating.
+	ArtistJoinStyle string
 }
 
 // HasSingleFolderHandling returns true if the collection has single folder handling.
@@ -76,6 +78,7 @@ func handleDescription(
 		itemPath,
 		in.Description,
 		in.FirstTrackFilename,
+		in.Tags,
 	)
 
 	if embeddableDescriptionPath == "" {
@@ -136,7 +139,7 @@ func determineItemFolderAndFilename(
 		rawItemFolderName := h.GetFolderNameTemplate(ctx, itemTags)
 		itemFolderName := s.getFolderNameAfterTemplateExecution(ctx, category, rawItemFolderName)
 
-		return itemFolderName, ""
+		return s.groupFolderByArtistInitial(category, itemTags, itemFolderName), ""
 	}
 
 	if tracksCount != 1 {
@@ -152,7 +155,7 @@ func determineItemFolderAndFilename(
 
 	firstTrackFilename := h.GetFirstTrackFilename(ctx, track, itemTags, tracksCount)
 
-	return "", firstTrackFilename
+	return s.groupFolderByArtistInitial(category, itemTags, ""), firstTrackFilename
 }
 
 // GetFirstTrackFilename returns the first track filename for a collection.
@@ -202,7 +205,7 @@ func (b *BaseCollectionHandler) FillTrackTagsForTemplating(
 
 	// Add track-specific fields.
 	result[TagCollectionTitle] = audioCollection.title
-	result[TagTrackArtist] = strings.Join(track.ArtistNames, ", ")
+	result[TagTrackArtist] = formatDisplayArtist(track.ArtistNames, b.ArtistJoinStyle)
 	result[TagTrackID] = strconv.FormatInt(track.ID, 10)
 	result[TagTrackNumber] = strconv.FormatInt(trackNumber, 10)
 	result[TagTrackNumberPad] = fmt.Sprintf("%0*d", trackNumberPaddingWidth, trackNumber)
@@ -250,8 +253,19 @@ func registerCollectionCore(
 		tracksCount: int64(len(in.TrackIDs)),
 	}
 
-	// Create the folder path for the item.
-	itemPath := filepath.Join(s.cfg.OutputPath, in.ItemFolderName)
+	if in.Category == DownloadCategoryPlaylist {
+		audioCollection.playlistPositions = newPlaylistPositions(in.TrackIDs)
+	}
+
+	// Create the folder path for the item, keeping it apart from folders differing only by letter case.
+	itemPath := s.claimPath(
+		ctx,
+		filepath.Join(s.cfg.OutputPath, in.ItemFolderName),
+		in.Category.ToLowerCase()+":"+in.ItemID,
+		true)
+
+	// Move the folder of an album saved under an earlier release ID before creating a new one.
+	s.relinkReissue(ctx, in, itemPath)
 
 	// Create the folder for the item unless in dry-run mode.
 	if !s.cfg.DryRun {
@@ -289,6 +303,8 @@ func registerCollectionCore(
 
 	// Set the paths for the audio collection.
 	audioCollection.tracksPath = itemPath
+	audioCollection.hasOwnFolder = in.FirstTrackFilename == "" &&
+		filepath.Clean(itemPath) != filepath.Clean(s.cfg.OutputPath)
 	audioCollection.embeddableCoverPath = embeddableCoverPath
 	audioCollection.coverPath = coverPath
 
@@ -300,6 +316,9 @@ func registerCollectionCore(
 	// Register the audio collection.
 	s.audioCollections[audioCollectionKey] = audioCollection
 
+	// The folder is about to change, so importers must wait for the new marker.
+	s.removeReadyMarker(ctx, audioCollection)
+
 	return audioCollection
 }
 
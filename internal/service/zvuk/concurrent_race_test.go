@@ -197,6 +197,7 @@ func TestUUIDBasedNaming_UniquePaths(t *testing.T) {
 			setup.tempDir,
 			"Test description",
 			"",
+			nil,
 		)
 
 		require.NotEmpty(t, tempDescPath, "Description path should not be empty")
@@ -0,0 +1,174 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// coverCache keeps one copy of every cover image downloaded during the run, keyed by cover URL.
+// Collections sharing a release cover (e.g., several tracks of one album requested separately)
+// fetch the image once; every later request gets a local copy of the cached file.
+type coverCache struct {
+	// mutex protects dir and entries.
+	mutex sync.Mutex
+	// dir is the temporary folder of cached covers, created on first use.
+	dir string
+	// entries maps a cover URL to its cached file.
+	entries map[string]*cachedCover
+}
+
+// cachedCover is a cover file that is being downloaded or is ready to be copied.
+type cachedCover struct {
+	// ready is closed once the download has finished.
+	ready chan struct{}
+	// path is the location of the cached file.
+	path string
+	// err is the download error, if any.
+	err error
+}
+
+// coverDownloadFunc downloads the image at url to destinationPath.
+type coverDownloadFunc func(ctx context.Context, url, destinationPath string) error
+
+// newCoverCache creates an empty cover cache.
+func newCoverCache() *coverCache {
+	return &coverCache{
+		entries: make(map[string]*cachedCover),
+	}
+}
+
+// copyTo writes the cover to destinationPath. The first request for coverURL downloads
+// the image into the cache; concurrent requests wait for it, later ones copy the cached file.
+// isCached reports whether the image came from the cache instead of the network.
+func (c *coverCache) copyTo(
+	ctx context.Context,
+	coverURL string,
+	destinationPath string,
+	download coverDownloadFunc,
+) (bool, error) {
+	cachedPath, isCached, err := c.get(ctx, coverURL, filepath.Ext(destinationPath), download)
+	if err != nil {
+		return false, err
+	}
+
+	if err = copyFile(cachedPath, destinationPath); err != nil {
+		return false, fmt.Errorf("failed to copy cached cover: %w", err)
+	}
+
+	return isCached, nil
+}
+
+// get returns the path of the cached cover, downloading it on the first request for coverURL.
+// The file belongs to the cache: it must be read or copied, never moved.
+// isCached reports whether the image had already been downloaded.
+func (c *coverCache) get(
+	ctx context.Context,
+	coverURL string,
+	extension string,
+	download coverDownloadFunc,
+) (string, bool, error) {
+	entry, isOwner, err := c.acquire(coverURL, extension)
+	if err != nil {
+		return "", false, err
+	}
+
+	if isOwner {
+		entry.err = download(ctx, coverURL, entry.path)
+		if entry.err != nil {
+			// Forget the failed download, so a later collection can try again.
+			c.mutex.Lock()
+			delete(c.entries, coverURL)
+			c.mutex.Unlock()
+		}
+
+		close(entry.ready)
+	} else {
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+	}
+
+	if entry.err != nil {
+		return "", false, entry.err
+	}
+
+	return entry.path, !isOwner, nil
+}
+
+// acquire returns the cache entry of the cover URL, creating it if needed.
+// isOwner is true when the caller created the entry and must download the image.
+func (c *coverCache) acquire(coverURL, extension string) (*cachedCover, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if entry, ok := c.entries[coverURL]; ok {
+		return entry, false, nil
+	}
+
+	if c.dir == "" {
+		dir, err := os.MkdirTemp("", "zvuk-covers-*")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create cover cache folder: %w", err)
+		}
+
+		c.dir = dir
+	}
+
+	entry := &cachedCover{
+		ready: make(chan struct{}),
+		path:  filepath.Join(c.dir, uuid.New().String()+extension),
+	}
+	c.entries[coverURL] = entry
+
+	return entry, true, nil
+}
+
+// cleanup removes the cached files.
+func (c *coverCache) cleanup() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(c.dir)
+
+	c.dir = ""
+	c.entries = make(map[string]*cachedCover)
+
+	return err
+}
+
+// copyFile copies the contents of sourcePath to destinationPath, replacing it.
+func copyFile(sourcePath, destinationPath string) error {
+	source, err := os.Open(filepath.Clean(sourcePath))
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+
+	destination, err := os.OpenFile(filepath.Clean(destinationPath), overwriteFileOptions, constants.DefaultFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(destination, source); err != nil {
+		_ = destination.Close()
+
+		return err
+	}
+
+	return destination.Close()
+}
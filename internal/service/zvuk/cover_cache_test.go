@@ -0,0 +1,96 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestCoverCache_DownloadsOncePerURL verifies that concurrent requests for the same cover
+// share one download and every destination receives a copy.
+func TestCoverCache_DownloadsOncePerURL(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache     = newCoverCache()
+		tempDir   = t.TempDir()
+		downloads atomic.Int64
+		waitGroup sync.WaitGroup
+	)
+
+	defer func() { require.NoError(t, cache.cleanup()) }()
+
+	download := func(_ context.Context, _ string, destinationPath string) error {
+		downloads.Add(1)
+
+		return os.WriteFile(destinationPath, []byte("image"), constants.DefaultFilePermissions)
+	}
+
+	const requestsCount = 8
+
+	var cachedCount atomic.Int64
+
+	for i := range requestsCount {
+		waitGroup.Go(func() {
+			destinationPath := filepath.Join(tempDir, "cover"+strconv.Itoa(i)+".jpg")
+
+			isCached, err := cache.copyTo(context.Background(), "https://example.com/cover.jpg", destinationPath, download)
+			assert.NoError(t, err)
+
+			if isCached {
+				cachedCount.Add(1)
+			}
+
+			content, err := os.ReadFile(destinationPath)
+			assert.NoError(t, err)
+			assert.Equal(t, "image", string(content))
+		})
+	}
+
+	waitGroup.Wait()
+
+	assert.Equal(t, int64(1), downloads.Load())
+	assert.Equal(t, int64(requestsCount-1), cachedCount.Load())
+}
+
+// TestCoverCache_RetriesFailedDownload verifies that a failed download is not cached.
+func TestCoverCache_RetriesFailedDownload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache           = newCoverCache()
+		destinationPath = filepath.Join(t.TempDir(), "cover.jpg")
+		errDownload     = errors.New("network error")
+		isFailing       = true
+	)
+
+	defer func() { require.NoError(t, cache.cleanup()) }()
+
+	download := func(_ context.Context, _ string, path string) error {
+		if isFailing {
+			return errDownload
+		}
+
+		return os.WriteFile(path, []byte("image"), constants.DefaultFilePermissions)
+	}
+
+	_, err := cache.copyTo(context.Background(), "https://example.com/cover.jpg", destinationPath, download)
+	require.ErrorIs(t, err, errDownload)
+
+	isFailing = false
+
+	isCached, err := cache.copyTo(context.Background(), "https://example.com/cover.jpg", destinationPath, download)
+	require.NoError(t, err)
+	assert.False(t, isCached)
+	assert.FileExists(t, destinationPath)
+}
@@ -15,19 +15,30 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
-	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/config"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 )
 
 // downloadCollection downloads any type of collection based on item.Category.
+// The errors are reported for the item of the download context carried by ctx, created here if missing.
 //
 //nolint:funlen,gocognit // It handles a lot of logic, so it's complex.
 func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem) {
+	dc := DownloadContextFromContext(ctx)
+	if dc == nil || dc.Category != item.Category || dc.ItemID != item.ItemID {
+		ctx = withItemDownloadContext(ctx, item)
+		dc = DownloadContextFromContext(ctx)
+	}
+
+	stopMetadataTimer := s.startPhaseTimer(DownloadPhaseMetadata)
+	defer stopMetadataTimer()
+
 	// Fetch metadata based on category.
 	var (
 		itemID          = item.ItemID
 		category        = item.Category
+		fallbackTitle   = category.ToTitleCase() + " ID: " + itemID
 		tracksMetadata  map[string]*zvuk.Track
 		audioCollection *audioCollection
 		streamsMetadata map[string]*zvuk.StreamQualities
@@ -41,14 +52,7 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 	case DownloadCategoryAlbum:
 		itemData, fetchErr := s.fetchAlbumData(ctx, itemID)
 		if fetchErr != nil {
-			s.recordError(&DownloadError{
-				Category:  category,
-				ItemID:    itemID,
-				ItemTitle: category.ToTitleCase() + " ID: " + itemID,
-				ItemURL:   item.URL,
-				Phase:     "fetching " + category.ToLowerCase() + " data",
-				Error:     fetchErr,
-			})
+			s.recordError(dc.newError(fallbackTitle, "fetching "+category.ToLowerCase()+" data", fetchErr))
 
 			return
 		}
@@ -64,35 +68,21 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 		labelsMetadata = itemData.labels
 
 	case DownloadCategoryPlaylist:
-		getPlaylistsMetadataResponse, fetchErr := s.zvukClient.GetPlaylistsMetadata(ctx, []string{itemID})
+		getPlaylistsMetadataResponse, fetchErr := s.fetchPlaylistMetadata(ctx, itemID)
 		if fetchErr != nil {
-			s.recordError(&DownloadError{
-				Category:  category,
-				ItemID:    itemID,
-				ItemTitle: category.ToTitleCase() + " ID: " + itemID,
-				ItemURL:   item.URL,
-				Phase:     "fetching " + category.ToLowerCase() + " metadata",
-				Error:     fetchErr,
-			})
+			s.recordError(dc.newError(fallbackTitle, "fetching "+category.ToLowerCase()+" metadata", fetchErr))
 
 			return
 		}
 
 		itemData, fetchErr := s.fetchAlbumsDataFromTracks(ctx, getPlaylistsMetadataResponse.Tracks)
 		if fetchErr != nil {
-			playlistTitle := "Playlist ID: " + itemID
+			playlistTitle := fallbackTitle
 			if playlist, ok := getPlaylistsMetadataResponse.Playlists[itemID]; ok && playlist != nil {
 				playlistTitle = playlist.Title
 			}
 
-			s.recordError(&DownloadError{
-				Category:  category,
-				ItemID:    itemID,
-				ItemTitle: playlistTitle,
-				ItemURL:   item.URL,
-				Phase:     "fetching track metadata",
-				Error:     fetchErr,
-			})
+			s.recordError(dc.newError(playlistTitle, "fetching track metadata", fetchErr))
 
 			return
 		}
@@ -117,14 +107,7 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 	case DownloadCategoryAudiobook:
 		itemData, fetchErr := s.zvukClient.GetAudiobooksMetadata(ctx, []string{itemID})
 		if fetchErr != nil {
-			s.recordError(&DownloadError{
-				Category:  category,
-				ItemID:    itemID,
-				ItemTitle: category.ToTitleCase() + " ID: " + itemID,
-				ItemURL:   item.URL,
-				Phase:     "fetching " + category.ToLowerCase() + " metadata",
-				Error:     fetchErr,
-			})
+			s.recordError(dc.newError(fallbackTitle, "fetching "+category.ToLowerCase()+" metadata", fetchErr))
 
 			return
 		}
@@ -138,14 +121,7 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 	case DownloadCategoryPodcast:
 		itemData, fetchErr := s.zvukClient.GetPodcastsMetadata(ctx, []string{itemID})
 		if fetchErr != nil {
-			s.recordError(&DownloadError{
-				Category:  category,
-				ItemID:    itemID,
-				ItemTitle: category.ToTitleCase() + " ID: " + itemID,
-				ItemURL:   item.URL,
-				Phase:     "fetching " + category.ToLowerCase() + " metadata",
-				Error:     fetchErr,
-			})
+			s.recordError(dc.newError(fallbackTitle, "fetching "+category.ToLowerCase()+" metadata", fetchErr))
 
 			return
 		}
@@ -176,22 +152,19 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 
 		streamsMetadata, err = s.zvukClient.GetStreamQualities(ctx, trackIDs)
 		if err != nil {
-			s.recordError(&DownloadError{
-				Category:       category,
-				ItemID:         itemID,
-				ItemTitle:      audioCollection.title,
-				ItemURL:        item.URL,
-				ParentCategory: category,
-				ParentID:       itemID,
-				ParentTitle:    audioCollection.title,
-				Phase:          fmt.Sprintf("fetching %s streams", category.ToSubcategory()),
-				Error:          err,
-			})
+			phase := fmt.Sprintf("fetching %s streams", category.ToSubcategory())
+
+			downloadErr := dc.newError(audioCollection.title, phase, err)
+			downloadErr.ParentCategory, downloadErr.ParentID, downloadErr.ParentTitle = category, itemID, audioCollection.title
+
+			s.recordError(downloadErr)
 
 			return
 		}
 	}
 
+	stopMetadataTimer()
+
 	// Prepare unified metadata for downloading tracks.
 	metadata := &downloadTracksMetadata{
 		audioCollection:        audioCollection,
@@ -202,6 +175,19 @@ func (s *ServiceImpl) downloadCollection(ctx context.Context, item *DownloadItem
 		albumsTags:             albumsTags,
 		chapterStreamsMetadata: streamsMetadata,
 		labelsMetadata:         labelsMetadata,
+		keepSavedTracks: s.cfg.WritePlaylistFiles &&
+			(category == DownloadCategoryAlbum || category == DownloadCategoryPlaylist) ||
+			s.cfg.WriteDiscographyPlaylists && category == DownloadCategoryAlbum,
+	}
+
+	if category == DownloadCategoryPlaylist {
+		metadata.playlistSync = s.startPlaylistSync(ctx, audioCollection)
+	}
+
+	if category == DownloadCategoryPlaylist && s.cfg.PlaylistLayout == config.PlaylistLayoutLibrary {
+		s.downloadPlaylistToLibrary(ctx, metadata)
+
+		return
 	}
 
 	// Download all tracks using unified pipeline.
@@ -309,13 +295,12 @@ func (s *ServiceImpl) parseItemCoverURL(sourceURL string) *parsedCoverURL {
 	}
 }
 
-// downloadCover downloads the cover art for albums, playlists, audiobooks and podcasts.
-func (s *ServiceImpl) downloadCover(
+// resolveCoverURL turns the cover URL from metadata into a full download URL and the image extension.
+// It returns an empty URL if the metadata has no cover.
+func (s *ServiceImpl) resolveCoverURL(
 	ctx context.Context,
 	category DownloadCategory,
 	sourceURL string,
-	itemPath string,
-	firstTrackFilename string,
 ) (string, string) {
 	// Trim and validate the cover art URL.
 	trimmedSourceURL := strings.TrimSpace(sourceURL)
@@ -356,6 +341,22 @@ func (s *ServiceImpl) downloadCover(
 		}
 	}
 
+	return coverURL, coverExtension
+}
+
+// downloadCover downloads the cover art for albums, playlists, audiobooks and podcasts.
+func (s *ServiceImpl) downloadCover(
+	ctx context.Context,
+	category DownloadCategory,
+	sourceURL string,
+	itemPath string,
+	firstTrackFilename string,
+) (string, string) {
+	coverURL, coverExtension := s.resolveCoverURL(ctx, category, sourceURL)
+	if coverURL == "" {
+		return "", ""
+	}
+
 	// Calculate the final cover filename.
 	var finalCoverFilename string
 	if firstTrackFilename != "" {
@@ -380,18 +381,41 @@ func (s *ServiceImpl) downloadCover(
 	downloadFilename := utils.SetFileExtension(defaultCoverFilename+"_"+uuid.New().String(), coverExtension, false)
 	downloadPath := filepath.Join(itemPath, downloadFilename)
 
-	// Download the cover art.
-	isExist, err := s.downloadAndSaveFile(ctx, coverURL, downloadPath, s.cfg.ReplaceCovers)
+	// Download the cover art, reusing the image if it was already fetched during this run.
+	var (
+		isExist  bool
+		isCached bool
+		err      error
+	)
+
+	if s.cfg.DryRun {
+		var result *DownloadResult
+
+		result, err = s.downloader.Download(ctx, &DownloadRequest{
+			Kind:            "cover",
+			Source:          &urlSource{client: s.zvukClient, url: coverURL},
+			DestinationPath: downloadPath,
+			Replace:         s.cfg.ReplaceCovers,
+		})
+		isExist = result != nil && result.IsExist
+	} else {
+		isCached, err = s.covers.copyTo(ctx, coverURL, downloadPath, s.downloadCoverFile)
+	}
+
 	if err != nil {
 		logger.Errorf(ctx, "Failed to download %s cover: %v", category.ToLowerCase(), err)
 		return "", ""
 	}
 
-	// Increment the cover skipped or downloaded counter.
-	if isExist {
+	// Increment the cover skipped, reused or downloaded counter.
+	switch {
+	case isExist:
 		logger.Infof(ctx, "%s cover already exists, skipping download", category.ToTitleCase())
 		s.incrementCoverSkipped()
-	} else {
+	case isCached:
+		logger.Infof(ctx, "Reused %s cover downloaded earlier in this run", category.ToLowerCase())
+		s.incrementCoverReused()
+	default:
 		logger.Infof(ctx, "Successfully downloaded %s cover", category.ToLowerCase())
 		s.incrementCoverDownloaded()
 	}
@@ -400,6 +424,48 @@ func (s *ServiceImpl) downloadCover(
 	return downloadPath, finalPath
 }
 
+// downloadCoverFile downloads a cover image into the cover cache.
+func (s *ServiceImpl) downloadCoverFile(ctx context.Context, url, destinationPath string) error {
+	_, err := s.downloader.Download(ctx, &DownloadRequest{
+		Kind:            "cover",
+		Source:          &urlSource{client: s.zvukClient, url: url},
+		DestinationPath: destinationPath,
+		Replace:         true,
+	})
+
+	return err
+}
+
+// getTrackAlbumCoverPath returns the cached cover of the track's album for embedding into a playlist track.
+// It returns an empty path if the album has no cover or it cannot be downloaded.
+func (s *ServiceImpl) getTrackAlbumCoverPath(ctx context.Context, t *downloadTrackTask) string {
+	if t.album == nil {
+		return ""
+	}
+
+	coverURL, coverExtension := s.resolveCoverURL(ctx, DownloadCategoryAlbum, s.albumHandler.GetCoverURL(t.album))
+	if coverURL == "" {
+		return ""
+	}
+
+	if !strings.HasPrefix(coverExtension, ".") {
+		coverExtension = "." + coverExtension
+	}
+
+	coverPath, isCached, err := s.covers.get(ctx, coverURL, coverExtension, s.downloadCoverFile)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to download album cover for track '%s': %v", t.track.Title, err)
+
+		return ""
+	}
+
+	if !isCached {
+		s.incrementCoverDownloaded()
+	}
+
+	return coverPath
+}
+
 // finalizeCover finalizes the album cover art.
 func (s *ServiceImpl) finalizeCover(
 	ctx context.Context,
@@ -444,17 +510,22 @@ func (s *ServiceImpl) saveDescription(
 	itemPath string,
 	description string,
 	descriptionFilename string,
+	tags map[string]string,
 ) (string, string) {
 	if description == "" {
 		return "", ""
 	}
 
+	extension := cmp.Or(s.cfg.DescriptionExtension, config.DefaultDescriptionExtension)
+
 	// Check if final destination already exists (to avoid inconsistent state).
+	// A single item saved without its own folder names the description after its track,
+	// otherwise description_filename_template is used.
 	var finalFilename string
 	if descriptionFilename != "" {
-		finalFilename = utils.SetFileExtension(descriptionFilename, extensionTXT, true)
+		finalFilename = utils.SetFileExtension(descriptionFilename, extension, true)
 	} else {
-		finalFilename = utils.SetFileExtension(defaultDescriptionFilename, extensionTXT, false)
+		finalFilename = utils.SetFileExtension(s.templateManager.GetDescriptionFilename(ctx, tags), extension, false)
 	}
 
 	finalPath := filepath.Join(itemPath, finalFilename)
@@ -474,22 +545,25 @@ func (s *ServiceImpl) saveDescription(
 	}
 
 	// Generate UUID-based temp filename to avoid concurrent download conflicts.
-	downloadFilename := utils.SetFileExtension(defaultDescriptionFilename+"_"+uuid.New().String(), extensionTXT, false)
+	downloadFilename := utils.SetFileExtension(defaultDescriptionFilename+"_"+uuid.New().String(), extension, false)
 	downloadPath := filepath.Join(itemPath, downloadFilename)
 
-	// Dry-run mode: simulate description save.
-	if s.cfg.DryRun {
-		logger.Infof(ctx, "[DRY-RUN] Would save %s description to: %s", category.ToLowerCase(), downloadFilename)
-		return downloadPath, finalPath
-	}
-
 	// Write description in UTF-8 encoding.
-	err = os.WriteFile(downloadPath, []byte(description), constants.DefaultFilePermissions)
+	_, err = s.downloader.Download(ctx, &DownloadRequest{
+		Kind:            "description",
+		Source:          textSource(description),
+		DestinationPath: downloadPath,
+		Replace:         true,
+	})
 	if err != nil {
 		logger.Errorf(ctx, "Failed to save %s description: %v", category.ToLowerCase(), err)
 		return "", ""
 	}
 
+	if s.cfg.DryRun {
+		return downloadPath, finalPath
+	}
+
 	logger.Infof(ctx, "Saved %s description to %s", category.ToLowerCase(), downloadFilename)
 	s.incrementDescriptionSaved()
 
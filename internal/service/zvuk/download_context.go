@@ -0,0 +1,127 @@
+package zvuk
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// traceIDLength is the number of characters of a trace ID, enough to tell the items of a run apart.
+const traceIDLength = 8
+
+// downloadContextKey is the key the download context is stored under in a context.Context.
+type downloadContextKey struct{}
+
+// DownloadContext describes the item being downloaded. It travels with the context.Context
+// through the service and the client calls, so every step knows which item it works for
+// without passing the item details one by one.
+type DownloadContext struct {
+	// TraceID identifies the item in the debug log, including the API requests made for it.
+	TraceID string
+	// Category is the type of the requested item (track, album, playlist, etc.).
+	Category DownloadCategory
+	// ItemID is the ID of the requested item.
+	ItemID string
+	// ItemURL is the URL the item was requested with.
+	ItemURL string
+	// ParentCategory is the type of the collection a track is saved into (empty for collections).
+	ParentCategory DownloadCategory
+	// ParentID is the ID of the collection a track is saved into.
+	ParentID string
+	// ParentTitle is the title of the collection a track is saved into.
+	ParentTitle string
+}
+
+// NewDownloadContext creates the download context of a requested item with a new trace ID.
+func NewDownloadContext(item *DownloadItem) *DownloadContext {
+	return &DownloadContext{
+		TraceID:  uuid.NewString()[:traceIDLength],
+		Category: item.Category,
+		ItemID:   item.ItemID,
+		ItemURL:  item.URL,
+	}
+}
+
+// WithDownloadContext returns a context carrying the download context.
+// In debug mode, the log lines written with the returned context carry the trace ID and the item.
+func WithDownloadContext(ctx context.Context, dc *DownloadContext) context.Context {
+	ctx = context.WithValue(ctx, downloadContextKey{}, dc)
+
+	if !logger.IsDebugLevel() {
+		return ctx
+	}
+
+	fields := []zap.Field{
+		zap.String("trace_id", dc.TraceID),
+		zap.String("item", dc.Category.ToLowerCase()+":"+dc.ItemID),
+	}
+
+	if dc.ParentID != "" && dc.ParentID != dc.ItemID {
+		fields = append(fields, zap.String("parent", dc.ParentCategory.ToLowerCase()+":"+dc.ParentID))
+	}
+
+	return logger.WithFields(ctx, fields...)
+}
+
+// DownloadContextFromContext returns the download context carried by the context, or nil.
+func DownloadContextFromContext(ctx context.Context) *DownloadContext {
+	dc, _ := ctx.Value(downloadContextKey{}).(*DownloadContext)
+
+	return dc
+}
+
+// withItemDownloadContext returns a context carrying a new download context of the item.
+func withItemDownloadContext(ctx context.Context, item *DownloadItem) context.Context {
+	return WithDownloadContext(ctx, NewDownloadContext(item))
+}
+
+// withTrackDownloadContext returns a context carrying the download context of a track of the task.
+// The trace ID of the requested item is kept, and the collection of the track is recorded.
+// Standalone tracks, downloaded in a batch, get a trace ID of their own.
+func withTrackDownloadContext(ctx context.Context, t *downloadTrackTask) context.Context {
+	dc := &DownloadContext{
+		Category:       DownloadCategoryTrack,
+		ItemID:         t.trackIDString,
+		ParentCategory: t.metadata.category,
+		ParentID:       t.parentID,
+		ParentTitle:    t.parentTitle,
+	}
+
+	if t.metadata.category == DownloadCategoryTrack {
+		dc.ParentCategory = DownloadCategoryAlbum
+	}
+
+	current := DownloadContextFromContext(ctx)
+	if current == nil || current.Category == DownloadCategoryTrack {
+		dc.TraceID = uuid.NewString()[:traceIDLength]
+	} else {
+		dc.TraceID = current.TraceID
+		dc.ItemURL = current.ItemURL
+	}
+
+	return WithDownloadContext(ctx, dc)
+}
+
+// newError returns a download error of the item described by the download context.
+// The title is used until the item title is known.
+func (dc *DownloadContext) newError(title, phase string, err error) *DownloadError {
+	return &DownloadError{
+		Category:       dc.Category,
+		ItemID:         dc.ItemID,
+		ItemTitle:      title,
+		ItemURL:        dc.ItemURL,
+		ParentCategory: dc.ParentCategory,
+		ParentID:       dc.ParentID,
+		ParentTitle:    dc.ParentTitle,
+		Phase:          phase,
+		Error:          err,
+	}
+}
+
+// configuredQuality returns the quality tracks are downloaded in.
+func (s *ServiceImpl) configuredQuality() TrackQuality {
+	return TrackQuality(s.cfg.Quality)
+}
@@ -0,0 +1,58 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloadContext verifies that the tracks of a collection keep the trace ID of the item,
+// while standalone tracks get trace IDs of their own.
+func TestDownloadContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, DownloadContextFromContext(context.Background()))
+
+	album := &DownloadItem{Category: DownloadCategoryAlbum, ItemID: "10", URL: "https://zvuk.com/release/10"}
+
+	dc := NewDownloadContext(album)
+
+	ctx := WithDownloadContext(context.Background(), dc)
+	require.Same(t, dc, DownloadContextFromContext(ctx))
+	assert.Len(t, dc.TraceID, traceIDLength)
+
+	// A new item does not keep the trace ID of the caller.
+	itemCtx := withItemDownloadContext(ctx, album)
+	itemDC := DownloadContextFromContext(itemCtx)
+	assert.NotEqual(t, dc.TraceID, itemDC.TraceID)
+
+	trackCtx := withTrackDownloadContext(itemCtx, &downloadTrackTask{
+		trackIDString: "1",
+		parentID:      "10",
+		parentTitle:   "Album",
+		metadata:      &downloadTracksMetadata{category: DownloadCategoryAlbum},
+	})
+	trackDC := DownloadContextFromContext(trackCtx)
+	assert.Equal(t, itemDC.TraceID, trackDC.TraceID)
+	assert.Equal(t, album.URL, trackDC.ItemURL)
+	assert.Equal(t, DownloadCategoryAlbum, trackDC.ParentCategory)
+
+	downloadErr := trackDC.newError("Song", "downloading track", errors.New("boom"))
+	assert.Equal(t, DownloadCategoryTrack, downloadErr.Category)
+	assert.Equal(t, "1", downloadErr.ItemID)
+	assert.Equal(t, "10", downloadErr.ParentID)
+	assert.Equal(t, "Album", downloadErr.ParentTitle)
+
+	// Standalone tracks are downloaded in a batch, so each gets its own trace ID.
+	standaloneCtx := withTrackDownloadContext(context.Background(), &downloadTrackTask{
+		trackIDString: "2",
+		parentID:      "20",
+		metadata:      &downloadTracksMetadata{category: DownloadCategoryTrack},
+	})
+	standaloneDC := DownloadContextFromContext(standaloneCtx)
+	assert.Len(t, standaloneDC.TraceID, traceIDLength)
+	assert.Equal(t, DownloadCategoryAlbum, standaloneDC.ParentCategory)
+}
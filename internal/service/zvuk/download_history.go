@@ -0,0 +1,384 @@
+package zvuk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// maxHistoryLineSize is the longest history record that is read back.
+const maxHistoryLineSize = 1 << 20
+
+// HistoryEntry is a saved track recorded in the download history.
+type HistoryEntry struct {
+	// TrackID is the unique identifier of the track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track.
+	Title string `json:"title"`
+	// Artists are the names of the track artists.
+	Artists []string `json:"artists,omitempty"`
+	// Album is the title of the release containing the track.
+	Album string `json:"album,omitempty"`
+	// Category is the type of item the track was downloaded with (album, playlist, track, etc.).
+	Category string `json:"category"`
+	// ParentID is the ID of the album, playlist, audiobook, or podcast the track was downloaded with.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the album, playlist, audiobook, or podcast the track was downloaded with.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// Position is the 1-based place of the track in the album, playlist, audiobook, or podcast
+	// it was downloaded with (0 for standalone tracks and records of earlier versions).
+	Position int64 `json:"position,omitempty"`
+	// Quality is the quality the track was saved in ("mid", "high", or "flac").
+	Quality string `json:"quality"`
+	// Path is where the track was saved.
+	Path string `json:"path"`
+	// Bytes is the size of the saved file.
+	Bytes int64 `json:"bytes"`
+	// DownloadedAt is when the track was saved.
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// HistoryQuery selects the entries of the download history.
+type HistoryQuery struct {
+	// Since keeps the tracks saved at or after this time (zero keeps all).
+	Since time.Time
+	// Artist keeps the tracks with an artist whose name contains this text, ignoring case (empty keeps all).
+	Artist string
+}
+
+// downloadHistory appends every saved track to a JSON Lines file, one record per line,
+// so a run interrupted at any point keeps the tracks saved so far.
+type downloadHistory struct {
+	// mutex protects the fields below.
+	mutex sync.Mutex
+	// path is the location of the history file.
+	path string
+	// isLoaded indicates that the file has been read into latestEntries.
+	isLoaded bool
+	// latestEntries maps a track ID to its latest record, used to skip tracks saved by earlier runs.
+	latestEntries map[string]*HistoryEntry
+}
+
+// newDownloadHistory creates a download history stored at path.
+func newDownloadHistory(path string) *downloadHistory {
+	return &downloadHistory{
+		path:          path,
+		latestEntries: make(map[string]*HistoryEntry),
+	}
+}
+
+// load reads the history file once, keeping the latest record of every track.
+func (h *downloadHistory) load(ctx context.Context) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.isLoaded {
+		return nil
+	}
+
+	entries, err := readHistoryEntries(ctx, h.path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		h.latestEntries[entry.TrackID] = entry
+	}
+
+	h.isLoaded = true
+
+	return nil
+}
+
+// add appends a record to the history file.
+func (h *downloadHistory) add(entry *HistoryEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if dir := filepath.Dir(h.path); dir != "" {
+		if err = os.MkdirAll(dir, constants.DefaultFolderPermissions); err != nil {
+			return fmt.Errorf("failed to create history folder: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(
+		filepath.Clean(h.path),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		constants.DefaultFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+
+	_, err = file.Write(append(content, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	if h.isLoaded {
+		h.latestEntries[entry.TrackID] = entry
+	}
+
+	return nil
+}
+
+// latest returns the latest record of a track, or nil if it was never saved.
+func (h *downloadHistory) latest(trackID string) *HistoryEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.latestEntries[trackID]
+}
+
+// QueryHistory returns the tracks of the download history matching the query, newest first.
+func QueryHistory(ctx context.Context, cfg *config.Config, query *HistoryQuery) ([]*HistoryEntry, error) {
+	entries, err := readHistoryEntries(ctx, cfg.HistoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	artist := strings.ToLower(strings.TrimSpace(query.Artist))
+
+	result := make([]*HistoryEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		if !query.Since.IsZero() && entry.DownloadedAt.Before(query.Since) {
+			continue
+		}
+
+		if artist != "" && !slices.ContainsFunc(entry.Artists, func(name string) bool {
+			return strings.Contains(strings.ToLower(name), artist)
+		}) {
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	slices.Reverse(result)
+
+	return result, nil
+}
+
+// RecentHistoryItem is an item downloaded recently, offered by shell completion.
+type RecentHistoryItem struct {
+	// URL is the link of the album, playlist, audiobook, podcast, or standalone track.
+	URL string
+	// Title is the human-readable title of the item.
+	Title string
+}
+
+// RecentHistoryItems returns the albums, playlists, audiobooks, podcasts, and standalone tracks
+// of the download history, most recently downloaded first, at most limit of them.
+func RecentHistoryItems(ctx context.Context, cfg *config.Config, limit int) ([]*RecentHistoryItem, error) {
+	entries, err := readHistoryEntries(ctx, cfg.HistoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = make([]*RecentHistoryItem, 0, min(limit, len(entries)))
+		seen   = make(map[string]struct{}, len(entries))
+	)
+
+	for _, entry := range slices.Backward(entries) {
+		if len(result) >= limit {
+			break
+		}
+
+		category, itemID, title := entry.Category, entry.ParentID, entry.ParentTitle
+		if category == DownloadCategoryTrack.ToLowerCase() || itemID == "" {
+			category, itemID, title = DownloadCategoryTrack.ToLowerCase(), entry.TrackID, entry.Title
+		}
+
+		kind, ok := identifierKinds[category]
+		if !ok || itemID == "" {
+			continue
+		}
+
+		itemURL := config.ZvukBaseURL + "/" + kind.pathSegment + "/" + itemID
+		if _, ok = seen[itemURL]; ok {
+			continue
+		}
+
+		seen[itemURL] = struct{}{}
+		result = append(result, &RecentHistoryItem{URL: itemURL, Title: title})
+	}
+
+	return result, nil
+}
+
+// recordHistory records a saved track in the download history.
+func (s *ServiceImpl) recordHistory(ctx context.Context, t *downloadTrackTask) {
+	if s.history == nil || s.cfg.DryRun {
+		return
+	}
+
+	entry := &HistoryEntry{
+		TrackID:      t.trackIDString,
+		Category:     t.metadata.category.ToLowerCase(),
+		ParentID:     t.parentID,
+		ParentTitle:  t.parentTitle,
+		Quality:      t.quality.AsStreamURLParameterValue(),
+		Path:         t.trackPath,
+		DownloadedAt: time.Now(),
+	}
+
+	// Standalone tracks are recorded with their albums, but have no place in them.
+	if t.metadata.audioCollection != nil {
+		entry.Position = t.trackIndex
+	}
+
+	// Tracks of a playlist saved into their album folders are recorded with the playlist, so it can be exported.
+	if playlist := t.metadata.libraryPlaylist; playlist != nil {
+		entry.Category = DownloadCategoryPlaylist.ToLowerCase()
+		entry.ParentID = playlist.id
+		entry.ParentTitle = playlist.title
+
+		if t.track != nil {
+			entry.Position = playlist.playlistPositions[t.track.ID]
+		}
+	}
+
+	if t.track != nil {
+		entry.Title = t.track.Title
+		entry.Artists = t.track.ArtistNames
+		entry.Album = t.track.ReleaseTitle
+	}
+
+	if info, err := os.Stat(t.trackPath); err == nil {
+		entry.Bytes = info.Size()
+	}
+
+	if err := s.history.add(entry); err != nil {
+		logger.Warnf(ctx, "Failed to record track '%s' in the download history: %v", entry.Title, err)
+	}
+}
+
+// skipDownloadedTrack reports whether the track was saved by an earlier run and its file still exists
+// in at least the quality that would be downloaded now (skip_downloaded_tracks).
+// The track is recorded as skipped, and its file is remembered for the playlist file and repeated tracks.
+func (s *ServiceImpl) skipDownloadedTrack(ctx context.Context, trackID int64, metadata *downloadTracksMetadata) bool {
+	if s.history == nil || !s.cfg.SkipDownloadedTracks {
+		return false
+	}
+
+	trackIDString := strconv.FormatInt(trackID, 10)
+
+	entry := s.history.latest(trackIDString)
+	if entry == nil {
+		return false
+	}
+
+	if _, err := os.Stat(entry.Path); err != nil {
+		return false
+	}
+
+	// A track is not downloaded again only because it is not available in the configured quality.
+	quality := ParseQuality(entry.Quality)
+	wantedQuality := s.configuredQuality()
+
+	track := metadata.tracksMetadata[trackIDString]
+	if track != nil {
+		if available := ParseQuality(track.HighestQuality); available != TrackQualityUnknown && available < wantedQuality {
+			wantedQuality = available
+		}
+	}
+
+	if quality < wantedQuality {
+		return false
+	}
+
+	title := entry.Title
+	if track != nil {
+		title = track.Title
+	}
+
+	logger.Debugf(ctx, "Track '%s' was saved to '%s' by an earlier run, skipping", title, entry.Path)
+
+	metadata.rememberSavedTrackPath(trackIDString, entry.Path, quality)
+
+	s.incrementTrackSkipped(trackIDString, SkipReasonDownloaded)
+	s.recordSkippedItem(&SkippedItem{
+		TrackID:        trackIDString,
+		Title:          title,
+		ParentCategory: metadata.category,
+		Reason:         SkipReasonDownloaded,
+	})
+
+	return true
+}
+
+// loadDownloadHistory reads the download history when tracks saved by earlier runs are skipped.
+func (s *ServiceImpl) loadDownloadHistory(ctx context.Context) error {
+	if s.history == nil || !s.cfg.SkipDownloadedTracks {
+		return nil
+	}
+
+	return s.history.load(ctx)
+}
+
+// readHistoryEntries reads the records of the history file in the order they were added.
+// A missing file is an empty history, and lines that cannot be decoded are skipped with a warning.
+func readHistoryEntries(ctx context.Context, path string) ([]*HistoryEntry, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		entries []*HistoryEntry
+		scanner = bufio.NewScanner(file)
+	)
+
+	scanner.Buffer(nil, maxHistoryLineSize)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// A line cut short by a crash or by a record being added meanwhile must not make the history unreadable.
+		entry := new(HistoryEntry)
+		if err = json.Unmarshal([]byte(line), entry); err != nil {
+			logger.Warnf(ctx, "Skipping damaged line %d of history '%s': %v", lineNumber, path, err)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,192 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestDownloadHistory tests that saved tracks are recorded and can be queried by time and artist.
+func TestDownloadHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath = t.TempDir()
+		cfg        = &config.Config{OutputPath: outputPath, HistoryPath: filepath.Join(t.TempDir(), "history.jsonl")}
+	)
+
+	impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	trackPath := filepath.Join(outputPath, "01 - Song.flac")
+	require.NoError(t, os.WriteFile(trackPath, []byte("audio"), constants.DefaultFilePermissions))
+
+	metadata := &downloadTracksMetadata{category: DownloadCategoryAlbum}
+
+	for _, track := range []*zvuk.Track{
+		{ID: 1, Title: "Song", ArtistNames: []string{"Alpha", "Beta"}, ReleaseTitle: "Album"},
+		{ID: 2, Title: "Other", ArtistNames: []string{"Gamma"}},
+	} {
+		impl.recordHistory(context.Background(), &downloadTrackTask{
+			trackIDString: strconv.FormatInt(track.ID, 10),
+			track:         track,
+			trackPath:     trackPath,
+			quality:       TrackQualityFLAC,
+			parentID:      "100",
+			parentTitle:   "Album",
+			metadata:      metadata,
+		})
+	}
+
+	entries, err := QueryHistory(t.Context(), cfg, &HistoryQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Other", entries[0].Title, "newest entries go first")
+
+	song := entries[1]
+	assert.Equal(t, "album", song.Category)
+	assert.Equal(t, "flac", song.Quality)
+	assert.Equal(t, "Album", song.Album)
+	assert.Equal(t, int64(len("audio")), song.Bytes)
+	assert.WithinDuration(t, time.Now(), song.DownloadedAt, time.Minute)
+
+	entries, err = QueryHistory(t.Context(), cfg, &HistoryQuery{Artist: " beta "})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Song", entries[0].Title)
+
+	entries, err = QueryHistory(t.Context(), cfg, &HistoryQuery{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A missing history is empty.
+	missingCfg := &config.Config{HistoryPath: filepath.Join(t.TempDir(), "missing.jsonl")}
+
+	entries, err = QueryHistory(t.Context(), missingCfg, &HistoryQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestReadHistoryEntriesSkipsDamagedLines tests that a damaged line does not hide the records around it.
+func TestReadHistoryEntriesSkipsDamagedLines(t *testing.T) {
+	t.Parallel()
+
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(historyPath, []byte(`{"track_id":"101","title":"Sonne"}`+"\n"+
+		`{"track_id":"102","tit`+"\n"+
+		`{"track_id":"103","title":"Mutter"}`+"\n"), constants.DefaultFilePermissions))
+
+	entries, err := readHistoryEntries(t.Context(), historyPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "101", entries[0].TrackID)
+	assert.Equal(t, "103", entries[1].TrackID)
+}
+
+// TestSkipDownloadedTrack tests that tracks saved by earlier runs are skipped while their files exist.
+func TestSkipDownloadedTrack(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath  = t.TempDir()
+		historyPath = filepath.Join(t.TempDir(), "history.jsonl")
+		flacPath    = filepath.Join(outputPath, "flac.flac")
+		mp3Path     = filepath.Join(outputPath, "mp3.mp3")
+	)
+
+	for _, trackPath := range []string{flacPath, mp3Path} {
+		require.NoError(t, os.WriteFile(trackPath, []byte("audio"), constants.DefaultFilePermissions))
+	}
+
+	history := newDownloadHistory(historyPath)
+	for _, entry := range []*HistoryEntry{
+		{TrackID: "1", Title: "Lossless", Quality: "flac", Path: flacPath},
+		{TrackID: "2", Title: "Lossy", Quality: "high", Path: mp3Path},
+		{TrackID: "3", Title: "Deleted", Quality: "flac", Path: filepath.Join(outputPath, "deleted.flac")},
+	} {
+		require.NoError(t, history.add(entry))
+	}
+
+	newService := func(t *testing.T, skipDownloadedTracks bool) *ServiceImpl {
+		t.Helper()
+
+		impl, ok := NewService(&config.Config{
+			OutputPath:           outputPath,
+			Quality:              uint8(TrackQualityFLAC),
+			HistoryPath:          historyPath,
+			SkipDownloadedTracks: skipDownloadedTracks,
+		}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+		require.NoError(t, impl.loadDownloadHistory(t.Context()))
+
+		return impl
+	}
+
+	metadata := &downloadTracksMetadata{
+		category: DownloadCategoryPlaylist,
+		tracksMetadata: map[string]*zvuk.Track{
+			"2": {ID: 2, Title: "Lossy", HighestQuality: "flac"},
+			"4": {ID: 4, Title: "MP3 only", HighestQuality: "high"},
+		},
+		keepSavedTracks: true,
+	}
+
+	disabled := newService(t, false)
+	assert.False(t, disabled.skipDownloadedTrack(context.Background(), 1, metadata))
+
+	impl := newService(t, true)
+	assert.True(t, impl.skipDownloadedTrack(context.Background(), 1, metadata))
+	assert.False(t, impl.skipDownloadedTrack(context.Background(), 2, metadata), "FLAC is available now")
+	assert.False(t, impl.skipDownloadedTrack(context.Background(), 3, metadata), "file is deleted")
+	assert.False(t, impl.skipDownloadedTrack(context.Background(), 5, metadata), "never saved")
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkippedDownloaded)
+
+	saved := metadata.getSavedTrack("1")
+	require.NotNil(t, saved)
+	assert.Equal(t, flacPath, saved.path)
+
+	// A track saved in MP3 is skipped when FLAC is not available.
+	require.NoError(t, impl.history.add(&HistoryEntry{TrackID: "4", Quality: "high", Path: mp3Path}))
+	assert.True(t, impl.skipDownloadedTrack(context.Background(), 4, metadata))
+}
+
+// TestRecentHistoryItems tests that the recently downloaded items are listed once, newest first.
+func TestRecentHistoryItems(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{HistoryPath: filepath.Join(t.TempDir(), "history.jsonl")}
+
+	history := newDownloadHistory(cfg.HistoryPath)
+	for _, entry := range []*HistoryEntry{
+		{TrackID: "1", Title: "First", Category: "album", ParentID: "100", ParentTitle: "Album"},
+		{TrackID: "2", Title: "Second", Category: "album", ParentID: "100", ParentTitle: "Album"},
+		{TrackID: "3", Title: "Single", Category: "track", ParentID: "300", ParentTitle: "Single Album"},
+		{TrackID: "4", Title: "Mix Track", Category: "playlist", ParentID: "400", ParentTitle: "Mix"},
+		{TrackID: "5", Title: "Chapter", Category: "audiobook", ParentID: "500", ParentTitle: "Book"},
+	} {
+		require.NoError(t, history.add(entry))
+	}
+
+	items, err := RecentHistoryItems(t.Context(), cfg, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*RecentHistoryItem{
+		{URL: "https://zvuk.com/abook/500", Title: "Book"},
+		{URL: "https://zvuk.com/playlist/400", Title: "Mix"},
+		{URL: "https://zvuk.com/track/3", Title: "Single"},
+		{URL: "https://zvuk.com/release/100", Title: "Album"},
+	}, items)
+
+	items, err = RecentHistoryItems(t.Context(), cfg, 2)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
@@ -0,0 +1,73 @@
+package zvuk
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// downloadOrder returns the indexes of the collection tracks in the order they are downloaded (download_order).
+// Only the order changes: every track keeps its index, so its number and playlist position stay the same.
+// Standalone tracks are downloaded in the order they were given.
+func (s *ServiceImpl) downloadOrder(metadata *downloadTracksMetadata) []int {
+	order := make([]int, len(metadata.trackIDs))
+	for index := range order {
+		order[index] = index
+	}
+
+	if metadata.audioCollection == nil {
+		return order
+	}
+
+	switch s.cfg.DownloadOrder {
+	case config.DownloadOrderAlphabetical:
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(
+				strings.ToLower(metadata.trackTitle(a)),
+				strings.ToLower(metadata.trackTitle(b)))
+		})
+	case config.DownloadOrderShuffle:
+		rand.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	case config.DownloadOrderSmallestFirst:
+		// File sizes are not known before the download, and at one quality they grow with the duration.
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(metadata.trackDuration(a), metadata.trackDuration(b))
+		})
+	}
+
+	return order
+}
+
+// trackTitle returns the title of the track at the given index, or an empty string when it is unknown.
+func (m *downloadTracksMetadata) trackTitle(index int) string {
+	if track := m.tracksMetadata[strconv.FormatInt(m.trackIDs[index], 10)]; track != nil {
+		return track.Title
+	}
+
+	return ""
+}
+
+// trackDuration returns the duration of the track at the given index in seconds, or 0 when it is unknown.
+func (m *downloadTracksMetadata) trackDuration(index int) int64 {
+	if track := m.tracksMetadata[strconv.FormatInt(m.trackIDs[index], 10)]; track != nil {
+		return track.Duration
+	}
+
+	return 0
+}
+
+// trackIDsAt returns the IDs of the tracks at the given indexes.
+func (m *downloadTracksMetadata) trackIDsAt(indexes []int) []int64 {
+	result := make([]int64, 0, len(indexes))
+	for _, index := range indexes {
+		result = append(result, m.trackIDs[index])
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package zvuk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestDownloadOrder tests the order in which the tracks of a collection are downloaded.
+func TestDownloadOrder(t *testing.T) {
+	t.Parallel()
+
+	newMetadata := func(collection *audioCollection) *downloadTracksMetadata {
+		return &downloadTracksMetadata{
+			audioCollection: collection,
+			trackIDs:        []int64{1, 2, 3, 4},
+			tracksMetadata: map[string]*zvuk.Track{
+				"1": {ID: 1, Title: "sonne", Duration: 272},
+				"2": {ID: 2, Title: "Links 2-3-4", Duration: 216},
+				"3": {ID: 3, Title: "Mutter", Duration: 216},
+				"4": {ID: 4, Title: "Feuer frei!", Duration: 188},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		order    string
+		expected []int
+	}{
+		{name: "position", order: config.DownloadOrderPosition, expected: []int{0, 1, 2, 3}},
+		{name: "alphabetical", order: config.DownloadOrderAlphabetical, expected: []int{3, 1, 2, 0}},
+		{name: "smallest first keeps ties in position order", order: config.DownloadOrderSmallestFirst,
+			expected: []int{3, 1, 2, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			impl, ok := NewService(&config.Config{DownloadOrder: tt.order}, nil, nil, nil, nil).(*ServiceImpl)
+			require.True(t, ok, "service must be of type *ServiceImpl")
+
+			assert.Equal(t, tt.expected, impl.downloadOrder(newMetadata(&audioCollection{})))
+
+			// Standalone tracks keep the order they were given in.
+			assert.Equal(t, []int{0, 1, 2, 3}, impl.downloadOrder(newMetadata(nil)))
+		})
+	}
+
+	t.Run("shuffle keeps every track", func(t *testing.T) {
+		t.Parallel()
+
+		impl, ok := NewService(&config.Config{DownloadOrder: config.DownloadOrderShuffle},
+			nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		order := impl.downloadOrder(newMetadata(&audioCollection{}))
+		assert.Equal(t, []int{0, 1, 2, 3}, slices.Sorted(slices.Values(order)))
+	})
+}
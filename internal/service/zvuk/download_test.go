@@ -3,6 +3,7 @@ package zvuk
 import (
 	"context"
 	"io"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
@@ -443,6 +445,112 @@ func TestDownloadTracks_ConcurrentLimitRespected(t *testing.T) {
 }
 
 // TestDownloadTracks_ConcurrentWithFewerTracks tests concurrent mode with fewer tracks than workers.
+// TestDownloadTracks_ConcurrentStartOrder tests that concurrent downloads start in the download order:
+// a freed slot goes to the next track in the order, not to whichever track grabs it first.
+func TestDownloadTracks_ConcurrentStartOrder(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_zvuk_client.NewMockClient(ctrl)
+	service := NewService(&config.Config{
+		OutputPath:             t.TempDir(),
+		MaxConcurrentDownloads: 2,
+		Quality:                3,
+		ParsedLogLevel:         logger.Level(),
+		ParsedMaxDownloadPause: time.Millisecond,
+	}, mockClient, new(mockURLProcessor), new(mockTemplateManager), new(mockTagProcessor))
+
+	var (
+		trackIDs       = []int64{401, 402, 403, 404, 405, 406}
+		tracksMetadata = make(map[string]*zvuk.Track, len(trackIDs))
+		started        = make(chan string, len(trackIDs))
+		releases       = make(map[string]chan struct{}, len(trackIDs))
+	)
+
+	for i, trackID := range trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+		streamURL := "/stream/" + trackIDString
+		release := make(chan struct{})
+
+		releases[trackIDString] = release
+		tracksMetadata[trackIDString] = &zvuk.Track{
+			ID:             trackID,
+			Title:          "Track " + trackIDString,
+			ReleaseID:      4,
+			Position:       int64(i + 1),
+			HighestQuality: "flac",
+			HasFLAC:        true,
+		}
+
+		mockClient.EXPECT().
+			GetStreamMetadata(gomock.Any(), trackIDString, TrackQualityFLACString).
+			DoAndReturn(func(context.Context, string, string) (*zvuk.StreamMetadata, error) {
+				started <- trackIDString
+				<-release
+
+				return &zvuk.StreamMetadata{Stream: streamURL}, nil
+			})
+		mockClient.EXPECT().
+			FetchTrack(gomock.Any(), streamURL).
+			Return(&zvuk.FetchTrackResult{Body: io.NopCloser(strings.NewReader("audio")), TotalBytes: 5}, nil)
+	}
+
+	metadata := &downloadTracksMetadata{
+		category:       DownloadCategoryAlbum,
+		trackIDs:       trackIDs,
+		tracksMetadata: tracksMetadata,
+		albumsMetadata: map[string]*zvuk.Release{
+			"4": {ID: 4, Title: "Order Test Album", LabelID: 999, TrackIDs: trackIDs},
+		},
+		albumsTags:     map[string]map[string]string{"4": {"albumTitle": "Order Test Album"}},
+		labelsMetadata: map[string]*zvuk.Label{"999": {Title: "Test Label"}},
+	}
+
+	impl, ok := service.(*ServiceImpl)
+	require.True(t, ok, "Service should be of type *ServiceImpl")
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		impl.downloadTracks(context.Background(), metadata)
+	}()
+
+	receiveStart := func() string {
+		select {
+		case trackID := <-started:
+			return trackID
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no track started")
+
+			return ""
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"401", "402"}, []string{receiveStart(), receiveStart()})
+
+	// Every finished track frees its slot for the next track in the order.
+	for _, step := range []struct {
+		finished string
+		expected string
+	}{
+		{finished: "402", expected: "403"},
+		{finished: "401", expected: "404"},
+		{finished: "404", expected: "405"},
+		{finished: "403", expected: "406"},
+	} {
+		close(releases[step.finished])
+		assert.Equal(t, step.expected, receiveStart())
+	}
+
+	close(releases["405"])
+	close(releases["406"])
+	<-done
+}
+
 func TestDownloadTracks_ConcurrentWithFewerTracks(t *testing.T) {
 	t.Parallel()
 
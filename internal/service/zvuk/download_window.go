@@ -0,0 +1,80 @@
+package zvuk
+
+import (
+	"context"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// waitForDownloadWindow blocks outside the download_window hours until the next window opens.
+// The transfers in progress are finished, and only new ones wait. Dry runs never wait.
+// It returns false if the context is canceled while waiting.
+func (s *ServiceImpl) waitForDownloadWindow(ctx context.Context) bool {
+	windows := s.cfg.ParsedDownloadWindows
+	if len(windows) == 0 || s.cfg.DryRun {
+		return true
+	}
+
+	isWaiting := false
+
+	for {
+		now := time.Now()
+		if config.IsInDownloadWindows(windows, now) {
+			break
+		}
+
+		resumeTime := config.NextDownloadWindowStart(windows, now)
+		s.reportDownloadWindowPause(ctx, resumeTime)
+
+		isWaiting = true
+
+		// The window is checked again after waking up, in case the clock was changed.
+		timer := time.NewTimer(time.Until(resumeTime))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return false
+		case <-timer.C:
+		}
+	}
+
+	if isWaiting {
+		s.reportDownloadWindowResume(ctx)
+	}
+
+	return true
+}
+
+// reportDownloadWindowPause logs that the downloads are paused until the resume time,
+// once for all the tracks waiting for the same window.
+func (s *ServiceImpl) reportDownloadWindowPause(ctx context.Context, resumeTime time.Time) {
+	s.downloadWindowMutex.Lock()
+	defer s.downloadWindowMutex.Unlock()
+
+	if s.downloadWindowResumeTime.Equal(resumeTime) {
+		return
+	}
+
+	s.downloadWindowResumeTime = resumeTime
+
+	logger.Infof(ctx, "Outside the download window (%s), downloads are paused until %s",
+		s.cfg.DownloadWindow, resumeTime.Format(time.DateTime))
+}
+
+// reportDownloadWindowResume logs that the downloads resume, once for all the tracks that waited.
+func (s *ServiceImpl) reportDownloadWindowResume(ctx context.Context) {
+	s.downloadWindowMutex.Lock()
+	defer s.downloadWindowMutex.Unlock()
+
+	if s.downloadWindowResumeTime.IsZero() {
+		return
+	}
+
+	s.downloadWindowResumeTime = time.Time{}
+
+	logger.Info(ctx, "Download window is open, resuming downloads")
+}
@@ -0,0 +1,73 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// TestWaitForDownloadWindow tests that the tracks wait outside download_window until it opens.
+func TestWaitForDownloadWindow(t *testing.T) {
+	t.Parallel()
+
+	newService := func(t *testing.T, isDryRun bool, windows ...config.DownloadWindow) *ServiceImpl {
+		t.Helper()
+
+		impl, ok := NewService(&config.Config{
+			DownloadWindow:        "test window",
+			ParsedDownloadWindows: windows,
+			DryRun:                isDryRun,
+		}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		return impl
+	}
+
+	// The window opening an hour from now and closing an hour later never contains the present.
+	year, month, day := time.Now().Date()
+	sinceMidnight := time.Since(time.Date(year, month, day, 0, 0, 0, 0, time.Local))
+	closed := config.DownloadWindow{
+		Start: (sinceMidnight + time.Hour) % (24 * time.Hour),
+		End:   (sinceMidnight + 2*time.Hour) % (24 * time.Hour),
+	}
+
+	t.Run("no window", func(t *testing.T) {
+		t.Parallel()
+
+		assert.True(t, newService(t, false).waitForDownloadWindow(context.Background()))
+	})
+
+	t.Run("dry run does not wait", func(t *testing.T) {
+		t.Parallel()
+
+		assert.True(t, newService(t, true, closed).waitForDownloadWindow(context.Background()))
+	})
+
+	t.Run("inside the window", func(t *testing.T) {
+		t.Parallel()
+
+		open := config.DownloadWindow{Start: 0, End: 24 * time.Hour}
+		assert.True(t, newService(t, false, open).waitForDownloadWindow(context.Background()))
+	})
+
+	t.Run("outside the window waits until canceled", func(t *testing.T) {
+		t.Parallel()
+
+		var console bytes.Buffer
+
+		ctx, cancel := context.WithTimeout(logger.ToContext(context.Background(), logger.NewPlain(&console)),
+			50*time.Millisecond)
+		defer cancel()
+
+		impl := newService(t, false, closed)
+		assert.False(t, impl.waitForDownloadWindow(ctx))
+		assert.Contains(t, console.String(), "Outside the download window (test window), downloads are paused until")
+	})
+}
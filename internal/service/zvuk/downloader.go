@@ -0,0 +1,355 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// Kinds of the assets saved for every track.
+const (
+	// downloadKindTrack is the kind of the track audio.
+	downloadKindTrack = "track"
+	// downloadKindLyrics is the kind of the track lyrics.
+	downloadKindLyrics = "lyrics"
+)
+
+// Downloader saves track audio, covers, lyrics, and descriptions to files.
+// Every asset type follows the same rules: an existing file is kept unless replacing is allowed,
+// dry-run mode only reports what would be saved, content is written to a temporary file
+// in the destination folder before it is moved into place, transfers respect the download speed limit,
+// and failed transfers are retried up to retry_attempts_count times.
+type Downloader interface {
+	// Download saves the content described by the request.
+	Download(ctx context.Context, request *DownloadRequest) (*DownloadResult, error)
+}
+
+// DownloadSource provides the content of a saved asset.
+type DownloadSource interface {
+	// Open opens the content and returns its size in bytes (0 if unknown).
+	Open(ctx context.Context) (io.ReadCloser, int64, error)
+	// Size returns the content size without transferring it, used in dry-run mode (0 if unknown).
+	Size(ctx context.Context) (int64, error)
+}
+
+// DownloadRequest describes a single asset to save.
+type DownloadRequest struct {
+	// Kind names the asset in log messages (e.g., "track", "lyrics").
+	Kind string
+	// Source provides the content.
+	Source DownloadSource
+	// DestinationPath is the final location of the asset.
+	DestinationPath string
+	// Replace indicates whether an existing file at DestinationPath is overwritten.
+	Replace bool
+	// KeepTempFile leaves the completed temporary file for the caller to move into place
+	// instead of renaming it to DestinationPath (tracks get their tags written first).
+	KeepTempFile bool
+	// Progress, if set, is called when a transfer starts. It returns a writer receiving
+	// the transferred bytes and a function called when the transfer ends.
+	Progress func(totalBytes int64) (io.Writer, func())
+}
+
+// DownloadResult describes the outcome of a download.
+type DownloadResult struct {
+	// IsExist indicates that the destination already existed and nothing was saved.
+	IsExist bool
+	// Path is the saved file: the temporary file with KeepTempFile, DestinationPath otherwise.
+	// It is empty when nothing was saved (existing file or dry-run mode).
+	Path string
+	// Bytes is the number of bytes saved, or the reported content size in dry-run mode.
+	Bytes int64
+}
+
+// FileDownloader implements Downloader on the local file system.
+type FileDownloader struct {
+	// cfg contains the application configuration.
+	cfg *config.Config
+	// speedLimit is the download speed limit in bytes per second (0 is unlimited),
+	// changed during the run by reload_config.
+	speedLimit atomic.Int64
+}
+
+// speedLimitSetter is a downloader whose speed limit can be changed during the run.
+type speedLimitSetter interface {
+	// setSpeedLimit changes the download speed limit in bytes per second (0 is unlimited).
+	setSpeedLimit(limit int64)
+}
+
+// NewDownloader creates a downloader using the dry-run, speed limit, and retry settings of the configuration.
+func NewDownloader(cfg *config.Config) Downloader {
+	d := &FileDownloader{cfg: cfg}
+	d.speedLimit.Store(cfg.ParsedDownloadSpeedLimit)
+
+	return d
+}
+
+// setSpeedLimit changes the download speed limit, picked up by the throttled transfers within a second.
+func (d *FileDownloader) setSpeedLimit(limit int64) {
+	d.speedLimit.Store(limit)
+}
+
+// Download saves the content described by the request.
+func (d *FileDownloader) Download(ctx context.Context, request *DownloadRequest) (*DownloadResult, error) {
+	kind := strings.ToUpper(request.Kind[:1]) + request.Kind[1:]
+
+	// The dry-run plan lists the tracks and their lyrics, so their lines are left to the debug level.
+	dryRunLogf := logger.Infof
+	if isDryRunPlanPrinted(d.cfg) && (request.Kind == downloadKindTrack || request.Kind == downloadKindLyrics) {
+		dryRunLogf = logger.Debugf
+	}
+
+	if !request.Replace {
+		if _, err := os.Stat(request.DestinationPath); err == nil {
+			if d.cfg.DryRun {
+				dryRunLogf(ctx, "[DRY-RUN] %s '%s' already exists, would skip", kind, request.DestinationPath)
+			} else {
+				logger.Infof(ctx, "%s '%s' already exists, skipping download", kind, request.DestinationPath)
+			}
+
+			return &DownloadResult{IsExist: true}, nil
+		}
+	}
+
+	// Dry-run mode: report the content size without transferring it.
+	if d.cfg.DryRun {
+		dryRunLogf(ctx, "[DRY-RUN] Would save %s to: %s", request.Kind, request.DestinationPath)
+
+		size, err := request.Source.Size(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s size: %w", request.Kind, err)
+		}
+
+		return &DownloadResult{Bytes: size}, nil
+	}
+
+	tempPath, bytesWritten, err := d.transferWithRetries(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.KeepTempFile {
+		return &DownloadResult{Path: tempPath, Bytes: bytesWritten}, nil
+	}
+
+	if err = utils.RenameFile(tempPath, request.DestinationPath, request.Replace); err != nil {
+		_ = os.Remove(tempPath)
+
+		// Another run or worker saved the file in the meantime.
+		if errors.Is(err, os.ErrExist) {
+			logger.Infof(ctx, "%s '%s' already exists, skipping download", kind, request.DestinationPath)
+
+			return &DownloadResult{IsExist: true}, nil
+		}
+
+		return nil, fmt.Errorf("failed to move %s into place: %w", request.Kind, err)
+	}
+
+	return &DownloadResult{Path: request.DestinationPath, Bytes: bytesWritten}, nil
+}
+
+// transferWithRetries transfers the content into a temporary file, retrying failed attempts.
+func (d *FileDownloader) transferWithRetries(ctx context.Context, request *DownloadRequest) (string, int64, error) {
+	attemptsCount := max(d.cfg.RetryAttemptsCount, 1)
+
+	for attempt := int64(1); ; attempt++ {
+		tempPath, bytesWritten, err := d.transfer(ctx, request)
+		if err == nil {
+			return tempPath, bytesWritten, nil
+		}
+
+		if attempt >= attemptsCount || ctx.Err() != nil {
+			return "", 0, err
+		}
+
+		logger.Infof(ctx, "Retrying %s download due to error (%d attempts left): %v",
+			request.Kind, attemptsCount-attempt, err)
+		utils.RandomPause(d.cfg.ParsedMinRetryPause, d.cfg.ParsedMaxRetryPause)
+	}
+}
+
+// transfer copies the content into a new temporary file next to the destination.
+// The temporary file is removed if the transfer fails.
+func (d *FileDownloader) transfer(ctx context.Context, request *DownloadRequest) (string, int64, error) {
+	reader, totalBytes, err := request.Source.Open(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch %s: %w", request.Kind, err)
+	}
+
+	defer reader.Close() //nolint:errcheck // Error on close is not critical here.
+
+	// Use unique temp files per worker to avoid concurrent write collisions.
+	file, err := os.CreateTemp(
+		filepath.Dir(request.DestinationPath),
+		filepath.Base(request.DestinationPath)+".part-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary %s file: %w", request.Kind, err)
+	}
+
+	tempPath := file.Name()
+
+	bytesWritten, err := d.writeTempFile(file, reader, totalBytes, request)
+	if err != nil {
+		// Small delay to ensure file handle is released (Windows needs this).
+		time.Sleep(10 * time.Millisecond)
+
+		if removeErr := os.Remove(tempPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			// Log warning but don't fail - this is best-effort cleanup.
+			logger.Warnf(ctx, "Failed to clean up temporary file '%s': %v", tempPath, removeErr)
+		}
+
+		return "", 0, err
+	}
+
+	return tempPath, bytesWritten, nil
+}
+
+// writeTempFile copies the content into the file, verifies its size, and closes the file.
+func (d *FileDownloader) writeTempFile(
+	file *os.File,
+	reader io.Reader,
+	totalBytes int64,
+	request *DownloadRequest,
+) (int64, error) {
+	if err := file.Chmod(constants.DefaultFilePermissions); err != nil {
+		_ = file.Close()
+
+		return 0, fmt.Errorf("failed to set temporary file permissions: %w", err)
+	}
+
+	var writer io.Writer = file
+
+	if request.Progress != nil {
+		progress, finish := request.Progress(totalBytes)
+		defer finish()
+
+		writer = io.MultiWriter(file, progress)
+	}
+
+	bytesWritten, err := copyWithSpeedLimit(writer, reader, d.speedLimit.Load)
+	if err != nil {
+		_ = file.Close()
+
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Verify that we downloaded the expected number of bytes.
+	if totalBytes > 0 && bytesWritten != totalBytes {
+		_ = file.Close()
+
+		return 0, fmt.Errorf("%w: wrote %d bytes, expected %d bytes", ErrIncompleteDownload, bytesWritten, totalBytes)
+	}
+
+	// Force flush all buffered data to disk.
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+
+		return 0, err
+	}
+
+	// The file must be fully written and closed before any other goroutine reads it
+	// (e.g., for embedding covers), so it is closed here rather than deferred.
+	if err = file.Close(); err != nil {
+		return 0, err
+	}
+
+	return bytesWritten, nil
+}
+
+// copyWithSpeedLimit copies from reader to writer, transferring at most limit() bytes per second (0 is unlimited).
+// A throttled transfer reads the limit again every second, so a changed limit applies to it at once,
+// while an unlimited transfer keeps its speed to the end.
+func copyWithSpeedLimit(writer io.Writer, reader io.Reader, limit func() int64) (int64, error) {
+	var bytesWritten int64
+
+	for {
+		bytesPerSecond := limit()
+		if bytesPerSecond == 0 {
+			n, err := io.Copy(writer, reader)
+
+			return bytesWritten + n, err
+		}
+
+		n, err := io.CopyN(writer, reader, bytesPerSecond)
+		bytesWritten += n
+
+		if errors.Is(err, io.EOF) {
+			return bytesWritten, nil
+		}
+
+		if err != nil {
+			return bytesWritten, err
+		}
+
+		// Throttle to respect speed limit.
+		time.Sleep(time.Second)
+	}
+}
+
+// urlSource downloads the content from a URL via the Zvuk client.
+type urlSource struct {
+	// client is the Zvuk API client.
+	client zvuk.Client
+	// url is the address of the content.
+	url string
+}
+
+// Open opens the content; its size is not known in advance.
+func (s *urlSource) Open(ctx context.Context) (io.ReadCloser, int64, error) {
+	reader, err := s.client.DownloadFromURL(ctx, s.url)
+
+	return reader, 0, err
+}
+
+// Size returns 0: the size of small assets is not estimated in dry-run mode.
+func (s *urlSource) Size(context.Context) (int64, error) {
+	return 0, nil
+}
+
+// trackStreamSource downloads track audio from its stream URL.
+type trackStreamSource struct {
+	// client is the Zvuk API client.
+	client zvuk.Client
+	// url is the stream URL of the track.
+	url string
+}
+
+// Open opens the audio stream.
+func (s *trackStreamSource) Open(ctx context.Context) (io.ReadCloser, int64, error) {
+	result, err := s.client.FetchTrack(ctx, s.url)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result.Body, result.TotalBytes, nil
+}
+
+// Size asks the server for the audio size without opening the stream.
+func (s *trackStreamSource) Size(ctx context.Context) (int64, error) {
+	return s.client.GetFileSize(ctx, s.url)
+}
+
+// textSource provides generated text content, such as lyrics and descriptions.
+type textSource string
+
+// Open returns a reader of the text.
+func (s textSource) Open(context.Context) (io.ReadCloser, int64, error) {
+	return io.NopCloser(strings.NewReader(string(s))), int64(len(s)), nil
+}
+
+// Size returns the size of the text in bytes.
+func (s textSource) Size(context.Context) (int64, error) {
+	return int64(len(s)), nil
+}
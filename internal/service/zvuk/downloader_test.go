@@ -0,0 +1,164 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// flakySource fails the first failuresCount attempts to open the content.
+type flakySource struct {
+	content       string
+	failuresCount int
+	attempts      int
+}
+
+// Open fails until the configured number of failures has been reached.
+func (s *flakySource) Open(context.Context) (io.ReadCloser, int64, error) {
+	s.attempts++
+	if s.attempts <= s.failuresCount {
+		return nil, 0, errors.New("connection reset")
+	}
+
+	return io.NopCloser(strings.NewReader(s.content)), int64(len(s.content)), nil
+}
+
+// Size returns the content size.
+func (s *flakySource) Size(context.Context) (int64, error) {
+	return int64(len(s.content)), nil
+}
+
+// TestFileDownloader_ReplaceSemantics verifies that existing files are kept unless replacing is allowed.
+func TestFileDownloader_ReplaceSemantics(t *testing.T) {
+	t.Parallel()
+
+	destinationPath := filepath.Join(t.TempDir(), "lyrics.lrc")
+	require.NoError(t, os.WriteFile(destinationPath, []byte("old"), constants.DefaultFilePermissions))
+
+	downloader := NewDownloader(&config.Config{})
+
+	result, err := downloader.Download(context.Background(), &DownloadRequest{
+		Kind:            "lyrics",
+		Source:          textSource("new"),
+		DestinationPath: destinationPath,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsExist)
+
+	content, err := os.ReadFile(destinationPath)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+
+	result, err = downloader.Download(context.Background(), &DownloadRequest{
+		Kind:            "lyrics",
+		Source:          textSource("new"),
+		DestinationPath: destinationPath,
+		Replace:         true,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsExist)
+	assert.Equal(t, destinationPath, result.Path)
+	assert.Equal(t, int64(3), result.Bytes)
+
+	content, err = os.ReadFile(destinationPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+}
+
+// TestFileDownloader_KeepTempFile verifies that the completed temporary file is left for the caller.
+func TestFileDownloader_KeepTempFile(t *testing.T) {
+	t.Parallel()
+
+	destinationPath := filepath.Join(t.TempDir(), "01 - Track.flac")
+
+	result, err := NewDownloader(&config.Config{}).Download(context.Background(), &DownloadRequest{
+		Kind:            "track",
+		Source:          textSource("audio"),
+		DestinationPath: destinationPath,
+		KeepTempFile:    true,
+	})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, destinationPath, result.Path)
+	assert.True(t, strings.HasPrefix(filepath.Base(result.Path), "01 - Track.flac.part-"))
+	assert.FileExists(t, result.Path)
+	assert.NoFileExists(t, destinationPath)
+}
+
+// TestFileDownloader_DryRun verifies that dry-run mode reports the size without saving anything.
+func TestFileDownloader_DryRun(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	destinationPath := filepath.Join(tempDir, "description.txt")
+
+	result, err := NewDownloader(&config.Config{DryRun: true}).Download(context.Background(), &DownloadRequest{
+		Kind:            "description",
+		Source:          textSource("About the book"),
+		DestinationPath: destinationPath,
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Path)
+	assert.Equal(t, int64(len("About the book")), result.Bytes)
+
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestFileDownloader_Retries verifies that failed transfers are retried up to retry_attempts_count times.
+func TestFileDownloader_Retries(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		RetryAttemptsCount:  3,
+		ParsedMinRetryPause: time.Millisecond,
+		ParsedMaxRetryPause: 2 * time.Millisecond,
+	}
+
+	t.Run("succeeds within attempts", func(t *testing.T) {
+		t.Parallel()
+
+		source := &flakySource{content: "image", failuresCount: 2}
+
+		result, err := NewDownloader(cfg).Download(context.Background(), &DownloadRequest{
+			Kind:            "cover",
+			Source:          source,
+			DestinationPath: filepath.Join(t.TempDir(), "cover.jpg"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, source.attempts)
+		assert.FileExists(t, result.Path)
+	})
+
+	t.Run("fails after attempts", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		source := &flakySource{content: "image", failuresCount: 3}
+
+		_, err := NewDownloader(cfg).Download(context.Background(), &DownloadRequest{
+			Kind:            "cover",
+			Source:          source,
+			DestinationPath: filepath.Join(tempDir, "cover.jpg"),
+		})
+		require.Error(t, err)
+		assert.Equal(t, 3, source.attempts)
+
+		entries, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "no temporary files should be left behind")
+	})
+}
@@ -0,0 +1,331 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// Actions of the tracks listed in the dry-run plan.
+const (
+	// dryRunActionDownload is the action of a track that would be downloaded.
+	dryRunActionDownload = "download"
+	// dryRunActionLink is the action of a repeated playlist track that would be hardlinked to its first occurrence.
+	dryRunActionLink = "link"
+	// dryRunActionFail is the action of a track that failed to be checked.
+	dryRunActionFail = "fail"
+	// dryRunActionNotSelected is the action of a track left out by --tracks or in the track picker.
+	dryRunActionNotSelected = "not selected"
+	// dryRunActionNotStarted is the action of a track that was not reached, e.g., because of the run time limit.
+	dryRunActionNotStarted = "not started"
+)
+
+// dryRunPlanCSVHeader is the header printed above the CSV rows of the first collection.
+var dryRunPlanCSVHeader = []string{
+	"category",
+	"collection_id",
+	"collection",
+	"position",
+	"track_id",
+	"title",
+	"quality",
+	"size_bytes",
+	"duration_seconds",
+	"action",
+}
+
+// dryRunPlan lists what a dry run would do with every track of a collection.
+type dryRunPlan struct {
+	// Category is the category of the collection (track for standalone tracks).
+	Category DownloadCategory `json:"category"`
+	// CollectionID is the ID of the collection (empty for standalone tracks).
+	CollectionID string `json:"collection_id,omitempty"`
+	// Collection is the title of the collection (empty for standalone tracks).
+	Collection string `json:"collection,omitempty"`
+	// Tracks are the tracks of the collection in their order in the collection.
+	Tracks []*dryRunPlanTrack `json:"tracks"`
+	// SizeBytes is the estimated size of the tracks that would be downloaded.
+	SizeBytes int64 `json:"size_bytes"`
+	// DurationSeconds is the total duration of the tracks that would be downloaded.
+	DurationSeconds int64 `json:"duration_seconds"`
+}
+
+// dryRunPlanTrack is a track listed in the dry-run plan.
+type dryRunPlanTrack struct {
+	// Position is the 1-based position of the track in the collection.
+	Position int `json:"position"`
+	// TrackID is the ID of the track.
+	TrackID string `json:"track_id"`
+	// Title is the title of the track.
+	Title string `json:"title"`
+	// Quality is the short name of the quality the track would be downloaded in (empty unless it is downloaded).
+	Quality string `json:"quality,omitempty"`
+	// SizeBytes is the estimated size of the track (0 unless it is downloaded).
+	SizeBytes int64 `json:"size_bytes"`
+	// DurationSeconds is the duration of the track.
+	DurationSeconds int64 `json:"duration_seconds"`
+	// Action is what the run would do with the track: download, skip with the reason, link, etc.
+	Action string `json:"action"`
+}
+
+// dryRunPlanStart is where the records of a collection start in the statistics.
+type dryRunPlanStart struct {
+	// downloadedTracks is the number of downloaded tracks recorded before the collection.
+	downloadedTracks int
+	// skippedItems is the number of skipped tracks recorded before the collection.
+	skippedItems int
+	// errors is the number of errors recorded before the collection.
+	errors int
+}
+
+// dryRunPlanPrinter prints the dry-run plan of every collection instead of a log line per track.
+type dryRunPlanPrinter struct {
+	// w receives the plans.
+	w io.Writer
+	// format is the format of the plans: table, json, or csv.
+	format string
+	// isCSVHeaderPrinted indicates that the CSV header was printed above the first plan.
+	isCSVHeaderPrinted bool
+	// mutex keeps the plans of different collections from being interleaved.
+	mutex sync.Mutex
+}
+
+// isDryRunPlanPrinted reports whether the tracks of dry runs are listed in plans.
+// With --json, the tracks that would be downloaded are a part of the JSON document instead.
+func isDryRunPlanPrinted(cfg *config.Config) bool {
+	return cfg.DryRun && !cfg.JSONOutput
+}
+
+// newDryRunPlanPrinter creates a printer writing the dry-run plans to w in the given format.
+func newDryRunPlanPrinter(w io.Writer, format string) *dryRunPlanPrinter {
+	return &dryRunPlanPrinter{w: w, format: format}
+}
+
+// logTrackf logs a line about a single track, left to the debug level when the dry-run plan lists the tracks.
+func (s *ServiceImpl) logTrackf(ctx context.Context, format string, args ...any) {
+	if s.dryRunPlan != nil {
+		logger.Debugf(ctx, format, args...)
+
+		return
+	}
+
+	logger.Infof(ctx, format, args...)
+}
+
+// getDryRunPlanStart returns where the records of the collection about to be downloaded start in the statistics.
+func (s *ServiceImpl) getDryRunPlanStart() dryRunPlanStart {
+	var start dryRunPlanStart
+	if s.dryRunPlan == nil {
+		return start
+	}
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		start = dryRunPlanStart{
+			downloadedTracks: len(stats.DownloadedTracks),
+			skippedItems:     len(stats.SkippedItems),
+			errors:           len(stats.Errors),
+		}
+	})
+
+	return start
+}
+
+// printDryRunPlan prints the plan of the collection built from the records made since start.
+func (s *ServiceImpl) printDryRunPlan(ctx context.Context, metadata *downloadTracksMetadata, start dryRunPlanStart) {
+	if s.dryRunPlan == nil {
+		return
+	}
+
+	records := new(DownloadStatistics)
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		records.DownloadedTracks = slices.Clone(stats.DownloadedTracks[start.downloadedTracks:])
+		records.SkippedItems = slices.Clone(stats.SkippedItems[start.skippedItems:])
+		records.Errors = slices.Clone(stats.Errors[start.errors:])
+	})
+
+	plan := buildDryRunPlan(metadata, records, s.cfg.PlaylistDuplicates == config.PlaylistDuplicatesHardlink)
+	if err := s.dryRunPlan.print(plan); err != nil {
+		logger.Warnf(ctx, "Failed to print the dry-run plan: %v", err)
+	}
+}
+
+// buildDryRunPlan lists the tracks of the collection with the outcomes recorded for them.
+// A track repeated in a playlist takes the outcomes of its track ID in turn,
+// and a repeat left without one is hardlinked when isHardlinked is set.
+func buildDryRunPlan(
+	metadata *downloadTracksMetadata,
+	records *DownloadStatistics,
+	isHardlinked bool,
+) *dryRunPlan {
+	plan := &dryRunPlan{
+		Category: metadata.category,
+		Tracks:   make([]*dryRunPlanTrack, 0, len(metadata.trackIDs)),
+	}
+
+	if collection := metadata.audioCollection; collection != nil {
+		plan.CollectionID = collection.id
+		plan.Collection = collection.title
+	}
+
+	outcomes := make(map[string][]*dryRunPlanTrack, len(metadata.trackIDs))
+
+	for _, track := range records.DownloadedTracks {
+		outcomes[track.TrackID] = append(outcomes[track.TrackID], &dryRunPlanTrack{
+			Title:     track.Title,
+			Quality:   formatPickerQuality(track.Quality),
+			SizeBytes: track.Bytes,
+			Action:    dryRunActionDownload,
+		})
+	}
+
+	for _, item := range records.SkippedItems {
+		outcomes[item.TrackID] = append(outcomes[item.TrackID], &dryRunPlanTrack{
+			Title:  item.Title,
+			Action: "skip (" + item.Reason.String() + ")",
+		})
+	}
+
+	failedTracks := make(map[string]struct{})
+
+	for _, e := range records.Errors {
+		if e.Category == DownloadCategoryTrack {
+			failedTracks[e.ItemID] = struct{}{}
+		}
+	}
+
+	for i, trackID := range metadata.trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+		track := &dryRunPlanTrack{Action: dryRunActionNotStarted}
+
+		_, isFailed := failedTracks[trackIDString]
+
+		switch queue := outcomes[trackIDString]; {
+		case metadata.isDeselected(i):
+			track.Action = dryRunActionNotSelected
+		case len(queue) > 0:
+			track, outcomes[trackIDString] = queue[0], queue[1:]
+		case metadata.isDuplicate(i) && isHardlinked:
+			track.Action = dryRunActionLink
+		case isFailed:
+			track.Action = dryRunActionFail
+		}
+
+		track.Position = i + 1
+		track.TrackID = trackIDString
+		track.DurationSeconds = metadata.trackDuration(i)
+
+		if title := metadata.trackTitle(i); title != "" {
+			track.Title = title
+		}
+
+		if track.Action == dryRunActionDownload {
+			plan.SizeBytes += track.SizeBytes
+			plan.DurationSeconds += track.DurationSeconds
+		}
+
+		plan.Tracks = append(plan.Tracks, track)
+	}
+
+	return plan
+}
+
+// print prints the plan in the format of the printer.
+func (p *dryRunPlanPrinter) print(plan *dryRunPlan) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch p.format {
+	case config.DryRunFormatJSON:
+		return json.NewEncoder(p.w).Encode(plan)
+	case config.DryRunFormatCSV:
+		return p.printCSV(plan)
+	default:
+		return p.printTable(plan)
+	}
+}
+
+// printTable prints the plan as an aligned table under the title of the collection, with a total row.
+func (p *dryRunPlanPrinter) printTable(plan *dryRunPlan) error {
+	const (
+		minColumnWidth = 0
+		tabWidth       = 8
+		padding        = 2
+	)
+
+	// The table is written at once, so it is not split by the log lines of other collections.
+	var buffer bytes.Buffer
+
+	title := "Tracks"
+	if plan.CollectionID != "" {
+		title = fmt.Sprintf("%s: %s (ID: %s)", plan.Category.ToTitleCase(), plan.Collection, plan.CollectionID)
+	}
+
+	fmt.Fprintf(&buffer, "\n%s\n", title)
+
+	tw := tabwriter.NewWriter(&buffer, minColumnWidth, tabWidth, padding, ' ', 0)
+	fmt.Fprintln(tw, "#\tTITLE\tQUALITY\tEST. SIZE\tDURATION\tACTION")
+
+	downloadedTracks := 0
+
+	for _, track := range plan.Tracks {
+		var size string
+		if track.Action == dryRunActionDownload {
+			size = humanize.Bytes(uint64(max(track.SizeBytes, 0)))
+			downloadedTracks++
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			track.Position, track.Title, track.Quality, size, formatPickerDuration(track.DurationSeconds), track.Action)
+	}
+
+	fmt.Fprintf(tw, "\tTotal\t\t%s\t%s\t%d of %d to download\n",
+		humanize.Bytes(uint64(max(plan.SizeBytes, 0))), formatPickerDuration(plan.DurationSeconds),
+		downloadedTracks, len(plan.Tracks))
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := p.w.Write(buffer.Bytes())
+
+	return err
+}
+
+// printCSV prints a CSV row per track of the plan, with the header above the rows of the first plan.
+func (p *dryRunPlanPrinter) printCSV(plan *dryRunPlan) error {
+	rows := make([][]string, 0, len(plan.Tracks)+1)
+	if !p.isCSVHeaderPrinted {
+		rows = append(rows, dryRunPlanCSVHeader)
+		p.isCSVHeaderPrinted = true
+	}
+
+	for _, track := range plan.Tracks {
+		rows = append(rows, []string{
+			plan.Category.String(),
+			plan.CollectionID,
+			plan.Collection,
+			strconv.Itoa(track.Position),
+			track.TrackID,
+			track.Title,
+			track.Quality,
+			strconv.FormatInt(track.SizeBytes, 10),
+			strconv.FormatInt(track.DurationSeconds, 10),
+			track.Action,
+		})
+	}
+
+	return csv.NewWriter(p.w).WriteAll(rows)
+}
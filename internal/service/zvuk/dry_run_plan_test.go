@@ -0,0 +1,151 @@
+package zvuk
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// newDryRunPlanTestMetadata returns a playlist with a repeated track, a track left out, and a failed track.
+func newDryRunPlanTestMetadata() *downloadTracksMetadata {
+	return &downloadTracksMetadata{
+		audioCollection: &audioCollection{category: DownloadCategoryPlaylist, id: "3001", title: "Industrial"},
+		category:        DownloadCategoryPlaylist,
+		trackIDs:        []int64{101, 102, 103, 101, 104, 105},
+		tracksMetadata: map[string]*zvuk.Track{
+			"101": {ID: 101, Title: "Mein Herz brennt", Duration: 279},
+			"102": {ID: 102, Title: "Links 2-3-4", Duration: 216},
+			"103": {ID: 103, Title: "Sonne", Duration: 272},
+			"104": {ID: 104, Title: "Feuer frei!", Duration: 188},
+			"105": {ID: 105, Title: "Mutter", Duration: 268},
+		},
+		duplicateNumbers: map[int]int64{3: 2},
+		deselectedTracks: map[int]struct{}{4: {}},
+	}
+}
+
+// newDryRunPlanTestRecords returns the statistics recorded for the tracks of newDryRunPlanTestMetadata.
+func newDryRunPlanTestRecords() *DownloadStatistics {
+	return &DownloadStatistics{
+		DownloadedTracks: []*DownloadedTrack{
+			{TrackID: "101", Title: "Mein Herz brennt", Quality: TrackQualityFLAC, Bytes: 31000000},
+			{TrackID: "103", Title: "Sonne", Quality: TrackQualityMP3High, Bytes: 10900000},
+		},
+		SkippedItems: []*SkippedItem{
+			{TrackID: "102", Title: "Links 2-3-4", Reason: SkipReasonExists},
+		},
+		Errors: []*DownloadError{
+			{Category: DownloadCategoryTrack, ItemID: "105", Error: errors.New("stream unavailable")},
+		},
+	}
+}
+
+// TestBuildDryRunPlan tests that every track of the collection is listed with the outcome recorded for it.
+func TestBuildDryRunPlan(t *testing.T) {
+	t.Parallel()
+
+	plan := buildDryRunPlan(newDryRunPlanTestMetadata(), newDryRunPlanTestRecords(), true)
+
+	assert.Equal(t, DownloadCategoryPlaylist, plan.Category)
+	assert.Equal(t, "3001", plan.CollectionID)
+	assert.Equal(t, int64(41900000), plan.SizeBytes)
+	assert.Equal(t, int64(279+272), plan.DurationSeconds)
+
+	actions := make([]string, 0, len(plan.Tracks))
+	for _, track := range plan.Tracks {
+		actions = append(actions, track.Action)
+	}
+
+	assert.Equal(t, []string{
+		dryRunActionDownload,
+		"skip (already exists)",
+		dryRunActionDownload,
+		dryRunActionLink,
+		dryRunActionNotSelected,
+		dryRunActionFail,
+	}, actions)
+
+	assert.Equal(t, 4, plan.Tracks[3].Position)
+	assert.Equal(t, "Mein Herz brennt", plan.Tracks[3].Title)
+	assert.Equal(t, "MP3 320", plan.Tracks[2].Quality)
+
+	plan = buildDryRunPlan(newDryRunPlanTestMetadata(), newDryRunPlanTestRecords(), false)
+	assert.Equal(t, dryRunActionNotStarted, plan.Tracks[3].Action)
+}
+
+// TestDryRunPlanPrinter tests that the plans are printed as an aligned table or as CSV rows under a header.
+func TestDryRunPlanPrinter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:   "table",
+			format: config.DryRunFormatTable,
+			expected: "\nPlaylist: Industrial (ID: 3001)\n" +
+				"#  TITLE             QUALITY  EST. SIZE  DURATION  ACTION\n" +
+				"1  Mein Herz brennt  FLAC     31 MB      4:39      download\n" +
+				"2  Links 2-3-4                           3:36      skip (already exists)\n" +
+				"3  Sonne             MP3 320  11 MB      4:32      download\n" +
+				"4  Mein Herz brennt                      4:39      link\n" +
+				"5  Feuer frei!                           3:08      not selected\n" +
+				"6  Mutter                                4:28      fail\n" +
+				"   Total                      42 MB      9:11      2 of 6 to download\n",
+		},
+		{
+			name:   "csv",
+			format: config.DryRunFormatCSV,
+			expected: "category,collection_id,collection,position,track_id,title,quality,size_bytes," +
+				"duration_seconds,action\n" +
+				"playlist,3001,Industrial,1,101,Mein Herz brennt,FLAC,31000000,279,download\n" +
+				"playlist,3001,Industrial,2,102,Links 2-3-4,,0,216,skip (already exists)\n" +
+				"playlist,3001,Industrial,3,103,Sonne,MP3 320,10900000,272,download\n" +
+				"playlist,3001,Industrial,4,101,Mein Herz brennt,,0,279,link\n" +
+				"playlist,3001,Industrial,5,104,Feuer frei!,,0,188,not selected\n" +
+				"playlist,3001,Industrial,6,105,Mutter,,0,268,fail\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output bytes.Buffer
+
+			printer := newDryRunPlanPrinter(&output, tt.format)
+			plan := buildDryRunPlan(newDryRunPlanTestMetadata(), newDryRunPlanTestRecords(), true)
+
+			require.NoError(t, printer.print(plan))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+// TestDryRunPlanPrinterJSON tests that every plan is printed as a JSON object on its own line.
+func TestDryRunPlanPrinterJSON(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	printer := newDryRunPlanPrinter(&output, config.DryRunFormatJSON)
+	plan := buildDryRunPlan(newDryRunPlanTestMetadata(), newDryRunPlanTestRecords(), true)
+
+	require.NoError(t, printer.print(plan))
+	require.NoError(t, printer.print(plan))
+
+	lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"category":"playlist","collection_id":"3001","collection":"Industrial"`)
+	assert.Contains(t, string(lines[0]),
+		`{"position":2,"track_id":"102","title":"Links 2-3-4","size_bytes":0,"duration_seconds":216,`+
+			`"action":"skip (already exists)"}`)
+}
@@ -55,6 +55,7 @@ func TestDownloadTracks_DryRunMode(t *testing.T) {
 			ID:             trackID,
 			Title:          "Dry-Run Test Track",
 			ReleaseID:      95,
+			Duration:       200,
 			Position:       1,
 			HighestQuality: "flac",
 			HasFLAC:        true,
@@ -85,17 +86,12 @@ func TestDownloadTracks_DryRunMode(t *testing.T) {
 		GetStreamMetadata(gomock.Any(), trackIDString, TrackQualityFLACString).
 		Return(streamMetadata, nil)
 
-	// Create fake audio data.
-	fakeAudioData := makeFakeAudioData(10 * 1024) // 10 MB.
-
-	fetchTrackResult := &zvuk.FetchTrackResult{
-		Body:       io.NopCloser(bytes.NewReader(fakeAudioData)),
-		TotalBytes: int64(len(fakeAudioData)),
-	}
+	// The size is probed without opening the audio stream.
+	const fileSize = int64(10 * 1024 * 1024) // 10 MB.
 
 	mockClient.EXPECT().
-		FetchTrack(gomock.Any(), "/streamfl?id="+trackIDString).
-		Return(fetchTrackResult, nil)
+		GetFileSize(gomock.Any(), "/streamfl?id="+trackIDString).
+		Return(fileSize, nil)
 
 	// Mock lyrics fetch.
 	lyricsData := &zvuk.Lyrics{
@@ -119,7 +115,7 @@ func TestDownloadTracks_DryRunMode(t *testing.T) {
 	impl, ok := service.(*ServiceImpl)
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
-	impl.stats.IsDryRun = true
+	impl.stats.update(func(stats *DownloadStatistics) { stats.IsDryRun = true })
 
 	// Execute dry-run download.
 	ctx := context.Background()
@@ -138,12 +134,24 @@ func TestDownloadTracks_DryRunMode(t *testing.T) {
 	assert.Empty(t, lyricsFiles, "No lyrics files should be created in dry-run mode")
 
 	// Verify statistics show correct counts.
-	assert.Equal(t, int64(1), impl.stats.TracksDownloaded, "Should count track as 'would download'")
-	assert.Equal(t, int64(len(fakeAudioData)), impl.stats.TotalBytesDownloaded, "Should estimate file size")
-	assert.Equal(t, int64(1), impl.stats.LyricsDownloaded, "Should count lyrics as 'would download'")
-	assert.True(t, impl.stats.IsDryRun, "Statistics should be marked as dry-run")
+	assert.Equal(t, int64(1), impl.Statistics().TracksDownloaded, "Should count track as 'would download'")
+	assert.Equal(t, fileSize, impl.Statistics().TotalBytesDownloaded, "Should estimate file size")
+	assert.Equal(t,
+		map[TrackQuality]*QualitySizeEstimate{TrackQualityFLAC: {Tracks: 1, Bytes: fileSize}},
+		impl.Statistics().QualitySizeEstimates,
+		"Should group the estimate by resolved quality")
+	assert.Equal(t,
+		map[TrackQuality]int64{
+			TrackQualityMP3High: 200 * 320 * 1000 / 8,
+			TrackQualityMP3Mid:  200 * 128 * 1000 / 8,
+		},
+		impl.Statistics().ProjectedMP3Bytes,
+		"Should project MP3 sizes from the track duration")
+	assert.Equal(t, int64(1), impl.Statistics().LyricsDownloaded, "Should count lyrics as 'would download'")
+	assert.True(t, impl.Statistics().IsDryRun, "Statistics should be marked as dry-run")
 
 	// Print summary to verify dry-run output.
+	mockClient.EXPECT().GetAPIStatistics().Return(&zvuk.APIStatistics{}).AnyTimes()
 	impl.PrintDownloadSummary(ctx)
 }
 
@@ -270,20 +278,21 @@ func TestDownloadTracks_DryRunSkipsExistingFiles(t *testing.T) {
 	impl, ok := service.(*ServiceImpl)
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
-	impl.stats.IsDryRun = true
+	impl.stats.update(func(stats *DownloadStatistics) { stats.IsDryRun = true })
 
 	// Execute dry-run download.
 	impl.downloadTracks(ctx, metadata)
 
 	// Verify statistics show track was skipped.
-	assert.Equal(t, int64(1), impl.stats.TracksSkipped, "Should count existing track as skipped in dry-run")
-	assert.Equal(t, int64(0), impl.stats.TracksDownloaded, "Should not count existing track as download")
-	assert.Equal(t, int64(0), impl.stats.TotalBytesDownloaded, "Should not estimate size for skipped tracks")
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkipped, "Should count existing track as skipped in dry-run")
+	assert.Equal(t, int64(0), impl.Statistics().TracksDownloaded, "Should not count existing track as download")
+	assert.Equal(t, int64(0), impl.Statistics().TotalBytesDownloaded, "Should not estimate size for skipped tracks")
 
 	// Verify only one audio file exists (not duplicated).
 	audioFilesAfter := findAudioFiles(t, tempDir)
 	assert.Len(t, audioFilesAfter, 1, "Should still have only one file after dry-run skip")
 
 	// Print summary.
+	mockClient.EXPECT().GetAPIStatistics().Return(&zvuk.APIStatistics{}).AnyTimes()
 	impl.PrintDownloadSummary(ctx)
 }
@@ -0,0 +1,75 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// embeddedCoverJPEGQuality is the JPEG quality of an embedded cover thumbnail.
+const embeddedCoverJPEGQuality = 85
+
+// applyEmbeddedCover sets how the cover is embedded into the track as defined by embedded_cover:
+// as downloaded, scaled down to a thumbnail, or not at all. The folder cover is not affected.
+func (s *ServiceImpl) applyEmbeddedCover(req *WriteTagsRequest) {
+	switch s.cfg.EmbeddedCover {
+	case config.EmbeddedCoverThumbnail:
+		req.CoverMaxSize = s.cfg.EmbeddedCoverSize
+		req.IsExistingCoverRemoved = true
+	case config.EmbeddedCoverNone:
+		req.CoverPath = ""
+		req.IsCoverEmbeddedToTrackTags = false
+		req.IsExistingCoverRemoved = true
+	}
+}
+
+// isCoverEmbeddingDisabled reports whether no cover is embedded into the tracks (embedded_cover is none),
+// so the album covers of playlist tracks need not be fetched.
+func (s *ServiceImpl) isCoverEmbeddingDisabled() bool {
+	return s.cfg.EmbeddedCover == config.EmbeddedCoverNone
+}
+
+// shrinkEmbeddedCover scales the cover down, keeping its proportions, so its largest side is maxSize pixels,
+// and re-encodes it as a JPEG. A smaller cover, or one that cannot be decoded, is embedded as it is.
+func shrinkEmbeddedCover(ctx context.Context, cover *imageMetadata, maxSize int) *imageMetadata {
+	img, _, err := image.Decode(bytes.NewReader(cover.data))
+	if err != nil {
+		logger.Warnf(ctx, "Failed to decode the cover, embedding it in full size: %v", err)
+
+		return cover
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() <= maxSize && bounds.Dy() <= maxSize {
+		return cover
+	}
+
+	width, height := maxSize, maxSize
+	if bounds.Dx() > bounds.Dy() {
+		height = max(bounds.Dy()*maxSize/bounds.Dx(), 1)
+	} else {
+		width = max(bounds.Dx()*maxSize/bounds.Dy(), 1)
+	}
+
+	var thumbnail bytes.Buffer
+
+	err = jpeg.Encode(&thumbnail, scaleImageTo(img, width, height), &jpeg.Options{Quality: embeddedCoverJPEGQuality})
+	if err != nil {
+		logger.Warnf(ctx, "Failed to encode the cover thumbnail, embedding it in full size: %v", err)
+
+		return cover
+	}
+
+	logger.Debugf(ctx, "Cover scaled down from %dx%d (%d bytes) to %dx%d (%d bytes)",
+		bounds.Dx(), bounds.Dy(), len(cover.data), width, height, thumbnail.Len())
+
+	return &imageMetadata{
+		data:     thumbnail.Bytes(),
+		mimeType: utils.ImageJPEGMimeType,
+	}
+}
@@ -0,0 +1,104 @@
+package zvuk
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-flac/go-flac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// TestShrinkEmbeddedCover tests that a large cover is scaled down in proportion and a small one is kept.
+func TestShrinkEmbeddedCover(t *testing.T) {
+	t.Parallel()
+
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, newTestSolidImage(600, 400, color.RGBA{R: 255, A: 255})))
+
+	cover := &imageMetadata{data: encoded.Bytes(), mimeType: "image/png"}
+
+	thumbnail := shrinkEmbeddedCover(t.Context(), cover, 300)
+	assert.Equal(t, utils.ImageJPEGMimeType, thumbnail.mimeType)
+
+	img, format, err := image.Decode(bytes.NewReader(thumbnail.data))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Rect(0, 0, 300, 200), img.Bounds())
+
+	assert.Same(t, cover, shrinkEmbeddedCover(t.Context(), cover, 600), "a cover that fits is kept as it is")
+}
+
+// TestWriteTags_EmbeddedCover tests that the thumbnail and none modes replace the pictures of a FLAC file.
+func TestWriteTags_EmbeddedCover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		mode             string
+		expectedPictures int
+	}{
+		{name: "full", mode: config.EmbeddedCoverFull, expectedPictures: 2},
+		{name: "thumbnail", mode: config.EmbeddedCoverThumbnail, expectedPictures: 1},
+		{name: "none", mode: config.EmbeddedCoverNone, expectedPictures: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				folder    = t.TempDir()
+				trackPath = filepath.Join(folder, "01 - Rammstein.flac")
+				coverPath = filepath.Join(folder, "cover.png")
+			)
+
+			writeTestFLAC(t, trackPath, 1, 1000, map[string]string{"TITLE": "Rammstein"})
+			writeTestPNG(t, coverPath, newTestSolidImage(64, 64, color.RGBA{B: 255, A: 255}))
+
+			// The downloaded file already has a picture.
+			f, err := flac.ParseFile(trackPath)
+			require.NoError(t, err)
+
+			f.Meta = append(f.Meta, &flac.MetaDataBlock{Type: flac.Picture, Data: make([]byte, 64)})
+			require.NoError(t, f.Save(trackPath))
+
+			s := &ServiceImpl{cfg: &config.Config{EmbeddedCover: tt.mode, EmbeddedCoverSize: 32}}
+
+			req := &WriteTagsRequest{
+				TrackPath:                  trackPath,
+				CoverPath:                  coverPath,
+				Quality:                    TrackQualityFLAC,
+				TrackTags:                  map[string]string{TagTrackTitle: "Rammstein"},
+				IsCoverEmbeddedToTrackTags: true,
+			}
+
+			s.applyEmbeddedCover(req)
+			require.NoError(t, NewTagProcessor().WriteTags(t.Context(), req))
+
+			f, err = flac.ParseFile(trackPath)
+			require.NoError(t, err)
+
+			var pictures int
+
+			for _, meta := range f.Meta {
+				if meta.Type == flac.Picture {
+					pictures++
+				}
+			}
+
+			assert.Equal(t, tt.expectedPictures, pictures)
+
+			_, err = os.Stat(coverPath)
+			require.NoError(t, err, "the folder cover must be kept")
+		})
+	}
+}
@@ -3,6 +3,7 @@ package zvuk
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
@@ -21,6 +22,10 @@ var (
 	ErrDurationBelowThreshold = errors.New("duration below minimum threshold")
 	// ErrDurationAboveThreshold indicates that track duration exceeds the configured maximum.
 	ErrDurationAboveThreshold = errors.New("duration above maximum threshold")
+	// ErrExcludedByPattern indicates that the track title or artist matches an exclude pattern.
+	ErrExcludedByPattern = errors.New("excluded by pattern")
+	// ErrBlocklisted indicates that the track, its release, or one of its artists is on the blocklist.
+	ErrBlocklisted = errors.New("blocklisted")
 	// ErrChapterStreamNotFound indicates that stream metadata for a chapter was not found.
 	ErrChapterStreamNotFound = errors.New("chapter stream metadata not found")
 	// ErrChapterNoStreams indicates that a chapter has no available streams.
@@ -33,6 +38,34 @@ var (
 	ErrAlbumContextFailed = errors.New("failed to prepare album context")
 	// ErrPodcastContextFailed indicates that preparing podcast context failed.
 	ErrPodcastContextFailed = errors.New("failed to prepare podcast context")
+	// ErrOutputPathMissing indicates that the output path does not exist and must not be created.
+	ErrOutputPathMissing = errors.New("output path does not exist")
+	// ErrOutputPathNotDirectory indicates that the output path points to a file.
+	ErrOutputPathNotDirectory = errors.New("output path is not a directory")
+	// ErrOutputPathNotWritable indicates that files cannot be created in the output path.
+	ErrOutputPathNotWritable = errors.New("output path is not writable")
+	// ErrOutputPathLocked indicates that another run is writing to the output path.
+	ErrOutputPathLocked = errors.New("output path is locked")
+	// ErrTooManyConsecutiveFailures indicates that a collection was aborted after repeated track failures.
+	ErrTooManyConsecutiveFailures = errors.New("too many consecutive track failures")
+	// ErrInvalidTemplate indicates that a configured template cannot be parsed or references unknown variables.
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrTagWriteTimeout indicates that writing tags to a track took longer than tag_write_timeout.
+	ErrTagWriteTimeout = errors.New("tag writing timed out")
+	// ErrUnknownDownloadCategory indicates that a saved category name is not recognized.
+	ErrUnknownDownloadCategory = errors.New("unknown download category")
+	// ErrUnknownSkipReason indicates that a saved skip reason is not recognized.
+	ErrUnknownSkipReason = errors.New("unknown skip reason")
+	// ErrSyncNotPlaylist indicates that an item passed to the sync command is not a playlist.
+	ErrSyncNotPlaylist = errors.New("only playlists can be synced")
+	// ErrRunTimeLimitReached indicates that an item was not started because the run used up max_run_duration.
+	ErrRunTimeLimitReached = errors.New("run time limit reached")
+	// ErrAPIQuotaReached indicates that an item was not started because the run sent max_api_requests requests.
+	ErrAPIQuotaReached = errors.New("API request limit reached")
+	// ErrRemoteStorageRequest indicates that the remote storage rejected a request.
+	ErrRemoteStorageRequest = errors.New("remote storage request failed")
+	// ErrRemoteStorageStalled indicates that an upload to the remote storage sent nothing for too long.
+	ErrRemoteStorageStalled = errors.New("remote storage upload stalled")
 )
 
 // handleError handles an error with logging and recording.
@@ -58,36 +91,115 @@ func (s *ServiceImpl) handleError(
 
 	// Increment failure counter if requested.
 	if incrementFailed && !isContextCanceled {
-		s.incrementTrackFailed()
+		s.incrementTrackFailed(e.ItemID)
 	}
 }
 
 // handleTrackSkipped handles a track skip with logging and recording.
+// The threshold is the configured limit the track failed to meet, kept for per-item skip reporting.
 func (s *ServiceImpl) handleTrackSkipped(
 	reason SkipReason,
+	threshold string,
 	e *DownloadError,
 ) {
-	s.incrementTrackSkipped(reason)
+	var trackID string
+	if e != nil {
+		trackID = e.ItemID
+	}
+
+	s.incrementTrackSkipped(trackID, reason)
 
 	if e != nil {
-		s.recordError(e)
+		s.recordSkippedItem(&SkippedItem{
+			TrackID:        e.ItemID,
+			Title:          e.ItemTitle,
+			ParentCategory: e.ParentCategory,
+			ParentID:       e.ParentID,
+			ParentTitle:    e.ParentTitle,
+			Reason:         reason,
+			Threshold:      threshold,
+		})
+
+		s.appendError(e)
+	}
+}
+
+// recordSkippedItem records a skipped track in the statistics.
+func (s *ServiceImpl) recordSkippedItem(item *SkippedItem) {
+	if item == nil {
+		return
 	}
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.SkippedItems = append(stats.SkippedItems, item)
+	})
 }
 
 // recordError records an error in the statistics with proper context.
 // Context cancellation errors are ignored as they are expected during graceful shutdown.
+// In fail-fast mode the first recorded error cancels the whole run.
 func (s *ServiceImpl) recordError(e *DownloadError) {
-	if e == nil || e.Error == nil {
+	if !s.appendError(e) {
+		return
+	}
+
+	s.abortMutex.Lock()
+	defer s.abortMutex.Unlock()
+
+	if s.failFastCancel == nil || s.abortReason != nil {
 		return
 	}
 
+	s.abortReason = fmt.Errorf("%s '%s' (ID: %s), %s: %w", e.Category, e.ItemTitle, e.ItemID, e.Phase, e.Error)
+	s.failFastCancel(s.abortReason)
+}
+
+// appendError adds an error to the statistics without triggering fail-fast.
+// It is used for expected outcomes such as tracks skipped by filters.
+// Returns true if the error was recorded.
+func (s *ServiceImpl) appendError(e *DownloadError) bool {
+	if e == nil || e.Error == nil {
+		return false
+	}
+
 	// Don't record context cancellation as an error - it's expected when user presses CTRL+C.
 	if errors.Is(e.Error, context.Canceled) {
-		return
+		return false
 	}
 
-	s.statsMutex.Lock()
-	defer s.statsMutex.Unlock()
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.Errors = append(stats.Errors, e)
+	})
+
+	return true
+}
+
+// recordQualityDowngrade adds a track received below the requested quality
+// to the statistics in a thread-safe manner.
+func (s *ServiceImpl) recordQualityDowngrade(item *QualityDowngrade) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.QualityDowngrades = append(stats.QualityDowngrades, item)
+	})
+}
+
+// recordRelinkedRelease adds an album moved to its re-issue to the statistics in a thread-safe manner.
+func (s *ServiceImpl) recordRelinkedRelease(item *RelinkedRelease) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.RelinkedReleases = append(stats.RelinkedReleases, item)
+	})
+}
+
+// recordRcloneFailure adds a collection or track that rclone failed to push
+// to the statistics in a thread-safe manner.
+func (s *ServiceImpl) recordRcloneFailure(item *RcloneFailure) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.RcloneFailures = append(stats.RcloneFailures, item)
+	})
+}
 
-	s.stats.Errors = append(s.stats.Errors, e)
+// recordUntaggedTrack adds a track kept without tags to the statistics in a thread-safe manner.
+func (s *ServiceImpl) recordUntaggedTrack(item *UntaggedTrack) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.UntaggedTracks = append(stats.UntaggedTracks, item)
+	})
 }
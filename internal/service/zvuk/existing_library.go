@@ -0,0 +1,299 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+	"github.com/oshokin/id3v2/v2"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// leadingTrackNumberRegex matches the track number filename templates start with, e.g. "01 - ".
+// The separator is required, so titles made of digits, like "1979", are kept.
+var leadingTrackNumberRegex = regexp.MustCompile(`^\d+ - `)
+
+// libraryNameSeparator separates the artist from the title in filenames and from the album in folder names.
+const libraryNameSeparator = " - "
+
+// existingLibrary indexes the tracks found in the existing library roots (existing_library_paths).
+type existingLibrary struct {
+	// byTrackID maps the TRACK_ID tags to the files.
+	byTrackID map[string]*existingLibraryFile
+	// byTitle maps the normalized titles, from the tags or the filenames, to the files.
+	byTitle map[string][]*existingLibraryFile
+	// filesCount is the number of audio files found.
+	filesCount int
+}
+
+// existingLibraryFile is an audio file found in an existing library root.
+type existingLibraryFile struct {
+	// path is the location of the file.
+	path string
+	// quality is the quality of the file, telling FLAC apart from MP3.
+	quality TrackQuality
+	// artists are the normalized artist names of the file: the ones of the artist tag,
+	// or the ones found in its filename and folders when the tags have no artist.
+	artists []string
+}
+
+// scanExistingLibraries indexes the tracks of the existing library roots before anything is downloaded.
+// A root that cannot be read fails the run, so a library on an unmounted drive is not downloaded again.
+func (s *ServiceImpl) scanExistingLibraries(ctx context.Context) error {
+	if len(s.cfg.ExistingLibraryPaths) == 0 {
+		return nil
+	}
+
+	library := &existingLibrary{
+		byTrackID: make(map[string]*existingLibraryFile),
+		byTitle:   make(map[string][]*existingLibraryFile),
+	}
+
+	for _, root := range s.cfg.ExistingLibraryPaths {
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("failed to read existing library: %w", err)
+		}
+
+		paths, err := findLibraryFiles(root)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			library.add(path)
+		}
+	}
+
+	s.existingLibrary = library
+
+	logger.Infof(ctx, "Found %d tracks in %d existing library folder(s)",
+		library.filesCount, len(s.cfg.ExistingLibraryPaths))
+
+	return nil
+}
+
+// add indexes an audio file by its TRACK_ID tag, or by its title when the tag is missing.
+// Files without readable tags are indexed by the title taken from their filenames.
+func (l *existingLibrary) add(path string) {
+	trackID, title, artist := readLibraryTags(path)
+
+	file := &existingLibraryFile{
+		path:    path,
+		quality: TrackQualityMP3Mid,
+		artists: splitLibraryArtists(artist),
+	}
+
+	if strings.EqualFold(filepath.Ext(path), extensionFLAC) {
+		file.quality = TrackQualityFLAC
+	}
+
+	l.filesCount++
+
+	if trackID != "" {
+		l.byTrackID[trackID] = file
+
+		return
+	}
+
+	var filenameArtist string
+
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		title = leadingTrackNumberRegex.ReplaceAllString(title, "")
+
+		// Playlist filenames usually put the artist before the title.
+		if index := strings.LastIndex(title, libraryNameSeparator); index >= 0 {
+			filenameArtist, title = title[:index], title[index+len(libraryNameSeparator):]
+		}
+	}
+
+	if len(file.artists) == 0 {
+		file.artists = pathLibraryArtists(path, filenameArtist)
+	}
+
+	key := normalizeLibraryTitle(title)
+	if key != "" {
+		l.byTitle[key] = append(l.byTitle[key], file)
+	}
+}
+
+// find returns the file of the track, matched by its ID first,
+// then by its title together with its first artist.
+func (l *existingLibrary) find(trackIDString string, track *zvuk.Track) *existingLibraryFile {
+	if file := l.byTrackID[trackIDString]; file != nil {
+		return file
+	}
+
+	if track == nil || len(track.ArtistNames) == 0 {
+		return nil
+	}
+
+	artist := normalizeLibraryTitle(track.ArtistNames[0])
+	if artist == "" {
+		return nil
+	}
+
+	for _, file := range l.byTitle[normalizeLibraryTitle(track.Title)] {
+		if slices.Contains(file.artists, artist) {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// splitLibraryArtists returns the normalized names of an artist tag, which may list several artists.
+func splitLibraryArtists(artist string) []string {
+	var result []string
+
+	for _, name := range strings.FieldsFunc(artist, func(r rune) bool { return strings.ContainsRune(",;&/", r) }) {
+		if normalized := normalizeLibraryTitle(name); normalized != "" {
+			result = append(result, normalized)
+		}
+	}
+
+	return result
+}
+
+// pathLibraryArtists returns the normalized artist names a file without an artist tag may belong to:
+// the artist of its filename and the names of its folder and the folder above it,
+// alone or before the album title ("Artist - Album").
+func pathLibraryArtists(path, filenameArtist string) []string {
+	var (
+		folder = filepath.Dir(path)
+		names  = []string{filenameArtist, filepath.Base(folder), filepath.Base(filepath.Dir(folder))}
+		result = make([]string, 0, len(names)*2)
+	)
+
+	for _, name := range names {
+		result = append(result, normalizeLibraryTitle(name))
+
+		if prefix, _, ok := strings.Cut(name, libraryNameSeparator); ok {
+			result = append(result, normalizeLibraryTitle(prefix))
+		}
+	}
+
+	return slices.DeleteFunc(result, func(name string) bool { return name == "" })
+}
+
+// skipExistingLibraryTrack reports whether the track is present in one of the existing library roots.
+// The track is recorded as skipped, and its file is remembered for the playlist file and repeated tracks.
+func (s *ServiceImpl) skipExistingLibraryTrack(
+	ctx context.Context,
+	trackID int64,
+	metadata *downloadTracksMetadata,
+) bool {
+	if s.existingLibrary == nil {
+		return false
+	}
+
+	trackIDString := strconv.FormatInt(trackID, 10)
+	track := metadata.tracksMetadata[trackIDString]
+
+	file := s.existingLibrary.find(trackIDString, track)
+	if file == nil {
+		return false
+	}
+
+	title := "Track ID: " + trackIDString
+	if track != nil {
+		title = track.Title
+	}
+
+	logger.Debugf(ctx, "Track '%s' is found in the existing library as '%s', skipping", title, file.path)
+
+	metadata.rememberSavedTrackPath(trackIDString, file.path, file.quality)
+
+	s.incrementTrackSkipped(trackIDString, SkipReasonInLibrary)
+	s.recordSkippedItem(&SkippedItem{
+		TrackID:        trackIDString,
+		Title:          title,
+		ParentCategory: metadata.category,
+		Reason:         SkipReasonInLibrary,
+	})
+
+	return true
+}
+
+// readLibraryTags reads the track ID, title, and artist tags of an audio file without reading its audio data.
+// Unreadable tags leave the values empty.
+func readLibraryTags(path string) (string, string, string) {
+	if strings.EqualFold(filepath.Ext(path), extensionFLAC) {
+		return readFLACLibraryTags(path)
+	}
+
+	//nolint:exhaustruct // Only the frames needed to match tracks are parsed.
+	tag, err := id3v2.Open(path, id3v2.Options{
+		Parse:       true,
+		ParseFrames: []string{"Title", "Artist", "User defined text information frame"},
+	})
+	if err != nil {
+		return "", "", ""
+	}
+	defer tag.Close()
+
+	var trackID string
+
+	for _, frame := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+		userFrame, ok := frame.(id3v2.UserDefinedTextFrame)
+		if ok && userFrame.Description == "TRACK_ID" {
+			trackID = userFrame.Value
+		}
+	}
+
+	return trackID, tag.Title(), tag.Artist()
+}
+
+// readFLACLibraryTags reads the track ID, title, and artist from the Vorbis comment of a FLAC file.
+func readFLACLibraryTags(path string) (string, string, string) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", "", ""
+	}
+	defer file.Close()
+
+	f, err := flac.ParseMetadata(file)
+	if err != nil {
+		return "", "", ""
+	}
+
+	for _, meta := range f.Meta {
+		if meta.Type != flac.VorbisComment {
+			continue
+		}
+
+		comment, parseErr := flacvorbis.ParseFromMetaDataBlock(*meta)
+		if parseErr != nil {
+			return "", "", ""
+		}
+
+		return firstVorbisValue(comment, "TRACK_ID"), firstVorbisValue(comment, "TITLE"),
+			firstVorbisValue(comment, "ARTIST")
+	}
+
+	return "", "", ""
+}
+
+// normalizeLibraryTitle lowercases a title and keeps only its letters and digits, so titles from tags match
+// the filenames, where characters not allowed by the filesystem are replaced.
+func normalizeLibraryTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,85 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestExistingLibrary tests that tracks found in the existing library roots are skipped.
+func TestExistingLibrary(t *testing.T) {
+	t.Parallel()
+
+	var (
+		flacRoot   = t.TempDir()
+		mp3Root    = t.TempDir()
+		albumPath  = filepath.Join(mp3Root, "Rammstein", "2001 - Mutter")
+		singlePath = filepath.Join(mp3Root, "The Smashing Pumpkins")
+	)
+
+	require.NoError(t, os.MkdirAll(albumPath, defaultFolderPermissions))
+	require.NoError(t, os.MkdirAll(singlePath, defaultFolderPermissions))
+
+	writeTestFLAC(t, filepath.Join(flacRoot, "01 - Mein Herz brennt.flac"), 1, 200,
+		map[string]string{"TRACK_ID": "1", "TITLE": "Mein Herz brennt", "ARTIST": "Rammstein"})
+	writeTestMP3(t, filepath.Join(mp3Root, "Sonne.mp3"), 1, "Sonne", "2")
+	// Files without tags are matched by their filenames and the artist in their folders.
+	require.NoError(t, os.WriteFile(filepath.Join(albumPath, "03 - Links 2-3-4.mp3"), []byte("audio"),
+		constants.DefaultFilePermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(mp3Root, "04 - Rammstein - Feuer frei!.mp3"), []byte("audio"),
+		constants.DefaultFilePermissions))
+	// A title made of digits is not taken for a track number.
+	require.NoError(t, os.WriteFile(filepath.Join(singlePath, "1979.mp3"), []byte("audio"),
+		constants.DefaultFilePermissions))
+
+	impl, ok := NewService(&config.Config{ExistingLibraryPaths: []string{flacRoot, mp3Root}},
+		nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	require.NoError(t, impl.scanExistingLibraries(context.Background()))
+
+	metadata := &downloadTracksMetadata{
+		category:        DownloadCategoryAlbum,
+		keepSavedTracks: true,
+		tracksMetadata: map[string]*zvuk.Track{
+			"1": {ID: 1, Title: "Mein Herz brennt", ArtistNames: []string{"Rammstein"}},
+			"2": {ID: 2, Title: "Sonne", ArtistNames: []string{"Rammstein"}},
+			"3": {ID: 3, Title: "Links 2 3 4", ArtistNames: []string{"Rammstein"}},
+			"4": {ID: 4, Title: "Feuer Frei!", ArtistNames: []string{"Rammstein"}},
+			"5": {ID: 5, Title: "Links 2-3-4", ArtistNames: []string{"Oomph!"}},
+			"6": {ID: 6, Title: "Mutter", ArtistNames: []string{"Rammstein"}},
+			"7": {ID: 7, Title: "1979", ArtistNames: []string{"The Smashing Pumpkins"}},
+			"8": {ID: 8, Title: "Feuer Frei!", ArtistNames: []string{"Ramm"}},
+		},
+	}
+
+	for trackID := range int64(4) {
+		assert.True(t, impl.skipExistingLibraryTrack(context.Background(), trackID+1, metadata), trackID+1)
+	}
+
+	assert.True(t, impl.skipExistingLibraryTrack(context.Background(), 7, metadata))
+
+	// The same title by another artist is not a match, even with a name contained in the artist of the file,
+	// nor is a title missing from the library.
+	assert.False(t, impl.skipExistingLibraryTrack(context.Background(), 5, metadata))
+	assert.False(t, impl.skipExistingLibraryTrack(context.Background(), 6, metadata))
+	assert.False(t, impl.skipExistingLibraryTrack(context.Background(), 8, metadata))
+
+	assert.Equal(t, int64(5), impl.Statistics().TracksSkippedInLibrary)
+
+	saved := metadata.getSavedTrack("1")
+	require.NotNil(t, saved)
+	assert.Equal(t, TrackQualityFLAC, saved.quality)
+
+	// A missing root fails the scan instead of downloading the whole library again.
+	impl.cfg.ExistingLibraryPaths = []string{filepath.Join(flacRoot, "missing")}
+	require.Error(t, impl.scanExistingLibraries(context.Background()))
+}
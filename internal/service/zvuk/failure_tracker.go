@@ -0,0 +1,87 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// consecutiveFailureTracker counts consecutive track failures within a single collection
+// and signals an early abort once the configured limit is reached.
+type consecutiveFailureTracker struct {
+	// limit is the number of consecutive failures that triggers an abort (0 disables the limit).
+	limit int64
+	// consecutive is the current number of failures in a row.
+	consecutive atomic.Int64
+	// aborted is set once the limit has been reached.
+	aborted atomic.Bool
+}
+
+// newConsecutiveFailureTracker creates a tracker with the given failure limit.
+func newConsecutiveFailureTracker(limit int64) *consecutiveFailureTracker {
+	return &consecutiveFailureTracker{
+		limit: limit,
+	}
+}
+
+// isAborted reports whether the collection download should stop.
+func (t *consecutiveFailureTracker) isAborted() bool {
+	return t != nil && t.aborted.Load()
+}
+
+// registerOutcome records a track result and returns true exactly once,
+// when the consecutive failure limit is reached.
+func (t *consecutiveFailureTracker) registerOutcome(isFailed bool) bool {
+	if t == nil || t.limit <= 0 {
+		return false
+	}
+
+	if !isFailed {
+		t.consecutive.Store(0)
+
+		return false
+	}
+
+	if t.consecutive.Add(1) < t.limit {
+		return false
+	}
+
+	return t.aborted.CompareAndSwap(false, true)
+}
+
+// registerTrackOutcome updates the collection's failure tracker and records
+// a collection-level error when the download is aborted early.
+func (s *ServiceImpl) registerTrackOutcome(ctx context.Context, metadata *downloadTracksMetadata, isFailed bool) {
+	if isFailed {
+		metadata.failedTracks.Add(1)
+	}
+
+	// Failures caused by CTRL+C are not the collection's fault.
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !metadata.failureTracker.registerOutcome(isFailed) {
+		return
+	}
+
+	var itemID, itemTitle string
+	if metadata.audioCollection != nil {
+		itemID = metadata.audioCollection.id
+		itemTitle = metadata.audioCollection.title
+	}
+
+	logger.Warnf(ctx, "Aborting %s '%s' after %d consecutive track failures",
+		metadata.category, itemTitle, metadata.failureTracker.limit)
+
+	s.recordError(&DownloadError{
+		Category:  metadata.category,
+		ItemID:    itemID,
+		ItemTitle: itemTitle,
+		Phase:     "downloading tracks",
+		Error: fmt.Errorf("%w: %d in a row, remaining tracks were not attempted",
+			ErrTooManyConsecutiveFailures, metadata.failureTracker.limit),
+	})
+}
@@ -0,0 +1,75 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestDownloadTracks_MaxConsecutiveFailures tests that a collection is aborted after too many failures in a row.
+func TestDownloadTracks_MaxConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+		cfg.MaxConsecutiveFailures = 2
+		cfg.ParsedMaxDownloadPause = time.Millisecond
+	})
+	defer setup.cleanup()
+
+	trackIDs := []int64{1001, 1002, 1003, 1004}
+	metadata := newTestMetadata(trackIDs, 100).build()
+
+	// Only the first two tracks must be attempted.
+	setup.mockClient.EXPECT().
+		GetStreamMetadata(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("forbidden")).
+		Times(2)
+
+	impl, ok := setup.service.(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.downloadTracks(context.Background(), metadata)
+
+	assert.Equal(t, int64(2), impl.Statistics().TracksFailed, "Only two tracks should be attempted")
+	assert.True(t, metadata.failureTracker.isAborted(), "Collection should be aborted")
+
+	var abortErrors int
+
+	for _, e := range impl.Statistics().Errors {
+		if errors.Is(e.Error, ErrTooManyConsecutiveFailures) {
+			abortErrors++
+
+			assert.Equal(t, DownloadCategoryAlbum, e.Category)
+		}
+	}
+
+	assert.Equal(t, 1, abortErrors, "Abort should be recorded exactly once")
+}
+
+// TestConsecutiveFailureTracker_ResetOnSuccess tests that a success resets the failure streak.
+func TestConsecutiveFailureTracker_ResetOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	tracker := newConsecutiveFailureTracker(2)
+
+	assert.False(t, tracker.registerOutcome(true))
+	assert.False(t, tracker.registerOutcome(false))
+	assert.False(t, tracker.registerOutcome(true))
+	assert.True(t, tracker.registerOutcome(true), "Second failure in a row should trigger abort")
+	assert.False(t, tracker.registerOutcome(true), "Abort should be signaled only once")
+	assert.True(t, tracker.isAborted())
+
+	disabled := newConsecutiveFailureTracker(0)
+	for range 10 {
+		assert.False(t, disabled.registerOutcome(true))
+	}
+
+	assert.False(t, disabled.isAborted())
+}
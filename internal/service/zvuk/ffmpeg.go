@@ -0,0 +1,33 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ffmpegTempSuffix is appended to an output file while ffmpeg is writing it.
+const ffmpegTempSuffix = ".part"
+
+// runFFmpeg executes ffmpeg and returns its stderr, where both progress and filter output are printed.
+func runFFmpeg(ctx context.Context, ffmpegPath string, args []string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...) //nolint:gosec // Arguments are built by the application.
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, lastLine(stderr.String()))
+	}
+
+	return stderr.Bytes(), nil
+}
+
+// lastLine returns the last non-empty line of the text, which holds the ffmpeg error message.
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -2,12 +2,19 @@ package zvuk
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
 	"github.com/oshokin/zvuk-grabber/internal/config"
 )
 
@@ -117,20 +124,28 @@ func TestDownloadTracks_MinQualityFilter(t *testing.T) {
 			impl.downloadTracks(ctx, metadata)
 
 			// Verify statistics.
-			assert.Equal(t, tc.expectedSkipped, impl.stats.TracksSkipped,
+			assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkipped,
 				"Expected %d tracks skipped", tc.expectedSkipped)
-			assert.Equal(t, tc.expectedDownloaded, impl.stats.TracksDownloaded,
+			assert.Equal(t, tc.expectedDownloaded, impl.Statistics().TracksDownloaded,
 				"Expected %d tracks downloaded", tc.expectedDownloaded)
 
 			// Verify error message if track was skipped.
 			if tc.expectedSkipped > 0 && tc.expectedErrorContains != "" {
-				require.NotEmpty(t, impl.stats.Errors, "Should have recorded an error")
-				assert.Contains(t, impl.stats.Errors[0].Error.Error(), tc.expectedErrorContains,
+				require.NotEmpty(t, impl.Statistics().Errors, "Should have recorded an error")
+				assert.Contains(t, impl.Statistics().Errors[0].Error.Error(), tc.expectedErrorContains,
 					"Error message should mention quality threshold")
-				assert.Equal(t, "quality check", impl.stats.Errors[0].Phase,
+				assert.Equal(t, "quality check", impl.Statistics().Errors[0].Phase,
 					"Error phase should be 'quality check'")
 			}
 
+			// Verify per-item skip entry.
+			if tc.expectedSkipped > 0 {
+				require.Len(t, impl.Statistics().SkippedItems, 1, "Should have recorded a skipped item")
+				assert.Equal(t, "1000", impl.Statistics().SkippedItems[0].TrackID)
+				assert.Equal(t, SkipReasonQuality, impl.Statistics().SkippedItems[0].Reason)
+				assert.Equal(t, TrackQuality(tc.minQuality).String(), impl.Statistics().SkippedItems[0].Threshold)
+			}
+
 			// Verify files.
 			audioFiles := findAudioFiles(t, setup.tempDir)
 			if tc.expectedDownloaded > 0 {
@@ -142,6 +157,56 @@ func TestDownloadTracks_MinQualityFilter(t *testing.T) {
 	}
 }
 
+// TestDownloadTracks_RecordsQualityDowngrades tests that tracks delivered below the requested quality
+// and tracks skipped by min_quality are listed in the downgrade report.
+func TestDownloadTracks_RecordsQualityDowngrades(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+		cfg.MinQuality = 2
+	})
+	defer setup.cleanup()
+
+	const (
+		flacTrackID = int64(1000)
+		highTrackID = int64(1001)
+		midTrackID  = int64(1002)
+	)
+
+	metadata := newTestMetadata([]int64{flacTrackID, highTrackID, midTrackID}, 100).
+		withTrackQuality(flacTrackID, TrackQualityFLACString, true).
+		withTrackQuality(highTrackID, TrackQualityMP3HighString, false).
+		withTrackQuality(midTrackID, TrackQualityMP3MidString, false).
+		build()
+
+	setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityFLACString, "/streamfl?id=1000")
+	setupMockFetchTrack(setup.mockClient, "/streamfl?id=1000", []byte("flac audio"))
+	setupMockStreamMetadata(setup.mockClient, "1001", TrackQualityMP3HighString, "/streamhq?id=1001")
+	setupMockFetchTrack(setup.mockClient, "/streamhq?id=1001", []byte("mp3 audio"))
+
+	impl, ok := setup.service.(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.downloadTracks(context.Background(), metadata)
+
+	downgrades := impl.Statistics().QualityDowngrades
+	require.Len(t, downgrades, 2)
+
+	byTrackID := make(map[string]*QualityDowngrade, len(downgrades))
+	for _, item := range downgrades {
+		byTrackID[item.TrackID] = item
+	}
+
+	require.Contains(t, byTrackID, "1001")
+	assert.Equal(t, TrackQualityFLAC, byTrackID["1001"].RequestedQuality)
+	assert.Equal(t, TrackQualityMP3High, byTrackID["1001"].AvailableQuality)
+	assert.False(t, byTrackID["1001"].IsSkipped)
+
+	require.Contains(t, byTrackID, "1002")
+	assert.Equal(t, TrackQualityMP3Mid, byTrackID["1002"].AvailableQuality)
+	assert.True(t, byTrackID["1002"].IsSkipped)
+}
+
 // TestDownloadTracks_MinDurationFilter tests that tracks below minimum duration are skipped.
 func TestDownloadTracks_MinDurationFilter(t *testing.T) {
 	t.Parallel()
@@ -232,23 +297,23 @@ func TestDownloadTracks_MinDurationFilter(t *testing.T) {
 			impl.downloadTracks(ctx, metadata)
 
 			// Verify statistics.
-			assert.Equal(t, tc.expectedSkipped, impl.stats.TracksSkipped,
+			assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkipped,
 				"Expected %d tracks skipped", tc.expectedSkipped)
-			assert.Equal(t, tc.expectedDownloaded, impl.stats.TracksDownloaded,
+			assert.Equal(t, tc.expectedDownloaded, impl.Statistics().TracksDownloaded,
 				"Expected %d tracks downloaded", tc.expectedDownloaded)
 
 			// Verify skip reason breakdown.
 			if tc.expectedSkipped > 0 {
-				assert.Equal(t, tc.expectedSkipped, impl.stats.TracksSkippedDuration,
+				assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkippedDuration,
 					"All skipped tracks should be due to duration filter")
 			}
 
 			// Verify error message if track was skipped.
 			if tc.expectedSkipped > 0 && tc.expectedErrorContains != "" {
-				require.NotEmpty(t, impl.stats.Errors, "Should have recorded an error")
-				assert.Contains(t, impl.stats.Errors[0].Error.Error(), tc.expectedErrorContains,
+				require.NotEmpty(t, impl.Statistics().Errors, "Should have recorded an error")
+				assert.Contains(t, impl.Statistics().Errors[0].Error.Error(), tc.expectedErrorContains,
 					"Error message should mention duration threshold")
-				assert.Equal(t, "duration check", impl.stats.Errors[0].Phase,
+				assert.Equal(t, "duration check", impl.Statistics().Errors[0].Phase,
 					"Error phase should be 'duration check'")
 			}
 
@@ -353,26 +418,34 @@ func TestDownloadTracks_MaxDurationFilter(t *testing.T) {
 			impl.downloadTracks(ctx, metadata)
 
 			// Verify statistics.
-			assert.Equal(t, tc.expectedSkipped, impl.stats.TracksSkipped,
+			assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkipped,
 				"Expected %d tracks skipped", tc.expectedSkipped)
-			assert.Equal(t, tc.expectedDownloaded, impl.stats.TracksDownloaded,
+			assert.Equal(t, tc.expectedDownloaded, impl.Statistics().TracksDownloaded,
 				"Expected %d tracks downloaded", tc.expectedDownloaded)
 
 			// Verify skip reason breakdown.
 			if tc.expectedSkipped > 0 {
-				assert.Equal(t, tc.expectedSkipped, impl.stats.TracksSkippedDuration,
+				assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkippedDuration,
 					"All skipped tracks should be due to duration filter")
 			}
 
 			// Verify error message if track was skipped.
 			if tc.expectedSkipped > 0 && tc.expectedErrorContains != "" {
-				require.NotEmpty(t, impl.stats.Errors, "Should have recorded an error")
-				assert.Contains(t, impl.stats.Errors[0].Error.Error(), tc.expectedErrorContains,
+				require.NotEmpty(t, impl.Statistics().Errors, "Should have recorded an error")
+				assert.Contains(t, impl.Statistics().Errors[0].Error.Error(), tc.expectedErrorContains,
 					"Error message should mention duration threshold")
-				assert.Equal(t, "duration check", impl.stats.Errors[0].Phase,
+				assert.Equal(t, "duration check", impl.Statistics().Errors[0].Phase,
 					"Error phase should be 'duration check'")
 			}
 
+			// Verify per-item skip entry.
+			if tc.expectedSkipped > 0 {
+				require.Len(t, impl.Statistics().SkippedItems, 1, "Should have recorded a skipped item")
+				assert.Equal(t, "3000", impl.Statistics().SkippedItems[0].TrackID)
+				assert.Equal(t, SkipReasonDuration, impl.Statistics().SkippedItems[0].Reason)
+				assert.Equal(t, "max "+setup.config.ParsedMaxDuration.String(), impl.Statistics().SkippedItems[0].Threshold)
+			}
+
 			// Verify files.
 			audioFiles := findAudioFiles(t, setup.tempDir)
 			if tc.expectedDownloaded > 0 {
@@ -383,3 +456,269 @@ func TestDownloadTracks_MaxDurationFilter(t *testing.T) {
 		})
 	}
 }
+
+// TestDownloadTracks_ExcludePatterns tests that tracks whose title or artist matches an exclude pattern are skipped.
+func TestDownloadTracks_ExcludePatterns(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		patterns           []string
+		title              string
+		artists            []string
+		expectedSkipped    int64
+		expectedDownloaded int64
+	}{
+		{
+			name:               "No patterns - download remix",
+			title:              "Sonne (Remix)",
+			artists:            []string{"Rammstein"},
+			expectedDownloaded: 1,
+		},
+		{
+			name:            "Title matches - skip remix",
+			patterns:        []string{`(?i)\bremix\b`},
+			title:           "Sonne (Remix)",
+			artists:         []string{"Rammstein"},
+			expectedSkipped: 1,
+		},
+		{
+			name:            "Artist matches - skip karaoke",
+			patterns:        []string{`(?i)intro`, `(?i)karaoke`},
+			title:           "Sonne",
+			artists:         []string{"Karaoke Stars"},
+			expectedSkipped: 1,
+		},
+		{
+			name:               "Nothing matches - download track",
+			patterns:           []string{`(?i)\bremix\b`},
+			title:              "Sonne",
+			artists:            []string{"Rammstein"},
+			expectedDownloaded: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+				cfg.ExcludePatterns = tc.patterns
+				for _, pattern := range tc.patterns {
+					cfg.ParsedExcludePatterns = append(cfg.ParsedExcludePatterns, regexp.MustCompile(pattern))
+				}
+			})
+			defer setup.cleanup()
+
+			trackID := int64(4000)
+			metadata := newTestMetadata([]int64{trackID}, 400).
+				withAlbumTitle("Exclusion Test Album").
+				build()
+			metadata.tracksMetadata["4000"].Title = tc.title
+			metadata.tracksMetadata["4000"].ArtistNames = tc.artists
+
+			// Setup mock expectations only if track should be downloaded.
+			if tc.expectedDownloaded > 0 {
+				trackIDString := "4000"
+				streamURL := "/streamfl?id=" + trackIDString
+				setupMockStreamMetadata(setup.mockClient, trackIDString, TrackQualityFLACString, streamURL)
+				setupMockFetchTrack(setup.mockClient, streamURL, []byte("test audio data"))
+			}
+
+			ctx := context.Background()
+			impl, ok := setup.service.(*ServiceImpl)
+			require.True(t, ok, "service must be of type *ServiceImpl")
+
+			impl.downloadTracks(ctx, metadata)
+
+			assert.Equal(t, tc.expectedSkipped, impl.Statistics().TracksSkippedExcluded)
+			assert.Equal(t, tc.expectedDownloaded, impl.Statistics().TracksDownloaded)
+
+			if tc.expectedSkipped > 0 {
+				require.NotEmpty(t, impl.Statistics().Errors, "Should have recorded an error")
+				assert.ErrorIs(t, impl.Statistics().Errors[0].Error, ErrExcludedByPattern)
+				assert.Equal(t, "exclusion check", impl.Statistics().Errors[0].Phase)
+
+				require.Len(t, impl.Statistics().SkippedItems, 1, "Should have recorded a skipped item")
+				assert.Equal(t, SkipReasonExcluded, impl.Statistics().SkippedItems[0].Reason)
+			}
+		})
+	}
+}
+
+// TestDownloadTracks_Blocklist tests that the tracks whose ID, release, or artist is on the blocklist are skipped
+// and counted for the blocklist rule.
+func TestDownloadTracks_Blocklist(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+		blocklist, err := config.ParseBlocklist([]string{"4001", "artist:karaoke stars"})
+		require.NoError(t, err)
+
+		cfg.ParsedBlocklist = blocklist
+	})
+	defer setup.cleanup()
+
+	metadata := newTestMetadata([]int64{4000, 4001, 4002}, 400).
+		withAlbumTitle("Blocklist Test Album").
+		build()
+	metadata.tracksMetadata["4002"].ArtistNames = []string{"Karaoke Stars"}
+
+	setupMockStreamMetadata(setup.mockClient, "4000", TrackQualityFLACString, "/streamfl?id=4000")
+	setupMockFetchTrack(setup.mockClient, "/streamfl?id=4000", []byte("test audio data"))
+
+	impl, ok := setup.service.(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.downloadTracks(context.Background(), metadata)
+
+	stats := impl.Statistics()
+	assert.Equal(t, int64(1), stats.TracksDownloaded)
+	assert.Equal(t, int64(2), stats.TracksSkippedBlocked)
+	assert.Equal(t, map[string]int64{ValidationRuleBlocklist: 2}, stats.TracksSkippedByRule)
+
+	require.Len(t, stats.Errors, 2)
+
+	for _, downloadErr := range stats.Errors {
+		assert.ErrorIs(t, downloadErr.Error, ErrBlocklisted)
+		assert.Equal(t, "blocklist check", downloadErr.Phase)
+	}
+}
+
+// TestDownloadTracks_QualityFallback tests that a track whose FLAC stream fails is downloaded in MP3 320
+// when quality_fallback is enabled, and that min_quality still limits the fallback.
+func TestDownloadTracks_QualityFallback(t *testing.T) {
+	t.Parallel()
+
+	errStreamRefused := fmt.Errorf("%w: %d", zvuk.ErrUnexpectedHTTPStatus, http.StatusNotFound)
+
+	testCases := []struct {
+		name               string
+		minQuality         uint8
+		streamErr          error
+		expectedDownloaded int64
+		expectedFailed     int64
+	}{
+		{
+			name:               "falls back to MP3 320",
+			minQuality:         2,
+			streamErr:          errStreamRefused,
+			expectedDownloaded: 1,
+		},
+		{
+			name:           "does not go below min_quality",
+			minQuality:     3,
+			streamErr:      errStreamRefused,
+			expectedFailed: 1,
+		},
+		{
+			name:           "does not fall back on network errors",
+			minQuality:     2,
+			streamErr:      errors.New("connection reset by peer"),
+			expectedFailed: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+				cfg.MinQuality = tc.minQuality
+				cfg.QualityFallback = true
+			})
+			defer setup.cleanup()
+
+			const trackID = int64(1000)
+
+			metadata := newTestMetadata([]int64{trackID}, 100).
+				withTrackQuality(trackID, TrackQualityFLACString, true).
+				build()
+
+			setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityFLACString, "/streamfl?id=1000")
+			setup.mockClient.EXPECT().
+				FetchTrack(gomock.Any(), "/streamfl?id=1000").
+				Return(nil, tc.streamErr).
+				AnyTimes()
+
+			if tc.expectedDownloaded > 0 {
+				setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityMP3HighString, "/streamhq?id=1000")
+				setupMockFetchTrack(setup.mockClient, "/streamhq?id=1000", []byte("mp3 audio"))
+			}
+
+			impl, ok := setup.service.(*ServiceImpl)
+			require.True(t, ok, "service must be of type *ServiceImpl")
+
+			impl.downloadTracks(context.Background(), metadata)
+
+			stats := impl.Statistics()
+			assert.Equal(t, tc.expectedDownloaded, stats.TracksDownloaded)
+			assert.Equal(t, tc.expectedFailed, stats.TracksFailed)
+
+			if tc.expectedDownloaded == 0 {
+				assert.Empty(t, stats.QualityDowngrades)
+
+				return
+			}
+
+			require.Len(t, stats.QualityDowngrades, 1)
+			assert.Equal(t, TrackQualityFLAC, stats.QualityDowngrades[0].RequestedQuality)
+			assert.Equal(t, TrackQualityMP3High, stats.QualityDowngrades[0].AvailableQuality)
+			assert.True(t, stats.QualityDowngrades[0].IsStreamFallback)
+
+			audioFiles := findAudioFiles(t, setup.tempDir)
+			require.Len(t, audioFiles, 1)
+			assert.Equal(t, ".mp3", filepath.Ext(audioFiles[0]))
+		})
+	}
+}
+
+// TestDownloadTracks_QualityFallbackTwoSteps tests that a track falling back twice, from FLAC to MP3 320 to MP3 128,
+// is recorded as a single downgrade to the quality it was finally downloaded in.
+func TestDownloadTracks_QualityFallbackTwoSteps(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+		cfg.MinQuality = 1
+		cfg.QualityFallback = true
+	})
+	defer setup.cleanup()
+
+	const trackID = int64(1000)
+
+	metadata := newTestMetadata([]int64{trackID}, 100).
+		withTrackQuality(trackID, TrackQualityFLACString, true).
+		build()
+
+	errStreamRefused := fmt.Errorf("%w: %d", zvuk.ErrUnexpectedHTTPStatus, http.StatusNotFound)
+
+	setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityFLACString, "/streamfl?id=1000")
+	setup.mockClient.EXPECT().
+		FetchTrack(gomock.Any(), "/streamfl?id=1000").
+		Return(nil, errStreamRefused).
+		AnyTimes()
+
+	setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityMP3HighString, "/streamhq?id=1000")
+	setup.mockClient.EXPECT().
+		FetchTrack(gomock.Any(), "/streamhq?id=1000").
+		Return(nil, errStreamRefused).
+		AnyTimes()
+
+	setupMockStreamMetadata(setup.mockClient, "1000", TrackQualityMP3MidString, "/streammid?id=1000")
+	setupMockFetchTrack(setup.mockClient, "/streammid?id=1000", []byte("mp3 audio"))
+
+	impl, ok := setup.service.(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.downloadTracks(context.Background(), metadata)
+
+	stats := impl.Statistics()
+	assert.Equal(t, int64(1), stats.TracksDownloaded)
+	assert.Zero(t, stats.TracksFailed)
+
+	require.Len(t, stats.QualityDowngrades, 1)
+	assert.Equal(t, TrackQualityFLAC, stats.QualityDowngrades[0].RequestedQuality)
+	assert.Equal(t, TrackQualityMP3Mid, stats.QualityDowngrades[0].AvailableQuality)
+	assert.True(t, stats.QualityDowngrades[0].IsStreamFallback)
+	assert.False(t, stats.QualityDowngrades[0].IsSkipped)
+}
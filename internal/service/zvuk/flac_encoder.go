@@ -0,0 +1,111 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// FLACEncoder re-encodes FLAC files at a chosen compression level.
+type FLACEncoder interface {
+	// Reencode replaces the FLAC file at path with a copy encoded at the configured compression level.
+	Reencode(ctx context.Context, path string) error
+}
+
+// FFmpegFLACEncoder re-encodes FLAC files with ffmpeg.
+type FFmpegFLACEncoder struct {
+	// ffmpegPath is the path to the ffmpeg executable.
+	ffmpegPath string
+	// compressionLevel is the FLAC compression level, from 0 (fastest) to 12 (smallest).
+	compressionLevel int64
+}
+
+// NewFLACEncoder creates a FLAC encoder backed by ffmpeg.
+func NewFLACEncoder(cfg *config.Config) FLACEncoder {
+	return &FFmpegFLACEncoder{
+		ffmpegPath:       cfg.FFmpegPath,
+		compressionLevel: cfg.ParsedFLACCompressionLevel,
+	}
+}
+
+// Reencode decodes the FLAC file and encodes it again at the compression level.
+// The audio is lossless either way, so only the size and the encoder settings change.
+// Tags and embedded pictures are copied, and the file is replaced only once the new one is complete.
+func (e *FFmpegFLACEncoder) Reencode(ctx context.Context, path string) error {
+	tempPath := path + ffmpegTempSuffix
+
+	args := []string{
+		"-hide_banner", "-nostats", "-y",
+		"-i", path,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-c:v", "copy",
+		"-c:a", "flac",
+		"-compression_level", strconv.FormatInt(e.compressionLevel, 10),
+		"-f", "flac",
+		tempPath,
+	}
+
+	if _, err := runFFmpeg(ctx, e.ffmpegPath, args); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to encode FLAC: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to replace FLAC with the re-encoded one: %w", err)
+	}
+
+	return nil
+}
+
+// checkFLACEncoder verifies that ffmpeg is available before any download starts.
+func (s *ServiceImpl) checkFLACEncoder() error {
+	if s.flacEncoder == nil || s.cfg.DryRun {
+		return nil
+	}
+
+	if _, err := exec.LookPath(s.cfg.FFmpegPath); err != nil {
+		return fmt.Errorf("ffmpeg is required for flac_compression_level: %w", err)
+	}
+
+	return nil
+}
+
+// reencodeFLAC re-encodes a downloaded FLAC track at flac_compression_level before its tags are written.
+// A track that fails to re-encode is recorded as an error and kept as downloaded.
+func (s *ServiceImpl) reencodeFLAC(ctx context.Context, t *downloadTrackTask, tempPath string) {
+	if s.flacEncoder == nil || s.cfg.DryRun || t.quality != TrackQualityFLAC {
+		return
+	}
+
+	if err := s.flacEncoder.Reencode(ctx, tempPath); err != nil {
+		s.handleError(ctx, &DownloadError{
+			Category:       DownloadCategoryTrack,
+			ItemID:         t.trackIDString,
+			ItemTitle:      t.track.Title,
+			ParentCategory: t.metadata.category,
+			ParentID:       t.parentID,
+			ParentTitle:    t.parentTitle,
+			Phase:          "re-encoding FLAC",
+			Error:          err,
+		}, false)
+	}
+}
+
+// flacPadding returns the size of the padding block written to FLAC files, or nil to keep their padding.
+func (s *ServiceImpl) flacPadding() *int64 {
+	if s.cfg.FLACPadding == "" {
+		return nil
+	}
+
+	padding := s.cfg.ParsedFLACPadding
+
+	return &padding
+}
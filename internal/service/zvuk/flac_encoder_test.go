@@ -0,0 +1,110 @@
+package zvuk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-flac/go-flac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// recordingFLACEncoder records the files it is asked to re-encode.
+type recordingFLACEncoder struct {
+	// paths are the re-encoded files.
+	paths []string
+}
+
+// Reencode records the path.
+func (e *recordingFLACEncoder) Reencode(_ context.Context, path string) error {
+	e.paths = append(e.paths, path)
+
+	return nil
+}
+
+// TestReencodeFLAC verifies that only FLAC tracks are re-encoded.
+func TestReencodeFLAC(t *testing.T) {
+	t.Parallel()
+
+	encoder := new(recordingFLACEncoder)
+	s := &ServiceImpl{cfg: &config.Config{}, flacEncoder: encoder, stats: newStatsCollector()}
+
+	newTask := func(quality TrackQuality) *downloadTrackTask {
+		return &downloadTrackTask{
+			quality:  quality,
+			track:    &zvuk.Track{Title: "Sonne"},
+			metadata: &downloadTracksMetadata{category: DownloadCategoryAlbum},
+		}
+	}
+
+	s.reencodeFLAC(t.Context(), newTask(TrackQualityFLAC), "sonne.flac.tmp")
+	s.reencodeFLAC(t.Context(), newTask(TrackQualityMP3High), "mutter.mp3.tmp")
+
+	assert.Equal(t, []string{"sonne.flac.tmp"}, encoder.paths)
+}
+
+// TestWriteTags_FLACPadding verifies that the padding of a FLAC file is replaced with a single block of the set size.
+func TestWriteTags_FLACPadding(t *testing.T) {
+	t.Parallel()
+
+	paddingSize := func(padding int64) *int64 {
+		return &padding
+	}
+
+	tests := []struct {
+		name          string
+		padding       *int64
+		expectedSizes []int
+	}{
+		{name: "kept", padding: nil, expectedSizes: []int{100, 200}},
+		{name: "replaced", padding: paddingSize(8192), expectedSizes: []int{8192}},
+		{name: "removed", padding: paddingSize(0), expectedSizes: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "04 - Sonne.flac")
+			writeTestFLAC(t, path, 1, 1000, map[string]string{"TITLE": "Sonne"})
+
+			// Two padding blocks, as left by some encoders.
+			f, err := flac.ParseFile(path)
+			require.NoError(t, err)
+
+			f.Meta = append(f.Meta,
+				&flac.MetaDataBlock{Type: flac.Padding, Data: make([]byte, 100)},
+				&flac.MetaDataBlock{Type: flac.Padding, Data: make([]byte, 200)})
+			require.NoError(t, f.Save(path))
+
+			err = NewTagProcessor().WriteTags(t.Context(), &WriteTagsRequest{
+				TrackPath:   path,
+				Quality:     TrackQualityFLAC,
+				TrackTags:   map[string]string{TagTrackTitle: "Sonne"},
+				FLACPadding: tt.padding,
+			})
+			require.NoError(t, err)
+
+			f, err = flac.ParseFile(path)
+			require.NoError(t, err)
+
+			var sizes []int
+
+			for _, meta := range f.Meta {
+				if meta.Type == flac.Padding {
+					sizes = append(sizes, len(meta.Data))
+				}
+			}
+
+			assert.Equal(t, tt.expectedSizes, sizes)
+
+			if tt.padding != nil && *tt.padding > 0 {
+				assert.Equal(t, flac.Padding, f.Meta[len(f.Meta)-1].Type, "the padding must be the last block")
+			}
+		})
+	}
+}
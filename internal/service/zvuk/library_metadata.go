@@ -0,0 +1,234 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// LibraryFileProblem describes a file of a downloaded library that a command left as it is.
+type LibraryFileProblem struct {
+	// Path is the location of the audio file.
+	Path string
+	// TrackID is the track ID read from the file tags (empty if unknown).
+	TrackID string
+	// Reason describes why the file was left as it is.
+	Reason string
+}
+
+// libraryScan is the result of reading the tags of the audio files in a folder.
+type libraryScan struct {
+	// filesFound is the number of audio files found.
+	filesFound int
+	// files are the files with a track ID.
+	files []*libraryFile
+	// skipped lists the files without a track ID.
+	skipped []*LibraryFileProblem
+	// failed lists the files that cannot be parsed.
+	failed []*LibraryFileProblem
+}
+
+// libraryMetadata is the fresh metadata of the files of a library.
+type libraryMetadata struct {
+	// tracks contains track metadata mapped by track ID.
+	tracks map[string]*zvuk.Track
+	// releases contains release metadata mapped by release ID.
+	releases map[string]*zvuk.Release
+	// playlists contains playlist metadata mapped by playlist ID.
+	playlists map[string]*zvuk.Playlist
+}
+
+// libraryTrack is a file of a library matched with its fresh metadata.
+type libraryTrack struct {
+	// file is the information read from the file.
+	file *libraryFile
+	// task holds the track with its release, as during a download.
+	task *downloadTrackTask
+	// collection is the album or playlist the file was saved with.
+	collection *audioCollection
+	// trackTags are the tags of the track, as during a download.
+	trackTags map[string]string
+}
+
+// scanLibrary reads the tags of the FLAC and MP3 files in the folder.
+func scanLibrary(ctx context.Context, dir string) (*libraryScan, error) {
+	paths, err := findLibraryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &libraryScan{
+		filesFound: len(paths),
+		files:      make([]*libraryFile, 0, len(paths)),
+	}
+
+	for _, path := range paths {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file, issue := inspectLibraryFile(path)
+		if issue != nil {
+			result.failed = append(result.failed, &LibraryFileProblem{Path: path, Reason: issue.Detail})
+
+			continue
+		}
+
+		if file.trackID == "" {
+			result.skipped = append(result.skipped, &LibraryFileProblem{Path: path, Reason: "no TRACK_ID tag"})
+
+			continue
+		}
+
+		result.files = append(result.files, file)
+	}
+
+	return result, nil
+}
+
+// sortLibraryFileProblems sorts the problems by path.
+func sortLibraryFileProblems(problems []*LibraryFileProblem) {
+	slices.SortStableFunc(problems, func(a, b *LibraryFileProblem) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// fetchLibraryMetadata fetches the tracks of the files, with their releases and the playlists they were saved with.
+func (s *ServiceImpl) fetchLibraryMetadata(ctx context.Context, files []*libraryFile) (*libraryMetadata, error) {
+	tracks, err := fetchLibraryTracks(ctx, s.cfg, s.zvukClient, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseIDs, playlistIDs []string
+
+	for _, track := range tracks {
+		releaseID := strconv.FormatInt(track.ReleaseID, 10)
+		if !slices.Contains(releaseIDs, releaseID) {
+			releaseIDs = append(releaseIDs, releaseID)
+		}
+	}
+
+	for _, file := range files {
+		if file.playlistID != "" && !slices.Contains(playlistIDs, file.playlistID) {
+			playlistIDs = append(playlistIDs, file.playlistID)
+		}
+	}
+
+	batchSize := int(s.cfg.MetadataBatchSize)
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataBatchSize
+	}
+
+	result := &libraryMetadata{
+		tracks:    tracks,
+		releases:  make(map[string]*zvuk.Release, len(releaseIDs)),
+		playlists: make(map[string]*zvuk.Playlist, len(playlistIDs)),
+	}
+
+	for batch := range slices.Chunk(releaseIDs, batchSize) {
+		response, albumsErr := s.zvukClient.GetAlbumsMetadata(ctx, batch, false)
+		if albumsErr != nil {
+			return nil, fmt.Errorf("failed to get albums metadata: %w", albumsErr)
+		}
+
+		for id, release := range response.Releases {
+			result.releases[id] = release
+		}
+	}
+
+	for batch := range slices.Chunk(playlistIDs, batchSize) {
+		response, playlistsErr := s.zvukClient.GetPlaylistsMetadata(ctx, batch)
+		if playlistsErr != nil {
+			return nil, fmt.Errorf("failed to get playlists metadata: %w", playlistsErr)
+		}
+
+		for id, playlist := range response.Playlists {
+			result.playlists[id] = playlist
+		}
+	}
+
+	return result, nil
+}
+
+// resolveLibraryTrack matches the file with its fresh metadata and builds its tags as during a download.
+// Files saved with a playlist keep the track number they were saved with.
+// It returns why the file cannot be matched when the track, its release, or its playlist is not found.
+func (s *ServiceImpl) resolveLibraryTrack(file *libraryFile, metadata *libraryMetadata) (*libraryTrack, string) {
+	track, ok := metadata.tracks[file.trackID]
+	if !ok || track == nil {
+		return nil, "track is not found"
+	}
+
+	release, ok := metadata.releases[strconv.FormatInt(track.ReleaseID, 10)]
+	if !ok || release == nil {
+		return nil, "release is not found"
+	}
+
+	t := &downloadTrackTask{
+		track:         track,
+		trackIDString: file.trackID,
+		album:         release,
+		albumTags:     s.albumHandler.FillTags(release),
+	}
+
+	collection := &audioCollection{
+		category:    DownloadCategoryAlbum,
+		id:          strconv.FormatInt(release.ID, 10),
+		title:       release.Title,
+		tags:        t.albumTags,
+		trackIDs:    release.TrackIDs,
+		tracksPath:  filepath.Dir(file.path),
+		tracksCount: int64(len(release.TrackIDs)),
+	}
+
+	trackNumber := track.Position
+	if trackNumber <= 0 {
+		trackNumber = file.trackNumber
+	}
+
+	if file.playlistID != "" {
+		playlist, isFound := metadata.playlists[file.playlistID]
+		if !isFound || playlist == nil {
+			return nil, "playlist is not found"
+		}
+
+		collection.category = DownloadCategoryPlaylist
+		collection.id = file.playlistID
+		collection.title = playlist.Title
+		collection.tags = s.playlistHandler.FillTags(playlist)
+		collection.trackIDs = playlist.TrackIDs
+		collection.tracksCount = int64(len(playlist.TrackIDs))
+		collection.playlistPositions = newPlaylistPositions(playlist.TrackIDs)
+
+		// The position in a playlist changes as it is edited, so the number the file was saved with is kept.
+		trackNumber = file.trackNumber
+		if trackNumber <= 0 {
+			trackNumber = int64(slices.Index(playlist.TrackIDs, track.ID) + 1)
+		}
+	}
+
+	t.trackPosition = trackNumber
+
+	trackTags := buildTrackTags(&trackTagContext{
+		trackNumber:     trackNumber,
+		track:           track,
+		audioCollection: collection,
+		albumTags:       t.albumTags,
+		category:        collection.category,
+		artistJoinStyle: s.cfg.ArtistJoinStyle,
+	})
+
+	return &libraryTrack{
+		file:       file,
+		task:       t,
+		collection: collection,
+		trackTags:  trackTags,
+	}, ""
+}
@@ -0,0 +1,89 @@
+package zvuk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// downloadSlotsPollInterval is how often a track waiting for a download slot checks whether
+// max_concurrent_downloads was raised during the run.
+const downloadSlotsPollInterval = time.Second
+
+// downloadSlots limits the tracks of a collection downloaded at once to a limit that may change during the run.
+type downloadSlots struct {
+	// limit returns the current number of tracks downloaded at once.
+	limit func() int64
+	// used is the number of slots taken, protected by mutex.
+	used int64
+	// released is closed and replaced every time a slot is released, protected by mutex.
+	released chan struct{}
+	// mutex protects used and released.
+	mutex sync.Mutex
+}
+
+// newDownloadSlots creates download slots following the given limit.
+func newDownloadSlots(limit func() int64) *downloadSlots {
+	return &downloadSlots{
+		limit:    limit,
+		released: make(chan struct{}),
+	}
+}
+
+// acquire waits for a free slot. It returns false if the context is canceled while waiting.
+func (d *downloadSlots) acquire(ctx context.Context) bool {
+	for {
+		d.mutex.Lock()
+
+		if d.used < max(d.limit(), 1) {
+			d.used++
+			d.mutex.Unlock()
+
+			return true
+		}
+
+		released := d.released
+		d.mutex.Unlock()
+
+		select {
+		case <-released:
+		case <-time.After(downloadSlotsPollInterval):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// release frees a slot taken by acquire.
+func (d *downloadSlots) release() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.used--
+
+	close(d.released)
+	d.released = make(chan struct{})
+}
+
+// SetDownloadSpeedLimit changes the download speed limit in bytes per second (0 is unlimited) during the run.
+func (s *ServiceImpl) SetDownloadSpeedLimit(limit int64) {
+	if downloader, ok := s.downloader.(speedLimitSetter); ok {
+		downloader.setSpeedLimit(limit)
+	}
+}
+
+// SetMaxConcurrentDownloads changes the number of tracks downloaded at once during the run.
+// The tracks in progress are finished; the limit applies to the tracks started next.
+func (s *ServiceImpl) SetMaxConcurrentDownloads(limit int64) {
+	s.maxConcurrentDownloads.Store(max(limit, 1))
+}
+
+// concurrentDownloads returns the number of tracks downloaded at once:
+// max_concurrent_downloads, or the value set by SetMaxConcurrentDownloads during the run.
+func (s *ServiceImpl) concurrentDownloads() int64 {
+	if limit := s.maxConcurrentDownloads.Load(); limit > 0 {
+		return limit
+	}
+
+	return s.cfg.MaxConcurrentDownloads
+}
@@ -0,0 +1,90 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestDownloadSlots_FollowsChangedLimit verifies that the slots wait for a release and honor a raised limit.
+func TestDownloadSlots_FollowsChangedLimit(t *testing.T) {
+	t.Parallel()
+
+	var limit atomic.Int64
+
+	limit.Store(1)
+
+	slots := newDownloadSlots(limit.Load)
+
+	require.True(t, slots.acquire(t.Context()))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.False(t, slots.acquire(ctx), "the only slot is taken")
+
+	limit.Store(2)
+	require.True(t, slots.acquire(t.Context()), "a raised limit frees a slot")
+
+	acquired := make(chan bool, 1)
+
+	go func() {
+		acquired <- slots.acquire(t.Context())
+	}()
+
+	slots.release()
+
+	select {
+	case ok := <-acquired:
+		assert.True(t, ok)
+	case <-time.After(downloadSlotsPollInterval / 2):
+		t.Fatal("a released slot must wake the waiting track")
+	}
+}
+
+// TestSetMaxConcurrentDownloads verifies that the number of tracks downloaded at once can be changed during the run.
+func TestSetMaxConcurrentDownloads(t *testing.T) {
+	t.Parallel()
+
+	s := &ServiceImpl{cfg: &config.Config{MaxConcurrentDownloads: 3}}
+	assert.Equal(t, int64(3), s.concurrentDownloads(), "falls back to the configuration")
+
+	s.SetMaxConcurrentDownloads(5)
+	assert.Equal(t, int64(5), s.concurrentDownloads())
+
+	s.SetMaxConcurrentDownloads(0)
+	assert.Equal(t, int64(1), s.concurrentDownloads(), "at least one track is downloaded")
+}
+
+// TestCopyWithSpeedLimit_ReadsChangedLimit verifies that lifting the limit applies to the transfer in progress.
+func TestCopyWithSpeedLimit_ReadsChangedLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls  atomic.Int64
+		writer bytes.Buffer
+	)
+
+	// The first chunk is throttled, then the limit is lifted.
+	limit := func() int64 {
+		if calls.Add(1) == 1 {
+			return 4
+		}
+
+		return 0
+	}
+
+	written, err := copyWithSpeedLimit(&writer, strings.NewReader("0123456789"), limit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), written)
+	assert.Equal(t, "0123456789", writer.String())
+	assert.Equal(t, int64(2), calls.Load())
+}
@@ -0,0 +1,242 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// loudnormTruePeak is the maximum true peak of normalized copies, in dBTP.
+	loudnormTruePeak = -1.5
+	// loudnormLoudnessRange is the target loudness range of normalized copies, in LU.
+	loudnormLoudnessRange = 11
+	// loudnormSampleRate is the output sample rate: loudnorm resamples to 192 kHz internally,
+	// and 44.1 kHz is supported by every car stereo and phone.
+	loudnormSampleRate = "44100"
+	// loudnormMP3Bitrate is the bitrate used when re-encoding MP3 tracks.
+	loudnormMP3Bitrate = "320k"
+)
+
+// ErrLoudnormMeasurementMissing indicates that the first loudnorm pass did not print its measurements.
+var ErrLoudnormMeasurementMissing = errors.New("loudnorm measurement not found in ffmpeg output")
+
+// LoudnessNormalizer produces loudness-normalized copies of audio files.
+type LoudnessNormalizer interface {
+	// Normalize writes a normalized copy of inputPath to outputPath.
+	Normalize(ctx context.Context, inputPath, outputPath string) error
+}
+
+// FFmpegLoudnessNormalizer normalizes audio with the two-pass ffmpeg loudnorm filter.
+type FFmpegLoudnessNormalizer struct {
+	// ffmpegPath is the path to the ffmpeg executable.
+	ffmpegPath string
+	// targetLUFS is the integrated loudness target.
+	targetLUFS float64
+}
+
+// loudnormMeasurement contains the values printed by the first (analysis) loudnorm pass.
+type loudnormMeasurement struct {
+	// InputIntegrated is the measured integrated loudness, in LUFS.
+	InputIntegrated string `json:"input_i"`
+	// InputTruePeak is the measured true peak, in dBTP.
+	InputTruePeak string `json:"input_tp"`
+	// InputLoudnessRange is the measured loudness range, in LU.
+	InputLoudnessRange string `json:"input_lra"`
+	// InputThreshold is the measured gating threshold, in LUFS.
+	InputThreshold string `json:"input_thresh"`
+	// TargetOffset is the gain offset required to hit the target after normalization.
+	TargetOffset string `json:"target_offset"`
+}
+
+// NewLoudnessNormalizer creates a loudness normalizer backed by ffmpeg.
+func NewLoudnessNormalizer(cfg *config.Config) LoudnessNormalizer {
+	return &FFmpegLoudnessNormalizer{
+		ffmpegPath: cfg.FFmpegPath,
+		targetLUFS: cfg.NormalizationTargetLUFS,
+	}
+}
+
+// Normalize measures the loudness of inputPath, then encodes a normalized copy to outputPath.
+// Tags and embedded cover art are copied from the original file.
+func (n *FFmpegLoudnessNormalizer) Normalize(ctx context.Context, inputPath, outputPath string) error {
+	measurement, err := n.measure(ctx, inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to measure loudness: %w", err)
+	}
+
+	tempPath := outputPath + ffmpegTempSuffix
+
+	args := []string{
+		"-hide_banner", "-nostats", "-y",
+		"-i", inputPath,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-c:v", "copy",
+		"-af", n.loudnormFilter(measurement),
+		"-ar", loudnormSampleRate,
+	}
+
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".flac":
+		args = append(args, "-c:a", "flac", "-f", "flac")
+	default:
+		args = append(args, "-c:a", "libmp3lame", "-b:a", loudnormMP3Bitrate, "-id3v2_version", "3", "-f", "mp3")
+	}
+
+	args = append(args, tempPath)
+
+	if _, err = runFFmpeg(ctx, n.ffmpegPath, args); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to encode normalized copy: %w", err)
+	}
+
+	if err = os.Rename(tempPath, outputPath); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to rename normalized copy: %w", err)
+	}
+
+	return nil
+}
+
+// measure runs the analysis pass and parses the JSON block loudnorm prints to stderr.
+func (n *FFmpegLoudnessNormalizer) measure(ctx context.Context, inputPath string) (*loudnormMeasurement, error) {
+	filter := fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s:print_format=json",
+		formatLoudnormValue(n.targetLUFS),
+		formatLoudnormValue(loudnormTruePeak),
+		formatLoudnormValue(loudnormLoudnessRange))
+
+	output, err := runFFmpeg(ctx, n.ffmpegPath, []string{
+		"-hide_banner", "-nostats",
+		"-i", inputPath,
+		"-map", "0:a:0",
+		"-af", filter,
+		"-f", "null", "-",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLoudnormMeasurement(output)
+}
+
+// loudnormFilter builds the second-pass filter that applies the measured values linearly.
+func (n *FFmpegLoudnessNormalizer) loudnormFilter(m *loudnormMeasurement) string {
+	return fmt.Sprintf(
+		"loudnorm=I=%s:TP=%s:LRA=%s:"+
+			"measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:"+
+			"linear=true:print_format=summary",
+		formatLoudnormValue(n.targetLUFS),
+		formatLoudnormValue(loudnormTruePeak),
+		formatLoudnormValue(loudnormLoudnessRange),
+		m.InputIntegrated,
+		m.InputTruePeak,
+		m.InputLoudnessRange,
+		m.InputThreshold,
+		m.TargetOffset,
+	)
+}
+
+// parseLoudnormMeasurement extracts the last JSON object from ffmpeg output.
+func parseLoudnormMeasurement(output []byte) (*loudnormMeasurement, error) {
+	start := bytes.LastIndexByte(output, '{')
+	end := bytes.LastIndexByte(output, '}')
+
+	if start < 0 || end < start {
+		return nil, ErrLoudnormMeasurementMissing
+	}
+
+	var result loudnormMeasurement
+	if err := json.Unmarshal(output[start:end+1], &result); err != nil {
+		return nil, fmt.Errorf("failed to parse loudnorm measurement: %w", err)
+	}
+
+	if result.InputIntegrated == "" || result.InputThreshold == "" {
+		return nil, ErrLoudnormMeasurementMissing
+	}
+
+	return &result, nil
+}
+
+// formatLoudnormValue formats a number in the shortest form accepted by ffmpeg filter options.
+func formatLoudnormValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// checkLoudnessNormalizer verifies that ffmpeg is available before any download starts.
+func (s *ServiceImpl) checkLoudnessNormalizer() error {
+	if s.loudnessNormalizer == nil || s.cfg.DryRun {
+		return nil
+	}
+
+	if _, err := exec.LookPath(s.cfg.FFmpegPath); err != nil {
+		return fmt.Errorf("ffmpeg is required for loudness normalization: %w", err)
+	}
+
+	return nil
+}
+
+// createNormalizedCopy writes a loudness-normalized copy of the downloaded track
+// to normalized_output_path, keeping the same relative path as in output_path.
+func (s *ServiceImpl) createNormalizedCopy(ctx context.Context, t *downloadTrackTask) {
+	if s.loudnessNormalizer == nil || s.cfg.DryRun {
+		return
+	}
+
+	relativePath, err := filepath.Rel(s.cfg.OutputPath, t.trackPath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		relativePath = filepath.Base(t.trackPath)
+	}
+
+	normalizedPath := filepath.Join(s.cfg.NormalizedOutputPath, relativePath)
+
+	if !s.cfg.ReplaceTracks {
+		if _, statErr := os.Stat(normalizedPath); statErr == nil {
+			logger.Infof(ctx, "Normalized copy '%s' already exists, skipping", normalizedPath)
+
+			return
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(normalizedPath), constants.DefaultFolderPermissions); err != nil {
+		s.recordNormalizationError(ctx, t, err)
+
+		return
+	}
+
+	if err = s.loudnessNormalizer.Normalize(ctx, t.trackPath, normalizedPath); err != nil {
+		s.recordNormalizationError(ctx, t, err)
+
+		return
+	}
+
+	s.incrementTrackNormalized()
+	logger.Infof(ctx, "Normalized copy saved to: %s", normalizedPath)
+}
+
+// recordNormalizationError records a failed normalization without failing the original download.
+func (s *ServiceImpl) recordNormalizationError(ctx context.Context, t *downloadTrackTask, err error) {
+	s.handleError(ctx, &DownloadError{
+		Category:       DownloadCategoryTrack,
+		ItemID:         t.trackIDString,
+		ItemTitle:      t.track.Title,
+		ParentCategory: t.metadata.category,
+		ParentID:       t.parentID,
+		ParentTitle:    t.parentTitle,
+		Phase:          "normalizing loudness",
+		Error:          err,
+	}, false)
+}
@@ -0,0 +1,118 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// fakeLoudnessNormalizer copies the input file instead of running ffmpeg.
+type fakeLoudnessNormalizer struct {
+	calls int
+}
+
+// Normalize copies the original so tests can check where the normalized copy is written.
+func (f *fakeLoudnessNormalizer) Normalize(_ context.Context, inputPath, outputPath string) error {
+	f.calls++
+
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputPath, content, constants.DefaultFilePermissions)
+}
+
+// TestParseLoudnormMeasurement tests parsing of the first loudnorm pass output.
+func TestParseLoudnormMeasurement(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`Input #0, flac, from 'track.flac':
+[Parsed_loudnorm_0 @ 0x5581]
+{
+	"input_i" : "-9.81",
+	"input_tp" : "0.12",
+	"input_lra" : "5.40",
+	"input_thresh" : "-19.95",
+	"output_i" : "-14.02",
+	"output_tp" : "-1.50",
+	"output_lra" : "4.90",
+	"output_thresh" : "-24.10",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.02"
+}
+`)
+
+	measurement, err := parseLoudnormMeasurement(output)
+	require.NoError(t, err)
+	assert.Equal(t, &loudnormMeasurement{
+		InputIntegrated:    "-9.81",
+		InputTruePeak:      "0.12",
+		InputLoudnessRange: "5.40",
+		InputThreshold:     "-19.95",
+		TargetOffset:       "0.02",
+	}, measurement)
+
+	_, err = parseLoudnormMeasurement([]byte("Conversion failed!"))
+	require.ErrorIs(t, err, ErrLoudnormMeasurementMissing)
+
+	normalizer := &FFmpegLoudnessNormalizer{targetLUFS: -14}
+	assert.Equal(t,
+		"loudnorm=I=-14:TP=-1.5:LRA=11:"+
+			"measured_I=-9.81:measured_TP=0.12:measured_LRA=5.40:measured_thresh=-19.95:offset=0.02:"+
+			"linear=true:print_format=summary",
+		normalizer.loudnormFilter(measurement))
+}
+
+// TestCreateNormalizedCopy tests that normalized copies mirror the original layout.
+func TestCreateNormalizedCopy(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+	normalizedOutputPath := t.TempDir()
+
+	cfg := &config.Config{
+		OutputPath:           outputPath,
+		NormalizedOutputPath: normalizedOutputPath,
+		ParsedLogLevel:       logger.Level(),
+	}
+
+	service, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok)
+
+	normalizer := new(fakeLoudnessNormalizer)
+	service.loudnessNormalizer = normalizer
+
+	trackPath := filepath.Join(outputPath, "2024 - Artist - Album", "01 - Track.flac")
+	require.NoError(t, os.MkdirAll(filepath.Dir(trackPath), constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(trackPath, []byte("audio"), constants.DefaultFilePermissions))
+
+	task := &downloadTrackTask{
+		trackPath:     trackPath,
+		trackIDString: "1",
+		track:         &zvuk.Track{ID: 1, Title: "Track"},
+		metadata:      &downloadTracksMetadata{category: DownloadCategoryAlbum},
+	}
+
+	ctx := context.Background()
+	service.createNormalizedCopy(ctx, task)
+
+	normalizedContent, err := os.ReadFile(filepath.Join(normalizedOutputPath, "2024 - Artist - Album", "01 - Track.flac"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("audio"), normalizedContent)
+	assert.Equal(t, int64(1), service.Statistics().TracksNormalized)
+
+	// An existing copy is kept unless replace_tracks is enabled.
+	service.createNormalizedCopy(ctx, task)
+	assert.Equal(t, 1, normalizer.calls)
+	assert.Empty(t, service.Statistics().Errors)
+}
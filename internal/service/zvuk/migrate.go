@@ -0,0 +1,508 @@
+package zvuk
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// migrateTempSuffix is appended to the files being moved while the migration is applied.
+const migrateTempSuffix = ".migrate"
+
+// ErrMigrationRolledBack is returned when a file cannot be moved and the files moved before it were moved back.
+var ErrMigrationRolledBack = errors.New("migration failed, the files were moved back")
+
+// LibraryMove is a file moved to the path computed from the current templates.
+type LibraryMove struct {
+	// From is the current location of the file.
+	From string
+	// To is the location of the file after the migration.
+	To string
+}
+
+// MigrateLibraryRequest contains the parameters of a library migration.
+type MigrateLibraryRequest struct {
+	// Dir is the folder the library was downloaded to, the root of the computed paths.
+	Dir string
+	// Confirm is called with the planned moves and reports whether they are applied.
+	Confirm func(moves []*LibraryMove) bool
+}
+
+// MigrateReport is the result of a library migration.
+type MigrateReport struct {
+	// FilesFound is the number of audio files found in the folder.
+	FilesFound int
+	// FilesInPlace is the number of audio files already at their computed paths.
+	FilesInPlace int
+	// Moves are the planned moves of the audio files and the files next to them, sorted by source path.
+	Moves []*LibraryMove
+	// IsApplied indicates that the moves were applied.
+	IsApplied bool
+	// Skipped lists the audio files whose paths cannot be computed, ordered by path.
+	Skipped []*LibraryFileProblem
+	// Conflicts lists the audio files left in place because their computed paths are taken, ordered by path.
+	Conflicts []*LibraryFileProblem
+}
+
+// migrationStep is a rename done while the migration is applied, undone on failure.
+type migrationStep struct {
+	// from is the path the file was renamed from.
+	from string
+	// to is the path the file was renamed to.
+	to string
+}
+
+// MigrateLibrary moves the files of a downloaded library to the paths computed from the current
+// folder and filename templates, using the metadata fetched by their TRACK_ID tags.
+// Lyrics move with their tracks, and the covers and other files of a folder move with it
+// when all of its tracks move to the same folder. The moves are applied once confirmed:
+// if any of them fails, the files moved before it are moved back. In dry-run mode nothing is moved.
+// The migrated folder is locked like the output path of a download, so no run writes to it meanwhile.
+func (s *ServiceImpl) MigrateLibrary(ctx context.Context, req *MigrateLibraryRequest) (*MigrateReport, error) {
+	if _, err := os.Stat(req.Dir); err == nil && !s.cfg.NoLock && !s.cfg.DryRun {
+		lock, lockErr := acquireRunLock(req.Dir)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+
+		defer lock.release() //nolint:errcheck // A lock file left behind is taken over by the next run.
+	}
+
+	scan, err := scanLibrary(ctx, req.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrateReport{
+		FilesFound: scan.filesFound,
+		Skipped:    append(scan.skipped, scan.failed...),
+	}
+
+	metadata, err := s.fetchLibraryMetadata(ctx, scan.files)
+	if err != nil {
+		return nil, err
+	}
+
+	var trackMoves []*LibraryMove
+
+	for _, file := range scan.files {
+		item, reason := s.resolveLibraryTrack(file, metadata)
+		if item == nil {
+			report.Skipped = append(report.Skipped, &LibraryFileProblem{
+				Path:    file.path,
+				TrackID: file.trackID,
+				Reason:  reason,
+			})
+
+			continue
+		}
+
+		newPath := s.libraryTrackPath(ctx, req.Dir, item)
+		if filepath.Clean(newPath) == filepath.Clean(file.path) {
+			report.FilesInPlace++
+
+			continue
+		}
+
+		trackMoves = append(trackMoves, &LibraryMove{From: file.path, To: newPath})
+	}
+
+	trackMoves, report.Conflicts = dropConflictingMoves(trackMoves)
+	report.Moves = s.addCompanionMoves(trackMoves, scan)
+
+	sortLibraryFileProblems(report.Skipped)
+	sortLibraryFileProblems(report.Conflicts)
+	slices.SortFunc(report.Moves, func(a, b *LibraryMove) int {
+		return strings.Compare(a.From, b.From)
+	})
+
+	if len(report.Moves) == 0 {
+		return report, nil
+	}
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would move %d file(s)", len(report.Moves))
+
+		return report, nil
+	}
+
+	if req.Confirm != nil && !req.Confirm(report.Moves) {
+		return report, nil
+	}
+
+	if err = applyLibraryMoves(ctx, report.Moves); err != nil {
+		return report, err
+	}
+
+	report.IsApplied = true
+
+	removeEmptyFolders(ctx, req.Dir, report.Moves)
+
+	if err = rewriteHistoryPaths(ctx, s.cfg.HistoryPath, report.Moves); err != nil {
+		logger.Warnf(ctx, "Files were moved, but the download history was not updated: %v", err)
+	}
+
+	return report, nil
+}
+
+// libraryTrackPath returns where the file of the track is saved with the current templates.
+func (s *ServiceImpl) libraryTrackPath(ctx context.Context, dir string, item *libraryTrack) string {
+	var (
+		collection = item.collection
+		handler    folderHandler
+		title      string
+	)
+
+	if collection.category == DownloadCategoryPlaylist {
+		handler = s.playlistHandler
+		title = collection.title
+	} else {
+		handler = s.albumHandler
+		title = s.albumHandler.GetTitle(item.task.album)
+	}
+
+	folderName, _ := determineItemFolderAndFilename(
+		ctx,
+		s,
+		handler,
+		collection.category,
+		collection.tracksCount,
+		collection.trackIDs,
+		map[string]*zvuk.Track{strconv.FormatInt(item.task.track.ID, 10): item.task.track},
+		collection.tags,
+		title,
+	)
+
+	filename := s.templateManager.GetTrackFilename(
+		ctx,
+		collection.category == DownloadCategoryPlaylist,
+		item.trackTags,
+		collection.tracksCount,
+	)
+
+	filename = utils.SetFileExtension(utils.SanitizeFilename(filename), item.file.quality.Extension(), false)
+
+	return filepath.Join(dir, folderName, filename)
+}
+
+// dropConflictingMoves leaves out the moves whose targets are taken by files that stay in place,
+// or by other moves, and returns them as conflicts.
+func dropConflictingMoves(moves []*LibraryMove) ([]*LibraryMove, []*LibraryFileProblem) {
+	var conflicts []*LibraryFileProblem
+
+	// A dropped move keeps its file in place, which can take the target of another move, so this repeats.
+	for {
+		var (
+			sources = make(map[string]struct{}, len(moves))
+			targets = make(map[string]int, len(moves))
+		)
+
+		for _, move := range moves {
+			sources[filepath.Clean(move.From)] = struct{}{}
+			targets[filepath.Clean(move.To)]++
+		}
+
+		result := make([]*LibraryMove, 0, len(moves))
+
+		for _, move := range moves {
+			target := filepath.Clean(move.To)
+
+			// A target taken by a file moved away is free once the migration is applied,
+			// and a target differing only by letter case is the file itself on case-insensitive file systems.
+			_, isMovedAway := sources[target]
+			_, statErr := os.Lstat(target)
+
+			switch {
+			case targets[target] > 1:
+				conflicts = append(conflicts, &LibraryFileProblem{
+					Path:   move.From,
+					Reason: fmt.Sprintf("other files move to '%s' too", move.To),
+				})
+			case statErr == nil && !isMovedAway && !strings.EqualFold(target, filepath.Clean(move.From)):
+				conflicts = append(conflicts, &LibraryFileProblem{
+					Path:   move.From,
+					Reason: fmt.Sprintf("'%s' already exists", move.To),
+				})
+			default:
+				result = append(result, move)
+			}
+		}
+
+		if len(result) == len(moves) {
+			return result, conflicts
+		}
+
+		moves = result
+	}
+}
+
+// addCompanionMoves adds the moves of the lyrics of the moved tracks, and of the other files
+// of every folder whose tracks all move to the same folder, such as covers and descriptions.
+func (s *ServiceImpl) addCompanionMoves(trackMoves []*LibraryMove, scan *libraryScan) []*LibraryMove {
+	lyricsExtension := cmp.Or(s.cfg.LyricsExtension, config.DefaultLyricsExtension)
+
+	var (
+		result      = slices.Clone(trackMoves)
+		movedPaths  = make(map[string]struct{}, len(trackMoves))
+		targetPaths = make(map[string]struct{}, len(trackMoves))
+		// folderTargets maps a folder to the folders its tracks move to.
+		folderTargets = make(map[string]map[string]struct{})
+		// folderTracks counts the tracks of every folder, moved or not.
+		folderTracks = make(map[string]int)
+		// folderMoves counts the moved tracks of every folder.
+		folderMoves = make(map[string]int)
+	)
+
+	for _, move := range trackMoves {
+		movedPaths[filepath.Clean(move.From)] = struct{}{}
+		targetPaths[filepath.Clean(move.To)] = struct{}{}
+	}
+
+	addMove := func(from, to string) {
+		if _, ok := movedPaths[filepath.Clean(from)]; ok {
+			return
+		}
+
+		if _, ok := targetPaths[filepath.Clean(to)]; ok {
+			return
+		}
+
+		if _, err := os.Lstat(to); err == nil {
+			return
+		}
+
+		movedPaths[filepath.Clean(from)] = struct{}{}
+		targetPaths[filepath.Clean(to)] = struct{}{}
+		result = append(result, &LibraryMove{From: from, To: to})
+	}
+
+	for _, move := range trackMoves {
+		lyricsPath := strings.TrimSuffix(move.From, filepath.Ext(move.From)) + lyricsExtension
+		if _, err := os.Stat(lyricsPath); err == nil {
+			addMove(lyricsPath, strings.TrimSuffix(move.To, filepath.Ext(move.To))+lyricsExtension)
+		}
+
+		folder := filepath.Dir(move.From)
+		if folderTargets[folder] == nil {
+			folderTargets[folder] = make(map[string]struct{})
+		}
+
+		folderTargets[folder][filepath.Dir(move.To)] = struct{}{}
+		folderMoves[folder]++
+	}
+
+	for _, path := range scanPaths(scan) {
+		folderTracks[filepath.Dir(path)]++
+	}
+
+	for folder, targets := range folderTargets {
+		if len(targets) != 1 || folderMoves[folder] != folderTracks[folder] {
+			continue
+		}
+
+		var target string
+		for target = range targets {
+			break
+		}
+
+		entries, err := os.ReadDir(folder)
+		if err != nil || filepath.Clean(target) == filepath.Clean(folder) {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				addMove(filepath.Join(folder, entry.Name()), filepath.Join(target, entry.Name()))
+			}
+		}
+	}
+
+	return result
+}
+
+// scanPaths returns the paths of every audio file of the scan.
+func scanPaths(scan *libraryScan) []string {
+	result := make([]string, 0, scan.filesFound)
+
+	for _, file := range scan.files {
+		result = append(result, file.path)
+	}
+
+	for _, problems := range [][]*LibraryFileProblem{scan.skipped, scan.failed} {
+		for _, problem := range problems {
+			result = append(result, problem.Path)
+		}
+	}
+
+	return result
+}
+
+// applyLibraryMoves moves every file in two steps: first to a temporary name next to it, then to its target,
+// so files can take the places of each other. If a step fails, the steps done so far are undone.
+func applyLibraryMoves(ctx context.Context, moves []*LibraryMove) error {
+	var (
+		steps          []*migrationStep
+		createdFolders []string
+		tempPaths      = make([]string, len(moves))
+	)
+
+	rollback := func(cause error) error {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if err := os.Rename(steps[i].to, steps[i].from); err != nil {
+				logger.Errorf(ctx, "Failed to move '%s' back to '%s': %v", steps[i].to, steps[i].from, err)
+			}
+		}
+
+		for i := len(createdFolders) - 1; i >= 0; i-- {
+			_ = os.Remove(createdFolders[i])
+		}
+
+		return fmt.Errorf("%w: %w", ErrMigrationRolledBack, cause)
+	}
+
+	for i, move := range moves {
+		tempPaths[i] = move.From + migrateTempSuffix + "-" + strconv.Itoa(i)
+
+		if err := os.Rename(move.From, tempPaths[i]); err != nil {
+			return rollback(fmt.Errorf("failed to move '%s': %w", move.From, err))
+		}
+
+		steps = append(steps, &migrationStep{from: move.From, to: tempPaths[i]})
+	}
+
+	for i, move := range moves {
+		folders, err := createFolders(filepath.Dir(move.To))
+		createdFolders = append(createdFolders, folders...)
+
+		if err != nil {
+			return rollback(err)
+		}
+
+		if err = os.Rename(tempPaths[i], move.To); err != nil {
+			return rollback(fmt.Errorf("failed to move '%s' to '%s': %w", move.From, move.To, err))
+		}
+
+		steps = append(steps, &migrationStep{from: tempPaths[i], to: move.To})
+
+		logger.Debugf(ctx, "Moved '%s' to '%s'", move.From, move.To)
+	}
+
+	return nil
+}
+
+// createFolders creates the folder with its missing parents and returns the folders created, outermost first.
+func createFolders(folder string) ([]string, error) {
+	var missing []string
+
+	for current := filepath.Clean(folder); ; current = filepath.Dir(current) {
+		if _, err := os.Stat(current); err == nil {
+			break
+		}
+
+		missing = append(missing, current)
+
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	slices.Reverse(missing)
+
+	for i, path := range missing {
+		if err := os.Mkdir(path, constants.DefaultFolderPermissions); err != nil && !errors.Is(err, os.ErrExist) {
+			return missing[:i], fmt.Errorf("failed to create folder '%s': %w", path, err)
+		}
+	}
+
+	return missing, nil
+}
+
+// removeEmptyFolders removes the folders left empty by the moves, up to the library folder.
+func removeEmptyFolders(ctx context.Context, dir string, moves []*LibraryMove) {
+	root := filepath.Clean(dir)
+
+	for _, move := range moves {
+		for folder := filepath.Dir(move.From); isWithinFolder(folder, root) && folder != root; {
+			entries, err := os.ReadDir(folder)
+			if err != nil || len(entries) > 0 {
+				break
+			}
+
+			if err = os.Remove(folder); err != nil {
+				logger.Warnf(ctx, "Failed to remove empty folder '%s': %v", folder, err)
+
+				break
+			}
+
+			folder = filepath.Dir(folder)
+		}
+	}
+}
+
+// rewriteHistoryPaths points the records of the download history at the new paths of the moved files,
+// so the tracks are still recognized as saved.
+func rewriteHistoryPaths(ctx context.Context, historyPath string, moves []*LibraryMove) error {
+	if historyPath == "" {
+		return nil
+	}
+
+	entries, err := readHistoryEntries(ctx, historyPath)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	newPaths := make(map[string]string, len(moves))
+
+	for _, move := range moves {
+		from, absErr := filepath.Abs(move.From)
+		if absErr != nil {
+			continue
+		}
+
+		newPaths[from] = move.To
+	}
+
+	var (
+		content   []byte
+		isChanged bool
+	)
+
+	for _, entry := range entries {
+		if path, absErr := filepath.Abs(entry.Path); absErr == nil {
+			if newPath, ok := newPaths[path]; ok {
+				entry.Path = newPath
+				isChanged = true
+			}
+		}
+
+		line, marshalErr := json.Marshal(entry)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to encode history entry: %w", marshalErr)
+		}
+
+		content = append(append(content, line...), '\n')
+	}
+
+	if !isChanged {
+		return nil
+	}
+
+	if err = utils.WriteFileAtomically(historyPath, content); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
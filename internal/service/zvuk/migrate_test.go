@@ -0,0 +1,195 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// newTestMigrateConfig returns a configuration naming album folders after the album and tracks after the title.
+func newTestMigrateConfig(dir string) *config.Config {
+	return &config.Config{
+		OutputPath:            dir,
+		NoLock:                true,
+		AlbumFolderTemplate:   "{{.albumTitle}}",
+		TrackFilenameTemplate: "{{.trackTitle}}",
+		HistoryPath:           filepath.Join(dir, "history.jsonl"),
+	}
+}
+
+// expectMigrateMetadata sets up the metadata requests of the album tracks being migrated.
+func expectMigrateMetadata(mockClient *mock_zvuk_client.MockClient) {
+	mockClient.EXPECT().GetTracksMetadata(gomock.Any(), gomock.Any()).Return(map[string]*zvuk.Track{
+		"101": {ID: 101, ReleaseID: 10, Title: "Sonne", ArtistNames: []string{"Rammstein"}, Position: 4},
+		"102": {ID: 102, ReleaseID: 10, Title: "Mutter", ArtistNames: []string{"Rammstein"}, Position: 9},
+	}, nil)
+	mockClient.EXPECT().GetAlbumsMetadata(gomock.Any(), []string{"10"}, false).
+		Return(&zvuk.GetAlbumsMetadataResponse{Releases: map[string]*zvuk.Release{
+			"10": {ID: 10, Title: "Mutter", Date: 20010402, TrackIDs: []int64{1, 2, 3, 101, 5, 6, 7, 8, 102, 10, 11}},
+		}}, nil)
+}
+
+// writeTestMigrateLibrary writes an album folder with two tracks, the lyrics of one of them, and a cover.
+func writeTestMigrateLibrary(t *testing.T, dir string) string {
+	t.Helper()
+
+	oldFolder := filepath.Join(dir, "2001 - Rammstein - Mutter")
+	require.NoError(t, os.MkdirAll(oldFolder, constants.DefaultFolderPermissions))
+
+	writeTestFLAC(t, filepath.Join(oldFolder, "04 - Sonne.flac"), 10, 20000, map[string]string{"TRACK_ID": "101"})
+	writeTestFLAC(t, filepath.Join(oldFolder, "09 - Mutter.flac"), 10, 20000, map[string]string{"TRACK_ID": "102"})
+
+	for _, name := range []string{"04 - Sonne.lrc", "cover.jpg"} {
+		//nolint:gosec // It's a test file.
+		require.NoError(t, os.WriteFile(filepath.Join(oldFolder, name), []byte(name), constants.DefaultFilePermissions))
+	}
+
+	return oldFolder
+}
+
+// TestMigrateLibrary tests that the files move to the paths of the current templates with their companions.
+func TestMigrateLibrary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldFolder := writeTestMigrateLibrary(t, dir)
+	cfg := newTestMigrateConfig(dir)
+
+	history, err := json.Marshal(&HistoryEntry{TrackID: "101", Path: filepath.Join(oldFolder, "04 - Sonne.flac")})
+	require.NoError(t, err)
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(cfg.HistoryPath, append(history, '\n'), constants.DefaultFilePermissions))
+
+	service, mockClient := newTestRetagService(t, cfg)
+	expectMigrateMetadata(mockClient)
+
+	var confirmedMoves []*LibraryMove
+
+	report, err := service.MigrateLibrary(context.Background(), &MigrateLibraryRequest{
+		Dir: dir,
+		Confirm: func(moves []*LibraryMove) bool {
+			confirmedMoves = moves
+
+			return true
+		},
+	})
+	require.NoError(t, err)
+
+	newFolder := filepath.Join(dir, "Mutter")
+
+	assert.True(t, report.IsApplied)
+	assert.Equal(t, 2, report.FilesFound)
+	assert.Equal(t, []*LibraryMove{
+		{From: filepath.Join(oldFolder, "04 - Sonne.flac"), To: filepath.Join(newFolder, "Sonne.flac")},
+		{From: filepath.Join(oldFolder, "04 - Sonne.lrc"), To: filepath.Join(newFolder, "Sonne.lrc")},
+		{From: filepath.Join(oldFolder, "09 - Mutter.flac"), To: filepath.Join(newFolder, "Mutter.flac")},
+		{From: filepath.Join(oldFolder, "cover.jpg"), To: filepath.Join(newFolder, "cover.jpg")},
+	}, report.Moves)
+	assert.Equal(t, report.Moves, confirmedMoves)
+
+	for _, move := range report.Moves {
+		assert.FileExists(t, move.To)
+	}
+
+	assert.NoDirExists(t, oldFolder, "the folder left empty should be removed")
+
+	entries, err := readHistoryEntries(t.Context(), cfg.HistoryPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Join(newFolder, "Sonne.flac"), entries[0].Path)
+}
+
+// TestMigrateLibrary_RollBack tests that the files moved before a failed move are moved back.
+func TestMigrateLibrary_RollBack(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldFolder := writeTestMigrateLibrary(t, dir)
+
+	// A file named like the new folder makes the moves into it fail.
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Mutter"), []byte("file"), constants.DefaultFilePermissions))
+
+	service, mockClient := newTestRetagService(t, newTestMigrateConfig(dir))
+	expectMigrateMetadata(mockClient)
+
+	report, err := service.MigrateLibrary(context.Background(), &MigrateLibraryRequest{Dir: dir})
+	require.ErrorIs(t, err, ErrMigrationRolledBack)
+	assert.False(t, report.IsApplied)
+
+	entries, err := os.ReadDir(oldFolder)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	assert.Equal(t, []string{"04 - Sonne.flac", "04 - Sonne.lrc", "09 - Mutter.flac", "cover.jpg"}, names)
+}
+
+// TestMigrateLibrary_LockedDir tests that the migrated folder is locked rather than the output path.
+func TestMigrateLibrary_LockedDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestMigrateLibrary(t, dir)
+	writeTestRunLock(t, dir, fmt.Sprintf("%d\nanother-host\n", os.Getpid()))
+
+	cfg := newTestMigrateConfig(dir)
+	cfg.OutputPath = t.TempDir()
+	cfg.NoLock = false
+
+	service, _ := newTestRetagService(t, cfg)
+
+	_, err := service.MigrateLibrary(context.Background(), &MigrateLibraryRequest{Dir: dir})
+	require.ErrorIs(t, err, ErrOutputPathLocked)
+	assert.NoFileExists(t, filepath.Join(cfg.OutputPath, runLockFilename))
+}
+
+// TestDropConflictingMoves tests that moves to taken paths are left out, including the ones taken by them.
+func TestDropConflictingMoves(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var (
+		a     = filepath.Join(dir, "a.flac")
+		b     = filepath.Join(dir, "b.flac")
+		c     = filepath.Join(dir, "c.flac")
+		d     = filepath.Join(dir, "d.flac")
+		taken = filepath.Join(dir, "taken.flac")
+	)
+
+	for _, path := range []string{a, b, c, d, taken} {
+		//nolint:gosec // It's a test file.
+		require.NoError(t, os.WriteFile(path, nil, constants.DefaultFilePermissions))
+	}
+
+	moves, conflicts := dropConflictingMoves([]*LibraryMove{
+		// The target of a is the file of b, which cannot move, so a cannot move either.
+		{From: a, To: b},
+		{From: b, To: taken},
+		// c and d swap their places.
+		{From: c, To: d},
+		{From: d, To: c},
+	})
+
+	assert.Equal(t, []*LibraryMove{{From: c, To: d}, {From: d, To: c}}, moves)
+
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, b, conflicts[0].Path)
+	assert.Equal(t, a, conflicts[1].Path)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	zvuk "github.com/oshokin/zvuk-grabber/internal/service/zvuk"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,20 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AbortReason mocks base method.
+func (m *MockService) AbortReason() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortReason")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortReason indicates an expected call of AbortReason.
+func (mr *MockServiceMockRecorder) AbortReason() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortReason", reflect.TypeOf((*MockService)(nil).AbortReason))
+}
+
 // DownloadURLs mocks base method.
 func (m *MockService) DownloadURLs(ctx context.Context, urls []string) {
 	m.ctrl.T.Helper()
@@ -52,6 +67,21 @@ func (mr *MockServiceMockRecorder) DownloadURLs(ctx, urls any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadURLs", reflect.TypeOf((*MockService)(nil).DownloadURLs), ctx, urls)
 }
 
+// MigrateLibrary mocks base method.
+func (m *MockService) MigrateLibrary(ctx context.Context, req *zvuk.MigrateLibraryRequest) (*zvuk.MigrateReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateLibrary", ctx, req)
+	ret0, _ := ret[0].(*zvuk.MigrateReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrateLibrary indicates an expected call of MigrateLibrary.
+func (mr *MockServiceMockRecorder) MigrateLibrary(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateLibrary", reflect.TypeOf((*MockService)(nil).MigrateLibrary), ctx, req)
+}
+
 // PrintDownloadSummary mocks base method.
 func (m *MockService) PrintDownloadSummary(ctx context.Context) {
 	m.ctrl.T.Helper()
@@ -63,3 +93,144 @@ func (mr *MockServiceMockRecorder) PrintDownloadSummary(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintDownloadSummary", reflect.TypeOf((*MockService)(nil).PrintDownloadSummary), ctx)
 }
+
+// PrintStatus mocks base method.
+func (m *MockService) PrintStatus(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PrintStatus", ctx)
+}
+
+// PrintStatus indicates an expected call of PrintStatus.
+func (mr *MockServiceMockRecorder) PrintStatus(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintStatus", reflect.TypeOf((*MockService)(nil).PrintStatus), ctx)
+}
+
+// ResumeFailedItems mocks base method.
+func (m *MockService) ResumeFailedItems(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResumeFailedItems", ctx)
+}
+
+// ResumeFailedItems indicates an expected call of ResumeFailedItems.
+func (mr *MockServiceMockRecorder) ResumeFailedItems(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeFailedItems", reflect.TypeOf((*MockService)(nil).ResumeFailedItems), ctx)
+}
+
+// RetagLibrary mocks base method.
+func (m *MockService) RetagLibrary(ctx context.Context, dir string) (*zvuk.RetagReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetagLibrary", ctx, dir)
+	ret0, _ := ret[0].(*zvuk.RetagReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetagLibrary indicates an expected call of RetagLibrary.
+func (mr *MockServiceMockRecorder) RetagLibrary(ctx, dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetagLibrary", reflect.TypeOf((*MockService)(nil).RetagLibrary), ctx, dir)
+}
+
+// RunError mocks base method.
+func (m *MockService) RunError() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunError")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunError indicates an expected call of RunError.
+func (mr *MockServiceMockRecorder) RunError() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunError", reflect.TypeOf((*MockService)(nil).RunError))
+}
+
+// SetDownloadSpeedLimit mocks base method.
+func (m *MockService) SetDownloadSpeedLimit(limit int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDownloadSpeedLimit", limit)
+}
+
+// SetDownloadSpeedLimit indicates an expected call of SetDownloadSpeedLimit.
+func (mr *MockServiceMockRecorder) SetDownloadSpeedLimit(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownloadSpeedLimit", reflect.TypeOf((*MockService)(nil).SetDownloadSpeedLimit), limit)
+}
+
+// SetMaxConcurrentDownloads mocks base method.
+func (m *MockService) SetMaxConcurrentDownloads(limit int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxConcurrentDownloads", limit)
+}
+
+// SetMaxConcurrentDownloads indicates an expected call of SetMaxConcurrentDownloads.
+func (mr *MockServiceMockRecorder) SetMaxConcurrentDownloads(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxConcurrentDownloads", reflect.TypeOf((*MockService)(nil).SetMaxConcurrentDownloads), limit)
+}
+
+// Statistics mocks base method.
+func (m *MockService) Statistics() *zvuk.DownloadStatistics {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statistics")
+	ret0, _ := ret[0].(*zvuk.DownloadStatistics)
+	return ret0
+}
+
+// Statistics indicates an expected call of Statistics.
+func (mr *MockServiceMockRecorder) Statistics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statistics", reflect.TypeOf((*MockService)(nil).Statistics))
+}
+
+// Summary mocks base method.
+func (m *MockService) Summary(ctx context.Context) *zvuk.JSONSummary {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", ctx)
+	ret0, _ := ret[0].(*zvuk.JSONSummary)
+	return ret0
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockServiceMockRecorder) Summary(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockService)(nil).Summary), ctx)
+}
+
+// SyncPlaylists mocks base method.
+func (m *MockService) SyncPlaylists(ctx context.Context, urls []string, deleteRemoved bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SyncPlaylists", ctx, urls, deleteRemoved)
+}
+
+// SyncPlaylists indicates an expected call of SyncPlaylists.
+func (mr *MockServiceMockRecorder) SyncPlaylists(ctx, urls, deleteRemoved any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPlaylists", reflect.TypeOf((*MockService)(nil).SyncPlaylists), ctx, urls, deleteRemoved)
+}
+
+// UpgradeWatchedTracks mocks base method.
+func (m *MockService) UpgradeWatchedTracks(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpgradeWatchedTracks", ctx)
+}
+
+// UpgradeWatchedTracks indicates an expected call of UpgradeWatchedTracks.
+func (mr *MockServiceMockRecorder) UpgradeWatchedTracks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeWatchedTracks", reflect.TypeOf((*MockService)(nil).UpgradeWatchedTracks), ctx)
+}
+
+// WatchItems mocks base method.
+func (m *MockService) WatchItems(ctx context.Context, urls []string, deleteRemoved bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WatchItems", ctx, urls, deleteRemoved)
+}
+
+// WatchItems indicates an expected call of WatchItems.
+func (mr *MockServiceMockRecorder) WatchItems(ctx, urls, deleteRemoved any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchItems", reflect.TypeOf((*MockService)(nil).WatchItems), ctx, urls, deleteRemoved)
+}
@@ -82,6 +82,34 @@ func (mr *MockTemplateManagerMockRecorder) GetAudiobookFolderName(ctx, tags any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudiobookFolderName", reflect.TypeOf((*MockTemplateManager)(nil).GetAudiobookFolderName), ctx, tags)
 }
 
+// GetDescriptionFilename mocks base method.
+func (m *MockTemplateManager) GetDescriptionFilename(ctx context.Context, tags map[string]string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDescriptionFilename", ctx, tags)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetDescriptionFilename indicates an expected call of GetDescriptionFilename.
+func (mr *MockTemplateManagerMockRecorder) GetDescriptionFilename(ctx, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDescriptionFilename", reflect.TypeOf((*MockTemplateManager)(nil).GetDescriptionFilename), ctx, tags)
+}
+
+// GetLyricsFilename mocks base method.
+func (m *MockTemplateManager) GetLyricsFilename(ctx context.Context, trackTags map[string]string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLyricsFilename", ctx, trackTags)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetLyricsFilename indicates an expected call of GetLyricsFilename.
+func (mr *MockTemplateManagerMockRecorder) GetLyricsFilename(ctx, trackTags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLyricsFilename", reflect.TypeOf((*MockTemplateManager)(nil).GetLyricsFilename), ctx, trackTags)
+}
+
 // GetPodcastEpisodeFilename mocks base method.
 func (m *MockTemplateManager) GetPodcastEpisodeFilename(ctx context.Context, episodeTags map[string]string, totalEpisodes int64) string {
 	m.ctrl.T.Helper()
@@ -126,6 +126,24 @@ func (dc DownloadCategory) ToSubcategory() string {
 	}
 }
 
+// MarshalText implements encoding.TextMarshaler so categories are reported as readable strings.
+func (dc DownloadCategory) MarshalText() ([]byte, error) {
+	return []byte(dc.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the strings written by MarshalText.
+func (dc *DownloadCategory) UnmarshalText(text []byte) error {
+	for category := DownloadCategoryUnknown; category <= DownloadCategoryPodcast; category++ {
+		if category.String() == string(text) {
+			*dc = category
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrUnknownDownloadCategory, text)
+}
+
 // IsSupported returns true if the category is supported for downloading.
 func (dc DownloadCategory) IsSupported() bool {
 	switch dc {
@@ -146,6 +164,18 @@ const (
 	SkipReasonQuality
 	// SkipReasonDuration - track duration outside acceptable range.
 	SkipReasonDuration
+	// SkipReasonDuplicate - track already saved earlier in the same playlist.
+	SkipReasonDuplicate
+	// SkipReasonSynced - track already fetched by an earlier sync of the playlist.
+	SkipReasonSynced
+	// SkipReasonDownloaded - track already saved by an earlier run, according to the download history.
+	SkipReasonDownloaded
+	// SkipReasonInLibrary - track already present in one of the existing library roots.
+	SkipReasonInLibrary
+	// SkipReasonExcluded - track title or artist matches one of the exclude patterns.
+	SkipReasonExcluded
+	// SkipReasonBlocked - track, its release, or one of its artists is on the blocklist.
+	SkipReasonBlocked
 )
 
 // String returns a human-readable representation of the SkipReason.
@@ -157,11 +187,137 @@ func (sr SkipReason) String() string {
 		return "quality filter"
 	case SkipReasonDuration:
 		return "duration filter"
+	case SkipReasonDuplicate:
+		return "playlist duplicate"
+	case SkipReasonSynced:
+		return "already synced"
+	case SkipReasonDownloaded:
+		return "downloaded before"
+	case SkipReasonInLibrary:
+		return "in existing library"
+	case SkipReasonExcluded:
+		return "excluded by pattern"
+	case SkipReasonBlocked:
+		return "blocklisted"
 	default:
 		return fmt.Sprintf("unknown reason: %d", sr)
 	}
 }
 
+// Code returns the single-word name of the SkipReason printed by the plain progress output.
+func (sr SkipReason) Code() string {
+	switch sr {
+	case SkipReasonExists:
+		return "exists"
+	case SkipReasonQuality:
+		return "quality"
+	case SkipReasonDuration:
+		return "duration"
+	case SkipReasonDuplicate:
+		return "duplicate"
+	case SkipReasonSynced:
+		return "synced"
+	case SkipReasonDownloaded:
+		return "downloaded"
+	case SkipReasonInLibrary:
+		return "in-library"
+	case SkipReasonExcluded:
+		return "excluded"
+	case SkipReasonBlocked:
+		return "blocked"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler so skip reasons are reported as readable strings.
+func (sr SkipReason) MarshalText() ([]byte, error) {
+	return []byte(sr.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the strings written by MarshalText.
+func (sr *SkipReason) UnmarshalText(text []byte) error {
+	for reason := SkipReasonExists; reason <= SkipReasonExcluded; reason++ {
+		if reason.String() == string(text) {
+			*sr = reason
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrUnknownSkipReason, text)
+}
+
+// DownloadPhase is a step of a download whose cumulative time is reported in the summary.
+type DownloadPhase uint8
+
+const (
+	// DownloadPhaseMetadata - fetching the metadata of collections and tracks.
+	DownloadPhaseMetadata DownloadPhase = iota
+	// DownloadPhaseStream - resolving the quality and the stream URL of a track.
+	DownloadPhaseStream
+	// DownloadPhaseDownload - transferring the track audio data.
+	DownloadPhaseDownload
+	// DownloadPhaseTagging - writing the tags of a track.
+	DownloadPhaseTagging
+	// DownloadPhaseFinalize - moving a track into place, making its copies, and finishing the collection files.
+	DownloadPhaseFinalize
+	// DownloadPhasePause - the random pauses between tracks.
+	DownloadPhasePause
+)
+
+// downloadPhases lists the phases in the order of a download.
+//
+//nolint:gochecknoglobals // Read-only list of the phases.
+var downloadPhases = []DownloadPhase{
+	DownloadPhaseMetadata,
+	DownloadPhaseStream,
+	DownloadPhaseDownload,
+	DownloadPhaseTagging,
+	DownloadPhaseFinalize,
+	DownloadPhasePause,
+}
+
+// String returns a human-readable representation of the DownloadPhase.
+func (dp DownloadPhase) String() string {
+	switch dp {
+	case DownloadPhaseMetadata:
+		return "Metadata"
+	case DownloadPhaseStream:
+		return "Stream URLs"
+	case DownloadPhaseDownload:
+		return "Download"
+	case DownloadPhaseTagging:
+		return "Tagging"
+	case DownloadPhaseFinalize:
+		return "Finalizing"
+	case DownloadPhasePause:
+		return "Pauses"
+	default:
+		return fmt.Sprintf("unknown phase: %d", dp)
+	}
+}
+
+// Code returns the single-word name of the DownloadPhase used as a key in the JSON summary.
+func (dp DownloadPhase) Code() string {
+	switch dp {
+	case DownloadPhaseMetadata:
+		return "metadata"
+	case DownloadPhaseStream:
+		return "stream"
+	case DownloadPhaseDownload:
+		return "download"
+	case DownloadPhaseTagging:
+		return "tagging"
+	case DownloadPhaseFinalize:
+		return "finalize"
+	case DownloadPhasePause:
+		return "pause"
+	default:
+		return "unknown"
+	}
+}
+
 // DownloadItem represents a full downloadable item, including its category, URL, and unique identifier.
 type DownloadItem struct {
 	// Category is the type of content. (track, album, playlist, etc.).
@@ -201,6 +357,26 @@ type DownloadStatistics struct {
 	TracksSkippedQuality int64
 	// TracksSkippedDuration is the number of tracks skipped due to duration threshold.
 	TracksSkippedDuration int64
+	// TracksSkippedExcluded is the number of tracks skipped because their title or artist matches an exclude pattern.
+	TracksSkippedExcluded int64
+	// TracksSkippedBlocked is the number of tracks skipped because the blocklist has them, their release, or an artist.
+	TracksSkippedBlocked int64
+	// TracksSkippedByRule is the number of tracks skipped by every rule of the track validator, by rule name.
+	TracksSkippedByRule map[string]int64
+	// TracksSkippedDuplicate is the number of repeated playlist tracks that were not saved again.
+	TracksSkippedDuplicate int64
+	// TracksSkippedSynced is the number of tracks skipped because an earlier sync of the playlist fetched them.
+	TracksSkippedSynced int64
+	// TracksSkippedDownloaded is the number of tracks skipped because the download history shows them saved.
+	TracksSkippedDownloaded int64
+	// TracksSkippedInLibrary is the number of tracks skipped because an existing library root has them.
+	TracksSkippedInLibrary int64
+	// TracksLinked is the number of repeated playlist tracks saved as hard links.
+	TracksLinked int64
+	// TracksUpgraded is the number of watched tracks saved again in FLAC.
+	TracksUpgraded int64
+	// PlaylistDuplicates is the number of repeated track occurrences found in playlists.
+	PlaylistDuplicates int64
 	// TracksFailed is the number of tracks that failed to download.
 	TracksFailed int64
 	// TotalBytesDownloaded is the total size of downloaded content in bytes.
@@ -213,14 +389,68 @@ type DownloadStatistics struct {
 	CoversDownloaded int64
 	// CoversSkipped is the number of cover art files skipped (already exist).
 	CoversSkipped int64
+	// CoversReused is the number of cover art files copied from an image downloaded earlier in the run.
+	CoversReused int64
 	// DescriptionsSaved is the number of description files downloaded.
 	DescriptionsSaved int64
 	// DescriptionsSkipped is the number of description files skipped (already exist).
 	DescriptionsSkipped int64
+	// TracksNormalized is the number of loudness-normalized copies created.
+	TracksNormalized int64
+	// TracksTranscoded is the number of portable copies created.
+	TracksTranscoded int64
+	// TotalDownloadDuration is the cumulative time spent transferring track audio data.
+	TotalDownloadDuration time.Duration
+	// PhaseDurations is the cumulative time spent in every phase of the downloads, summed over the workers.
+	PhaseDurations map[DownloadPhase]time.Duration
+	// QualitySizeEstimates holds dry-run size totals grouped by the resolved track quality.
+	QualitySizeEstimates map[TrackQuality]*QualitySizeEstimate
+	// ProjectedMP3Bytes holds dry-run size projections as if every track were downloaded
+	// in the given constant-bitrate MP3 quality.
+	ProjectedMP3Bytes map[TrackQuality]int64
+	// CollectionUsage holds the size of the audio downloaded for every collection, keyed by category and ID.
+	CollectionUsage map[string]*CollectionUsage
+	// DownloadedTracks is a list of all tracks downloaded during the run.
+	DownloadedTracks []*DownloadedTrack
+	// SkippedItems is a list of all tracks skipped during the download process.
+	SkippedItems []*SkippedItem
+	// UntaggedTracks is a list of downloaded tracks kept without tags because tagging failed.
+	UntaggedTracks []*UntaggedTrack
+	// QualityDowngrades is a list of tracks delivered below the requested quality or skipped by min_quality.
+	QualityDowngrades []*QualityDowngrade
+	// RelinkedReleases is a list of albums whose folder was moved to their re-issue under a new release ID.
+	RelinkedReleases []*RelinkedRelease
+	// RclonePushed is the number of collections and tracks pushed to rclone_remote after the run.
+	RclonePushed int64
+	// RcloneFailures is a list of collections and tracks that rclone failed to push.
+	RcloneFailures []*RcloneFailure
 	// Errors is a list of all errors encountered during the download process.
 	Errors []*DownloadError
 }
 
+// QualitySizeEstimate holds dry-run size totals for a single audio quality.
+type QualitySizeEstimate struct {
+	// Tracks is the number of tracks that would be downloaded in this quality.
+	Tracks int64
+	// Bytes is the total size of these tracks as reported by the server.
+	Bytes int64
+}
+
+// CollectionUsage is the size of the audio downloaded for an album, playlist, audiobook, or podcast during the run.
+// Standalone tracks are counted for their albums.
+type CollectionUsage struct {
+	// Category is the type of the collection.
+	Category DownloadCategory `json:"category"`
+	// ItemID is the ID of the collection.
+	ItemID string `json:"item_id"`
+	// Title is the title of the collection.
+	Title string `json:"title"`
+	// Tracks is the number of tracks downloaded for the collection.
+	Tracks int64 `json:"tracks"`
+	// Bytes is the size of the audio downloaded for the collection.
+	Bytes int64 `json:"bytes"`
+}
+
 // DownloadError represents a single error that occurred during download.
 type DownloadError struct {
 	// Category is the type of item that failed (track, album, playlist, etc.).
@@ -243,6 +473,106 @@ type DownloadError struct {
 	Error error
 }
 
+// SkippedItem represents a single track that was skipped during download.
+type SkippedItem struct {
+	// TrackID is the unique identifier of the skipped track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track.
+	Title string `json:"title"`
+	// ParentCategory is the type of parent collection (album/playlist) for the track.
+	ParentCategory DownloadCategory `json:"parent_category"`
+	// ParentID is the ID of the parent collection.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the parent collection.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// Reason is why the track was skipped.
+	Reason SkipReason `json:"reason"`
+	// Threshold is the configured limit the track failed to meet (empty for existing files).
+	Threshold string `json:"threshold,omitempty"`
+}
+
+// DownloadedTrack represents a track saved during the run (or that would be saved, in dry-run mode).
+type DownloadedTrack struct {
+	// TrackID is the unique identifier of the track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track.
+	Title string `json:"title"`
+	// Artists are the names of the track artists.
+	Artists []string `json:"artists,omitempty"`
+	// ParentCategory is the type of parent collection (album/playlist) for the track.
+	ParentCategory DownloadCategory `json:"parent_category"`
+	// ParentID is the ID of the parent collection.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the parent collection.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// Quality is the quality the track was downloaded in.
+	Quality TrackQuality `json:"quality"`
+	// Path is where the track was saved.
+	Path string `json:"path"`
+	// Bytes is the number of bytes downloaded.
+	Bytes int64 `json:"bytes"`
+}
+
+// UntaggedTrack represents a downloaded track whose audio was kept although writing its tags failed.
+type UntaggedTrack struct {
+	// TrackID is the unique identifier of the track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track.
+	Title string `json:"title"`
+	// Path is where the untagged audio was saved.
+	Path string `json:"path"`
+}
+
+// RcloneFailure represents a collection folder or a track that rclone failed to push.
+type RcloneFailure struct {
+	// Title is the human-readable title of the collection or track.
+	Title string `json:"title"`
+	// Path is the local folder or file that was pushed.
+	Path string `json:"path"`
+	// Error is the rclone error message.
+	Error string `json:"error"`
+}
+
+// QualityDowngrade represents a track that is not available in the requested quality.
+type QualityDowngrade struct {
+	// TrackID is the unique identifier of the track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track, prefixed with its artists.
+	Title string `json:"title"`
+	// ParentCategory is the type of parent collection (album/playlist) for the track.
+	ParentCategory DownloadCategory `json:"parent_category"`
+	// ParentID is the ID of the parent collection.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the parent collection.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// RequestedQuality is the configured quality.
+	RequestedQuality TrackQuality `json:"requested_quality"`
+	// AvailableQuality is the quality that was delivered, or the best one offered if the track was skipped.
+	AvailableQuality TrackQuality `json:"available_quality"`
+	// IsSkipped indicates that the track was not downloaded because of min_quality.
+	IsSkipped bool `json:"is_skipped"`
+	// IsStreamFallback indicates that the requested quality was offered but its stream failed,
+	// so the track fell back to a lower quality (quality_fallback).
+	IsStreamFallback bool `json:"is_stream_fallback,omitempty"`
+}
+
+// RelinkedRelease is an album saved by an earlier run whose folder was moved to its re-issue
+// under a new release ID (relink_reissues).
+type RelinkedRelease struct {
+	// ReleaseID is the ID of the re-issue.
+	ReleaseID string `json:"release_id"`
+	// Title is the title of the re-issue.
+	Title string `json:"title"`
+	// PreviousReleaseID is the ID the album was saved with.
+	PreviousReleaseID string `json:"previous_release_id"`
+	// PreviousPath is the folder the album was saved to.
+	PreviousPath string `json:"previous_path"`
+	// Path is the folder of the re-issue the album was moved to.
+	Path string `json:"path"`
+	// Tracks is the number of saved tracks moved with the folder.
+	Tracks int `json:"tracks"`
+}
+
 // DownloadTrackResult contains the result of downloadAndSaveTrack operation.
 type DownloadTrackResult struct {
 	// IsExist indicates whether the track file already existed (download was skipped).
@@ -372,4 +702,8 @@ type audioCollection struct {
 	trackIDs []int64
 	// tracksCount is the total number of tracks in the collection.
 	tracksCount int64
+	// playlistPositions maps the track IDs of a playlist to their 1-based positions in it, as returned by the API.
+	playlistPositions map[int64]int64
+	// hasOwnFolder indicates whether the tracks are saved into a folder of their own, not next to other items.
+	hasOwnFolder bool
 }
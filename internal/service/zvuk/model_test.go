@@ -1,9 +1,11 @@
 package zvuk
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestDownloadCategory tests the DownloadCategory enum and String method.
@@ -206,3 +208,31 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, DownloadCategoryPlaylist, DownloadCategory(3))
 	assert.Equal(t, DownloadCategoryArtist, DownloadCategory(4))
 }
+
+// TestSkippedItem_MarshalJSON tests that skipped items serialize with readable reason and category.
+func TestSkippedItem_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	item := &SkippedItem{
+		TrackID:        "1000",
+		Title:          "Test Track",
+		ParentCategory: DownloadCategoryAlbum,
+		ParentID:       "100",
+		ParentTitle:    "Test Album",
+		Reason:         SkipReasonQuality,
+		Threshold:      "MP3, 320 Kbps",
+	}
+
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"track_id": "1000",
+		"title": "Test Track",
+		"parent_category": "album",
+		"parent_id": "100",
+		"parent_title": "Test Album",
+		"reason": "quality filter",
+		"threshold": "MP3, 320 Kbps"
+	}`, string(data))
+}
@@ -0,0 +1,107 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// writeCheckFilePattern is the name pattern of the probe file used to verify output path writability.
+const writeCheckFilePattern = ".zvuk-grabber-write-check-*"
+
+// prepareOutputPath verifies that the output path is a writable directory, creating it when allowed.
+// In dry-run mode nothing is created, but an existing path is still validated.
+func (s *ServiceImpl) prepareOutputPath(ctx context.Context) error {
+	outputPath := s.cfg.OutputPath
+
+	info, err := os.Stat(outputPath)
+
+	switch {
+	case err == nil:
+		if !info.IsDir() {
+			return ErrOutputPathNotDirectory
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to check output path: %w", err)
+	case s.cfg.RequireExistingOutputPath:
+		return ErrOutputPathMissing
+	case s.cfg.DryRun:
+		logger.Infof(ctx, "[DRY-RUN] Would create output directory: %s", outputPath)
+
+		return nil
+	default:
+		if err = os.MkdirAll(outputPath, defaultFolderPermissions); err != nil {
+			return fmt.Errorf("failed to create output path: %w", err)
+		}
+	}
+
+	if s.cfg.DryRun {
+		return nil
+	}
+
+	return checkDirectoryWritable(outputPath)
+}
+
+// CheckOutputPath verifies that files can be saved to the output path without creating anything:
+// an existing path must be a writable directory, and a missing one must be creatable
+// (its nearest existing parent must be a writable directory) unless require_existing_output_path is set.
+func CheckOutputPath(cfg *config.Config) error {
+	path := cfg.OutputPath
+
+	for {
+		info, err := os.Stat(path)
+
+		switch {
+		case err == nil:
+			if !info.IsDir() {
+				if path == cfg.OutputPath {
+					return ErrOutputPathNotDirectory
+				}
+
+				return fmt.Errorf("%w: parent '%s' is not a directory", ErrOutputPathNotWritable, path)
+			}
+
+			return checkDirectoryWritable(path)
+		// A file in place of a parent folder is found while walking up.
+		case !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR):
+			return fmt.Errorf("failed to check output path: %w", err)
+		case cfg.RequireExistingOutputPath:
+			return ErrOutputPathMissing
+		}
+
+		parentPath := filepath.Dir(path)
+		if parentPath == path {
+			return fmt.Errorf("failed to check output path: %w", err)
+		}
+
+		path = parentPath
+	}
+}
+
+// checkDirectoryWritable creates and removes a probe file to verify that the directory is writable.
+func checkDirectoryWritable(path string) error {
+	probeFile, err := os.CreateTemp(path, writeCheckFilePattern)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrOutputPathNotWritable, err)
+	}
+
+	probePath := probeFile.Name()
+
+	if err = probeFile.Close(); err != nil {
+		_ = os.Remove(probePath)
+
+		return fmt.Errorf("%w: %w", ErrOutputPathNotWritable, err)
+	}
+
+	if err = os.Remove(probePath); err != nil {
+		return fmt.Errorf("failed to remove write check file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestPrepareOutputPath tests output path validation and creation at startup.
+func TestPrepareOutputPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setup         func(t *testing.T, cfg *config.Config)
+		expectedError error
+		expectCreated bool
+	}{
+		{
+			name: "missing path is created",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "new", "music")
+			},
+			expectCreated: true,
+		},
+		{
+			name: "missing path is rejected when it must exist",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "unmounted")
+				cfg.RequireExistingOutputPath = true
+			},
+			expectedError: ErrOutputPathMissing,
+		},
+		{
+			name: "missing path is not created in dry-run mode",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "preview")
+				cfg.DryRun = true
+			},
+			expectCreated: false,
+		},
+		{
+			name: "file instead of directory is rejected",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "file.txt")
+
+				//nolint:gosec // It's a test file.
+				err := os.WriteFile(cfg.OutputPath, []byte("not a directory"), constants.DefaultFilePermissions)
+				require.NoError(t, err)
+			},
+			expectedError: ErrOutputPathNotDirectory,
+		},
+		{
+			name: "existing writable directory is accepted",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = t.TempDir()
+			},
+			expectCreated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := new(config.Config)
+			tt.setup(t, cfg)
+
+			impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+			require.True(t, ok, "service must be of type *ServiceImpl")
+
+			err := impl.prepareOutputPath(context.Background())
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.expectCreated {
+				assert.DirExists(t, cfg.OutputPath)
+
+				// The write check must not leave any files behind.
+				entries, readErr := os.ReadDir(cfg.OutputPath)
+				require.NoError(t, readErr)
+				assert.Empty(t, entries)
+			} else {
+				assert.NoDirExists(t, cfg.OutputPath)
+			}
+		})
+	}
+}
+
+// TestCheckOutputPath tests that the output path is checked without creating anything.
+func TestCheckOutputPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setup         func(t *testing.T, cfg *config.Config)
+		expectedError error
+	}{
+		{
+			name: "missing path under a writable parent is accepted",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "new", "music")
+			},
+		},
+		{
+			name: "missing path is rejected when it must exist",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "unmounted")
+				cfg.RequireExistingOutputPath = true
+			},
+			expectedError: ErrOutputPathMissing,
+		},
+		{
+			name: "file instead of directory is rejected",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(t.TempDir(), "file.txt")
+
+				//nolint:gosec // It's a test file.
+				err := os.WriteFile(cfg.OutputPath, []byte("not a directory"), constants.DefaultFilePermissions)
+				require.NoError(t, err)
+			},
+			expectedError: ErrOutputPathNotDirectory,
+		},
+		{
+			name: "file instead of parent directory is rejected",
+			setup: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				parentPath := filepath.Join(t.TempDir(), "file.txt")
+				cfg.OutputPath = filepath.Join(parentPath, "music")
+
+				//nolint:gosec // It's a test file.
+				err := os.WriteFile(parentPath, []byte("not a directory"), constants.DefaultFilePermissions)
+				require.NoError(t, err)
+			},
+			expectedError: ErrOutputPathNotWritable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := new(config.Config)
+			tt.setup(t, cfg)
+
+			err := CheckOutputPath(cfg)
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoDirExists(t, cfg.OutputPath)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// claimedPath is a destination path used during the run.
+type claimedPath struct {
+	// path is the path as it is written to disk, without the extension of files.
+	path string
+	// owner identifies the item saved to the path (e.g., "album:123").
+	owner string
+}
+
+// claimPath registers the destination of an item and returns the path to save it to.
+// On case-insensitive file systems (macOS, Windows) paths differing only by letter case are the same file,
+// so a path that matches the path of another item, or an existing file or folder, except for case
+// gets a " (2)", " (3)", etc. suffix. Files are compared without their extensions, so a track keeps its name
+// whatever quality it is saved in, and the suffix goes before the extension; folder names are used as a whole.
+// Items claiming exactly the same path keep sharing it, and an item claiming a path again gets the same result.
+func (s *ServiceImpl) claimPath(ctx context.Context, path, owner string, isDir bool) string {
+	s.claimedPathsMutex.Lock()
+	defer s.claimedPathsMutex.Unlock()
+
+	if s.claimedPaths == nil {
+		s.claimedPaths = make(map[string]claimedPath)
+	}
+
+	var (
+		requested = filepath.Clean(path)
+		extension string
+	)
+
+	if !isDir {
+		extension = filepath.Ext(requested)
+		requested = strings.TrimSuffix(requested, extension)
+	}
+
+	candidate := requested
+
+	for number := 2; ; number++ {
+		key := strings.ToLower(candidate)
+
+		claimed, isClaimed := s.claimedPaths[key]
+		if !isClaimed && !hasCaseVariantOnDisk(candidate, isDir) {
+			s.claimedPaths[key] = claimedPath{path: candidate, owner: owner}
+
+			break
+		}
+
+		if isClaimed && (claimed.owner == owner || claimed.path == candidate) {
+			return claimed.path + extension
+		}
+
+		candidate = requested + fmt.Sprintf(" (%d)", number)
+	}
+
+	if candidate != requested {
+		logger.Warnf(ctx, "Path '%s' differs only by letter case from the path of another item, saving to '%s'",
+			path, candidate+extension)
+	}
+
+	return candidate + extension
+}
+
+// hasCaseVariantOnDisk reports whether the folder of the path holds an entry whose name differs from the path
+// only by letter case. Files are compared without their extensions, like in claimPath.
+func hasCaseVariantOnDisk(path string, isDir bool) bool {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+
+	name := filepath.Base(path)
+
+	for _, entry := range entries {
+		if entry.IsDir() != isDir {
+			continue
+		}
+
+		entryName := entry.Name()
+		if !isDir {
+			entryName = strings.TrimSuffix(entryName, filepath.Ext(entryName))
+		}
+
+		if entryName != name && strings.EqualFold(entryName, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// claimTrackPaths claims the paths of the tracks of a collection in track order before they are downloaded
+// concurrently, so the track getting the suffix of a path differing only by letter case does not depend
+// on which download gets to its path first. Standalone tracks are claimed as they are downloaded,
+// since their album folders are only known then.
+func (s *ServiceImpl) claimTrackPaths(ctx context.Context, metadata *downloadTracksMetadata) {
+	if metadata.audioCollection == nil {
+		return
+	}
+
+	for index := range metadata.trackIDs {
+		if metadata.isDuplicate(index) || metadata.isDeselected(index) {
+			continue
+		}
+
+		trackID := metadata.trackIDs[index]
+		trackIDString := strconv.FormatInt(trackID, 10)
+
+		track := metadata.tracksMetadata[trackIDString]
+		if track == nil {
+			continue
+		}
+
+		s.prepareTrackFiles(ctx, &downloadTrackTask{
+			trackIndex:      int64(index) + 1,
+			trackID:         trackID,
+			trackIDString:   trackIDString,
+			track:           track,
+			audioCollection: metadata.audioCollection,
+			metadata:        metadata,
+			duplicateNumber: metadata.duplicateNumbers[index],
+			albumTags:       metadata.albumsTags[strconv.FormatInt(track.ReleaseID, 10)],
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestClaimPath verifies that paths differing only by letter case are disambiguated.
+func TestClaimPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := new(ServiceImpl)
+
+	albumPath := filepath.Join("music", "Radio Ga Ga")
+	assert.Equal(t, albumPath, s.claimPath(ctx, albumPath, "album:1", true))
+
+	// The same item gets the same path again.
+	assert.Equal(t, albumPath, s.claimPath(ctx, albumPath, "album:1", true))
+
+	// Another item with a path differing only by case gets a suffix.
+	upperCasePath := filepath.Join("music", "Radio GA GA")
+	assert.Equal(t, upperCasePath+" (2)", s.claimPath(ctx, upperCasePath, "album:2", true))
+
+	lowerCasePath := filepath.Join("music", "radio ga ga")
+	assert.Equal(t, lowerCasePath+" (3)", s.claimPath(ctx, lowerCasePath, "album:3", true))
+	assert.Equal(t, upperCasePath+" (2)", s.claimPath(ctx, upperCasePath, "album:2", true))
+
+	// Items saved to exactly the same path keep sharing it.
+	assert.Equal(t, albumPath, s.claimPath(ctx, albumPath, "album:4", true))
+
+	// Files get the suffix before the extension.
+	trackPath := filepath.Join(albumPath, "01 - Intro.flac")
+	assert.Equal(t, trackPath, s.claimPath(ctx, trackPath, "track:1:0", false))
+	assert.Equal(t,
+		filepath.Join(albumPath, "01 - intro (2).flac"),
+		s.claimPath(ctx, filepath.Join(albumPath, "01 - intro.flac"), "track:2:0", false))
+}
+
+// TestClaimPath_CaseVariantOnDisk verifies that files and folders saved by earlier runs are taken into account.
+func TestClaimPath_CaseVariantOnDisk(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		s          = new(ServiceImpl)
+		outputPath = t.TempDir()
+		albumPath  = filepath.Join(outputPath, "Radio Ga Ga")
+	)
+
+	require.NoError(t, os.MkdirAll(albumPath, defaultFolderPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(albumPath, "01 - Intro.mp3"), nil, constants.DefaultFilePermissions))
+
+	// The folder and the file themselves are claimed as they are.
+	assert.Equal(t, albumPath, s.claimPath(ctx, albumPath, "album:1", true))
+	assert.Equal(t,
+		filepath.Join(albumPath, "01 - Intro.flac"),
+		s.claimPath(ctx, filepath.Join(albumPath, "01 - Intro.flac"), "track:1:0", false))
+
+	// Names differing only by case get a suffix.
+	assert.Equal(t,
+		filepath.Join(outputPath, "Radio GA GA (2)"),
+		s.claimPath(ctx, filepath.Join(outputPath, "Radio GA GA"), "album:2", true))
+	assert.Equal(t,
+		filepath.Join(albumPath, "01 - INTRO (2).flac"),
+		s.claimPath(ctx, filepath.Join(albumPath, "01 - INTRO.flac"), "track:2:0", false))
+}
+
+// titleTemplateManager names the track files after their titles.
+type titleTemplateManager struct {
+	mockTemplateManager
+}
+
+// GetTrackFilename returns the track title.
+func (m *titleTemplateManager) GetTrackFilename(_ context.Context, _ bool, tags map[string]string, _ int64) string {
+	return tags[TagTrackTitle]
+}
+
+// TestClaimTrackPaths verifies that the tracks of a collection differing only by letter case
+// get their suffixes in track order, before any of them is downloaded.
+func TestClaimTrackPaths(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		outputPath = t.TempDir()
+		collection = &audioCollection{
+			category:    DownloadCategoryAlbum,
+			title:       "Album",
+			tracksPath:  outputPath,
+			tracksCount: 3,
+		}
+		metadata = &downloadTracksMetadata{
+			audioCollection: collection,
+			category:        DownloadCategoryAlbum,
+			trackIDs:        []int64{1, 2, 3},
+			tracksMetadata: map[string]*zvuk.Track{
+				"1": {ID: 1, Title: "Intro"},
+				"2": {ID: 2, Title: "INTRO"},
+				"3": {ID: 3, Title: "intro"},
+			},
+		}
+	)
+
+	s, ok := NewService(&config.Config{OutputPath: outputPath}, nil, nil, new(titleTemplateManager), nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	s.claimTrackPaths(ctx, metadata)
+
+	// The downloads finishing in reverse order get the paths claimed in track order.
+	for _, tt := range []struct {
+		index    int
+		expected string
+	}{
+		{index: 2, expected: "intro (3).flac"},
+		{index: 1, expected: "INTRO (2).flac"},
+		{index: 0, expected: "Intro.flac"},
+	} {
+		task := &downloadTrackTask{
+			trackIndex:      int64(tt.index) + 1,
+			trackID:         metadata.trackIDs[tt.index],
+			trackIDString:   strconv.FormatInt(metadata.trackIDs[tt.index], 10),
+			track:           metadata.tracksMetadata[strconv.FormatInt(metadata.trackIDs[tt.index], 10)],
+			audioCollection: collection,
+			metadata:        metadata,
+			quality:         TrackQualityFLAC,
+		}
+
+		s.prepareTrackFiles(ctx, task)
+		assert.Equal(t, filepath.Join(outputPath, tt.expected), task.trackPath)
+	}
+}
@@ -0,0 +1,253 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	// Registers the PNG decoder for the album covers.
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+const (
+	// playlistMosaicCovers is the number of album covers in a generated playlist cover, laid out 2x2.
+	playlistMosaicCovers = 4
+	// playlistMosaicMaxTileSize is the largest side of a single album cover in a generated playlist cover.
+	playlistMosaicMaxTileSize = 600
+	// playlistMosaicJPEGQuality is the JPEG quality of a generated playlist cover.
+	playlistMosaicJPEGQuality = 90
+)
+
+// generatePlaylistCover saves a cover composed of the first four distinct album covers of the playlist
+// into the playlist folder when the playlist has no cover of its own (generate_playlist_covers).
+// A playlist with fewer distinct albums gets the cover of its first album.
+func (s *ServiceImpl) generatePlaylistCover(ctx context.Context, metadata *downloadTracksMetadata) {
+	collection := metadata.audioCollection
+	if !s.cfg.GeneratePlaylistCovers || s.cfg.DryRun || ctx.Err() != nil ||
+		collection == nil || collection.category != DownloadCategoryPlaylist || !collection.hasOwnFolder ||
+		collection.embeddableCoverPath != "" || collection.coverPath != "" {
+		return
+	}
+
+	coverPath := filepath.Join(collection.tracksPath, defaultCoverFilename+extensionJPG)
+	if _, err := os.Stat(coverPath); err == nil && !s.cfg.ReplaceCovers {
+		logger.Infof(ctx, "Playlist cover already exists, skipping generation")
+		s.incrementCoverSkipped()
+
+		return
+	}
+
+	albumCoverPaths := s.getPlaylistAlbumCoverPaths(ctx, metadata)
+	if len(albumCoverPaths) == 0 {
+		return
+	}
+
+	if err := writePlaylistCover(albumCoverPaths, coverPath); err != nil {
+		logger.Errorf(ctx, "Failed to generate playlist cover '%s': %v", coverPath, err)
+
+		return
+	}
+
+	collection.coverPath = coverPath
+
+	logger.Infof(ctx, "Playlist cover generated from %d album cover(s): %s", len(albumCoverPaths), coverPath)
+}
+
+// getPlaylistAlbumCoverPaths returns the cached covers of the first four distinct albums of the playlist tracks,
+// in the playlist order. Albums without a cover, and covers that cannot be downloaded, are left out.
+func (s *ServiceImpl) getPlaylistAlbumCoverPaths(ctx context.Context, metadata *downloadTracksMetadata) []string {
+	var (
+		result   = make([]string, 0, playlistMosaicCovers)
+		seenURLs = make(map[string]struct{}, playlistMosaicCovers)
+	)
+
+	for _, trackID := range metadata.audioCollection.trackIDs {
+		if len(result) == playlistMosaicCovers || ctx.Err() != nil {
+			break
+		}
+
+		track := metadata.tracksMetadata[strconv.FormatInt(trackID, 10)]
+		if track == nil {
+			continue
+		}
+
+		album := metadata.albumsMetadata[strconv.FormatInt(track.ReleaseID, 10)]
+		if album == nil {
+			continue
+		}
+
+		coverURL, coverExtension := s.resolveCoverURL(ctx, DownloadCategoryAlbum, s.albumHandler.GetCoverURL(album))
+		if coverURL == "" {
+			continue
+		}
+
+		if _, ok := seenURLs[coverURL]; ok {
+			continue
+		}
+
+		seenURLs[coverURL] = struct{}{}
+
+		if !strings.HasPrefix(coverExtension, ".") {
+			coverExtension = "." + coverExtension
+		}
+
+		coverPath, isCached, err := s.covers.get(ctx, coverURL, coverExtension, s.downloadCoverFile)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to download album cover of '%s' for the playlist cover: %v", album.Title, err)
+
+			continue
+		}
+
+		if !isCached {
+			s.incrementCoverDownloaded()
+		}
+
+		result = append(result, coverPath)
+	}
+
+	return result
+}
+
+// writePlaylistCover saves the playlist cover as a JPEG file: the first four images laid out 2x2,
+// or the first image alone when there are fewer of them.
+// The image is written to a temporary file first, so a failure never leaves a broken cover behind.
+func writePlaylistCover(imagePaths []string, destinationPath string) error {
+	images := make([]image.Image, 0, playlistMosaicCovers)
+
+	for _, imagePath := range imagePaths[:min(len(imagePaths), playlistMosaicCovers)] {
+		img, err := decodeImageFile(imagePath)
+		if err != nil {
+			return err
+		}
+
+		images = append(images, img)
+	}
+
+	cover := images[0]
+	if len(images) == playlistMosaicCovers {
+		cover = composeCoverMosaic(images)
+	}
+
+	tempPath := filepath.Join(filepath.Dir(destinationPath),
+		utils.SetFileExtension(defaultCoverFilename+"_"+uuid.New().String(), extensionJPG, false))
+
+	//nolint:gosec // The path is built from the playlist folder.
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, constants.DefaultFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create cover file: %w", err)
+	}
+
+	err = jpeg.Encode(file, cover, &jpeg.Options{Quality: playlistMosaicJPEGQuality})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tempPath, destinationPath)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to write cover file: %w", err)
+	}
+
+	return nil
+}
+
+// decodeImageFile decodes a JPEG or PNG image file.
+func decodeImageFile(path string) (image.Image, error) {
+	//nolint:gosec // The path comes from the cover cache.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image '%s': %w", path, err)
+	}
+
+	return img, nil
+}
+
+// composeCoverMosaic lays four images out 2x2, each scaled to a square tile.
+// The tile is as large as the smallest side of the images, up to playlistMosaicMaxTileSize.
+func composeCoverMosaic(images []image.Image) *image.RGBA {
+	tileSize := playlistMosaicMaxTileSize
+
+	for _, img := range images {
+		bounds := img.Bounds()
+		tileSize = min(tileSize, bounds.Dx(), bounds.Dy())
+	}
+
+	tileSize = max(tileSize, 1)
+
+	mosaic := image.NewRGBA(image.Rect(0, 0, 2*tileSize, 2*tileSize))
+
+	for index, img := range images[:playlistMosaicCovers] {
+		tile := image.Rect(0, 0, tileSize, tileSize).Add(image.Pt(index%2*tileSize, index/2*tileSize))
+		draw.Draw(mosaic, tile, scaleImage(img, tileSize), image.Point{}, draw.Src)
+	}
+
+	return mosaic
+}
+
+// scaleImage scales the image to a square of the given size.
+func scaleImage(img image.Image, size int) *image.RGBA {
+	return scaleImageTo(img, size, size)
+}
+
+// scaleImageTo scales the image to the given width and height, averaging the source pixels
+// that fall into every target pixel, so downscaled covers stay smooth.
+func scaleImageTo(img image.Image, width, height int) *image.RGBA {
+	var (
+		bounds = img.Bounds()
+		result = image.NewRGBA(image.Rect(0, 0, width, height))
+	)
+
+	for y := range height {
+		top := bounds.Min.Y + y*bounds.Dy()/height
+		bottom := max(bounds.Min.Y+(y+1)*bounds.Dy()/height, top+1)
+
+		for x := range width {
+			left := bounds.Min.X + x*bounds.Dx()/width
+			right := max(bounds.Min.X+(x+1)*bounds.Dx()/width, left+1)
+
+			var red, green, blue, alpha, count uint64
+
+			for sourceY := top; sourceY < bottom; sourceY++ {
+				for sourceX := left; sourceX < right; sourceX++ {
+					r, g, b, a := img.At(sourceX, sourceY).RGBA()
+					red += uint64(r)
+					green += uint64(g)
+					blue += uint64(b)
+					alpha += uint64(a)
+					count++
+				}
+			}
+
+			offset := result.PixOffset(x, y)
+			// The 16-bit channels are averaged and reduced to 8 bits.
+			result.Pix[offset] = uint8(red / count >> 8)
+			result.Pix[offset+1] = uint8(green / count >> 8)
+			result.Pix[offset+2] = uint8(blue / count >> 8)
+			result.Pix[offset+3] = uint8(alpha / count >> 8)
+		}
+	}
+
+	return result
+}
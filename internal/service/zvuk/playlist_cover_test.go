@@ -0,0 +1,140 @@
+package zvuk
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// newTestSolidImage creates an image of the given size filled with one color.
+func newTestSolidImage(width, height int, fill color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, fill)
+		}
+	}
+
+	return img
+}
+
+// writeTestPNG saves the image as a PNG file.
+func writeTestPNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+
+	//nolint:gosec // It's a test file.
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	require.NoError(t, png.Encode(file, img))
+	require.NoError(t, file.Close())
+}
+
+// TestComposeCoverMosaic tests that four images are scaled to the smallest side and laid out 2x2.
+func TestComposeCoverMosaic(t *testing.T) {
+	t.Parallel()
+
+	var (
+		red    = color.RGBA{R: 255, A: 255}
+		green  = color.RGBA{G: 255, A: 255}
+		blue   = color.RGBA{B: 255, A: 255}
+		yellow = color.RGBA{R: 255, G: 255, A: 255}
+	)
+
+	mosaic := composeCoverMosaic([]image.Image{
+		newTestSolidImage(40, 40, red),
+		newTestSolidImage(20, 30, green),
+		newTestSolidImage(80, 80, blue),
+		newTestSolidImage(40, 40, yellow),
+	})
+
+	assert.Equal(t, image.Rect(0, 0, 40, 40), mosaic.Bounds())
+	assert.Equal(t, red, mosaic.RGBAAt(5, 5))
+	assert.Equal(t, green, mosaic.RGBAAt(35, 5))
+	assert.Equal(t, blue, mosaic.RGBAAt(5, 35))
+	assert.Equal(t, yellow, mosaic.RGBAAt(35, 35))
+}
+
+// TestScaleImage tests that the source pixels falling into a target pixel are averaged.
+func TestScaleImage(t *testing.T) {
+	t.Parallel()
+
+	img := newTestSolidImage(4, 4, color.RGBA{A: 255})
+	img.Set(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	img.Set(1, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	scaled := scaleImage(img, 2)
+
+	assert.Equal(t, color.RGBA{R: 127, G: 127, B: 127, A: 255}, scaled.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{A: 255}, scaled.RGBAAt(1, 1))
+}
+
+// TestWritePlaylistCover tests that the cover is a mosaic of four images, or the first image when there are fewer.
+func TestWritePlaylistCover(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	imagePaths := make([]string, 0, playlistMosaicCovers)
+	for index, size := range []int{100, 120, 140, 160} {
+		imagePath := filepath.Join(dir, "album"+string(rune('a'+index))+".png")
+		writeTestPNG(t, imagePath, newTestSolidImage(size, size, color.RGBA{R: uint8(index * 60), A: 255}))
+
+		imagePaths = append(imagePaths, imagePath)
+	}
+
+	decodeCover := func(t *testing.T, path string) image.Image {
+		t.Helper()
+
+		//nolint:gosec // It's a test file.
+		file, err := os.Open(path)
+		require.NoError(t, err)
+
+		defer file.Close()
+
+		img, err := jpeg.Decode(file)
+		require.NoError(t, err)
+
+		return img
+	}
+
+	t.Run("mosaic", func(t *testing.T) {
+		t.Parallel()
+
+		coverPath := filepath.Join(dir, "mosaic.jpg")
+		require.NoError(t, writePlaylistCover(imagePaths, coverPath))
+
+		assert.Equal(t, image.Rect(0, 0, 200, 200), decodeCover(t, coverPath).Bounds())
+	})
+
+	t.Run("fewer albums", func(t *testing.T) {
+		t.Parallel()
+
+		coverPath := filepath.Join(dir, "single.jpg")
+		require.NoError(t, writePlaylistCover(imagePaths[:2], coverPath))
+
+		assert.Equal(t, image.Rect(0, 0, 100, 100), decodeCover(t, coverPath).Bounds())
+	})
+
+	t.Run("broken image", func(t *testing.T) {
+		t.Parallel()
+
+		brokenPath := filepath.Join(dir, "broken.png")
+		//nolint:gosec // It's a test file.
+		require.NoError(t, os.WriteFile(brokenPath, []byte("not an image"), constants.DefaultFilePermissions))
+
+		coverPath := filepath.Join(dir, "broken.jpg")
+		require.Error(t, writePlaylistCover([]string{brokenPath}, coverPath))
+		assert.NoFileExists(t, coverPath)
+	})
+}
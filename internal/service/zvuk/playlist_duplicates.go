@@ -0,0 +1,199 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// savedTrack describes a track file saved during the current collection download.
+type savedTrack struct {
+	// path is the final path of the track file.
+	path string
+	// quality is the quality the track was saved in.
+	quality TrackQuality
+}
+
+// findPlaylistDuplicates returns the occurrence number of every repeated track keyed by its index.
+// The first occurrence of a track is not included.
+func findPlaylistDuplicates(trackIDs []int64) map[int]int64 {
+	var (
+		result      = make(map[int]int64)
+		occurrences = make(map[int64]int64, len(trackIDs))
+	)
+
+	for index, trackID := range trackIDs {
+		occurrences[trackID]++
+
+		if occurrences[trackID] > 1 {
+			result[index] = occurrences[trackID]
+		}
+	}
+
+	return result
+}
+
+// isDuplicate reports whether the track at the given index repeats an earlier playlist track.
+func (m *downloadTracksMetadata) isDuplicate(index int) bool {
+	_, ok := m.duplicateNumbers[index]
+
+	return ok
+}
+
+// rememberSavedTrack records the final path of a track so repeated occurrences can link to it.
+// The track is also recorded as fetched when the playlist is synced.
+func (m *downloadTracksMetadata) rememberSavedTrack(t *downloadTrackTask) {
+	if m == nil || t.duplicateNumber > 0 {
+		return
+	}
+
+	m.playlistSync.markSynced(t.trackIDString, t.trackPath, t.trackPosition)
+	m.rememberSavedTrackPath(t.trackIDString, t.trackPath, t.quality)
+}
+
+// rememberSavedTrackPath records the final path of a track when saved tracks are needed later.
+func (m *downloadTracksMetadata) rememberSavedTrackPath(trackID, trackPath string, quality TrackQuality) {
+	if len(m.duplicateNumbers) == 0 && !m.keepSavedTracks {
+		return
+	}
+
+	m.savedTracksMutex.Lock()
+	defer m.savedTracksMutex.Unlock()
+
+	if m.savedTracks == nil {
+		m.savedTracks = make(map[string]*savedTrack)
+	}
+
+	m.savedTracks[trackID] = &savedTrack{
+		path:    trackPath,
+		quality: quality,
+	}
+}
+
+// getSavedTrack returns the first saved occurrence of the track, or nil if it was not saved.
+func (m *downloadTracksMetadata) getSavedTrack(trackID string) *savedTrack {
+	m.savedTracksMutex.Lock()
+	defer m.savedTracksMutex.Unlock()
+
+	return m.savedTracks[trackID]
+}
+
+// downloadPlaylistDuplicates processes repeated playlist tracks according to the playlist_duplicates policy.
+func (s *ServiceImpl) downloadPlaylistDuplicates(ctx context.Context, metadata *downloadTracksMetadata) {
+	for _, index := range slices.Sorted(maps.Keys(metadata.duplicateNumbers)) {
+		if ctx.Err() != nil || metadata.failureTracker.isAborted() {
+			return
+		}
+
+		if metadata.isDeselected(index) {
+			continue
+		}
+
+		trackID := metadata.trackIDs[index]
+
+		if s.isRunLimitReached(ctx) {
+			s.recordTracksNotStarted(metadata, []int64{trackID})
+
+			return
+		}
+
+		s.incrementPlaylistDuplicate()
+
+		switch s.cfg.PlaylistDuplicates {
+		case config.PlaylistDuplicatesSkip:
+			s.skipPlaylistDuplicate(ctx, index, trackID, metadata)
+
+			continue
+		case config.PlaylistDuplicatesHardlink:
+			if s.linkPlaylistDuplicate(ctx, index, trackID, metadata) {
+				continue
+			}
+		}
+
+		s.downloadSingleTrack(ctx, index, trackID, metadata)
+	}
+}
+
+// skipPlaylistDuplicate records a repeated playlist track as skipped.
+func (s *ServiceImpl) skipPlaylistDuplicate(
+	ctx context.Context,
+	index int,
+	trackID int64,
+	metadata *downloadTracksMetadata,
+) {
+	task, err := s.newDownloadTrackTask(ctx, index, trackID, metadata)
+	if err != nil {
+		return
+	}
+
+	logger.Infof(ctx, "Track '%s' is repeated in the playlist, skipping", task.track.Title)
+
+	s.incrementTrackSkipped(task.trackIDString, SkipReasonDuplicate)
+	s.recordSkippedItem(&SkippedItem{
+		TrackID:        task.trackIDString,
+		Title:          task.track.Title,
+		ParentCategory: metadata.category,
+		ParentID:       task.parentID,
+		ParentTitle:    task.parentTitle,
+		Reason:         SkipReasonDuplicate,
+	})
+}
+
+// linkPlaylistDuplicate saves a repeated playlist track as a hard link to its first occurrence.
+// It returns false when the track has to be downloaded instead.
+func (s *ServiceImpl) linkPlaylistDuplicate(
+	ctx context.Context,
+	index int,
+	trackID int64,
+	metadata *downloadTracksMetadata,
+) bool {
+	task, err := s.newDownloadTrackTask(ctx, index, trackID, metadata)
+	if err != nil {
+		s.registerTrackOutcome(ctx, metadata, true)
+
+		return true
+	}
+
+	source := metadata.getSavedTrack(task.trackIDString)
+	if source == nil {
+		return false
+	}
+
+	task.quality = source.quality
+	s.prepareTrackFiles(ctx, task)
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would link '%s' to '%s'", task.trackPath, source.path)
+		s.incrementTrackLinked(task.trackIDString)
+
+		return true
+	}
+
+	if s.cfg.ReplaceTracks {
+		_ = os.Remove(task.trackPath)
+	}
+
+	err = os.Link(source.path, task.trackPath)
+
+	switch {
+	case err == nil:
+		logger.Infof(ctx, "Track '%s' linked to '%s'", task.trackPath, source.path)
+		s.incrementTrackLinked(task.trackIDString)
+	case errors.Is(err, os.ErrExist):
+		logger.Infof(ctx, "Track '%s' already exists, skipping link", task.trackPath)
+		s.incrementTrackSkipped(task.trackIDString, SkipReasonExists)
+	default:
+		logger.Warnf(ctx, "Failed to link '%s', downloading it again: %v", task.trackPath, err)
+
+		return false
+	}
+
+	s.registerTrackOutcome(ctx, metadata, false)
+
+	return true
+}
@@ -0,0 +1,101 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestFindPlaylistDuplicates tests that only repeated occurrences are numbered.
+func TestFindPlaylistDuplicates(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[int]int64{2: 2, 3: 3, 4: 2}, findPlaylistDuplicates([]int64{1, 2, 1, 1, 2, 3}))
+	assert.Empty(t, findPlaylistDuplicates([]int64{1, 2, 3}))
+}
+
+// TestDownloadTracks_PlaylistDuplicates tests every playlist_duplicates policy.
+func TestDownloadTracks_PlaylistDuplicates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		policy             string
+		expectedFetches    int
+		expectedFiles      int
+		expectedDownloaded int64
+		expectedSkipped    int64
+		expectedLinked     int64
+	}{
+		{
+			name:               "numbered",
+			policy:             config.PlaylistDuplicatesNumbered,
+			expectedFetches:    2,
+			expectedFiles:      2,
+			expectedDownloaded: 2,
+		},
+		{
+			name:               "skip",
+			policy:             config.PlaylistDuplicatesSkip,
+			expectedFetches:    1,
+			expectedFiles:      1,
+			expectedDownloaded: 1,
+			expectedSkipped:    1,
+		},
+		{
+			name:               "hardlink",
+			policy:             config.PlaylistDuplicatesHardlink,
+			expectedFetches:    1,
+			expectedFiles:      2,
+			expectedDownloaded: 1,
+			expectedLinked:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+				cfg.PlaylistDuplicates = tt.policy
+			})
+			defer setup.cleanup()
+
+			audioData := makeFakeAudioData(4)
+
+			for range tt.expectedFetches {
+				setupMockStreamMetadata(setup.mockClient, "801", TrackQualityFLACString, "/stream/801")
+				setupMockFetchTrack(setup.mockClient, "/stream/801", audioData)
+			}
+
+			metadata := newTestMetadata([]int64{801, 801}, 8).build()
+			metadata.category = DownloadCategoryPlaylist
+			metadata.audioCollection.category = DownloadCategoryPlaylist
+
+			impl, ok := setup.service.(*ServiceImpl)
+			require.True(t, ok)
+
+			impl.downloadTracks(context.Background(), metadata)
+
+			audioFiles := findAudioFiles(t, setup.tempDir)
+			require.Len(t, audioFiles, tt.expectedFiles)
+
+			for _, audioFile := range audioFiles {
+				content, err := os.ReadFile(audioFile)
+				require.NoError(t, err)
+				assert.Equal(t, audioData, content)
+			}
+
+			assert.Equal(t, int64(1), impl.Statistics().PlaylistDuplicates)
+			assert.Equal(t, tt.expectedDownloaded, impl.Statistics().TracksDownloaded)
+			assert.Equal(t, tt.expectedSkipped, impl.Statistics().TracksSkippedDuplicate)
+			assert.Equal(t, tt.expectedLinked, impl.Statistics().TracksLinked)
+			assert.Empty(t, impl.Statistics().Errors)
+		})
+	}
+}
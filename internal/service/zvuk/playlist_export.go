@@ -0,0 +1,255 @@
+package zvuk
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// ErrNothingToExport is returned when the download history has no saved tracks of the requested collections.
+var ErrNothingToExport = errors.New("no saved albums or playlists found in the download history")
+
+// PlaylistExportQuery selects the collections of the download history written as M3U playlists.
+type PlaylistExportQuery struct {
+	// Items keeps the albums and playlists with these categories and IDs (empty keeps all of them).
+	Items []ShortDownloadItem
+	// Extension is the extension of the playlist files (".m3u8" or ".m3u", empty uses ".m3u8").
+	Extension string
+	// OutputPath is the folder the playlists are written to.
+	// Empty writes every playlist into the deepest folder holding all of its tracks.
+	OutputPath string
+}
+
+// ExportedPlaylist is an M3U playlist written from the download history.
+type ExportedPlaylist struct {
+	// Category is the type of the collection (album or playlist).
+	Category DownloadCategory
+	// ItemID is the ID of the collection.
+	ItemID string
+	// Title is the title of the collection.
+	Title string
+	// Path is where the playlist was saved.
+	Path string
+	// TracksCount is the number of tracks listed in the playlist.
+	TracksCount int
+}
+
+// historyCollection is an album or playlist recorded in the download history with its saved tracks.
+type historyCollection struct {
+	// item is the category and ID of the collection.
+	item ShortDownloadItem
+	// title is the title of the collection.
+	title string
+	// entries are the latest records of the collection tracks whose files still exist.
+	entries []*HistoryEntry
+}
+
+// ExportPlaylists writes an M3U playlist for every album and playlist of the download history matching the query.
+// The tracks are listed in their order in the collection, pointing at the files they were saved to.
+// Tracks whose files no longer exist are left out. Existing playlist files are kept
+// unless replace_playlist_files is enabled.
+func ExportPlaylists(ctx context.Context, cfg *config.Config, query *PlaylistExportQuery) ([]*ExportedPlaylist, error) {
+	entries, err := readHistoryEntries(ctx, cfg.HistoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := groupHistoryCollections(entries, query.Items)
+	if len(collections) == 0 {
+		return nil, ErrNothingToExport
+	}
+
+	extension := cmp.Or(query.Extension, extensionM3U8)
+
+	if query.OutputPath != "" {
+		if err = os.MkdirAll(query.OutputPath, constants.DefaultFolderPermissions); err != nil {
+			return nil, fmt.Errorf("failed to create export folder: %w", err)
+		}
+	}
+
+	var (
+		result    = make([]*ExportedPlaylist, 0, len(collections))
+		usedPaths = make(map[string]struct{}, len(collections))
+	)
+
+	for _, collection := range collections {
+		playlistPath := collection.playlistPath(cfg, query.OutputPath, extension)
+		if _, ok := usedPaths[playlistPath]; ok {
+			// Collections sharing a title are told apart by their IDs.
+			playlistPath = strings.TrimSuffix(playlistPath, extension) + " (" + collection.item.ItemID + ")" + extension
+		}
+
+		usedPaths[playlistPath] = struct{}{}
+
+		if _, err = os.Stat(playlistPath); err == nil {
+			if !cfg.ReplacePlaylistFiles {
+				logger.Warnf(ctx, "Playlist file already exists, skipping (set replace_playlist_files to overwrite it): %s",
+					playlistPath)
+
+				continue
+			}
+
+			logger.Infof(ctx, "Replacing existing playlist file: %s", playlistPath)
+		}
+
+		fileEntries := make([]*playlistFileEntry, 0, len(collection.entries))
+		for _, entry := range collection.entries {
+			fileEntries = append(fileEntries, &playlistFileEntry{
+				path:     entry.Path,
+				duration: unknownPlaylistEntryDuration,
+				artists:  entry.Artists,
+				title:    entry.Title,
+			})
+		}
+
+		content := buildPlaylistFile(filepath.Dir(playlistPath), fileEntries)
+
+		if err = os.MkdirAll(filepath.Dir(playlistPath), constants.DefaultFolderPermissions); err != nil {
+			return result, fmt.Errorf("failed to create playlist folder: %w", err)
+		}
+
+		err = os.WriteFile(playlistPath, []byte(content), constants.DefaultFilePermissions)
+		if err != nil {
+			return result, fmt.Errorf("failed to write playlist '%s': %w", playlistPath, err)
+		}
+
+		result = append(result, &ExportedPlaylist{
+			Category:    collection.item.Category,
+			ItemID:      collection.item.ItemID,
+			Title:       collection.title,
+			Path:        playlistPath,
+			TracksCount: len(fileEntries),
+		})
+	}
+
+	return result, nil
+}
+
+// groupHistoryCollections returns the albums and playlists of the history entries in the order
+// they were first downloaded, keeping only the requested items when any are given.
+// Every track is represented by its latest record, and the tracks are sorted by their position.
+func groupHistoryCollections(entries []*HistoryEntry, items []ShortDownloadItem) []*historyCollection {
+	categories := map[string]DownloadCategory{
+		DownloadCategoryAlbum.ToLowerCase():    DownloadCategoryAlbum,
+		DownloadCategoryPlaylist.ToLowerCase(): DownloadCategoryPlaylist,
+	}
+
+	var (
+		result       []*historyCollection
+		collections  = make(map[ShortDownloadItem]*historyCollection)
+		trackIndexes = make(map[ShortDownloadItem]map[string]int)
+	)
+
+	for _, entry := range entries {
+		category, ok := categories[entry.Category]
+		if !ok || entry.ParentID == "" {
+			continue
+		}
+
+		key := ShortDownloadItem{Category: category, ItemID: entry.ParentID}
+		if len(items) > 0 && !slices.Contains(items, key) {
+			continue
+		}
+
+		collection, ok := collections[key]
+		if !ok {
+			collection = &historyCollection{item: key}
+			collections[key] = collection
+			trackIndexes[key] = make(map[string]int)
+			result = append(result, collection)
+		}
+
+		collection.title = cmp.Or(entry.ParentTitle, collection.title)
+
+		// A track downloaded again replaces its earlier record.
+		if index, ok := trackIndexes[key][entry.TrackID]; ok {
+			collection.entries[index] = entry
+
+			continue
+		}
+
+		trackIndexes[key][entry.TrackID] = len(collection.entries)
+		collection.entries = append(collection.entries, entry)
+	}
+
+	result = slices.DeleteFunc(result, func(collection *historyCollection) bool {
+		collection.entries = slices.DeleteFunc(collection.entries, func(entry *HistoryEntry) bool {
+			_, err := os.Stat(entry.Path)
+
+			return err != nil
+		})
+
+		// Records of earlier versions have no position and keep the order they were saved in.
+		slices.SortStableFunc(collection.entries, func(a, b *HistoryEntry) int {
+			return cmp.Compare(a.Position, b.Position)
+		})
+
+		return len(collection.entries) == 0
+	})
+
+	return result
+}
+
+// playlistPath returns where the playlist of the collection is written: into outputPath,
+// named after the collection, or into the deepest folder holding all of its tracks, named after the folder.
+// Under playlist_layout library, the tracks of a playlist are in their album folders,
+// so its playlist is written into the playlist folder, like the downloads do.
+func (c *historyCollection) playlistPath(cfg *config.Config, outputPath, extension string) string {
+	if outputPath != "" {
+		name := utils.SanitizeFilename(c.title)
+		if name == "" {
+			name = c.item.Category.ToLowerCase() + " " + c.item.ItemID
+		}
+
+		return filepath.Join(outputPath, name+extension)
+	}
+
+	if c.item.Category == DownloadCategoryPlaylist && cfg.PlaylistLayout == config.PlaylistLayoutLibrary {
+		folder := filepath.Join(cfg.OutputPath, playlistFolderName(c.title, cfg.MaxFolderNameLength))
+
+		return filepath.Join(folder, filepath.Base(folder)+extension)
+	}
+
+	folder := filepath.Dir(c.entries[0].Path)
+
+	for _, entry := range c.entries[1:] {
+		for !isWithinFolder(entry.Path, folder) {
+			parent := filepath.Dir(folder)
+			if parent == folder {
+				break
+			}
+
+			folder = parent
+		}
+	}
+
+	return filepath.Join(folder, filepath.Base(folder)+extension)
+}
+
+// playlistFolderName returns the name of the folder a playlist is downloaded into,
+// truncated to maxLength characters like the folders the downloads create.
+func playlistFolderName(title string, maxLength int64) string {
+	name := []rune(strings.TrimSpace(title))
+	if maxLength > 0 && int64(len(name)) > maxLength {
+		name = name[:maxLength]
+	}
+
+	return string(name)
+}
+
+// isWithinFolder reports whether the path is inside the folder.
+func isWithinFolder(path, folder string) bool {
+	relativePath, err := filepath.Rel(folder, path)
+
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
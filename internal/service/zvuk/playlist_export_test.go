@@ -0,0 +1,137 @@
+package zvuk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestExportPlaylists verifies that the playlists written from the download history list the existing files
+// in their order in the collection, using the latest record of a track downloaded again.
+func TestExportPlaylists(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath  = t.TempDir()
+		albumDir    = filepath.Join(outputPath, "Artist - Album")
+		playlistDir = filepath.Join(outputPath, "Mix")
+		historyPath = filepath.Join(t.TempDir(), "history.jsonl")
+	)
+
+	writeFile := func(path string) string {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), constants.DefaultFolderPermissions))
+		require.NoError(t, os.WriteFile(path, []byte("audio"), constants.DefaultFilePermissions))
+
+		return path
+	}
+
+	history := newDownloadHistory(historyPath)
+	for _, entry := range []*HistoryEntry{
+		{TrackID: "2", Title: "Second", Artists: []string{"Artist"}, Category: "album", ParentID: "10",
+			ParentTitle: "Album", Position: 2, Path: writeFile(filepath.Join(albumDir, "02 - Second.mp3"))},
+		{TrackID: "1", Title: "First", Artists: []string{"Artist"}, Category: "album", ParentID: "10",
+			ParentTitle: "Album", Position: 1, Path: writeFile(filepath.Join(albumDir, "01 - First.mp3"))},
+		{TrackID: "3", Title: "Deleted", Category: "album", ParentID: "10",
+			ParentTitle: "Album", Position: 3, Path: filepath.Join(albumDir, "03 - Deleted.mp3")},
+		{TrackID: "2", Title: "Second", Artists: []string{"Artist"}, Category: "album", ParentID: "10",
+			ParentTitle: "Album", Position: 2, Path: writeFile(filepath.Join(albumDir, "02 - Second.flac"))},
+		{TrackID: "5", Title: "Mixed", Artists: []string{"Other"}, Category: "playlist", ParentID: "20",
+			ParentTitle: "Mix", Position: 1, Path: writeFile(filepath.Join(playlistDir, "01 - Mixed.flac"))},
+		{TrackID: "6", Title: "Single", Category: "track", ParentID: "30",
+			Path: writeFile(filepath.Join(outputPath, "Single.flac"))},
+	} {
+		require.NoError(t, history.add(entry))
+	}
+
+	cfg := &config.Config{HistoryPath: historyPath}
+
+	playlists, err := ExportPlaylists(t.Context(), cfg, &PlaylistExportQuery{})
+	require.NoError(t, err)
+	require.Len(t, playlists, 2, "standalone tracks are not exported")
+
+	assert.Equal(t, DownloadCategoryAlbum, playlists[0].Category)
+	assert.Equal(t, filepath.Join(albumDir, "Artist - Album.m3u8"), playlists[0].Path)
+	assert.Equal(t, 2, playlists[0].TracksCount)
+
+	content, err := os.ReadFile(playlists[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "#EXTM3U\n"+
+		"#EXTINF:-1,Artist - First\n01 - First.mp3\n"+
+		"#EXTINF:-1,Artist - Second\n02 - Second.flac\n", string(content))
+
+	// Only the requested playlist is written into the given folder, named after the playlist.
+	exportDir := filepath.Join(t.TempDir(), "Playlists")
+
+	playlists, err = ExportPlaylists(t.Context(), cfg, &PlaylistExportQuery{
+		Items:      []ShortDownloadItem{{Category: DownloadCategoryPlaylist, ItemID: "20"}},
+		Extension:  ".m3u",
+		OutputPath: exportDir,
+	})
+	require.NoError(t, err)
+	require.Len(t, playlists, 1)
+	assert.Equal(t, filepath.Join(exportDir, "Mix.m3u"), playlists[0].Path)
+
+	content, err = os.ReadFile(playlists[0].Path)
+	require.NoError(t, err)
+
+	relativePath, err := filepath.Rel(exportDir, filepath.Join(playlistDir, "01 - Mixed.flac"))
+	require.NoError(t, err)
+	assert.Equal(t, "#EXTM3U\n#EXTINF:-1,Other - Mixed\n"+filepath.ToSlash(relativePath)+"\n", string(content))
+
+	// An existing playlist file is kept unless replace_playlist_files is enabled.
+	albumQuery := &PlaylistExportQuery{Items: []ShortDownloadItem{{Category: DownloadCategoryAlbum, ItemID: "10"}}}
+	albumPlaylistPath := filepath.Join(albumDir, "Artist - Album.m3u8")
+	require.NoError(t, os.WriteFile(albumPlaylistPath, []byte("edited"), constants.DefaultFilePermissions))
+
+	playlists, err = ExportPlaylists(t.Context(), cfg, albumQuery)
+	require.NoError(t, err)
+	assert.Empty(t, playlists)
+
+	content, err = os.ReadFile(albumPlaylistPath)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(content))
+
+	cfg.ReplacePlaylistFiles = true
+
+	playlists, err = ExportPlaylists(t.Context(), cfg, albumQuery)
+	require.NoError(t, err)
+	require.Len(t, playlists, 1)
+
+	content, err = os.ReadFile(albumPlaylistPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "#EXTM3U")
+
+	_, err = ExportPlaylists(t.Context(), cfg, &PlaylistExportQuery{
+		Items: []ShortDownloadItem{{Category: DownloadCategoryAlbum, ItemID: "404"}},
+	})
+	require.ErrorIs(t, err, ErrNothingToExport)
+}
+
+// TestHistoryCollectionPlaylistPath verifies that a playlist without a folder of its own
+// is written into the deepest folder holding all of its tracks, or into the playlist folder
+// under playlist_layout library.
+func TestHistoryCollectionPlaylistPath(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "music")
+	collection := &historyCollection{
+		item:  ShortDownloadItem{Category: DownloadCategoryPlaylist, ItemID: "1"},
+		title: "Road Trip",
+		entries: []*HistoryEntry{
+			{Path: filepath.Join(root, "Artist", "Album A", "01 - One.flac")},
+			{Path: filepath.Join(root, "Artist", "Album B", "03 - Three.flac")},
+		},
+	}
+
+	cfg := &config.Config{OutputPath: root, PlaylistLayout: config.PlaylistLayoutFolder}
+	assert.Equal(t, filepath.Join(root, "Artist", "Artist.m3u8"), collection.playlistPath(cfg, "", ".m3u8"))
+
+	cfg.PlaylistLayout = config.PlaylistLayoutLibrary
+	assert.Equal(t, filepath.Join(root, "Road Trip", "Road Trip.m3u8"), collection.playlistPath(cfg, "", ".m3u8"))
+}
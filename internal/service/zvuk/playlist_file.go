@@ -0,0 +1,149 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// extensionM3U8 is the file extension of UTF-8 encoded M3U playlists.
+	extensionM3U8 = ".m3u8"
+	// unknownPlaylistEntryDuration is the #EXTINF duration of a track whose length is not known.
+	unknownPlaylistEntryDuration = -1
+)
+
+// playlistFileEntry is a track listed in an M3U playlist.
+type playlistFileEntry struct {
+	// path is where the track file is saved.
+	path string
+	// duration is the track length in seconds (unknownPlaylistEntryDuration if not known).
+	duration int64
+	// artists are the names of the track artists.
+	artists []string
+	// title is the track title (empty leaves out the #EXTINF line).
+	title string
+}
+
+// buildPlaylistFile returns the content of an M3U playlist saved in playlistDir,
+// with the track paths relative to it when possible.
+func buildPlaylistFile(playlistDir string, entries []*playlistFileEntry) string {
+	var content strings.Builder
+
+	content.WriteString("#EXTM3U\n")
+
+	for _, entry := range entries {
+		relativePath, err := filepath.Rel(playlistDir, entry.path)
+		if err != nil {
+			relativePath = entry.path
+		}
+
+		if entry.title != "" {
+			fmt.Fprintf(&content, "#EXTINF:%d,%s - %s\n",
+				entry.duration, strings.Join(entry.artists, ", "), entry.title)
+		}
+
+		content.WriteString(filepath.ToSlash(relativePath))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// savedPlaylistFileEntries returns the saved tracks of the collection in its order, for its playlist file.
+// Tracks that were not saved are left out, and a repeated track is listed at every occurrence.
+func savedPlaylistFileEntries(trackIDs []int64, metadata *downloadTracksMetadata) []*playlistFileEntry {
+	entries := make([]*playlistFileEntry, 0, len(trackIDs))
+
+	for _, trackID := range trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+
+		saved := metadata.getSavedTrack(trackIDString)
+		if saved == nil {
+			continue
+		}
+
+		entry := &playlistFileEntry{path: saved.path}
+		if track := metadata.tracksMetadata[trackIDString]; track != nil {
+			entry.duration = track.Duration
+			entry.artists = track.ArtistNames
+			entry.title = track.Title
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// writePlaylistFile writes the M3U playlist of the collection into its folder, named after the folder.
+func (s *ServiceImpl) writePlaylistFile(
+	ctx context.Context,
+	collection *audioCollection,
+	entries []*playlistFileEntry,
+) {
+	playlistDir := collection.tracksPath
+	playlistPath := filepath.Join(playlistDir, filepath.Base(playlistDir)+extensionM3U8)
+
+	_, err := os.Stat(playlistPath)
+	isExisting := err == nil
+
+	if isExisting && !s.cfg.ReplacePlaylistFiles {
+		logMessage := "Playlist file already exists, skipping save: %s"
+		if s.cfg.DryRun {
+			logMessage = "[DRY-RUN] Playlist file already exists, would skip: %s"
+		}
+
+		logger.Infof(ctx, logMessage, playlistPath)
+
+		return
+	}
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would write playlist file with %d tracks: %s", len(entries), playlistPath)
+
+		return
+	}
+
+	if isExisting {
+		logger.Infof(ctx, "Replacing existing playlist file: %s", playlistPath)
+	}
+
+	content := buildPlaylistFile(playlistDir, entries)
+
+	err = os.WriteFile(playlistPath, []byte(content), constants.DefaultFilePermissions)
+	if err != nil {
+		s.recordError(&DownloadError{
+			Category:  collection.category,
+			ItemID:    collection.id,
+			ItemTitle: collection.title,
+			Phase:     "writing playlist file",
+			Error:     err,
+		})
+
+		return
+	}
+
+	logger.Infof(ctx, "Playlist file with %d tracks saved to: %s", len(entries), playlistPath)
+}
+
+// writeCollectionPlaylist writes the M3U playlist of an album or playlist folder
+// once its tracks are saved (write_playlist_files).
+func (s *ServiceImpl) writeCollectionPlaylist(ctx context.Context, metadata *downloadTracksMetadata) {
+	collection := metadata.audioCollection
+	if !s.cfg.WritePlaylistFiles || collection == nil || !collection.hasOwnFolder || ctx.Err() != nil {
+		return
+	}
+
+	if collection.category != DownloadCategoryAlbum && collection.category != DownloadCategoryPlaylist {
+		return
+	}
+
+	s.writePlaylistFile(ctx, collection, savedPlaylistFileEntries(collection.trackIDs, metadata))
+}
@@ -0,0 +1,90 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestWriteCollectionPlaylist verifies that an album folder gets a playlist of its saved tracks
+// only when write_playlist_files is enabled.
+func TestWriteCollectionPlaylist(t *testing.T) {
+	t.Parallel()
+
+	for _, isEnabled := range []bool{false, true} {
+		albumDir := filepath.Join(t.TempDir(), "Artist - Album")
+		require.NoError(t, os.MkdirAll(albumDir, 0o755))
+
+		impl, ok := NewService(&config.Config{WritePlaylistFiles: isEnabled}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		metadata := &downloadTracksMetadata{
+			audioCollection: &audioCollection{
+				category:     DownloadCategoryAlbum,
+				id:           "10",
+				title:        "Album",
+				trackIDs:     []int64{1, 2},
+				tracksPath:   albumDir,
+				hasOwnFolder: true,
+			},
+			keepSavedTracks: true,
+			tracksMetadata: map[string]*zvuk.Track{
+				"2": {ID: 2, Title: "Second", Duration: 200, ArtistNames: []string{"Artist"}},
+			},
+		}
+
+		metadata.rememberSavedTrack(&downloadTrackTask{
+			trackIDString: "2",
+			trackPath:     filepath.Join(albumDir, "02 - Second.flac"),
+		})
+
+		impl.writeCollectionPlaylist(context.Background(), metadata)
+
+		content, err := os.ReadFile(filepath.Join(albumDir, "Artist - Album.m3u8"))
+		if !isEnabled {
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, "#EXTM3U\n#EXTINF:200,Artist - Second\n02 - Second.flac\n", string(content))
+	}
+}
+
+// TestWritePlaylistFile_Existing verifies that an existing playlist file is replaced only with replace_playlist_files.
+func TestWritePlaylistFile_Existing(t *testing.T) {
+	t.Parallel()
+
+	for _, isReplaced := range []bool{false, true} {
+		playlistDir := filepath.Join(t.TempDir(), "Mix")
+		playlistPath := filepath.Join(playlistDir, "Mix.m3u8")
+
+		require.NoError(t, os.MkdirAll(playlistDir, 0o755))
+		require.NoError(t, os.WriteFile(playlistPath, []byte("edited"), 0o644)) //nolint:gosec // It's a test file.
+
+		impl, ok := NewService(&config.Config{ReplacePlaylistFiles: isReplaced}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		impl.writePlaylistFile(context.Background(), &audioCollection{
+			category:   DownloadCategoryPlaylist,
+			tracksPath: playlistDir,
+		}, []*playlistFileEntry{{path: filepath.Join(playlistDir, "01 - One.flac"), title: ""}})
+
+		content, err := os.ReadFile(playlistPath)
+		require.NoError(t, err)
+
+		if isReplaced {
+			assert.Equal(t, "#EXTM3U\n01 - One.flac\n", string(content))
+		} else {
+			assert.Equal(t, "edited", string(content))
+		}
+	}
+}
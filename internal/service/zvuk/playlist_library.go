@@ -0,0 +1,53 @@
+package zvuk
+
+import "context"
+
+// downloadPlaylistToLibrary saves the playlist tracks into their album folders, like standalone tracks,
+// and writes an M3U playlist referencing them into the playlist folder.
+func (s *ServiceImpl) downloadPlaylistToLibrary(ctx context.Context, metadata *downloadTracksMetadata) {
+	playlistCollection := metadata.audioCollection
+
+	// Each track resolves its own album collection, and a track repeated in the playlist is saved once.
+	libraryMetadata := &downloadTracksMetadata{
+		category:        DownloadCategoryTrack,
+		trackIDs:        uniqueTrackIDs(metadata.trackIDs),
+		tracksMetadata:  metadata.tracksMetadata,
+		albumsMetadata:  metadata.albumsMetadata,
+		albumsTags:      metadata.albumsTags,
+		labelsMetadata:  metadata.labelsMetadata,
+		keepSavedTracks: true,
+		playlistSync:    metadata.playlistSync,
+		libraryPlaylist: playlistCollection,
+	}
+
+	s.downloadTracks(ctx, libraryMetadata)
+
+	// The playlist cover is finalized here, since the tracks belong to album collections.
+	s.finalizeCover(ctx, playlistCollection.tracksCount, playlistCollection)
+	s.generatePlaylistCover(ctx, metadata)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.writePlaylistFile(ctx, playlistCollection, savedPlaylistFileEntries(playlistCollection.trackIDs, libraryMetadata))
+}
+
+// uniqueTrackIDs returns the track IDs without repetitions, keeping the first occurrence order.
+func uniqueTrackIDs(trackIDs []int64) []int64 {
+	var (
+		result = make([]int64, 0, len(trackIDs))
+		seen   = make(map[int64]struct{}, len(trackIDs))
+	)
+
+	for _, trackID := range trackIDs {
+		if _, ok := seen[trackID]; ok {
+			continue
+		}
+
+		seen[trackID] = struct{}{}
+		result = append(result, trackID)
+	}
+
+	return result
+}
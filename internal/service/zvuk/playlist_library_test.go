@@ -0,0 +1,75 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestWriteLibraryPlaylist verifies that the M3U playlist keeps the playlist order,
+// references the album folders relatively, and leaves out tracks that were not saved.
+func TestWriteLibraryPlaylist(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+	playlistDir := filepath.Join(outputPath, "My Playlist")
+	require.NoError(t, os.MkdirAll(playlistDir, 0o755))
+
+	impl := NewService(&config.Config{OutputPath: outputPath}, nil, nil, nil, nil).(*ServiceImpl)
+
+	metadata := &downloadTracksMetadata{
+		keepSavedTracks: true,
+		tracksMetadata: map[string]*zvuk.Track{
+			"1": {ID: 1, Title: "First", Duration: 180, ArtistNames: []string{"Artist A"}},
+			"2": {ID: 2, Title: "Second", Duration: 200, ArtistNames: []string{"Artist B", "Artist C"}},
+			"3": {ID: 3, Title: "Failed", Duration: 210, ArtistNames: []string{"Artist D"}},
+		},
+	}
+
+	metadata.rememberSavedTrack(&downloadTrackTask{
+		trackIDString: "1",
+		trackPath:     filepath.Join(outputPath, "Artist A - Album", "01 - First.flac"),
+	})
+	metadata.rememberSavedTrack(&downloadTrackTask{
+		trackIDString: "2",
+		trackPath:     filepath.Join(outputPath, "Artist B - Album", "05 - Second.flac"),
+	})
+
+	playlistCollection := &audioCollection{
+		category:   DownloadCategoryPlaylist,
+		id:         "100",
+		title:      "My Playlist",
+		trackIDs:   []int64{2, 3, 1, 2},
+		tracksPath: playlistDir,
+	}
+
+	impl.writePlaylistFile(context.Background(), playlistCollection,
+		savedPlaylistFileEntries(playlistCollection.trackIDs, metadata))
+
+	content, err := os.ReadFile(filepath.Join(playlistDir, "My Playlist.m3u8"))
+	require.NoError(t, err)
+
+	expected := "#EXTM3U\n" +
+		"#EXTINF:200,Artist B, Artist C - Second\n" +
+		"../Artist B - Album/05 - Second.flac\n" +
+		"#EXTINF:180,Artist A - First\n" +
+		"../Artist A - Album/01 - First.flac\n" +
+		"#EXTINF:200,Artist B, Artist C - Second\n" +
+		"../Artist B - Album/05 - Second.flac\n"
+	assert.Equal(t, expected, string(content))
+}
+
+// TestUniqueTrackIDs verifies that repeated track IDs are dropped in order.
+func TestUniqueTrackIDs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []int64{3, 1, 2}, uniqueTrackIDs([]int64{3, 1, 3, 2, 1}))
+	assert.Empty(t, uniqueTrackIDs(nil))
+}
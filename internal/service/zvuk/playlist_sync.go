@@ -0,0 +1,472 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// PlaylistSyncState is the content of the sync state file of a playlist: the tracks already fetched from it.
+type PlaylistSyncState struct {
+	// PlaylistID is the unique identifier of the playlist.
+	PlaylistID string `json:"playlist_id"`
+	// Title is the title of the playlist at the last sync.
+	Title string `json:"title,omitempty"`
+	// SyncedAt is when the playlist was last synced.
+	SyncedAt time.Time `json:"synced_at"`
+	// Tracks maps the ID of every fetched track to its file, relative to output_path.
+	Tracks map[string]string `json:"tracks"`
+	// Positions maps the ID of every fetched track to its number in the file names and tags,
+	// so the tracks added later are numbered after them.
+	Positions map[string]int64 `json:"positions,omitempty"`
+}
+
+// playlistSync is a playlist being synced during the run.
+type playlistSync struct {
+	// statePath is the location of the sync state file.
+	statePath string
+	// outputPath is the directory the track paths are relative to.
+	outputPath string
+	// deleteRemoved indicates whether the files of tracks removed from the playlist are deleted.
+	deleteRemoved bool
+	// isStarted indicates that the playlist was fetched, so its state is saved at the end of the run.
+	isStarted bool
+	// state is the sync state, protected by mutex.
+	state *PlaylistSyncState
+	// newPositions are the numbers of the tracks not fetched before, continuing after the fetched ones.
+	newPositions map[int64]int64
+	// numberWidth is the number of digits of the largest track number, the width the numbers are padded to.
+	numberWidth int
+	// mutex protects state while tracks are downloaded concurrently.
+	mutex sync.Mutex
+}
+
+// SyncPlaylists downloads the tracks added to the playlists since their last sync.
+// Tracks fetched before are skipped without requesting their streams, and with deleteRemoved
+// the files of tracks removed from a playlist are deleted.
+func (s *ServiceImpl) SyncPlaylists(ctx context.Context, urls []string, deleteRemoved bool) {
+	items, err := s.urlProcessor.ExtractDownloadItems(ctx, urls)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to extract playlists to sync: %v", err)
+
+		return
+	}
+
+	if len(items.Tracks) > 0 || len(items.Artists) > 0 {
+		logger.Errorf(ctx, "Failed to sync: %v", ErrSyncNotPlaylist)
+
+		return
+	}
+
+	syncs := make(map[string]*playlistSync, len(items.StandaloneItems))
+
+	for _, item := range items.StandaloneItems {
+		// A wave hands out different tracks every time, so there is nothing to sync.
+		if _, isWave := waveStationID(item.ItemID); item.Category != DownloadCategoryPlaylist || isWave {
+			logger.Errorf(ctx, "Failed to sync '%s': %v", item.URL, ErrSyncNotPlaylist)
+
+			return
+		}
+
+		ps, loadErr := s.newPlaylistSync(item.ItemID, deleteRemoved)
+		if loadErr != nil {
+			logger.Errorf(ctx, "Failed to load sync state of playlist %s: %v", item.ItemID, loadErr)
+
+			return
+		}
+
+		syncs[item.ItemID] = ps
+	}
+
+	s.playlistSyncs = syncs
+
+	s.DownloadURLs(ctx, urls)
+}
+
+// newPlaylistSync loads the sync state of the playlist.
+func (s *ServiceImpl) newPlaylistSync(playlistID string, deleteRemoved bool) (*playlistSync, error) {
+	// Wave IDs contain characters that are not allowed in file names on Windows.
+	statePath := filepath.Join(s.cfg.SyncStatePath, utils.SanitizeFilename(playlistID)+".json")
+
+	state, err := loadPlaylistSyncState(statePath, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &playlistSync{
+		statePath:     statePath,
+		outputPath:    s.cfg.OutputPath,
+		deleteRemoved: deleteRemoved,
+		state:         state,
+	}, nil
+}
+
+// startPlaylistSync returns the sync of the playlist (nil when the playlist is not being synced),
+// first handling the tracks removed from the playlist since its last sync.
+// Only the files in the playlist folder are deleted: the files of other folders, such as the album folders
+// of playlist_layout: library, are shared with other collections, so only their playlist entries are dropped.
+func (s *ServiceImpl) startPlaylistSync(ctx context.Context, playlist *audioCollection) *playlistSync {
+	ps := s.playlistSyncs[playlist.id]
+	if ps == nil {
+		return nil
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.isStarted = true
+	ps.state.Title = playlist.title
+
+	// The files of the removed tracks may be kept, so they are numbered before they are deleted.
+	if firstPosition, ok := ps.assignTrackPositions(playlist.trackIDs); ok {
+		logger.Infof(ctx, "New tracks of playlist '%s' are numbered from %d, after the tracks fetched before",
+			playlist.title, firstPosition)
+	}
+
+	// The numbers are saved before the tracks are downloaded, so an interrupted sync does not renumber them.
+	if !s.cfg.DryRun {
+		if err := writeJSONAtomically(ps.statePath, ps.state); err != nil {
+			logger.Warnf(ctx, "Failed to save sync state of playlist %s: %v", ps.state.PlaylistID, err)
+		}
+	}
+
+	removedTrackIDs := ps.removedTrackIDs(playlist.trackIDs)
+
+	logger.Infof(ctx, "Syncing playlist '%s': %d track(s) fetched before, %d removed since the last sync",
+		playlist.title, len(ps.state.Tracks)-len(removedTrackIDs), len(removedTrackIDs))
+
+	if len(removedTrackIDs) == 0 {
+		return ps
+	}
+
+	if !ps.deleteRemoved {
+		logger.Infof(ctx, "Files of the removed tracks are kept, pass --delete-removed to delete them")
+
+		return ps
+	}
+
+	for _, trackID := range removedTrackIDs {
+		trackPath := ps.absolutePath(ps.state.Tracks[trackID])
+
+		if !isPlaylistOwnedFile(s.cfg, playlist, trackPath) {
+			if s.cfg.DryRun {
+				logger.Infof(ctx, "[DRY-RUN] Would drop '%s' removed from the playlist, keeping the file "+
+					"outside the playlist folder", trackPath)
+
+				continue
+			}
+
+			logger.Infof(ctx, "Dropped '%s' removed from the playlist, the file outside the playlist folder is kept",
+				trackPath)
+			delete(ps.state.Tracks, trackID)
+			delete(ps.state.Positions, trackID)
+
+			continue
+		}
+
+		if s.cfg.DryRun {
+			logger.Infof(ctx, "[DRY-RUN] Would delete '%s' removed from the playlist", trackPath)
+
+			continue
+		}
+
+		if err := os.Remove(trackPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf(ctx, "Failed to delete '%s' removed from the playlist: %v", trackPath, err)
+
+			continue
+		}
+
+		logger.Infof(ctx, "Deleted '%s' removed from the playlist", trackPath)
+		delete(ps.state.Tracks, trackID)
+		delete(ps.state.Positions, trackID)
+	}
+
+	return ps
+}
+
+// isPlaylistOwnedFile reports whether a track file belongs to the playlist alone,
+// i.e., it is in the own folder of a playlist saved with the default layout.
+func isPlaylistOwnedFile(cfg *config.Config, playlist *audioCollection, trackPath string) bool {
+	return playlist.hasOwnFolder &&
+		cfg.PlaylistLayout != config.PlaylistLayoutLibrary &&
+		isWithinFolder(trackPath, playlist.tracksPath)
+}
+
+// skipSyncedTrack reports whether the track was fetched by an earlier sync of the playlist,
+// recording it as skipped and remembering its file for the playlist file and repeated tracks.
+func (s *ServiceImpl) skipSyncedTrack(ctx context.Context, trackID int64, metadata *downloadTracksMetadata) bool {
+	if metadata.playlistSync == nil {
+		return false
+	}
+
+	trackIDString := strconv.FormatInt(trackID, 10)
+
+	trackPath, ok := metadata.playlistSync.syncedTrackPath(trackIDString)
+	if !ok {
+		return false
+	}
+
+	title := "Track ID: " + trackIDString
+	if track := metadata.tracksMetadata[trackIDString]; track != nil {
+		title = track.Title
+	}
+
+	logger.Debugf(ctx, "Track '%s' was fetched by an earlier sync, skipping", title)
+
+	metadata.rememberSavedTrackPath(trackIDString, trackPath, qualityFromPath(trackPath))
+
+	s.incrementTrackSkipped(trackIDString, SkipReasonSynced)
+	s.recordSkippedItem(&SkippedItem{
+		TrackID:        trackIDString,
+		Title:          title,
+		ParentCategory: DownloadCategoryPlaylist,
+		Reason:         SkipReasonSynced,
+	})
+
+	return true
+}
+
+// savePlaylistSyncStates writes the sync state of every playlist fetched during the run.
+func (s *ServiceImpl) savePlaylistSyncStates(ctx context.Context) {
+	if s.cfg.DryRun {
+		return
+	}
+
+	for _, ps := range s.playlistSyncs {
+		ps.mutex.Lock()
+
+		if !ps.isStarted {
+			ps.mutex.Unlock()
+
+			continue
+		}
+
+		ps.state.SyncedAt = time.Now()
+		err := writeJSONAtomically(ps.statePath, ps.state)
+
+		ps.mutex.Unlock()
+
+		if err != nil {
+			logger.Warnf(ctx, "Failed to save sync state of playlist %s: %v", ps.state.PlaylistID, err)
+
+			continue
+		}
+
+		logger.Infof(ctx, "Sync state of playlist '%s' is saved to '%s'", ps.state.Title, ps.statePath)
+	}
+}
+
+// markSynced records the file and the number of a track fetched from the playlist.
+func (ps *playlistSync) markSynced(trackID, trackPath string, position int64) {
+	if ps == nil {
+		return
+	}
+
+	if relativePath, err := filepath.Rel(ps.outputPath, trackPath); err == nil {
+		trackPath = relativePath
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.state.Tracks[trackID] = filepath.ToSlash(trackPath)
+
+	if position <= 0 {
+		return
+	}
+
+	if ps.state.Positions == nil {
+		ps.state.Positions = make(map[string]int64)
+	}
+
+	ps.state.Positions[trackID] = position
+}
+
+// assignTrackPositions numbers the tracks not fetched before in the playlist order, after the fetched ones,
+// so the files of an appended sync slot in after the existing ones, wherever the new tracks are in the playlist.
+// The first sync keeps the playlist positions. The tracks of a state saved by an earlier version have no numbers,
+// so they are counted instead. The numbers are recorded in the state at once, so a track whose download
+// is interrupted keeps its number in the next sync. It returns the first new number,
+// and false if there is nothing new to number after the fetched tracks.
+func (ps *playlistSync) assignTrackPositions(trackIDs []int64) (int64, bool) {
+	listedTrackIDs := make(map[string]struct{}, len(trackIDs))
+	for _, trackID := range trackIDs {
+		listedTrackIDs[strconv.FormatInt(trackID, 10)] = struct{}{}
+	}
+
+	// The numbers of tracks removed from the playlist before they were fetched are released.
+	for trackID := range ps.state.Positions {
+		_, isFetched := ps.state.Tracks[trackID]
+		if _, isListed := listedTrackIDs[trackID]; !isFetched && !isListed {
+			delete(ps.state.Positions, trackID)
+		}
+	}
+
+	if ps.state.Positions == nil {
+		ps.state.Positions = make(map[string]int64)
+	}
+
+	isFirstSync := len(ps.state.Tracks) == 0 && len(ps.state.Positions) == 0
+
+	lastPosition := int64(len(ps.state.Tracks))
+	for _, position := range ps.state.Positions {
+		lastPosition = max(lastPosition, position)
+	}
+
+	var (
+		firstPosition int64
+		seen          = make(map[int64]struct{}, len(trackIDs))
+	)
+
+	ps.newPositions = make(map[int64]int64)
+
+	for index, trackID := range trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+		if _, ok := ps.state.Tracks[trackIDString]; ok {
+			continue
+		}
+
+		// A repeated track shares the number of its first occurrence.
+		if _, ok := seen[trackID]; ok {
+			continue
+		}
+
+		seen[trackID] = struct{}{}
+
+		switch position, ok := ps.state.Positions[trackIDString]; {
+		case ok:
+			// A track numbered by an interrupted sync keeps its number.
+			ps.newPositions[trackID] = position
+		case isFirstSync:
+			// The first sync numbers the tracks by their playlist positions, as a download does.
+			ps.state.Positions[trackIDString] = int64(index) + 1
+			lastPosition = max(lastPosition, int64(index)+1)
+		default:
+			lastPosition++
+			ps.newPositions[trackID] = lastPosition
+			ps.state.Positions[trackIDString] = lastPosition
+
+			if firstPosition == 0 {
+				firstPosition = lastPosition
+			}
+		}
+	}
+
+	ps.numberWidth = max(trackNumberPaddingWidth, len(strconv.FormatInt(lastPosition, 10)))
+
+	return firstPosition, firstPosition > 0
+}
+
+// trackPosition returns the number assigned to a track not fetched before by assignTrackPositions.
+func (ps *playlistSync) trackPosition(trackID int64) (int64, bool) {
+	if ps == nil {
+		return 0, false
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	position, ok := ps.newPositions[trackID]
+
+	return position, ok
+}
+
+// trackNumberWidth returns the width the track numbers of the playlist are padded to,
+// so the files of the tracks numbered after the fetched ones sort after them (0 keeps the default width).
+func (ps *playlistSync) trackNumberWidth() int {
+	if ps == nil {
+		return 0
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	return ps.numberWidth
+}
+
+// syncedTrackPath returns the file of a track fetched by an earlier sync.
+func (ps *playlistSync) syncedTrackPath(trackID string) (string, bool) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	trackPath, ok := ps.state.Tracks[trackID]
+
+	return ps.absolutePath(trackPath), ok
+}
+
+// removedTrackIDs returns the fetched tracks that are no longer in the playlist, sorted.
+func (ps *playlistSync) removedTrackIDs(trackIDs []int64) []string {
+	current := make(map[string]struct{}, len(trackIDs))
+	for _, trackID := range trackIDs {
+		current[strconv.FormatInt(trackID, 10)] = struct{}{}
+	}
+
+	var result []string
+
+	for trackID := range ps.state.Tracks {
+		if _, ok := current[trackID]; !ok {
+			result = append(result, trackID)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// absolutePath resolves a track path saved in the state against the output path.
+func (ps *playlistSync) absolutePath(trackPath string) string {
+	trackPath = filepath.FromSlash(trackPath)
+	if filepath.IsAbs(trackPath) {
+		return trackPath
+	}
+
+	return filepath.Join(ps.outputPath, trackPath)
+}
+
+// qualityFromPath guesses the quality of a saved track from its file extension.
+func qualityFromPath(trackPath string) TrackQuality {
+	if filepath.Ext(trackPath) == extensionFLAC {
+		return TrackQualityFLAC
+	}
+
+	return TrackQualityMP3High
+}
+
+// loadPlaylistSyncState reads the sync state file of a playlist. A missing file is an empty state.
+func loadPlaylistSyncState(path, playlistID string) (*PlaylistSyncState, error) {
+	state := &PlaylistSyncState{
+		PlaylistID: playlistID,
+		Tracks:     make(map[string]string),
+	}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state '%s': %w", path, err)
+	}
+
+	if state.Tracks == nil {
+		state.Tracks = make(map[string]string)
+	}
+
+	return state, nil
+}
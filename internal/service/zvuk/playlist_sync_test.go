@@ -0,0 +1,283 @@
+package zvuk
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestStartPlaylistSync tests how the tracks removed from a synced playlist are handled.
+func TestStartPlaylistSync(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		deleteRemoved  bool
+		dryRun         bool
+		playlistLayout string
+		removedPath    string
+		expectedTracks []string
+		expectDeleted  bool
+	}{
+		{
+			name:           "removed tracks are kept by default",
+			expectedTracks: []string{"1", "2"},
+		},
+		{
+			name:           "removed tracks are deleted with delete-removed",
+			deleteRemoved:  true,
+			expectedTracks: []string{"1"},
+			expectDeleted:  true,
+		},
+		{
+			name:           "removed tracks are not deleted in dry-run mode",
+			deleteRemoved:  true,
+			dryRun:         true,
+			expectedTracks: []string{"1", "2"},
+		},
+		{
+			name:           "album files of the library layout are kept",
+			deleteRemoved:  true,
+			playlistLayout: config.PlaylistLayoutLibrary,
+			removedPath:    "Artist/Album/removed.mp3",
+			expectedTracks: []string{"1"},
+		},
+		{
+			name:           "files outside the playlist folder are kept",
+			deleteRemoved:  true,
+			removedPath:    "Artist/Album/removed.mp3",
+			expectedTracks: []string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputPath := t.TempDir()
+
+			removedPath := tt.removedPath
+			if removedPath == "" {
+				removedPath = "Mix/removed.mp3"
+			}
+
+			for _, name := range []string{"Mix/kept.flac", removedPath} {
+				filePath := filepath.Join(outputPath, filepath.FromSlash(name))
+				require.NoError(t, os.MkdirAll(filepath.Dir(filePath), defaultFolderPermissions))
+				require.NoError(t, os.WriteFile(filePath, []byte("audio"), constants.DefaultFilePermissions))
+			}
+
+			cfg := &config.Config{OutputPath: outputPath, DryRun: tt.dryRun, PlaylistLayout: tt.playlistLayout}
+			impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+			require.True(t, ok, "service must be of type *ServiceImpl")
+
+			impl.playlistSyncs = map[string]*playlistSync{
+				"100": {
+					outputPath:    outputPath,
+					deleteRemoved: tt.deleteRemoved,
+					state: &PlaylistSyncState{
+						PlaylistID: "100",
+						Tracks:     map[string]string{"1": "Mix/kept.flac", "2": removedPath},
+					},
+				},
+			}
+
+			ps := impl.startPlaylistSync(context.Background(), &audioCollection{
+				id:           "100",
+				title:        "Mix",
+				trackIDs:     []int64{1, 3},
+				tracksPath:   filepath.Join(outputPath, "Mix"),
+				hasOwnFolder: true,
+			})
+			require.NotNil(t, ps)
+
+			assert.True(t, ps.isStarted)
+			assert.Equal(t, "Mix", ps.state.Title)
+			assert.ElementsMatch(t, tt.expectedTracks, slices.Collect(maps.Keys(ps.state.Tracks)))
+			assert.FileExists(t, filepath.Join(outputPath, "Mix", "kept.flac"))
+
+			if tt.expectDeleted {
+				assert.NoFileExists(t, filepath.Join(outputPath, filepath.FromSlash(removedPath)))
+			} else {
+				assert.FileExists(t, filepath.Join(outputPath, filepath.FromSlash(removedPath)))
+			}
+
+			// Playlists outside the sync are not tracked.
+			assert.Nil(t, impl.startPlaylistSync(context.Background(), &audioCollection{id: "200"}))
+		})
+	}
+}
+
+// TestPlaylistSync_SkipsSyncedTracks tests that fetched tracks are skipped and new ones are recorded and saved.
+func TestPlaylistSync_SkipsSyncedTracks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath = t.TempDir()
+		statePath  = filepath.Join(t.TempDir(), "sync", "100.json")
+		cfg        = &config.Config{OutputPath: outputPath}
+	)
+
+	impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	state, err := loadPlaylistSyncState(statePath, "100")
+	require.NoError(t, err)
+
+	state.Tracks["1"] = "Mix/01 - Old.flac"
+
+	ps := &playlistSync{
+		statePath:  statePath,
+		outputPath: outputPath,
+		state:      state,
+	}
+	impl.playlistSyncs = map[string]*playlistSync{"100": ps}
+
+	metadata := &downloadTracksMetadata{
+		category:        DownloadCategoryPlaylist,
+		tracksMetadata:  map[string]*zvuk.Track{"1": {ID: 1, Title: "Old"}},
+		keepSavedTracks: true,
+		playlistSync:    ps,
+	}
+
+	// A track fetched before is skipped and remembered for the playlist file.
+	require.True(t, impl.skipSyncedTrack(context.Background(), 1, metadata))
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkippedSynced)
+
+	saved := metadata.getSavedTrack("1")
+	require.NotNil(t, saved)
+	assert.Equal(t, filepath.Join(outputPath, "Mix", "01 - Old.flac"), saved.path)
+	assert.Equal(t, TrackQualityFLAC, saved.quality)
+
+	// A new track is downloaded and recorded once saved.
+	require.False(t, impl.skipSyncedTrack(context.Background(), 2, metadata))
+
+	metadata.rememberSavedTrack(&downloadTrackTask{
+		trackIDString: "2",
+		trackPath:     filepath.Join(outputPath, "Mix", "02 - New.mp3"),
+		quality:       TrackQualityMP3High,
+	})
+
+	// Only playlists fetched during the run are saved.
+	impl.savePlaylistSyncStates(context.Background())
+	assert.NoFileExists(t, statePath)
+
+	ps.isStarted = true
+	impl.savePlaylistSyncStates(context.Background())
+
+	loaded, err := loadPlaylistSyncState(statePath, "100")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"1": "Mix/01 - Old.flac", "2": "Mix/02 - New.mp3"}, loaded.Tracks)
+	assert.False(t, loaded.SyncedAt.IsZero())
+}
+
+// TestPlaylistSync_NumbersNewTracksAfterFetchedOnes tests that an appended sync continues the numbering
+// of the earlier syncs instead of using the playlist positions.
+func TestPlaylistSync_NumbersNewTracksAfterFetchedOnes(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+
+	// The first sync keeps the playlist positions.
+	ps := &playlistSync{
+		outputPath: outputPath,
+		state:      &PlaylistSyncState{PlaylistID: "100", Tracks: map[string]string{}},
+	}
+
+	_, ok := ps.assignTrackPositions([]int64{10, 20})
+	assert.False(t, ok)
+
+	task := &downloadTrackTask{
+		trackIndex: 2,
+		trackID:    20,
+		track:      &zvuk.Track{ID: 20},
+		metadata:   &downloadTracksMetadata{category: DownloadCategoryPlaylist, playlistSync: ps},
+	}
+	assert.Equal(t, int64(2), resolveTrackPosition(task))
+
+	ps.markSynced("10", filepath.Join(outputPath, "Mix", "01 - A.mp3"), 1)
+	ps.markSynced("20", filepath.Join(outputPath, "Mix", "02 - B.mp3"), 2)
+	assert.Equal(t, map[string]int64{"10": 1, "20": 2}, ps.state.Positions)
+
+	// New tracks are added to the top and the middle of the playlist, and one of them is repeated.
+	firstPosition, ok := ps.assignTrackPositions([]int64{30, 10, 40, 20, 30})
+	require.True(t, ok)
+	assert.Equal(t, int64(3), firstPosition)
+
+	for _, tt := range []struct {
+		trackID    int64
+		trackIndex int64
+		expected   int64
+	}{
+		{trackID: 30, trackIndex: 1, expected: 3},
+		{trackID: 40, trackIndex: 3, expected: 4},
+		{trackID: 30, trackIndex: 5, expected: 3},
+	} {
+		task.trackID, task.trackIndex = tt.trackID, tt.trackIndex
+		assert.Equal(t, tt.expected, resolveTrackPosition(task), "track %d", tt.trackID)
+	}
+
+	// A state saved by an earlier version has no numbers, so its tracks are counted.
+	legacy := &playlistSync{
+		outputPath: outputPath,
+		state: &PlaylistSyncState{
+			PlaylistID: "200",
+			Tracks:     map[string]string{"1": "Old/01.mp3", "2": "Old/02.mp3", "3": "Old/03.mp3"},
+		},
+	}
+
+	firstPosition, ok = legacy.assignTrackPositions([]int64{4, 1, 2, 3})
+	require.True(t, ok)
+	assert.Equal(t, int64(4), firstPosition)
+}
+
+// TestPlaylistSync_KeepsNumbersOfInterruptedSync tests that the numbers are recorded before the tracks are fetched,
+// so an interrupted sync keeps them, and that the numbers are padded to the width of the largest one.
+func TestPlaylistSync_KeepsNumbersOfInterruptedSync(t *testing.T) {
+	t.Parallel()
+
+	ps := &playlistSync{
+		outputPath: t.TempDir(),
+		state: &PlaylistSyncState{
+			PlaylistID: "100",
+			Tracks:     map[string]string{"1": "Mix/98.mp3", "2": "Mix/99.mp3"},
+			Positions:  map[string]int64{"1": 98, "2": 99},
+		},
+	}
+
+	firstPosition, ok := ps.assignTrackPositions([]int64{1, 2, 3, 4})
+	require.True(t, ok)
+	assert.Equal(t, int64(100), firstPosition)
+	assert.Equal(t, map[string]int64{"1": 98, "2": 99, "3": 100, "4": 101}, ps.state.Positions)
+	assert.Equal(t, 3, ps.trackNumberWidth())
+
+	// Track 3 was not fetched before the run was interrupted, and track 4 was removed from the playlist since.
+	ps.markSynced("4", filepath.Join(ps.outputPath, "Mix", "101.mp3"), 101)
+
+	_, ok = ps.assignTrackPositions([]int64{5, 1, 2, 3})
+	require.True(t, ok)
+
+	position, ok := ps.trackPosition(3)
+	require.True(t, ok)
+	assert.Equal(t, int64(100), position, "an interrupted track keeps its number")
+
+	position, ok = ps.trackPosition(5)
+	require.True(t, ok)
+	assert.Equal(t, int64(102), position)
+
+	// A track removed before it was fetched releases its number.
+	_, ok = ps.assignTrackPositions([]int64{1, 2})
+	assert.False(t, ok)
+	assert.NotContains(t, ps.state.Positions, "3")
+	assert.NotContains(t, ps.state.Positions, "5")
+}
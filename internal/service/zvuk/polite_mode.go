@@ -0,0 +1,57 @@
+package zvuk
+
+import (
+	"context"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// isPoliteHours reports whether the polite mode is on now (polite_hours),
+// and logs every switch between the polite and the usual settings.
+func (s *ServiceImpl) isPoliteHours(ctx context.Context) bool {
+	windows := s.cfg.ParsedPoliteHours
+	if len(windows) == 0 {
+		return false
+	}
+
+	isPolite := config.IsInDownloadWindows(windows, time.Now())
+	if s.isPoliteMode.Swap(isPolite) == isPolite {
+		return isPolite
+	}
+
+	if isPolite {
+		logger.Infof(ctx, "Peak hours (%s): pauses are %d times longer, up to %d track(s) at once",
+			s.cfg.PoliteHours, s.cfg.PolitePauseMultiplier, s.cfg.PoliteMaxConcurrentDownloads)
+	} else {
+		logger.Info(ctx, "Peak hours are over, back to the usual pauses and concurrency")
+	}
+
+	return isPolite
+}
+
+// maxDownloadPause returns the longest random pause after a track, longer during polite_hours.
+func (s *ServiceImpl) maxDownloadPause(ctx context.Context) time.Duration {
+	if s.isPoliteHours(ctx) {
+		return s.cfg.ParsedMaxDownloadPause * time.Duration(s.cfg.PolitePauseMultiplier)
+	}
+
+	return s.cfg.ParsedMaxDownloadPause
+}
+
+// acquirePoliteSlot waits for one of the polite_max_concurrent_downloads slots during polite_hours,
+// so fewer tracks are downloaded at once. Outside of them, it returns at once.
+// It returns the function releasing the slot, and false if the context is canceled while waiting.
+func (s *ServiceImpl) acquirePoliteSlot(ctx context.Context) (func(), bool) {
+	if s.politeSlots == nil || !s.isPoliteHours(ctx) {
+		return func() {}, true
+	}
+
+	select {
+	case s.politeSlots <- struct{}{}:
+		return func() { <-s.politeSlots }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
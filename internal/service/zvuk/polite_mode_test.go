@@ -0,0 +1,82 @@
+package zvuk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// newTestPoliteService returns a service in polite mode all day long, or never when isPolite is false.
+func newTestPoliteService(t *testing.T, isPolite bool) *ServiceImpl {
+	t.Helper()
+
+	// A window of a minute twelve hours away is never reached by the test.
+	politeHours := time.Now().Add(12*time.Hour).Format("15:04") + "-" +
+		time.Now().Add(12*time.Hour+time.Minute).Format("15:04")
+	if isPolite {
+		politeHours = "00:00-24:00"
+	}
+
+	cfg := &config.Config{
+		PoliteHours:                  politeHours,
+		PoliteMaxConcurrentDownloads: 1,
+		PolitePauseMultiplier:        4,
+		ParsedMaxDownloadPause:       2 * time.Second,
+	}
+
+	var err error
+
+	cfg.ParsedPoliteHours, err = config.ParseDownloadWindows(cfg.PoliteHours)
+	require.NoError(t, err)
+
+	impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	return impl
+}
+
+// TestPoliteMode tests that the pauses are longer and the tracks wait for a slot during polite_hours.
+func TestPoliteMode(t *testing.T) {
+	t.Parallel()
+
+	impl := newTestPoliteService(t, true)
+	ctx := context.Background()
+
+	assert.Equal(t, 8*time.Second, impl.maxDownloadPause(ctx))
+
+	release, ok := impl.acquirePoliteSlot(ctx)
+	require.True(t, ok)
+
+	// The only slot is taken, so the next track waits until it is released or the run is canceled.
+	canceledCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, ok = impl.acquirePoliteSlot(canceledCtx)
+	assert.False(t, ok)
+
+	release()
+
+	release, ok = impl.acquirePoliteSlot(ctx)
+	require.True(t, ok)
+	release()
+}
+
+// TestPoliteMode_OutsideHours tests that the usual settings apply outside polite_hours.
+func TestPoliteMode_OutsideHours(t *testing.T) {
+	t.Parallel()
+
+	impl := newTestPoliteService(t, false)
+	ctx := context.Background()
+
+	assert.Equal(t, 2*time.Second, impl.maxDownloadPause(ctx))
+
+	for range 3 {
+		_, ok := impl.acquirePoliteSlot(ctx)
+		require.True(t, ok, "slots must not be limited outside polite_hours")
+	}
+}
@@ -0,0 +1,201 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// Transcoder encodes downloaded tracks into the portable output format.
+type Transcoder interface {
+	// Transcode writes a transcoded copy of inputPath to outputPath.
+	Transcode(ctx context.Context, inputPath, outputPath string) error
+	// Extension returns the file extension of transcoded files (e.g., ".mp3").
+	Extension() string
+}
+
+// FFmpegTranscoder transcodes audio with ffmpeg.
+type FFmpegTranscoder struct {
+	// ffmpegPath is the path to the ffmpeg executable.
+	ffmpegPath string
+	// format is the output format ("mp3" or "opus").
+	format string
+	// bitrate is the output audio bitrate (e.g., "320k").
+	bitrate string
+}
+
+// NewTranscoder creates a portable copy transcoder backed by ffmpeg.
+func NewTranscoder(cfg *config.Config) Transcoder {
+	return &FFmpegTranscoder{
+		ffmpegPath: cfg.FFmpegPath,
+		format:     cfg.PortableFormat,
+		bitrate:    cfg.PortableBitrate,
+	}
+}
+
+// Extension returns the file extension of transcoded files.
+func (t *FFmpegTranscoder) Extension() string {
+	if t.format == config.PortableFormatOpus {
+		return ".opus"
+	}
+
+	return ".mp3"
+}
+
+// Transcode encodes inputPath to outputPath, keeping the tags.
+// MP3 copies also keep the embedded cover; the Ogg container cannot carry it as a video stream.
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, inputPath, outputPath string) error {
+	tempPath := outputPath + ffmpegTempSuffix
+
+	args := []string{
+		"-hide_banner", "-nostats", "-y",
+		"-i", inputPath,
+		"-map_metadata", "0",
+	}
+
+	switch t.format {
+	case config.PortableFormatOpus:
+		args = append(args, "-map", "0:a", "-c:a", "libopus", "-b:a", t.bitrate, "-f", "ogg")
+	default:
+		args = append(args,
+			"-map", "0", "-c:v", "copy",
+			"-c:a", "libmp3lame", "-b:a", t.bitrate, "-id3v2_version", "3", "-f", "mp3")
+	}
+
+	args = append(args, tempPath)
+
+	if _, err := runFFmpeg(ctx, t.ffmpegPath, args); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to transcode portable copy: %w", err)
+	}
+
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to rename portable copy: %w", err)
+	}
+
+	return nil
+}
+
+// newPortableTemplateManager creates a template manager where the portable templates
+// replace the main ones; empty portable templates fall back to the main templates.
+func newPortableTemplateManager(cfg *config.Config) TemplateManager {
+	portableCfg := *cfg
+
+	if cfg.PortableTrackFilenameTemplate != "" {
+		portableCfg.TrackFilenameTemplate = cfg.PortableTrackFilenameTemplate
+	}
+
+	if cfg.PortableAlbumFolderTemplate != "" {
+		portableCfg.AlbumFolderTemplate = cfg.PortableAlbumFolderTemplate
+	}
+
+	if cfg.PortablePlaylistFilenameTemplate != "" {
+		portableCfg.PlaylistFilenameTemplate = cfg.PortablePlaylistFilenameTemplate
+	}
+
+	return NewTemplateManager(context.Background(), &portableCfg)
+}
+
+// checkTranscoder verifies that ffmpeg is available before any download starts.
+func (s *ServiceImpl) checkTranscoder() error {
+	if s.transcoder == nil || s.cfg.DryRun {
+		return nil
+	}
+
+	if _, err := exec.LookPath(s.cfg.FFmpegPath); err != nil {
+		return fmt.Errorf("ffmpeg is required for portable copies: %w", err)
+	}
+
+	return nil
+}
+
+// createPortableCopy transcodes the downloaded track into portable_output_path.
+// The copy is encoded from the file that was just saved, so every track is fetched only once.
+func (s *ServiceImpl) createPortableCopy(ctx context.Context, t *downloadTrackTask, trackTags map[string]string) {
+	if s.transcoder == nil || s.cfg.DryRun {
+		return
+	}
+
+	portablePath := filepath.Join(s.cfg.PortableOutputPath, s.getPortableRelativePath(ctx, t, trackTags))
+
+	if !s.cfg.ReplaceTracks {
+		if _, statErr := os.Stat(portablePath); statErr == nil {
+			logger.Infof(ctx, "Portable copy '%s' already exists, skipping", portablePath)
+
+			return
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(portablePath), constants.DefaultFolderPermissions)
+	if err == nil {
+		err = s.transcoder.Transcode(ctx, t.trackPath, portablePath)
+	}
+
+	if err != nil {
+		s.handleError(ctx, &DownloadError{
+			Category:       DownloadCategoryTrack,
+			ItemID:         t.trackIDString,
+			ItemTitle:      t.track.Title,
+			ParentCategory: t.metadata.category,
+			ParentID:       t.parentID,
+			ParentTitle:    t.parentTitle,
+			Phase:          "creating portable copy",
+			Error:          err,
+		}, false)
+
+		return
+	}
+
+	s.incrementTrackTranscoded()
+	logger.Infof(ctx, "Portable copy saved to: %s", portablePath)
+}
+
+// getPortableRelativePath returns the path of the portable copy relative to portable_output_path.
+// Music is laid out with the portable templates, audiobooks and podcasts mirror output_path.
+func (s *ServiceImpl) getPortableRelativePath(
+	ctx context.Context,
+	t *downloadTrackTask,
+	trackTags map[string]string,
+) string {
+	extension := s.transcoder.Extension()
+
+	relativeDir, err := filepath.Rel(s.cfg.OutputPath, filepath.Dir(t.trackPath))
+	if err != nil || strings.HasPrefix(relativeDir, "..") {
+		relativeDir = ""
+	}
+
+	category := t.metadata.category
+	if category == DownloadCategoryAudiobook || category == DownloadCategoryPodcast || t.audioCollection == nil {
+		return filepath.Join(relativeDir, utils.SetFileExtension(filepath.Base(t.trackPath), extension, true))
+	}
+
+	// Albums get their own folder unless the track was saved directly into output_path (singles, playlists).
+	if relativeDir != "" && relativeDir != "." && t.audioCollection.category == DownloadCategoryAlbum {
+		rawFolderName := s.portableTemplateManager.GetAlbumFolderName(ctx, t.audioCollection.tags)
+		relativeDir = s.groupFolderByArtistInitial(
+			DownloadCategoryAlbum,
+			t.audioCollection.tags,
+			s.getFolderNameAfterTemplateExecution(ctx, DownloadCategoryAlbum, rawFolderName),
+		)
+	}
+
+	filename := s.portableTemplateManager.GetTrackFilename(
+		ctx,
+		category == DownloadCategoryPlaylist,
+		trackTags,
+		t.audioCollection.tracksCount,
+	)
+
+	return filepath.Join(relativeDir, utils.SetFileExtension(utils.SanitizeFilename(filename), extension, false))
+}
@@ -0,0 +1,106 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// fakeTranscoder copies the input file instead of running ffmpeg.
+type fakeTranscoder struct {
+	calls int
+}
+
+// Transcode copies the original so tests can check where the portable copy is written.
+func (f *fakeTranscoder) Transcode(_ context.Context, inputPath, outputPath string) error {
+	f.calls++
+
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputPath, content, constants.DefaultFilePermissions)
+}
+
+// Extension returns the extension of the fake portable format.
+func (f *fakeTranscoder) Extension() string {
+	return ".mp3"
+}
+
+// TestCreatePortableCopy tests that portable copies are laid out with the portable templates.
+func TestCreatePortableCopy(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+	portableOutputPath := t.TempDir()
+
+	cfg := &config.Config{
+		OutputPath:                    outputPath,
+		PortableOutputPath:            portableOutputPath,
+		PortableFormat:                config.PortableFormatMP3,
+		TrackFilenameTemplate:         "{{.trackNumberPad}} - {{.trackTitle}}",
+		AlbumFolderTemplate:           "{{.releaseYear}} - {{.albumArtist}} - {{.albumTitle}}",
+		PortableTrackFilenameTemplate: "{{.trackArtist}} - {{.trackTitle}}",
+		PortableAlbumFolderTemplate:   "{{.albumArtist}}/{{.albumTitle}}",
+		ParsedLogLevel:                logger.Level(),
+	}
+
+	service, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok)
+
+	transcoder := new(fakeTranscoder)
+	service.transcoder = transcoder
+
+	trackPath := filepath.Join(outputPath, "2024 - Artist - Album", "01 - Track.flac")
+	require.NoError(t, os.MkdirAll(filepath.Dir(trackPath), constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(trackPath, []byte("audio"), constants.DefaultFilePermissions))
+
+	collection := &audioCollection{
+		category:    DownloadCategoryAlbum,
+		title:       "Album",
+		tags:        map[string]string{TagAlbumArtist: "Artist", TagAlbumTitle: "Album", TagReleaseYear: "2024"},
+		tracksCount: 2,
+	}
+
+	task := &downloadTrackTask{
+		trackPath:       trackPath,
+		trackIDString:   "1",
+		track:           &zvuk.Track{ID: 1, Title: "Track"},
+		audioCollection: collection,
+		metadata:        &downloadTracksMetadata{category: DownloadCategoryAlbum},
+	}
+
+	trackTags := map[string]string{TagTrackArtist: "Artist", TagTrackTitle: "Track", TagTrackNumberPad: "01"}
+
+	ctx := context.Background()
+	service.createPortableCopy(ctx, task, trackTags)
+
+	portableContent, err := os.ReadFile(filepath.Join(portableOutputPath, "Artist", "Album", "Artist - Track.mp3"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("audio"), portableContent)
+	assert.Equal(t, int64(1), service.Statistics().TracksTranscoded)
+
+	// An existing copy is kept unless replace_tracks is enabled.
+	service.createPortableCopy(ctx, task, trackTags)
+	assert.Equal(t, 1, transcoder.calls)
+	assert.Empty(t, service.Statistics().Errors)
+
+	// Audiobook chapters keep the archive layout and only change the extension.
+	chapterPath := filepath.Join(outputPath, "Audiobook", "01 - Chapter.mp3")
+	task.trackPath = chapterPath
+	task.metadata = &downloadTracksMetadata{category: DownloadCategoryAudiobook}
+
+	assert.Equal(t,
+		filepath.Join("Audiobook", "01 - Chapter.mp3"),
+		service.getPortableRelativePath(ctx, task, trackTags))
+}
@@ -12,6 +12,7 @@ import (
 // QualityResolutionResult contains the result of quality resolution.
 type QualityResolutionResult struct {
 	// Quality is the final quality determined for the track.
+	// When the track is skipped, it is the best quality the track is available in.
 	Quality TrackQuality
 	// StreamURL is the URL to stream/download the track.
 	StreamURL string
@@ -73,6 +74,7 @@ func (r *trackQualityResolver) ResolveQuality(
 			finalQuality, minQuality)
 
 		return &QualityResolutionResult{
+			Quality:    finalQuality,
 			ShouldSkip: true,
 			SkipReason: fmt.Errorf("%w: %s below %s",
 				ErrQualityBelowThreshold, finalQuality, minQuality),
@@ -136,6 +138,7 @@ func (r *audiobookQualityResolver) ResolveQuality(
 			highestAvailable, minQuality)
 
 		return &QualityResolutionResult{
+			Quality:    highestAvailable,
 			ShouldSkip: true,
 			SkipReason: fmt.Errorf("%w: %s below %s",
 				ErrQualityBelowThreshold, highestAvailable, minQuality),
@@ -265,11 +268,11 @@ func (s *ServiceImpl) resolveTrackQuality(
 	trackID string,
 	track *zvuk.Track,
 	metadata *downloadTracksMetadata,
+	desiredQuality TrackQuality,
 ) (*QualityResolutionResult, error) {
 	var (
-		desiredQuality = TrackQuality(s.cfg.Quality)
-		minQuality     = TrackQuality(s.cfg.MinQuality)
-		resolver       = createQualityResolver(metadata.category, s.zvukClient, metadata.chapterStreamsMetadata)
+		minQuality = TrackQuality(s.cfg.MinQuality)
+		resolver   = createQualityResolver(metadata.category, s.zvukClient, metadata.chapterStreamsMetadata)
 	)
 
 	result, err := resolver.ResolveQuality(ctx, trackID, track, desiredQuality, minQuality)
@@ -284,3 +287,45 @@ func (s *ServiceImpl) resolveTrackQuality(
 
 	return result, nil
 }
+
+// lowerQualityCap lowers the quality the track is resolved in after a stream error, if quality_fallback allows it.
+// It returns false if the track cannot fall back: the option is disabled, the run is canceled,
+// the error does not mean the quality is unavailable, or the next lower quality is below min_quality.
+func (s *ServiceImpl) lowerQualityCap(
+	ctx context.Context,
+	t *downloadTrackTask,
+	failedQuality TrackQuality,
+	err error,
+) bool {
+	if !s.cfg.QualityFallback || ctx.Err() != nil || !isQualityUnavailable(err) {
+		return false
+	}
+
+	// The cap only goes down, even if a lower cap was answered with the failed quality again.
+	if t.qualityCap > 0 {
+		failedQuality = min(failedQuality, t.qualityCap)
+	}
+
+	if failedQuality <= max(TrackQuality(s.cfg.MinQuality), TrackQualityMP3Mid) {
+		return false
+	}
+
+	lowerQuality := failedQuality - 1
+
+	logger.Warnf(ctx, "Track '%s' failed in %s, falling back to %s: %v", t.track.Title, failedQuality, lowerQuality, err)
+
+	t.qualityCap = lowerQuality
+
+	return true
+}
+
+// isQualityUnavailable reports whether the stream error means Zvuk does not serve the track in the quality:
+// no stream was returned, or the stream metadata or the stream itself was refused with an HTTP status.
+// Network errors, cancellation, and rejected tokens would fail in a lower quality as well.
+func isQualityUnavailable(err error) bool {
+	if errors.Is(err, zvuk.ErrNoStreamAvailable) || errors.Is(err, zvuk.ErrFailedToFetchStreamMetadata) {
+		return true
+	}
+
+	return errors.Is(err, zvuk.ErrUnexpectedHTTPStatus) && !errors.Is(err, zvuk.ErrAuthTokenRejected)
+}
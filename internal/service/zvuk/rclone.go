@@ -0,0 +1,175 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// RcloneRunner runs rclone commands.
+type RcloneRunner interface {
+	// Run executes rclone with the arguments and returns an error with its last output line on failure.
+	Run(ctx context.Context, args []string) error
+}
+
+// ExecRcloneRunner runs the rclone executable.
+type ExecRcloneRunner struct {
+	// rclonePath is the path to the rclone executable.
+	rclonePath string
+}
+
+// rcloneTarget is a folder or a file saved during the run that is pushed with rclone once the run is over.
+type rcloneTarget struct {
+	// title is the collection or track the target holds.
+	title string
+	// localPath is the folder of a collection, or a track saved outside a collection folder.
+	localPath string
+	// isFolder indicates whether localPath is a collection folder.
+	isFolder bool
+}
+
+// NewRcloneRunner creates a runner for the configured rclone executable.
+func NewRcloneRunner(cfg *config.Config) RcloneRunner {
+	return &ExecRcloneRunner{rclonePath: cfg.RclonePath}
+}
+
+// Run executes rclone with the arguments.
+func (r *ExecRcloneRunner) Run(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, r.rclonePath, args...) //nolint:gosec // Arguments are built by the application.
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, lastLine(string(output)))
+	}
+
+	return nil
+}
+
+// checkRclone verifies that rclone is available before any download starts.
+func (s *ServiceImpl) checkRclone() error {
+	if s.rclone == nil || s.cfg.DryRun {
+		return nil
+	}
+
+	if _, err := exec.LookPath(s.cfg.RclonePath); err != nil {
+		return fmt.Errorf("rclone is required for rclone_remote: %w", err)
+	}
+
+	return nil
+}
+
+// queueRcloneTarget remembers the folder of a saved track's collection, or the track itself when it is saved
+// outside a collection folder, so it is pushed to rclone_remote after the run.
+func (s *ServiceImpl) queueRcloneTarget(t *downloadTrackTask) {
+	if s.rclone == nil {
+		return
+	}
+
+	target := &rcloneTarget{localPath: t.trackPath}
+	if t.track != nil {
+		target.title = t.track.Title
+	}
+
+	if collection := t.audioCollection; collection != nil && collection.hasOwnFolder {
+		target = &rcloneTarget{
+			title:     collection.title,
+			localPath: collection.tracksPath,
+			isFolder:  true,
+		}
+	}
+
+	s.rcloneMutex.Lock()
+	defer s.rcloneMutex.Unlock()
+
+	if s.rcloneTargets == nil {
+		s.rcloneTargets = make(map[string]*rcloneTarget)
+	}
+
+	s.rcloneTargets[target.localPath] = target
+}
+
+// runRcloneTargets pushes the collections saved during the run to rclone_remote, one rclone command each,
+// so a failure affects only its collection and is reported in the summary.
+func (s *ServiceImpl) runRcloneTargets(ctx context.Context) {
+	s.rcloneMutex.Lock()
+	targets := make([]*rcloneTarget, 0, len(s.rcloneTargets))
+
+	for _, localPath := range slices.Sorted(maps.Keys(s.rcloneTargets)) {
+		targets = append(targets, s.rcloneTargets[localPath])
+	}
+
+	s.rcloneTargets = nil
+	s.rcloneMutex.Unlock()
+
+	if len(targets) == 0 {
+		return
+	}
+
+	if ctx.Err() != nil {
+		logger.Warnf(ctx, "Run is interrupted, %d item(s) are not pushed to '%s'", len(targets), s.cfg.RcloneRemote)
+
+		return
+	}
+
+	logger.Infof(ctx, "Pushing %d item(s) to '%s' with rclone %s", len(targets), s.cfg.RcloneRemote, s.cfg.RcloneMode)
+
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		args := s.rcloneArgs(target)
+
+		if s.cfg.DryRun {
+			logger.Infof(ctx, "[DRY-RUN] Would run: rclone %s", strings.Join(args, " "))
+
+			continue
+		}
+
+		logger.Debugf(ctx, "Running: rclone %s", strings.Join(args, " "))
+
+		if err := s.rclone.Run(ctx, args); err != nil {
+			logger.Errorf(ctx, "Failed to push '%s' with rclone: %v", target.localPath, err)
+
+			s.recordRcloneFailure(&RcloneFailure{
+				Title: target.title,
+				Path:  target.localPath,
+				Error: err.Error(),
+			})
+
+			continue
+		}
+
+		s.incrementRclonePushed()
+	}
+}
+
+// rcloneArgs builds the rclone command of a target, mirroring its place in output_path on the remote.
+func (s *ServiceImpl) rcloneArgs(target *rcloneTarget) []string {
+	relativePath, err := filepath.Rel(s.cfg.OutputPath, target.localPath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		relativePath = filepath.Base(target.localPath)
+	}
+
+	destination := s.cfg.RcloneRemote
+	if !strings.HasSuffix(destination, ":") && !strings.HasSuffix(destination, "/") {
+		destination += "/"
+	}
+
+	destination += filepath.ToSlash(relativePath)
+
+	command := s.cfg.RcloneMode
+	if !target.isFolder {
+		// A single file is copied to its exact name; syncing a file would not differ from it.
+		command = "copyto"
+	}
+
+	return slices.Concat([]string{command, target.localPath, destination}, s.cfg.RcloneArgs)
+}
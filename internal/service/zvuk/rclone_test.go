@@ -0,0 +1,113 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// fakeRcloneRunner records the rclone commands and fails the ones for failingPath.
+type fakeRcloneRunner struct {
+	calls       [][]string
+	failingPath string
+}
+
+func (f *fakeRcloneRunner) Run(_ context.Context, args []string) error {
+	f.calls = append(f.calls, args)
+
+	if args[1] == f.failingPath {
+		return errors.New("exit status 1: directory not found")
+	}
+
+	return nil
+}
+
+// TestRunRcloneTargets tests that every saved collection is pushed once and failures are reported.
+func TestRunRcloneTargets(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath = t.TempDir()
+		albumPath  = filepath.Join(outputPath, "Artist", "Album")
+		singlePath = filepath.Join(outputPath, "Other - Single.mp3")
+		failedPath = filepath.Join(outputPath, "Artist", "Broken")
+		runner     = &fakeRcloneRunner{failingPath: failedPath}
+	)
+
+	impl, ok := NewService(&config.Config{
+		OutputPath:   outputPath,
+		RcloneRemote: "gdrive:Music",
+		RcloneMode:   config.RcloneModeSync,
+		RcloneArgs:   []string{"--transfers=8"},
+	}, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.rclone = runner
+
+	album := &audioCollection{title: "Album", tracksPath: albumPath, hasOwnFolder: true}
+	broken := &audioCollection{title: "Broken", tracksPath: failedPath, hasOwnFolder: true}
+	single := &audioCollection{title: "Single", tracksPath: outputPath}
+
+	for _, task := range []*downloadTrackTask{
+		{audioCollection: album, trackPath: filepath.Join(albumPath, "01.flac")},
+		{audioCollection: album, trackPath: filepath.Join(albumPath, "02.flac")},
+		{audioCollection: broken, trackPath: filepath.Join(failedPath, "01.flac")},
+		{audioCollection: single, trackPath: singlePath, track: &zvuk.Track{Title: "Single"}},
+	} {
+		impl.queueRcloneTarget(task)
+	}
+
+	impl.runRcloneTargets(context.Background())
+
+	assert.Equal(t, [][]string{
+		{"sync", albumPath, "gdrive:Music/Artist/Album", "--transfers=8"},
+		{"sync", failedPath, "gdrive:Music/Artist/Broken", "--transfers=8"},
+		{"copyto", singlePath, "gdrive:Music/Other - Single.mp3", "--transfers=8"},
+	}, runner.calls)
+
+	stats := impl.Statistics()
+	assert.Equal(t, int64(2), stats.RclonePushed)
+	require.Len(t, stats.RcloneFailures, 1)
+	assert.Equal(t, "Broken", stats.RcloneFailures[0].Title)
+	assert.Contains(t, stats.RcloneFailures[0].Error, "directory not found")
+
+	// The targets are pushed once.
+	impl.runRcloneTargets(context.Background())
+	assert.Len(t, runner.calls, 3)
+}
+
+// TestRunRcloneTargets_DryRun tests that nothing is pushed in dry-run mode.
+func TestRunRcloneTargets_DryRun(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+	runner := &fakeRcloneRunner{}
+
+	impl, ok := NewService(&config.Config{
+		OutputPath:   outputPath,
+		DryRun:       true,
+		RcloneRemote: "nas:",
+		RcloneMode:   config.RcloneModeCopy,
+	}, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+
+	impl.rclone = runner
+
+	target := &rcloneTarget{localPath: filepath.Join(outputPath, "Album"), isFolder: true}
+	assert.Equal(t, []string{"copy", target.localPath, "nas:Album"}, impl.rcloneArgs(target))
+
+	impl.queueRcloneTarget(&downloadTrackTask{
+		audioCollection: &audioCollection{tracksPath: target.localPath, hasOwnFolder: true},
+	})
+	impl.runRcloneTargets(context.Background())
+
+	assert.Empty(t, runner.calls)
+	assert.Zero(t, impl.Statistics().RclonePushed)
+}
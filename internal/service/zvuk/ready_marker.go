@@ -0,0 +1,71 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// isCompleted reports whether every track of the collection was saved or skipped, and none was left out,
+// so the folder is final and can be handed over to importers and remote storages.
+func (metadata *downloadTracksMetadata) isCompleted(ctx context.Context) bool {
+	return ctx.Err() == nil &&
+		metadata.failedTracks.Load() == 0 &&
+		len(metadata.deselectedTracks) == 0 &&
+		!metadata.isCutShort.Load() &&
+		!metadata.failureTracker.isAborted()
+}
+
+// removeReadyMarker deletes the ready marker of a collection folder that is about to change,
+// so media server importers do not pick up the folder before the new tracks are finished.
+func (s *ServiceImpl) removeReadyMarker(ctx context.Context, collection *audioCollection) {
+	if s.cfg.ReadyMarkerFilename == "" || s.cfg.DryRun || !collection.hasOwnFolder {
+		return
+	}
+
+	markerPath := filepath.Join(collection.tracksPath, s.cfg.ReadyMarkerFilename)
+	if err := os.Remove(markerPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Errorf(ctx, "Failed to delete ready marker '%s': %v", markerPath, err)
+	}
+}
+
+// writeReadyMarker creates the ready marker in the folder of a collection once its tracks are tagged
+// and its covers are in place. The marker is written under a temporary name and renamed,
+// so watchers receive a single event for a complete file.
+func (s *ServiceImpl) writeReadyMarker(ctx context.Context, metadata *downloadTracksMetadata) {
+	collection := metadata.audioCollection
+	if s.cfg.ReadyMarkerFilename == "" || collection == nil || !collection.hasOwnFolder || !metadata.isCompleted(ctx) {
+		return
+	}
+
+	markerPath := filepath.Join(collection.tracksPath, s.cfg.ReadyMarkerFilename)
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would create ready marker: %s", markerPath)
+
+		return
+	}
+
+	tempPath := markerPath + ".part"
+	content := []byte(time.Now().Format(time.RFC3339) + "\n")
+
+	err := os.WriteFile(tempPath, content, constants.DefaultFilePermissions)
+	if err == nil {
+		err = os.Rename(tempPath, markerPath)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempPath)
+
+		logger.Errorf(ctx, "Failed to create ready marker '%s': %v", markerPath, err)
+
+		return
+	}
+
+	logger.Debugf(ctx, "Created ready marker '%s'", markerPath)
+}
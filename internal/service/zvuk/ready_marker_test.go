@@ -0,0 +1,80 @@
+package zvuk
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestReadyMarker tests that the ready marker appears only in the folders of completed collections.
+func TestReadyMarker(t *testing.T) {
+	t.Parallel()
+
+	newMetadata := func(t *testing.T, cfg *config.Config) (*ServiceImpl, *downloadTracksMetadata, string) {
+		t.Helper()
+
+		impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		collection := &audioCollection{
+			category:     DownloadCategoryAlbum,
+			id:           "100",
+			title:        "Album",
+			tracksPath:   t.TempDir(),
+			hasOwnFolder: true,
+		}
+
+		metadata := &downloadTracksMetadata{audioCollection: collection, category: DownloadCategoryAlbum}
+
+		return impl, metadata, filepath.Join(collection.tracksPath, ".complete")
+	}
+
+	t.Run("completed collection is marked and the marker is removed before changes", func(t *testing.T) {
+		t.Parallel()
+
+		impl, metadata, markerPath := newMetadata(t, &config.Config{ReadyMarkerFilename: ".complete"})
+
+		impl.writeReadyMarker(context.Background(), metadata)
+		assert.FileExists(t, markerPath)
+		assert.NoFileExists(t, markerPath+".part")
+
+		impl.removeReadyMarker(context.Background(), metadata.audioCollection)
+		assert.NoFileExists(t, markerPath)
+	})
+
+	t.Run("incomplete collection is not marked", func(t *testing.T) {
+		t.Parallel()
+
+		impl, metadata, markerPath := newMetadata(t, &config.Config{ReadyMarkerFilename: ".complete"})
+
+		metadata.failedTracks.Store(1)
+		impl.writeReadyMarker(context.Background(), metadata)
+
+		metadata.failedTracks.Store(0)
+		metadata.isCutShort.Store(true)
+		impl.writeReadyMarker(context.Background(), metadata)
+
+		metadata.isCutShort.Store(false)
+		metadata.audioCollection.hasOwnFolder = false
+		impl.writeReadyMarker(context.Background(), metadata)
+
+		assert.NoFileExists(t, markerPath)
+	})
+
+	t.Run("markers are disabled or simulated", func(t *testing.T) {
+		t.Parallel()
+
+		impl, metadata, markerPath := newMetadata(t, &config.Config{})
+		impl.writeReadyMarker(context.Background(), metadata)
+		assert.NoFileExists(t, markerPath)
+
+		impl, metadata, markerPath = newMetadata(t, &config.Config{ReadyMarkerFilename: ".complete", DryRun: true})
+		impl.writeReadyMarker(context.Background(), metadata)
+		assert.NoFileExists(t, markerPath)
+	})
+}
@@ -0,0 +1,199 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// reissuedRelease is an album saved by an earlier run whose tracks are on a release with another ID.
+type reissuedRelease struct {
+	// releaseID is the ID the album was saved with.
+	releaseID string
+	// folder is the folder the album was saved to.
+	folder string
+	// entries are the latest history records of the saved tracks.
+	entries []*HistoryEntry
+}
+
+// findReissuedRelease returns the album of the download history that the release re-issues, or nil.
+// The album qualifies when all of its tracks are on the release and make up more than half of it,
+// so a compilation is not taken for a re-issue, and when its tracks, and no others, are in one existing folder.
+// Of several albums, the one with the most tracks is taken.
+func findReissuedRelease(
+	entries []*HistoryEntry,
+	releaseID string,
+	trackIDs []int64,
+	outputPath string,
+) *reissuedRelease {
+	latestEntries := make(map[string]*HistoryEntry, len(entries))
+	for _, entry := range entries {
+		latestEntries[entry.TrackID] = entry
+	}
+
+	releaseTrackIDs := make(map[string]struct{}, len(trackIDs))
+	for _, trackID := range trackIDs {
+		releaseTrackIDs[strconv.FormatInt(trackID, 10)] = struct{}{}
+	}
+
+	var (
+		albumEntries  = make(map[string][]*HistoryEntry)
+		folderParents = make(map[string]map[string]struct{})
+	)
+
+	for _, trackID := range slices.Sorted(maps.Keys(latestEntries)) {
+		entry := latestEntries[trackID]
+		folder := filepath.Clean(filepath.Dir(entry.Path))
+
+		if folderParents[folder] == nil {
+			folderParents[folder] = make(map[string]struct{})
+		}
+
+		folderParents[folder][entry.Category+":"+entry.ParentID] = struct{}{}
+
+		if entry.Category == DownloadCategoryAlbum.ToLowerCase() && entry.ParentID != "" && entry.ParentID != releaseID {
+			albumEntries[entry.ParentID] = append(albumEntries[entry.ParentID], entry)
+		}
+	}
+
+	var result *reissuedRelease
+
+	for _, parentID := range slices.Sorted(maps.Keys(albumEntries)) {
+		candidate := albumEntries[parentID]
+		if len(candidate)*2 <= len(trackIDs) || (result != nil && len(candidate) <= len(result.entries)) {
+			continue
+		}
+
+		folder := filepath.Clean(filepath.Dir(candidate[0].Path))
+		if !isReissueCandidate(candidate, folder, releaseTrackIDs) ||
+			len(folderParents[folder]) != 1 ||
+			folder == filepath.Clean(outputPath) {
+			continue
+		}
+
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			continue
+		}
+
+		result = &reissuedRelease{releaseID: parentID, folder: folder, entries: candidate}
+	}
+
+	return result
+}
+
+// isReissueCandidate reports whether every saved track of an album is on the release and in the folder.
+func isReissueCandidate(entries []*HistoryEntry, folder string, releaseTrackIDs map[string]struct{}) bool {
+	for _, entry := range entries {
+		if _, ok := releaseTrackIDs[entry.TrackID]; !ok {
+			return false
+		}
+
+		if filepath.Clean(filepath.Dir(entry.Path)) != folder {
+			return false
+		}
+	}
+
+	return true
+}
+
+// relinkReissue moves the folder of an album saved by an earlier run to the folder of its re-issue
+// under a new release ID, so the saved tracks are not downloaded again (relink_reissues).
+// The moved tracks are recorded in the download history under the new release.
+func (s *ServiceImpl) relinkReissue(ctx context.Context, in *registerCollectionCoreInput, itemPath string) {
+	if !s.cfg.RelinkReissues || s.history == nil || in.Category != DownloadCategoryAlbum || in.FirstTrackFilename != "" {
+		return
+	}
+
+	if filepath.Clean(itemPath) == filepath.Clean(s.cfg.OutputPath) {
+		return
+	}
+
+	// An existing folder is never merged into.
+	if _, err := os.Stat(itemPath); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	entries, err := readHistoryEntries(ctx, s.history.path)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to read the download history to relink album '%s': %v", in.Title, err)
+
+		return
+	}
+
+	previous := findReissuedRelease(entries, in.ItemID, in.TrackIDs, s.cfg.OutputPath)
+	if previous == nil {
+		return
+	}
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would move album '%s' (ID: %s) re-issued from ID %s from '%s' to '%s'",
+			in.Title, in.ItemID, previous.releaseID, previous.folder, itemPath)
+
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(itemPath), defaultFolderPermissions)
+	if err == nil {
+		err = os.Rename(previous.folder, itemPath)
+	}
+
+	if err != nil {
+		s.recordError(&DownloadError{
+			Category:  DownloadCategoryAlbum,
+			ItemID:    in.ItemID,
+			ItemTitle: in.Title,
+			Phase:     "relinking re-issued release",
+			Error:     err,
+		})
+
+		return
+	}
+
+	s.recordRelinkedHistory(ctx, in, previous, itemPath)
+
+	logger.Infof(ctx, "Album '%s' (ID: %s) is a re-issue of ID %s, moved %d saved track(s) from '%s' to '%s'",
+		in.Title, in.ItemID, previous.releaseID, len(previous.entries), previous.folder, itemPath)
+
+	s.recordRelinkedRelease(&RelinkedRelease{
+		ReleaseID:         in.ItemID,
+		Title:             in.Title,
+		PreviousReleaseID: previous.releaseID,
+		PreviousPath:      previous.folder,
+		Path:              itemPath,
+		Tracks:            len(previous.entries),
+	})
+}
+
+// recordRelinkedHistory records the moved tracks in the download history under the re-issue,
+// so their new paths are used to skip them and to list them in playlists.
+func (s *ServiceImpl) recordRelinkedHistory(
+	ctx context.Context,
+	in *registerCollectionCoreInput,
+	previous *reissuedRelease,
+	itemPath string,
+) {
+	now := time.Now()
+
+	for _, entry := range previous.entries {
+		relinked := *entry
+		relinked.ParentID = in.ItemID
+		relinked.ParentTitle = in.Title
+		relinked.Path = filepath.Join(itemPath, filepath.Base(entry.Path))
+		relinked.DownloadedAt = now
+
+		if trackID, err := strconv.ParseInt(entry.TrackID, 10, 64); err == nil {
+			relinked.Position = int64(slices.Index(in.TrackIDs, trackID) + 1)
+		}
+
+		if err := s.history.add(&relinked); err != nil {
+			logger.Warnf(ctx, "Failed to record track '%s' in the download history: %v", relinked.Title, err)
+		}
+	}
+}
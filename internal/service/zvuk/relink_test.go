@@ -0,0 +1,150 @@
+package zvuk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestFindReissuedRelease tests that only an album whose tracks make up most of the release is taken for a re-issue.
+func TestFindReissuedRelease(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath   = t.TempDir()
+		mutterFolder = filepath.Join(outputPath, "Rammstein", "2001 - Mutter")
+		sharedFolder = filepath.Join(outputPath, "Singles")
+	)
+
+	require.NoError(t, os.MkdirAll(mutterFolder, constants.DefaultFolderPermissions))
+	require.NoError(t, os.MkdirAll(sharedFolder, constants.DefaultFolderPermissions))
+
+	albumEntry := func(trackID, parentID, folder string) *HistoryEntry {
+		return &HistoryEntry{
+			TrackID:  trackID,
+			Category: DownloadCategoryAlbum.ToLowerCase(),
+			ParentID: parentID,
+			Path:     filepath.Join(folder, trackID+".flac"),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		entries    []*HistoryEntry
+		trackIDs   []int64
+		expectedID string
+	}{
+		{
+			name:       "re-issue with a bonus track",
+			entries:    []*HistoryEntry{albumEntry("101", "10", mutterFolder), albumEntry("102", "10", mutterFolder)},
+			trackIDs:   []int64{101, 102, 103},
+			expectedID: "10",
+		},
+		{
+			name:     "compilation",
+			entries:  []*HistoryEntry{albumEntry("101", "10", mutterFolder)},
+			trackIDs: []int64{101, 201, 301},
+		},
+		{
+			name:     "track missing from the re-issue",
+			entries:  []*HistoryEntry{albumEntry("101", "10", mutterFolder), albumEntry("102", "10", mutterFolder)},
+			trackIDs: []int64{101},
+		},
+		{
+			name:     "folder shared with another release",
+			entries:  []*HistoryEntry{albumEntry("101", "10", sharedFolder), albumEntry("201", "20", sharedFolder)},
+			trackIDs: []int64{101},
+		},
+		{
+			name: "folder removed",
+			entries: []*HistoryEntry{
+				albumEntry("101", "10", filepath.Join(outputPath, "Rammstein", "1997 - Sehnsucht")),
+			},
+			trackIDs: []int64{101},
+		},
+		{
+			name:     "same release",
+			entries:  []*HistoryEntry{albumEntry("101", "11", mutterFolder)},
+			trackIDs: []int64{101},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := findReissuedRelease(tt.entries, "11", tt.trackIDs, outputPath)
+			if tt.expectedID == "" {
+				assert.Nil(t, result)
+
+				return
+			}
+
+			require.NotNil(t, result)
+			assert.Equal(t, tt.expectedID, result.releaseID)
+			assert.Equal(t, mutterFolder, result.folder)
+		})
+	}
+}
+
+// TestRelinkReissue tests that the folder of the old release is moved and its tracks are recorded under the new one.
+func TestRelinkReissue(t *testing.T) {
+	t.Parallel()
+
+	var (
+		outputPath  = t.TempDir()
+		oldFolder   = filepath.Join(outputPath, "Rammstein", "2001 - Mutter")
+		newFolder   = filepath.Join(outputPath, "Rammstein", "2001 - Mutter (Remastered)")
+		oldPath     = filepath.Join(oldFolder, "01 - Mein Herz brennt.flac")
+		historyPath = filepath.Join(t.TempDir(), "history.jsonl")
+	)
+
+	require.NoError(t, os.MkdirAll(oldFolder, constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(oldPath, []byte("audio"), constants.DefaultFilePermissions))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(oldFolder, "02 - Links 2-3-4.flac"), []byte("audio"), constants.DefaultFilePermissions))
+
+	s := &ServiceImpl{
+		cfg:     &config.Config{OutputPath: outputPath, RelinkReissues: true},
+		history: newDownloadHistory(historyPath),
+		stats:   newStatsCollector(),
+	}
+
+	require.NoError(t, s.history.add(&HistoryEntry{
+		TrackID: "101", Title: "Mein Herz brennt", Category: DownloadCategoryAlbum.ToLowerCase(),
+		ParentID: "10", ParentTitle: "Mutter", Position: 1, Path: oldPath,
+	}))
+	require.NoError(t, s.history.add(&HistoryEntry{
+		TrackID: "102", Title: "Links 2-3-4", Category: DownloadCategoryAlbum.ToLowerCase(),
+		ParentID: "10", ParentTitle: "Mutter", Position: 2, Path: filepath.Join(oldFolder, "02 - Links 2-3-4.flac"),
+	}))
+
+	s.relinkReissue(t.Context(), &registerCollectionCoreInput{
+		Category: DownloadCategoryAlbum,
+		ItemID:   "11",
+		Title:    "Mutter (Remastered)",
+		TrackIDs: []int64{101, 102, 103},
+	}, newFolder)
+
+	assert.NoDirExists(t, oldFolder)
+	assert.FileExists(t, filepath.Join(newFolder, "01 - Mein Herz brennt.flac"))
+
+	entries, err := readHistoryEntries(t.Context(), historyPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "11", entries[2].ParentID)
+	assert.Equal(t, "Mutter (Remastered)", entries[2].ParentTitle)
+	assert.Equal(t, int64(1), entries[2].Position)
+	assert.Equal(t, filepath.Join(newFolder, "01 - Mein Herz brennt.flac"), entries[2].Path)
+
+	stats := s.stats.snapshot()
+	require.Len(t, stats.RelinkedReleases, 1)
+	assert.Equal(t, "10", stats.RelinkedReleases[0].PreviousReleaseID)
+	assert.Equal(t, oldFolder, stats.RelinkedReleases[0].PreviousPath)
+}
@@ -0,0 +1,326 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+const (
+	// s3PartSize is the size of the parts archives are uploaded in; S3 needs at least 5 MiB for every part but the last.
+	s3PartSize = 8 << 20
+	// s3DateFormat is the timestamp format of the x-amz-date header.
+	s3DateFormat = "20060102T150405Z"
+	// s3Service is the service name requests are signed for.
+	s3Service = "s3"
+)
+
+// s3Storage uploads archives to an S3-compatible object storage with multipart uploads.
+// Requests are signed with AWS Signature Version 4 and use path-style URLs (endpoint/bucket/key),
+// which all S3-compatible servers accept.
+type s3Storage struct {
+	// endpoint is the URL of the bucket, with the optional key prefix.
+	endpoint *url.URL
+	// accessKey is the access key ID.
+	accessKey string
+	// secretKey is the secret access key.
+	secretKey string
+	// region is the region requests are signed for.
+	region string
+	// httpClient sends the requests, each limited by remoteRequestTimeout.
+	httpClient *http.Client
+	// now returns the time requests are signed at.
+	now func() time.Time
+}
+
+// s3Error is the error document returned by S3.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3CompletedPart is a part listed in the request completing a multipart upload.
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// newS3Storage creates an S3 storage from the remote storage settings.
+func newS3Storage(cfg *config.Config) *s3Storage {
+	// The URL is validated with the configuration.
+	endpoint, _ := url.Parse(strings.TrimRight(cfg.RemoteURL, "/"))
+
+	return &s3Storage{
+		endpoint:   endpoint,
+		accessKey:  cfg.RemoteUsername,
+		secretKey:  cfg.RemotePassword,
+		region:     cfg.RemoteRegion,
+		httpClient: &http.Client{Timeout: remoteRequestTimeout},
+		now:        time.Now,
+	}
+}
+
+// Upload streams the content to the object named key in parts, so archives are never held in memory as a whole.
+// The upload is aborted on failure, so no incomplete parts are left in the bucket.
+func (s *s3Storage) Upload(ctx context.Context, key string, r io.Reader) error {
+	objectPath := s.endpoint.Path + "/" + key
+
+	var started struct {
+		UploadID string `xml:"UploadId"`
+	}
+
+	err := s.send(ctx, http.MethodPost, objectPath, url.Values{"uploads": {""}}, nil, &started)
+	if err != nil {
+		return fmt.Errorf("failed to start the upload: %w", err)
+	}
+
+	parts, err := s.uploadParts(ctx, objectPath, started.UploadID, r)
+	if err == nil {
+		err = s.completeUpload(ctx, objectPath, started.UploadID, parts)
+	}
+
+	if err != nil {
+		// The request context may be canceled already, and the abort must still reach the server.
+		abortQuery := url.Values{"uploadId": {started.UploadID}}
+		_ = s.send(context.WithoutCancel(ctx), http.MethodDelete, objectPath, abortQuery, nil, nil)
+
+		return err
+	}
+
+	return nil
+}
+
+// uploadParts reads the content part by part and uploads each of them.
+func (s *s3Storage) uploadParts(
+	ctx context.Context,
+	objectPath, uploadID string,
+	r io.Reader,
+) ([]s3CompletedPart, error) {
+	var (
+		parts  []s3CompletedPart
+		buffer = make([]byte, s3PartSize)
+	)
+
+	for partNumber := 1; ; partNumber++ {
+		size, readErr := io.ReadFull(r, buffer)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil, readErr
+		}
+
+		// An empty object is still uploaded as a single empty part.
+		if size == 0 && len(parts) > 0 {
+			break
+		}
+
+		query := url.Values{
+			"partNumber": {strconv.Itoa(partNumber)},
+			"uploadId":   {uploadID},
+		}
+
+		response, err := s.do(ctx, http.MethodPut, objectPath, query, buffer[:size])
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+		}
+
+		parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: response.header.Get("ETag")})
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	return parts, nil
+}
+
+// completeUpload assembles the uploaded parts into the object.
+func (s *s3Storage) completeUpload(
+	ctx context.Context,
+	objectPath, uploadID string,
+	parts []s3CompletedPart,
+) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	// S3 may answer 200 OK and still report the failure in the body.
+	var result struct {
+		s3Error
+
+		XMLName xml.Name
+	}
+
+	err = s.send(ctx, http.MethodPost, objectPath, url.Values{"uploadId": {uploadID}}, body, &result)
+	if err != nil {
+		return fmt.Errorf("failed to complete the upload: %w", err)
+	}
+
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("failed to complete the upload: %w: %s: %s",
+			ErrRemoteStorageRequest, result.Code, result.Message)
+	}
+
+	return nil
+}
+
+// send performs a request and decodes the XML response into result (nil skips decoding).
+func (s *s3Storage) send(
+	ctx context.Context,
+	method, objectPath string,
+	query url.Values,
+	body []byte,
+	result any,
+) error {
+	response, err := s.do(ctx, method, objectPath, query, body)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return xml.Unmarshal(response.body, result)
+}
+
+// s3Response is the headers and body of a successful S3 response.
+type s3Response struct {
+	header http.Header
+	body   []byte
+}
+
+// do signs and performs a request, returning an error for unsuccessful statuses.
+func (s *s3Storage) do(
+	ctx context.Context,
+	method, objectPath string,
+	query url.Values,
+	body []byte,
+) (*s3Response, error) {
+	requestURL := *s.endpoint
+	requestURL.Path = objectPath
+	requestURL.RawPath = escapeURLPath(objectPath, false)
+	requestURL.RawQuery = canonicalS3Query(query)
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(request, requestURL.RawPath, requestURL.RawQuery, body)
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		var s3Err s3Error
+		if xml.Unmarshal(responseBody, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fmt.Errorf("%w: %s: %s: %s", ErrRemoteStorageRequest, response.Status, s3Err.Code, s3Err.Message)
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrRemoteStorageRequest, response.Status)
+	}
+
+	return &s3Response{header: response.Header, body: responseBody}, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to a request.
+func (s *s3Storage) sign(request *http.Request, canonicalPath, canonicalQuery string, body []byte) {
+	var (
+		now         = s.now().UTC()
+		amzDate     = now.Format(s3DateFormat)
+		date        = amzDate[:8]
+		payloadHash = sha256Hex(body)
+		scope       = date + "/" + s.region + "/" + s3Service + "/aws4_request"
+	)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalPath,
+		canonicalQuery,
+		"host:" + request.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	for _, part := range []string{s.region, s3Service, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(signingKey, stringToSign))))
+}
+
+// canonicalS3Query encodes the query sorted by key, as the signature expects.
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, escapeURLPath(key, true)+"="+escapeURLPath(value, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of data.
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with the key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,162 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// sftpPath is the OpenSSH sftp client executable.
+const sftpPath = "sftp"
+
+// sftpStorage uploads archives with the OpenSSH sftp client, so servers are reached with the keys, the agent,
+// and the host settings of ~/.ssh/config. The client reads the local file to upload, so every archive
+// is written to a temporary file in the output folder first.
+type sftpStorage struct {
+	// destination is the [user@]host the client connects to.
+	destination string
+	// port is the SSH port (empty for the default or the one of ~/.ssh/config).
+	port string
+	// basePath is the folder archives are uploaded to (empty for the home folder).
+	basePath string
+	// tempDir is the folder the archives are written to before the upload.
+	tempDir string
+	// runBatch runs the client with the arguments and the batch of commands.
+	runBatch func(ctx context.Context, args []string, batch string) error
+}
+
+// newSFTPStorage creates an SFTP storage from the remote storage settings.
+func newSFTPStorage(cfg *config.Config) *sftpStorage {
+	// The URL is validated with the configuration.
+	remoteURL, _ := url.Parse(cfg.RemoteURL)
+
+	username := remoteURL.User.Username()
+	if username == "" {
+		username = cfg.RemoteUsername
+	}
+
+	destination := remoteURL.Hostname()
+	if username != "" {
+		destination = username + "@" + destination
+	}
+
+	return &sftpStorage{
+		destination: destination,
+		port:        remoteURL.Port(),
+		basePath:    strings.TrimRight(remoteURL.Path, "/"),
+		tempDir:     cfg.OutputPath,
+		runBatch:    runSFTPBatch,
+	}
+}
+
+// Upload writes the content to a temporary file and uploads it under a temporary name renamed to key,
+// so an interrupted upload never leaves a truncated archive under the final name.
+func (s *sftpStorage) Upload(ctx context.Context, key string, r io.Reader) error {
+	if strings.ContainsAny(key, "\r\n") {
+		return fmt.Errorf("%w: line breaks are not supported in '%s'", ErrRemoteStorageRequest, key)
+	}
+
+	file, err := os.CreateTemp(s.tempDir, ".zvuk-grabber-upload-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary archive: %w", err)
+	}
+
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write temporary archive: %w", err)
+	}
+
+	args := []string{"-b", "-"}
+	if s.port != "" {
+		args = append(args, "-P", s.port)
+	}
+
+	args = append(args, s.destination)
+
+	if err = s.runBatch(ctx, args, s.buildBatch(tempPath, key)); err != nil {
+		return fmt.Errorf("%w: %w", ErrRemoteStorageRequest, err)
+	}
+
+	return nil
+}
+
+// buildBatch returns the commands creating the parent folders of key and uploading the local file to it.
+// Commands prefixed with '-' may fail: the folders and the previous archive may or may not exist.
+func (s *sftpStorage) buildBatch(localPath, key string) string {
+	remotePath := path.Join(s.basePath, key)
+	if s.basePath == "" {
+		remotePath = key
+	}
+
+	var batch strings.Builder
+
+	segments := strings.Split(remotePath, "/")
+
+	folderPath := ""
+	for i, segment := range segments[:len(segments)-1] {
+		if i > 0 {
+			folderPath += "/"
+		}
+
+		folderPath += segment
+		if segment != "" {
+			fmt.Fprintf(&batch, "-mkdir %s\n", quoteSFTPArgument(folderPath))
+		}
+	}
+
+	partPath := remotePath + ".part"
+
+	fmt.Fprintf(&batch, "put %s %s\n", quoteSFTPArgument(localPath), quoteSFTPArgument(partPath))
+	fmt.Fprintf(&batch, "-rm %s\n", quoteSFTPArgument(remotePath))
+	fmt.Fprintf(&batch, "rename %s %s\n", quoteSFTPArgument(partPath), quoteSFTPArgument(remotePath))
+
+	return batch.String()
+}
+
+// quoteSFTPArgument quotes a path for the sftp batch, escaping the quotes, backslashes,
+// and the characters sftp would otherwise expand as a glob pattern.
+func quoteSFTPArgument(value string) string {
+	var builder strings.Builder
+
+	builder.WriteByte('"')
+
+	for _, r := range value {
+		switch r {
+		case '"', '\\', '*', '?', '[', ']':
+			builder.WriteByte('\\')
+		}
+
+		builder.WriteRune(r)
+	}
+
+	builder.WriteByte('"')
+
+	return builder.String()
+}
+
+// runSFTPBatch runs the sftp client with the batch of commands on its standard input.
+func runSFTPBatch(ctx context.Context, args []string, batch string) error {
+	cmd := exec.CommandContext(ctx, sftpPath, args...) //nolint:gosec // Arguments are built by the application.
+	cmd.Stdin = strings.NewReader(batch)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, lastLine(string(output)))
+	}
+
+	return nil
+}
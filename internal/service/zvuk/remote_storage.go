@@ -0,0 +1,239 @@
+package zvuk
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// uploadedMarkerFilename is the file created in the folder of a collection once its archive is uploaded.
+	// It is not a part of the archive.
+	uploadedMarkerFilename = ".zvuk-grabber.uploaded"
+	// remoteRequestTimeout limits a single remote storage request with a body of a known size
+	// (an S3 part is at most s3PartSize) and the wait for the response to a streamed WebDAV upload.
+	remoteRequestTimeout = 5 * time.Minute
+	// remoteStallTimeout is how long a streamed WebDAV upload may send nothing before it is canceled.
+	remoteStallTimeout = 5 * time.Minute
+)
+
+// RemoteStorage stores the archives of completed collections outside the local filesystem.
+type RemoteStorage interface {
+	// Upload streams the content read from r to the object or file named key.
+	Upload(ctx context.Context, key string, r io.Reader) error
+}
+
+// NewRemoteStorage creates the remote storage selected by remote_storage.
+func NewRemoteStorage(cfg *config.Config) RemoteStorage {
+	switch cfg.RemoteStorage {
+	case config.RemoteStorageS3:
+		return newS3Storage(cfg)
+	case config.RemoteStorageSFTP:
+		return newSFTPStorage(cfg)
+	default:
+		return newWebDAVStorage(cfg)
+	}
+}
+
+// checkRemoteStorage verifies that the sftp client is available before any download starts.
+func (s *ServiceImpl) checkRemoteStorage() error {
+	if s.remoteStorage == nil || s.cfg.RemoteStorage != config.RemoteStorageSFTP || s.cfg.DryRun {
+		return nil
+	}
+
+	if _, err := exec.LookPath(sftpPath); err != nil {
+		return fmt.Errorf("the OpenSSH sftp client is required for SFTP uploads: %w", err)
+	}
+
+	return nil
+}
+
+// uploadCollection pushes the folder of a completed collection to the remote storage as a ZIP archive.
+// Collections with failed or skipped tracks are left for the next run. A successful upload leaves
+// the uploaded marker in the folder, so an archive is uploaded again only once new tracks are saved,
+// while a failed upload is retried on the next run.
+func (s *ServiceImpl) uploadCollection(ctx context.Context, metadata *downloadTracksMetadata) {
+	collection := metadata.audioCollection
+	if s.remoteStorage == nil || collection == nil || !collection.hasOwnFolder {
+		return
+	}
+
+	markerPath := filepath.Join(collection.tracksPath, uploadedMarkerFilename)
+
+	if metadata.isChanged.Load() {
+		// The uploaded archive is out of date until the folder is uploaded again.
+		if !s.cfg.DryRun {
+			if err := os.Remove(markerPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				logger.Errorf(ctx, "Failed to delete uploaded marker '%s': %v", markerPath, err)
+			}
+		}
+	} else if _, err := os.Stat(markerPath); err == nil {
+		return
+	}
+
+	if !metadata.isCompleted(ctx) {
+		return
+	}
+
+	relativePath, err := filepath.Rel(s.cfg.OutputPath, collection.tracksPath)
+	if err != nil {
+		relativePath = filepath.Base(collection.tracksPath)
+	}
+
+	key := filepath.ToSlash(relativePath) + ".zip"
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would upload %s '%s' to %s as '%s'",
+			collection.category.ToLowerCase(), collection.title, s.cfg.RemoteStorage, key)
+
+		return
+	}
+
+	logger.Infof(ctx, "Uploading %s '%s' to %s as '%s'",
+		collection.category.ToLowerCase(), collection.title, s.cfg.RemoteStorage, key)
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		writer.CloseWithError(writeFolderArchive(writer, collection.tracksPath))
+	}()
+
+	err = s.remoteStorage.Upload(ctx, key, reader)
+	// Unblock the archive writer if the upload stopped reading early.
+	_ = reader.CloseWithError(err)
+
+	if err != nil {
+		s.recordError(&DownloadError{
+			Category:  collection.category,
+			ItemID:    collection.id,
+			ItemTitle: collection.title,
+			Phase:     "uploading to remote storage",
+			Error:     err,
+		})
+
+		logger.Errorf(ctx, "Failed to upload %s '%s': %v", collection.category.ToLowerCase(), collection.title, err)
+
+		return
+	}
+
+	logger.Infof(ctx, "Uploaded %s '%s'", collection.category.ToLowerCase(), collection.title)
+
+	if !s.cfg.RemoteDeleteLocal {
+		content := []byte(time.Now().Format(time.RFC3339) + "\n")
+		if err = os.WriteFile(markerPath, content, constants.DefaultFilePermissions); err != nil {
+			logger.Errorf(ctx, "Failed to create uploaded marker '%s': %v", markerPath, err)
+		}
+
+		return
+	}
+
+	if err = os.RemoveAll(collection.tracksPath); err != nil {
+		logger.Errorf(ctx, "Failed to delete local folder '%s': %v", collection.tracksPath, err)
+	}
+}
+
+// writeFolderArchive writes the files of a folder as a ZIP archive with the folder name as its root.
+// Audio and images are already compressed, so they are stored as is. The uploaded marker is left out.
+func writeFolderArchive(w io.Writer, folderPath string) error {
+	archive := zip.NewWriter(w)
+	rootName := filepath.Base(folderPath)
+
+	err := filepath.WalkDir(folderPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || entry.Name() == uploadedMarkerFilename {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(folderPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		return addArchiveFile(archive, filePath, path.Join(rootName, filepath.ToSlash(relativePath)))
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// addArchiveFile copies a file into the archive under the given name.
+func addArchiveFile(archive *zip.Writer, filePath, name string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+
+	header.Method = zip.Deflate
+	if isCompressedFile(filePath) {
+		header.Method = zip.Store
+	}
+
+	entryWriter, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add '%s' to the archive: %w", name, err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(entryWriter, file)
+
+	return err
+}
+
+// isCompressedFile reports whether a file is audio or an image that does not shrink when deflated.
+func isCompressedFile(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".flac", ".mp3", ".m4a", ".aac", ".ogg", ".opus", ".jpg", ".jpeg", ".png", ".webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// escapeURLPath percent-encodes everything except the unreserved characters (and slashes unless isSlashEncoded).
+// This is the encoding S3 signatures expect, and WebDAV servers accept it as well.
+func escapeURLPath(value string, isSlashEncoded bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var builder strings.Builder
+
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~',
+			b == '/' && !isSlashEncoded:
+			builder.WriteByte(b)
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hexDigits[b>>4])
+			builder.WriteByte(hexDigits[b&0x0F])
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,420 @@
+package zvuk
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// fakeRemoteStorage keeps uploaded content in memory.
+type fakeRemoteStorage struct {
+	uploads map[string][]byte
+	err     error
+}
+
+func (f *fakeRemoteStorage) Upload(_ context.Context, key string, r io.Reader) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	f.uploads[key] = content
+
+	return nil
+}
+
+// TestUploadCollection tests that completed collections are archived and uploaded.
+func TestUploadCollection(t *testing.T) {
+	t.Parallel()
+
+	newCollection := func(t *testing.T, outputPath string) (*audioCollection, *downloadTracksMetadata) {
+		t.Helper()
+
+		tracksPath := filepath.Join(outputPath, "Artist", "Album")
+		require.NoError(t, os.MkdirAll(filepath.Join(tracksPath, "CD 2"), defaultFolderPermissions))
+
+		for name, content := range map[string]string{
+			"01 - First.flac":      "audio",
+			"cover.jpg":            "image",
+			"CD 2/02 - Second.mp3": "audio",
+		} {
+			err := os.WriteFile(filepath.Join(tracksPath, name), []byte(content), constants.DefaultFilePermissions)
+			require.NoError(t, err)
+		}
+
+		collection := &audioCollection{
+			category:     DownloadCategoryAlbum,
+			id:           "100",
+			title:        "Album",
+			tracksPath:   tracksPath,
+			hasOwnFolder: true,
+		}
+
+		metadata := &downloadTracksMetadata{audioCollection: collection, category: DownloadCategoryAlbum}
+		metadata.isChanged.Store(true)
+
+		return collection, metadata
+	}
+
+	newService := func(t *testing.T, cfg *config.Config, storage RemoteStorage) *ServiceImpl {
+		t.Helper()
+
+		impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		impl.remoteStorage = storage
+
+		return impl
+	}
+
+	t.Run("archive is uploaded and the local folder deleted", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			outputPath = t.TempDir()
+			storage    = &fakeRemoteStorage{uploads: make(map[string][]byte)}
+			impl       = newService(t, &config.Config{OutputPath: outputPath, RemoteDeleteLocal: true}, storage)
+		)
+
+		collection, metadata := newCollection(t, outputPath)
+		impl.uploadCollection(context.Background(), metadata)
+
+		require.Contains(t, storage.uploads, "Artist/Album.zip")
+
+		content := storage.uploads["Artist/Album.zip"]
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(archive.File))
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+
+		assert.ElementsMatch(t, []string{"Album/01 - First.flac", "Album/cover.jpg", "Album/CD 2/02 - Second.mp3"}, names)
+		assert.NoDirExists(t, collection.tracksPath)
+	})
+
+	t.Run("incomplete or unchanged collections are not uploaded", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			outputPath = t.TempDir()
+			storage    = &fakeRemoteStorage{uploads: make(map[string][]byte)}
+			impl       = newService(t, &config.Config{OutputPath: outputPath, RemoteDeleteLocal: true}, storage)
+		)
+
+		collection, metadata := newCollection(t, outputPath)
+
+		metadata.failedTracks.Store(1)
+		impl.uploadCollection(context.Background(), metadata)
+
+		metadata.failedTracks.Store(0)
+		metadata.isChanged.Store(false)
+		markerPath := filepath.Join(collection.tracksPath, uploadedMarkerFilename)
+		require.NoError(t, os.WriteFile(markerPath, nil, constants.DefaultFilePermissions))
+		impl.uploadCollection(context.Background(), metadata)
+
+		metadata.isChanged.Store(true)
+		collection.hasOwnFolder = false
+		impl.uploadCollection(context.Background(), metadata)
+
+		assert.Empty(t, storage.uploads)
+		assert.DirExists(t, collection.tracksPath)
+	})
+
+	t.Run("failed upload keeps the local folder", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			outputPath = t.TempDir()
+			storage    = &fakeRemoteStorage{err: ErrRemoteStorageRequest}
+			impl       = newService(t, &config.Config{OutputPath: outputPath, RemoteDeleteLocal: true}, storage)
+		)
+
+		collection, metadata := newCollection(t, outputPath)
+		impl.uploadCollection(context.Background(), metadata)
+
+		errs := impl.Statistics().Errors
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0].Error, ErrRemoteStorageRequest)
+		assert.Equal(t, "uploading to remote storage", errs[0].Phase)
+		assert.DirExists(t, collection.tracksPath)
+	})
+
+	t.Run("failed upload is retried on the next run", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			outputPath = t.TempDir()
+			storage    = &fakeRemoteStorage{uploads: make(map[string][]byte), err: ErrRemoteStorageRequest}
+			impl       = newService(t, &config.Config{OutputPath: outputPath}, storage)
+		)
+
+		collection, metadata := newCollection(t, outputPath)
+		markerPath := filepath.Join(collection.tracksPath, uploadedMarkerFilename)
+
+		impl.uploadCollection(context.Background(), metadata)
+		assert.NoFileExists(t, markerPath)
+
+		// The next run finds nothing new, but the collection was never uploaded.
+		storage.err = nil
+		metadata.isChanged.Store(false)
+		impl.uploadCollection(context.Background(), metadata)
+
+		require.Contains(t, storage.uploads, "Artist/Album.zip")
+		assert.FileExists(t, markerPath)
+
+		// Once uploaded, an unchanged collection is not uploaded again, and the marker is not archived.
+		delete(storage.uploads, "Artist/Album.zip")
+		impl.uploadCollection(context.Background(), metadata)
+		assert.Empty(t, storage.uploads)
+
+		metadata.isChanged.Store(true)
+		impl.uploadCollection(context.Background(), metadata)
+
+		content := storage.uploads["Artist/Album.zip"]
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		assert.Len(t, archive.File, 3)
+	})
+}
+
+// TestWebDAVStorage_Upload tests that parent folders are created before the archive is uploaded.
+func TestWebDAVStorage_Upload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mutex    sync.Mutex
+		requests []string
+		uploaded []byte
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+
+		switch r.Method {
+		case "MKCOL":
+			// The first folder exists already.
+			if strings.Count(r.URL.Path, "/") == 3 {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			} else {
+				w.WriteHeader(http.StatusCreated)
+			}
+		case http.MethodPut:
+			uploaded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	storage := newWebDAVStorage(&config.Config{
+		RemoteURL:      server.URL + "/dav/",
+		RemoteUsername: "user",
+		RemotePassword: "secret",
+	})
+
+	err := storage.Upload(context.Background(), "A/Artist Name/Album.zip", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"MKCOL /dav/A/",
+		"MKCOL /dav/A/Artist%20Name/",
+		"PUT /dav/A/Artist%20Name/Album.zip",
+	}, requests)
+	assert.Equal(t, "archive", string(uploaded))
+}
+
+// TestWebDAVStorage_UploadStalled tests that an upload the server stops accepting is canceled after the stall timeout.
+func TestWebDAVStorage_UploadStalled(t *testing.T) {
+	t.Parallel()
+
+	// The server never reads the archive, so the connection buffers fill up and the upload stalls.
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	storage := newWebDAVStorage(&config.Config{RemoteURL: server.URL})
+	storage.stallTimeout = 100 * time.Millisecond
+
+	err := storage.Upload(context.Background(), "Album.zip", bytes.NewReader(make([]byte, 64<<20)))
+	require.ErrorIs(t, err, ErrRemoteStorageStalled)
+}
+
+// TestS3Storage_Upload tests the multipart upload of an archive larger than one part.
+func TestS3Storage_Upload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mutex    sync.Mutex
+		parts    = make(map[string][]byte)
+		complete string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=access/"), "request must be signed")
+		assert.Equal(t, "/bucket/music/Artist%20Name/Album.zip", r.URL.EscapedPath())
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, sha256Hex(body), r.Header.Get("X-Amz-Content-Sha256"))
+
+		query := r.URL.Query()
+
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			_, _ = io.WriteString(w, `<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut:
+			assert.Equal(t, "upload-1", query.Get("uploadId"))
+			parts[query.Get("partNumber")] = body
+			w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+		case r.Method == http.MethodPost:
+			complete = string(body)
+			_, _ = io.WriteString(w, `<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	storage := newS3Storage(&config.Config{
+		RemoteURL:      server.URL + "/bucket/music",
+		RemoteUsername: "access",
+		RemotePassword: "secret",
+		RemoteRegion:   "us-east-1",
+	})
+
+	content := bytes.Repeat([]byte("x"), s3PartSize+10)
+
+	err := storage.Upload(context.Background(), "Artist Name/Album.zip", bytes.NewReader(content))
+	require.NoError(t, err)
+
+	require.Len(t, parts, 2)
+	assert.Len(t, parts["1"], s3PartSize)
+	assert.Len(t, parts["2"], 10)
+	assert.Contains(t, complete, `<Part><PartNumber>2</PartNumber><ETag>&#34;etag-2&#34;</ETag></Part>`)
+}
+
+// TestS3Storage_UploadAborted tests that a failed upload is aborted.
+func TestS3Storage_UploadAborted(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mutex     sync.Mutex
+		isAborted bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch r.Method {
+		case http.MethodPost:
+			_, _ = io.WriteString(w, `<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case http.MethodPut:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		case http.MethodDelete:
+			isAborted = r.URL.Query().Get("uploadId") == "upload-1"
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	storage := newS3Storage(&config.Config{
+		RemoteURL:      server.URL + "/bucket",
+		RemoteUsername: "access",
+		RemotePassword: "secret",
+		RemoteRegion:   "us-east-1",
+	})
+
+	err := storage.Upload(context.Background(), "Album.zip", strings.NewReader("archive"))
+	require.ErrorIs(t, err, ErrRemoteStorageRequest)
+	assert.Contains(t, err.Error(), "AccessDenied")
+	assert.True(t, isAborted, "upload must be aborted")
+}
+
+// TestSFTPStorage_Upload tests that the archive is uploaded under a temporary name into created parent folders.
+func TestSFTPStorage_Upload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		args     []string
+		batch    string
+		uploaded []byte
+		tempDir  = t.TempDir()
+	)
+
+	storage := newSFTPStorage(&config.Config{
+		OutputPath:     tempDir,
+		RemoteURL:      "sftp://nas.local:2222/volume1/music",
+		RemoteUsername: "music",
+	})
+	storage.runBatch = func(_ context.Context, runArgs []string, runBatch string) error {
+		args, batch = runArgs, runBatch
+
+		tempPaths, err := filepath.Glob(filepath.Join(tempDir, ".zvuk-grabber-upload-*.zip"))
+		require.NoError(t, err)
+		require.Len(t, tempPaths, 1)
+
+		uploaded, err = os.ReadFile(tempPaths[0])
+
+		return err
+	}
+
+	err := storage.Upload(context.Background(), `A/Artist "Best" [Live]/Album.zip`, strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	tempPaths, err := filepath.Glob(filepath.Join(tempDir, "*"))
+	require.NoError(t, err)
+
+	assert.Empty(t, tempPaths)
+	assert.Equal(t, []string{"-b", "-", "-P", "2222", "music@nas.local"}, args)
+	assert.Equal(t, "archive", string(uploaded))
+
+	batchLines := strings.Split(strings.TrimSpace(batch), "\n")
+	assert.Equal(t, []string{
+		`-mkdir "/volume1"`,
+		`-mkdir "/volume1/music"`,
+		`-mkdir "/volume1/music/A"`,
+		`-mkdir "/volume1/music/A/Artist \"Best\" \[Live\]"`,
+	}, batchLines[:4])
+	assert.Regexp(t, `^put ".+\.zip" "/volume1/music/A/Artist \\"Best\\" \\\[Live\\\]/Album\.zip\.part"$`, batchLines[4])
+	assert.Equal(t, []string{
+		`-rm "/volume1/music/A/Artist \"Best\" \[Live\]/Album.zip"`,
+		`rename "/volume1/music/A/Artist \"Best\" \[Live\]/Album.zip.part" ` +
+			`"/volume1/music/A/Artist \"Best\" \[Live\]/Album.zip"`,
+	}, batchLines[5:])
+}
@@ -0,0 +1,139 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// webDAVStorage uploads archives to a folder of a WebDAV server.
+type webDAVStorage struct {
+	// baseURL is the folder archives are uploaded to, without the trailing slash.
+	baseURL string
+	// username is the basic authentication user name (empty disables authentication).
+	username string
+	// password is the basic authentication password.
+	password string
+	// httpClient sends the requests. It has no overall timeout, as uploads of large collections take long:
+	// folders are created with remoteRequestTimeout, and uploads are canceled once they stall.
+	httpClient *http.Client
+	// stallTimeout is how long an upload may send nothing before it is canceled.
+	stallTimeout time.Duration
+}
+
+// stallGuardReader cancels an upload once its body is not read for the stall timeout,
+// which happens when the server stops accepting data.
+type stallGuardReader struct {
+	// reader is the body of the upload.
+	reader io.Reader
+	// timer cancels the upload when it fires.
+	timer *time.Timer
+	// timeout is how long the body may go unread.
+	timeout time.Duration
+}
+
+// Read reads the body and restarts the stall timer, which is stopped once the body is sent,
+// leaving the wait for the response to the response header timeout.
+func (r *stallGuardReader) Read(p []byte) (int, error) {
+	r.timer.Reset(r.timeout)
+
+	n, err := r.reader.Read(p)
+	if err != nil {
+		r.timer.Stop()
+	}
+
+	return n, err
+}
+
+// newWebDAVStorage creates a WebDAV storage from the remote storage settings.
+func newWebDAVStorage(cfg *config.Config) *webDAVStorage {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.ResponseHeaderTimeout = remoteRequestTimeout
+
+	return &webDAVStorage{
+		baseURL:      strings.TrimRight(cfg.RemoteURL, "/"),
+		username:     cfg.RemoteUsername,
+		password:     cfg.RemotePassword,
+		httpClient:   &http.Client{Transport: transport},
+		stallTimeout: remoteStallTimeout,
+	}
+}
+
+// Upload creates the parent folders of key and streams the content to it with PUT.
+func (w *webDAVStorage) Upload(ctx context.Context, key string, r io.Reader) error {
+	segments := strings.Split(key, "/")
+
+	folderURL := w.baseURL
+	for _, segment := range segments[:len(segments)-1] {
+		folderURL += "/" + escapeURLPath(segment, true)
+
+		requestCtx, cancel := context.WithTimeout(ctx, remoteRequestTimeout)
+		err := w.send(requestCtx, "MKCOL", folderURL+"/", http.NoBody,
+			http.StatusCreated, http.StatusMethodNotAllowed)
+
+		cancel()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	uploadCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	timer := time.AfterFunc(w.stallTimeout, func() {
+		cancel(fmt.Errorf("%w: nothing was sent for %s", ErrRemoteStorageStalled, w.stallTimeout))
+	})
+	defer timer.Stop()
+
+	body := &stallGuardReader{reader: r, timer: timer, timeout: w.stallTimeout}
+
+	err := w.send(uploadCtx, http.MethodPut, folderURL+"/"+escapeURLPath(segments[len(segments)-1], true), body,
+		http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if cause := context.Cause(uploadCtx); err != nil && errors.Is(cause, ErrRemoteStorageStalled) {
+		return cause
+	}
+
+	return err
+}
+
+// send performs a request and checks that the server answered with one of the expected statuses.
+// MKCOL answers 405 Method Not Allowed for folders that already exist.
+func (w *webDAVStorage) send(
+	ctx context.Context,
+	method, requestURL string,
+	body io.Reader,
+	expectedStatuses ...int,
+) error {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return err
+	}
+
+	if w.username != "" {
+		request.SetBasicAuth(w.username, w.password)
+	}
+
+	response, err := w.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	for _, status := range expectedStatuses {
+		if response.StatusCode == status {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s %s: %s", ErrRemoteStorageRequest, method, requestURL, response.Status)
+}
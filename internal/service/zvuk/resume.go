@@ -0,0 +1,206 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// ResumeState is the content of the resume state file: the failed items of the last run with errors.
+type ResumeState struct {
+	// SavedAt is when the state was written.
+	SavedAt time.Time `json:"saved_at"`
+	// Items lists the failed items in the order they failed.
+	Items []*ResumeItem `json:"items"`
+}
+
+// ResumeItem is a failed item saved to the resume state file.
+type ResumeItem struct {
+	// Ref is the identifier downloaded again by the resume command, e.g. "album:123".
+	Ref string `json:"ref"`
+	// Category is the type of item that failed.
+	Category DownloadCategory `json:"category"`
+	// ItemID is the unique identifier of the item that failed.
+	ItemID string `json:"item_id"`
+	// Title is the human-readable title of the item.
+	Title string `json:"title,omitempty"`
+	// URL is the URL of the failed item (for albums/playlists/artists).
+	URL string `json:"url,omitempty"`
+	// ParentCategory is the type of parent collection for tracks.
+	ParentCategory DownloadCategory `json:"parent_category,omitempty"`
+	// ParentID is the ID of the parent collection.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the parent collection.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// Phase indicates when the error occurred.
+	Phase string `json:"phase"`
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// ResumeFailedItems downloads again the items that failed in the last run with errors,
+// as saved to resume_state_path.
+func (s *ServiceImpl) ResumeFailedItems(ctx context.Context) {
+	state, err := loadResumeState(s.cfg.ResumeStatePath)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to load resume state: %v", err)
+
+		return
+	}
+
+	refs := state.refs()
+	if len(refs) == 0 {
+		logger.Infof(ctx, "No failed items to resume in '%s'", s.cfg.ResumeStatePath)
+
+		return
+	}
+
+	logger.Infof(ctx, "Resuming %d failed item(s) saved at %s",
+		len(refs), state.SavedAt.Local().Format(time.DateTime))
+
+	s.isResuming = true
+
+	s.DownloadURLs(ctx, refs)
+}
+
+// saveResumeState writes the failed items of the run to resume_state_path.
+// A run without failures keeps the previous state, unless it is the resume run itself,
+// in which case the replayed state is removed.
+func (s *ServiceImpl) saveResumeState(ctx context.Context) {
+	if s.cfg.DryRun || s.cfg.ResumeStatePath == "" {
+		return
+	}
+
+	items := buildResumeItems(s.Statistics().Errors)
+	if len(items) == 0 {
+		if !s.isResuming {
+			return
+		}
+
+		if err := os.Remove(s.cfg.ResumeStatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf(ctx, "Failed to remove resume state: %v", err)
+		}
+
+		return
+	}
+
+	state := &ResumeState{
+		SavedAt: time.Now(),
+		Items:   items,
+	}
+
+	if err := writeJSONAtomically(s.cfg.ResumeStatePath, state); err != nil {
+		logger.Warnf(ctx, "Failed to save resume state: %v", err)
+
+		return
+	}
+
+	logger.Infof(ctx, "Failed items are saved to '%s'", s.cfg.ResumeStatePath)
+}
+
+// buildResumeItems converts the recorded errors into resume items.
+// Tracks skipped by filters are left out, since they would be skipped again.
+func buildResumeItems(downloadErrors []*DownloadError) []*ResumeItem {
+	items := make([]*ResumeItem, 0, len(downloadErrors))
+
+	for _, e := range downloadErrors {
+		if e == nil || e.Error == nil || isFilterError(e.Error) {
+			continue
+		}
+
+		ref := resumeRef(e)
+		if ref == "" {
+			continue
+		}
+
+		items = append(items, &ResumeItem{
+			Ref:            ref,
+			Category:       e.Category,
+			ItemID:         e.ItemID,
+			Title:          e.ItemTitle,
+			URL:            e.ItemURL,
+			ParentCategory: e.ParentCategory,
+			ParentID:       e.ParentID,
+			ParentTitle:    e.ParentTitle,
+			Phase:          e.Phase,
+			Error:          logger.Redact(e.Error.Error()),
+		})
+	}
+
+	return items
+}
+
+// isFilterError reports whether the error records a track skipped by a quality or duration filter.
+func isFilterError(err error) bool {
+	return errors.Is(err, ErrQualityBelowThreshold) ||
+		errors.Is(err, ErrDurationBelowThreshold) ||
+		errors.Is(err, ErrDurationAboveThreshold)
+}
+
+// resumeRef returns the identifier that downloads the failed item again (empty if there is none).
+// A track of a playlist, audiobook, or podcast is downloaded again through its collection,
+// so it is saved to the same folder with the same number; the saved tracks of the collection are skipped.
+func resumeRef(e *DownloadError) string {
+	if e.Category == DownloadCategoryTrack {
+		switch e.ParentCategory {
+		case DownloadCategoryPlaylist, DownloadCategoryAudiobook, DownloadCategoryPodcast:
+			if isNumericID(e.ParentID) {
+				return e.ParentCategory.String() + ":" + e.ParentID
+			}
+		}
+	}
+
+	if e.Category == DownloadCategoryUnknown || !isNumericID(e.ItemID) {
+		return ""
+	}
+
+	return e.Category.String() + ":" + e.ItemID
+}
+
+// isNumericID reports whether the ID can be used in an identifier such as "track:123".
+func isNumericID(id string) bool {
+	_, err := strconv.ParseUint(id, 10, 64)
+
+	return err == nil
+}
+
+// refs returns the identifiers of the saved items without duplicates, in order.
+func (r *ResumeState) refs() []string {
+	result := make([]string, 0, len(r.Items))
+
+	for _, item := range r.Items {
+		if item.Ref != "" && !slices.Contains(result, item.Ref) {
+			result = append(result, item.Ref)
+		}
+	}
+
+	return result
+}
+
+// loadResumeState reads the resume state file. A missing file is an empty state.
+func loadResumeState(path string) (*ResumeState, error) {
+	state := new(ResumeState)
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resume state: %w", err)
+	}
+
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse resume state '%s': %w", path, err)
+	}
+
+	return state, nil
+}
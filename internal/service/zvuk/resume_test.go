@@ -0,0 +1,110 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestBuildResumeItems tests which failed items are saved and how they are downloaded again.
+func TestBuildResumeItems(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("connection reset")
+
+	items := buildResumeItems([]*DownloadError{
+		{Category: DownloadCategoryAlbum, ItemID: "100", ItemURL: "https://zvuk.com/release/100", Error: errFailed},
+		{Category: DownloadCategoryArtist, ItemID: "200", Error: errFailed},
+		{
+			Category:       DownloadCategoryTrack,
+			ItemID:         "301",
+			ParentCategory: DownloadCategoryAlbum,
+			ParentID:       "300",
+			Error:          errFailed,
+		},
+		{
+			Category:       DownloadCategoryTrack,
+			ItemID:         "401",
+			ParentCategory: DownloadCategoryPlaylist,
+			ParentID:       "400",
+			Error:          errFailed,
+		},
+		{
+			Category:       DownloadCategoryTrack,
+			ItemID:         "402",
+			ParentCategory: DownloadCategoryPlaylist,
+			ParentID:       "400",
+			Error:          errFailed,
+		},
+		{
+			Category:       DownloadCategoryTrack,
+			ItemID:         "501",
+			ParentCategory: DownloadCategoryTrack,
+			ParentID:       "standalone-tracks",
+			Error:          errFailed,
+		},
+		{Category: DownloadCategoryTrack, ItemID: "601", Error: ErrDurationBelowThreshold},
+		{Category: DownloadCategoryTrack, ItemID: "not-a-number", Error: errFailed},
+	})
+
+	refs := (&ResumeState{Items: items}).refs()
+	assert.Equal(t, []string{"album:100", "artist:200", "track:301", "playlist:400", "track:501"}, refs)
+	assert.Len(t, items, 6)
+	assert.Equal(t, "connection reset", items[0].Error)
+}
+
+// TestSaveResumeState tests that failed items are saved and the state is removed after a clean resume.
+func TestSaveResumeState(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{ResumeStatePath: filepath.Join(t.TempDir(), "state", "resume.json")}
+
+	newService := func(t *testing.T) *ServiceImpl {
+		t.Helper()
+
+		impl, ok := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		return impl
+	}
+
+	// A run with errors saves them.
+	failedRun := newService(t)
+	failedRun.recordError(&DownloadError{
+		Category:  DownloadCategoryAlbum,
+		ItemID:    "100",
+		ItemTitle: "Mutter",
+		Phase:     "fetching metadata",
+		Error:     errors.New("timeout"),
+	})
+	failedRun.saveResumeState(context.Background())
+
+	state, err := loadResumeState(cfg.ResumeStatePath)
+	require.NoError(t, err)
+	require.Len(t, state.Items, 1)
+	assert.Equal(t, "album:100", state.Items[0].Ref)
+	assert.Equal(t, DownloadCategoryAlbum, state.Items[0].Category)
+	assert.Equal(t, "Mutter", state.Items[0].Title)
+	assert.False(t, state.SavedAt.IsZero())
+
+	// A regular run without errors keeps the state.
+	newService(t).saveResumeState(context.Background())
+	assert.FileExists(t, cfg.ResumeStatePath)
+
+	// A resume run without errors removes it.
+	resumeRun := newService(t)
+	resumeRun.isResuming = true
+	resumeRun.saveResumeState(context.Background())
+	assert.NoFileExists(t, cfg.ResumeStatePath)
+
+	// A missing state has nothing to resume.
+	state, err = loadResumeState(cfg.ResumeStatePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.refs())
+}
@@ -0,0 +1,147 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// RetagReport is the result of re-tagging a downloaded library.
+type RetagReport struct {
+	// FilesFound is the number of audio files found in the folder.
+	FilesFound int
+	// FilesRetagged is the number of files whose tags were rewritten.
+	FilesRetagged int
+	// Skipped lists the files left as they are, ordered by path.
+	Skipped []*LibraryFileProblem
+	// Failed lists the files whose tags could not be rewritten, ordered by path.
+	Failed []*LibraryFileProblem
+}
+
+// RetagLibrary rewrites the tags, covers, and lyrics of the audio files in the folder
+// from the metadata fetched by their TRACK_ID tags, without downloading the audio again.
+// Files saved with a playlist keep their playlist tags and track numbers.
+// Files without a track ID, and files of audiobooks and podcasts, are skipped.
+func (s *ServiceImpl) RetagLibrary(ctx context.Context, dir string) (*RetagReport, error) {
+	scan, err := scanLibrary(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RetagReport{
+		FilesFound: scan.filesFound,
+		Skipped:    scan.skipped,
+		Failed:     scan.failed,
+	}
+
+	metadata, err := s.fetchLibraryMetadata(ctx, scan.files)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if cleanupErr := s.covers.cleanup(); cleanupErr != nil {
+			logger.Warnf(ctx, "Failed to remove cached covers: %v", cleanupErr)
+		}
+	}()
+
+	for _, file := range scan.files {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item, reason := s.resolveLibraryTrack(file, metadata)
+		if item == nil {
+			report.Skipped = append(report.Skipped, &LibraryFileProblem{
+				Path:    file.path,
+				TrackID: file.trackID,
+				Reason:  reason,
+			})
+
+			continue
+		}
+
+		if err = s.retagLibraryTrack(ctx, item); err != nil {
+			report.Failed = append(report.Failed, &LibraryFileProblem{
+				Path:    file.path,
+				TrackID: file.trackID,
+				Reason:  err.Error(),
+			})
+
+			continue
+		}
+
+		report.FilesRetagged++
+	}
+
+	sortLibraryFileProblems(report.Skipped)
+	sortLibraryFileProblems(report.Failed)
+
+	return report, nil
+}
+
+// retagLibraryTrack rewrites the tags of the file from the fresh metadata.
+func (s *ServiceImpl) retagLibraryTrack(ctx context.Context, item *libraryTrack) error {
+	var (
+		file       = item.file
+		track      = item.task.track
+		collection = item.collection
+	)
+
+	if s.cfg.DryRun {
+		logger.Infof(ctx, "[DRY-RUN] Would rewrite the tags of '%s' as '%s - %s'",
+			file.path, item.trackTags[TagTrackArtist], track.Title)
+
+		return nil
+	}
+
+	trackLyrics := s.downloadAndSaveLyrics(ctx, track, filepath.Base(file.path), item.trackTags, collection)
+
+	isCoverEmbedded := (collection.category == DownloadCategoryAlbum || s.cfg.EmbedPlaylistCovers) &&
+		!s.isCoverEmbeddingDisabled()
+
+	var coverPath string
+	if isCoverEmbedded {
+		coverPath = s.getTrackAlbumCoverPath(ctx, item.task)
+	}
+
+	// The tags are written to a copy, so the file is left intact if writing fails.
+	tempPath := file.path + ".part"
+	if err := copyFile(file.path, tempPath); err != nil {
+		return fmt.Errorf("failed to copy the file: %w", err)
+	}
+
+	writeTagsRequest := &WriteTagsRequest{
+		TrackPath:                  tempPath,
+		CoverPath:                  coverPath,
+		Quality:                    file.quality,
+		TrackTags:                  item.trackTags,
+		TrackArtists:               parseArtistCredits(track.ArtistNames).all(),
+		TrackLyrics:                trackLyrics,
+		IsCoverEmbeddedToTrackTags: isCoverEmbedded,
+		IsExistingTagsReplaced:     true,
+		FLACPadding:                s.flacPadding(),
+	}
+
+	s.applyEmbeddedCover(writeTagsRequest)
+
+	err := s.writeTagsWithTimeout(ctx, writeTagsRequest)
+	if err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to write tags: %w", err)
+	}
+
+	if err = os.Rename(tempPath, file.path); err != nil {
+		_ = os.Remove(tempPath)
+
+		return fmt.Errorf("failed to replace the file: %w", err)
+	}
+
+	logger.Infof(ctx, "Tags of '%s' rewritten", file.path)
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// newTestRetagService creates a service writing tags with the real tag processor.
+func newTestRetagService(t *testing.T, cfg *config.Config) (*ServiceImpl, *mock_zvuk_client.MockClient) {
+	t.Helper()
+
+	cfg.ParsedLogLevel = logger.Level()
+	mockClient := mock_zvuk_client.NewMockClient(gomock.NewController(t))
+
+	service := NewService(cfg, mockClient, NewURLProcessor(), NewTemplateManager(t.Context(), cfg), NewTagProcessor())
+
+	impl, ok := service.(*ServiceImpl)
+	require.True(t, ok)
+
+	return impl, mockClient
+}
+
+// expectRetagMetadata sets up the metadata requests of the re-tagged files.
+func expectRetagMetadata(mockClient *mock_zvuk_client.MockClient) {
+	mockClient.EXPECT().GetTracksMetadata(gomock.Any(), gomock.Any()).Return(map[string]*zvuk.Track{
+		"101": {ID: 101, ReleaseID: 10, Title: "Sonne", ArtistNames: []string{"Rammstein"}, Position: 4},
+		"102": {ID: 102, ReleaseID: 10, Title: "Mutter", ArtistNames: []string{"Rammstein"}, Position: 9},
+	}, nil)
+	mockClient.EXPECT().GetAlbumsMetadata(gomock.Any(), []string{"10"}, false).
+		Return(&zvuk.GetAlbumsMetadataResponse{Releases: map[string]*zvuk.Release{
+			"10": {ID: 10, Title: "Mutter", Date: 20010402, TrackIDs: []int64{1, 2, 3, 101, 5, 6, 7, 8, 102, 10, 11}},
+		}}, nil)
+	mockClient.EXPECT().GetPlaylistsMetadata(gomock.Any(), []string{"500"}).
+		Return(&zvuk.GetPlaylistsMetadataResponse{Playlists: map[string]*zvuk.Playlist{
+			"500": {ID: 500, Title: "Favorites", TrackIDs: []int64{102, 101}},
+		}}, nil)
+}
+
+// TestRetagLibrary tests that the tags are replaced with fresh metadata, keeping the playlist track numbers.
+func TestRetagLibrary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var (
+		albumPath    = filepath.Join(dir, "04 - Sonne.flac")
+		playlistPath = filepath.Join(dir, "07 - Mutter.flac")
+		mp3Path      = filepath.Join(dir, "Sonne.mp3")
+		untaggedPath = filepath.Join(dir, "Unknown.flac")
+		brokenPath   = filepath.Join(dir, "Broken.flac")
+	)
+
+	writeTestFLAC(t, albumPath, 10, 20000, map[string]string{"TRACK_ID": "101", "TITLE": "Old title"})
+	writeTestFLAC(t, playlistPath, 10, 20000, map[string]string{
+		"TRACK_ID":    "102",
+		"PLAYLIST_ID": "500",
+		"TRACKNUMBER": "7",
+	})
+	writeTestMP3(t, mp3Path, 3, "Old title", "101")
+	writeTestFLAC(t, untaggedPath, 10, 20000, map[string]string{"TITLE": "Unknown"})
+
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(brokenPath, []byte("not a flac file"), constants.DefaultFilePermissions))
+
+	service, mockClient := newTestRetagService(t, &config.Config{OutputPath: dir})
+	expectRetagMetadata(mockClient)
+
+	report, err := service.RetagLibrary(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.FilesFound)
+	assert.Equal(t, 3, report.FilesRetagged)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, untaggedPath, report.Skipped[0].Path)
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, brokenPath, report.Failed[0].Path)
+
+	albumFile, issue := inspectLibraryFile(albumPath)
+	require.Nil(t, issue)
+	assert.Equal(t, "Sonne", albumFile.title)
+	assert.Equal(t, "10", albumFile.releaseID)
+	assert.Equal(t, int64(4), albumFile.trackNumber)
+
+	f, err := flac.ParseFile(albumPath)
+	require.NoError(t, err)
+
+	comment, err := flacvorbis.ParseFromMetaDataBlock(*f.Meta[1])
+	require.NoError(t, err)
+
+	titles, err := comment.Get("TITLE")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sonne"}, titles, "the old tags should be replaced, not kept")
+
+	playlistFile, issue := inspectLibraryFile(playlistPath)
+	require.Nil(t, issue)
+	assert.Equal(t, "Mutter", playlistFile.title)
+	assert.Equal(t, "500", playlistFile.playlistID)
+	assert.Equal(t, int64(7), playlistFile.trackNumber)
+
+	mp3File, issue := inspectLibraryFile(mp3Path)
+	require.Nil(t, issue)
+	assert.Equal(t, "Sonne", mp3File.title)
+	assert.Equal(t, "101", mp3File.trackID)
+	assert.Equal(t, int64(4), mp3File.trackNumber)
+
+	_, err = os.Stat(albumPath + ".part")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// TestRetagLibrary_DryRun tests that dry-run mode leaves the files as they are.
+func TestRetagLibrary_DryRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "04 - Sonne.flac")
+
+	writeTestFLAC(t, path, 10, 20000, map[string]string{"TRACK_ID": "101", "TITLE": "Old title"})
+
+	service, mockClient := newTestRetagService(t, &config.Config{OutputPath: dir, DryRun: true})
+	mockClient.EXPECT().GetTracksMetadata(gomock.Any(), []string{"101"}).Return(map[string]*zvuk.Track{
+		"101": {ID: 101, ReleaseID: 10, Title: "Sonne", ArtistNames: []string{"Rammstein"}, Position: 4},
+	}, nil)
+	mockClient.EXPECT().GetAlbumsMetadata(gomock.Any(), []string{"10"}, false).
+		Return(&zvuk.GetAlbumsMetadataResponse{Releases: map[string]*zvuk.Release{
+			"10": {ID: 10, Title: "Mutter", TrackIDs: []int64{101}},
+		}}, nil)
+
+	report, err := service.RetagLibrary(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.FilesRetagged)
+
+	file, issue := inspectLibraryFile(path)
+	require.Nil(t, issue)
+	assert.Equal(t, "Old title", file.title)
+}
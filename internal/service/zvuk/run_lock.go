@@ -0,0 +1,159 @@
+package zvuk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// runLockFilename is the name of the lock file created in the output path while a run is in progress.
+const runLockFilename = ".zvuk-grabber.lock"
+
+// runLock is the lock file that keeps two runs from writing to the same output path at once.
+type runLock struct {
+	// path is the location of the lock file.
+	path string
+}
+
+// runLockOwner describes the run holding a lock, as recorded in the lock file.
+type runLockOwner struct {
+	// pid is the process ID of the run.
+	pid int
+	// hostname is the host the run is executing on.
+	hostname string
+	// startedAt is the time the lock was acquired (zero if unknown).
+	startedAt time.Time
+}
+
+// acquireRunLock creates the lock file in the output path.
+// A lock left behind by a run that is no longer running on this host is taken over;
+// a lock held by a live run, or by a run on another host sharing the folder, is reported as ErrOutputPathLocked.
+func acquireRunLock(outputPath string) (*runLock, error) {
+	hostname, _ := os.Hostname() //nolint:errcheck // An unknown hostname only disables stale lock detection.
+	lock := &runLock{path: filepath.Join(outputPath, runLockFilename)}
+
+	content := fmt.Sprintf("%d\n%s\n%s\n", os.Getpid(), hostname, time.Now().Format(time.RFC3339))
+
+	for attempt := 0; ; attempt++ {
+		err := writeNewFile(lock.path, content)
+		if err == nil {
+			return lock, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		owner := readRunLockOwner(lock.path)
+
+		isStale := attempt == 0 &&
+			owner.pid > 0 &&
+			owner.hostname != "" &&
+			owner.hostname == hostname &&
+			!isProcessRunning(owner.pid)
+		if !isStale {
+			return nil, fmt.Errorf("%w by %s: pass --no-lock to ignore it or delete '%s' if no other run is active",
+				ErrOutputPathLocked, owner, lock.path)
+		}
+
+		if err = os.Remove(lock.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+}
+
+// release removes the lock file.
+func (l *runLock) release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// String describes the run holding the lock.
+func (o runLockOwner) String() string {
+	if o.pid <= 0 {
+		return "an unknown run"
+	}
+
+	description := "PID " + strconv.Itoa(o.pid)
+	if o.hostname != "" {
+		description += " on " + o.hostname
+	}
+
+	if !o.startedAt.IsZero() {
+		description += " since " + o.startedAt.Format(time.DateTime)
+	}
+
+	return description
+}
+
+// writeNewFile writes the content to a file that must not exist yet.
+func writeNewFile(path, content string) error {
+	//nolint:gosec // The lock file is created in the output path chosen by the user.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.DefaultFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.WriteString(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// readRunLockOwner reads the owner of a lock file, leaving unreadable fields empty.
+func readRunLockOwner(path string) runLockOwner {
+	var owner runLockOwner
+
+	content, err := os.ReadFile(path) //nolint:gosec // The lock file is in the output path chosen by the user.
+	if err != nil {
+		return owner
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	owner.pid, _ = strconv.Atoi(strings.TrimSpace(lines[0])) //nolint:errcheck // Unknown PIDs are handled as 0.
+
+	if len(lines) > 1 {
+		owner.hostname = strings.TrimSpace(lines[1])
+	}
+
+	if len(lines) > 2 {
+		owner.startedAt, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[2])) //nolint:errcheck // Optional.
+	}
+
+	return owner
+}
+
+// isProcessRunning reports whether a process with the PID is running on this host.
+// When it cannot be determined, the process is assumed to be running, so a live lock is never taken over.
+func isProcessRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		// On Windows, finding a process fails if it does not exist.
+		return false
+	}
+
+	// Signals other than kill are not supported on Windows, where a found process is running.
+	if runtime.GOOS == "windows" {
+		return true
+	}
+
+	err = process.Signal(syscall.Signal(0))
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
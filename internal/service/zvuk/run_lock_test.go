@@ -0,0 +1,110 @@
+package zvuk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestAcquireRunLock verifies that only one run at a time can hold the output path lock.
+func TestAcquireRunLock(t *testing.T) {
+	t.Parallel()
+
+	outputPath := t.TempDir()
+
+	lock, err := acquireRunLock(outputPath)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(outputPath, runLockFilename))
+
+	_, err = acquireRunLock(outputPath)
+	require.ErrorIs(t, err, ErrOutputPathLocked)
+	assert.Contains(t, err.Error(), fmt.Sprintf("PID %d", os.Getpid()))
+
+	require.NoError(t, lock.release())
+	assert.NoFileExists(t, filepath.Join(outputPath, runLockFilename))
+
+	lock, err = acquireRunLock(outputPath)
+	require.NoError(t, err)
+	require.NoError(t, lock.release())
+}
+
+// TestAcquireRunLock_StaleLock verifies that locks of finished runs are taken over only on the same host.
+func TestAcquireRunLock_StaleLock(t *testing.T) {
+	t.Parallel()
+
+	// A PID above the limit of every supported OS never belongs to a running process.
+	const finishedPID = 1 << 30
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	t.Run("finished run on this host", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := t.TempDir()
+		writeTestRunLock(t, outputPath, fmt.Sprintf("%d\n%s\n", finishedPID, hostname))
+
+		lock, acquireErr := acquireRunLock(outputPath)
+		require.NoError(t, acquireErr)
+		assert.Equal(t, os.Getpid(), readRunLockOwner(lock.path).pid)
+	})
+
+	t.Run("run on another host", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := t.TempDir()
+		writeTestRunLock(t, outputPath, fmt.Sprintf("%d\nanother-%s\n", finishedPID, hostname))
+
+		_, acquireErr := acquireRunLock(outputPath)
+		require.ErrorIs(t, acquireErr, ErrOutputPathLocked)
+	})
+
+	t.Run("unreadable lock", func(t *testing.T) {
+		t.Parallel()
+
+		outputPath := t.TempDir()
+		writeTestRunLock(t, outputPath, "garbage")
+
+		_, acquireErr := acquireRunLock(outputPath)
+		require.ErrorIs(t, acquireErr, ErrOutputPathLocked)
+		assert.Contains(t, acquireErr.Error(), "an unknown run")
+	})
+}
+
+// TestDownloadURLs_OutputPathLocked verifies that a run refused by the lock of another run records the conflict
+// as its error and leaves the lock of the other run in place.
+func TestDownloadURLs_OutputPathLocked(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t)
+	defer setup.cleanup()
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	content := fmt.Sprintf("%d\n%s\n", os.Getpid(), hostname)
+	writeTestRunLock(t, setup.tempDir, content)
+
+	setup.service.DownloadURLs(t.Context(), []string{"https://zvuk.com/track/123"})
+
+	require.ErrorIs(t, setup.service.RunError(), ErrOutputPathLocked)
+
+	lockContent, err := os.ReadFile(filepath.Join(setup.tempDir, runLockFilename))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(lockContent))
+}
+
+// writeTestRunLock creates a lock file with the given content in the output path.
+func writeTestRunLock(t *testing.T, outputPath, content string) {
+	t.Helper()
+
+	//nolint:gosec // It's a test file.
+	err := os.WriteFile(filepath.Join(outputPath, runLockFilename), []byte(content), constants.DefaultFilePermissions)
+	require.NoError(t, err)
+}
@@ -0,0 +1,84 @@
+package zvuk
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// startRunTimeLimit sets the time after which no new items or tracks are started (max_run_duration).
+func (s *ServiceImpl) startRunTimeLimit(startTime time.Time) {
+	if s.cfg.ParsedMaxRunDuration <= 0 {
+		return
+	}
+
+	s.runDeadline = startTime.Add(s.cfg.ParsedMaxRunDuration)
+}
+
+// isRunTimeLimitReached reports whether the run has used up max_run_duration.
+// The tracks in progress are finished, and the items that were not started are recorded
+// as failed, so the resume command can continue from there.
+func (s *ServiceImpl) isRunTimeLimitReached(ctx context.Context) bool {
+	if s.runDeadline.IsZero() || time.Now().Before(s.runDeadline) {
+		return false
+	}
+
+	if s.isRunTimeLimitLogged.CompareAndSwap(false, true) {
+		logger.Warnf(ctx, "Run time limit of %s is reached, finishing the tracks in progress and skipping the rest",
+			s.cfg.ParsedMaxRunDuration)
+	}
+
+	return true
+}
+
+// recordItemsNotStarted records the albums, playlists, audiobooks, podcasts, or artists
+// left out because the run time or API request limit was reached.
+func (s *ServiceImpl) recordItemsNotStarted(items []*DownloadItem) {
+	for _, item := range items {
+		s.recordError(&DownloadError{
+			Category:  item.Category,
+			ItemID:    item.ItemID,
+			ItemTitle: item.Category.ToTitleCase() + " ID: " + item.ItemID,
+			ItemURL:   item.URL,
+			Phase:     "waiting to download",
+			Error:     s.runLimitError(),
+		})
+	}
+}
+
+// recordTracksNotStarted records the tracks left out because the run time or API request limit was reached.
+// A collection is recorded once, so it is downloaded again as a whole and its saved tracks are skipped.
+func (s *ServiceImpl) recordTracksNotStarted(metadata *downloadTracksMetadata, trackIDs []int64) {
+	if collection := metadata.audioCollection; collection != nil {
+		if metadata.isCutShort.CompareAndSwap(false, true) {
+			s.recordError(&DownloadError{
+				Category:  collection.category,
+				ItemID:    collection.id,
+				ItemTitle: collection.title,
+				Phase:     "downloading tracks",
+				Error:     s.runLimitError(),
+			})
+		}
+
+		return
+	}
+
+	for _, trackID := range trackIDs {
+		trackIDString := strconv.FormatInt(trackID, 10)
+
+		title := "Track ID: " + trackIDString
+		if track := metadata.tracksMetadata[trackIDString]; track != nil {
+			title = track.Title
+		}
+
+		s.recordError(&DownloadError{
+			Category:  DownloadCategoryTrack,
+			ItemID:    trackIDString,
+			ItemTitle: title,
+			Phase:     "waiting to download",
+			Error:     s.runLimitError(),
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package zvuk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestRunTimeLimit tests that nothing new is started once max_run_duration is used up,
+// and that the items left out are recorded for the resume command.
+func TestRunTimeLimit(t *testing.T) {
+	t.Parallel()
+
+	newService := func(t *testing.T, maxRunDuration time.Duration) *ServiceImpl {
+		t.Helper()
+
+		impl, ok := NewService(&config.Config{
+			ParsedMaxRunDuration:   maxRunDuration,
+			MaxConcurrentDownloads: 1,
+		}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		// The run started long enough ago to use up any limit set here.
+		impl.startRunTimeLimit(time.Now().Add(-time.Hour))
+
+		return impl
+	}
+
+	t.Run("no limit", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, 0)
+		assert.False(t, impl.isRunTimeLimitReached(context.Background()))
+	})
+
+	t.Run("limit not reached", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, 2*time.Hour)
+		assert.False(t, impl.isRunTimeLimitReached(context.Background()))
+	})
+
+	t.Run("items are not started", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, time.Minute)
+
+		impl.downloadStandaloneItems(context.Background(), []*DownloadItem{
+			{Category: DownloadCategoryAlbum, ItemID: "100", URL: "https://zvuk.com/release/100"},
+			{Category: DownloadCategoryPlaylist, ItemID: "200", URL: "https://zvuk.com/playlist/200"},
+		})
+
+		errs := impl.Statistics().Errors
+		require.Len(t, errs, 2)
+		assert.ErrorIs(t, errs[0].Error, ErrRunTimeLimitReached)
+		assert.Equal(t, []string{"album:100", "playlist:200"},
+			(&ResumeState{Items: buildResumeItems(errs)}).refs())
+	})
+
+	t.Run("unfinished collection is recorded once", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, time.Minute)
+
+		impl.downloadTracks(context.Background(), &downloadTracksMetadata{
+			audioCollection: &audioCollection{
+				category: DownloadCategoryPlaylist,
+				id:       "300",
+				title:    "Mix",
+			},
+			category: DownloadCategoryPlaylist,
+			trackIDs: []int64{1, 2, 1},
+			tracksMetadata: map[string]*zvuk.Track{
+				"1": {ID: 1, Title: "First"},
+				"2": {ID: 2, Title: "Second"},
+			},
+		})
+
+		errs := impl.Statistics().Errors
+		require.Len(t, errs, 1)
+		assert.Equal(t, DownloadCategoryPlaylist, errs[0].Category)
+		assert.Equal(t, "300", errs[0].ItemID)
+		assert.Equal(t, int64(0), impl.Statistics().TotalTracksProcessed)
+	})
+
+	t.Run("standalone tracks are recorded one by one", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, time.Minute)
+
+		impl.downloadTracks(context.Background(), &downloadTracksMetadata{
+			category: DownloadCategoryTrack,
+			trackIDs: []int64{1, 2},
+			tracksMetadata: map[string]*zvuk.Track{
+				"1": {ID: 1, Title: "First"},
+			},
+		})
+
+		errs := impl.Statistics().Errors
+		require.Len(t, errs, 2)
+		assert.Equal(t, "First", errs[0].ItemTitle)
+		assert.Equal(t, "Track ID: 2", errs[1].ItemTitle)
+		assert.Equal(t, []string{"track:1", "track:2"}, (&ResumeState{Items: buildResumeItems(errs)}).refs())
+	})
+}
@@ -5,9 +5,11 @@ package zvuk
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
@@ -21,6 +23,34 @@ type Service interface {
 	DownloadURLs(ctx context.Context, urls []string)
 	// PrintDownloadSummary prints a formatted summary of download statistics.
 	PrintDownloadSummary(ctx context.Context)
+	// AbortReason returns the error that aborted the run in fail-fast mode, or nil.
+	AbortReason() error
+	// RunError returns the error that stopped the run before anything was downloaded, or nil.
+	RunError() error
+	// Statistics returns a consistent snapshot of the session statistics.
+	Statistics() *DownloadStatistics
+	// Summary returns the outcome of the run as the document printed by --json.
+	Summary(ctx context.Context) *JSONSummary
+	// PrintStatus prints a live snapshot of the current queue.
+	PrintStatus(ctx context.Context)
+	// UpgradeWatchedTracks downloads the watched tracks that have become available in FLAC.
+	UpgradeWatchedTracks(ctx context.Context)
+	// ResumeFailedItems downloads again the items that failed in the last run with errors.
+	ResumeFailedItems(ctx context.Context)
+	// SyncPlaylists downloads the tracks added to the playlists since their last sync.
+	SyncPlaylists(ctx context.Context, urls []string, deleteRemoved bool)
+	// WatchItems downloads the tracks added to the playlists and the releases added to the artists
+	// since the previous check, along with the other items.
+	WatchItems(ctx context.Context, urls []string, deleteRemoved bool)
+	// RetagLibrary rewrites the tags, covers, and lyrics of the downloaded files in the folder
+	// from fresh metadata, without downloading the audio again.
+	RetagLibrary(ctx context.Context, dir string) (*RetagReport, error)
+	// MigrateLibrary moves the downloaded files in the folder to the paths computed from the current templates.
+	MigrateLibrary(ctx context.Context, req *MigrateLibraryRequest) (*MigrateReport, error)
+	// SetDownloadSpeedLimit changes the download speed limit in bytes per second (0 is unlimited) during the run.
+	SetDownloadSpeedLimit(limit int64)
+	// SetMaxConcurrentDownloads changes the number of tracks downloaded at once during the run.
+	SetMaxConcurrentDownloads(limit int64)
 }
 
 // ServiceImpl implements audio download service with deduplication and metadata handling.
@@ -35,6 +65,22 @@ type ServiceImpl struct {
 	templateManager TemplateManager
 	// tagProcessor writes metadata tags to audio files.
 	tagProcessor TagProcessor
+	// loudnessNormalizer creates normalized copies of tracks (nil when normalization is disabled).
+	loudnessNormalizer LoudnessNormalizer
+	// flacEncoder re-encodes downloaded FLAC tracks (nil when flac_compression_level is not set).
+	flacEncoder FLACEncoder
+	// transcoder creates portable copies of tracks (nil when portable copies are disabled).
+	transcoder Transcoder
+	// remoteStorage receives the archives of completed collections (nil when uploads are disabled).
+	remoteStorage RemoteStorage
+	// rclone pushes the collections saved during the run to rclone_remote (nil when rclone is disabled).
+	rclone RcloneRunner
+	// rcloneTargets maps the local path of every folder or file to push with rclone to its target.
+	rcloneTargets map[string]*rcloneTarget
+	// rcloneMutex protects concurrent access to rcloneTargets.
+	rcloneMutex sync.Mutex
+	// portableTemplateManager generates filenames and folder names of portable copies.
+	portableTemplateManager TemplateManager
 	// audioCollections stores download collections indexed by item.
 	audioCollections map[ShortDownloadItem]*audioCollection
 	// audioCollectionsMutex protects concurrent access to audioCollections.
@@ -49,14 +95,87 @@ type ServiceImpl struct {
 	podcastHandler *PodcastCollectionHandler
 	// validator validates track constraints.
 	validator *TrackValidator
-	// stats tracks download statistics for the current session.
-	stats *DownloadStatistics
-	// statsMutex protects concurrent access to statistics.
-	statsMutex sync.Mutex
+	// stats collects download statistics for the current session.
+	stats *statsCollector
+	// status keeps the live queue state shown by PrintStatus.
+	status *downloadStatusTracker
+	// downloader saves track audio, covers, lyrics, and descriptions.
+	downloader Downloader
+	// covers keeps the cover images downloaded during the run for reuse.
+	covers *coverCache
+	// upgradeWatch lists the tracks saved below FLAC (nil when the watch list is disabled).
+	upgradeWatch *upgradeWatchList
+	// isUpgradingWatchedTracks indicates that the run re-downloads watched tracks, which replace their files in place.
+	isUpgradingWatchedTracks bool
+	// history records every saved track (nil when history_path is not set).
+	history *downloadHistory
+	// existingLibrary indexes the tracks of existing_library_paths (nil when none are configured).
+	existingLibrary *existingLibrary
+	// trackPicker lets the user choose the tracks of albums and playlists (nil without --interactive).
+	trackPicker *trackPicker
+	// jsonOutput receives the summary as a JSON document (nil without --json).
+	jsonOutput io.Writer
+	// progressEvents prints one line per track event (nil unless the progress is plain).
+	progressEvents *trackEventPrinter
+	// dryRunPlan prints the tracks of every collection in dry-run mode (nil unless it is printed).
+	dryRunPlan *dryRunPlanPrinter
+	// isResuming indicates that the run replays the resume state, which is removed once nothing fails.
+	isResuming bool
+	// runDeadline is the time after which no new items or tracks are started (zero without max_run_duration).
+	runDeadline time.Time
+	// isRunTimeLimitLogged indicates that reaching the run time limit was reported.
+	isRunTimeLimitLogged atomic.Bool
+	// isAPIQuotaLogged indicates that reaching max_api_requests was reported, stopping the run.
+	isAPIQuotaLogged atomic.Bool
+	// apiQuotaResumeTime is when the tracks paused by max_api_requests resume
+	// (zero while requests are allowed), protected by apiQuotaMutex.
+	apiQuotaResumeTime time.Time
+	// apiQuotaRequestsBefore is the number of requests sent before the last pause, protected by apiQuotaMutex.
+	apiQuotaRequestsBefore int64
+	// apiQuotaMutex protects apiQuotaResumeTime and apiQuotaRequestsBefore.
+	apiQuotaMutex sync.Mutex
+	// downloadWindowResumeTime is when the tracks waiting for the next download window resume
+	// (zero while downloads are allowed), protected by downloadWindowMutex.
+	downloadWindowResumeTime time.Time
+	// downloadWindowMutex protects downloadWindowResumeTime.
+	downloadWindowMutex sync.Mutex
+	// maxConcurrentDownloads is the number of tracks downloaded at once, changed during the run by reload_config
+	// (0 until set, falling back to max_concurrent_downloads).
+	maxConcurrentDownloads atomic.Int64
+	// politeSlots limits the tracks downloaded at once during polite_hours (nil when polite_hours is not set).
+	politeSlots chan struct{}
+	// isPoliteMode indicates that the polite mode was on at the last check, so its switches are logged once.
+	isPoliteMode atomic.Bool
+	// playlistSyncs maps the ID of every playlist synced during the run to its sync (nil outside the sync command).
+	playlistSyncs map[string]*playlistSync
+	// artistWatches maps the ID of every artist watched during the run to its watch (nil outside the watch command).
+	artistWatches map[string]*artistWatch
+	// artistCheckpoints maps the ID of every artist downloaded during the run to its checkpoint
+	// (nil when artist_checkpoints is off), protected by artistCheckpointsMutex.
+	artistCheckpoints map[string]*artistCheckpoint
+	// artistCheckpointsMutex protects artistCheckpoints.
+	artistCheckpointsMutex sync.Mutex
+	// discographies maps the ID of every artist downloaded during the run to its discography
+	// (nil when write_discography_playlists is off), protected by discographiesMutex.
+	discographies map[string]*artistDiscography
+	// discographiesMutex protects discographies.
+	discographiesMutex sync.Mutex
 	// filePathLocks serializes writes to the same destination path.
 	filePathLocks map[string]*pathLock
 	// filePathLocksMutex protects concurrent access to filePathLocks.
 	filePathLocksMutex sync.Mutex
+	// claimedPaths maps a lowercased destination path to the item saved there during the run.
+	claimedPaths map[string]claimedPath
+	// claimedPathsMutex protects concurrent access to claimedPaths.
+	claimedPathsMutex sync.Mutex
+	// failFastCancel cancels the whole run in fail-fast mode (nil when fail-fast is disabled).
+	failFastCancel context.CancelCauseFunc
+	// abortReason is the first error recorded in fail-fast mode, protected by abortMutex.
+	abortReason error
+	// runError is the error that stopped the run before anything was downloaded, protected by abortMutex.
+	runError error
+	// abortMutex protects failFastCancel, abortReason, and runError.
+	abortMutex sync.Mutex
 }
 
 // NewService creates a download service instance with dependency-injected components.
@@ -80,30 +199,187 @@ func NewService(
 		audiobookHandler:      NewAudiobookCollectionHandler(templateManager),
 		podcastHandler:        NewPodcastCollectionHandler(templateManager),
 		validator:             NewTrackValidator(cfg),
-		stats:                 new(DownloadStatistics),
+		stats:                 newStatsCollector(),
+		status:                newDownloadStatusTracker(),
+		downloader:            NewDownloader(cfg),
+		covers:                newCoverCache(),
 		filePathLocks:         make(map[string]*pathLock),
 	}
 
+	s.maxConcurrentDownloads.Store(cfg.MaxConcurrentDownloads)
+
+	// Single tracks are named before download, so the album handler must join artists the same way.
+	s.albumHandler.ArtistJoinStyle = cfg.ArtistJoinStyle
+
+	if len(cfg.ParsedPoliteHours) > 0 {
+		s.politeSlots = make(chan struct{}, max(cfg.PoliteMaxConcurrentDownloads, 1))
+	}
+
+	if cfg.NormalizeLoudness {
+		s.loudnessNormalizer = NewLoudnessNormalizer(cfg)
+	}
+
+	if cfg.FLACCompressionLevel != "" {
+		s.flacEncoder = NewFLACEncoder(cfg)
+	}
+
+	if cfg.PortableOutputPath != "" {
+		s.transcoder = NewTranscoder(cfg)
+		s.portableTemplateManager = newPortableTemplateManager(cfg)
+	}
+
+	if cfg.RemoteStorage != "" {
+		s.remoteStorage = NewRemoteStorage(cfg)
+	}
+
+	if cfg.RcloneRemote != "" {
+		s.rclone = NewRcloneRunner(cfg)
+	}
+
+	if cfg.UpgradeWatchPath != "" {
+		s.upgradeWatch = newUpgradeWatchList(cfg.UpgradeWatchPath)
+	}
+
+	if cfg.HistoryPath != "" {
+		s.history = newDownloadHistory(cfg.HistoryPath)
+	}
+
+	if cfg.JSONOutput {
+		s.jsonOutput = os.Stdout
+	}
+
+	if cfg.Progress == config.ProgressPlain {
+		// The events replace the progress bars, which are drawn on standard error.
+		s.progressEvents = newTrackEventPrinter(os.Stderr)
+	}
+
+	if isDryRunPlanPrinted(cfg) {
+		s.dryRunPlan = newDryRunPlanPrinter(os.Stdout, cfg.DryRunFormat)
+	}
+
+	if cfg.Interactive {
+		// Standard output is kept for the JSON document.
+		pickerOutput := io.Writer(os.Stdout)
+		if cfg.JSONOutput {
+			pickerOutput = os.Stderr
+		}
+
+		s.trackPicker = newTrackPicker(os.Stdin, pickerOutput)
+	}
+
 	return s
 }
 
 // DownloadURLs orchestrates the full download pipeline, from URL processing to file creation.
 func (s *ServiceImpl) DownloadURLs(ctx context.Context, urls []string) {
 	// Record start time and dry-run mode for statistics.
-	s.statsMutex.Lock()
-	s.stats.StartTime = time.Now()
-	s.stats.IsDryRun = s.cfg.DryRun
-	s.statsMutex.Unlock()
-
-	// Ensure the output directory exists (skip in dry-run mode).
-	if !s.cfg.DryRun {
-		err := os.MkdirAll(s.cfg.OutputPath, defaultFolderPermissions)
+	startTime := time.Now()
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.StartTime = startTime
+		stats.IsDryRun = s.cfg.DryRun
+	})
+
+	// Stop starting new tracks once the run has used up max_run_duration.
+	s.startRunTimeLimit(startTime)
+
+	// In fail-fast mode the first recorded error cancels everything that is still running.
+	if s.cfg.FailFast {
+		var cancel context.CancelCauseFunc
+
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		s.abortMutex.Lock()
+		s.failFastCancel = cancel
+		s.abortMutex.Unlock()
+	}
+
+	// Ensure the output directory exists and is writable before any network work.
+	if err := s.prepareOutputPath(ctx); err != nil {
+		s.stopRun(ctx, fmt.Sprintf("Output path '%s' cannot be used", s.cfg.OutputPath), err)
+		return
+	}
+
+	// Keep other runs from writing the same temporary files, covers, and state files.
+	if !s.cfg.NoLock && !s.cfg.DryRun {
+		lock, err := acquireRunLock(s.cfg.OutputPath)
 		if err != nil {
-			logger.Errorf(ctx, "Failed to create output path: %v", err)
+			s.stopRun(ctx, fmt.Sprintf("Output path '%s' cannot be used", s.cfg.OutputPath), err)
 			return
 		}
-	} else {
-		logger.Infof(ctx, "[DRY-RUN] Would create output directory: %s", s.cfg.OutputPath)
+
+		defer func() {
+			if err = lock.release(); err != nil {
+				logger.Warnf(ctx, "Failed to remove lock file: %v", err)
+			}
+		}()
+	}
+
+	// Save the failed items for the resume command once the run is over.
+	defer s.saveResumeState(ctx)
+
+	// Push what the run saved once every download is over, while the output path is still locked.
+	defer s.runRcloneTargets(ctx)
+
+	// Save what the synced playlists fetched, including the tracks saved before an interruption.
+	defer s.savePlaylistSyncStates(ctx)
+
+	// Save the releases of the watched artists, once the errors of the run are known.
+	defer s.saveArtistWatchStates(ctx)
+
+	// Forget the checkpoints of the artists downloaded in full.
+	defer s.removeCompletedArtistCheckpoints(ctx)
+
+	// List the saved releases of every downloaded artist once all of them are saved.
+	defer s.writeArtistDiscographies(ctx)
+
+	// Fail before downloading anything if normalized copies cannot be produced.
+	if err := s.checkLoudnessNormalizer(); err != nil {
+		s.stopRun(ctx, "Loudness normalization cannot be used", err)
+		return
+	}
+
+	// Fail before downloading anything if FLAC tracks cannot be re-encoded.
+	if err := s.checkFLACEncoder(); err != nil {
+		s.stopRun(ctx, "FLAC re-encoding cannot be used", err)
+		return
+	}
+
+	// Fail before downloading anything if portable copies cannot be produced.
+	if err := s.checkTranscoder(); err != nil {
+		s.stopRun(ctx, "Portable copies cannot be used", err)
+		return
+	}
+
+	// Fail before downloading anything if the saved collections cannot be pushed.
+	if err := s.checkRclone(); err != nil {
+		s.stopRun(ctx, "Rclone cannot be used", err)
+		return
+	}
+
+	if err := s.checkRemoteStorage(); err != nil {
+		s.stopRun(ctx, "Remote storage cannot be used", err)
+		return
+	}
+
+	// Fail before downloading anything if the upgrade watch list cannot be read.
+	if err := s.loadUpgradeWatch(); err != nil {
+		s.stopRun(ctx, "Upgrade watch list cannot be used", err)
+		return
+	}
+
+	defer s.saveUpgradeWatch(ctx)
+
+	// Fail before downloading anything if the tracks saved by earlier runs cannot be told apart.
+	if err := s.loadDownloadHistory(ctx); err != nil {
+		s.stopRun(ctx, "Download history cannot be used", err)
+		return
+	}
+
+	if err := s.scanExistingLibraries(ctx); err != nil {
+		s.stopRun(ctx, "Existing library cannot be used", err)
+		return
 	}
 
 	// Verify the user's subscription status before proceeding.
@@ -112,12 +388,18 @@ func (s *ServiceImpl) DownloadURLs(ctx context.Context, urls []string) {
 	// Extract and categorize download items from the provided URLs.
 	downloadItemsByCategories, err := s.urlProcessor.ExtractDownloadItems(ctx, urls)
 	if err != nil {
-		logger.Errorf(ctx, "Failed to extract items to download: %v", err)
+		s.stopRun(ctx, "Failed to extract items to download", err)
 		return
 	}
 
 	logger.Info(ctx, "Starting download process")
 
+	defer func() {
+		if err := s.covers.cleanup(); err != nil {
+			logger.Warnf(ctx, "Failed to remove cached covers: %v", err)
+		}
+	}()
+
 	// Process albums and playlists first to maintain organizational structure.
 	standaloneItems := s.fetchAndDeduplicateStandaloneItems(ctx, downloadItemsByCategories)
 	if len(standaloneItems) > 0 {
@@ -132,9 +414,36 @@ func (s *ServiceImpl) DownloadURLs(ctx context.Context, urls []string) {
 	logger.Info(ctx, "Download process completed")
 
 	// Record end time for statistics.
-	s.statsMutex.Lock()
-	s.stats.EndTime = time.Now()
-	s.statsMutex.Unlock()
+	s.stats.update(func(stats *DownloadStatistics) { stats.EndTime = time.Now() })
+}
+
+// AbortReason returns the error that aborted the run in fail-fast mode, or nil.
+func (s *ServiceImpl) AbortReason() error {
+	s.abortMutex.Lock()
+	defer s.abortMutex.Unlock()
+
+	return s.abortReason
+}
+
+// RunError returns the error that stopped the run before anything was downloaded, or nil.
+func (s *ServiceImpl) RunError() error {
+	s.abortMutex.Lock()
+	defer s.abortMutex.Unlock()
+
+	return s.runError
+}
+
+// stopRun logs the error that stops the run before anything is downloaded and keeps the first one for RunError,
+// so the run does not end as a success.
+func (s *ServiceImpl) stopRun(ctx context.Context, reason string, err error) {
+	logger.Errorf(ctx, "%s: %v", reason, err)
+
+	s.abortMutex.Lock()
+	defer s.abortMutex.Unlock()
+
+	if s.runError == nil {
+		s.runError = err
+	}
 }
 
 // fetchAndDeduplicateStandaloneItems processes artist URLs to fetch their albums and removes duplicate entries.
@@ -170,6 +479,12 @@ func (s *ServiceImpl) downloadStandaloneItems(ctx context.Context, items []*Down
 		default:
 		}
 
+		if s.isRunLimitReached(ctx) {
+			s.recordItemsNotStarted(items[index:])
+
+			return
+		}
+
 		// Check if the category is supported for downloading.
 		if !item.Category.IsSupported() {
 			logger.Errorf(ctx, "Unknown URL category: %d", item.Category)
@@ -179,7 +494,8 @@ func (s *ServiceImpl) downloadStandaloneItems(ctx context.Context, items []*Down
 		// Download the collection.
 		logger.Infof(ctx, "Downloading item: %v (%d / %d)", item, index+1, itemsCount)
 
-		s.downloadCollection(ctx, item)
+		s.downloadCollection(withItemDownloadContext(ctx, item), item)
+		s.checkpointRelease(ctx, item)
 	}
 }
 
@@ -187,11 +503,20 @@ func (s *ServiceImpl) downloadStandaloneItems(ctx context.Context, items []*Down
 func (s *ServiceImpl) downloadTrackItems(ctx context.Context, items []*DownloadItem) {
 	logger.Info(ctx, "Downloading tracks")
 
+	if s.isRunLimitReached(ctx) {
+		s.recordItemsNotStarted(items)
+
+		return
+	}
+
 	trackIDsToFetch, numericTrackIDs := s.prepareStandaloneTrackIDs(ctx, items)
 	if len(trackIDsToFetch) == 0 {
 		return
 	}
 
+	stopMetadataTimer := s.startPhaseTimer(DownloadPhaseMetadata)
+	defer stopMetadataTimer()
+
 	// Fetch metadata for the tracks.
 	tracksMetadata, err := s.zvukClient.GetTracksMetadata(ctx, trackIDsToFetch)
 	if err != nil {
@@ -210,6 +535,8 @@ func (s *ServiceImpl) downloadTrackItems(ctx context.Context, items []*DownloadI
 		return
 	}
 
+	stopMetadataTimer()
+
 	// Prepare metadata for downloading the tracks.
 	metadata := &downloadTracksMetadata{
 		category:       DownloadCategoryTrack,
@@ -250,7 +577,7 @@ func (s *ServiceImpl) prepareStandaloneTrackIDs(ctx context.Context, items []*Do
 			logger.Infof(ctx,
 				"Track ID '%s' is already covered by previously processed collections, skipping standalone download",
 				trackIDString)
-			s.incrementTrackSkipped(SkipReasonExists)
+			s.incrementTrackSkipped(trackIDString, SkipReasonExists)
 
 			continue
 		}
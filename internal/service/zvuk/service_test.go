@@ -6,30 +6,37 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
 	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
 	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
 
 var (
 	// errUnauthorizedTest simulates an invalid-token error response from the API.
-	errUnauthorizedTest = errors.New("unauthorized: invalid token")
+	errUnauthorizedTest = fmt.Errorf("%w: invalid token", zvuk.ErrAuthTokenRejected)
+	// errNetworkTest simulates a failed connection to the API.
+	errNetworkTest = errors.New("dial tcp: connection refused")
 
 	// fatalExitMu is a helper mutex so that parallel tests don't clobber each other.
 	fatalExitMu sync.Mutex
 )
 
-// assertFatalExit runs fn and asserts that the custom fatal handler would exit the process.
-func assertFatalExit(t *testing.T, fn func()) {
+// assertFatalExit runs fn and asserts that the custom fatal handler would exit the process with the code.
+func assertFatalExit(t *testing.T, code int, fn func()) {
 	t.Helper()
 
 	fatalExitMu.Lock()
@@ -40,7 +47,7 @@ func assertFatalExit(t *testing.T, fn func()) {
 	})
 	defer logger.SetFatalHandler(nil)
 
-	assert.PanicsWithValue(t, "fatal-exit-1", fn)
+	assert.PanicsWithValue(t, fmt.Sprintf("fatal-exit-%d", code), fn)
 }
 
 // mockURLProcessor is a mock implementation of the URLProcessor interface.
@@ -113,6 +120,16 @@ func (m *mockTemplateManager) GetPodcastEpisodeFilename(
 	return tags["trackNumberPad"] + " - " + tags["trackTitle"]
 }
 
+// GetLyricsFilename names lyrics after the track file, like the default template.
+func (m *mockTemplateManager) GetLyricsFilename(_ context.Context, tags map[string]string) string {
+	return tags[TagTrackFilename]
+}
+
+// GetDescriptionFilename returns the default description filename.
+func (m *mockTemplateManager) GetDescriptionFilename(_ context.Context, _ map[string]string) string {
+	return config.DefaultDescriptionFilenameTemplate
+}
+
 // mockTagProcessor is a mock implementation of the TagProcessor interface.
 type mockTagProcessor struct{}
 
@@ -298,6 +315,152 @@ func TestServiceImpl_DownloadURLs_NilURLs(t *testing.T) {
 	service.DownloadURLs(ctx, urls)
 }
 
+// failingURLProcessor is a URL processor that cannot extract anything.
+type failingURLProcessor struct {
+	mockURLProcessor
+}
+
+// ExtractDownloadItems always fails.
+func (m *failingURLProcessor) ExtractDownloadItems(
+	_ context.Context,
+	_ []string,
+) (*ExtractDownloadItemsResponse, error) {
+	return nil, errNetworkTest
+}
+
+// TestDownloadURLs_StopRun tests that every check failing before the download records the error of the run.
+//
+//nolint:paralleltest // Cannot run in parallel due to the environment variables.
+func TestDownloadURLs_StopRun(t *testing.T) {
+	// No external tool can be found.
+	t.Setenv("PATH", "")
+
+	tests := []struct {
+		name         string
+		configure    func(t *testing.T, cfg *config.Config)
+		urlProcessor URLProcessor
+		wantErr      error
+	}{
+		{
+			name: "output path is a file",
+			configure: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.OutputPath = filepath.Join(cfg.OutputPath, "file")
+				require.NoError(t, os.WriteFile(cfg.OutputPath, nil, constants.DefaultFilePermissions))
+			},
+			wantErr: ErrOutputPathNotDirectory,
+		},
+		{
+			name: "output path is locked",
+			configure: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				hostname, err := os.Hostname()
+				require.NoError(t, err)
+				writeTestRunLock(t, cfg.OutputPath, fmt.Sprintf("%d\n%s\n", os.Getpid(), hostname))
+			},
+			wantErr: ErrOutputPathLocked,
+		},
+		{
+			name:      "ffmpeg is missing for loudness normalization",
+			configure: func(_ *testing.T, cfg *config.Config) { cfg.NormalizeLoudness = true },
+			wantErr:   exec.ErrNotFound,
+		},
+		{
+			name:      "ffmpeg is missing for FLAC re-encoding",
+			configure: func(_ *testing.T, cfg *config.Config) { cfg.FLACCompressionLevel = "8" },
+			wantErr:   exec.ErrNotFound,
+		},
+		{
+			name: "ffmpeg is missing for portable copies",
+			configure: func(_ *testing.T, cfg *config.Config) {
+				cfg.PortableOutputPath = filepath.Join(cfg.OutputPath, "portable")
+			},
+			wantErr: exec.ErrNotFound,
+		},
+		{
+			name:      "rclone is missing",
+			configure: func(_ *testing.T, cfg *config.Config) { cfg.RcloneRemote = "remote:music" },
+			wantErr:   exec.ErrNotFound,
+		},
+		{
+			name: "sftp is missing",
+			configure: func(_ *testing.T, cfg *config.Config) {
+				cfg.RemoteStorage = config.RemoteStorageSFTP
+				cfg.RemoteURL = "sftp://nas/music"
+			},
+			wantErr: exec.ErrNotFound,
+		},
+		{
+			name: "upgrade watch list is damaged",
+			configure: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.UpgradeWatchPath = filepath.Join(t.TempDir(), "upgrade-watch.json")
+				require.NoError(t, os.WriteFile(cfg.UpgradeWatchPath, []byte("{"), constants.DefaultFilePermissions))
+			},
+		},
+		{
+			name: "download history cannot be read",
+			configure: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.HistoryPath = t.TempDir()
+				cfg.SkipDownloadedTracks = true
+			},
+		},
+		{
+			name: "existing library is missing",
+			configure: func(t *testing.T, cfg *config.Config) {
+				t.Helper()
+
+				cfg.ExistingLibraryPaths = []string{filepath.Join(t.TempDir(), "missing")}
+			},
+			wantErr: os.ErrNotExist,
+		},
+		{
+			name:         "URLs cannot be extracted",
+			configure:    func(_ *testing.T, _ *config.Config) {},
+			urlProcessor: new(failingURLProcessor),
+			wantErr:      errNetworkTest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_zvuk_client.NewMockClient(ctrl)
+			mockClient.EXPECT().GetUserProfile(gomock.Any()).Return(&zvuk.UserProfile{
+				Subscription: &zvuk.UserSubscription{Title: "Premium"},
+			}, nil).AnyTimes()
+			mockClient.EXPECT().GetAPIStatistics().Return(&zvuk.APIStatistics{}).AnyTimes()
+
+			cfg := &config.Config{OutputPath: t.TempDir(), FFmpegPath: "ffmpeg", RclonePath: "rclone"}
+			tt.configure(t, cfg)
+
+			urlProcessor := tt.urlProcessor
+			if urlProcessor == nil {
+				urlProcessor = new(mockURLProcessor)
+			}
+
+			service := NewService(cfg, mockClient, urlProcessor, new(mockTemplateManager), new(mockTagProcessor))
+			service.DownloadURLs(t.Context(), []string{"https://zvuk.com/track/123"})
+
+			err := service.RunError()
+			require.Error(t, err)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+
+			summary := service.Summary(t.Context())
+			assert.Equal(t, JSONSummaryStatusFailed, summary.Status)
+			assert.NotEmpty(t, summary.Error)
+		})
+	}
+}
+
 // TestDownloadURLs_Integration_FullPipeline tests the full download pipeline with mocked client responses.
 func TestDownloadURLs_Integration_FullPipeline(t *testing.T) {
 	t.Parallel()
@@ -394,36 +557,49 @@ func TestDownloadURLs_Integration_FullPipeline(t *testing.T) {
 	service.DownloadURLs(ctx, urls)
 }
 
-// TestDownloadURLs_InvalidToken verifies fatal handling triggered by invalid authentication tokens.
+// TestDownloadURLs_InvalidToken verifies that a rejected token, unlike a network failure, exits with the auth code.
 func TestDownloadURLs_InvalidToken(t *testing.T) {
 	t.Parallel()
 
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	tests := []struct {
+		name         string
+		profileErr   error
+		expectedCode int
+	}{
+		{name: "rejected token", profileErr: errUnauthorizedTest, expectedCode: constants.ExitCodeAuthFailure},
+		{name: "network failure", profileErr: errNetworkTest, expectedCode: constants.ExitCodeFailure},
+	}
 
-	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	cfg := &config.Config{
-		OutputPath:             t.TempDir(),
-		MaxConcurrentDownloads: 1,
-	}
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 
-	mockClient := mock_zvuk_client.NewMockClient(ctrl)
-	mockURLProcessor := NewURLProcessor()
-	mockTemplateManager := NewTemplateManager(ctx, cfg)
-	mockTagProcessor := NewTagProcessor()
+			ctx := context.Background()
 
-	urls := []string{"https://zvuk.com/track/123"}
+			cfg := &config.Config{
+				OutputPath:             t.TempDir(),
+				MaxConcurrentDownloads: 1,
+			}
 
-	mockClient.EXPECT().GetUserProfile(gomock.Any()).Return(nil, errUnauthorizedTest)
+			mockClient := mock_zvuk_client.NewMockClient(ctrl)
+			mockURLProcessor := NewURLProcessor()
+			mockTemplateManager := NewTemplateManager(ctx, cfg)
+			mockTagProcessor := NewTagProcessor()
 
-	service := NewService(cfg, mockClient, mockURLProcessor, mockTemplateManager, mockTagProcessor)
+			urls := []string{"https://zvuk.com/track/123"}
 
-	t.Helper()
+			mockClient.EXPECT().GetUserProfile(gomock.Any()).Return(nil, tt.profileErr)
 
-	assertFatalExit(t, func() {
-		service.DownloadURLs(ctx, urls)
-	})
+			service := NewService(cfg, mockClient, mockURLProcessor, mockTemplateManager, mockTagProcessor)
+
+			assertFatalExit(t, tt.expectedCode, func() {
+				service.DownloadURLs(ctx, urls)
+			})
+		})
+	}
 }
 
 // TestDownloadURLs_ExpiredSubscription ensures the service exits when subscription data is missing.
@@ -451,7 +627,7 @@ func TestDownloadURLs_ExpiredSubscription(t *testing.T) {
 
 	service := NewService(cfg, mockClient, mockURLProcessor, mockTemplateManager, mockTagProcessor)
 
-	assertFatalExit(t, func() {
+	assertFatalExit(t, constants.ExitCodeAuthFailure, func() {
 		service.DownloadURLs(context.Background(), urls)
 	})
 }
@@ -720,3 +896,41 @@ func TestDownloadURLs_SpeedLimiting(t *testing.T) {
 	// Note: Actual timing may vary because mocks are fast, but the throttling logic should still execute.
 	assert.GreaterOrEqual(t, duration, 1*time.Second, "Download should show some evidence of throttling")
 }
+
+// TestRecordError_FailFast tests that the first hard error cancels the run while skips do not.
+func TestRecordError_FailFast(t *testing.T) {
+	t.Parallel()
+
+	service := NewService(&config.Config{FailFast: true}, nil, nil, nil, nil)
+
+	impl, ok := service.(*ServiceImpl)
+	assert.True(t, ok, "Service should be of type *ServiceImpl")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	impl.failFastCancel = cancel
+
+	// Tracks skipped by filters are expected and must not abort the run.
+	impl.handleTrackSkipped(SkipReasonQuality, "FLAC", &DownloadError{
+		Category: DownloadCategoryTrack,
+		ItemID:   "1",
+		Phase:    "quality check",
+		Error:    ErrQualityBelowThreshold,
+	})
+
+	assert.NoError(t, ctx.Err(), "Skipped tracks should not cancel the run")
+	assert.NoError(t, service.AbortReason())
+
+	impl.recordError(&DownloadError{
+		Category: DownloadCategoryTrack,
+		ItemID:   "2",
+		Phase:    "downloading file",
+		Error:    ErrIncompleteDownload,
+	})
+
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "First hard error should cancel the run")
+	assert.ErrorIs(t, service.AbortReason(), ErrIncompleteDownload)
+	assert.ErrorIs(t, context.Cause(ctx), ErrIncompleteDownload)
+	assert.Len(t, impl.Statistics().Errors, 2, "Both entries should still be reported")
+}
@@ -36,11 +36,11 @@ func TestDownloadTrackItems_RecordsMetadataFetchError(t *testing.T) {
 		},
 	})
 
-	require.Len(t, impl.stats.Errors, 1)
-	assert.Equal(t, DownloadCategoryTrack, impl.stats.Errors[0].Category)
-	assert.Equal(t, trackID, impl.stats.Errors[0].ItemID)
-	assert.Equal(t, "fetching track metadata", impl.stats.Errors[0].Phase)
-	assert.ErrorIs(t, impl.stats.Errors[0].Error, errTrackMetadataFetch)
+	require.Len(t, impl.Statistics().Errors, 1)
+	assert.Equal(t, DownloadCategoryTrack, impl.Statistics().Errors[0].Category)
+	assert.Equal(t, trackID, impl.Statistics().Errors[0].ItemID)
+	assert.Equal(t, "fetching track metadata", impl.Statistics().Errors[0].Phase)
+	assert.ErrorIs(t, impl.Statistics().Errors[0].Error, errTrackMetadataFetch)
 }
 
 func TestDownloadTrackItems_SkipsTracksCoveredByRegisteredCollections(t *testing.T) {
@@ -78,8 +78,8 @@ func TestDownloadTrackItems_SkipsTracksCoveredByRegisteredCollections(t *testing
 		},
 	})
 
-	assert.Equal(t, int64(1), impl.stats.TotalTracksProcessed)
-	assert.Equal(t, int64(1), impl.stats.TracksSkipped)
-	assert.Equal(t, int64(1), impl.stats.TracksSkippedExists)
-	assert.Empty(t, impl.stats.Errors)
+	assert.Equal(t, int64(1), impl.Statistics().TotalTracksProcessed)
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkipped)
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkippedExists)
+	assert.Empty(t, impl.Statistics().Errors)
 }
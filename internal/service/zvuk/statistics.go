@@ -1,10 +1,13 @@
 package zvuk
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -15,8 +18,22 @@ import (
 const (
 	// unknownParentKey is used as a fallback key when parent collection is unknown.
 	unknownParentKey = "unknown"
+	// summaryCollectionUsageLimit is the number of the largest collections whose size is shown in the summary.
+	summaryCollectionUsageLimit = 10
 )
 
+// projectedMP3BitratesKbps lists constant-bitrate MP3 qualities used for dry-run size projections,
+// from the highest to the lowest bitrate.
+//
+//nolint:gochecknoglobals // It is a static lookup table.
+var projectedMP3BitratesKbps = []struct {
+	quality     TrackQuality
+	bitrateKbps int64
+}{
+	{quality: TrackQualityMP3High, bitrateKbps: 320},
+	{quality: TrackQualityMP3Mid, bitrateKbps: 128},
+}
+
 // formatDuration formats a duration into a human-readable string.
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
@@ -38,72 +55,270 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%ds", seconds)
 }
 
-// incrementTrackDownloaded atomically increments the downloaded tracks counter and adds bytes.
-func (s *ServiceImpl) incrementTrackDownloaded(bytes int64) {
-	s.statsMutex.Lock()
-	defer s.statsMutex.Unlock()
+// incrementTrackDownloaded increments the downloaded tracks counter and adds bytes.
+func (s *ServiceImpl) incrementTrackDownloaded(trackID string, bytes int64) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.TracksDownloaded++
+		stats.TotalTracksProcessed++
+		stats.TotalBytesDownloaded += bytes
+	})
 
-	s.stats.TracksDownloaded++
-	s.stats.TotalTracksProcessed++
-	s.stats.TotalBytesDownloaded += bytes
+	s.printTrackEvent(trackEventDone, trackID, formatEventBytes(bytes))
 }
 
-// incrementTrackSkipped atomically increments the skipped tracks counter with reason.
-func (s *ServiceImpl) incrementTrackSkipped(reason SkipReason) {
-	s.statsMutex.Lock()
-	defer s.statsMutex.Unlock()
+// recordDownloadedTrack records a downloaded track in the statistics.
+func (s *ServiceImpl) recordDownloadedTrack(t *downloadTrackTask, bytes int64) {
+	item := &DownloadedTrack{
+		TrackID:        t.trackIDString,
+		ParentCategory: t.metadata.category,
+		ParentID:       t.parentID,
+		ParentTitle:    t.parentTitle,
+		Quality:        t.quality,
+		Path:           t.trackPath,
+		Bytes:          bytes,
+	}
+
+	if t.track != nil {
+		item.Title = t.track.Title
+		item.Artists = t.track.ArtistNames
+	}
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.DownloadedTracks = append(stats.DownloadedTracks, item)
+	})
+
+	s.addCollectionUsage(t, bytes)
+}
 
-	s.stats.TracksSkipped++
-	s.stats.TotalTracksProcessed++
+// addCollectionUsage adds the size of a downloaded track to the usage of its collection.
+// Standalone tracks are counted for their albums.
+func (s *ServiceImpl) addCollectionUsage(t *downloadTrackTask, bytes int64) {
+	category := t.metadata.category
+	if category == DownloadCategoryTrack {
+		category = DownloadCategoryAlbum
+	}
 
-	// Track specific skip reason.
-	switch reason {
-	case SkipReasonExists:
-		s.stats.TracksSkippedExists++
-	case SkipReasonQuality:
-		s.stats.TracksSkippedQuality++
-	case SkipReasonDuration:
-		s.stats.TracksSkippedDuration++
+	key := category.ToLowerCase() + ":" + t.parentID
+	if t.parentID == "" {
+		key = unknownParentKey
 	}
+
+	s.stats.update(func(stats *DownloadStatistics) {
+		if stats.CollectionUsage == nil {
+			stats.CollectionUsage = make(map[string]*CollectionUsage)
+		}
+
+		usage, ok := stats.CollectionUsage[key]
+		if !ok {
+			usage = &CollectionUsage{
+				Category: category,
+				ItemID:   t.parentID,
+				Title:    t.parentTitle,
+			}
+			stats.CollectionUsage[key] = usage
+		}
+
+		usage.Tracks++
+		usage.Bytes += bytes
+	})
+}
+
+// sortedCollectionUsage returns the usage of the collections, the largest first (never nil).
+func sortedCollectionUsage(stats *DownloadStatistics) []*CollectionUsage {
+	result := make([]*CollectionUsage, 0, len(stats.CollectionUsage))
+	result = slices.AppendSeq(result, maps.Values(stats.CollectionUsage))
+
+	slices.SortFunc(result, func(a, b *CollectionUsage) int {
+		return cmp.Or(
+			cmp.Compare(b.Bytes, a.Bytes),
+			strings.Compare(a.Category.ToLowerCase(), b.Category.ToLowerCase()),
+			strings.Compare(a.ItemID, b.ItemID),
+		)
+	})
+
+	return result
+}
+
+// incrementTrackSkipped increments the skipped tracks counter with reason.
+func (s *ServiceImpl) incrementTrackSkipped(trackID string, reason SkipReason) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.TracksSkipped++
+		stats.TotalTracksProcessed++
+
+		// Track specific skip reason.
+		switch reason {
+		case SkipReasonExists:
+			stats.TracksSkippedExists++
+		case SkipReasonQuality:
+			stats.TracksSkippedQuality++
+		case SkipReasonDuration:
+			stats.TracksSkippedDuration++
+		case SkipReasonDuplicate:
+			stats.TracksSkippedDuplicate++
+		case SkipReasonSynced:
+			stats.TracksSkippedSynced++
+		case SkipReasonDownloaded:
+			stats.TracksSkippedDownloaded++
+		case SkipReasonInLibrary:
+			stats.TracksSkippedInLibrary++
+		case SkipReasonExcluded:
+			stats.TracksSkippedExcluded++
+		case SkipReasonBlocked:
+			stats.TracksSkippedBlocked++
+		}
+	})
+
+	s.printTrackEvent(trackEventSkip, trackID, reason.Code())
+}
+
+// incrementTrackLinked increments the counter of repeated playlist tracks saved as hard links.
+func (s *ServiceImpl) incrementTrackLinked(trackID string) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.TracksLinked++
+		stats.TotalTracksProcessed++
+	})
+
+	s.printTrackEvent(trackEventLink, trackID)
+}
+
+// incrementRclonePushed increments the counter of collections and tracks pushed with rclone.
+func (s *ServiceImpl) incrementRclonePushed() {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.RclonePushed++
+	})
+}
+
+// incrementTrackUpgraded increments the counter of watched tracks saved again in FLAC.
+func (s *ServiceImpl) incrementTrackUpgraded() {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.TracksUpgraded++
+	})
+}
+
+// incrementPlaylistDuplicate increments the repeated playlist tracks counter.
+func (s *ServiceImpl) incrementPlaylistDuplicate() {
+	s.stats.update(func(stats *DownloadStatistics) { stats.PlaylistDuplicates++ })
 }
 
-// incrementTrackFailed atomically increments the failed tracks counter.
-func (s *ServiceImpl) incrementTrackFailed() {
-	s.statsMutex.Lock()
-	defer s.statsMutex.Unlock()
+// incrementTrackFailed increments the failed tracks counter.
+func (s *ServiceImpl) incrementTrackFailed(trackID string) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		stats.TracksFailed++
+		stats.TotalTracksProcessed++
+	})
 
-	s.stats.TracksFailed++
-	s.stats.TotalTracksProcessed++
+	s.printTrackEvent(trackEventFail, trackID)
 }
 
-// incrementLyricsDownloaded atomically increments the downloaded lyrics counter.
+// incrementLyricsDownloaded increments the downloaded lyrics counter.
 func (s *ServiceImpl) incrementLyricsDownloaded() {
-	atomic.AddInt64(&s.stats.LyricsDownloaded, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.LyricsDownloaded++ })
 }
 
-// incrementLyricsSkipped atomically increments the skipped lyrics counter.
+// incrementLyricsSkipped increments the skipped lyrics counter.
 func (s *ServiceImpl) incrementLyricsSkipped() {
-	atomic.AddInt64(&s.stats.LyricsSkipped, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.LyricsSkipped++ })
 }
 
-// incrementCoverDownloaded atomically increments the downloaded covers counter.
+// incrementCoverDownloaded increments the downloaded covers counter.
 func (s *ServiceImpl) incrementCoverDownloaded() {
-	atomic.AddInt64(&s.stats.CoversDownloaded, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.CoversDownloaded++ })
+}
+
+// incrementCoverReused increments the reused covers counter.
+func (s *ServiceImpl) incrementCoverReused() {
+	s.stats.update(func(stats *DownloadStatistics) { stats.CoversReused++ })
 }
 
-// incrementCoverSkipped atomically increments the skipped covers counter.
+// incrementCoverSkipped increments the skipped covers counter.
 func (s *ServiceImpl) incrementCoverSkipped() {
-	atomic.AddInt64(&s.stats.CoversSkipped, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.CoversSkipped++ })
 }
 
-// incrementDescriptionSaved atomically increments the saved descriptions counter.
+// incrementDescriptionSaved increments the saved descriptions counter.
 func (s *ServiceImpl) incrementDescriptionSaved() {
-	atomic.AddInt64(&s.stats.DescriptionsSaved, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.DescriptionsSaved++ })
 }
 
-// incrementDescriptionSkipped atomically increments the skipped descriptions counter.
+// incrementDescriptionSkipped increments the skipped descriptions counter.
 func (s *ServiceImpl) incrementDescriptionSkipped() {
-	atomic.AddInt64(&s.stats.DescriptionsSkipped, 1)
+	s.stats.update(func(stats *DownloadStatistics) { stats.DescriptionsSkipped++ })
+}
+
+// incrementTrackNormalized increments the normalized copies counter.
+func (s *ServiceImpl) incrementTrackNormalized() {
+	s.stats.update(func(stats *DownloadStatistics) { stats.TracksNormalized++ })
+}
+
+// incrementTrackTranscoded increments the portable copies counter.
+func (s *ServiceImpl) incrementTrackTranscoded() {
+	s.stats.update(func(stats *DownloadStatistics) { stats.TracksTranscoded++ })
+}
+
+// addDownloadDuration adds time spent transferring track data.
+func (s *ServiceImpl) addDownloadDuration(d time.Duration) {
+	s.stats.update(func(stats *DownloadStatistics) { stats.TotalDownloadDuration += d })
+	s.addPhaseDuration(DownloadPhaseDownload, d)
+}
+
+// addPhaseDuration adds time spent in a phase of the downloads.
+func (s *ServiceImpl) addPhaseDuration(phase DownloadPhase, d time.Duration) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		if stats.PhaseDurations == nil {
+			stats.PhaseDurations = make(map[DownloadPhase]time.Duration, len(downloadPhases))
+		}
+
+		stats.PhaseDurations[phase] += d
+	})
+}
+
+// startPhaseTimer starts measuring a phase and returns the function that adds the time spent so far to it.
+// Only the first call of the function counts, so it can be both deferred and called once the phase is over.
+func (s *ServiceImpl) startPhaseTimer(phase DownloadPhase) func() {
+	startTime := time.Now()
+
+	return sync.OnceFunc(func() {
+		s.addPhaseDuration(phase, time.Since(startTime))
+	})
+}
+
+// addQualitySizeEstimate records the size of a track that would be downloaded in dry-run mode,
+// grouped by its resolved quality, and projects its size for each MP3 bitrate.
+func (s *ServiceImpl) addQualitySizeEstimate(quality TrackQuality, durationSeconds, bytes int64) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		if stats.QualitySizeEstimates == nil {
+			stats.QualitySizeEstimates = make(map[TrackQuality]*QualitySizeEstimate)
+		}
+
+		estimate, ok := stats.QualitySizeEstimates[quality]
+		if !ok {
+			estimate = new(QualitySizeEstimate)
+			stats.QualitySizeEstimates[quality] = estimate
+		}
+
+		estimate.Tracks++
+		estimate.Bytes += bytes
+
+		if stats.ProjectedMP3Bytes == nil {
+			stats.ProjectedMP3Bytes = make(map[TrackQuality]int64, len(projectedMP3BitratesKbps))
+		}
+
+		for _, projection := range projectedMP3BitratesKbps {
+			// The actual size is known for the resolved quality, the rest is derived from the bitrate.
+			projectedBytes := bytes
+			if quality != projection.quality {
+				projectedBytes = durationSeconds * projection.bitrateKbps * 1000 / 8
+			}
+
+			stats.ProjectedMP3Bytes[projection.quality] += projectedBytes
+		}
+	})
+}
+
+// Statistics returns a consistent snapshot of the session statistics.
+// It is safe to call while downloads are in progress.
+func (s *ServiceImpl) Statistics() *DownloadStatistics {
+	return s.stats.snapshot()
 }
 
 // groupErrors separates track errors from collection errors for better display organization.
@@ -121,10 +336,14 @@ func (s *ServiceImpl) groupErrors(errors []*DownloadError) (trackErrors, collect
 
 // PrintDownloadSummary prints a formatted summary of download statistics.
 func (s *ServiceImpl) PrintDownloadSummary(ctx context.Context) {
-	s.statsMutex.Lock()
-	defer s.statsMutex.Unlock()
+	stats := s.stats.snapshot()
 
-	stats := s.stats
+	// Scripts always get a document, even when nothing was processed.
+	if s.jsonOutput != nil {
+		s.printJSONSummary(ctx, stats)
+
+		return
+	}
 
 	// If nothing was processed and no errors were recorded, don't print summary.
 	if stats.TotalTracksProcessed == 0 && len(stats.Errors) == 0 {
@@ -134,15 +353,52 @@ func (s *ServiceImpl) PrintDownloadSummary(ctx context.Context) {
 	// Check if the context was canceled (CTRL+C or timeout).
 	wasInterrupted := ctx.Err() != nil
 
+	// Too many errors to read in the console: the full summary goes to a file and the console gets a short one.
+	if s.cfg.SummaryErrorLimit > 0 && int64(len(stats.Errors)) > s.cfg.SummaryErrorLimit {
+		reportPath, err := s.writeSummaryReport(ctx, stats, wasInterrupted)
+		if err == nil {
+			s.printSummary(ctx, stats, wasInterrupted, reportPath)
+
+			return
+		}
+
+		logger.Errorf(ctx, "Failed to save the detailed summary, printing it here: %v", err)
+	}
+
+	s.printSummary(ctx, stats, wasInterrupted, "")
+}
+
+// printSummary prints the summary sections in order.
+// When the detailed summary was saved to reportPath, the errors are only counted and the file is referenced.
+func (s *ServiceImpl) printSummary(
+	ctx context.Context,
+	stats *DownloadStatistics,
+	wasInterrupted bool,
+	reportPath string,
+) {
 	s.printSummaryHeader(ctx, wasInterrupted, stats.IsDryRun)
 	s.printTrackStatistics(ctx, stats)
 	s.printDataTransferStatistics(ctx, stats)
+	s.printPhaseTimings(ctx, stats)
+	s.printCopiesStatistics(ctx, stats)
+	s.printRcloneStatistics(ctx, stats)
 	s.printLyricsStatistics(ctx, stats)
 	s.printCoverArtStatistics(ctx, stats)
 	s.printDescriptionStatistics(ctx, stats)
+	s.printAPIActivityStatistics(ctx, stats)
+	s.printSkippedItems(ctx, stats)
+	s.printUntaggedTracks(ctx, stats)
+	s.printQualityDowngrades(ctx, stats)
+	s.printRelinkedReleases(ctx, stats)
 	s.printSummaryFooter(ctx)
-	s.printErrorDetails(ctx, stats)
-	s.printFinalMessage(ctx, wasInterrupted, stats)
+
+	if reportPath != "" {
+		s.printErrorCounts(ctx, stats, reportPath)
+	} else {
+		s.printErrorDetails(ctx, stats)
+	}
+
+	s.printFinalMessage(ctx, wasInterrupted, stats, reportPath)
 	s.printDryRunSuggestion(ctx, stats)
 }
 
@@ -193,11 +449,41 @@ func (s *ServiceImpl) printTrackStatisticsDryRun(ctx context.Context, stats *Dow
 		if stats.TracksSkippedDuration > 0 {
 			logger.Infof(ctx, "  Duration Filter: %d", stats.TracksSkippedDuration)
 		}
+
+		if stats.TracksSkippedExcluded > 0 {
+			logger.Infof(ctx, "  Excluded:        %d", stats.TracksSkippedExcluded)
+		}
+
+		if stats.TracksSkippedBlocked > 0 {
+			logger.Infof(ctx, "  Blocklisted:     %d", stats.TracksSkippedBlocked)
+		}
+
+		if stats.TracksSkippedDuplicate > 0 {
+			logger.Infof(ctx, "  Duplicates:      %d", stats.TracksSkippedDuplicate)
+		}
+
+		if stats.TracksSkippedSynced > 0 {
+			logger.Infof(ctx, "  Already Synced:  %d", stats.TracksSkippedSynced)
+		}
+
+		if stats.TracksSkippedDownloaded > 0 {
+			logger.Infof(ctx, "  In History:      %d", stats.TracksSkippedDownloaded)
+		}
+
+		if stats.TracksSkippedInLibrary > 0 {
+			logger.Infof(ctx, "  In Library:      %d", stats.TracksSkippedInLibrary)
+		}
+	}
+
+	if stats.TracksLinked > 0 {
+		logger.Infof(ctx, "  Would Link:      %d", stats.TracksLinked)
 	}
 
 	if stats.TracksFailed > 0 {
 		logger.Infof(ctx, "  Unavailable:     %d", stats.TracksFailed)
 	}
+
+	s.printPlaylistDuplicates(ctx, stats)
 }
 
 // printTrackStatisticsRegular prints track statistics for regular download mode.
@@ -222,6 +508,38 @@ func (s *ServiceImpl) printTrackStatisticsRegular(ctx context.Context, stats *Do
 		if stats.TracksSkippedDuration > 0 {
 			logger.Infof(ctx, "    Duration:      %d", stats.TracksSkippedDuration)
 		}
+
+		if stats.TracksSkippedExcluded > 0 {
+			logger.Infof(ctx, "    Excluded:      %d", stats.TracksSkippedExcluded)
+		}
+
+		if stats.TracksSkippedBlocked > 0 {
+			logger.Infof(ctx, "    Blocklisted:   %d", stats.TracksSkippedBlocked)
+		}
+
+		if stats.TracksSkippedDuplicate > 0 {
+			logger.Infof(ctx, "    Duplicates:    %d", stats.TracksSkippedDuplicate)
+		}
+
+		if stats.TracksSkippedSynced > 0 {
+			logger.Infof(ctx, "    Synced Before: %d", stats.TracksSkippedSynced)
+		}
+
+		if stats.TracksSkippedDownloaded > 0 {
+			logger.Infof(ctx, "    In History:    %d", stats.TracksSkippedDownloaded)
+		}
+
+		if stats.TracksSkippedInLibrary > 0 {
+			logger.Infof(ctx, "    In Library:    %d", stats.TracksSkippedInLibrary)
+		}
+	}
+
+	if stats.TracksLinked > 0 {
+		logger.Infof(ctx, "  Hard Linked:     %d", stats.TracksLinked)
+	}
+
+	if stats.TracksUpgraded > 0 {
+		logger.Infof(ctx, "  Upgraded:        %d", stats.TracksUpgraded)
 	}
 
 	if stats.TracksFailed > 0 {
@@ -230,10 +548,21 @@ func (s *ServiceImpl) printTrackStatisticsRegular(ctx context.Context, stats *Do
 
 	// Success rate.
 	if stats.TotalTracksProcessed > 0 {
-		successCount := stats.TracksDownloaded + stats.TracksSkipped
+		successCount := stats.TracksDownloaded + stats.TracksSkipped + stats.TracksLinked
 		successRate := float64(successCount) / float64(stats.TotalTracksProcessed) * 100
 		logger.Infof(ctx, "  Success Rate:    %.1f%%", successRate)
 	}
+
+	s.printPlaylistDuplicates(ctx, stats)
+}
+
+// printPlaylistDuplicates prints how many repeated playlist tracks were found and the policy applied to them.
+func (s *ServiceImpl) printPlaylistDuplicates(ctx context.Context, stats *DownloadStatistics) {
+	if stats.PlaylistDuplicates == 0 {
+		return
+	}
+
+	logger.Infof(ctx, "  Playlist Repeats: %d (%s)", stats.PlaylistDuplicates, s.cfg.PlaylistDuplicates)
 }
 
 // printDataTransferStatistics prints data transfer statistics.
@@ -243,11 +572,15 @@ func (s *ServiceImpl) printDataTransferStatistics(ctx context.Context, stats *Do
 
 		if stats.IsDryRun {
 			logger.Infof(ctx, "Estimated Size:   %s", humanize.Bytes(uint64(stats.TotalBytesDownloaded)))
+			s.printQualitySizeEstimates(ctx, stats)
 		} else {
 			logger.Infof(ctx, "Data Downloaded:  %s", humanize.Bytes(uint64(stats.TotalBytesDownloaded)))
 		}
 	}
 
+	s.printCollectionUsage(ctx, stats)
+	s.printAPIRequests(ctx)
+
 	// Print duration if we have both start and end times (skip for dry-run).
 	if !stats.IsDryRun && !stats.StartTime.IsZero() && !stats.EndTime.IsZero() {
 		duration := stats.EndTime.Sub(stats.StartTime)
@@ -265,6 +598,139 @@ func (s *ServiceImpl) printDataTransferStatistics(ctx context.Context, stats *Do
 	}
 }
 
+// printPhaseTimings prints the cumulative time spent in every phase of the downloads,
+// so it shows whether the transfers, the API requests, or the pauses take the time.
+// The phases of concurrent downloads overlap, so the parallelism is their total time over the duration of the run.
+func (s *ServiceImpl) printPhaseTimings(ctx context.Context, stats *DownloadStatistics) {
+	var totalDuration time.Duration
+	for _, d := range stats.PhaseDurations {
+		totalDuration += d
+	}
+
+	// Only show if the time is meaningful (> 100ms), like the duration of the run.
+	if stats.IsDryRun || totalDuration <= 100*time.Millisecond {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Info(ctx, "Time by Phase:    (cumulative)")
+
+	for _, phase := range downloadPhases {
+		d := stats.PhaseDurations[phase]
+		if d == 0 {
+			continue
+		}
+
+		logger.Infof(ctx, "  %-16s%s (%.0f%%)",
+			phase.String()+":", formatDuration(d), 100*d.Seconds()/totalDuration.Seconds())
+	}
+
+	if !stats.StartTime.IsZero() && !stats.EndTime.IsZero() {
+		if duration := stats.EndTime.Sub(stats.StartTime); duration > 0 {
+			logger.Infof(ctx, "  Parallelism:    %.1fx", totalDuration.Seconds()/duration.Seconds())
+		}
+	}
+}
+
+// printAPIRequests prints the number of API requests sent during the run, with the max_api_requests limit if set.
+func (s *ServiceImpl) printAPIRequests(ctx context.Context) {
+	requests := s.apiRequestsCount()
+	if requests == 0 {
+		return
+	}
+
+	if s.cfg.MaxAPIRequests > 0 {
+		logger.Infof(ctx, "API Requests:     %d (limit %d)", requests, s.cfg.MaxAPIRequests)
+
+		return
+	}
+
+	logger.Infof(ctx, "API Requests:     %d", requests)
+}
+
+// printCollectionUsage prints the size of the audio downloaded for the largest collections,
+// showing where the data went when more than one collection was downloaded.
+func (s *ServiceImpl) printCollectionUsage(ctx context.Context, stats *DownloadStatistics) {
+	if len(stats.CollectionUsage) < 2 {
+		return
+	}
+
+	usage := sortedCollectionUsage(stats)
+	shownCount := min(len(usage), summaryCollectionUsageLimit)
+
+	logger.Info(ctx, "By Collection:")
+
+	for _, item := range usage[:shownCount] {
+		title := item.Title
+		if title == "" {
+			title = "unknown collection"
+		}
+
+		logger.Infof(ctx, "  %-10s %8s (%d tracks) %s",
+			item.Category.ToLowerCase(), humanize.Bytes(uint64(item.Bytes)), item.Tracks, title)
+	}
+
+	if len(usage) > shownCount {
+		logger.Infof(ctx, "  ...and %d more", len(usage)-shownCount)
+	}
+}
+
+// printQualitySizeEstimates prints dry-run size totals per quality and MP3 projections,
+// so FLAC and MP3 space usage can be compared before downloading.
+func (s *ServiceImpl) printQualitySizeEstimates(ctx context.Context, stats *DownloadStatistics) {
+	if len(stats.QualitySizeEstimates) == 0 {
+		return
+	}
+
+	// Print from the highest quality to the lowest.
+	qualities := slices.Sorted(maps.Keys(stats.QualitySizeEstimates))
+	slices.Reverse(qualities)
+
+	for _, quality := range qualities {
+		estimate := stats.QualitySizeEstimates[quality]
+		logger.Infof(ctx, "  %-34s %s (%d tracks)",
+			quality.String()+":", humanize.Bytes(uint64(estimate.Bytes)), estimate.Tracks)
+	}
+
+	logger.Info(ctx, "Projected Size If Downloaded As:")
+
+	for _, projection := range projectedMP3BitratesKbps {
+		logger.Infof(ctx, "  %-34s %s",
+			projection.quality.String()+":", humanize.Bytes(uint64(stats.ProjectedMP3Bytes[projection.quality])))
+	}
+}
+
+// printCopiesStatistics prints the number of loudness-normalized and portable copies.
+func (s *ServiceImpl) printCopiesStatistics(ctx context.Context, stats *DownloadStatistics) {
+	if stats.TracksNormalized == 0 && stats.TracksTranscoded == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+
+	if stats.TracksNormalized > 0 {
+		logger.Infof(ctx, "Normalized Copies: %d (%s)", stats.TracksNormalized, s.cfg.NormalizedOutputPath)
+	}
+
+	if stats.TracksTranscoded > 0 {
+		logger.Infof(ctx, "Portable Copies: %d (%s)", stats.TracksTranscoded, s.cfg.PortableOutputPath)
+	}
+}
+
+// printRcloneStatistics prints how many collections and tracks were pushed with rclone and which failed.
+func (s *ServiceImpl) printRcloneStatistics(ctx context.Context, stats *DownloadStatistics) {
+	if stats.RclonePushed == 0 && len(stats.RcloneFailures) == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Rclone (%s): %d pushed, %d failed", s.cfg.RcloneRemote, stats.RclonePushed, len(stats.RcloneFailures))
+
+	for i, item := range stats.RcloneFailures {
+		logger.Errorf(ctx, "  [%d] %s (%s): %s", i+1, item.Title, item.Path, item.Error)
+	}
+}
+
 // printLyricsStatistics prints lyrics download statistics.
 func (s *ServiceImpl) printLyricsStatistics(ctx context.Context, stats *DownloadStatistics) {
 	totalLyrics := stats.LyricsDownloaded + stats.LyricsSkipped
@@ -286,7 +752,7 @@ func (s *ServiceImpl) printLyricsStatistics(ctx context.Context, stats *Download
 
 // printCoverArtStatistics prints cover art download statistics.
 func (s *ServiceImpl) printCoverArtStatistics(ctx context.Context, stats *DownloadStatistics) {
-	totalCovers := stats.CoversDownloaded + stats.CoversSkipped
+	totalCovers := stats.CoversDownloaded + stats.CoversSkipped + stats.CoversReused
 	if totalCovers == 0 {
 		return
 	}
@@ -298,6 +764,10 @@ func (s *ServiceImpl) printCoverArtStatistics(ctx context.Context, stats *Downlo
 		logger.Infof(ctx, "  Downloaded:     %d", stats.CoversDownloaded)
 	}
 
+	if stats.CoversReused > 0 {
+		logger.Infof(ctx, "  Reused:         %d", stats.CoversReused)
+	}
+
 	if stats.CoversSkipped > 0 {
 		logger.Infof(ctx, "  Skipped:        %d", stats.CoversSkipped)
 	}
@@ -321,6 +791,135 @@ func (s *ServiceImpl) printDescriptionStatistics(ctx context.Context, stats *Dow
 	}
 }
 
+// printAPIActivityStatistics prints API request counts, cache hit rates and time split
+// between metadata requests and downloads. It is shown only at debug log level.
+func (s *ServiceImpl) printAPIActivityStatistics(ctx context.Context, stats *DownloadStatistics) {
+	if !logger.IsDebugLevel() || s.zvukClient == nil {
+		return
+	}
+
+	apiStats := s.zvukClient.GetAPIStatistics()
+	if apiStats == nil {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Info(ctx, "API Activity (debug):")
+
+	for _, endpoint := range slices.Sorted(maps.Keys(apiStats.Endpoints)) {
+		endpointStats := apiStats.Endpoints[endpoint]
+		logger.Infof(ctx, "  %-40s %5d request(s), %s",
+			endpoint, endpointStats.Requests, formatDuration(endpointStats.TotalDuration))
+	}
+
+	if len(apiStats.Caches) > 0 {
+		logger.Info(ctx, "Cache Hit Rates:")
+
+		for _, cacheName := range slices.Sorted(maps.Keys(apiStats.Caches)) {
+			cacheStats := apiStats.Caches[cacheName]
+			logger.Infof(ctx, "  %-12s %5.1f%% (%d hit(s), %d miss(es))",
+				cacheName, cacheStats.HitRate(), cacheStats.Hits, cacheStats.Misses)
+		}
+	}
+
+	logger.Infof(ctx, "Metadata Time:    %s (cumulative)", formatDuration(apiStats.TotalAPIDuration()))
+	logger.Infof(ctx, "Download Time:    %s (cumulative)", formatDuration(stats.TotalDownloadDuration))
+}
+
+// printSkippedItems prints every skipped track with its reason and threshold.
+// It is shown only at debug log level to keep the regular summary compact.
+func (s *ServiceImpl) printSkippedItems(ctx context.Context, stats *DownloadStatistics) {
+	if !logger.IsDebugLevel() || len(stats.SkippedItems) == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Skipped Tracks (debug): %d", len(stats.SkippedItems))
+
+	for i, item := range stats.SkippedItems {
+		reason := item.Reason.String()
+		if item.Threshold != "" {
+			reason += " (" + item.Threshold + ")"
+		}
+
+		if item.ParentTitle != "" {
+			logger.Infof(ctx, "  [%d] %s (ID: %s) from %s '%s': %s",
+				i+1, item.Title, item.TrackID, item.ParentCategory, item.ParentTitle, reason)
+		} else {
+			logger.Infof(ctx, "  [%d] %s (ID: %s): %s", i+1, item.Title, item.TrackID, reason)
+		}
+	}
+}
+
+// printUntaggedTracks prints tracks whose audio was kept although writing tags failed.
+func (s *ServiceImpl) printUntaggedTracks(ctx context.Context, stats *DownloadStatistics) {
+	if len(stats.UntaggedTracks) == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Untagged Tracks (tagging failed, audio kept): %d", len(stats.UntaggedTracks))
+
+	for i, item := range stats.UntaggedTracks {
+		logger.Infof(ctx, "  [%d] %s (ID: %s): %s", i+1, item.Title, item.TrackID, item.Path)
+	}
+}
+
+// printRelinkedReleases prints the albums whose folder was moved to their re-issue under a new release ID.
+func (s *ServiceImpl) printRelinkedReleases(ctx context.Context, stats *DownloadStatistics) {
+	if len(stats.RelinkedReleases) == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Relinked Releases (re-issued under a new ID): %d", len(stats.RelinkedReleases))
+
+	for i, item := range stats.RelinkedReleases {
+		logger.Infof(ctx, "  [%d] %s (ID: %s, was %s): %d track(s) moved from '%s' to '%s'",
+			i+1, item.Title, item.ReleaseID, item.PreviousReleaseID, item.Tracks, item.PreviousPath, item.Path)
+	}
+}
+
+// printQualityDowngrades prints tracks that are not available in the requested quality,
+// so they can be downloaded again later.
+func (s *ServiceImpl) printQualityDowngrades(ctx context.Context, stats *DownloadStatistics) {
+	if len(stats.QualityDowngrades) == 0 {
+		return
+	}
+
+	const (
+		idColumnWidth      = 10
+		qualityColumnWidth = 9
+	)
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Quality Downgrades (requested vs delivered): %d", len(stats.QualityDowngrades))
+	logger.Infof(ctx, "  %-*s %-*s %-*s %s",
+		idColumnWidth, "Track ID", qualityColumnWidth, "Requested", qualityColumnWidth, "Delivered", "Track")
+
+	for _, item := range stats.QualityDowngrades {
+		delivered := item.AvailableQuality.String()
+		if item.IsSkipped {
+			delivered = "skipped"
+		}
+
+		title := item.Title
+		if item.ParentTitle != "" {
+			title += fmt.Sprintf(" (%s '%s')", item.ParentCategory, item.ParentTitle)
+		}
+
+		if item.IsStreamFallback {
+			title += " [stream error]"
+		}
+
+		logger.Infof(ctx, "  %-*s %-*s %-*s %s",
+			idColumnWidth, item.TrackID,
+			qualityColumnWidth, item.RequestedQuality,
+			qualityColumnWidth, delivered,
+			title)
+	}
+}
+
 // printSummaryFooter prints the summary footer separator.
 func (s *ServiceImpl) printSummaryFooter(ctx context.Context) {
 	logger.Info(ctx, "═══════════════════════════════════════════════════════════════")
@@ -471,6 +1070,14 @@ func (s *ServiceImpl) printRetryCommand(ctx context.Context, errors []*DownloadE
 
 		logger.Infof(ctx, "  %s", commandStringBuilder.String())
 	}
+
+	// The resume state also covers failed tracks, which the command above leaves out.
+	if s.cfg.ResumeStatePath != "" && !s.cfg.DryRun && len(buildResumeItems(errors)) > 0 {
+		logger.Info(ctx, "")
+		logger.Info(ctx, "To retry every failed item, run:")
+		logger.Info(ctx, "")
+		logger.Info(ctx, "  zvuk-grabber resume")
+	}
 }
 
 // printDryRunSuggestion prints a suggestion to proceed with actual download after dry-run.
@@ -485,7 +1092,12 @@ func (s *ServiceImpl) printDryRunSuggestion(ctx context.Context, stats *Download
 }
 
 // printFinalMessage prints a helpful message based on download results.
-func (s *ServiceImpl) printFinalMessage(ctx context.Context, wasInterrupted bool, stats *DownloadStatistics) {
+func (s *ServiceImpl) printFinalMessage(
+	ctx context.Context,
+	wasInterrupted bool,
+	stats *DownloadStatistics,
+	reportPath string,
+) {
 	// Dry-run specific messages.
 	if stats.IsDryRun {
 		if stats.TracksDownloaded == 0 && stats.TracksSkipped > 0 {
@@ -498,6 +1110,9 @@ func (s *ServiceImpl) printFinalMessage(ctx context.Context, wasInterrupted bool
 
 	// Regular download messages.
 	switch {
+	case s.abortReason != nil:
+		logger.Info(ctx, "")
+		logger.Warnf(ctx, "Download aborted on the first error (--fail-fast): %v", s.abortReason)
 	case wasInterrupted:
 		logger.Info(ctx, "")
 		logger.Warn(ctx, "Download interrupted by user (CTRL+C).")
@@ -505,6 +1120,10 @@ func (s *ServiceImpl) printFinalMessage(ctx context.Context, wasInterrupted bool
 		if stats.TracksDownloaded > 0 {
 			logger.Infof(ctx, "Successfully downloaded %d track(s) before interruption.", stats.TracksDownloaded)
 		}
+	case len(stats.Errors) > 0 && reportPath != "":
+		logger.Info(ctx, "")
+		logger.Warnf(ctx, "%d error(s) occurred during download. See the detailed summary in %s.",
+			len(stats.Errors), reportPath)
 	case len(stats.Errors) > 0:
 		logger.Info(ctx, "")
 		logger.Warnf(ctx, "%d error(s) occurred during download. See detailed error log above.", len(stats.Errors))
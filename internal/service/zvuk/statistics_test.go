@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/oshokin/zvuk-grabber/internal/config"
 )
@@ -24,10 +25,10 @@ func TestDownloadStatistics_InitialState(t *testing.T) {
 	impl, ok := service.(*ServiceImpl)
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 	assert.NotNil(t, impl.stats, "Statistics should be initialized")
-	assert.Equal(t, int64(0), impl.stats.TotalTracksProcessed, "Initial tracks processed should be 0")
-	assert.Equal(t, int64(0), impl.stats.TracksDownloaded, "Initial tracks downloaded should be 0")
-	assert.Equal(t, int64(0), impl.stats.TracksSkipped, "Initial tracks skipped should be 0")
-	assert.Equal(t, int64(0), impl.stats.TracksFailed, "Initial tracks failed should be 0")
+	assert.Equal(t, int64(0), impl.Statistics().TotalTracksProcessed, "Initial tracks processed should be 0")
+	assert.Equal(t, int64(0), impl.Statistics().TracksDownloaded, "Initial tracks downloaded should be 0")
+	assert.Equal(t, int64(0), impl.Statistics().TracksSkipped, "Initial tracks skipped should be 0")
+	assert.Equal(t, int64(0), impl.Statistics().TracksFailed, "Initial tracks failed should be 0")
 }
 
 func TestDownloadStatistics_IncrementTrackDownloaded(t *testing.T) {
@@ -45,12 +46,12 @@ func TestDownloadStatistics_IncrementTrackDownloaded(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Increment downloaded tracks.
-	impl.incrementTrackDownloaded(1024)
-	impl.incrementTrackDownloaded(2048)
+	impl.incrementTrackDownloaded("1", 1024)
+	impl.incrementTrackDownloaded("1", 2048)
 
-	assert.Equal(t, int64(2), impl.stats.TotalTracksProcessed, "Should have 2 tracks processed")
-	assert.Equal(t, int64(2), impl.stats.TracksDownloaded, "Should have 2 tracks downloaded")
-	assert.Equal(t, int64(3072), impl.stats.TotalBytesDownloaded, "Should have 3072 bytes downloaded")
+	assert.Equal(t, int64(2), impl.Statistics().TotalTracksProcessed, "Should have 2 tracks processed")
+	assert.Equal(t, int64(2), impl.Statistics().TracksDownloaded, "Should have 2 tracks downloaded")
+	assert.Equal(t, int64(3072), impl.Statistics().TotalBytesDownloaded, "Should have 3072 bytes downloaded")
 }
 
 func TestDownloadStatistics_IncrementTrackSkipped(t *testing.T) {
@@ -68,13 +69,13 @@ func TestDownloadStatistics_IncrementTrackSkipped(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Increment skipped tracks.
-	impl.incrementTrackSkipped(SkipReasonExists)
-	impl.incrementTrackSkipped(SkipReasonQuality)
+	impl.incrementTrackSkipped("1", SkipReasonExists)
+	impl.incrementTrackSkipped("1", SkipReasonQuality)
 
-	assert.Equal(t, int64(2), impl.stats.TotalTracksProcessed, "Should have 2 tracks processed")
-	assert.Equal(t, int64(2), impl.stats.TracksSkipped, "Should have 2 tracks skipped")
-	assert.Equal(t, int64(1), impl.stats.TracksSkippedExists, "Should have 1 track skipped (exists)")
-	assert.Equal(t, int64(1), impl.stats.TracksSkippedQuality, "Should have 1 track skipped (quality)")
+	assert.Equal(t, int64(2), impl.Statistics().TotalTracksProcessed, "Should have 2 tracks processed")
+	assert.Equal(t, int64(2), impl.Statistics().TracksSkipped, "Should have 2 tracks skipped")
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkippedExists, "Should have 1 track skipped (exists)")
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkippedQuality, "Should have 1 track skipped (quality)")
 }
 
 func TestDownloadStatistics_IncrementTrackFailed(t *testing.T) {
@@ -92,10 +93,10 @@ func TestDownloadStatistics_IncrementTrackFailed(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Increment failed tracks.
-	impl.incrementTrackFailed()
+	impl.incrementTrackFailed("1")
 
-	assert.Equal(t, int64(1), impl.stats.TotalTracksProcessed, "Should have 1 track processed")
-	assert.Equal(t, int64(1), impl.stats.TracksFailed, "Should have 1 track failed")
+	assert.Equal(t, int64(1), impl.Statistics().TotalTracksProcessed, "Should have 1 track processed")
+	assert.Equal(t, int64(1), impl.Statistics().TracksFailed, "Should have 1 track failed")
 }
 
 func TestDownloadStatistics_MixedResults(t *testing.T) {
@@ -113,24 +114,24 @@ func TestDownloadStatistics_MixedResults(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Simulate mixed download results.
-	impl.incrementTrackDownloaded(1000)
-	impl.incrementTrackDownloaded(2000)
-	impl.incrementTrackSkipped(SkipReasonDuration)
-	impl.incrementTrackFailed()
+	impl.incrementTrackDownloaded("1", 1000)
+	impl.incrementTrackDownloaded("1", 2000)
+	impl.incrementTrackSkipped("1", SkipReasonDuration)
+	impl.incrementTrackFailed("1")
 	impl.incrementLyricsDownloaded()
 	impl.incrementLyricsSkipped()
 	impl.incrementCoverDownloaded()
 	impl.incrementCoverSkipped()
 
-	assert.Equal(t, int64(4), impl.stats.TotalTracksProcessed, "Should have 4 tracks processed")
-	assert.Equal(t, int64(2), impl.stats.TracksDownloaded, "Should have 2 tracks downloaded")
-	assert.Equal(t, int64(1), impl.stats.TracksSkipped, "Should have 1 track skipped")
-	assert.Equal(t, int64(1), impl.stats.TracksFailed, "Should have 1 track failed")
-	assert.Equal(t, int64(3000), impl.stats.TotalBytesDownloaded, "Should have 3000 bytes downloaded")
-	assert.Equal(t, int64(1), impl.stats.LyricsDownloaded, "Should have 1 lyrics downloaded")
-	assert.Equal(t, int64(1), impl.stats.LyricsSkipped, "Should have 1 lyrics skipped")
-	assert.Equal(t, int64(1), impl.stats.CoversDownloaded, "Should have 1 cover downloaded")
-	assert.Equal(t, int64(1), impl.stats.CoversSkipped, "Should have 1 cover skipped")
+	assert.Equal(t, int64(4), impl.Statistics().TotalTracksProcessed, "Should have 4 tracks processed")
+	assert.Equal(t, int64(2), impl.Statistics().TracksDownloaded, "Should have 2 tracks downloaded")
+	assert.Equal(t, int64(1), impl.Statistics().TracksSkipped, "Should have 1 track skipped")
+	assert.Equal(t, int64(1), impl.Statistics().TracksFailed, "Should have 1 track failed")
+	assert.Equal(t, int64(3000), impl.Statistics().TotalBytesDownloaded, "Should have 3000 bytes downloaded")
+	assert.Equal(t, int64(1), impl.Statistics().LyricsDownloaded, "Should have 1 lyrics downloaded")
+	assert.Equal(t, int64(1), impl.Statistics().LyricsSkipped, "Should have 1 lyrics skipped")
+	assert.Equal(t, int64(1), impl.Statistics().CoversDownloaded, "Should have 1 cover downloaded")
+	assert.Equal(t, int64(1), impl.Statistics().CoversSkipped, "Should have 1 cover skipped")
 }
 
 func TestPrintDownloadSummary_NoTracksProcessed(t *testing.T) {
@@ -152,7 +153,7 @@ func TestPrintDownloadSummary_NoTracksProcessed(t *testing.T) {
 	impl.PrintDownloadSummary(ctx)
 
 	// Verify no changes to stats.
-	assert.Equal(t, int64(0), impl.stats.TotalTracksProcessed, "Should still have 0 tracks processed")
+	assert.Equal(t, int64(0), impl.Statistics().TotalTracksProcessed, "Should still have 0 tracks processed")
 }
 
 func TestPrintDownloadSummary_WithResults(t *testing.T) {
@@ -170,7 +171,7 @@ func TestPrintDownloadSummary_WithResults(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Simulate some downloads.
-	impl.incrementTrackDownloaded(36860019) // ~37 MB (from the example).
+	impl.incrementTrackDownloaded("1", 36860019) // ~37 MB (from the example).
 	impl.incrementLyricsDownloaded()
 	impl.incrementCoverDownloaded()
 
@@ -179,9 +180,9 @@ func TestPrintDownloadSummary_WithResults(t *testing.T) {
 	impl.PrintDownloadSummary(ctx)
 
 	// Verify stats are correct.
-	assert.Equal(t, int64(1), impl.stats.TotalTracksProcessed, "Should have 1 track processed")
-	assert.Equal(t, int64(1), impl.stats.TracksDownloaded, "Should have 1 track downloaded")
-	assert.Equal(t, int64(36860019), impl.stats.TotalBytesDownloaded, "Should have correct bytes")
+	assert.Equal(t, int64(1), impl.Statistics().TotalTracksProcessed, "Should have 1 track processed")
+	assert.Equal(t, int64(1), impl.Statistics().TracksDownloaded, "Should have 1 track downloaded")
+	assert.Equal(t, int64(36860019), impl.Statistics().TotalBytesDownloaded, "Should have correct bytes")
 }
 
 func TestDownloadStatistics_ConcurrentAccess(t *testing.T) {
@@ -205,7 +206,7 @@ func TestDownloadStatistics_ConcurrentAccess(t *testing.T) {
 
 	for range 10 {
 		go func() {
-			impl.incrementTrackDownloaded(1000)
+			impl.incrementTrackDownloaded("1", 1000)
 			impl.incrementLyricsDownloaded()
 			impl.incrementCoverDownloaded()
 
@@ -219,11 +220,11 @@ func TestDownloadStatistics_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all increments were recorded.
-	assert.Equal(t, int64(10), impl.stats.TotalTracksProcessed, "Should have 10 tracks processed")
-	assert.Equal(t, int64(10), impl.stats.TracksDownloaded, "Should have 10 tracks downloaded")
-	assert.Equal(t, int64(10000), impl.stats.TotalBytesDownloaded, "Should have 10000 bytes downloaded")
-	assert.Equal(t, int64(10), impl.stats.LyricsDownloaded, "Should have 10 lyrics downloaded")
-	assert.Equal(t, int64(10), impl.stats.CoversDownloaded, "Should have 10 covers downloaded")
+	assert.Equal(t, int64(10), impl.Statistics().TotalTracksProcessed, "Should have 10 tracks processed")
+	assert.Equal(t, int64(10), impl.Statistics().TracksDownloaded, "Should have 10 tracks downloaded")
+	assert.Equal(t, int64(10000), impl.Statistics().TotalBytesDownloaded, "Should have 10000 bytes downloaded")
+	assert.Equal(t, int64(10), impl.Statistics().LyricsDownloaded, "Should have 10 lyrics downloaded")
+	assert.Equal(t, int64(10), impl.Statistics().CoversDownloaded, "Should have 10 covers downloaded")
 }
 
 func TestPrintDownloadSummary_WithInterruption(t *testing.T) {
@@ -241,8 +242,8 @@ func TestPrintDownloadSummary_WithInterruption(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Simulate partial download before interruption.
-	impl.incrementTrackDownloaded(10000000) // 10 MB.
-	impl.incrementTrackDownloaded(5000000)  // 5 MB.
+	impl.incrementTrackDownloaded("1", 10000000) // 10 MB.
+	impl.incrementTrackDownloaded("1", 5000000)  // 5 MB.
 	impl.incrementCoverDownloaded()
 
 	// Create a canceled context to simulate CTRL+C.
@@ -253,9 +254,9 @@ func TestPrintDownloadSummary_WithInterruption(t *testing.T) {
 	impl.PrintDownloadSummary(ctx)
 
 	// Verify stats are correct.
-	assert.Equal(t, int64(2), impl.stats.TotalTracksProcessed, "Should have 2 tracks processed")
-	assert.Equal(t, int64(2), impl.stats.TracksDownloaded, "Should have 2 tracks downloaded")
-	assert.Equal(t, int64(15000000), impl.stats.TotalBytesDownloaded, "Should have 15 MB downloaded")
+	assert.Equal(t, int64(2), impl.Statistics().TotalTracksProcessed, "Should have 2 tracks processed")
+	assert.Equal(t, int64(2), impl.Statistics().TracksDownloaded, "Should have 2 tracks downloaded")
+	assert.Equal(t, int64(15000000), impl.Statistics().TotalBytesDownloaded, "Should have 15 MB downloaded")
 }
 
 func TestDownloadStatistics_ErrorTracking(t *testing.T) {
@@ -302,15 +303,15 @@ func TestDownloadStatistics_ErrorTracking(t *testing.T) {
 		Error:     assert.AnError,
 	})
 
-	impl.incrementTrackFailed()
-	impl.incrementTrackDownloaded(1000)
+	impl.incrementTrackFailed("1")
+	impl.incrementTrackDownloaded("1", 1000)
 
 	// Verify errors were recorded.
-	assert.Len(t, impl.stats.Errors, 3, "Should have 3 errors recorded")
-	assert.Equal(t, "12345", impl.stats.Errors[0].ItemID)
-	assert.Equal(t, "Test Track 1", impl.stats.Errors[0].ItemTitle)
-	assert.Equal(t, "downloading file", impl.stats.Errors[0].Phase)
-	assert.Equal(t, DownloadCategoryTrack, impl.stats.Errors[0].Category)
+	assert.Len(t, impl.Statistics().Errors, 3, "Should have 3 errors recorded")
+	assert.Equal(t, "12345", impl.Statistics().Errors[0].ItemID)
+	assert.Equal(t, "Test Track 1", impl.Statistics().Errors[0].ItemTitle)
+	assert.Equal(t, "downloading file", impl.Statistics().Errors[0].Phase)
+	assert.Equal(t, DownloadCategoryTrack, impl.Statistics().Errors[0].Category)
 
 	// Print summary with errors (should not panic).
 	ctx := context.Background()
@@ -333,26 +334,26 @@ func TestPrintDownloadSummary_WithDuration(t *testing.T) {
 	assert.True(t, ok, "Service should be of type *ServiceImpl")
 
 	// Record actual start time.
-	impl.stats.StartTime = time.Now()
+	impl.stats.update(func(stats *DownloadStatistics) { stats.StartTime = time.Now() })
 
 	// Simulate some download work with controlled timing.
 	totalBytes := int64(100 * 1024 * 1024)
-	impl.incrementTrackDownloaded(totalBytes)
+	impl.incrementTrackDownloaded("1", totalBytes)
 
 	// Sleep to ensure measurable duration (at least 100ms for test reliability).
 	time.Sleep(150 * time.Millisecond)
 
-	impl.incrementTrackDownloaded(totalBytes)
+	impl.incrementTrackDownloaded("1", totalBytes)
 
 	// Record actual end time.
-	impl.stats.EndTime = time.Now()
+	impl.stats.update(func(stats *DownloadStatistics) { stats.EndTime = time.Now() })
 
 	// Calculate actual duration.
-	actualDuration := impl.stats.EndTime.Sub(impl.stats.StartTime)
+	actualDuration := impl.Statistics().EndTime.Sub(impl.Statistics().StartTime)
 
 	// Verify stats.
-	assert.Equal(t, int64(2), impl.stats.TracksDownloaded)
-	assert.Equal(t, totalBytes*2, impl.stats.TotalBytesDownloaded)
+	assert.Equal(t, int64(2), impl.Statistics().TracksDownloaded)
+	assert.Equal(t, totalBytes*2, impl.Statistics().TotalBytesDownloaded)
 
 	// Print summary (should show duration and average speed).
 	ctx := context.Background()
@@ -371,6 +372,77 @@ func TestPrintDownloadSummary_WithDuration(t *testing.T) {
 	}
 }
 
+// TestDownloadStatistics_CollectionUsage tests that the downloaded bytes are added up per collection.
+func TestDownloadStatistics_CollectionUsage(t *testing.T) {
+	t.Parallel()
+
+	impl, ok := NewService(new(config.Config), nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "Service should be of type *ServiceImpl")
+
+	for _, task := range []*downloadTrackTask{
+		{metadata: &downloadTracksMetadata{category: DownloadCategoryAlbum}, parentID: "100", parentTitle: "Mutter"},
+		{metadata: &downloadTracksMetadata{category: DownloadCategoryAlbum}, parentID: "100", parentTitle: "Mutter"},
+		{metadata: &downloadTracksMetadata{category: DownloadCategoryPlaylist}, parentID: "7", parentTitle: "Favorites"},
+		// Standalone tracks are counted for their albums.
+		{metadata: &downloadTracksMetadata{category: DownloadCategoryTrack}, parentID: "100", parentTitle: "Mutter"},
+	} {
+		impl.recordDownloadedTrack(task, 1000)
+	}
+
+	impl.recordDownloadedTrack(
+		&downloadTrackTask{metadata: &downloadTracksMetadata{category: DownloadCategoryPlaylist}, parentID: "7"},
+		5000)
+
+	usage := sortedCollectionUsage(impl.Statistics())
+	require.Len(t, usage, 2)
+	assert.Equal(t, &CollectionUsage{
+		Category: DownloadCategoryPlaylist,
+		ItemID:   "7",
+		Title:    "Favorites",
+		Tracks:   2,
+		Bytes:    6000,
+	}, usage[0])
+	assert.Equal(t, &CollectionUsage{
+		Category: DownloadCategoryAlbum,
+		ItemID:   "100",
+		Title:    "Mutter",
+		Tracks:   3,
+		Bytes:    3000,
+	}, usage[1])
+}
+
+// TestDownloadStatistics_PhaseDurations tests that the time of every phase is added up, and a timer counts once.
+func TestDownloadStatistics_PhaseDurations(t *testing.T) {
+	t.Parallel()
+
+	impl, ok := NewService(new(config.Config), nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "Service should be of type *ServiceImpl")
+
+	impl.addDownloadDuration(2 * time.Second)
+	impl.addDownloadDuration(time.Second)
+	impl.addPhaseDuration(DownloadPhaseTagging, 500*time.Millisecond)
+
+	stopTimer := impl.startPhaseTimer(DownloadPhaseStream)
+	stopTimer()
+
+	streamDuration := impl.Statistics().PhaseDurations[DownloadPhaseStream]
+
+	// The deferred call after the explicit one must not add the time again.
+	time.Sleep(10 * time.Millisecond)
+	stopTimer()
+
+	stats := impl.Statistics()
+	assert.Equal(t, 3*time.Second, stats.PhaseDurations[DownloadPhaseDownload])
+	assert.Equal(t, 3*time.Second, stats.TotalDownloadDuration)
+	assert.Equal(t, 500*time.Millisecond, stats.PhaseDurations[DownloadPhaseTagging])
+	assert.Equal(t, streamDuration, stats.PhaseDurations[DownloadPhaseStream])
+	assert.NotContains(t, stats.PhaseDurations, DownloadPhaseMetadata)
+
+	summary := impl.newJSONSummary(context.Background(), stats)
+	assert.InDelta(t, 3.0, summary.PhaseSeconds["download"], 0.001)
+	assert.InDelta(t, 0.5, summary.PhaseSeconds["tagging"], 0.001)
+}
+
 // TestFormatDuration tests the formatDuration helper function.
 func TestFormatDuration(t *testing.T) {
 	t.Parallel()
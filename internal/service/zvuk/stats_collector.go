@@ -0,0 +1,62 @@
+package zvuk
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// statsCollector accumulates download statistics in a thread-safe manner.
+// Every counter is changed under one mutex, so a snapshot is always consistent:
+// e.g., TotalTracksProcessed never lags behind TracksDownloaded.
+type statsCollector struct {
+	mutex sync.Mutex
+	stats DownloadStatistics
+}
+
+// newStatsCollector creates an empty statistics collector.
+func newStatsCollector() *statsCollector {
+	return new(statsCollector)
+}
+
+// update applies a change to the statistics while holding the lock.
+func (c *statsCollector) update(change func(stats *DownloadStatistics)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	change(&c.stats)
+}
+
+// snapshot returns a deep copy of the collected statistics that is safe to read while downloads continue.
+func (c *statsCollector) snapshot() *DownloadStatistics {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := c.stats
+
+	result.QualitySizeEstimates = make(map[TrackQuality]*QualitySizeEstimate, len(c.stats.QualitySizeEstimates))
+	for quality, estimate := range c.stats.QualitySizeEstimates {
+		estimateCopy := *estimate
+		result.QualitySizeEstimates[quality] = &estimateCopy
+	}
+
+	result.ProjectedMP3Bytes = maps.Clone(c.stats.ProjectedMP3Bytes)
+
+	result.CollectionUsage = make(map[string]*CollectionUsage, len(c.stats.CollectionUsage))
+	for key, usage := range c.stats.CollectionUsage {
+		usageCopy := *usage
+		result.CollectionUsage[key] = &usageCopy
+	}
+
+	result.TracksSkippedByRule = maps.Clone(c.stats.TracksSkippedByRule)
+	result.PhaseDurations = maps.Clone(c.stats.PhaseDurations)
+	result.DownloadedTracks = slices.Clone(c.stats.DownloadedTracks)
+	result.SkippedItems = slices.Clone(c.stats.SkippedItems)
+	result.UntaggedTracks = slices.Clone(c.stats.UntaggedTracks)
+	result.QualityDowngrades = slices.Clone(c.stats.QualityDowngrades)
+	result.RelinkedReleases = slices.Clone(c.stats.RelinkedReleases)
+	result.RcloneFailures = slices.Clone(c.stats.RcloneFailures)
+	result.Errors = slices.Clone(c.stats.Errors)
+
+	return &result
+}
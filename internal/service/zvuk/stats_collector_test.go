@@ -0,0 +1,71 @@
+package zvuk
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatsCollector_ConcurrentUpdates tests that snapshots taken during updates stay consistent.
+func TestStatsCollector_ConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+
+	impl, ok := newTestDownloadSetup(t).service.(*ServiceImpl)
+	require.True(t, ok)
+
+	const workers, iterations = 8, 200
+
+	var waitGroup sync.WaitGroup
+
+	for range workers {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for range iterations {
+				impl.incrementTrackDownloaded("1", 10)
+				impl.incrementTrackSkipped("1", SkipReasonExists)
+				impl.recordSkippedItem(&SkippedItem{TrackID: "1", Reason: SkipReasonExists})
+
+				stats := impl.Statistics()
+				assert.Equal(t, stats.TracksDownloaded+stats.TracksSkipped, stats.TotalTracksProcessed)
+				assert.Equal(t, stats.TracksDownloaded*10, stats.TotalBytesDownloaded)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	stats := impl.Statistics()
+	assert.Equal(t, int64(workers*iterations), stats.TracksDownloaded)
+	assert.Equal(t, int64(workers*iterations), stats.TracksSkippedExists)
+	assert.Len(t, stats.SkippedItems, workers*iterations)
+}
+
+// TestStatsCollector_SnapshotIsIsolated tests that a snapshot does not change after further updates.
+func TestStatsCollector_SnapshotIsIsolated(t *testing.T) {
+	t.Parallel()
+
+	collector := newStatsCollector()
+	collector.update(func(stats *DownloadStatistics) {
+		stats.TracksDownloaded = 1
+		stats.SkippedItems = append(stats.SkippedItems, &SkippedItem{TrackID: "1"})
+		stats.QualitySizeEstimates = map[TrackQuality]*QualitySizeEstimate{TrackQualityFLAC: {Tracks: 1}}
+	})
+
+	snapshot := collector.snapshot()
+
+	collector.update(func(stats *DownloadStatistics) {
+		stats.TracksDownloaded++
+		stats.SkippedItems = append(stats.SkippedItems, &SkippedItem{TrackID: "2"})
+		stats.QualitySizeEstimates[TrackQualityFLAC].Tracks++
+	})
+
+	require.Len(t, snapshot.SkippedItems, 1)
+	assert.Equal(t, int64(1), snapshot.TracksDownloaded)
+	assert.Equal(t, int64(1), snapshot.QualitySizeEstimates[TrackQualityFLAC].Tracks)
+	assert.Equal(t, int64(2), collector.snapshot().TracksDownloaded)
+}
@@ -0,0 +1,154 @@
+package zvuk
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// statusRecentErrorsCount is the number of latest errors shown in a status dump.
+const statusRecentErrorsCount = 5
+
+// activeTrack is a track whose audio is being transferred right now.
+type activeTrack struct {
+	// name is the filename the track is saved to.
+	name string
+	// startTime is when the transfer began.
+	startTime time.Time
+	// totalBytes is the expected size of the audio.
+	totalBytes int64
+	// bytesWritten is the number of bytes transferred so far.
+	bytesWritten atomic.Int64
+}
+
+// Write counts transferred bytes; it is used as an io.Writer next to the destination file.
+func (t *activeTrack) Write(p []byte) (int, error) {
+	t.bytesWritten.Add(int64(len(p)))
+
+	return len(p), nil
+}
+
+// downloadStatusTracker keeps the live state of the queue for status dumps.
+type downloadStatusTracker struct {
+	// pendingTracks is the number of tracks of the collections in progress that have not started yet.
+	pendingTracks atomic.Int64
+	// activeTracksMutex protects activeTracks.
+	activeTracksMutex sync.Mutex
+	// activeTracks is the set of tracks being transferred.
+	activeTracks map[*activeTrack]struct{}
+}
+
+// newDownloadStatusTracker creates an empty status tracker.
+func newDownloadStatusTracker() *downloadStatusTracker {
+	return &downloadStatusTracker{
+		activeTracks: make(map[*activeTrack]struct{}),
+	}
+}
+
+// addPendingTracks changes the number of tracks waiting to be downloaded.
+func (t *downloadStatusTracker) addPendingTracks(delta int64) {
+	t.pendingTracks.Add(delta)
+}
+
+// startTrack registers a track transfer and returns it for byte counting.
+func (t *downloadStatusTracker) startTrack(name string, totalBytes int64) *activeTrack {
+	track := &activeTrack{
+		name:       name,
+		startTime:  time.Now(),
+		totalBytes: totalBytes,
+	}
+
+	t.activeTracksMutex.Lock()
+	t.activeTracks[track] = struct{}{}
+	t.activeTracksMutex.Unlock()
+
+	return track
+}
+
+// finishTrack removes a track transfer from the active set.
+func (t *downloadStatusTracker) finishTrack(track *activeTrack) {
+	t.activeTracksMutex.Lock()
+	delete(t.activeTracks, track)
+	t.activeTracksMutex.Unlock()
+}
+
+// activeTracksSnapshot returns the active transfers sorted by start time.
+func (t *downloadStatusTracker) activeTracksSnapshot() []*activeTrack {
+	t.activeTracksMutex.Lock()
+	result := make([]*activeTrack, 0, len(t.activeTracks))
+
+	for track := range t.activeTracks {
+		result = append(result, track)
+	}
+
+	t.activeTracksMutex.Unlock()
+
+	slices.SortFunc(result, func(a, b *activeTrack) int {
+		return a.startTime.Compare(b.startTime)
+	})
+
+	return result
+}
+
+// PrintStatus prints a live snapshot of the queue: active tracks, bytes so far, pending tracks, and recent errors.
+// It is safe to call while downloads are in progress.
+func (s *ServiceImpl) PrintStatus(ctx context.Context) {
+	var (
+		stats        = s.stats.snapshot()
+		activeTracks = s.status.activeTracksSnapshot()
+		pending      = max(s.status.pendingTracks.Load(), 0)
+		elapsed      time.Duration
+	)
+
+	if !stats.StartTime.IsZero() {
+		elapsed = time.Since(stats.StartTime)
+	}
+
+	logger.Info(ctx, "")
+	logger.Info(ctx, "──────────────────────── LIVE STATUS ────────────────────────")
+	logger.Infof(ctx, "Elapsed:          %s", formatDuration(elapsed))
+	logger.Infof(ctx, "Tracks:           %d processed (%d downloaded, %d skipped, %d failed), %d pending",
+		stats.TotalTracksProcessed, stats.TracksDownloaded, stats.TracksSkipped, stats.TracksFailed, pending)
+	logger.Infof(ctx, "Downloaded:       %s", humanize.IBytes(uint64(max(stats.TotalBytesDownloaded, 0))))
+
+	if len(activeTracks) == 0 {
+		logger.Info(ctx, "Active:           none")
+	} else {
+		logger.Infof(ctx, "Active:           %d", len(activeTracks))
+
+		for _, track := range activeTracks {
+			written := track.bytesWritten.Load()
+
+			var percent float64
+			if track.totalBytes > 0 {
+				percent = float64(written) / float64(track.totalBytes) * 100
+			}
+
+			logger.Infof(ctx, "  %s: %s / %s (%.0f%%), %s",
+				track.name,
+				humanize.IBytes(uint64(max(written, 0))),
+				humanize.IBytes(uint64(max(track.totalBytes, 0))),
+				percent,
+				formatDuration(time.Since(track.startTime)))
+		}
+	}
+
+	if len(stats.Errors) > 0 {
+		recentErrors := stats.Errors[max(len(stats.Errors)-statusRecentErrorsCount, 0):]
+
+		logger.Infof(ctx, "Recent Errors:    %d of %d", len(recentErrors), len(stats.Errors))
+
+		for _, e := range recentErrors {
+			logger.Infof(ctx, "  %s '%s': %s", e.Category, strings.TrimSpace(e.ItemTitle), e.Error)
+		}
+	}
+
+	logger.Info(ctx, "─────────────────────────────────────────────────────────────")
+}
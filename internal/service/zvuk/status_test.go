@@ -0,0 +1,78 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestDownloadStatusTracker_ActiveTracks tests that transfers are tracked until they finish.
+func TestDownloadStatusTracker_ActiveTracks(t *testing.T) {
+	t.Parallel()
+
+	tracker := newDownloadStatusTracker()
+
+	first := tracker.startTrack("01 - First.flac", 100)
+	second := tracker.startTrack("02 - Second.flac", 200)
+
+	_, err := first.Write(make([]byte, 40))
+	require.NoError(t, err)
+
+	activeTracks := tracker.activeTracksSnapshot()
+	require.Len(t, activeTracks, 2)
+	assert.Equal(t, "01 - First.flac", activeTracks[0].name)
+	assert.Equal(t, int64(40), activeTracks[0].bytesWritten.Load())
+
+	tracker.finishTrack(first)
+
+	activeTracks = tracker.activeTracksSnapshot()
+	require.Len(t, activeTracks, 1)
+	assert.Same(t, second, activeTracks[0])
+}
+
+// TestDownloadTracks_PendingTracksReleasedOnCancel tests that unstarted tracks do not stay pending.
+func TestDownloadTracks_PendingTracksReleasedOnCancel(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t)
+
+	impl, ok := setup.service.(*ServiceImpl)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	impl.downloadTracks(ctx, &downloadTracksMetadata{
+		category: DownloadCategoryAlbum,
+		trackIDs: []int64{1, 2, 3},
+	})
+
+	assert.Equal(t, int64(0), impl.status.pendingTracks.Load())
+}
+
+// TestPrintStatus tests that a status dump can be printed at any moment of the session.
+func TestPrintStatus(t *testing.T) {
+	t.Parallel()
+
+	impl, ok := NewService(new(config.Config), nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok)
+
+	// Before the session starts.
+	impl.PrintStatus(context.Background())
+
+	impl.status.addPendingTracks(3)
+	impl.status.startTrack("01 - Track.flac", 0)
+	impl.incrementTrackDownloaded("1", 1024)
+	impl.appendError(&DownloadError{
+		Category:  DownloadCategoryTrack,
+		ItemTitle: "Broken",
+		Error:     errors.New("stream unavailable"),
+	})
+
+	assert.NotPanics(t, func() { impl.PrintStatus(context.Background()) })
+}
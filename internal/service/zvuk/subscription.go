@@ -2,20 +2,30 @@ package zvuk
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
 
 // checkUserSubscription checks the user's subscription status.
+// A rejected token exits with ExitCodeAuthFailure, while network and server failures exit with ExitCodeFailure.
 func (s *ServiceImpl) checkUserSubscription(ctx context.Context) {
 	userProfile, err := s.zvukClient.GetUserProfile(ctx)
 	if err != nil {
-		logger.Fatalf(ctx, "Failed to retrieve user profile: %v", err)
+		exitCode := constants.ExitCodeFailure
+		if errors.Is(err, zvuk.ErrAuthTokenRejected) {
+			exitCode = constants.ExitCodeAuthFailure
+		}
+
+		logger.FatalCodef(ctx, exitCode, "Failed to retrieve user profile: %v", err)
 	}
 
 	if userProfile.Subscription == nil {
-		logger.Fatalf(ctx, "User does not have an active subscription")
+		logger.FatalCodef(ctx, constants.ExitCodeAuthFailure,
+			"User does not have an active subscription")
 	}
 
 	expiration := time.UnixMilli(userProfile.Subscription.Expiration).Format(time.RFC1123)
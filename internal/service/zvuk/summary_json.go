@@ -0,0 +1,233 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// Run statuses reported in the JSON summary.
+const (
+	// JSONSummaryStatusCompleted means that every item was processed without errors.
+	JSONSummaryStatusCompleted = "completed"
+	// JSONSummaryStatusCompletedWithErrors means that every item was processed, but some of them failed.
+	JSONSummaryStatusCompletedWithErrors = "completed_with_errors"
+	// JSONSummaryStatusInterrupted means that the run was interrupted (CTRL+C).
+	JSONSummaryStatusInterrupted = "interrupted"
+	// JSONSummaryStatusAborted means that the run was canceled on the first error (--fail-fast).
+	JSONSummaryStatusAborted = "aborted"
+	// JSONSummaryStatusFailed means that the run stopped before downloading anything, e.g., ffmpeg is missing.
+	JSONSummaryStatusFailed = "failed"
+)
+
+// JSONSummary is the outcome of a run printed by --json, meant to be parsed by scripts.
+type JSONSummary struct {
+	// Status is the outcome of the run ("completed", "completed_with_errors", "interrupted", "aborted", or "failed").
+	Status string `json:"status"`
+	// AbortReason is the error that canceled the run in fail-fast mode.
+	AbortReason string `json:"abort_reason,omitempty"`
+	// Error is the error that stopped the run before anything was downloaded.
+	Error string `json:"error,omitempty"`
+	// IsDryRun indicates that nothing was downloaded, and the tracks are the ones that would be.
+	IsDryRun bool `json:"dry_run"`
+	// StartedAt is when the run began.
+	StartedAt time.Time `json:"started_at"`
+	// FinishedAt is when the run completed.
+	FinishedAt time.Time `json:"finished_at"`
+	// DurationSeconds is the wall-clock duration of the run.
+	DurationSeconds float64 `json:"duration_seconds"`
+	// PhaseSeconds is the cumulative time spent in every phase of the downloads, summed over the workers.
+	PhaseSeconds map[string]float64 `json:"phase_seconds"`
+	// Tracks counts the processed tracks.
+	Tracks *JSONTrackCounts `json:"tracks"`
+	// BytesDownloaded is the total size of the downloaded audio.
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+	// APIRequests is the number of requests sent to the API, including audio and cover downloads.
+	APIRequests int64 `json:"api_requests"`
+	// Collections breaks the size of the downloaded audio down by collection, the largest first.
+	Collections []*CollectionUsage `json:"collections"`
+	// Downloaded lists the downloaded tracks.
+	Downloaded []*DownloadedTrack `json:"downloaded"`
+	// Skipped lists the skipped tracks with their reasons.
+	Skipped []*SkippedItem `json:"skipped"`
+	// Errors lists the failed items.
+	Errors []*JSONDownloadError `json:"errors"`
+	// UntaggedTracks lists the downloaded tracks kept without tags.
+	UntaggedTracks []*UntaggedTrack `json:"untagged_tracks,omitempty"`
+	// QualityDowngrades lists the tracks not available in the requested quality.
+	QualityDowngrades []*QualityDowngrade `json:"quality_downgrades,omitempty"`
+	// RelinkedReleases lists the albums whose folder was moved to their re-issue.
+	RelinkedReleases []*RelinkedRelease `json:"relinked_releases,omitempty"`
+	// RcloneFailures lists the collections and tracks rclone failed to push.
+	RcloneFailures []*RcloneFailure `json:"rclone_failures,omitempty"`
+}
+
+// JSONTrackCounts counts the processed tracks in the JSON summary.
+type JSONTrackCounts struct {
+	// Processed is the number of tracks attempted.
+	Processed int64 `json:"processed"`
+	// Downloaded is the number of tracks downloaded.
+	Downloaded int64 `json:"downloaded"`
+	// Skipped is the number of tracks skipped for any reason.
+	Skipped int64 `json:"skipped"`
+	// SkippedByReason is the number of skipped tracks for every skip reason.
+	SkippedByReason map[string]int64 `json:"skipped_by_reason"`
+	// SkippedByRule is the number of tracks skipped by every rule of the track validator.
+	SkippedByRule map[string]int64 `json:"skipped_by_rule"`
+	// Linked is the number of repeated playlist tracks saved as hard links.
+	Linked int64 `json:"linked"`
+	// Failed is the number of tracks that failed to download.
+	Failed int64 `json:"failed"`
+}
+
+// JSONDownloadError is a failed item in the JSON summary.
+type JSONDownloadError struct {
+	// Category is the type of item that failed (track, album, playlist, etc.).
+	Category DownloadCategory `json:"category"`
+	// ItemID is the unique identifier of the item that failed.
+	ItemID string `json:"item_id"`
+	// ItemTitle is the human-readable title of the item.
+	ItemTitle string `json:"item_title"`
+	// ItemURL is the URL of the failed item (for albums/playlists/artists).
+	ItemURL string `json:"item_url,omitempty"`
+	// ParentCategory is the type of parent collection (album/playlist) for tracks.
+	ParentCategory DownloadCategory `json:"parent_category,omitempty"`
+	// ParentID is the ID of the parent collection.
+	ParentID string `json:"parent_id,omitempty"`
+	// ParentTitle is the title of the parent collection.
+	ParentTitle string `json:"parent_title,omitempty"`
+	// Phase indicates when the error occurred.
+	Phase string `json:"phase"`
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// Summary returns the outcome of the run as the document printed by --json.
+// It is safe to call while downloads are in progress.
+func (s *ServiceImpl) Summary(ctx context.Context) *JSONSummary {
+	return s.newJSONSummary(ctx, s.stats.snapshot())
+}
+
+// printJSONSummary writes the statistics as a single JSON document instead of the summary.
+func (s *ServiceImpl) printJSONSummary(ctx context.Context, stats *DownloadStatistics) {
+	encoder := json.NewEncoder(s.jsonOutput)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(s.newJSONSummary(ctx, stats)); err != nil {
+		logger.Errorf(ctx, "Failed to write the JSON summary: %v", err)
+	}
+}
+
+// newJSONSummary converts the statistics to the JSON summary.
+// The lists are never null, so scripts can iterate them without checks.
+func (s *ServiceImpl) newJSONSummary(ctx context.Context, stats *DownloadStatistics) *JSONSummary {
+	summary := &JSONSummary{
+		Status:          JSONSummaryStatusCompleted,
+		IsDryRun:        stats.IsDryRun,
+		StartedAt:       stats.StartTime,
+		FinishedAt:      stats.EndTime,
+		BytesDownloaded: stats.TotalBytesDownloaded,
+		APIRequests:     s.apiRequestsCount(),
+		Collections:     sortedCollectionUsage(stats),
+		PhaseSeconds:    make(map[string]float64, len(stats.PhaseDurations)),
+		Tracks: &JSONTrackCounts{
+			Processed:       stats.TotalTracksProcessed,
+			Downloaded:      stats.TracksDownloaded,
+			Skipped:         stats.TracksSkipped,
+			SkippedByReason: skippedByReason(stats),
+			SkippedByRule:   make(map[string]int64, len(stats.TracksSkippedByRule)),
+			Linked:          stats.TracksLinked,
+			Failed:          stats.TracksFailed,
+		},
+		Downloaded:        stats.DownloadedTracks,
+		Skipped:           stats.SkippedItems,
+		Errors:            make([]*JSONDownloadError, 0, len(stats.Errors)),
+		UntaggedTracks:    stats.UntaggedTracks,
+		QualityDowngrades: stats.QualityDowngrades,
+		RelinkedReleases:  stats.RelinkedReleases,
+		RcloneFailures:    stats.RcloneFailures,
+	}
+
+	if !stats.StartTime.IsZero() && !stats.EndTime.IsZero() {
+		summary.DurationSeconds = stats.EndTime.Sub(stats.StartTime).Seconds()
+	}
+
+	maps.Copy(summary.Tracks.SkippedByRule, stats.TracksSkippedByRule)
+
+	for phase, d := range stats.PhaseDurations {
+		summary.PhaseSeconds[phase.Code()] = d.Seconds()
+	}
+
+	if summary.Downloaded == nil {
+		summary.Downloaded = []*DownloadedTrack{}
+	}
+
+	if summary.Skipped == nil {
+		summary.Skipped = []*SkippedItem{}
+	}
+
+	for _, downloadErr := range stats.Errors {
+		item := &JSONDownloadError{
+			Category:       downloadErr.Category,
+			ItemID:         downloadErr.ItemID,
+			ItemTitle:      downloadErr.ItemTitle,
+			ItemURL:        downloadErr.ItemURL,
+			ParentCategory: downloadErr.ParentCategory,
+			ParentID:       downloadErr.ParentID,
+			ParentTitle:    downloadErr.ParentTitle,
+			Phase:          downloadErr.Phase,
+		}
+
+		if downloadErr.Error != nil {
+			item.Error = logger.Redact(downloadErr.Error.Error())
+		}
+
+		summary.Errors = append(summary.Errors, item)
+	}
+
+	abortReason := s.AbortReason()
+	runError := s.RunError()
+
+	switch {
+	case runError != nil:
+		summary.Status = JSONSummaryStatusFailed
+		summary.Error = logger.Redact(runError.Error())
+	case abortReason != nil:
+		summary.Status = JSONSummaryStatusAborted
+		summary.AbortReason = logger.Redact(abortReason.Error())
+	case ctx.Err() != nil:
+		summary.Status = JSONSummaryStatusInterrupted
+	case len(stats.Errors) > 0:
+		summary.Status = JSONSummaryStatusCompletedWithErrors
+	}
+
+	return summary
+}
+
+// skippedByReason returns the number of skipped tracks for every skip reason that occurred.
+func skippedByReason(stats *DownloadStatistics) map[string]int64 {
+	counts := map[SkipReason]int64{
+		SkipReasonExists:     stats.TracksSkippedExists,
+		SkipReasonQuality:    stats.TracksSkippedQuality,
+		SkipReasonDuration:   stats.TracksSkippedDuration,
+		SkipReasonExcluded:   stats.TracksSkippedExcluded,
+		SkipReasonDuplicate:  stats.TracksSkippedDuplicate,
+		SkipReasonSynced:     stats.TracksSkippedSynced,
+		SkipReasonDownloaded: stats.TracksSkippedDownloaded,
+		SkipReasonInLibrary:  stats.TracksSkippedInLibrary,
+		SkipReasonBlocked:    stats.TracksSkippedBlocked,
+	}
+
+	result := make(map[string]int64, len(counts))
+
+	for reason, count := range counts {
+		if count > 0 {
+			result[reason.String()] = count
+		}
+	}
+
+	return result
+}
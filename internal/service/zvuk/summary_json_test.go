@@ -0,0 +1,111 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestPrintDownloadSummary_JSON tests that --json prints the results as a single JSON document.
+func TestPrintDownloadSummary_JSON(t *testing.T) {
+	t.Parallel()
+
+	newService := func(t *testing.T) (*ServiceImpl, *bytes.Buffer) {
+		t.Helper()
+
+		impl, ok := NewService(&config.Config{JSONOutput: true}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		var output bytes.Buffer
+
+		impl.jsonOutput = &output
+
+		return impl, &output
+	}
+
+	t.Run("results are reported per item", func(t *testing.T) {
+		t.Parallel()
+
+		impl, output := newService(t)
+
+		impl.incrementTrackDownloaded("1", 1000)
+		impl.recordDownloadedTrack(&downloadTrackTask{
+			trackIDString: "1",
+			track:         &zvuk.Track{ID: 1, Title: "Sonne", ArtistNames: []string{"Rammstein"}},
+			metadata:      &downloadTracksMetadata{category: DownloadCategoryAlbum},
+			parentID:      "100",
+			parentTitle:   "Mutter",
+			quality:       TrackQualityFLAC,
+			trackPath:     "Rammstein/Mutter/01 - Sonne.flac",
+		}, 1000)
+
+		impl.handleTrackSkipped(SkipReasonExcluded, "", &DownloadError{
+			Category:       DownloadCategoryTrack,
+			ItemID:         "2",
+			ItemTitle:      "Sonne (Remix)",
+			ParentCategory: DownloadCategoryAlbum,
+			ParentID:       "100",
+			ParentTitle:    "Mutter",
+			Phase:          "exclusion check",
+			Error:          ErrExcludedByPattern,
+		})
+
+		impl.PrintDownloadSummary(context.Background())
+
+		var summary JSONSummary
+		require.NoError(t, json.Unmarshal(output.Bytes(), &summary))
+
+		assert.Equal(t, JSONSummaryStatusCompletedWithErrors, summary.Status)
+		assert.Equal(t, int64(2), summary.Tracks.Processed)
+		assert.Equal(t, map[string]int64{"excluded by pattern": 1}, summary.Tracks.SkippedByReason)
+		assert.Equal(t, int64(1000), summary.BytesDownloaded)
+
+		require.Len(t, summary.Downloaded, 1)
+		assert.Equal(t, "Rammstein/Mutter/01 - Sonne.flac", summary.Downloaded[0].Path)
+		assert.Equal(t, DownloadCategoryAlbum, summary.Downloaded[0].ParentCategory)
+		assert.Equal(t, int64(1000), summary.Downloaded[0].Bytes)
+
+		require.Len(t, summary.Collections, 1)
+		assert.Equal(t, &CollectionUsage{
+			Category: DownloadCategoryAlbum,
+			ItemID:   "100",
+			Title:    "Mutter",
+			Tracks:   1,
+			Bytes:    1000,
+		}, summary.Collections[0])
+
+		require.Len(t, summary.Skipped, 1)
+		assert.Equal(t, SkipReasonExcluded, summary.Skipped[0].Reason)
+
+		require.Len(t, summary.Errors, 1)
+		assert.Equal(t, "excluded by pattern", summary.Errors[0].Error)
+		assert.Contains(t, output.String(), `"category": "track"`)
+	})
+
+	t.Run("empty run still prints a document", func(t *testing.T) {
+		t.Parallel()
+
+		impl, output := newService(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		impl.PrintDownloadSummary(ctx)
+
+		var summary map[string]any
+		require.NoError(t, json.Unmarshal(output.Bytes(), &summary))
+
+		assert.Equal(t, JSONSummaryStatusInterrupted, summary["status"])
+		assert.Equal(t, []any{}, summary["downloaded"])
+		assert.Equal(t, []any{}, summary["skipped"])
+		assert.Equal(t, []any{}, summary["errors"])
+		assert.Equal(t, []any{}, summary["collections"])
+	})
+}
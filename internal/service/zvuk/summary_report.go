@@ -0,0 +1,124 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// summaryReportTimeLayout is the timestamp layout of the detailed summary filenames.
+const summaryReportTimeLayout = "2006-01-02_15-04-05"
+
+// errorCount is the number of errors of an album, playlist, or artist shown in the short summary.
+type errorCount struct {
+	// title describes the collection the errors belong to.
+	title string
+	// count is the number of errors.
+	count int
+}
+
+// writeSummaryReport saves the full summary, with every error, to a timestamped text file in the output path.
+// It returns the path of the file.
+func (s *ServiceImpl) writeSummaryReport(
+	ctx context.Context,
+	stats *DownloadStatistics,
+	wasInterrupted bool,
+) (string, error) {
+	if err := os.MkdirAll(s.cfg.OutputPath, defaultFolderPermissions); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	reportPath := filepath.Join(s.cfg.OutputPath,
+		fmt.Sprintf("zvuk-grabber-summary-%s.txt", time.Now().Format(summaryReportTimeLayout)))
+
+	var report strings.Builder
+
+	// The summary is printed through the logger, so it is rendered into the file by a plain logger.
+	reportLogger := logger.NewPlain(&report)
+	s.printSummary(logger.ToContext(ctx, reportLogger), stats, wasInterrupted, "")
+
+	if err := os.WriteFile(reportPath, []byte(report.String()), constants.DefaultFilePermissions); err != nil {
+		return "", fmt.Errorf("failed to write detailed summary: %w", err)
+	}
+
+	return reportPath, nil
+}
+
+// printErrorCounts prints the number of errors of every collection instead of the errors themselves,
+// pointing to the detailed summary saved to reportPath.
+func (s *ServiceImpl) printErrorCounts(ctx context.Context, stats *DownloadStatistics, reportPath string) {
+	if len(stats.Errors) == 0 {
+		return
+	}
+
+	logger.Info(ctx, "")
+	logger.Errorf(ctx, "ERRORS ENCOUNTERED: %d", len(stats.Errors))
+	logger.Info(ctx, "")
+
+	counts := countErrorsByCollection(stats.Errors)
+	shownCount := min(len(counts), int(s.cfg.SummaryErrorLimit))
+
+	for _, item := range counts[:shownCount] {
+		logger.Errorf(ctx, "  %s: %d error(s)", item.title, item.count)
+	}
+
+	if len(counts) > shownCount {
+		logger.Errorf(ctx, "  ...and %d more", len(counts)-shownCount)
+	}
+
+	logger.Info(ctx, "")
+	logger.Infof(ctx, "Every error is listed in the detailed summary: %s", reportPath)
+	logger.Info(ctx, "")
+	logger.Info(ctx, "═══════════════════════════════════════════════════════════════")
+
+	s.printRetryCommand(ctx, stats.Errors)
+}
+
+// countErrorsByCollection counts the errors of every collection, with the errors of tracks
+// counted for the collections they belong to. The collections with the most errors come first.
+func countErrorsByCollection(errs []*DownloadError) []*errorCount {
+	var (
+		counts = make([]*errorCount, 0, len(errs))
+		byKey  = make(map[string]*errorCount, len(errs))
+	)
+
+	for _, downloadErr := range errs {
+		var key, title string
+
+		switch {
+		case downloadErr.Category != DownloadCategoryTrack:
+			key = downloadErr.Category.String() + ":" + downloadErr.ItemID
+			title = fmt.Sprintf("%s: %s (ID: %s)",
+				downloadErr.Category, downloadErr.ItemTitle, downloadErr.ItemID)
+		case downloadErr.ParentID != "":
+			key = downloadErr.ParentCategory.String() + ":" + downloadErr.ParentID
+			title = fmt.Sprintf("%s: %s (ID: %s)",
+				downloadErr.ParentCategory, downloadErr.ParentTitle, downloadErr.ParentID)
+		default:
+			key = unknownParentKey
+			title = "Tracks from unknown collections"
+		}
+
+		item := byKey[key]
+		if item == nil {
+			item = &errorCount{title: title}
+			byKey[key] = item
+			counts = append(counts, item)
+		}
+
+		item.count++
+	}
+
+	slices.SortStableFunc(counts, func(a, b *errorCount) int {
+		return b.count - a.count
+	})
+
+	return counts
+}
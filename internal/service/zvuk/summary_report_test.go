@@ -0,0 +1,87 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// TestPrintDownloadSummary_DetailedReport tests that a summary with too many errors is saved to a file
+// and only counted in the console.
+func TestPrintDownloadSummary_DetailedReport(t *testing.T) {
+	t.Parallel()
+
+	newService := func(t *testing.T, errorsCount int) (*ServiceImpl, string) {
+		t.Helper()
+
+		outputPath := t.TempDir()
+
+		impl, ok := NewService(&config.Config{OutputPath: outputPath, SummaryErrorLimit: 2},
+			nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		impl.incrementTrackDownloaded("1", 1000)
+
+		for i := range errorsCount {
+			impl.recordError(&DownloadError{
+				Category:       DownloadCategoryTrack,
+				ItemID:         strconv.Itoa(i + 1),
+				ItemTitle:      "Track " + strconv.Itoa(i+1),
+				Phase:          "downloading file",
+				ParentCategory: DownloadCategoryAlbum,
+				ParentID:       "100",
+				ParentTitle:    "Mutter",
+				Error:          assert.AnError,
+			})
+		}
+
+		return impl, outputPath
+	}
+
+	t.Run("errors above the limit are saved to a file", func(t *testing.T) {
+		t.Parallel()
+
+		impl, outputPath := newService(t, 3)
+
+		var console bytes.Buffer
+
+		impl.PrintDownloadSummary(logger.ToContext(context.Background(), logger.NewPlain(&console)))
+
+		reportPaths, err := filepath.Glob(filepath.Join(outputPath, "zvuk-grabber-summary-*.txt"))
+		require.NoError(t, err)
+		require.Len(t, reportPaths, 1)
+
+		report, err := os.ReadFile(reportPaths[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(report), "DOWNLOAD SUMMARY")
+		assert.Contains(t, string(report), "[3] Track 3")
+
+		assert.Contains(t, console.String(), "album: Mutter (ID: 100): 3 error(s)")
+		assert.Contains(t, console.String(), "Every error is listed in the detailed summary: "+reportPaths[0])
+		assert.NotContains(t, console.String(), "Track 3")
+	})
+
+	t.Run("errors within the limit are printed", func(t *testing.T) {
+		t.Parallel()
+
+		impl, outputPath := newService(t, 2)
+
+		var console bytes.Buffer
+
+		impl.PrintDownloadSummary(logger.ToContext(context.Background(), logger.NewPlain(&console)))
+
+		entries, err := os.ReadDir(outputPath)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.Contains(t, console.String(), "[2] Track 2")
+	})
+}
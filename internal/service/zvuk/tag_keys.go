@@ -15,15 +15,19 @@ const (
 	TagAlbumID          = "albumID"
 	TagAlbumTitle       = "albumTitle"
 	TagAlbumTrackCount  = "albumTrackCount"
+	TagBarcode          = "barcode"
+	TagCatalogNumber    = "catalogNumber"
 	TagRecordLabel      = "recordLabel"
 	TagReleaseDate      = "releaseDate"
 	TagReleaseTimestamp = "releaseTimestamp"
 	TagReleaseYear      = "releaseYear"
 
 	// TagPlaylistID is a playlist tag key.
-	TagPlaylistID         = "playlistID"
-	TagPlaylistTitle      = "playlistTitle"
-	TagPlaylistTrackCount = "playlistTrackCount"
+	TagPlaylistID          = "playlistID"
+	TagPlaylistTitle       = "playlistTitle"
+	TagPlaylistTrackCount  = "playlistTrackCount"
+	TagPlaylistPosition    = "playlistPosition"
+	TagPlaylistPositionPad = "playlistPositionPad"
 
 	// TagTrackArtist is a track (common) tag key.
 	TagTrackArtist    = "trackArtist"
@@ -67,4 +71,7 @@ const (
 	TagEpisodeNumber          = "episodeNumber"
 	TagEpisodeNumberPad       = "episodeNumberPad"
 	TagEpisodeDuration        = "episodeDuration"
+
+	// TagTrackFilename is a lyrics filename template key: the track filename without its extension.
+	TagTrackFilename = "trackFilename"
 )
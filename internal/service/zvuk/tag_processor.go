@@ -8,6 +8,7 @@ import (
 	"mime"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-flac/flacpicture"
@@ -34,10 +35,23 @@ type WriteTagsRequest struct {
 	Quality TrackQuality
 	// TrackTags contains metadata key-value pairs to write.
 	TrackTags map[string]string
+	// TrackArtists contains every credited artist, written as separate ARTISTS entries.
+	TrackArtists []string
 	// TrackLyrics contains the lyrics data for the track.
 	TrackLyrics *zvuk.Lyrics
 	// IsCoverEmbeddedToTrackTags indicates whether cover art is embedded in the audio file.
 	IsCoverEmbeddedToTrackTags bool
+	// CoverMaxSize is the largest side in pixels the embedded cover is scaled down to (0 keeps its size).
+	CoverMaxSize int
+	// IsExistingCoverRemoved indicates whether the pictures already in a FLAC file are dropped,
+	// so the file keeps only the written cover, if any. MP3 tags are always replaced.
+	IsExistingCoverRemoved bool
+	// IsExistingTagsReplaced indicates whether the tags and pictures already in a FLAC file are dropped
+	// instead of being kept next to the written ones. MP3 tags are always replaced.
+	IsExistingTagsReplaced bool
+	// FLACPadding is the size of the single padding block a FLAC file is saved with
+	// (nil keeps the padding of the file, 0 removes it).
+	FLACPadding *int64
 }
 
 // TagProcessorImpl provides the default implementation of TagProcessor.
@@ -59,6 +73,9 @@ type extractFLACCommentResult struct {
 	Index int
 }
 
+// id3v2V4 is the major version of ID3v2.4 tags.
+const id3v2V4 = 4
+
 // Static error definitions for better error handling.
 var (
 	// ErrEmptyTrackPath indicates that the track file path is empty.
@@ -96,6 +113,10 @@ func (tp *TagProcessorImpl) WriteTags(ctx context.Context, req *WriteTagsRequest
 			data:     imageData,
 			mimeType: imageMIMEType,
 		}
+
+		if req.CoverMaxSize > 0 {
+			image = shrinkEmbeddedCover(ctx, image, req.CoverMaxSize)
+		}
 	}
 
 	// Write tags based on the track quality (FLAC or MP3).
@@ -121,8 +142,8 @@ func (tp *TagProcessorImpl) writeFLACTags(ctx context.Context, req *WriteTagsReq
 
 	tag := commentResult.Comment
 
-	// If no existing comments are found, create a new metadata block.
-	if tag == nil {
+	// If no existing comments are found or they are replaced, create a new metadata block.
+	if tag == nil || req.IsExistingTagsReplaced {
 		tag = flacvorbis.New()
 	}
 
@@ -140,9 +161,27 @@ func (tp *TagProcessorImpl) writeFLACTags(ctx context.Context, req *WriteTagsReq
 		f.Meta = append(f.Meta, &tagMeta)
 	}
 
+	// Drop the pictures embedded earlier, so the file keeps only the written cover.
+	if req.IsExistingTagsReplaced || req.IsExistingCoverRemoved {
+		f.Meta = slices.DeleteFunc(f.Meta, func(meta *flac.MetaDataBlock) bool {
+			return meta.Type == flac.Picture
+		})
+	}
+
 	// Embed the cover art into the FLAC file if provided.
 	tp.embedFLACCover(ctx, f, image)
 
+	// Replace the padding with a single block of the requested size, placed last.
+	if req.FLACPadding != nil {
+		f.Meta = slices.DeleteFunc(f.Meta, func(meta *flac.MetaDataBlock) bool {
+			return meta.Type == flac.Padding
+		})
+
+		if *req.FLACPadding > 0 {
+			f.Meta = append(f.Meta, &flac.MetaDataBlock{Type: flac.Padding, Data: make([]byte, *req.FLACPadding)})
+		}
+	}
+
 	// Save the updated FLAC file.
 	return f.Save(req.TrackPath)
 }
@@ -181,21 +220,23 @@ func (tp *TagProcessorImpl) extractFLACComment(filename string) (*extractFLACCom
 func (tp *TagProcessorImpl) addFLACTags(tag *flacvorbis.MetaDataBlockVorbisComment, req *WriteTagsRequest) error {
 	// Map of FLAC tag keys to their corresponding values in req.TrackTags.
 	flacTags := map[string]string{
-		"ALBUM":       req.TrackTags["collectionTitle"],
-		"ALBUMARTIST": req.TrackTags["albumArtist"],
-		"ARTIST":      req.TrackTags["trackArtist"],
-		"COPYRIGHT":   req.TrackTags["recordLabel"],
-		"DATE":        req.TrackTags["releaseDate"],
-		"GENRE":       req.TrackTags["trackGenre"],
-		"PLAYLIST_ID": req.TrackTags["playlistID"],
-		"RELEASE_ID":  req.TrackTags["albumID"],
-		"TITLE":       req.TrackTags["trackTitle"],
-		"TOTALTRACKS": req.TrackTags["trackCount"],
-		"TRACK_ID":    req.TrackTags["trackID"],
-		"TRACKNUMBER": req.TrackTags["trackNumber"],
-		"YEAR":        req.TrackTags["releaseYear"],
-		"DESCRIPTION": req.TrackTags["audiobookDescription"],
-		"PERFORMER":   req.TrackTags["audiobookPerformers"],
+		"ALBUM":         req.TrackTags["collectionTitle"],
+		"ALBUMARTIST":   req.TrackTags["albumArtist"],
+		"ARTIST":        req.TrackTags["trackArtist"],
+		"BARCODE":       req.TrackTags["barcode"],
+		"CATALOGNUMBER": req.TrackTags["catalogNumber"],
+		"COPYRIGHT":     req.TrackTags["recordLabel"],
+		"DATE":          req.TrackTags["releaseDate"],
+		"GENRE":         req.TrackTags["trackGenre"],
+		"PLAYLIST_ID":   req.TrackTags["playlistID"],
+		"RELEASE_ID":    req.TrackTags["albumID"],
+		"TITLE":         req.TrackTags["trackTitle"],
+		"TOTALTRACKS":   req.TrackTags["trackCount"],
+		"TRACK_ID":      req.TrackTags["trackID"],
+		"TRACKNUMBER":   req.TrackTags["trackNumber"],
+		"YEAR":          req.TrackTags["releaseYear"],
+		"DESCRIPTION":   req.TrackTags["audiobookDescription"],
+		"PERFORMER":     req.TrackTags["audiobookPerformers"],
 	}
 
 	if req.TrackLyrics != nil && strings.TrimSpace(req.TrackLyrics.Lyrics) != "" {
@@ -214,6 +255,14 @@ func (tp *TagProcessorImpl) addFLACTags(tag *flacvorbis.MetaDataBlockVorbisComme
 		}
 	}
 
+	// Vorbis comments support multiple values natively: one ARTISTS entry per artist.
+	for _, artist := range req.TrackArtists {
+		err := tag.Add("ARTISTS", artist)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -297,6 +346,47 @@ func (tp *TagProcessorImpl) addMP3Tags(ctx context.Context, tag *id3v2.Tag, req
 	tag.AddTextFrame(tag.CommonID("Band/Orchestra/Accompaniment"), tag.DefaultEncoding(), req.TrackTags["albumArtist"])
 	tag.AddTextFrame(tag.CommonID("Publisher"), tag.DefaultEncoding(), req.TrackTags["recordLabel"])
 
+	// ID3v2.4 separates multiple values with a null byte, ID3v2.3 readers expect a slash.
+	if len(req.TrackArtists) > 0 {
+		separator := "/"
+		if tag.Version() == id3v2V4 {
+			separator = "\x00"
+		}
+
+		//nolint:exhaustruct // Multi is only used when parsing frames.
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    tag.DefaultEncoding(),
+			Description: "ARTISTS",
+			Value:       strings.Join(req.TrackArtists, separator),
+		})
+	}
+
+	// The track ID lets the verify command match the file to the track metadata, as TRACK_ID does in FLAC,
+	// and the playlist ID lets the tag command know the file was saved with a playlist.
+	// Release identifiers use the TXXX descriptions common taggers read.
+	userDefinedTags := []struct {
+		description string
+		value       string
+	}{
+		{description: "TRACK_ID", value: req.TrackTags["trackID"]},
+		{description: "PLAYLIST_ID", value: req.TrackTags["playlistID"]},
+		{description: "BARCODE", value: req.TrackTags["barcode"]},
+		{description: "CATALOGNUMBER", value: req.TrackTags["catalogNumber"]},
+	}
+
+	for _, userDefinedTag := range userDefinedTags {
+		if userDefinedTag.value == "" {
+			continue
+		}
+
+		//nolint:exhaustruct // Multi is only used when parsing frames.
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    tag.DefaultEncoding(),
+			Description: userDefinedTag.description,
+			Value:       userDefinedTag.value,
+		})
+	}
+
 	// Add audiobook-specific metadata.
 	if req.TrackTags["audiobookPerformers"] != "" {
 		tag.AddTextFrame(
@@ -0,0 +1,141 @@
+package zvuk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+const (
+	// untaggedSuffix is appended to downloaded audio that could not be tagged.
+	untaggedSuffix = ".untagged"
+	// untaggedMarker is inserted before the extension of downloaded audio that could not be tagged.
+	untaggedMarker = " [untagged]"
+)
+
+// writeTagsWithTimeout writes tags, giving up after tag_write_timeout.
+// The tag libraries cannot be interrupted, so on timeout the writer is left running in the background
+// and the track is reported as failed instead of blocking the worker forever.
+// The tags are written to a private copy that replaces the track only on success, so the abandoned writer
+// never touches the file the caller renames or deletes next; the copy is removed once the writer returns.
+func (s *ServiceImpl) writeTagsWithTimeout(ctx context.Context, req *WriteTagsRequest) error {
+	timeout := s.cfg.ParsedTagWriteTimeout
+	if timeout <= 0 {
+		return s.tagProcessor.WriteTags(ctx, req)
+	}
+
+	taggingPath, err := copyForTagging(req.TrackPath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		startTime  = time.Now()
+		done       = make(chan error, 1)
+		taggingReq = *req
+	)
+
+	taggingReq.TrackPath = taggingPath
+
+	go func() {
+		done <- s.tagProcessor.WriteTags(ctx, &taggingReq)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+		if err == nil {
+			err = utils.RenameFile(taggingPath, req.TrackPath, true)
+		}
+
+		if err != nil {
+			_ = os.Remove(taggingPath)
+		}
+
+		return err
+	case <-timer.C:
+	}
+
+	go func() {
+		<-done
+
+		_ = os.Remove(taggingPath)
+	}()
+
+	var fileSize int64
+	if info, err := os.Stat(req.TrackPath); err == nil {
+		fileSize = info.Size()
+	}
+
+	logger.Warnf(ctx, "Writing tags to '%s' (%s, %d bytes, cover: %t) did not finish in %s",
+		req.TrackPath, req.Quality, fileSize, req.CoverPath != "", time.Since(startTime).Round(time.Second))
+
+	return fmt.Errorf("%w after %s", ErrTagWriteTimeout, timeout)
+}
+
+// copyForTagging copies the track to a new file next to it and returns the path of the copy.
+func copyForTagging(trackPath string) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(trackPath), filepath.Base(trackPath)+".tagging-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a copy for tagging: %w", err)
+	}
+
+	taggingPath := file.Name()
+	_ = file.Close()
+
+	if err = copyFile(trackPath, taggingPath); err != nil {
+		_ = os.Remove(taggingPath)
+
+		return "", fmt.Errorf("failed to copy the track for tagging: %w", err)
+	}
+
+	return taggingPath, nil
+}
+
+// keepUntaggedAudio handles the downloaded audio of a track that could not be tagged
+// according to untagged_audio, so it does not have to be downloaded again.
+func (s *ServiceImpl) keepUntaggedAudio(ctx context.Context, t *downloadTrackTask, tempPath string) {
+	var untaggedPath string
+
+	switch s.cfg.UntaggedAudio {
+	case config.UntaggedAudioDelete:
+		_ = os.Remove(tempPath)
+
+		return
+	case config.UntaggedAudioMarker:
+		extension := filepath.Ext(t.trackPath)
+		untaggedPath = strings.TrimSuffix(t.trackPath, extension) + untaggedMarker + extension
+	default:
+		untaggedPath = t.trackPath + untaggedSuffix
+	}
+
+	if err := utils.RenameFile(tempPath, untaggedPath, true); err != nil {
+		logger.Errorf(ctx, "Failed to keep untagged audio '%s': %v", untaggedPath, err)
+
+		_ = os.Remove(tempPath)
+
+		return
+	}
+
+	logger.Warnf(ctx, "Untagged audio kept as '%s'", untaggedPath)
+
+	item := &UntaggedTrack{
+		TrackID: t.trackIDString,
+		Path:    untaggedPath,
+	}
+
+	if t.track != nil {
+		item.Title = t.track.Title
+	}
+
+	s.recordUntaggedTrack(item)
+}
@@ -0,0 +1,224 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// blockingTagProcessor never finishes writing tags until released.
+type blockingTagProcessor struct {
+	release chan struct{}
+}
+
+// WriteTags blocks until the test releases it, simulating stuck I/O.
+func (b *blockingTagProcessor) WriteTags(_ context.Context, _ *WriteTagsRequest) error {
+	<-b.release
+
+	return nil
+}
+
+// TestWriteTrackMetadata_TagWriteTimeout tests that stuck tagging fails the track and keeps the raw audio.
+func TestWriteTrackMetadata_TagWriteTimeout(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+
+	tempTrackPath := filepath.Join(tmpDir, "track.flac.part")
+	require.NoError(t, os.WriteFile(tempTrackPath, []byte("fake audio data"), 0o644))
+
+	finalTrackPath := filepath.Join(tmpDir, "track.flac")
+
+	tagProcessor := &blockingTagProcessor{release: make(chan struct{})}
+	defer close(tagProcessor.release)
+
+	impl, ok := NewService(&config.Config{
+		OutputPath:            tmpDir,
+		ParsedLogLevel:        logger.Level(),
+		ParsedTagWriteTimeout: 50 * time.Millisecond,
+	}, nil, nil, nil, tagProcessor).(*ServiceImpl)
+	require.True(t, ok)
+
+	task := &downloadTrackTask{
+		trackIDString: "1",
+		track:         &zvuk.Track{ID: 1, Title: "Track"},
+		quality:       TrackQualityFLAC,
+		trackPath:     finalTrackPath,
+		metadata:      &downloadTracksMetadata{category: DownloadCategoryAlbum},
+	}
+
+	impl.writeTrackMetadata(context.Background(), task, map[string]string{}, nil, tempTrackPath)
+
+	require.Len(t, impl.Statistics().Errors, 1)
+	require.ErrorIs(t, impl.Statistics().Errors[0].Error, ErrTagWriteTimeout)
+
+	assert.NoFileExists(t, tempTrackPath)
+	assert.NoFileExists(t, finalTrackPath)
+
+	content, err := os.ReadFile(finalTrackPath + untaggedSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("fake audio data"), content)
+
+	require.Len(t, impl.Statistics().UntaggedTracks, 1)
+	assert.Equal(t, finalTrackPath+untaggedSuffix, impl.Statistics().UntaggedTracks[0].Path)
+}
+
+// overwritingTagProcessor replaces the content of the track with "tagged" once released.
+type overwritingTagProcessor struct {
+	release chan struct{}
+}
+
+// WriteTags waits for the release, if any, and overwrites the track, simulating a writer that ends late.
+func (o *overwritingTagProcessor) WriteTags(_ context.Context, req *WriteTagsRequest) error {
+	if o.release != nil {
+		<-o.release
+	}
+
+	return os.WriteFile(req.TrackPath, []byte("tagged"), 0o644) //nolint:gosec // It's a test file.
+}
+
+// TestWriteTagsWithTimeout_PrivateCopy tests that the tags reach the track only when written in time,
+// and that a writer ending after the timeout touches neither the track nor the untagged audio.
+func TestWriteTagsWithTimeout_PrivateCopy(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	trackPath := filepath.Join(tmpDir, "track.flac.part")
+
+	newService := func(tagProcessor TagProcessor) *ServiceImpl {
+		impl, ok := NewService(&config.Config{
+			OutputPath:            tmpDir,
+			ParsedLogLevel:        logger.Level(),
+			ParsedTagWriteTimeout: 50 * time.Millisecond,
+		}, nil, nil, nil, tagProcessor).(*ServiceImpl)
+		require.True(t, ok)
+
+		return impl
+	}
+
+	require.NoError(t, os.WriteFile(trackPath, []byte("fake audio data"), 0o644))
+
+	err := newService(new(overwritingTagProcessor)).writeTagsWithTimeout(context.Background(),
+		&WriteTagsRequest{TrackPath: trackPath})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(trackPath)
+	require.NoError(t, err)
+	assert.Equal(t, "tagged", string(content))
+
+	require.NoError(t, os.WriteFile(trackPath, []byte("fake audio data"), 0o644))
+
+	tagProcessor := &overwritingTagProcessor{release: make(chan struct{})}
+
+	err = newService(tagProcessor).writeTagsWithTimeout(context.Background(), &WriteTagsRequest{TrackPath: trackPath})
+	require.ErrorIs(t, err, ErrTagWriteTimeout)
+
+	// The caller moves the untagged audio away before the abandoned writer ends.
+	untaggedPath := trackPath + untaggedSuffix
+	require.NoError(t, os.Rename(trackPath, untaggedPath))
+
+	close(tagProcessor.release)
+
+	// The copy is removed once the writer returns.
+	require.Eventually(t, func() bool {
+		entries, readErr := os.ReadDir(tmpDir)
+
+		return readErr == nil && len(entries) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoFileExists(t, trackPath)
+
+	content, err = os.ReadFile(untaggedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "fake audio data", string(content))
+}
+
+// failingTagProcessor always fails to write tags.
+type failingTagProcessor struct{}
+
+// WriteTags returns an error without touching the file.
+func (failingTagProcessor) WriteTags(_ context.Context, _ *WriteTagsRequest) error {
+	return errors.New("corrupted frame")
+}
+
+// TestKeepUntaggedAudio_Modes tests every untagged_audio mode.
+func TestKeepUntaggedAudio_Modes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mode         string
+		expectedName string
+	}{
+		{
+			name:         "suffix",
+			mode:         config.UntaggedAudioSuffix,
+			expectedName: "01 - Track.flac" + untaggedSuffix,
+		},
+		{
+			name:         "marker",
+			mode:         config.UntaggedAudioMarker,
+			expectedName: "01 - Track [untagged].flac",
+		},
+		{
+			name: "delete",
+			mode: config.UntaggedAudioDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := t.TempDir()
+
+			tempTrackPath := filepath.Join(tmpDir, "01 - Track.flac.part")
+			require.NoError(t, os.WriteFile(tempTrackPath, []byte("fake audio data"), 0o644))
+
+			impl, ok := NewService(&config.Config{
+				OutputPath:     tmpDir,
+				ParsedLogLevel: logger.Level(),
+				UntaggedAudio:  tt.mode,
+			}, nil, nil, nil, failingTagProcessor{}).(*ServiceImpl)
+			require.True(t, ok)
+
+			task := &downloadTrackTask{
+				trackIDString: "1",
+				track:         &zvuk.Track{ID: 1, Title: "Track"},
+				quality:       TrackQualityFLAC,
+				trackPath:     filepath.Join(tmpDir, "01 - Track.flac"),
+				metadata:      &downloadTracksMetadata{category: DownloadCategoryAlbum},
+			}
+
+			impl.writeTrackMetadata(context.Background(), task, map[string]string{}, nil, tempTrackPath)
+
+			require.Len(t, impl.Statistics().Errors, 1)
+
+			entries, err := os.ReadDir(tmpDir)
+			require.NoError(t, err)
+
+			if tt.expectedName == "" {
+				assert.Empty(t, entries)
+				assert.Empty(t, impl.Statistics().UntaggedTracks)
+
+				return
+			}
+
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.expectedName, entries[0].Name())
+
+			require.Len(t, impl.Statistics().UntaggedTracks, 1)
+			assert.Equal(t, "Track", impl.Statistics().UntaggedTracks[0].Title)
+		})
+	}
+}
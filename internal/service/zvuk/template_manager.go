@@ -7,6 +7,7 @@ import (
 	"context"
 	"html"
 	"html/template"
+	"strings"
 
 	"github.com/oshokin/zvuk-grabber/internal/config"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
@@ -33,6 +34,14 @@ type TemplateManager interface {
 
 	// GetPodcastEpisodeFilename generates a filename for a podcast episode based on its tags.
 	GetPodcastEpisodeFilename(ctx context.Context, episodeTags map[string]string, totalEpisodes int64) string
+
+	// GetLyricsFilename generates a filename for a lyrics file, without the extension, based on the track tags.
+	// The tags include trackFilename, the name of the track file without its extension.
+	GetLyricsFilename(ctx context.Context, trackTags map[string]string) string
+
+	// GetDescriptionFilename generates a filename for an audiobook or podcast description file,
+	// without the extension, based on the collection tags.
+	GetDescriptionFilename(ctx context.Context, tags map[string]string) string
 }
 
 // TemplateManagerImpl implements the TemplateManager interface.
@@ -53,6 +62,10 @@ type TemplateManagerImpl struct {
 	podcastFolderTemplate *template.Template
 	// podcastEpisodeFilenameTemplate is the template for podcast episode filenames.
 	podcastEpisodeFilenameTemplate *template.Template
+	// lyricsFilenameTemplate is the template for lyrics filenames.
+	lyricsFilenameTemplate *template.Template
+	// descriptionFilenameTemplate is the template for description filenames.
+	descriptionFilenameTemplate *template.Template
 	// defaultTrackFilenameTemplate is the fallback template for track filenames.
 	defaultTrackFilenameTemplate *template.Template
 	// defaultAlbumFolderTemplate is the fallback template for album folder names.
@@ -67,6 +80,10 @@ type TemplateManagerImpl struct {
 	defaultPodcastFolderTemplate *template.Template
 	// defaultPodcastEpisodeFilenameTemplate is the fallback template for podcast episode filenames.
 	defaultPodcastEpisodeFilenameTemplate *template.Template
+	// defaultLyricsFilenameTemplate is the fallback template for lyrics filenames.
+	defaultLyricsFilenameTemplate *template.Template
+	// defaultDescriptionFilenameTemplate is the fallback template for description filenames.
+	defaultDescriptionFilenameTemplate *template.Template
 }
 
 // NewTemplateManager creates and returns a new instance of TemplateManagerImpl.
@@ -87,6 +104,10 @@ func NewTemplateManager(ctx context.Context, cfg *config.Config) TemplateManager
 		template.New("defaultPodcastFolderTemplate").Parse(config.DefaultPodcastFolderTemplate))
 	defaultPodcastEpisodeFilenameTemplate := template.Must(
 		template.New("defaultPodcastEpisodeFilenameTemplate").Parse(config.DefaultPodcastEpisodeFilenameTemplate))
+	defaultLyricsFilenameTemplate := template.Must(
+		template.New("defaultLyricsFilenameTemplate").Parse(config.DefaultLyricsFilenameTemplate))
+	defaultDescriptionFilenameTemplate := template.Must(
+		template.New("defaultDescriptionFilenameTemplate").Parse(config.DefaultDescriptionFilenameTemplate))
 
 	// Parse custom templates from the configuration.
 	trackFilenameTemplate, err := template.New("trackFilenameTemplate").Parse(cfg.TrackFilenameTemplate)
@@ -126,6 +147,24 @@ func NewTemplateManager(ctx context.Context, cfg *config.Config) TemplateManager
 		logger.Errorf(ctx, "Failed to parse podcast episode filename template, using default: %v", err)
 	}
 
+	// Sidecar templates are optional: an empty setting keeps the default naming.
+	var lyricsFilenameTemplate, descriptionFilenameTemplate *template.Template
+
+	if cfg.LyricsFilenameTemplate != "" {
+		lyricsFilenameTemplate, err = template.New("lyricsFilenameTemplate").Parse(cfg.LyricsFilenameTemplate)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to parse lyrics filename template, using default: %v", err)
+		}
+	}
+
+	if cfg.DescriptionFilenameTemplate != "" {
+		descriptionFilenameTemplate, err = template.New("descriptionFilenameTemplate").
+			Parse(cfg.DescriptionFilenameTemplate)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to parse description filename template, using default: %v", err)
+		}
+	}
+
 	return &TemplateManagerImpl{
 		cfg:                                     cfg,
 		trackFilenameTemplate:                   trackFilenameTemplate,
@@ -142,6 +181,10 @@ func NewTemplateManager(ctx context.Context, cfg *config.Config) TemplateManager
 		defaultAudiobookChapterFilenameTemplate: defaultAudiobookChapterFilenameTemplate,
 		defaultPodcastFolderTemplate:            defaultPodcastFolderTemplate,
 		defaultPodcastEpisodeFilenameTemplate:   defaultPodcastEpisodeFilenameTemplate,
+		lyricsFilenameTemplate:                  lyricsFilenameTemplate,
+		descriptionFilenameTemplate:             descriptionFilenameTemplate,
+		defaultLyricsFilenameTemplate:           defaultLyricsFilenameTemplate,
+		defaultDescriptionFilenameTemplate:      defaultDescriptionFilenameTemplate,
 	}
 }
 
@@ -153,13 +196,11 @@ func (s *TemplateManagerImpl) GetTrackFilename(
 	trackTags map[string]string,
 	tracksCount int64,
 ) string {
-	// Determine if the track is a single and should not have its own folder.
-	isSingleWithoutFolder := !s.cfg.CreateFolderForSingles && tracksCount == 1
-
-	// Select the appropriate template based on whether the track is part of a playlist or a single without a folder.
-	textBuilder, defaultTextBuilder := s.trackFilenameTemplate, s.defaultTrackFilenameTemplate
-	if isPlaylist || isSingleWithoutFolder {
-		textBuilder, defaultTextBuilder = s.playlistFilenameTemplate, s.defaultPlaylistFilenameTemplate
+	// Playlist tracks follow the playlist template whatever the number of tracks, while an album single
+	// saved without its own folder borrows it, since no album folder names it.
+	textBuilder, defaultTextBuilder := s.playlistFilenameTemplate, s.defaultPlaylistFilenameTemplate
+	if !isPlaylist && (s.cfg.CreateFolderForSingles || tracksCount != 1) {
+		textBuilder, defaultTextBuilder = s.trackFilenameTemplate, s.defaultTrackFilenameTemplate
 	}
 
 	// Execute the selected template with the track tags.
@@ -336,3 +377,43 @@ func (s *TemplateManagerImpl) GetPodcastEpisodeFilename(
 	// Unescape HTML entities in the generated filename.
 	return html.UnescapeString(buffer.String())
 }
+
+// GetLyricsFilename generates a filename for a lyrics file, without the extension, based on the track tags.
+func (s *TemplateManagerImpl) GetLyricsFilename(ctx context.Context, trackTags map[string]string) string {
+	return s.executeSidecarTemplate(ctx, "lyrics",
+		s.lyricsFilenameTemplate, s.defaultLyricsFilenameTemplate, trackTags)
+}
+
+// GetDescriptionFilename generates a filename for a description file, without the extension,
+// based on the collection tags.
+func (s *TemplateManagerImpl) GetDescriptionFilename(ctx context.Context, tags map[string]string) string {
+	return s.executeSidecarTemplate(ctx, "description",
+		s.descriptionFilenameTemplate, s.defaultDescriptionFilenameTemplate, tags)
+}
+
+// executeSidecarTemplate executes a sidecar filename template, falling back to the default one
+// if the template is not set, fails, or produces an empty name.
+func (s *TemplateManagerImpl) executeSidecarTemplate(
+	ctx context.Context,
+	kind string,
+	textBuilder *template.Template,
+	defaultTextBuilder *template.Template,
+	tags map[string]string,
+) string {
+	var buffer bytes.Buffer
+
+	if textBuilder != nil {
+		if err := textBuilder.Execute(&buffer, tags); err != nil {
+			logger.Errorf(ctx, "Failed to execute %s filename template, using default: %v", kind, err)
+			buffer.Reset()
+		}
+	}
+
+	if strings.TrimSpace(buffer.String()) == "" {
+		buffer.Reset()
+		_ = defaultTextBuilder.Execute(&buffer, tags) //nolint:errcheck // Default template is always valid.
+	}
+
+	// Unescape HTML entities and drop characters not allowed in filenames.
+	return utils.SanitizeFilename(html.UnescapeString(buffer.String()))
+}
@@ -191,11 +191,12 @@ func TestTemplateManagerImpl_GetTrackFilename(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name        string
-		isPlaylist  bool
-		trackTags   map[string]string
-		tracksCount int64
-		expected    string
+		name                   string
+		isPlaylist             bool
+		createFolderForSingles bool
+		trackTags              map[string]string
+		tracksCount            int64
+		expected               string
 	}{
 		{
 			name:       "regular track",
@@ -229,6 +230,30 @@ func TestTemplateManagerImpl_GetTrackFilename(t *testing.T) {
 			tracksCount: 1,
 			expected:    "01 - Test Artist - Test Track", // Uses playlist template for singles
 		},
+		{
+			name:                   "single track with its own folder",
+			isPlaylist:             false,
+			createFolderForSingles: true,
+			trackTags: map[string]string{
+				"trackNumberPad": "01",
+				"trackArtist":    "Test Artist",
+				"trackTitle":     "Test Track",
+			},
+			tracksCount: 1,
+			expected:    "01 - Test Track",
+		},
+		{
+			name:                   "single-track playlist",
+			isPlaylist:             true,
+			createFolderForSingles: true,
+			trackTags: map[string]string{
+				"trackNumberPad": "01",
+				"trackArtist":    "Test Artist",
+				"trackTitle":     "Test Track",
+			},
+			tracksCount: 1,
+			expected:    "01 - Test Artist - Test Track",
+		},
 		{
 			name:       "track with missing tags",
 			isPlaylist: false,
@@ -255,7 +280,7 @@ func TestTemplateManagerImpl_GetTrackFilename(t *testing.T) {
 			cfg := &config.Config{
 				TrackFilenameTemplate:    "{{.trackNumberPad}} - {{.trackTitle}}",
 				PlaylistFilenameTemplate: "{{.trackNumberPad}} - {{.trackArtist}} - {{.trackTitle}}",
-				CreateFolderForSingles:   false,
+				CreateFolderForSingles:   tt.createFolderForSingles,
 			}
 			manager := NewTemplateManager(ctx, cfg)
 
@@ -312,3 +337,48 @@ func TestTemplateManagerImpl_UnicodeCharacters(t *testing.T) {
 	result = manager.GetAlbumFolderName(ctx, albumTags)
 	assert.Contains(t, result, "Альбом 🎶")
 }
+
+// TestTemplateManager_SidecarFilenames tests lyrics and description filename templates.
+func TestTemplateManager_SidecarFilenames(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	trackTags := map[string]string{
+		TagTrackFilename: "01 - Test Track",
+		TagTrackTitle:    "Test Track",
+	}
+	audiobookTags := map[string]string{
+		TagAudiobookTitle: "Test: Book",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewTemplateManager(ctx, &config.Config{})
+
+		assert.Equal(t, "01 - Test Track", manager.GetLyricsFilename(ctx, trackTags))
+		assert.Equal(t, "description", manager.GetDescriptionFilename(ctx, audiobookTags))
+	})
+
+	t.Run("custom templates", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewTemplateManager(ctx, &config.Config{
+			LyricsFilenameTemplate:      "{{.trackFilename}}.ru",
+			DescriptionFilenameTemplate: "{{.audiobookTitle}} - info",
+		})
+
+		assert.Equal(t, "01 - Test Track.ru", manager.GetLyricsFilename(ctx, trackTags))
+		assert.Equal(t, "Test_ Book - info", manager.GetDescriptionFilename(ctx, audiobookTags))
+	})
+
+	t.Run("empty result falls back to default", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewTemplateManager(ctx, &config.Config{
+			LyricsFilenameTemplate: "{{.missing}}",
+		})
+
+		assert.Equal(t, "01 - Test Track", manager.GetLyricsFilename(ctx, trackTags))
+	})
+}
@@ -0,0 +1,309 @@
+package zvuk
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TemplateVariable describes a single placeholder available in a filename or folder template.
+type TemplateVariable struct {
+	// Name is the placeholder key (used as {{.Name}} in templates).
+	Name string
+	// Example is the value the placeholder takes for the sample metadata.
+	Example string
+}
+
+// TemplateVariableGroup lists the placeholders available in one template setting.
+type TemplateVariableGroup struct {
+	// Category is the content type the template applies to.
+	Category DownloadCategory
+	// ConfigKey is the configuration key of the template.
+	ConfigKey string
+	// Variables is the list of placeholders sorted by name.
+	Variables []*TemplateVariable
+}
+
+// Sample metadata used to produce example values for the template variable catalog.
+const (
+	sampleArtistName      = "Example Artist"
+	sampleFeaturingArtist = "Guest Artist"
+	sampleGenre           = "Electronic"
+	sampleTrackTitle      = "Example Track"
+	sampleTrackNumber     = 3
+	sampleTrackDuration   = 215
+	samplePublicationDate = "2024-03-15T00:00:00Z"
+)
+
+// GetTemplateVariableGroups returns every template variable per template setting.
+// The values are produced by running the same tag fillers used during downloads
+// on sample metadata, so the catalog never drifts from the actual implementation.
+func GetTemplateVariableGroups() []*TemplateVariableGroup {
+	var (
+		albumHandler     = NewAlbumCollectionHandler(nil)
+		playlistHandler  = NewPlaylistCollectionHandler(nil)
+		audiobookHandler = NewAudiobookCollectionHandler(nil)
+		podcastHandler   = NewPodcastCollectionHandler(nil)
+	)
+
+	release := &zvuk.Release{
+		ID:            12345678,
+		Type:          "album",
+		Title:         "Example Album",
+		TrackIDs:      sampleTrackIDs(10),
+		ArtistNames:   []string{sampleArtistName},
+		Date:          20240315,
+		Barcode:       "0602435901234",
+		CatalogNumber: "EX-0042",
+	}
+
+	track := &zvuk.Track{
+		ID:           87654321,
+		Title:        sampleTrackTitle,
+		ReleaseID:    release.ID,
+		ReleaseTitle: release.Title,
+		Duration:     sampleTrackDuration,
+		Genres:       []string{sampleGenre},
+		ArtistNames:  []string{sampleArtistName, sampleFeaturingArtist},
+	}
+
+	playlist := &zvuk.Playlist{
+		ID:       11223344,
+		Title:    "Example Playlist",
+		TrackIDs: sampleTrackIDs(20),
+	}
+
+	audiobook := &zvuk.Audiobook{
+		ID:              55667788,
+		Title:           "Example Audiobook",
+		ArtistNames:     []string{"Example Author"},
+		TrackIDs:        sampleTrackIDs(12),
+		PublicationDate: samplePublicationDate,
+		Copyright:       "Example Publisher",
+		Description:     "Audiobook description.",
+		AgeLimit:        16,
+		FullDuration:    36000,
+		PublisherName:   "Example Publisher LLC",
+		PublisherBrand:  "Example Publisher",
+		PerformerNames:  []string{"Example Narrator"},
+		Genres:          []string{"Fiction"},
+	}
+
+	chapter := &zvuk.Track{
+		ID:          99887766,
+		Title:       "Chapter 3",
+		Duration:    1800,
+		Genres:      []string{"Fiction"},
+		ArtistNames: []string{"Example Author"},
+	}
+
+	podcast := &zvuk.Podcast{
+		ID:          44332211,
+		Title:       "Example Podcast",
+		ArtistNames: []string{"Example Host"},
+		TrackIDs:    sampleTrackIDs(25),
+		Description: "Podcast description.",
+		Category:    "Technology",
+		Explicit:    true,
+	}
+
+	episode := &zvuk.Track{
+		ID:          66778899,
+		Title:       "Episode 3",
+		Credits:     samplePublicationDate,
+		Duration:    2700,
+		Genres:      []string{"Technology"},
+		ArtistNames: []string{"Example Host"},
+	}
+
+	albumTags := albumHandler.FillTags(release)
+	playlistTags := playlistHandler.FillTags(playlist)
+	audiobookTags := audiobookHandler.FillTags(audiobook)
+	podcastTags := podcastHandler.FillTags(podcast)
+
+	albumTrackTags := buildTrackTags(&trackTagContext{
+		trackNumber: sampleTrackNumber,
+		track:       track,
+		audioCollection: &audioCollection{
+			category:    DownloadCategoryAlbum,
+			title:       release.Title,
+			tags:        albumTags,
+			tracksCount: int64(len(release.TrackIDs)),
+		},
+		albumTags: albumTags,
+		category:  DownloadCategoryAlbum,
+	})
+
+	playlistTrackTags := buildTrackTags(&trackTagContext{
+		trackNumber: sampleTrackNumber,
+		track:       track,
+		audioCollection: &audioCollection{
+			category:          DownloadCategoryPlaylist,
+			title:             playlist.Title,
+			tags:              playlistTags,
+			tracksCount:       int64(len(playlist.TrackIDs)),
+			playlistPositions: newPlaylistPositions(playlist.TrackIDs),
+		},
+		albumTags: albumTags,
+		category:  DownloadCategoryPlaylist,
+	})
+
+	chapterTags := buildTrackTags(&trackTagContext{
+		trackNumber: sampleTrackNumber,
+		track:       chapter,
+		audioCollection: &audioCollection{
+			category:    DownloadCategoryAudiobook,
+			title:       audiobook.Title,
+			tags:        audiobookTags,
+			tracksCount: int64(len(audiobook.TrackIDs)),
+		},
+		category: DownloadCategoryAudiobook,
+	})
+
+	episodeTags := buildTrackTags(&trackTagContext{
+		trackNumber: sampleTrackNumber,
+		track:       episode,
+		audioCollection: &audioCollection{
+			category:    DownloadCategoryPodcast,
+			title:       podcast.Title,
+			tags:        podcastTags,
+			tracksCount: int64(len(podcast.TrackIDs)),
+		},
+		category: DownloadCategoryPodcast,
+	})
+
+	lyricsTags := maps.Clone(albumTrackTags)
+	lyricsTags[TagTrackFilename] = fmt.Sprintf("%02d - %s", sampleTrackNumber, sampleTrackTitle)
+
+	return []*TemplateVariableGroup{
+		newTemplateVariableGroup(DownloadCategoryTrack, "track_filename_template", albumTrackTags),
+		newTemplateVariableGroup(DownloadCategoryAlbum, "album_folder_template", albumTags),
+		newTemplateVariableGroup(DownloadCategoryPlaylist, "playlist_filename_template", playlistTrackTags),
+		newTemplateVariableGroup(DownloadCategoryAudiobook, "audiobook_folder_template", audiobookTags),
+		newTemplateVariableGroup(DownloadCategoryAudiobook, "audiobook_chapter_filename_template", chapterTags),
+		newTemplateVariableGroup(DownloadCategoryPodcast, "podcast_folder_template", podcastTags),
+		newTemplateVariableGroup(DownloadCategoryPodcast, "podcast_episode_filename_template", episodeTags),
+		newTemplateVariableGroup(DownloadCategoryTrack, "lyrics_filename_template", lyricsTags),
+		newTemplateVariableGroup(DownloadCategoryAudiobook, "description_filename_template", audiobookTags),
+		newTemplateVariableGroup(DownloadCategoryPodcast, "description_filename_template", podcastTags),
+	}
+}
+
+// sampleTrackIDs returns a list of sequential track IDs of the given length.
+func sampleTrackIDs(count int64) []int64 {
+	result := make([]int64, 0, count)
+	for i := range count {
+		result = append(result, i+1)
+	}
+
+	return result
+}
+
+// newTemplateVariableGroup converts a tag map into a group of variables sorted by name.
+func newTemplateVariableGroup(
+	category DownloadCategory,
+	configKey string,
+	tags map[string]string,
+) *TemplateVariableGroup {
+	variables := make([]*TemplateVariable, 0, len(tags))
+	for name, example := range tags {
+		variables = append(variables, &TemplateVariable{
+			Name:    name,
+			Example: example,
+		})
+	}
+
+	slices.SortFunc(variables, func(a, b *TemplateVariable) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return &TemplateVariableGroup{
+		Category:  category,
+		ConfigKey: configKey,
+		Variables: variables,
+	}
+}
+
+// ValidateTemplates checks every configured template with missingkey=error semantics:
+// a template that fails to parse or references a variable absent from the catalog is rejected.
+// The problems of all templates are reported together.
+func ValidateTemplates(cfg *config.Config) error {
+	configuredTemplates := map[string]string{
+		"track_filename_template":             cfg.TrackFilenameTemplate,
+		"album_folder_template":               cfg.AlbumFolderTemplate,
+		"playlist_filename_template":          cfg.PlaylistFilenameTemplate,
+		"audiobook_folder_template":           cfg.AudiobookFolderTemplate,
+		"audiobook_chapter_filename_template": cfg.AudiobookChapterFilenameTemplate,
+		"podcast_folder_template":             cfg.PodcastFolderTemplate,
+		"podcast_episode_filename_template":   cfg.PodcastEpisodeFilenameTemplate,
+		"lyrics_filename_template":            cfg.LyricsFilenameTemplate,
+		"description_filename_template":       cfg.DescriptionFilenameTemplate,
+	}
+
+	// Portable templates accept the same variables as the main ones they override.
+	portableTemplates := map[string]string{
+		"track_filename_template":    cfg.PortableTrackFilenameTemplate,
+		"album_folder_template":      cfg.PortableAlbumFolderTemplate,
+		"playlist_filename_template": cfg.PortablePlaylistFilenameTemplate,
+	}
+
+	// A setting shared by several categories (description_filename_template) accepts the variables of all of them.
+	var (
+		configKeys      []string
+		dataByConfigKey = make(map[string]map[string]string)
+	)
+
+	for _, group := range GetTemplateVariableGroups() {
+		data, ok := dataByConfigKey[group.ConfigKey]
+		if !ok {
+			data = make(map[string]string, len(group.Variables))
+			dataByConfigKey[group.ConfigKey] = data
+			configKeys = append(configKeys, group.ConfigKey)
+		}
+
+		for _, variable := range group.Variables {
+			data[variable.Name] = variable.Example
+		}
+	}
+
+	var errs []error
+
+	for _, configKey := range configKeys {
+		data := dataByConfigKey[configKey]
+
+		if err := validateTemplate(configKey, configuredTemplates[configKey], data); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := validateTemplate("portable_"+configKey, portableTemplates[configKey], data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTemplate parses and executes a single template against the sample data.
+func validateTemplate(configKey, text string, data map[string]string) error {
+	if text == "" {
+		return nil
+	}
+
+	textBuilder, err := template.New(configKey).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("%w '%s': %w", ErrInvalidTemplate, configKey, err)
+	}
+
+	if err = textBuilder.Execute(io.Discard, data); err != nil {
+		return fmt.Errorf("%w '%s': %w", ErrInvalidTemplate, configKey, err)
+	}
+
+	return nil
+}
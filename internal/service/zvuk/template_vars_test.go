@@ -0,0 +1,161 @@
+package zvuk
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestGetTemplateVariableGroups_CoversDefaultTemplates verifies that every default template
+// renders with the catalog variables alone, so the catalog lists everything they use.
+func TestGetTemplateVariableGroups_CoversDefaultTemplates(t *testing.T) {
+	t.Parallel()
+
+	defaultTemplates := map[string]string{
+		"track_filename_template":             config.DefaultTrackFilenameTemplate,
+		"album_folder_template":               config.DefaultAlbumFolderTemplate,
+		"playlist_filename_template":          config.DefaultPlaylistFilenameTemplate,
+		"audiobook_folder_template":           config.DefaultAudiobookFolderTemplate,
+		"audiobook_chapter_filename_template": config.DefaultAudiobookChapterFilenameTemplate,
+		"podcast_folder_template":             config.DefaultPodcastFolderTemplate,
+		"podcast_episode_filename_template":   config.DefaultPodcastEpisodeFilenameTemplate,
+		"lyrics_filename_template":            config.DefaultLyricsFilenameTemplate,
+		"description_filename_template":       config.DefaultDescriptionFilenameTemplate,
+	}
+
+	groups := GetTemplateVariableGroups()
+
+	configKeys := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		configKeys[group.ConfigKey] = struct{}{}
+	}
+
+	require.Len(t, configKeys, len(defaultTemplates))
+
+	for _, group := range groups {
+		t.Run(group.ConfigKey, func(t *testing.T) {
+			t.Parallel()
+
+			defaultTemplate, ok := defaultTemplates[group.ConfigKey]
+			require.True(t, ok, "unexpected config key")
+			require.NotEmpty(t, group.Variables)
+
+			names := make([]string, 0, len(group.Variables))
+			data := make(map[string]string, len(group.Variables))
+
+			for _, variable := range group.Variables {
+				names = append(names, variable.Name)
+				data[variable.Name] = variable.Example
+			}
+
+			assert.True(t, slices.IsSorted(names), "variables must be sorted by name")
+			assert.Contains(t, names, TagType)
+
+			tmpl, err := template.New(group.ConfigKey).Option("missingkey=error").Parse(defaultTemplate)
+			require.NoError(t, err)
+
+			var result strings.Builder
+			require.NoError(t, tmpl.Execute(&result, data))
+			assert.NotEmpty(t, result.String())
+		})
+	}
+}
+
+// TestValidateTemplates tests strict validation of configured templates.
+func TestValidateTemplates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		errContains string
+	}{
+		{
+			name: "default templates",
+			cfg: &config.Config{
+				TrackFilenameTemplate:            config.DefaultTrackFilenameTemplate,
+				AlbumFolderTemplate:              config.DefaultAlbumFolderTemplate,
+				PlaylistFilenameTemplate:         config.DefaultPlaylistFilenameTemplate,
+				AudiobookFolderTemplate:          config.DefaultAudiobookFolderTemplate,
+				AudiobookChapterFilenameTemplate: config.DefaultAudiobookChapterFilenameTemplate,
+				PodcastFolderTemplate:            config.DefaultPodcastFolderTemplate,
+				PodcastEpisodeFilenameTemplate:   config.DefaultPodcastEpisodeFilenameTemplate,
+			},
+		},
+		{
+			name: "empty templates are skipped",
+			cfg:  &config.Config{},
+		},
+		{
+			name: "typo in album folder template",
+			cfg: &config.Config{
+				AlbumFolderTemplate: "{{.releaseYear}} - {{.albumArtst}} - {{.albumTitle}}",
+			},
+			errContains: `album_folder_template`,
+		},
+		{
+			name: "playlist variable in album folder template",
+			cfg: &config.Config{
+				AlbumFolderTemplate: "{{.playlistTitle}}",
+			},
+			errContains: `map has no entry for key "playlistTitle"`,
+		},
+		{
+			name: "release identifiers in album and track templates",
+			cfg: &config.Config{
+				AlbumFolderTemplate:   "{{.catalogNumber}} - {{.albumTitle}}",
+				TrackFilenameTemplate: "{{.barcode}} - {{.trackNumberPad}}",
+			},
+		},
+		{
+			name: "sidecar templates",
+			cfg: &config.Config{
+				LyricsFilenameTemplate:      "{{.trackFilename}}.{{.trackID}}",
+				DescriptionFilenameTemplate: "{{.podcastTitle}}{{.audiobookTitle}} - info",
+			},
+		},
+		{
+			name: "typo in lyrics filename template",
+			cfg: &config.Config{
+				LyricsFilenameTemplate: "{{.trackFilname}}",
+			},
+			errContains: `lyrics_filename_template`,
+		},
+		{
+			name: "problems of every template are reported",
+			cfg: &config.Config{
+				AlbumFolderTemplate:            "{{.albumArtst}}",
+				PodcastEpisodeFilenameTemplate: "{{.trackTitle",
+			},
+			errContains: `podcast_episode_filename_template`,
+		},
+		{
+			name: "unparsable template",
+			cfg: &config.Config{
+				PodcastEpisodeFilenameTemplate: "{{.trackTitle",
+			},
+			errContains: `podcast_episode_filename_template`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateTemplates(tt.cfg)
+			if tt.errContains == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidTemplate)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package zvuk
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -11,12 +12,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"go.uber.org/zap"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 )
@@ -50,6 +53,32 @@ type downloadTracksMetadata struct {
 	chapterStreamsMetadata map[string]*zvuk.StreamQualities
 	// labelsMetadata contains music label metadata mapped by label ID.
 	labelsMetadata map[string]*zvuk.Label
+	// failureTracker aborts the remaining tracks after too many consecutive failures.
+	failureTracker *consecutiveFailureTracker
+	// duplicateNumbers maps the index of a repeated playlist track to its occurrence number (2, 3, etc.).
+	// Repeated tracks are processed after all the others, so their first occurrence is already saved.
+	duplicateNumbers map[int]int64
+	// savedTracks remembers where the tracks of the collection were saved, keyed by track ID.
+	savedTracks map[string]*savedTrack
+	// keepSavedTracks indicates whether every saved track is remembered, not only repeated playlist tracks.
+	keepSavedTracks bool
+	// playlistSync records the fetched tracks of a playlist being synced (nil when it is not synced).
+	playlistSync *playlistSync
+	// libraryPlaylist is the playlist whose tracks are saved into their album folders
+	// (playlist_layout library), nil otherwise.
+	libraryPlaylist *audioCollection
+	// savedTracksMutex protects concurrent access to savedTracks.
+	savedTracksMutex sync.Mutex
+	// startedTracks is the number of tracks of the collection that have started downloading.
+	startedTracks atomic.Int64
+	// isCutShort is set once the collection is recorded as unfinished because of the run time limit.
+	isCutShort atomic.Bool
+	// isChanged is set once a track of the collection is saved during the run.
+	isChanged atomic.Bool
+	// failedTracks is the number of tracks of the collection that failed to download.
+	failedTracks atomic.Int64
+	// deselectedTracks holds the indexes of the tracks left out by --tracks or in the track picker.
+	deselectedTracks map[int]struct{}
 }
 
 // downloadTrackTask is a task for downloading a single track.
@@ -72,11 +101,14 @@ type downloadTrackTask struct {
 	// For standalone track downloads it is derived from the track's album.
 	audioCollection *audioCollection
 	metadata        *downloadTracksMetadata
+	// isFailed is set when the track download failed (as opposed to being skipped).
+	isFailed bool
+	// duplicateNumber is the occurrence number of a repeated playlist track (0 for the first occurrence).
+	duplicateNumber int64
+	// qualityCap is the highest quality to resolve, lowered after stream errors (0 uses the configured quality).
+	qualityCap TrackQuality
 }
 
-// defaultLyricsExtension is the default file extension for lyrics files.
-const defaultLyricsExtension = extensionLRC
-
 // fetchAlbumsDataFromTracks fetches album and label data for a list of tracks.
 func (s *ServiceImpl) fetchAlbumsDataFromTracks(
 	ctx context.Context,
@@ -131,52 +163,129 @@ func (s *ServiceImpl) fetchAlbumsDataFromTracks(
 
 // downloadTracks downloads a list of tracks, either sequentially or concurrently.
 func (s *ServiceImpl) downloadTracks(ctx context.Context, metadata *downloadTracksMetadata) {
-	maxConcurrent := s.cfg.MaxConcurrentDownloads
+	if metadata.failureTracker == nil {
+		metadata.failureTracker = newConsecutiveFailureTracker(s.cfg.MaxConsecutiveFailures)
+	}
+
+	if metadata.category == DownloadCategoryPlaylist && metadata.duplicateNumbers == nil {
+		metadata.duplicateNumbers = findPlaylistDuplicates(metadata.trackIDs)
+	}
+
+	// Only the tracks in --tracks and, in interactive mode, the tracks chosen by the user are downloaded.
+	if !s.selectTrackRanges(ctx, metadata) || !s.pickTracks(ctx, metadata) {
+		return
+	}
+
+	// Tracks that were never started (CTRL+C, early abort) must not stay pending.
+	tracksCount := int64(len(metadata.trackIDs))
+	s.status.addPendingTracks(tracksCount)
+
+	defer func() {
+		s.status.addPendingTracks(metadata.startedTracks.Load() - tracksCount)
+	}()
+
+	order := s.downloadOrder(metadata)
 
-	// Sequential download (default behavior when maxConcurrent == 1).
-	if maxConcurrent == 1 {
-		s.downloadTracksSequentially(ctx, metadata)
+	// Dry runs list the tracks once they are processed; the start is taken now, before any of them.
+	defer s.printDryRunPlan(ctx, metadata, s.getDryRunPlanStart())
+
+	// Sequential download (default behavior when max_concurrent_downloads is 1).
+	if s.concurrentDownloads() == 1 {
+		s.downloadTracksSequentially(ctx, metadata, order)
 
 		return
 	}
 
 	// Concurrent downloads with worker pool pattern.
-	s.downloadTracksConcurrently(ctx, metadata, maxConcurrent)
+	s.downloadTracksConcurrently(ctx, metadata, order)
 }
 
 // downloadTracksSequentially downloads tracks one by one (original behavior).
-func (s *ServiceImpl) downloadTracksSequentially(ctx context.Context, metadata *downloadTracksMetadata) {
-	for i, trackID := range metadata.trackIDs {
-		// Stop between tracks on CTRL+C, but still finalize shared assets.
-		if ctx.Err() != nil {
+func (s *ServiceImpl) downloadTracksSequentially(ctx context.Context, metadata *downloadTracksMetadata, order []int) {
+	for position, i := range order {
+		// Stop between tracks on CTRL+C or early abort, but still finalize shared assets.
+		if ctx.Err() != nil || metadata.failureTracker.isAborted() {
 			break
 		}
 
-		s.downloadSingleTrack(ctx, i, trackID, metadata)
+		// The rest of the tracks are downloaded concurrently once reload_config raises max_concurrent_downloads.
+		if s.concurrentDownloads() > 1 {
+			s.downloadTracksConcurrently(ctx, metadata, order[position:])
+
+			return
+		}
+
+		if metadata.isDuplicate(i) || metadata.isDeselected(i) {
+			continue
+		}
+
+		if s.isRunLimitReached(ctx) {
+			s.recordTracksNotStarted(metadata, metadata.trackIDsAt(order[position:]))
+
+			break
+		}
+
+		s.downloadSingleTrack(ctx, i, metadata.trackIDs[i], metadata)
 	}
 
+	s.downloadPlaylistDuplicates(ctx, metadata)
 	s.finalizeCollectionAssets(ctx, metadata)
 }
 
 // downloadTracksConcurrently downloads tracks using a worker pool for concurrent execution.
+// The number of tracks downloaded at once follows max_concurrent_downloads, even when it changes during the run.
 func (s *ServiceImpl) downloadTracksConcurrently(
 	ctx context.Context,
 	metadata *downloadTracksMetadata,
-	maxConcurrent int64,
+	order []int,
 ) {
-	// Create a semaphore channel to limit concurrent downloads.
-	semaphore := make(chan struct{}, maxConcurrent)
+	// Tracks differing only by letter case get their suffixes in track order, whichever is downloaded first.
+	s.claimTrackPaths(ctx, metadata)
+
+	// Limit concurrent downloads.
+	slots := newDownloadSlots(s.concurrentDownloads)
 
 	var waitGroup sync.WaitGroup
 
-	// Process each track in a separate goroutine.
+	// Process each track in a separate goroutine. The slots are taken here rather than in the goroutines,
+	// so the tracks start in the download order.
 queueTracks:
-	for index, trackID := range metadata.trackIDs {
-		// Check if context was canceled (CTRL+C pressed) - stop queueing new downloads.
-		select {
-		case <-ctx.Done():
+	for position, index := range order {
+		if metadata.isDuplicate(index) || metadata.isDeselected(index) {
+			continue
+		}
+
+		// Acquire a download slot or stop immediately on cancellation (CTRL+C pressed).
+		if !slots.acquire(ctx) {
+			break queueTracks
+		}
+
+		// Fewer tracks are downloaded at once during polite_hours.
+		releasePoliteSlot, ok := s.acquirePoliteSlot(ctx)
+		if !ok {
+			slots.release()
+
+			break queueTracks
+		}
+
+		release := func() {
+			releasePoliteSlot()
+			slots.release()
+		}
+
+		// Avoid starting new work when cancellation or early abort arrives while waiting for a slot.
+		if ctx.Err() != nil || metadata.failureTracker.isAborted() {
+			release()
+
+			break queueTracks
+		}
+
+		// Tracks waiting for a slot are not started once the run time or API request limit is reached.
+		if s.isRunLimitReached(ctx) {
+			release()
+			s.recordTracksNotStarted(metadata, metadata.trackIDsAt(order[position:]))
+
 			break queueTracks
-		default:
 		}
 
 		waitGroup.Add(1)
@@ -184,40 +293,63 @@ queueTracks:
 		go func(trackIndex int, currentTrackID int64) {
 			defer waitGroup.Done()
 
-			// Acquire semaphore slot or stop immediately on cancellation.
-			select {
-			case semaphore <- struct{}{}:
-			case <-ctx.Done():
-				return
-			}
-
-			defer func() {
-				// Release semaphore slot when done.
-				<-semaphore
-			}()
-
-			// Avoid starting new work when cancellation arrives while waiting for a slot.
-			if ctx.Err() != nil {
-				return
-			}
+			// Release the slots when done.
+			defer release()
 
 			s.downloadSingleTrack(ctx, trackIndex, currentTrackID, metadata)
-		}(index, trackID)
+		}(index, metadata.trackIDs[index])
 	}
 
 	// Wait for all in-flight downloads to complete.
 	waitGroup.Wait()
 
+	s.downloadPlaylistDuplicates(ctx, metadata)
 	s.finalizeCollectionAssets(ctx, metadata)
 }
 
 func (s *ServiceImpl) finalizeCollectionAssets(ctx context.Context, metadata *downloadTracksMetadata) {
-	if metadata == nil || metadata.audioCollection == nil {
+	if metadata == nil {
+		return
+	}
+
+	defer s.startPhaseTimer(DownloadPhaseFinalize)()
+
+	// Standalone tracks are saved into the collections of their albums, so their covers are finalized instead.
+	if metadata.audioCollection == nil {
+		for _, albumCollection := range s.getTrackAlbumCollections(metadata) {
+			s.finalizeCover(ctx, albumCollection.tracksCount, albumCollection)
+		}
+
 		return
 	}
 
 	s.finalizeCover(ctx, metadata.audioCollection.tracksCount, metadata.audioCollection)
+	s.generatePlaylistCover(ctx, metadata)
 	s.finalizeDescription(ctx, metadata.audioCollection, metadata.audioCollection.tracksCount)
+	s.writeCollectionPlaylist(ctx, metadata)
+	s.recordDiscographyRelease(metadata)
+	s.writeReadyMarker(ctx, metadata)
+	s.uploadCollection(ctx, metadata)
+}
+
+// getTrackAlbumCollections returns the registered album collections of the tracks being downloaded.
+func (s *ServiceImpl) getTrackAlbumCollections(metadata *downloadTracksMetadata) []*audioCollection {
+	s.audioCollectionsMutex.Lock()
+	defer s.audioCollectionsMutex.Unlock()
+
+	result := make([]*audioCollection, 0, len(metadata.albumsMetadata))
+
+	for albumID := range metadata.albumsMetadata {
+		collection, ok := s.audioCollections[ShortDownloadItem{
+			Category: DownloadCategoryAlbum,
+			ItemID:   albumID,
+		}]
+		if ok && collection != nil {
+			result = append(result, collection)
+		}
+	}
+
+	return result
 }
 
 func (s *ServiceImpl) downloadSingleTrack(
@@ -231,9 +363,36 @@ func (s *ServiceImpl) downloadSingleTrack(
 		return
 	}
 
+	metadata.startedTracks.Add(1)
+	s.status.addPendingTracks(-1)
+	s.printTrackEvent(trackEventStart, strconv.FormatInt(trackID, 10))
+
+	// Tracks fetched by an earlier sync of the playlist are not requested again.
+	if s.skipSyncedTrack(ctx, trackID, metadata) {
+		s.registerTrackOutcome(ctx, metadata, false)
+
+		return
+	}
+
+	// Tracks saved by earlier runs are not downloaded again while their files exist.
+	if s.skipDownloadedTrack(ctx, trackID, metadata) {
+		s.registerTrackOutcome(ctx, metadata, false)
+
+		return
+	}
+
+	// Tracks kept in other library folders are not downloaded into output_path again.
+	if s.skipExistingLibraryTrack(ctx, trackID, metadata) {
+		s.registerTrackOutcome(ctx, metadata, false)
+
+		return
+	}
+
 	// Create new download track task.
 	task, err := s.newDownloadTrackTask(ctx, trackIndex, trackID, metadata)
 	if err != nil {
+		s.registerTrackOutcome(ctx, metadata, true)
+
 		return
 	}
 
@@ -241,15 +400,20 @@ func (s *ServiceImpl) downloadSingleTrack(
 		return
 	}
 
+	ctx = withTrackDownloadContext(ctx, task)
+
 	// Download track.
 	s.downloadTrack(ctx, task)
+	s.registerTrackOutcome(ctx, metadata, task.isFailed)
 
 	if ctx.Err() != nil {
 		return
 	}
 
-	// Random pause.
-	utils.RandomPause(0, s.cfg.ParsedMaxDownloadPause)
+	// Random pause, longer during polite_hours.
+	stopPauseTimer := s.startPhaseTimer(DownloadPhasePause)
+	utils.RandomPause(0, s.maxDownloadPause(ctx))
+	stopPauseTimer()
 }
 
 func (s *ServiceImpl) newDownloadTrackTask(
@@ -265,6 +429,7 @@ func (s *ServiceImpl) newDownloadTrackTask(
 		trackIDString:   strconv.FormatInt(trackID, 10),
 		audioCollection: metadata.audioCollection,
 		metadata:        metadata,
+		duplicateNumber: metadata.duplicateNumbers[trackIndex],
 	}
 
 	// Fetch track metadata.
@@ -322,24 +487,44 @@ func (s *ServiceImpl) downloadTrack(
 		return
 	}
 
-	// Resolve quality and stream URL.
-	if !s.resolveQualityAndStream(ctx, task) {
-		return // Errors already handled.
-	}
+	// A failed stream may be retried in a lower quality (quality_fallback).
+	for {
+		// Outside download_window, the track waits with its metadata ready, and the stream URL is requested later.
+		if !s.waitForDownloadWindow(ctx) {
+			return
+		}
 
-	if ctx.Err() != nil {
-		return
-	}
+		// Once max_api_requests is used up with api_quota_action set to pause, the track waits for the pause to end.
+		if !s.waitForAPIQuota(ctx) {
+			return
+		}
 
-	// Generate file paths and tags.
-	s.prepareTrackFiles(ctx, task)
+		// Resolve quality and stream URL.
+		if !s.resolveQualityAndStream(ctx, task) {
+			return // Errors already handled.
+		}
 
-	if ctx.Err() != nil {
-		return
-	}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Generate file paths and tags.
+		s.prepareTrackFiles(ctx, task)
 
-	// Download and finalize.
-	s.downloadAndFinalizeTrack(ctx, task)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Download and finalize.
+		if !s.downloadAndFinalizeTrack(ctx, task) {
+			// The quality is known only once the fallback settles, so the downgrade is recorded once per track.
+			if !task.isFailed {
+				s.recordQualityDowngradeIfNeeded(task, task.quality, false)
+			}
+
+			return
+		}
+	}
 }
 
 // prepareRegularTrackTask prepares track context for regular tracks/albums/playlists.
@@ -406,7 +591,19 @@ func (s *ServiceImpl) resolveQualityAndStream(
 	ctx context.Context,
 	t *downloadTrackTask,
 ) bool {
-	qualityResult, err := s.resolveTrackQuality(ctx, t.trackIDString, t.track, t.metadata)
+	defer s.startPhaseTimer(DownloadPhaseStream)()
+
+	desiredQuality := s.configuredQuality()
+	if t.qualityCap > 0 {
+		desiredQuality = t.qualityCap
+	}
+
+	qualityResult, err := s.resolveTrackQuality(ctx, t.trackIDString, t.track, t.metadata, desiredQuality)
+	for err != nil && s.lowerQualityCap(ctx, t, desiredQuality, err) {
+		desiredQuality = t.qualityCap
+		qualityResult, err = s.resolveTrackQuality(ctx, t.trackIDString, t.track, t.metadata, desiredQuality)
+	}
+
 	if err != nil {
 		s.handleError(ctx, &DownloadError{
 			Category:       DownloadCategoryTrack,
@@ -419,21 +616,28 @@ func (s *ServiceImpl) resolveQualityAndStream(
 			Error:          err,
 		}, true)
 
+		t.isFailed = true
+
 		return false
 	}
 
 	// Check if track should be skipped due to quality constraints.
 	if qualityResult.ShouldSkip {
-		s.handleTrackSkipped(SkipReasonQuality, &DownloadError{
-			Category:       DownloadCategoryTrack,
-			ItemID:         t.trackIDString,
-			ItemTitle:      t.track.Title,
-			ParentCategory: t.metadata.category,
-			ParentID:       t.parentID,
-			ParentTitle:    t.parentTitle,
-			Phase:          "quality check",
-			Error:          qualityResult.SkipReason,
-		})
+		s.recordQualityDowngradeIfNeeded(t, qualityResult.Quality, true)
+
+		result := s.validator.Validate(ctx, &TrackCandidate{Track: t.track, Quality: qualityResult.Quality})
+		if result.IsValid {
+			// The resolvers report the quality they skipped, so this only guards against a mismatch.
+			result = &ValidationResult{
+				Rule:       ValidationRuleMinQuality,
+				SkipReason: SkipReasonQuality,
+				Error:      qualityResult.SkipReason,
+				Threshold:  TrackQuality(s.cfg.MinQuality).String(),
+				Phase:      "quality check",
+			}
+		}
+
+		s.skipInvalidTrack(t, result)
 
 		return false
 	}
@@ -444,24 +648,47 @@ func (s *ServiceImpl) resolveQualityAndStream(
 	return true
 }
 
-// validateTrackConstraints validates duration and other constraints.
+// recordQualityDowngradeIfNeeded records a music track delivered below the requested quality,
+// or skipped by min_quality, so it can be downloaded again once a better quality appears.
+func (s *ServiceImpl) recordQualityDowngradeIfNeeded(t *downloadTrackTask, quality TrackQuality, isSkipped bool) {
+	category := t.metadata.category
+	if category == DownloadCategoryAudiobook || category == DownloadCategoryPodcast {
+		return
+	}
+
+	requestedQuality := s.configuredQuality()
+	if !isSkipped && quality >= requestedQuality {
+		return
+	}
+
+	item := &QualityDowngrade{
+		TrackID:          t.trackIDString,
+		Title:            t.track.Title,
+		ParentCategory:   category,
+		ParentID:         t.parentID,
+		ParentTitle:      t.parentTitle,
+		RequestedQuality: requestedQuality,
+		AvailableQuality: quality,
+		IsSkipped:        isSkipped,
+		IsStreamFallback: t.qualityCap > 0,
+	}
+
+	if len(t.track.ArtistNames) > 0 {
+		item.Title = strings.Join(t.track.ArtistNames, ", ") + " - " + t.track.Title
+	}
+
+	s.recordQualityDowngrade(item)
+}
+
+// validateTrackConstraints runs the rules of the track validator that do not need the stream.
 func (s *ServiceImpl) validateTrackConstraints(
 	ctx context.Context,
 	task *downloadTrackTask,
 ) bool {
-	result := s.validator.Validate(ctx, task.track)
+	result := s.validator.Validate(ctx, &TrackCandidate{Track: task.track})
 
 	if !result.IsValid {
-		s.handleTrackSkipped(result.SkipReason, &DownloadError{
-			Category:       DownloadCategoryTrack,
-			ItemID:         task.trackIDString,
-			ItemTitle:      task.track.Title,
-			ParentCategory: task.metadata.category,
-			ParentID:       task.parentID,
-			ParentTitle:    task.parentTitle,
-			Phase:          "duration check",
-			Error:          result.Error,
-		})
+		s.skipInvalidTrack(task, result)
 
 		return false
 	}
@@ -469,6 +696,28 @@ func (s *ServiceImpl) validateTrackConstraints(
 	return true
 }
 
+// skipInvalidTrack records the track failing a validation rule as skipped, counting it for the rule.
+func (s *ServiceImpl) skipInvalidTrack(task *downloadTrackTask, result *ValidationResult) {
+	s.stats.update(func(stats *DownloadStatistics) {
+		if stats.TracksSkippedByRule == nil {
+			stats.TracksSkippedByRule = make(map[string]int64)
+		}
+
+		stats.TracksSkippedByRule[result.Rule]++
+	})
+
+	s.handleTrackSkipped(result.SkipReason, result.Threshold, &DownloadError{
+		Category:       DownloadCategoryTrack,
+		ItemID:         task.trackIDString,
+		ItemTitle:      task.track.Title,
+		ParentCategory: task.metadata.category,
+		ParentID:       task.parentID,
+		ParentTitle:    task.parentTitle,
+		Phase:          result.Phase,
+		Error:          result.Error,
+	})
+}
+
 // prepareTrackFiles generates file paths and tags for the track.
 func (s *ServiceImpl) prepareTrackFiles(
 	ctx context.Context,
@@ -478,11 +727,13 @@ func (s *ServiceImpl) prepareTrackFiles(
 	task.trackPosition = resolveTrackPosition(task)
 
 	trackTags := buildTrackTags(&trackTagContext{
-		trackNumber:     task.trackPosition,
-		track:           task.track,
-		audioCollection: task.audioCollection,
-		albumTags:       task.albumTags,
-		category:        task.metadata.category,
+		trackNumber:      task.trackPosition,
+		track:            task.track,
+		audioCollection:  task.audioCollection,
+		albumTags:        task.albumTags,
+		category:         task.metadata.category,
+		artistJoinStyle:  s.cfg.ArtistJoinStyle,
+		trackNumberWidth: task.metadata.playlistSync.trackNumberWidth(),
 	})
 
 	// Generate filename.
@@ -508,6 +759,10 @@ func (s *ServiceImpl) prepareTrackFiles(
 		)
 	}
 
+	if task.duplicateNumber > 0 {
+		task.trackFilename = fmt.Sprintf("%s (%d)", task.trackFilename, task.duplicateNumber)
+	}
+
 	task.trackFilename = utils.SetFileExtension(
 		utils.SanitizeFilename(task.trackFilename),
 		task.quality.Extension(),
@@ -530,7 +785,20 @@ func (s *ServiceImpl) prepareTrackFiles(
 		basePath = "." // last-resort fallback to avoid writing to an empty path
 	}
 
-	task.trackPath = filepath.Join(basePath, task.trackFilename)
+	trackPath := filepath.Join(basePath, task.trackFilename)
+
+	// An upgraded watched track replaces its lower-quality file in the folder of the collection it was saved for.
+	if upgradePath, ok := s.upgradeInPlacePath(task); ok {
+		trackPath = upgradePath
+	}
+
+	// Keep the track apart from files of other tracks differing only by letter case.
+	task.trackPath = s.claimPath(
+		ctx,
+		trackPath,
+		fmt.Sprintf("track:%s:%d", task.trackIDString, task.duplicateNumber),
+		false)
+	task.trackFilename = filepath.Base(task.trackPath)
 }
 
 func resolveTrackPosition(task *downloadTrackTask) int64 {
@@ -549,18 +817,24 @@ func resolveTrackPosition(task *downloadTrackTask) int64 {
 		}
 	}
 
+	// A synced playlist numbers its new tracks after the ones fetched before.
+	if position, ok := task.metadata.playlistSync.trackPosition(task.trackID); ok {
+		return position
+	}
+
 	return task.trackIndex
 }
 
 // downloadAndFinalizeTrack downloads the track and writes metadata.
+// It returns true if the download failed and should be retried in the lower quality set in task.qualityCap.
 func (s *ServiceImpl) downloadAndFinalizeTrack(
 	ctx context.Context,
 	task *downloadTrackTask,
-) {
+) bool {
 	unlockPath := s.lockPath(task.trackPath)
 	defer unlockPath()
 
-	logger.Infof(
+	s.logTrackf(
 		ctx,
 		"Downloading %s %d of %d: %s (ID: %s, Quality: %s)",
 		task.metadata.category.ToSubcategory(),
@@ -572,7 +846,15 @@ func (s *ServiceImpl) downloadAndFinalizeTrack(
 	)
 
 	// Download track.
+	downloadStartTime := time.Now()
 	result, err := s.downloadAndSaveTrack(ctx, task.streamURL, task.trackPath)
+
+	s.addDownloadDuration(time.Since(downloadStartTime))
+
+	if err != nil && s.lowerQualityCap(ctx, task, task.quality, err) {
+		return true
+	}
+
 	if err != nil {
 		s.handleError(ctx, &DownloadError{
 			Category:       DownloadCategoryTrack,
@@ -585,18 +867,38 @@ func (s *ServiceImpl) downloadAndFinalizeTrack(
 			Error:          err,
 		}, true)
 
-		return
+		task.isFailed = true
+
+		return false
 	}
 
 	if result.IsExist {
-		s.incrementTrackSkipped(SkipReasonExists)
-		return
+		task.metadata.rememberSavedTrack(task)
+		s.watchTrackUpgrade(ctx, task)
+		s.incrementTrackSkipped(task.trackIDString, SkipReasonExists)
+		s.recordSkippedItem(&SkippedItem{
+			TrackID:        task.trackIDString,
+			Title:          task.track.Title,
+			ParentCategory: task.metadata.category,
+			ParentID:       task.parentID,
+			ParentTitle:    task.parentTitle,
+			Reason:         SkipReasonExists,
+		})
+
+		return false
 	}
 
-	s.incrementTrackDownloaded(result.BytesDownloaded)
+	s.incrementTrackDownloaded(task.trackIDString, result.BytesDownloaded)
+	s.recordDownloadedTrack(task, result.BytesDownloaded)
+
+	if s.cfg.DryRun {
+		s.addQualitySizeEstimate(task.quality, task.track.Duration, result.BytesDownloaded)
+	}
 
 	// Write metadata and finalize assets.
 	s.writeAndFinalizeTrackAssets(ctx, task, result.TempPath)
+
+	return false
 }
 
 // writeAndFinalizeTrackAssets writes track metadata and finalizes covers/descriptions.
@@ -606,14 +908,16 @@ func (s *ServiceImpl) writeAndFinalizeTrackAssets(
 	tempPath string,
 ) {
 	trackTags := buildTrackTags(&trackTagContext{
-		trackNumber:     t.trackPosition,
-		track:           t.track,
-		audioCollection: t.audioCollection,
-		albumTags:       t.albumTags,
-		category:        t.metadata.category,
+		trackNumber:      t.trackPosition,
+		track:            t.track,
+		audioCollection:  t.audioCollection,
+		albumTags:        t.albumTags,
+		category:         t.metadata.category,
+		artistJoinStyle:  s.cfg.ArtistJoinStyle,
+		trackNumberWidth: t.metadata.playlistSync.trackNumberWidth(),
 	})
 
-	trackLyrics := s.downloadAndSaveLyrics(ctx, t.track, t.trackFilename, t.audioCollection)
+	trackLyrics := s.downloadAndSaveLyrics(ctx, t.track, t.trackFilename, trackTags, t.audioCollection)
 
 	s.writeTrackMetadata(ctx, t, trackTags, trackLyrics, tempPath)
 }
@@ -627,7 +931,19 @@ func (s *ServiceImpl) writeTrackMetadata(
 	tempPath string,
 ) {
 	var coverPath string
-	if t.audioCollection != nil {
+
+	isPlaylistTrack := t.metadata.category == DownloadCategoryPlaylist
+	isPlaylistCoverEmbedded := isPlaylistTrack && s.cfg.EmbedPlaylistCovers
+
+	switch {
+	case s.isCoverEmbeddingDisabled():
+		// Only the folder keeps the cover.
+	case isPlaylistCoverEmbedded:
+		// Playlist tracks embed the cover of their own album, not the playlist cover.
+		if !s.cfg.DryRun {
+			coverPath = s.getTrackAlbumCoverPath(ctx, t)
+		}
+	case t.audioCollection != nil:
 		for _, candidate := range []string{
 			strings.TrimSpace(t.audioCollection.embeddableCoverPath),
 			strings.TrimSpace(t.audioCollection.coverPath),
@@ -643,22 +959,43 @@ func (s *ServiceImpl) writeTrackMetadata(
 		}
 	}
 
+	var trackArtists []string
+	if t.track != nil {
+		trackArtists = parseArtistCredits(t.track.ArtistNames).all()
+	}
+
 	writeTagsRequest := &WriteTagsRequest{
 		TrackPath:                  tempPath,
 		CoverPath:                  coverPath,
 		Quality:                    t.quality,
 		TrackTags:                  trackTags,
+		TrackArtists:               trackArtists,
 		TrackLyrics:                trackLyrics,
-		IsCoverEmbeddedToTrackTags: t.metadata.category != DownloadCategoryPlaylist,
+		IsCoverEmbeddedToTrackTags: !isPlaylistTrack || isPlaylistCoverEmbedded,
+		FLACPadding:                s.flacPadding(),
 	}
 
+	s.applyEmbeddedCover(writeTagsRequest)
+
 	// Skip in dry-run mode.
 	if s.cfg.DryRun {
+		t.metadata.rememberSavedTrack(t)
+		s.queueRcloneTarget(t)
+
 		return
 	}
 
+	// Re-encode at flac_compression_level before the tags are written, so they are kept as written.
+	stopFinalizeTimer := s.startPhaseTimer(DownloadPhaseFinalize)
+	s.reencodeFLAC(ctx, t, tempPath)
+	stopFinalizeTimer()
+
 	// Write tags.
-	err := s.tagProcessor.WriteTags(ctx, writeTagsRequest)
+	stopTaggingTimer := s.startPhaseTimer(DownloadPhaseTagging)
+	err := s.writeTagsWithTimeout(ctx, writeTagsRequest)
+
+	stopTaggingTimer()
+
 	if err != nil {
 		s.handleError(ctx, &DownloadError{
 			Category:       DownloadCategoryTrack,
@@ -671,16 +1008,19 @@ func (s *ServiceImpl) writeTrackMetadata(
 			Error:          err,
 		}, false)
 
-		_ = os.Remove(tempPath)
+		s.keepUntaggedAudio(ctx, t, tempPath)
 
 		return
 	}
 
+	defer s.startPhaseTimer(DownloadPhaseFinalize)()
+
 	// Rename to final path.
 	if err = utils.RenameFile(tempPath, t.trackPath, s.cfg.ReplaceTracks); err != nil {
 		if errors.Is(err, os.ErrExist) && !s.cfg.ReplaceTracks {
 			logger.Infof(ctx, "Track '%s' already exists, skipping download", t.trackPath)
-			s.incrementTrackSkipped(SkipReasonExists)
+			t.metadata.rememberSavedTrack(t)
+			s.incrementTrackSkipped(t.trackIDString, SkipReasonExists)
 
 			_ = os.Remove(tempPath)
 
@@ -699,7 +1039,17 @@ func (s *ServiceImpl) writeTrackMetadata(
 		}, false)
 
 		_ = os.Remove(tempPath)
+
+		return
 	}
+
+	t.metadata.isChanged.Store(true)
+	t.metadata.rememberSavedTrack(t)
+	s.recordHistory(ctx, t)
+	s.queueRcloneTarget(t)
+	s.watchTrackUpgrade(ctx, t)
+	s.createNormalizedCopy(ctx, t)
+	s.createPortableCopy(ctx, t, trackTags)
 }
 
 // getOrRegisterAudioCollection gets or registers an audio collection.
@@ -733,174 +1083,48 @@ func (s *ServiceImpl) getOrRegisterAudioCollection(
 	return collection
 }
 
-// downloadAndSaveTrack downloads and saves a track to a file.
-//
-//nolint:cyclop,funlen,gocognit,nolintlint // Function orchestrates complex download workflow with multiple sequential steps.
+// downloadAndSaveTrack downloads a track into a temporary file next to trackPath.
+// The caller moves the file into place after writing its tags.
 func (s *ServiceImpl) downloadAndSaveTrack(
 	ctx context.Context,
 	trackURL string,
 	trackPath string,
 ) (*DownloadTrackResult, error) {
-	// Check if final file already exists.
-	if !s.cfg.ReplaceTracks {
-		if _, err := os.Stat(trackPath); err == nil {
-			// In regular mode, return immediately.
-			if !s.cfg.DryRun {
-				logger.Infof(ctx, "Track '%s' already exists, skipping download", trackPath)
-
-				return &DownloadTrackResult{
-					IsExist:         true,
-					TempPath:        "",
-					BytesDownloaded: 0,
-				}, nil
-			}
-
-			// In dry-run mode, just log and return (no need to fetch size).
-			logger.Infof(ctx, "[DRY-RUN] Track '%s' already exists, would skip", trackPath)
-
-			return &DownloadTrackResult{
-				IsExist:         true,
-				TempPath:        "",
-				BytesDownloaded: 0,
-			}, nil
-		}
-	}
-
-	// Dry-run mode: simulate download without fetching actual data.
-	if s.cfg.DryRun {
-		logger.Infof(ctx, "[DRY-RUN] Would download track to: %s", trackPath)
-
-		// Fetch metadata to get file size estimate.
-		fetchResult, fetchErr := s.zvukClient.FetchTrack(ctx, trackURL)
-		if fetchErr != nil {
-			return nil, fmt.Errorf("failed to fetch track metadata: %w", fetchErr)
-		}
-
-		// Close immediately without reading.
-		_ = fetchResult.Body.Close()
-
-		return &DownloadTrackResult{
-			IsExist:         false,
-			TempPath:        "",
-			BytesDownloaded: fetchResult.TotalBytes,
-		}, nil
-	}
-
-	// Fetch the track.
-	fetchResult, fetchErr := s.zvukClient.FetchTrack(ctx, trackURL)
-	if fetchErr != nil {
-		return nil, fmt.Errorf("failed to fetch track: %w", fetchErr)
-	}
-
-	defer fetchResult.Body.Close() //nolint:errcheck // Error on close is not critical here.
-
-	// Download to temporary .part file first for atomic operation.
-	// Use unique temp files per worker to avoid concurrent write collisions.
-	tmpFile, openErr := os.CreateTemp(filepath.Dir(trackPath), filepath.Base(trackPath)+".part-*")
-	if openErr != nil {
-		return nil, fmt.Errorf("failed to create temporary track file: %w", openErr)
-	}
-
-	tempFilePath := tmpFile.Name()
-	if chmodErr := tmpFile.Chmod(defaultFolderPermissions); chmodErr != nil {
-		_ = tmpFile.Close()
-		_ = os.Remove(tempFilePath)
-
-		return nil, fmt.Errorf("failed to set temporary file permissions: %w", chmodErr)
-	}
-
-	f := tmpFile
-
-	// Track whether download succeeded.
-	// If not, we'll clean up the .part file on function exit.
-	var downloadSucceeded bool
-
-	defer func() {
-		// Ensure file is closed before cleanup.
-		closeErr := f.Close()
-
-		// Clean up .part file if download failed.
-		if !downloadSucceeded {
-			// Small delay to ensure file handle is released (Windows needs this).
-			time.Sleep(10 * time.Millisecond)
-
-			if removeErr := os.Remove(tempFilePath); removeErr != nil && !os.IsNotExist(removeErr) {
-				// Log warning but don't fail - this is best-effort cleanup.
-				logger.Warnf(ctx, "Failed to clean up temporary file '%s': %v (close error: %v)",
-					tempFilePath, removeErr, closeErr)
-			}
-		}
-	}()
-
-	// Initialize progress tracker.
-	// Progress bars are disabled when downloading concurrently to avoid terminal output conflicts.
-	var writer io.Writer
-
-	if logger.Level() <= zap.InfoLevel && s.cfg.MaxConcurrentDownloads == 1 {
-		bar := progressbar.DefaultBytes(
-			fetchResult.TotalBytes,
-			"Downloading",
-		)
-
-		writer = io.MultiWriter(f, bar)
-	} else {
-		writer = f
+	result, err := s.downloader.Download(ctx, &DownloadRequest{
+		Kind:            downloadKindTrack,
+		Source:          &trackStreamSource{client: s.zvukClient, url: trackURL},
+		DestinationPath: trackPath,
+		Replace:         s.cfg.ReplaceTracks,
+		KeepTempFile:    true,
+		Progress:        s.trackProgress(trackPath),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Download logic.
-	var (
-		bytesWritten int64
-		err          error
-	)
-
-	if s.cfg.ParsedDownloadSpeedLimit == 0 {
-		bytesWritten, err = io.Copy(writer, fetchResult.Body)
-	} else {
-		for {
-			var n int64
-
-			n, err = io.CopyN(writer, fetchResult.Body, s.cfg.ParsedDownloadSpeedLimit)
-			bytesWritten += n
+	return &DownloadTrackResult{
+		IsExist:         result.IsExist,
+		TempPath:        result.Path,
+		BytesDownloaded: result.Bytes,
+	}, nil
+}
 
-			if errors.Is(err, io.EOF) {
-				err = nil
+// trackProgress returns the progress hook of a track transfer.
+// Every transfer is registered for live status dumps; progress bars are shown only
+// when tracks are downloaded one at a time to avoid terminal output conflicts.
+func (s *ServiceImpl) trackProgress(trackPath string) func(totalBytes int64) (io.Writer, func()) {
+	return func(totalBytes int64) (io.Writer, func()) {
+		activeTrack := s.status.startTrack(filepath.Base(trackPath), totalBytes)
+		finish := func() { s.status.finishTrack(activeTrack) }
 
-				break
-			}
+		if s.progressEvents == nil && logger.Level() <= zap.InfoLevel && s.concurrentDownloads() == 1 {
+			bar := progressbar.DefaultBytes(totalBytes, "Downloading")
 
-			if err != nil {
-				break
-			}
-
-			// Throttle to respect speed limit.
-			time.Sleep(time.Second)
+			return io.MultiWriter(bar, activeTrack), finish
 		}
-	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to write file: %w", err)
+		return activeTrack, finish
 	}
-
-	// Verify that we downloaded the expected number of bytes.
-	if bytesWritten != fetchResult.TotalBytes {
-		return nil, fmt.Errorf(
-			"%w: wrote %d bytes, expected %d bytes",
-			ErrIncompleteDownload,
-			bytesWritten,
-			fetchResult.TotalBytes,
-		)
-	}
-
-	// Mark download as successful to prevent cleanup by defer.
-	// The .part file will be renamed to final name by the caller after tags are written.
-	downloadSucceeded = true
-
-	// Return the temp file path for the caller to rename after writing tags.
-	return &DownloadTrackResult{
-		IsExist:         false,
-		TempPath:        tempFilePath,
-		BytesDownloaded: bytesWritten,
-	}, nil
 }
 
 // downloadAndSaveLyrics downloads and saves lyrics for a track.
@@ -908,6 +1132,7 @@ func (s *ServiceImpl) downloadAndSaveLyrics(
 	ctx context.Context,
 	track *zvuk.Track,
 	trackFilename string,
+	trackTags map[string]string,
 	audioCollection *audioCollection,
 ) *zvuk.Lyrics {
 	if !s.cfg.DownloadLyrics ||
@@ -917,7 +1142,7 @@ func (s *ServiceImpl) downloadAndSaveLyrics(
 		return nil
 	}
 
-	logger.Infof(ctx, "Downloading lyrics for track: %s\n", track.Title)
+	s.logTrackf(ctx, "Downloading lyrics for track: %s\n", track.Title)
 
 	trackID := strconv.FormatInt(track.ID, 10)
 
@@ -935,89 +1160,40 @@ func (s *ServiceImpl) downloadAndSaveLyrics(
 		return nil
 	}
 
-	lyricsPath := filepath.Join(
-		audioCollection.tracksPath,
-		utils.SetFileExtension(trackFilename, defaultLyricsExtension, true))
-
-	// Dry-run mode: simulate lyrics download.
-	if s.cfg.DryRun {
-		// Check if lyrics file exists.
-		if _, statErr := os.Stat(lyricsPath); statErr == nil && !s.cfg.ReplaceLyrics {
-			logger.Infof(ctx, "[DRY-RUN] Lyrics '%s' already exists, would skip", lyricsPath)
-			s.incrementLyricsSkipped()
-		} else {
-			logger.Infof(ctx, "[DRY-RUN] Would save lyrics to: %s", lyricsPath)
-			s.incrementLyricsDownloaded()
-		}
-
-		return lyrics
+	lyricsTags := maps.Clone(trackTags)
+	if lyricsTags == nil {
+		lyricsTags = make(map[string]string, 1)
 	}
 
-	isLyricsExist, err := s.writeLyrics(ctx, lyrics.Lyrics, lyricsPath)
+	lyricsTags[TagTrackFilename] = strings.TrimSuffix(trackFilename, filepath.Ext(trackFilename))
+
+	lyricsPath := filepath.Join(
+		audioCollection.tracksPath,
+		utils.SetFileExtension(s.templateManager.GetLyricsFilename(ctx, lyricsTags),
+			cmp.Or(s.cfg.LyricsExtension, config.DefaultLyricsExtension),
+			false))
+
+	result, err := s.downloader.Download(ctx, &DownloadRequest{
+		Kind:            downloadKindLyrics,
+		Source:          textSource(lyrics.Lyrics),
+		DestinationPath: lyricsPath,
+		Replace:         s.cfg.ReplaceLyrics,
+	})
 	if err != nil {
 		logger.Errorf(ctx, "Failed to write lyrics: %v", err)
 
 		return nil
 	}
 
-	if isLyricsExist {
+	switch {
+	case result.IsExist:
 		s.incrementLyricsSkipped()
-	} else {
+	case s.cfg.DryRun:
+		s.incrementLyricsDownloaded()
+	default:
 		s.incrementLyricsDownloaded()
 		logger.Infof(ctx, "Lyrics saved to file: %s", lyricsPath)
 	}
 
 	return lyrics
 }
-
-// writeLyrics writes lyrics to a file.
-func (s *ServiceImpl) writeLyrics(ctx context.Context, lyrics, destinationPath string) (bool, error) {
-	destinationPath = filepath.Clean(destinationPath)
-
-	if !s.cfg.ReplaceLyrics {
-		if _, err := os.Stat(destinationPath); err == nil {
-			logger.Infof(ctx, "File '%s' already exists, skipping download", destinationPath)
-
-			return true, nil
-		}
-	}
-
-	// Write into a temp file in the same folder, then atomically rename to destination.
-	// This avoids partially-written .lrc files on crashes/interruption.
-	tmpFile, err := os.CreateTemp(filepath.Dir(destinationPath), filepath.Base(destinationPath)+".tmp-*")
-	if err != nil {
-		return false, err
-	}
-
-	tmpPath := tmpFile.Name()
-
-	defer func() {
-		_ = tmpFile.Close()
-		_ = os.Remove(tmpPath)
-	}()
-
-	if chmodErr := os.Chmod(tmpPath, defaultFolderPermissions); chmodErr != nil {
-		return false, chmodErr
-	}
-
-	_, err = tmpFile.WriteString(lyrics)
-	if err != nil {
-		return false, err
-	}
-
-	if err = tmpFile.Close(); err != nil {
-		return false, err
-	}
-
-	if err = utils.RenameFile(tmpPath, destinationPath, s.cfg.ReplaceLyrics); err != nil {
-		if errors.Is(err, os.ErrExist) && !s.cfg.ReplaceLyrics {
-			logger.Infof(ctx, "File '%s' already exists, skipping download", destinationPath)
-
-			return true, nil
-		}
-
-		return false, err
-	}
-
-	return false, nil
-}
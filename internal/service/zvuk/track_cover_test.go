@@ -2,13 +2,17 @@ package zvuk
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
 	"github.com/oshokin/zvuk-grabber/internal/config"
 )
 
@@ -45,6 +49,7 @@ func TestWriteTrackMetadata_UsesEmbeddableCoverPath(t *testing.T) {
 			DryRun:     false,
 		},
 		tagProcessor: rec,
+		stats:        newStatsCollector(),
 	}
 
 	task := &downloadTrackTask{
@@ -92,6 +97,7 @@ func TestWriteTrackMetadata_FallsBackToFinalCoverPath(t *testing.T) {
 			DryRun:     false,
 		},
 		tagProcessor: rec,
+		stats:        newStatsCollector(),
 	}
 
 	task := &downloadTrackTask{
@@ -114,3 +120,57 @@ func TestWriteTrackMetadata_FallsBackToFinalCoverPath(t *testing.T) {
 	assert.FileExists(t, finalTrackPath)
 	assert.NoFileExists(t, tempTrackPath)
 }
+
+func TestWriteTrackMetadata_EmbedsAlbumCoverInPlaylistTracks(t *testing.T) {
+	t.Parallel()
+
+	setup := newTestDownloadSetup(t, func(cfg *config.Config) {
+		cfg.EmbedPlaylistCovers = true
+	})
+	defer setup.cleanup()
+
+	impl := setup.service.(*ServiceImpl)
+	defer func() { require.NoError(t, impl.covers.cleanup()) }()
+
+	rec := &recordingTagProcessor{}
+	impl.tagProcessor = rec
+
+	// Both tracks share the album, so its cover is downloaded once.
+	setup.mockClient.EXPECT().
+		DownloadFromURL(gomock.Any(), "https://cdn.example.com/album.jpg").
+		Return(io.NopCloser(strings.NewReader("album cover")), nil).
+		Times(1)
+
+	album := &zvuk.Release{
+		ID:    10,
+		Image: &zvuk.Image{SourceURL: "https://cdn.example.com/album.jpg?size={size}"},
+	}
+
+	metadata := &downloadTracksMetadata{category: DownloadCategoryPlaylist}
+
+	for _, trackID := range []string{"1", "2"} {
+		tempTrackPath := filepath.Join(setup.tempDir, trackID+".mp3.part")
+		require.NoError(t, os.WriteFile(tempTrackPath, []byte("fake audio data"), 0o644))
+
+		task := &downloadTrackTask{
+			trackIDString: trackID,
+			track:         &zvuk.Track{Title: "Track " + trackID},
+			quality:       TrackQualityMP3Mid,
+			trackPath:     filepath.Join(setup.tempDir, trackID+".mp3"),
+			album:         album,
+			audioCollection: &audioCollection{
+				category: DownloadCategoryPlaylist,
+			},
+			metadata: metadata,
+		}
+
+		impl.writeTrackMetadata(context.Background(), task, map[string]string{}, nil, tempTrackPath)
+
+		require.NotNil(t, rec.lastReq)
+		assert.True(t, rec.lastReq.IsCoverEmbeddedToTrackTags)
+		require.FileExists(t, rec.lastReq.CoverPath)
+		assert.Equal(t, ".jpg", filepath.Ext(rec.lastReq.CoverPath))
+	}
+
+	assert.Equal(t, int64(1), impl.Statistics().CoversDownloaded)
+}
@@ -0,0 +1,61 @@
+package zvuk
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Track events printed one per line by the plain progress output.
+const (
+	// trackEventStart is printed when a track is picked up.
+	trackEventStart = "START"
+	// trackEventDone is printed when a track is downloaded, with its size.
+	trackEventDone = "DONE"
+	// trackEventSkip is printed when a track is skipped, with the skip reason code.
+	trackEventSkip = "SKIP"
+	// trackEventLink is printed when a repeated playlist track is saved as a hard link.
+	trackEventLink = "LINK"
+	// trackEventFail is printed when a track fails.
+	trackEventFail = "FAIL"
+)
+
+// trackEventPrinter prints track events as lines like "DONE track 123 4.2MB", so scripts can tail them.
+type trackEventPrinter struct {
+	// w receives the events.
+	w io.Writer
+	// mutex keeps the lines of concurrent downloads from interleaving.
+	mutex sync.Mutex
+}
+
+// newTrackEventPrinter creates a printer writing the track events to w.
+func newTrackEventPrinter(w io.Writer) *trackEventPrinter {
+	return &trackEventPrinter{w: w}
+}
+
+// printTrackEvent prints a track event when the progress is plain.
+// The track ID of an unknown track is printed as "-" to keep the columns.
+func (s *ServiceImpl) printTrackEvent(event, trackID string, details ...string) {
+	if s.progressEvents == nil {
+		return
+	}
+
+	if trackID == "" {
+		trackID = "-"
+	}
+
+	fields := append([]string{event, DownloadCategoryTrack.String(), trackID}, details...)
+
+	s.progressEvents.mutex.Lock()
+	defer s.progressEvents.mutex.Unlock()
+
+	fmt.Fprintln(s.progressEvents.w, strings.Join(fields, " ")) //nolint:errcheck // Progress output is best-effort.
+}
+
+// formatEventBytes formats a size without spaces, so every event field is a single word.
+func formatEventBytes(bytes int64) string {
+	return strings.ReplaceAll(humanize.Bytes(uint64(max(bytes, 0))), " ", "")
+}
@@ -0,0 +1,38 @@
+package zvuk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestPrintTrackEvent tests that the plain progress output prints one line per track event.
+func TestPrintTrackEvent(t *testing.T) {
+	t.Parallel()
+
+	impl, ok := NewService(&config.Config{Progress: config.ProgressPlain}, nil, nil, nil, nil).(*ServiceImpl)
+	require.True(t, ok, "service must be of type *ServiceImpl")
+	require.NotNil(t, impl.progressEvents)
+
+	var output bytes.Buffer
+
+	impl.progressEvents = newTrackEventPrinter(&output)
+
+	impl.printTrackEvent(trackEventStart, "123")
+	impl.incrementTrackDownloaded("123", 4200000)
+	impl.incrementTrackSkipped("456", SkipReasonExists)
+	impl.incrementTrackSkipped("457", SkipReasonInLibrary)
+	impl.incrementTrackLinked("458")
+	impl.incrementTrackFailed("")
+
+	assert.Equal(t, "START track 123\n"+
+		"DONE track 123 4.2MB\n"+
+		"SKIP track 456 exists\n"+
+		"SKIP track 457 in-library\n"+
+		"LINK track 458\n"+
+		"FAIL track -\n", output.String())
+}
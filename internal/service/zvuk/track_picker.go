@@ -0,0 +1,292 @@
+package zvuk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// ErrInvalidTrackSelection is returned when a line typed into the track picker cannot be parsed.
+var ErrInvalidTrackSelection = errors.New("invalid track selection")
+
+// trackPicker shows a checkbox list of the tracks of an album or playlist and lets the user
+// choose which of them are downloaded (--interactive).
+type trackPicker struct {
+	reader *bufio.Reader
+	writer io.Writer
+	// mutex keeps the prompts of different collections from being interleaved.
+	mutex sync.Mutex
+}
+
+// pickerTrack is a track shown in the picker.
+type pickerTrack struct {
+	title    string
+	duration int64
+	quality  TrackQuality
+}
+
+// newTrackPicker creates a track picker reading the answers from r and printing the list to w.
+func newTrackPicker(r io.Reader, w io.Writer) *trackPicker {
+	return &trackPicker{
+		reader: bufio.NewReader(r),
+		writer: w,
+	}
+}
+
+// pickTracks lets the user choose the tracks of an album or playlist before they are downloaded.
+// It returns false when no track is chosen, so the collection is not downloaded at all.
+func (s *ServiceImpl) pickTracks(ctx context.Context, metadata *downloadTracksMetadata) bool {
+	collection := metadata.audioCollection
+	if s.trackPicker == nil || collection == nil ||
+		(collection.category != DownloadCategoryAlbum && collection.category != DownloadCategoryPlaylist) {
+		return true
+	}
+
+	// Repeated playlist tracks are listed once and follow the choice made for their first occurrence.
+	// Tracks left out by --tracks are not listed.
+	var (
+		indexes = make([]int, 0, len(metadata.trackIDs))
+		tracks  = make([]*pickerTrack, 0, len(metadata.trackIDs))
+	)
+
+	for index, trackID := range metadata.trackIDs {
+		if metadata.isDuplicate(index) || metadata.isDeselected(index) {
+			continue
+		}
+
+		indexes = append(indexes, index)
+		tracks = append(tracks, s.newPickerTrack(trackID, metadata.tracksMetadata))
+	}
+
+	if len(tracks) == 0 {
+		return true
+	}
+
+	header := fmt.Sprintf("%s '%s'", collection.category.ToTitleCase(), collection.title)
+
+	selected, err := s.trackPicker.pick(header, tracks)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to read the track selection, downloading every track: %v", err)
+
+		return true
+	}
+
+	deselectedTrackIDs := make(map[int64]struct{})
+
+	for i, isSelected := range selected {
+		if !isSelected {
+			deselectedTrackIDs[metadata.trackIDs[indexes[i]]] = struct{}{}
+		}
+	}
+
+	if len(deselectedTrackIDs) == 0 {
+		return true
+	}
+
+	if len(deselectedTrackIDs) == len(tracks) {
+		logger.Infof(ctx, "No tracks of %s '%s' are selected, skipping it",
+			collection.category.ToLowerCase(), collection.title)
+
+		return false
+	}
+
+	if metadata.deselectedTracks == nil {
+		metadata.deselectedTracks = make(map[int]struct{}, len(deselectedTrackIDs))
+	}
+
+	for index, trackID := range metadata.trackIDs {
+		if _, ok := deselectedTrackIDs[trackID]; ok {
+			metadata.deselectedTracks[index] = struct{}{}
+		}
+	}
+
+	logger.Infof(ctx, "Downloading %d of %d tracks of %s '%s'", len(tracks)-len(deselectedTrackIDs), len(tracks),
+		collection.category.ToLowerCase(), collection.title)
+
+	return true
+}
+
+// newPickerTrack describes a track for the picker with the quality it would be downloaded in.
+func (s *ServiceImpl) newPickerTrack(trackID int64, tracksMetadata map[string]*zvuk.Track) *pickerTrack {
+	track := tracksMetadata[strconv.FormatInt(trackID, 10)]
+	if track == nil {
+		return &pickerTrack{title: "Track ID: " + strconv.FormatInt(trackID, 10)}
+	}
+
+	quality := ParseQuality(track.HighestQuality)
+	if track.HasFLAC {
+		quality = TrackQualityFLAC
+	}
+
+	if wantedQuality := s.configuredQuality(); quality > wantedQuality {
+		quality = wantedQuality
+	}
+
+	return &pickerTrack{
+		title:    track.Title,
+		duration: track.Duration,
+		quality:  quality,
+	}
+}
+
+// isDeselected reports whether the track at the given index was left out by --tracks or in the track picker.
+func (m *downloadTracksMetadata) isDeselected(index int) bool {
+	_, ok := m.deselectedTracks[index]
+
+	return ok
+}
+
+// pick prints the checkbox list and toggles tracks until an empty line is entered.
+// Every track is selected at first. It returns the selection state of every track.
+func (p *trackPicker) pick(header string, tracks []*pickerTrack) ([]bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	selected := make([]bool, len(tracks))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	for {
+		if err := p.printTracks(header, tracks, selected); err != nil {
+			return nil, err
+		}
+
+		line, err := p.reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		answer := strings.ToLower(strings.TrimSpace(line))
+
+		switch {
+		case answer == "":
+			return selected, nil
+		case answer == "a":
+			setAll(selected, true)
+		case answer == "n":
+			setAll(selected, false)
+		default:
+			if toggleErr := toggleTracks(selected, answer); toggleErr != nil {
+				if _, printErr := fmt.Fprintf(p.writer, "%v\n", toggleErr); printErr != nil {
+					return nil, printErr
+				}
+			}
+		}
+
+		// Nothing more can be read, so the current selection is final.
+		if errors.Is(err, io.EOF) {
+			return selected, nil
+		}
+	}
+}
+
+// printTracks prints the checkbox list of tracks followed by the prompt.
+func (p *trackPicker) printTracks(header string, tracks []*pickerTrack, selected []bool) error {
+	var builder strings.Builder
+
+	selectedCount := 0
+
+	for _, isSelected := range selected {
+		if isSelected {
+			selectedCount++
+		}
+	}
+
+	fmt.Fprintf(&builder, "\n%s: %d of %d tracks selected\n", header, selectedCount, len(tracks))
+
+	numberWidth := len(strconv.Itoa(len(tracks)))
+
+	for i, track := range tracks {
+		checkbox := "[ ]"
+		if selected[i] {
+			checkbox = "[x]"
+		}
+
+		fmt.Fprintf(&builder, "  %s %*d. %s  %s  %s\n",
+			checkbox, numberWidth, i+1, track.title,
+			formatPickerDuration(track.duration), formatPickerQuality(track.quality))
+	}
+
+	builder.WriteString("Toggle tracks by number or range (e.g. 2,5-7), 'a' selects all, 'n' selects none, " +
+		"Enter starts the download: ")
+
+	_, err := io.WriteString(p.writer, builder.String())
+
+	return err
+}
+
+// toggleTracks flips the tracks whose numbers are listed in the answer, e.g. "2,5-7".
+func toggleTracks(selected []bool, answer string) error {
+	numbers := make([]int, 0, len(selected))
+
+	for part := range strings.SplitSeq(answer, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+
+		from, fromErr := strconv.Atoi(strings.TrimSpace(first))
+		to, toErr := strconv.Atoi(strings.TrimSpace(last))
+
+		if fromErr != nil || toErr != nil || from < 1 || to > len(selected) || from > to {
+			return fmt.Errorf("%w '%s': use track numbers from 1 to %d", ErrInvalidTrackSelection, part, len(selected))
+		}
+
+		for number := from; number <= to; number++ {
+			numbers = append(numbers, number)
+		}
+	}
+
+	for _, number := range numbers {
+		selected[number-1] = !selected[number-1]
+	}
+
+	return nil
+}
+
+// setAll selects or deselects every track.
+func setAll(selected []bool, isSelected bool) {
+	for i := range selected {
+		selected[i] = isSelected
+	}
+}
+
+// formatPickerDuration formats a duration in seconds as M:SS.
+func formatPickerDuration(seconds int64) string {
+	const secondsPerMinute = 60
+
+	if seconds <= 0 {
+		return "-:--"
+	}
+
+	return fmt.Sprintf("%d:%02d", seconds/secondsPerMinute, seconds%secondsPerMinute)
+}
+
+// formatPickerQuality returns a short name of the quality a track would be downloaded in.
+func formatPickerQuality(quality TrackQuality) string {
+	//nolint:exhaustive // All meaningful cases are explicitly handled; default covers unknown values.
+	switch quality {
+	case TrackQualityMP3Mid:
+		return "MP3 128"
+	case TrackQualityMP3High:
+		return "MP3 320"
+	case TrackQualityFLAC:
+		return "FLAC"
+	default:
+		return "unknown quality"
+	}
+}
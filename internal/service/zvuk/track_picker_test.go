@@ -0,0 +1,120 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestTrackPicker_Pick tests how the answers typed into the track picker change the selection.
+func TestTrackPicker_Pick(t *testing.T) {
+	t.Parallel()
+
+	tracks := []*pickerTrack{
+		{title: "First", duration: 225, quality: TrackQualityFLAC},
+		{title: "Second", duration: 61, quality: TrackQualityMP3High},
+		{title: "Third"},
+		{title: "Fourth"},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []bool
+	}{
+		{name: "everything is selected at first", input: "\n", expected: []bool{true, true, true, true}},
+		{name: "numbers and ranges are toggled", input: "2, 3-4\n4\n\n", expected: []bool{true, false, false, true}},
+		{name: "none and then one", input: "n\n3\n\n", expected: []bool{false, false, true, false}},
+		{name: "invalid answers are ignored", input: "5\n0-2\nx\n\n", expected: []bool{true, true, true, true}},
+		{name: "end of input confirms the selection", input: "1", expected: []bool{false, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output bytes.Buffer
+
+			selected, err := newTrackPicker(strings.NewReader(tt.input), &output).pick("Album 'Mutter'", tracks)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selected)
+			assert.Contains(t, output.String(), "Album 'Mutter': 4 of 4 tracks selected")
+			assert.Contains(t, output.String(), "[x] 1. First  3:45  FLAC")
+			assert.Contains(t, output.String(), "2. Second  1:01  MP3 320")
+		})
+	}
+}
+
+// TestPickTracks tests that the tracks left out in the picker are not downloaded.
+func TestPickTracks(t *testing.T) {
+	t.Parallel()
+
+	newMetadata := func() *downloadTracksMetadata {
+		return &downloadTracksMetadata{
+			audioCollection: &audioCollection{category: DownloadCategoryPlaylist, id: "100", title: "Mix"},
+			category:        DownloadCategoryPlaylist,
+			trackIDs:        []int64{1, 2, 1, 3},
+			tracksMetadata: map[string]*zvuk.Track{
+				"1": {ID: 1, Title: "First", HighestQuality: "flac"},
+				"2": {ID: 2, Title: "Second", HighestQuality: "high"},
+				"3": {ID: 3, Title: "Third", HighestQuality: "mid"},
+			},
+			duplicateNumbers: map[int]int64{2: 2},
+		}
+	}
+
+	newService := func(t *testing.T, input string) (*ServiceImpl, *bytes.Buffer) {
+		t.Helper()
+
+		impl, ok := NewService(&config.Config{Quality: uint8(TrackQualityMP3High)}, nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		var output bytes.Buffer
+
+		impl.trackPicker = newTrackPicker(strings.NewReader(input), &output)
+
+		return impl, &output
+	}
+
+	t.Run("repeated tracks follow their first occurrence", func(t *testing.T) {
+		t.Parallel()
+
+		impl, output := newService(t, "1\n\n")
+		metadata := newMetadata()
+
+		require.True(t, impl.pickTracks(context.Background(), metadata))
+		assert.Equal(t, map[int]struct{}{0: {}, 2: {}}, metadata.deselectedTracks)
+		assert.False(t, metadata.isCompleted(context.Background()))
+
+		// The chosen quality caps the quality shown.
+		assert.Contains(t, output.String(), "1. First  -:--  MP3 320")
+		assert.Contains(t, output.String(), "3. Third  -:--  MP3 128")
+	})
+
+	t.Run("collection with no tracks selected is skipped", func(t *testing.T) {
+		t.Parallel()
+
+		impl, _ := newService(t, "n\n\n")
+		require.False(t, impl.pickTracks(context.Background(), newMetadata()))
+	})
+
+	t.Run("audiobooks are not picked", func(t *testing.T) {
+		t.Parallel()
+
+		impl, output := newService(t, "n\n\n")
+
+		metadata := newMetadata()
+		metadata.audioCollection.category = DownloadCategoryAudiobook
+
+		require.True(t, impl.pickTracks(context.Background(), metadata))
+		assert.Empty(t, metadata.deselectedTracks)
+		assert.Empty(t, output.String())
+	})
+}
@@ -78,3 +78,71 @@ func TestResolveTrackPosition(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildTrackTags_PlaylistPosition verifies that playlist tracks get their position in the playlist
+// whatever the order they are downloaded in.
+func TestBuildTrackTags_PlaylistPosition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		trackIDs    []int64
+		trackID     int64
+		trackNumber int64
+		expected    string
+		expectedPad string
+	}{
+		{
+			name:        "position from playlist, not download index",
+			trackIDs:    []int64{10, 20, 30},
+			trackID:     30,
+			trackNumber: 1,
+			expected:    "3",
+			expectedPad: "03",
+		},
+		{
+			name:        "repeated track keeps its first position",
+			trackIDs:    []int64{10, 20, 10},
+			trackID:     10,
+			trackNumber: 3,
+			expected:    "1",
+			expectedPad: "01",
+		},
+		{
+			name:        "padding follows playlist length",
+			trackIDs:    sampleTrackIDs(120),
+			trackID:     7,
+			trackNumber: 1,
+			expected:    "7",
+			expectedPad: "007",
+		},
+		{
+			name:        "unknown track falls back to track number",
+			trackIDs:    []int64{10, 20},
+			trackID:     99,
+			trackNumber: 5,
+			expected:    "5",
+			expectedPad: "05",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tags := buildTrackTags(&trackTagContext{
+				trackNumber: tt.trackNumber,
+				track:       &zvuk.Track{ID: tt.trackID},
+				audioCollection: &audioCollection{
+					category:          DownloadCategoryPlaylist,
+					tracksCount:       int64(len(tt.trackIDs)),
+					playlistPositions: newPlaylistPositions(tt.trackIDs),
+				},
+				category: DownloadCategoryPlaylist,
+			})
+
+			assert.Equal(t, tt.expected, tags[TagPlaylistPosition])
+			assert.Equal(t, tt.expectedPad, tags[TagPlaylistPositionPad])
+		})
+	}
+}
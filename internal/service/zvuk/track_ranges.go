@@ -0,0 +1,56 @@
+package zvuk
+
+import (
+	"context"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// selectTrackRanges leaves out the tracks of a collection whose positions are not in --tracks.
+// It returns false when no track is in the ranges, so the collection is not downloaded at all.
+// Positions count from 1 in the order of the collection, and the tracks kept keep their numbers.
+func (s *ServiceImpl) selectTrackRanges(ctx context.Context, metadata *downloadTracksMetadata) bool {
+	collection := metadata.audioCollection
+	if len(s.cfg.ParsedTrackRanges) == 0 || collection == nil {
+		return true
+	}
+
+	deselectedTracks := make(map[int]struct{}, len(metadata.trackIDs))
+
+	for index := range metadata.trackIDs {
+		if !isInTrackRanges(s.cfg.ParsedTrackRanges, int64(index)+1) {
+			deselectedTracks[index] = struct{}{}
+		}
+	}
+
+	selectedCount := len(metadata.trackIDs) - len(deselectedTracks)
+	if selectedCount == 0 {
+		logger.Infof(ctx, "No tracks of %s '%s' are in the ranges '%s', skipping it",
+			collection.category.ToLowerCase(), collection.title, s.cfg.TrackRanges)
+
+		return false
+	}
+
+	if len(deselectedTracks) == 0 {
+		return true
+	}
+
+	metadata.deselectedTracks = deselectedTracks
+
+	logger.Infof(ctx, "Downloading tracks %s of %s '%s' (%d of %d)", s.cfg.TrackRanges,
+		collection.category.ToLowerCase(), collection.title, selectedCount, len(metadata.trackIDs))
+
+	return true
+}
+
+// isInTrackRanges reports whether the track position is in any of the ranges.
+func isInTrackRanges(ranges []config.TrackRange, position int64) bool {
+	for _, trackRange := range ranges {
+		if trackRange.Contains(position) {
+			return true
+		}
+	}
+
+	return false
+}
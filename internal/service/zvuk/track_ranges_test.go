@@ -0,0 +1,81 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestSelectTrackRanges tests that only the tracks at the positions given in --tracks are downloaded.
+func TestSelectTrackRanges(t *testing.T) {
+	t.Parallel()
+
+	newService := func(t *testing.T, trackRanges string) *ServiceImpl {
+		t.Helper()
+
+		ranges, err := config.ParseTrackRanges(trackRanges)
+		require.NoError(t, err)
+
+		impl, ok := NewService(&config.Config{TrackRanges: trackRanges, ParsedTrackRanges: ranges},
+			nil, nil, nil, nil).(*ServiceImpl)
+		require.True(t, ok, "service must be of type *ServiceImpl")
+
+		return impl
+	}
+
+	newMetadata := func(collection *audioCollection) *downloadTracksMetadata {
+		return &downloadTracksMetadata{
+			audioCollection: collection,
+			category:        DownloadCategoryAlbum,
+			trackIDs:        []int64{10, 20, 30, 40, 50},
+		}
+	}
+
+	t.Run("positions outside the ranges are left out", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := newMetadata(&audioCollection{category: DownloadCategoryAlbum, title: "Mutter"})
+
+		require.True(t, newService(t, "1,4-").selectTrackRanges(context.Background(), metadata))
+		assert.Equal(t, map[int]struct{}{1: {}, 2: {}}, metadata.deselectedTracks)
+	})
+
+	t.Run("collection without tracks in the ranges is skipped", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := newMetadata(&audioCollection{category: DownloadCategoryAlbum, title: "Mutter"})
+		require.False(t, newService(t, "6-").selectTrackRanges(context.Background(), metadata))
+	})
+
+	t.Run("standalone tracks are not filtered", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := newMetadata(nil)
+
+		require.True(t, newService(t, "1").selectTrackRanges(context.Background(), metadata))
+		assert.Empty(t, metadata.deselectedTracks)
+	})
+
+	t.Run("picker lists only the tracks in the ranges", func(t *testing.T) {
+		t.Parallel()
+
+		impl := newService(t, "2-3")
+
+		var output bytes.Buffer
+
+		impl.trackPicker = newTrackPicker(strings.NewReader("1\n\n"), &output)
+
+		metadata := newMetadata(&audioCollection{category: DownloadCategoryAlbum, title: "Mutter"})
+
+		require.True(t, impl.selectTrackRanges(context.Background(), metadata))
+		require.True(t, impl.pickTracks(context.Background(), metadata))
+		assert.Contains(t, output.String(), "Album 'Mutter': 2 of 2 tracks selected\n")
+		assert.Equal(t, map[int]struct{}{0: {}, 1: {}, 3: {}, 4: {}}, metadata.deselectedTracks)
+	})
+}
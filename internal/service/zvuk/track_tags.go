@@ -20,6 +20,10 @@ type trackTagContext struct {
 	audioCollection *audioCollection
 	albumTags       map[string]string
 	category        DownloadCategory
+	// artistJoinStyle defines how the track artists are combined into the trackArtist tag.
+	artistJoinStyle string
+	// trackNumberWidth is the width the track number is padded to (0 keeps trackNumberPaddingWidth).
+	trackNumberWidth int
 }
 
 func setIfNotBlank(tags map[string]string, key, value string) {
@@ -93,15 +97,41 @@ func buildDefaultTrackTags(ctx *trackTagContext) map[string]string {
 	maps.Copy(result, collection.tags)
 
 	result[TagCollectionTitle] = collection.title
-	result[TagTrackArtist] = strings.Join(track.ArtistNames, ", ")
+	result[TagTrackArtist] = formatDisplayArtist(track.ArtistNames, ctx.artistJoinStyle)
 	setIfNotBlank(result, TagTrackGenre, strings.Join(track.Genres, ", "))
 
 	result[TagTrackID] = strconv.FormatInt(track.ID, 10)
 	result[TagTrackNumber] = strconv.FormatInt(ctx.trackNumber, 10)
-	result[TagTrackNumberPad] = fmt.Sprintf("%0*d", trackNumberPaddingWidth, ctx.trackNumber)
+	result[TagTrackNumberPad] = fmt.Sprintf("%0*d", max(trackNumberPaddingWidth, ctx.trackNumberWidth), ctx.trackNumber)
 	result[TagTrackTitle] = track.Title
 	result[TagTrackCount] = strconv.FormatInt(collection.tracksCount, 10)
 
+	if collection.category == DownloadCategoryPlaylist {
+		position, ok := collection.playlistPositions[track.ID]
+		if !ok {
+			position = ctx.trackNumber
+		}
+
+		width := max(trackNumberPaddingWidth, len(strconv.FormatInt(collection.tracksCount, 10)))
+
+		result[TagPlaylistPosition] = strconv.FormatInt(position, 10)
+		result[TagPlaylistPositionPad] = fmt.Sprintf("%0*d", width, position)
+	}
+
+	return result
+}
+
+// newPlaylistPositions maps the track IDs to their 1-based positions in the playlist.
+// A track added to the playlist more than once keeps its first position.
+func newPlaylistPositions(trackIDs []int64) map[int64]int64 {
+	result := make(map[int64]int64, len(trackIDs))
+
+	for index, trackID := range trackIDs {
+		if _, ok := result[trackID]; !ok {
+			result[trackID] = int64(index) + 1
+		}
+	}
+
 	return result
 }
 
@@ -3,6 +3,7 @@ package zvuk
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
@@ -10,66 +11,116 @@ import (
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 )
 
+// Names of the standard validation rules, reported in the per-rule statistics.
+const (
+	// ValidationRuleMinDuration is the rule skipping tracks shorter than min_duration.
+	ValidationRuleMinDuration = "minimum duration"
+	// ValidationRuleMaxDuration is the rule skipping tracks longer than max_duration.
+	ValidationRuleMaxDuration = "maximum duration"
+	// ValidationRuleMinQuality is the rule skipping tracks available below min_quality.
+	ValidationRuleMinQuality = "minimum quality"
+	// ValidationRuleExcludePatterns is the rule skipping tracks matching exclude_patterns.
+	ValidationRuleExcludePatterns = "exclude patterns"
+	// ValidationRuleBlocklist is the rule skipping tracks on the blocklist.
+	ValidationRuleBlocklist = "blocklist"
+)
+
 // ValidationResult contains the result of track validation.
 type ValidationResult struct {
 	// IsValid indicates if the track passed all validation rules.
 	IsValid bool
+	// Rule is the name of the rule the track failed (if IsValid is false).
+	Rule string
 	// SkipReason indicates why the track should be skipped (if IsValid is false).
 	SkipReason SkipReason
 	// Error contains the validation error (if IsValid is false).
 	Error error
+	// Threshold is the configured limit the track failed to meet (if IsValid is false).
+	Threshold string
+	// Phase is the phase reported for the skipped track (if IsValid is false).
+	Phase string
+}
+
+// TrackCandidate is a track checked by the validator.
+type TrackCandidate struct {
+	// Track is the metadata of the track.
+	Track *zvuk.Track
+	// Quality is the quality the track would be downloaded in.
+	// It is TrackQualityUnknown until the stream is resolved, and the rules needing it pass.
+	Quality TrackQuality
 }
 
 // ValidationRule defines a single validation check for tracks.
 type ValidationRule struct {
-	// Name is a human-readable name for the rule.
+	// Name is a human-readable name for the rule, reported in the per-rule statistics.
 	Name string
-	// Check performs the validation and returns true if valid.
-	Check func(context.Context, *zvuk.Track, *config.Config) bool
+	// Check returns nil if the track passes the rule, or the error explaining why it is skipped.
+	Check func(ctx context.Context, candidate *TrackCandidate) error
 	// SkipReason is the reason to use if validation fails.
 	SkipReason SkipReason
-	// ErrorFunc generates an error message for failed validation.
-	ErrorFunc func(*zvuk.Track, *config.Config) error
+	// Threshold is the configured limit checked by the rule, for reporting.
+	Threshold string
+	// Phase is the phase reported for the tracks failing the rule.
+	Phase string
 }
 
-// TrackValidator validates tracks against configured constraints.
+// TrackValidator validates tracks against a chain of rules, stopping at the first one that fails.
 type TrackValidator struct {
-	cfg   *config.Config
 	rules []*ValidationRule
 }
 
-// NewTrackValidator creates a validator with standard validation rules.
+// NewTrackValidator creates a validator with the standard rules enabled by the configuration:
+// duration, quality, exclude patterns, and blocklist. More rules can be added with AddRule.
 func NewTrackValidator(cfg *config.Config) *TrackValidator {
-	return &TrackValidator{
-		cfg: cfg,
-		rules: []*ValidationRule{
-			{
-				Name:       "minimum duration",
-				Check:      checkMinDuration,
-				SkipReason: SkipReasonDuration,
-				ErrorFunc:  errMinDuration,
-			},
-			{
-				Name:       "maximum duration",
-				Check:      checkMaxDuration,
-				SkipReason: SkipReasonDuration,
-				ErrorFunc:  errMaxDuration,
-			},
-		},
+	v := &TrackValidator{}
+
+	for _, rule := range []*ValidationRule{
+		newMinDurationRule(cfg),
+		newMaxDurationRule(cfg),
+		newMinQualityRule(cfg),
+		newExcludePatternsRule(cfg),
+		newBlocklistRule(cfg),
+	} {
+		if rule != nil {
+			v.AddRule(rule)
+		}
 	}
+
+	return v
+}
+
+// AddRule appends a rule to the end of the chain.
+func (v *TrackValidator) AddRule(rule *ValidationRule) {
+	v.rules = append(v.rules, rule)
 }
 
-// Validate runs all validation rules against a track.
-func (v *TrackValidator) Validate(ctx context.Context, track *zvuk.Track) *ValidationResult {
+// Rules returns the names of the rules in the chain, in the order they run.
+func (v *TrackValidator) Rules() []string {
+	names := make([]string, 0, len(v.rules))
 	for _, rule := range v.rules {
-		if !rule.Check(ctx, track, v.cfg) {
-			logger.Warnf(ctx, "Track validation failed: %s", rule.Name)
+		names = append(names, rule.Name)
+	}
 
-			return &ValidationResult{
-				IsValid:    false,
-				SkipReason: rule.SkipReason,
-				Error:      rule.ErrorFunc(track, v.cfg),
-			}
+	return names
+}
+
+// Validate runs the rules against a track until one of them fails.
+func (v *TrackValidator) Validate(ctx context.Context, candidate *TrackCandidate) *ValidationResult {
+	for _, rule := range v.rules {
+		err := rule.Check(ctx, candidate)
+		if err == nil {
+			continue
+		}
+
+		logger.Warnf(ctx, "Track validation failed: %s", rule.Name)
+
+		return &ValidationResult{
+			IsValid:    false,
+			Rule:       rule.Name,
+			SkipReason: rule.SkipReason,
+			Error:      err,
+			Threshold:  rule.Threshold,
+			Phase:      rule.Phase,
 		}
 	}
 
@@ -78,48 +129,143 @@ func (v *TrackValidator) Validate(ctx context.Context, track *zvuk.Track) *Valid
 	}
 }
 
-// checkMinDuration validates minimum duration constraint.
-func checkMinDuration(ctx context.Context, track *zvuk.Track, cfg *config.Config) bool {
+// newMinDurationRule creates the rule skipping tracks shorter than min_duration (nil if it is not set).
+func newMinDurationRule(cfg *config.Config) *ValidationRule {
 	if cfg.ParsedMinDuration <= 0 {
-		return true // No constraint.
+		return nil
 	}
 
-	trackDuration := time.Duration(track.Duration) * time.Second
-	if trackDuration < cfg.ParsedMinDuration {
-		logger.Warnf(ctx, "Track duration %ds is below minimum threshold %s, skipping",
-			track.Duration, cfg.ParsedMinDuration)
+	return &ValidationRule{
+		Name: ValidationRuleMinDuration,
+		Check: func(ctx context.Context, candidate *TrackCandidate) error {
+			if time.Duration(candidate.Track.Duration)*time.Second >= cfg.ParsedMinDuration {
+				return nil
+			}
 
-		return false
-	}
+			logger.Warnf(ctx, "Track duration %ds is below minimum threshold %s, skipping",
+				candidate.Track.Duration, cfg.ParsedMinDuration)
 
-	return true
+			return fmt.Errorf("%w: %ds below %s",
+				ErrDurationBelowThreshold, candidate.Track.Duration, cfg.ParsedMinDuration)
+		},
+		SkipReason: SkipReasonDuration,
+		Threshold:  "min " + cfg.ParsedMinDuration.String(),
+		Phase:      "duration check",
+	}
 }
 
-// checkMaxDuration validates maximum duration constraint.
-func checkMaxDuration(ctx context.Context, track *zvuk.Track, cfg *config.Config) bool {
+// newMaxDurationRule creates the rule skipping tracks longer than max_duration (nil if it is not set).
+func newMaxDurationRule(cfg *config.Config) *ValidationRule {
 	if cfg.ParsedMaxDuration <= 0 {
-		return true // No constraint.
+		return nil
+	}
+
+	return &ValidationRule{
+		Name: ValidationRuleMaxDuration,
+		Check: func(ctx context.Context, candidate *TrackCandidate) error {
+			if time.Duration(candidate.Track.Duration)*time.Second <= cfg.ParsedMaxDuration {
+				return nil
+			}
+
+			logger.Warnf(ctx, "Track duration %ds exceeds maximum threshold %s, skipping",
+				candidate.Track.Duration, cfg.ParsedMaxDuration)
+
+			return fmt.Errorf("%w: %ds exceeds %s",
+				ErrDurationAboveThreshold, candidate.Track.Duration, cfg.ParsedMaxDuration)
+		},
+		SkipReason: SkipReasonDuration,
+		Threshold:  "max " + cfg.ParsedMaxDuration.String(),
+		Phase:      "duration check",
 	}
+}
+
+// newMinQualityRule creates the rule skipping tracks whose resolved quality is below min_quality
+// (nil if it is not set). The quality is known once the stream is resolved, so the rule passes before that.
+func newMinQualityRule(cfg *config.Config) *ValidationRule {
+	if cfg.MinQuality == 0 {
+		return nil
+	}
+
+	minQuality := TrackQuality(cfg.MinQuality)
 
-	trackDuration := time.Duration(track.Duration) * time.Second
-	if trackDuration > cfg.ParsedMaxDuration {
-		logger.Warnf(ctx, "Track duration %ds exceeds maximum threshold %s, skipping",
-			track.Duration, cfg.ParsedMaxDuration)
+	return &ValidationRule{
+		Name: ValidationRuleMinQuality,
+		Check: func(_ context.Context, candidate *TrackCandidate) error {
+			if candidate.Quality == TrackQualityUnknown || candidate.Quality >= minQuality {
+				return nil
+			}
+
+			return fmt.Errorf("%w: %s below %s", ErrQualityBelowThreshold, candidate.Quality, minQuality)
+		},
+		SkipReason: SkipReasonQuality,
+		Threshold:  minQuality.String(),
+		Phase:      "quality check",
+	}
+}
 
-		return false
+// newExcludePatternsRule creates the rule skipping tracks whose title or artists match an exclude pattern
+// (nil if there are no patterns).
+func newExcludePatternsRule(cfg *config.Config) *ValidationRule {
+	if len(cfg.ParsedExcludePatterns) == 0 {
+		return nil
 	}
 
-	return true
+	return &ValidationRule{
+		Name: ValidationRuleExcludePatterns,
+		Check: func(ctx context.Context, candidate *TrackCandidate) error {
+			pattern := matchingExcludePattern(candidate.Track, cfg.ParsedExcludePatterns)
+			if pattern == nil {
+				return nil
+			}
+
+			logger.Warnf(ctx, "Track '%s' matches exclude pattern '%s', skipping", candidate.Track.Title, pattern)
+
+			return fmt.Errorf("%w '%s'", ErrExcludedByPattern, pattern)
+		},
+		SkipReason: SkipReasonExcluded,
+		Phase:      "exclusion check",
+	}
 }
 
-// errMinDuration generates error for minimum duration violation.
-func errMinDuration(track *zvuk.Track, cfg *config.Config) error {
-	return fmt.Errorf("%w: %ds below %s",
-		ErrDurationBelowThreshold, track.Duration, cfg.ParsedMinDuration)
+// newBlocklistRule creates the rule skipping tracks whose ID, release, or artist is on the blocklist
+// (nil if the blocklist is empty).
+func newBlocklistRule(cfg *config.Config) *ValidationRule {
+	if cfg.ParsedBlocklist == nil {
+		return nil
+	}
+
+	return &ValidationRule{
+		Name: ValidationRuleBlocklist,
+		Check: func(ctx context.Context, candidate *TrackCandidate) error {
+			track := candidate.Track
+
+			entry := cfg.ParsedBlocklist.Match(track.ID, track.ReleaseID, track.ArtistNames)
+			if entry == "" {
+				return nil
+			}
+
+			logger.Warnf(ctx, "Track '%s' is blocked by blocklist entry '%s', skipping", track.Title, entry)
+
+			return fmt.Errorf("%w '%s'", ErrBlocklisted, entry)
+		},
+		SkipReason: SkipReasonBlocked,
+		Phase:      "blocklist check",
+	}
 }
 
-// errMaxDuration generates error for maximum duration violation.
-func errMaxDuration(track *zvuk.Track, cfg *config.Config) error {
-	return fmt.Errorf("%w: %ds exceeds %s",
-		ErrDurationAboveThreshold, track.Duration, cfg.ParsedMaxDuration)
+// matchingExcludePattern returns the first exclude pattern matching the title or an artist of the track.
+func matchingExcludePattern(track *zvuk.Track, patterns []*regexp.Regexp) *regexp.Regexp {
+	for _, pattern := range patterns {
+		if pattern.MatchString(track.Title) {
+			return pattern
+		}
+
+		for _, artist := range track.ArtistNames {
+			if pattern.MatchString(artist) {
+				return pattern
+			}
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,90 @@
+package zvuk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestTrackValidator tests the rule chain built from the configuration and extended with custom rules.
+func TestTrackValidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled rules are left out", func(t *testing.T) {
+		t.Parallel()
+
+		validator := NewTrackValidator(&config.Config{})
+		assert.Empty(t, validator.Rules())
+		assert.True(t, validator.Validate(context.Background(), &TrackCandidate{Track: &zvuk.Track{}}).IsValid)
+	})
+
+	t.Run("the first failing rule is reported", func(t *testing.T) {
+		t.Parallel()
+
+		validator := NewTrackValidator(&config.Config{
+			ParsedMinDuration: time.Minute,
+			ParsedMaxDuration: 10 * time.Minute,
+			MinQuality:        uint8(TrackQualityFLAC),
+		})
+		require.Equal(t,
+			[]string{ValidationRuleMinDuration, ValidationRuleMaxDuration, ValidationRuleMinQuality},
+			validator.Rules())
+
+		result := validator.Validate(context.Background(), &TrackCandidate{
+			Track:   &zvuk.Track{Duration: 30},
+			Quality: TrackQualityMP3Mid,
+		})
+		assert.False(t, result.IsValid)
+		assert.Equal(t, ValidationRuleMinDuration, result.Rule)
+		assert.Equal(t, "min 1m0s", result.Threshold)
+		assert.ErrorIs(t, result.Error, ErrDurationBelowThreshold)
+	})
+
+	t.Run("the quality rule waits for the stream", func(t *testing.T) {
+		t.Parallel()
+
+		validator := NewTrackValidator(&config.Config{MinQuality: uint8(TrackQualityFLAC)})
+		track := &zvuk.Track{Duration: 200}
+
+		assert.True(t, validator.Validate(context.Background(), &TrackCandidate{Track: track}).IsValid)
+
+		result := validator.Validate(context.Background(), &TrackCandidate{Track: track, Quality: TrackQualityMP3High})
+		assert.Equal(t, ValidationRuleMinQuality, result.Rule)
+		assert.Equal(t, SkipReasonQuality, result.SkipReason)
+		assert.ErrorIs(t, result.Error, ErrQualityBelowThreshold)
+	})
+
+	t.Run("custom rules run after the standard ones", func(t *testing.T) {
+		t.Parallel()
+
+		errLive := errors.New("live recording")
+
+		validator := NewTrackValidator(&config.Config{})
+		validator.AddRule(&ValidationRule{
+			Name: "live recordings",
+			Check: func(_ context.Context, candidate *TrackCandidate) error {
+				if candidate.Track.Title == "Sonne (Live)" {
+					return errLive
+				}
+
+				return nil
+			},
+			SkipReason: SkipReasonExcluded,
+			Phase:      "live check",
+		})
+
+		result := validator.Validate(context.Background(), &TrackCandidate{Track: &zvuk.Track{Title: "Sonne (Live)"}})
+		assert.Equal(t, "live recordings", result.Rule)
+		assert.Equal(t, "live check", result.Phase)
+		assert.ErrorIs(t, result.Error, errLive)
+
+		assert.True(t, validator.Validate(context.Background(), &TrackCandidate{Track: &zvuk.Track{Title: "Sonne"}}).IsValid)
+	})
+}
@@ -0,0 +1,346 @@
+package zvuk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+	"github.com/oshokin/zvuk-grabber/internal/utils"
+)
+
+// UpgradeWatchEntry is a track saved below FLAC that is re-checked for a quality upgrade.
+type UpgradeWatchEntry struct {
+	// TrackID is the unique identifier of the track.
+	TrackID string `json:"track_id"`
+	// Title is the human-readable title of the track.
+	Title string `json:"title"`
+	// Path is where the lower-quality file was saved.
+	Path string `json:"path"`
+	// Quality is the quality the track was saved in.
+	Quality string `json:"quality"`
+	// AddedAt is when the track was added to the watch list.
+	AddedAt time.Time `json:"added_at"`
+	// CheckedAt is when the track was last checked for FLAC availability.
+	CheckedAt time.Time `json:"checked_at,omitzero"`
+}
+
+// upgradeWatchList keeps the tracks saved below FLAC in a JSON file between runs.
+type upgradeWatchList struct {
+	// mutex protects the fields below.
+	mutex sync.Mutex
+	// path is the location of the watch list file.
+	path string
+	// isLoaded indicates that the file has been read.
+	isLoaded bool
+	// isChanged indicates that the entries differ from the file.
+	isChanged bool
+	// entries maps a track ID to its watch entry.
+	entries map[string]*UpgradeWatchEntry
+}
+
+// newUpgradeWatchList creates a watch list stored at path.
+func newUpgradeWatchList(path string) *upgradeWatchList {
+	return &upgradeWatchList{
+		path:    path,
+		entries: make(map[string]*UpgradeWatchEntry),
+	}
+}
+
+// load reads the watch list file once. A missing file is an empty list.
+func (w *upgradeWatchList) load() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.isLoaded {
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Clean(w.path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read upgrade watch list: %w", err)
+	}
+
+	if len(content) > 0 {
+		var entries []*UpgradeWatchEntry
+		if err = json.Unmarshal(content, &entries); err != nil {
+			return fmt.Errorf("failed to parse upgrade watch list '%s': %w", w.path, err)
+		}
+
+		for _, entry := range entries {
+			w.entries[entry.TrackID] = entry
+		}
+	}
+
+	w.isLoaded = true
+
+	return nil
+}
+
+// save writes the watch list file if it has changed since it was loaded.
+func (w *upgradeWatchList) save() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.isChanged {
+		return nil
+	}
+
+	entries := make([]*UpgradeWatchEntry, 0, len(w.entries))
+	for _, trackID := range slices.Sorted(maps.Keys(w.entries)) {
+		entries = append(entries, w.entries[trackID])
+	}
+
+	if err := writeJSONAtomically(w.path, entries); err != nil {
+		return fmt.Errorf("failed to write upgrade watch list: %w", err)
+	}
+
+	w.isChanged = false
+
+	return nil
+}
+
+// add puts the track on the watch list, keeping the original date of an already watched track.
+func (w *upgradeWatchList) add(entry *UpgradeWatchEntry) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if previous, ok := w.entries[entry.TrackID]; ok {
+		if previous.Path == entry.Path && previous.Quality == entry.Quality {
+			return
+		}
+
+		entry.AddedAt = previous.AddedAt
+	}
+
+	w.entries[entry.TrackID] = entry
+	w.isChanged = true
+}
+
+// take removes the track from the watch list and returns its entry, or nil if it was not watched.
+func (w *upgradeWatchList) take(trackID string) *UpgradeWatchEntry {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	entry, ok := w.entries[trackID]
+	if !ok {
+		return nil
+	}
+
+	delete(w.entries, trackID)
+	w.isChanged = true
+
+	return entry
+}
+
+// watchedPath returns the file of a watched track.
+func (w *upgradeWatchList) watchedPath(trackID string) (string, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	entry, ok := w.entries[trackID]
+	if !ok || entry.Path == "" {
+		return "", false
+	}
+
+	return entry.Path, true
+}
+
+// trackIDs returns the IDs of the watched tracks in ascending order.
+func (w *upgradeWatchList) trackIDs() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return slices.Sorted(maps.Keys(w.entries))
+}
+
+// markChecked records the time the tracks were checked for FLAC availability.
+func (w *upgradeWatchList) markChecked(trackIDs []string, checkedAt time.Time) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, trackID := range trackIDs {
+		if entry, ok := w.entries[trackID]; ok {
+			entry.CheckedAt = checkedAt
+			w.isChanged = true
+		}
+	}
+}
+
+// UpgradeWatchedTracks checks the tracks on the upgrade watch list and downloads
+// the ones that have become available in FLAC. Every FLAC version is saved next to the file it replaces,
+// so tracks saved from playlists and other collections stay in their folders. The lower-quality files
+// are deleted or moved to upgrade_quarantine_path once their FLAC versions are saved.
+func (s *ServiceImpl) UpgradeWatchedTracks(ctx context.Context) {
+	if s.upgradeWatch == nil {
+		logger.Error(ctx, "Upgrade watch list is disabled: set upgrade_watch_path in the configuration")
+
+		return
+	}
+
+	if err := s.upgradeWatch.load(); err != nil {
+		logger.Errorf(ctx, "Failed to load upgrade watch list: %v", err)
+
+		return
+	}
+
+	trackIDs := s.upgradeWatch.trackIDs()
+	if len(trackIDs) == 0 {
+		logger.Info(ctx, "No tracks are watched for quality upgrades")
+
+		return
+	}
+
+	logger.Infof(ctx, "Checking %d watched track(s) for FLAC availability", len(trackIDs))
+
+	tracks, err := s.zvukClient.GetTracksMetadata(ctx, trackIDs)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to fetch metadata of watched tracks: %v", err)
+
+		return
+	}
+
+	s.upgradeWatch.markChecked(trackIDs, time.Now())
+
+	upgradableIDs := make([]string, 0, len(trackIDs))
+
+	for _, trackID := range trackIDs {
+		track := tracks[trackID]
+		if track != nil && (track.HasFLAC || ParseQuality(track.HighestQuality) == TrackQualityFLAC) {
+			upgradableIDs = append(upgradableIDs, DownloadCategoryTrack.String()+":"+trackID)
+		}
+	}
+
+	logger.Infof(ctx, "%d of %d watched track(s) are available in FLAC", len(upgradableIDs), len(trackIDs))
+
+	if len(upgradableIDs) == 0 {
+		s.saveUpgradeWatch(ctx)
+
+		return
+	}
+
+	s.isUpgradingWatchedTracks = true
+
+	s.DownloadURLs(ctx, upgradableIDs)
+}
+
+// upgradeInPlacePath returns where a watched track re-downloaded by UpgradeWatchedTracks is saved:
+// the file it replaces with the extension of the new quality.
+func (s *ServiceImpl) upgradeInPlacePath(task *downloadTrackTask) (string, bool) {
+	if !s.isUpgradingWatchedTracks || s.upgradeWatch == nil || task.metadata.category != DownloadCategoryTrack {
+		return "", false
+	}
+
+	watchedPath, ok := s.upgradeWatch.watchedPath(task.trackIDString)
+	if !ok {
+		return "", false
+	}
+
+	return utils.SetFileExtension(watchedPath, task.quality.Extension(), true), true
+}
+
+// watchTrackUpgrade keeps the upgrade watch list up to date with a saved track.
+// Tracks saved below FLAC are added to the list; a watched track saved in FLAC
+// is removed from it and its lower-quality file is retired.
+func (s *ServiceImpl) watchTrackUpgrade(ctx context.Context, t *downloadTrackTask) {
+	if s.upgradeWatch == nil || s.cfg.DryRun {
+		return
+	}
+
+	category := t.metadata.category
+	if category == DownloadCategoryAudiobook || category == DownloadCategoryPodcast {
+		return
+	}
+
+	if t.quality < TrackQualityFLAC {
+		entry := &UpgradeWatchEntry{
+			TrackID: t.trackIDString,
+			Path:    t.trackPath,
+			Quality: t.quality.String(),
+			AddedAt: time.Now(),
+		}
+
+		if t.track != nil {
+			entry.Title = t.track.Title
+		}
+
+		s.upgradeWatch.add(entry)
+
+		return
+	}
+
+	previous := s.upgradeWatch.take(t.trackIDString)
+	if previous == nil || previous.Path == t.trackPath {
+		return
+	}
+
+	logger.Infof(ctx, "Track '%s' upgraded from %s to FLAC", t.trackPath, previous.Quality)
+	s.incrementTrackUpgraded()
+	s.retireDowngradedTrack(ctx, previous.Path)
+}
+
+// retireDowngradedTrack deletes the lower-quality file of an upgraded track,
+// or moves it to upgrade_quarantine_path mirroring output_path.
+func (s *ServiceImpl) retireDowngradedTrack(ctx context.Context, trackPath string) {
+	if _, err := os.Stat(trackPath); err != nil {
+		return
+	}
+
+	if s.cfg.UpgradeQuarantinePath == "" {
+		if err := os.Remove(trackPath); err != nil {
+			logger.Warnf(ctx, "Failed to delete replaced track '%s': %v", trackPath, err)
+		}
+
+		return
+	}
+
+	relativePath, err := filepath.Rel(s.cfg.OutputPath, trackPath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		relativePath = filepath.Base(trackPath)
+	}
+
+	quarantinePath := filepath.Join(s.cfg.UpgradeQuarantinePath, relativePath)
+
+	if err = os.MkdirAll(filepath.Dir(quarantinePath), constants.DefaultFolderPermissions); err != nil {
+		logger.Warnf(ctx, "Failed to create quarantine folder for '%s': %v", trackPath, err)
+
+		return
+	}
+
+	if err = utils.RenameFile(trackPath, quarantinePath, true); err != nil {
+		logger.Warnf(ctx, "Failed to move replaced track '%s' to quarantine: %v", trackPath, err)
+
+		return
+	}
+
+	logger.Infof(ctx, "Replaced track moved to '%s'", quarantinePath)
+}
+
+// loadUpgradeWatch reads the upgrade watch list, if it is enabled.
+func (s *ServiceImpl) loadUpgradeWatch() error {
+	if s.upgradeWatch == nil {
+		return nil
+	}
+
+	return s.upgradeWatch.load()
+}
+
+// saveUpgradeWatch writes the upgrade watch list, if it is enabled.
+func (s *ServiceImpl) saveUpgradeWatch(ctx context.Context) {
+	if s.upgradeWatch == nil {
+		return
+	}
+
+	if err := s.upgradeWatch.save(); err != nil {
+		logger.Errorf(ctx, "Failed to save upgrade watch list: %v", err)
+	}
+}
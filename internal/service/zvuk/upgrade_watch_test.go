@@ -0,0 +1,177 @@
+package zvuk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// TestWatchTrackUpgrade verifies that a track saved in MP3 is watched and persisted,
+// and that saving it in FLAC later moves the MP3 file to quarantine.
+func TestWatchTrackUpgrade(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tempDir        = t.TempDir()
+		outputPath     = filepath.Join(tempDir, "music")
+		quarantinePath = filepath.Join(tempDir, "quarantine")
+		watchPath      = filepath.Join(tempDir, "watch.json")
+		mp3Path        = filepath.Join(outputPath, "Album", "01 - Track.mp3")
+		flacPath       = filepath.Join(outputPath, "Album", "01 - Track.flac")
+		cfg            = &config.Config{
+			OutputPath:            outputPath,
+			UpgradeWatchPath:      watchPath,
+			UpgradeQuarantinePath: quarantinePath,
+		}
+		metadata = &downloadTracksMetadata{category: DownloadCategoryAlbum}
+		ctx      = context.Background()
+	)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(mp3Path), constants.DefaultFolderPermissions))
+	require.NoError(t, os.WriteFile(mp3Path, []byte("mp3"), constants.DefaultFilePermissions))
+
+	impl := NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.NoError(t, impl.loadUpgradeWatch())
+
+	impl.watchTrackUpgrade(ctx, &downloadTrackTask{
+		metadata:      metadata,
+		trackIDString: "100",
+		track:         &zvuk.Track{Title: "Track"},
+		trackPath:     mp3Path,
+		quality:       TrackQualityMP3High,
+	})
+	impl.saveUpgradeWatch(ctx)
+
+	// A new run reads the watch list from the file.
+	impl = NewService(cfg, nil, nil, nil, nil).(*ServiceImpl)
+	require.NoError(t, impl.loadUpgradeWatch())
+	require.Equal(t, []string{"100"}, impl.upgradeWatch.trackIDs())
+
+	impl.watchTrackUpgrade(ctx, &downloadTrackTask{
+		metadata:      metadata,
+		trackIDString: "100",
+		track:         &zvuk.Track{Title: "Track"},
+		trackPath:     flacPath,
+		quality:       TrackQualityFLAC,
+	})
+	impl.saveUpgradeWatch(ctx)
+
+	assert.Empty(t, impl.upgradeWatch.trackIDs())
+	assert.NoFileExists(t, mp3Path)
+	assert.FileExists(t, filepath.Join(quarantinePath, "Album", "01 - Track.mp3"))
+	assert.Equal(t, int64(1), impl.Statistics().TracksUpgraded)
+
+	content, err := os.ReadFile(watchPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, "[]", string(content))
+}
+
+// TestUpgradeInPlacePath verifies that a watched track re-downloaded by the upgrade run
+// replaces its file in the folder it was saved to, and that other runs keep their own paths.
+func TestUpgradeInPlacePath(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tempDir   = t.TempDir()
+		watchPath = filepath.Join(tempDir, "watch.json")
+		mp3Path   = filepath.Join(tempDir, "music", "Playlist", "03 - Track.mp3")
+	)
+
+	impl := NewService(&config.Config{OutputPath: tempDir, UpgradeWatchPath: watchPath},
+		nil, nil, nil, nil).(*ServiceImpl)
+	require.NoError(t, impl.loadUpgradeWatch())
+
+	impl.upgradeWatch.add(&UpgradeWatchEntry{TrackID: "100", Path: mp3Path, Quality: "high"})
+
+	newTask := func(category DownloadCategory, trackID string) *downloadTrackTask {
+		return &downloadTrackTask{
+			metadata:      &downloadTracksMetadata{category: category},
+			trackIDString: trackID,
+			quality:       TrackQualityFLAC,
+		}
+	}
+
+	_, ok := impl.upgradeInPlacePath(newTask(DownloadCategoryTrack, "100"))
+	assert.False(t, ok, "only the upgrade run replaces files in place")
+
+	impl.isUpgradingWatchedTracks = true
+
+	upgradePath, ok := impl.upgradeInPlacePath(newTask(DownloadCategoryTrack, "100"))
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(tempDir, "music", "Playlist", "03 - Track.flac"), upgradePath)
+
+	_, ok = impl.upgradeInPlacePath(newTask(DownloadCategoryTrack, "200"))
+	assert.False(t, ok, "tracks that are not watched keep their paths")
+
+	_, ok = impl.upgradeInPlacePath(newTask(DownloadCategoryAlbum, "100"))
+	assert.False(t, ok, "tracks of collections keep their paths")
+}
+
+// recordingURLProcessor remembers the URLs passed to the download pipeline.
+type recordingURLProcessor struct {
+	mockURLProcessor
+
+	urls []string
+}
+
+// ExtractDownloadItems records the URLs and returns an empty response.
+func (p *recordingURLProcessor) ExtractDownloadItems(
+	_ context.Context,
+	urls []string,
+) (*ExtractDownloadItemsResponse, error) {
+	p.urls = append(p.urls, urls...)
+
+	return new(ExtractDownloadItemsResponse), nil
+}
+
+// TestUpgradeWatchedTracks_DownloadsOnlyFLACTracks verifies that only watched tracks
+// now available in FLAC are downloaded again.
+func TestUpgradeWatchedTracks_DownloadsOnlyFLACTracks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchPath := filepath.Join(t.TempDir(), "watch.json")
+	require.NoError(t, os.WriteFile(watchPath, []byte(`[
+		{"track_id": "1", "path": "a.mp3", "quality": "high"},
+		{"track_id": "2", "path": "b.mp3", "quality": "high"}
+	]`), constants.DefaultFilePermissions))
+
+	mockClient := mock_zvuk_client.NewMockClient(ctrl)
+	mockClient.EXPECT().GetUserProfile(gomock.Any()).Return(&zvuk.UserProfile{
+		Subscription: &zvuk.UserSubscription{Title: "Premium"},
+	}, nil).AnyTimes()
+	mockClient.EXPECT().
+		GetTracksMetadata(gomock.Any(), []string{"1", "2"}).
+		Return(map[string]*zvuk.Track{
+			"1": {ID: 1, HighestQuality: TrackQualityFLACString, HasFLAC: true},
+			"2": {ID: 2, HighestQuality: TrackQualityMP3HighString},
+		}, nil)
+
+	urlProcessor := new(recordingURLProcessor)
+	service := NewService(&config.Config{
+		OutputPath:       t.TempDir(),
+		Quality:          3,
+		UpgradeWatchPath: watchPath,
+	}, mockClient, urlProcessor, new(mockTemplateManager), new(mockTagProcessor))
+
+	service.UpgradeWatchedTracks(context.Background())
+
+	assert.Equal(t, []string{"track:1"}, urlProcessor.urls)
+
+	// Both tracks stay watched until their FLAC versions are saved, with the check time recorded.
+	content, err := os.ReadFile(watchPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"checked_at"`)
+}
@@ -3,14 +3,24 @@ package zvuk
 //go:generate $MOCKGEN -source=url_processor.go -destination=mocks/url_processor_mock.go
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/oshokin/zvuk-grabber/internal/config"
 	"github.com/oshokin/zvuk-grabber/internal/logger"
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 )
 
+// urlListCommentPrefix starts a comment line in URL lists.
+const urlListCommentPrefix = "#"
+
 // URLProcessor defines the interface for processing URLs and extracting downloadable items.
 type URLProcessor interface {
 	// ExtractDownloadItems processes a list of URLs and categorizes them into tracks, standalone items, and artists.
@@ -50,6 +60,36 @@ var categoriesByPatterns = []struct {
 	{regexp.MustCompile(`/podcast/(?<ID>\d+)$`), DownloadCategoryPodcast},
 }
 
+// wavePattern matches the URLs of the personal wave ("/wave") and of radio stations ("/wave/<ID>", "/radio/<ID>").
+//
+//nolint:gochecknoglobals // This is immutable, pre-compiled regex pattern and used as a constant.
+var wavePattern = regexp.MustCompile(`/(?:wave|radio)(?:/(?<ID>[\w-]+))?$`)
+
+// identifierPattern matches bare identifiers like "track:123" or "album:456" and plain numeric track IDs.
+//
+//nolint:gochecknoglobals // This is immutable, pre-compiled regex pattern and used as a constant.
+var identifierPattern = regexp.MustCompile(`^(?:(?<Kind>[A-Za-z]+):)?(?<ID>\d+)$`)
+
+// identifierKinds maps identifier prefixes to their category and the URL path segment of the item.
+//
+//nolint:gochecknoglobals // This is a static lookup table.
+var identifierKinds = map[string]struct {
+	// category is the download category of the identifier.
+	category DownloadCategory
+	// pathSegment is used to build the canonical URL of the item.
+	pathSegment string
+}{
+	"":          {category: DownloadCategoryTrack, pathSegment: "track"},
+	"track":     {category: DownloadCategoryTrack, pathSegment: "track"},
+	"album":     {category: DownloadCategoryAlbum, pathSegment: "release"},
+	"release":   {category: DownloadCategoryAlbum, pathSegment: "release"},
+	"playlist":  {category: DownloadCategoryPlaylist, pathSegment: "playlist"},
+	"artist":    {category: DownloadCategoryArtist, pathSegment: "artist"},
+	"abook":     {category: DownloadCategoryAudiobook, pathSegment: "abook"},
+	"audiobook": {category: DownloadCategoryAudiobook, pathSegment: "abook"},
+	"podcast":   {category: DownloadCategoryPodcast, pathSegment: "podcast"},
+}
+
 // NewURLProcessor creates and returns a new instance of URLProcessorImpl.
 func NewURLProcessor() URLProcessor {
 	return new(URLProcessorImpl)
@@ -61,7 +101,7 @@ func (up *URLProcessorImpl) ExtractDownloadItems(
 	urls []string,
 ) (*ExtractDownloadItemsResponse, error) {
 	// Process and flatten URLs to handle text files containing multiple URLs.
-	urls, err := up.processAndFlattenURLs(urls)
+	urls, err := up.processAndFlattenURLs(ctx, urls)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +167,11 @@ func (up *URLProcessorImpl) DeduplicateDownloadItems(items []*DownloadItem) []*D
 }
 
 func (up *URLProcessorImpl) parseDownloadItem(url string) *DownloadItem {
+	// Bare identifiers are turned into items with the canonical URL used in error reports.
+	if item := up.parseIdentifier(url); item != nil {
+		return item
+	}
+
 	// Match the URL against each pattern to determine its category.
 	for _, p := range categoriesByPatterns {
 		if itemID := utils.ExtractNamedGroup(p.Pattern, "ID", url); itemID != "" {
@@ -134,6 +179,13 @@ func (up *URLProcessorImpl) parseDownloadItem(url string) *DownloadItem {
 		}
 	}
 
+	// A wave is downloaded as a playlist of the tracks it hands out.
+	if match := wavePattern.FindStringSubmatch(url); match != nil {
+		stationID := cmp.Or(match[wavePattern.SubexpIndex("ID")], personalWaveStationID)
+
+		return &DownloadItem{Category: DownloadCategoryPlaylist, URL: url, ItemID: waveItemIDPrefix + stationID}
+	}
+
 	// If no pattern matches, return an item with an unknown category.
 	return &DownloadItem{
 		Category: DownloadCategoryUnknown,
@@ -142,9 +194,31 @@ func (up *URLProcessorImpl) parseDownloadItem(url string) *DownloadItem {
 	}
 }
 
+// parseIdentifier parses identifiers like "track:123" and plain numeric track IDs.
+// It returns nil if the value is not an identifier.
+func (up *URLProcessorImpl) parseIdentifier(value string) *DownloadItem {
+	match := identifierPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil
+	}
+
+	kind, ok := identifierKinds[strings.ToLower(match[identifierPattern.SubexpIndex("Kind")])]
+	if !ok {
+		return nil
+	}
+
+	itemID := match[identifierPattern.SubexpIndex("ID")]
+
+	return &DownloadItem{
+		Category: kind.category,
+		URL:      config.ZvukBaseURL + "/" + kind.pathSegment + "/" + itemID,
+		ItemID:   itemID,
+	}
+}
+
 // processAndFlattenURLs processes and flattens a list of URLs,
 // handling text files containing multiple URLs.
-func (up *URLProcessorImpl) processAndFlattenURLs(urls []string) ([]string, error) {
+func (up *URLProcessorImpl) processAndFlattenURLs(ctx context.Context, urls []string) ([]string, error) {
 	var (
 		// Track processed URLs.
 		processedSet = make(map[string]struct{})
@@ -174,8 +248,8 @@ func (up *URLProcessorImpl) processAndFlattenURLs(urls []string) ([]string, erro
 			continue
 		}
 
-		// Read unique lines from the text file.
-		lines, err := utils.ReadUniqueLinesFromFile(url)
+		// Read the URLs listed in the text file.
+		lines, err := readURLListFile(ctx, url)
 		if err != nil {
 			return nil, err
 		}
@@ -197,3 +271,55 @@ func (up *URLProcessorImpl) processAndFlattenURLs(urls []string) ([]string, erro
 
 	return processedURLs, nil
 }
+
+// IsDownloadItem reports whether the value is a Zvuk URL or an identifier like "track:123".
+func IsDownloadItem(value string) bool {
+	return new(URLProcessorImpl).parseDownloadItem(value).Category != DownloadCategoryUnknown
+}
+
+// ReadURLList reads URLs and identifiers from a list, one per line.
+// Blank lines and lines starting with "#" are skipped. Unrecognized lines are reported
+// with their line number and skipped, so one bad line does not abort the whole batch.
+// The source names the list in the reports.
+func ReadURLList(ctx context.Context, r io.Reader, source string) ([]string, error) {
+	var (
+		lines      []string
+		scanner    = bufio.NewScanner(r)
+		lineNumber int
+	)
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, urlListCommentPrefix) {
+			continue
+		}
+
+		if !IsDownloadItem(line) {
+			logger.Warnf(ctx, "Skipping line %d of '%s': unrecognized URL or identifier '%s'", lineNumber, source, line)
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", source, err)
+	}
+
+	return lines, nil
+}
+
+// readURLListFile reads the URL list stored in a text file.
+func readURLListFile(ctx context.Context, path string) ([]string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close() //nolint:errcheck // The file is only read.
+
+	return ReadURLList(ctx, file, path)
+}
@@ -2,6 +2,9 @@ package zvuk
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,6 +59,66 @@ func TestURLPatterns(t *testing.T) {
 			url:      "https://zvuk.com/podcast/12891594",
 			expected: DownloadCategoryPodcast,
 		},
+		{
+			name:     "personal wave URL",
+			url:      "https://zvuk.com/wave",
+			expected: DownloadCategoryPlaylist,
+		},
+		{
+			name:     "radio station URL",
+			url:      "https://zvuk.com/radio/215",
+			expected: DownloadCategoryPlaylist,
+		},
+		{
+			name:     "track identifier",
+			url:      "track:123",
+			expected: DownloadCategoryTrack,
+		},
+		{
+			name:     "bare numeric track ID",
+			url:      "123",
+			expected: DownloadCategoryTrack,
+		},
+		{
+			name:     "album identifier",
+			url:      "album:678",
+			expected: DownloadCategoryAlbum,
+		},
+		{
+			name:     "release identifier in upper case",
+			url:      "RELEASE:678",
+			expected: DownloadCategoryAlbum,
+		},
+		{
+			name:     "playlist identifier",
+			url:      "playlist:789",
+			expected: DownloadCategoryPlaylist,
+		},
+		{
+			name:     "artist identifier",
+			url:      "artist:101",
+			expected: DownloadCategoryArtist,
+		},
+		{
+			name:     "audiobook identifier",
+			url:      "audiobook:32124448",
+			expected: DownloadCategoryAudiobook,
+		},
+		{
+			name:     "podcast identifier",
+			url:      "podcast:12891594",
+			expected: DownloadCategoryPodcast,
+		},
+		{
+			name:     "unknown identifier kind",
+			url:      "video:123",
+			expected: DownloadCategoryUnknown,
+		},
+		{
+			name:     "identifier without ID",
+			url:      "track:",
+			expected: DownloadCategoryUnknown,
+		},
 		{
 			name:     "URL with trailing slash",
 			url:      "https://zvuk.com/track/123/",
@@ -336,3 +399,56 @@ func TestURLProcessorImpl_ExtractDownloadItems(t *testing.T) {
 		})
 	}
 }
+
+// TestURLProcessorImpl_ParseIdentifier tests that identifiers get the same item ID and URL as their links.
+func TestURLProcessorImpl_ParseIdentifier(t *testing.T) {
+	t.Parallel()
+
+	processor := new(URLProcessorImpl)
+
+	fromIdentifier := processor.parseDownloadItem("album:678")
+	fromURL := processor.parseDownloadItem("https://zvuk.com/release/678")
+
+	assert.Equal(t, fromURL, fromIdentifier)
+}
+
+// TestReadURLList tests that blank lines, comments, and unrecognized lines are skipped.
+func TestReadURLList(t *testing.T) {
+	t.Parallel()
+
+	list := strings.Join([]string{
+		"# Albums to grab",
+		"https://zvuk.com/release/123",
+		"",
+		"   ",
+		"not a url",
+		"playlist:456",
+		"  https://zvuk.com/track/789  ",
+		"https://example.com/nothing",
+	}, "\n")
+
+	lines, err := ReadURLList(context.Background(), strings.NewReader(list), "urls.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://zvuk.com/release/123",
+		"playlist:456",
+		"https://zvuk.com/track/789",
+	}, lines)
+}
+
+// TestURLProcessorImpl_ExtractDownloadItems_TextFile tests that text files are expanded into their URLs.
+func TestURLProcessorImpl_ExtractDownloadItems_TextFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	content := "# Weekend\nhttps://zvuk.com/release/123\nbroken line\nhttps://zvuk.com/release/123\ntrack:456\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	result, err := NewURLProcessor().ExtractDownloadItems(context.Background(), []string{path})
+	require.NoError(t, err)
+
+	require.Len(t, result.StandaloneItems, 1)
+	assert.Equal(t, "123", result.StandaloneItems[0].ItemID)
+	require.Len(t, result.Tracks, 1)
+	assert.Equal(t, "456", result.Tracks[0].ItemID)
+}
@@ -0,0 +1,546 @@
+package zvuk
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+	"github.com/oshokin/id3v2/v2"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// LibraryIssueKind is the kind of problem found in a downloaded audio file.
+type LibraryIssueKind string
+
+// Enum values for LibraryIssueKind.
+const (
+	// LibraryIssueCorrupted indicates that the file cannot be parsed as FLAC or MP3.
+	LibraryIssueCorrupted LibraryIssueKind = "corrupted"
+	// LibraryIssueTruncated indicates that the file holds less audio than the track has.
+	LibraryIssueTruncated LibraryIssueKind = "truncated"
+	// LibraryIssueTagMismatch indicates that the embedded tags differ from the track metadata.
+	LibraryIssueTagMismatch LibraryIssueKind = "tag mismatch"
+)
+
+// LibraryIssue describes a single problem found in a downloaded audio file.
+type LibraryIssue struct {
+	// Path is the location of the audio file.
+	Path string
+	// TrackID is the track ID read from the file tags (empty if unknown).
+	TrackID string
+	// Kind is the kind of the problem.
+	Kind LibraryIssueKind
+	// Detail describes the problem.
+	Detail string
+}
+
+// LibraryReport is the result of verifying a downloaded library.
+type LibraryReport struct {
+	// FilesChecked is the number of audio files inspected.
+	FilesChecked int
+	// IsMetadataChecked indicates that the tags were compared against the track metadata.
+	IsMetadataChecked bool
+	// Issues lists every problem found, ordered by path.
+	Issues []*LibraryIssue
+}
+
+// VerifyLibraryRequest contains parameters for verifying a downloaded library.
+type VerifyLibraryRequest struct {
+	// Dir is the folder to walk.
+	Dir string
+	// Client fetches the track metadata; nil limits the audit to the file structure.
+	Client zvuk.Client
+	// CheckSizes compares the audio size with the size of the stream (two extra requests per file).
+	CheckSizes bool
+}
+
+// libraryFile is the information read from a downloaded audio file.
+type libraryFile struct {
+	// path is the location of the file.
+	path string
+	// quality is the quality the file was saved in.
+	quality TrackQuality
+	// trackID is the track ID from the tags (empty if missing).
+	trackID string
+	// releaseID is the release ID from the tags (empty if missing).
+	releaseID string
+	// playlistID is the ID of the playlist the track was saved with (empty for other tracks or if missing).
+	playlistID string
+	// trackNumber is the track number from the tags (0 if missing).
+	trackNumber int64
+	// title is the track title from the tags.
+	title string
+	// artist is the track artist from the tags.
+	artist string
+	// duration is the playback time in seconds (0 if unknown).
+	duration float64
+	// audioSize is the size of the audio data in bytes, without the tags.
+	audioSize int64
+}
+
+const (
+	// id3v2HeaderSize is the size of an ID3v2 tag header and footer.
+	id3v2HeaderSize = 10
+	// id3v2FooterFlag marks an ID3v2 tag followed by a footer.
+	id3v2FooterFlag = 0x10
+	// mp3FrameHeaderSize is the size of an MPEG audio frame header.
+	mp3FrameHeaderSize = 4
+	// mp3HighBitrate is the bitrate in Kbps of the high MP3 quality.
+	mp3HighBitrate = 320
+	// durationTolerance is the shortfall in seconds tolerated before a file is reported as truncated.
+	durationTolerance = 2
+	// streamMetadataAllowance is the room left for the tags of the stream itself when comparing sizes.
+	streamMetadataAllowance = 512 * 1024
+)
+
+// mp3Bitrates maps an MPEG-1 Layer III bitrate index to the bitrate in Kbps.
+var mp3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+
+// VerifyLibrary walks the folder and reports the audio files that are corrupted, truncated,
+// or tagged differently from the track metadata.
+func VerifyLibrary(ctx context.Context, cfg *config.Config, req *VerifyLibraryRequest) (*LibraryReport, error) {
+	report := &LibraryReport{IsMetadataChecked: req.Client != nil}
+
+	paths, err := findLibraryFiles(req.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*libraryFile, 0, len(paths))
+
+	for _, path := range paths {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		report.FilesChecked++
+
+		file, issue := inspectLibraryFile(path)
+		if issue != nil {
+			report.Issues = append(report.Issues, issue)
+
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	if req.Client != nil {
+		issues, verifyErr := verifyLibraryMetadata(ctx, cfg, req, files)
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
+
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	slices.SortStableFunc(report.Issues, func(a, b *LibraryIssue) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return report, nil
+}
+
+// RepairTrackIDs returns the sorted IDs of the tracks with problems, which can be downloaded again.
+func (r *LibraryReport) RepairTrackIDs() []string {
+	var result []string
+
+	for _, issue := range r.Issues {
+		if issue.TrackID != "" && !slices.Contains(result, issue.TrackID) {
+			result = append(result, issue.TrackID)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b string) int {
+		aID, _ := strconv.ParseInt(a, 10, 64) //nolint:errcheck // Invalid IDs are sorted first.
+		bID, _ := strconv.ParseInt(b, 10, 64) //nolint:errcheck // Invalid IDs are sorted first.
+
+		return cmp.Compare(aID, bID)
+	})
+
+	return result
+}
+
+// UnidentifiedPaths returns the files with problems that have no track ID, so they cannot be downloaded again.
+func (r *LibraryReport) UnidentifiedPaths() []string {
+	var result []string
+
+	for _, issue := range r.Issues {
+		if issue.TrackID == "" && !slices.Contains(result, issue.Path) {
+			result = append(result, issue.Path)
+		}
+	}
+
+	return result
+}
+
+// findLibraryFiles returns the FLAC and MP3 files in the folder, skipping unfinished downloads.
+func findLibraryFiles(dir string) ([]string, error) {
+	var result []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case extensionFLAC, extensionMP3:
+			result = append(result, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk '%s': %w", dir, err)
+	}
+
+	return result, nil
+}
+
+// inspectLibraryFile reads the tags and checks the structure of an audio file.
+func inspectLibraryFile(path string) (*libraryFile, *LibraryIssue) {
+	if strings.EqualFold(filepath.Ext(path), extensionFLAC) {
+		return inspectFLACFile(path)
+	}
+
+	return inspectMP3File(path)
+}
+
+// inspectFLACFile checks the STREAMINFO block and the start of the audio frames of a FLAC file.
+func inspectFLACFile(path string) (*libraryFile, *LibraryIssue) {
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		return nil, &LibraryIssue{Path: path, Kind: LibraryIssueCorrupted, Detail: err.Error()}
+	}
+
+	streamInfo, err := f.GetStreamInfo()
+	if err != nil {
+		return nil, &LibraryIssue{Path: path, Kind: LibraryIssueCorrupted, Detail: err.Error()}
+	}
+
+	file := &libraryFile{
+		path:      path,
+		quality:   TrackQualityFLAC,
+		audioSize: int64(len(f.Frames)),
+	}
+
+	for _, meta := range f.Meta {
+		if meta.Type != flac.VorbisComment {
+			continue
+		}
+
+		comment, parseErr := flacvorbis.ParseFromMetaDataBlock(*meta)
+		if parseErr != nil {
+			return nil, &LibraryIssue{Path: path, Kind: LibraryIssueCorrupted, Detail: parseErr.Error()}
+		}
+
+		file.trackID = firstVorbisValue(comment, "TRACK_ID")
+		file.releaseID = firstVorbisValue(comment, "RELEASE_ID")
+		file.playlistID = firstVorbisValue(comment, "PLAYLIST_ID")
+		file.trackNumber = parseTagTrackNumber(firstVorbisValue(comment, "TRACKNUMBER"))
+		file.title = firstVorbisValue(comment, "TITLE")
+		file.artist = firstVorbisValue(comment, "ARTIST")
+	}
+
+	// Every FLAC frame starts with the 14-bit sync code 0b11111111111110.
+	if len(f.Frames) < 2 || f.Frames[0] != 0xFF || f.Frames[1]&0xFE != 0xF8 {
+		return nil, &LibraryIssue{
+			Path:    path,
+			TrackID: file.trackID,
+			Kind:    LibraryIssueCorrupted,
+			Detail:  "audio data does not start with a FLAC frame",
+		}
+	}
+
+	if streamInfo.SampleRate > 0 {
+		file.duration = float64(streamInfo.SampleCount) / float64(streamInfo.SampleRate)
+	}
+
+	// The stream cannot be shorter than its frame count times the smallest frame.
+	if streamInfo.FrameSizeMin > 0 && streamInfo.BlockSizeMax > 0 {
+		frameCount := (streamInfo.SampleCount + int64(streamInfo.BlockSizeMax) - 1) / int64(streamInfo.BlockSizeMax)
+
+		minSize := frameCount * int64(streamInfo.FrameSizeMin)
+		if file.audioSize < minSize {
+			return nil, &LibraryIssue{
+				Path:    path,
+				TrackID: file.trackID,
+				Kind:    LibraryIssueTruncated,
+				Detail:  fmt.Sprintf("audio data is %d bytes, expected at least %d bytes", file.audioSize, minSize),
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// parseTagTrackNumber parses a track number tag like "3" or "3/12" (0 if it is missing or invalid).
+func parseTagTrackNumber(value string) int64 {
+	number, _, _ := strings.Cut(strings.TrimSpace(value), "/")
+
+	result, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || result < 0 {
+		return 0
+	}
+
+	return result
+}
+
+// firstVorbisValue returns the first value of a Vorbis comment field (empty if missing).
+func firstVorbisValue(comment *flacvorbis.MetaDataBlockVorbisComment, name string) string {
+	values, err := comment.Get(name)
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// inspectMP3File checks the ID3v2 tag and the first audio frame of an MP3 file.
+func inspectMP3File(path string) (*libraryFile, *LibraryIssue) {
+	content, err := os.ReadFile(path) //nolint:gosec // The file is in the folder chosen by the user.
+	if err != nil {
+		return nil, &LibraryIssue{Path: path, Kind: LibraryIssueCorrupted, Detail: err.Error()}
+	}
+
+	//nolint:exhaustruct // Every frame is parsed.
+	tag, err := id3v2.ParseReader(bytes.NewReader(content), id3v2.Options{Parse: true})
+	if err != nil {
+		return nil, &LibraryIssue{Path: path, Kind: LibraryIssueCorrupted, Detail: err.Error()}
+	}
+
+	file := &libraryFile{
+		path:    path,
+		quality: TrackQualityMP3Mid,
+		title:   tag.Title(),
+		artist:  tag.Artist(),
+	}
+
+	file.trackNumber = parseTagTrackNumber(tag.GetTextFrame(tag.CommonID("Track number/Position in set")).Text)
+
+	for _, frame := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+		userFrame, ok := frame.(id3v2.UserDefinedTextFrame)
+		if !ok {
+			continue
+		}
+
+		switch userFrame.Description {
+		case "TRACK_ID":
+			file.trackID = userFrame.Value
+		case "PLAYLIST_ID":
+			file.playlistID = userFrame.Value
+		}
+	}
+
+	audio := content[id3v2TagSize(content):]
+	file.audioSize = int64(len(audio))
+
+	// Every MPEG audio frame starts with the 11-bit sync code.
+	if len(audio) < mp3FrameHeaderSize || audio[0] != 0xFF || audio[1]&0xE0 != 0xE0 {
+		return nil, &LibraryIssue{
+			Path:    path,
+			TrackID: file.trackID,
+			Kind:    LibraryIssueCorrupted,
+			Detail:  "audio data does not start with an MP3 frame",
+		}
+	}
+
+	// The playback time is only estimated for constant bitrate MPEG-1 Layer III, which Zvuk serves.
+	const mpeg1Layer3 = 0x1A
+	if audio[1]&0x1E == mpeg1Layer3 {
+		bitrate := mp3Bitrates[audio[2]>>4]
+		if bitrate > 0 {
+			file.duration = float64(file.audioSize*8) / float64(bitrate*1000)
+		}
+
+		if bitrate >= mp3HighBitrate {
+			file.quality = TrackQualityMP3High
+		}
+	}
+
+	return file, nil
+}
+
+// id3v2TagSize returns the size of the ID3v2 tag at the start of the content (0 if there is none).
+func id3v2TagSize(content []byte) int {
+	if len(content) < id3v2HeaderSize || string(content[:3]) != "ID3" {
+		return 0
+	}
+
+	// The size is a 28-bit synchsafe integer: 7 bits in each of 4 bytes.
+	sizeBytes := binary.BigEndian.Uint32(content[6:id3v2HeaderSize])
+	size := int(sizeBytes&0x7F | sizeBytes>>1&0x3F80 | sizeBytes>>2&0x1FC000 | sizeBytes>>3&0xFE00000)
+
+	size += id3v2HeaderSize
+	if content[5]&id3v2FooterFlag != 0 {
+		size += id3v2HeaderSize
+	}
+
+	return min(size, len(content))
+}
+
+// verifyLibraryMetadata compares the files against the metadata of their tracks.
+func verifyLibraryMetadata(
+	ctx context.Context,
+	cfg *config.Config,
+	req *VerifyLibraryRequest,
+	files []*libraryFile,
+) ([]*LibraryIssue, error) {
+	tracks, err := fetchLibraryTracks(ctx, cfg, req.Client, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*LibraryIssue
+
+	for _, file := range files {
+		if file.trackID == "" {
+			logger.Debugf(ctx, "File '%s' has no track ID, skipping metadata checks", file.path)
+
+			continue
+		}
+
+		track, ok := tracks[file.trackID]
+		if !ok || track == nil {
+			logger.Warnf(ctx, "Track with ID '%s' of file '%s' is not found", file.trackID, file.path)
+
+			continue
+		}
+
+		issues = append(issues, compareLibraryFile(file, track)...)
+
+		if !req.CheckSizes {
+			continue
+		}
+
+		streamSize, err := getLibraryStreamSize(ctx, req.Client, file)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to check the size of '%s': %v", file.path, err)
+
+			continue
+		}
+
+		// The stream carries tags of its own, which were replaced when the file was tagged.
+		if streamSize-file.audioSize > streamMetadataAllowance {
+			issues = append(issues, &LibraryIssue{
+				Path:    file.path,
+				TrackID: file.trackID,
+				Kind:    LibraryIssueTruncated,
+				Detail: fmt.Sprintf("audio data is %d bytes, the %s stream is %d bytes",
+					file.audioSize, file.quality, streamSize),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// fetchLibraryTracks fetches the metadata of the tracks of the files by their track IDs, in batches.
+func fetchLibraryTracks(
+	ctx context.Context,
+	cfg *config.Config,
+	client zvuk.Client,
+	files []*libraryFile,
+) (map[string]*zvuk.Track, error) {
+	var trackIDs []string
+
+	for _, file := range files {
+		if file.trackID != "" && !slices.Contains(trackIDs, file.trackID) {
+			trackIDs = append(trackIDs, file.trackID)
+		}
+	}
+
+	batchSize := int(cfg.MetadataBatchSize)
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataBatchSize
+	}
+
+	tracks := make(map[string]*zvuk.Track, len(trackIDs))
+
+	for batch := range slices.Chunk(trackIDs, batchSize) {
+		result, err := client.GetTracksMetadata(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks metadata: %w", err)
+		}
+
+		for id, track := range result {
+			tracks[id] = track
+		}
+	}
+
+	return tracks, nil
+}
+
+// compareLibraryFile compares the tags and the playback time of a file with the track metadata.
+func compareLibraryFile(file *libraryFile, track *zvuk.Track) []*LibraryIssue {
+	var issues []*LibraryIssue
+
+	addIssue := func(kind LibraryIssueKind, format string, args ...any) {
+		issues = append(issues, &LibraryIssue{
+			Path:    file.path,
+			TrackID: file.trackID,
+			Kind:    kind,
+			Detail:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if file.title != track.Title {
+		addIssue(LibraryIssueTagMismatch, "title is '%s', expected '%s'", file.title, track.Title)
+	}
+
+	for _, artistName := range track.ArtistNames {
+		if !strings.Contains(file.artist, artistName) {
+			addIssue(LibraryIssueTagMismatch, "artist '%s' does not credit '%s'", file.artist, artistName)
+
+			break
+		}
+	}
+
+	if file.releaseID != "" && track.ReleaseID > 0 && file.releaseID != strconv.FormatInt(track.ReleaseID, 10) {
+		addIssue(LibraryIssueTagMismatch, "release ID is '%s', expected '%d'", file.releaseID, track.ReleaseID)
+	}
+
+	expectedDuration := float64(track.Duration)
+	if file.duration > 0 && expectedDuration > 0 &&
+		file.duration < expectedDuration-max(durationTolerance, expectedDuration*0.02) {
+		addIssue(LibraryIssueTruncated, "plays %s of %s",
+			formatLibraryDuration(file.duration), formatLibraryDuration(expectedDuration))
+	}
+
+	return issues
+}
+
+// getLibraryStreamSize returns the size of the track stream in the quality of the file.
+func getLibraryStreamSize(ctx context.Context, client zvuk.Client, file *libraryFile) (int64, error) {
+	streamMetadata, err := client.GetStreamMetadata(ctx, file.trackID, file.quality.AsStreamURLParameterValue())
+	if err != nil {
+		return 0, err
+	}
+
+	return client.GetFileSize(ctx, streamMetadata.Stream)
+}
+
+// formatLibraryDuration formats seconds as m:ss.
+func formatLibraryDuration(seconds float64) string {
+	total := int64(math.Round(seconds))
+
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
@@ -0,0 +1,222 @@
+package zvuk
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+	"github.com/oshokin/id3v2/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+const (
+	// testFLACSampleRate is the sample rate of the generated FLAC files.
+	testFLACSampleRate = 44100
+	// testFLACBlockSize is the block size of the generated FLAC files.
+	testFLACBlockSize = 4096
+	// testFLACMinFrameSize is the smallest frame size declared by the generated FLAC files.
+	testFLACMinFrameSize = 100
+	// testMP3BytesPerSecond is the audio size of one second at 128 Kbps.
+	testMP3BytesPerSecond = 16000
+)
+
+// writeTestFLAC writes a FLAC file of the given length with the audio size and Vorbis comments.
+func writeTestFLAC(t *testing.T, path string, seconds, audioSize int, tags map[string]string) {
+	t.Helper()
+
+	// STREAMINFO: block sizes (16+16), frame sizes (24+24), sample rate (20),
+	// channels (3), bits per sample (5), total samples (36), and MD5 (128).
+	streamInfo := new(big.Int)
+	for _, field := range []struct {
+		value int64
+		bits  uint
+	}{
+		{testFLACBlockSize, 16},
+		{testFLACBlockSize, 16},
+		{testFLACMinFrameSize, 24},
+		{testFLACMinFrameSize * 10, 24},
+		{testFLACSampleRate, 20},
+		{1, 3},
+		{15, 5},
+		{int64(seconds) * testFLACSampleRate, 36},
+	} {
+		streamInfo.Lsh(streamInfo, field.bits).Or(streamInfo, big.NewInt(field.value))
+	}
+
+	streamInfo.Lsh(streamInfo, 128)
+
+	comment := flacvorbis.New()
+	for key, value := range tags {
+		require.NoError(t, comment.Add(key, value))
+	}
+
+	commentBlock := comment.Marshal()
+	frames := append([]byte{0xFF, 0xF8}, make([]byte, audioSize-2)...)
+
+	file := &flac.File{
+		Meta: []*flac.MetaDataBlock{
+			{Type: flac.StreamInfo, Data: streamInfo.FillBytes(make([]byte, 34))},
+			&commentBlock,
+		},
+		Frames: frames,
+	}
+
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(path, file.Marshal(), constants.DefaultFilePermissions))
+}
+
+// writeTestMP3 writes a 128 Kbps MP3 file of the given length with an ID3v2 tag.
+func writeTestMP3(t *testing.T, path string, seconds int, title, trackID string) {
+	t.Helper()
+
+	tag := id3v2.NewEmptyTag()
+	tag.SetDefaultEncoding(id3v2.EncodingUTF8)
+	tag.SetTitle(title)
+	tag.SetArtist("Rammstein")
+
+	//nolint:exhaustruct // Multi is only used when parsing frames.
+	tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+		Encoding:    id3v2.EncodingUTF8,
+		Description: "TRACK_ID",
+		Value:       trackID,
+	})
+
+	var content bytes.Buffer
+
+	_, err := tag.WriteTo(&content)
+	require.NoError(t, err)
+
+	// MPEG-1 Layer III, 128 Kbps, 44.1 kHz.
+	content.Write([]byte{0xFF, 0xFB, 0x90, 0x00})
+	content.Write(make([]byte, seconds*testMP3BytesPerSecond-4))
+
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(path, content.Bytes(), constants.DefaultFilePermissions))
+}
+
+// TestVerifyLibrary_FileStructure tests the checks that need no track metadata.
+func TestVerifyLibrary_FileStructure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeTestFLAC(t, filepath.Join(dir, "01 - Good.flac"), 10, 20000, map[string]string{"TRACK_ID": "101"})
+	writeTestFLAC(t, filepath.Join(dir, "02 - Cut.flac"), 10, 500, map[string]string{"TRACK_ID": "102"})
+	writeTestMP3(t, filepath.Join(dir, "03 - Good.mp3"), 3, "Good", "103")
+
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "04 - Broken.flac"), []byte("not a flac file"),
+		constants.DefaultFilePermissions))
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "05 - Broken.mp3"), []byte("not an mp3 file"),
+		constants.DefaultFilePermissions))
+	//nolint:gosec // It's a test file.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("image"),
+		constants.DefaultFilePermissions))
+
+	report, err := VerifyLibrary(context.Background(), new(config.Config), &VerifyLibraryRequest{Dir: dir})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.FilesChecked)
+	assert.False(t, report.IsMetadataChecked)
+
+	kinds := make(map[string]LibraryIssueKind, len(report.Issues))
+	for _, issue := range report.Issues {
+		kinds[filepath.Base(issue.Path)] = issue.Kind
+	}
+
+	assert.Equal(t, map[string]LibraryIssueKind{
+		"02 - Cut.flac":    LibraryIssueTruncated,
+		"04 - Broken.flac": LibraryIssueCorrupted,
+		"05 - Broken.mp3":  LibraryIssueCorrupted,
+	}, kinds)
+
+	assert.Equal(t, []string{"102"}, report.RepairTrackIDs())
+	assert.Equal(t, []string{
+		filepath.Join(dir, "04 - Broken.flac"),
+		filepath.Join(dir, "05 - Broken.mp3"),
+	}, report.UnidentifiedPaths())
+}
+
+// TestVerifyLibrary_Metadata tests the comparison of files with the track metadata.
+func TestVerifyLibrary_Metadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		checkSizes    bool
+		setupMock     func(client *mock_zvuk_client.MockClient)
+		expectedKinds []LibraryIssueKind
+	}{
+		{
+			name: "matching file",
+			setupMock: func(client *mock_zvuk_client.MockClient) {
+				client.EXPECT().GetTracksMetadata(gomock.Any(), []string{"201"}).Return(map[string]*zvuk.Track{
+					"201": {ID: 201, Title: "Sonne", ArtistNames: []string{"Rammstein"}, Duration: 10},
+				}, nil)
+			},
+		},
+		{
+			name: "different title and shorter audio",
+			setupMock: func(client *mock_zvuk_client.MockClient) {
+				client.EXPECT().GetTracksMetadata(gomock.Any(), []string{"201"}).Return(map[string]*zvuk.Track{
+					"201": {ID: 201, Title: "Mutter", ArtistNames: []string{"Rammstein"}, Duration: 272},
+				}, nil)
+			},
+			expectedKinds: []LibraryIssueKind{LibraryIssueTagMismatch, LibraryIssueTruncated},
+		},
+		{
+			name:       "stream larger than the audio",
+			checkSizes: true,
+			setupMock: func(client *mock_zvuk_client.MockClient) {
+				client.EXPECT().GetTracksMetadata(gomock.Any(), []string{"201"}).Return(map[string]*zvuk.Track{
+					"201": {ID: 201, Title: "Sonne", ArtistNames: []string{"Rammstein"}, Duration: 10},
+				}, nil)
+				client.EXPECT().GetStreamMetadata(gomock.Any(), "201", TrackQualityMP3MidString).
+					Return(&zvuk.StreamMetadata{Stream: "https://cdn.example.com/201.mp3"}, nil)
+				client.EXPECT().GetFileSize(gomock.Any(), "https://cdn.example.com/201.mp3").
+					Return(int64(10*testMP3BytesPerSecond+streamMetadataAllowance+1), nil)
+			},
+			expectedKinds: []LibraryIssueKind{LibraryIssueTruncated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeTestMP3(t, filepath.Join(dir, "Sonne.mp3"), 10, "Sonne", "201")
+
+			client := mock_zvuk_client.NewMockClient(gomock.NewController(t))
+			tt.setupMock(client)
+
+			report, err := VerifyLibrary(context.Background(), new(config.Config), &VerifyLibraryRequest{
+				Dir:        dir,
+				Client:     client,
+				CheckSizes: tt.checkSizes,
+			})
+			require.NoError(t, err)
+
+			kinds := make([]LibraryIssueKind, 0, len(report.Issues))
+			for _, issue := range report.Issues {
+				kinds = append(kinds, issue.Kind)
+			}
+
+			assert.ElementsMatch(t, tt.expectedKinds, kinds)
+			assert.True(t, report.IsMetadataChecked)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package zvuk
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+const (
+	// waveItemIDPrefix starts the item ID of a wave, followed by the station ID.
+	// The item ID becomes part of file paths, so the separator is safe in filenames.
+	waveItemIDPrefix = "wave-"
+	// personalWaveStationID is the station ID of the personal wave, which Zvuk requests without a station.
+	personalWaveStationID = "personal"
+	// defaultWaveTitle names the wave when Zvuk returns no title.
+	defaultWaveTitle = "My Wave"
+	// waveDateLayout is the layout of the date added to the folder name of a wave.
+	waveDateLayout = "2006-01-02"
+)
+
+// waveStationID returns the station ID of a wave item ID, empty for the personal wave,
+// and whether the item ID is one of a wave.
+func waveStationID(itemID string) (string, bool) {
+	stationID, isWave := strings.CutPrefix(itemID, waveItemIDPrefix)
+	if !isWave || stationID == personalWaveStationID {
+		return "", isWave
+	}
+
+	return stationID, true
+}
+
+// fetchPlaylistMetadata returns the metadata of the playlist, or of a sample of the wave for wave items.
+func (s *ServiceImpl) fetchPlaylistMetadata(
+	ctx context.Context,
+	itemID string,
+) (*zvuk.GetPlaylistsMetadataResponse, error) {
+	if stationID, isWave := waveStationID(itemID); isWave {
+		return s.fetchWaveSample(ctx, itemID, stationID, time.Now())
+	}
+
+	return s.zvukClient.GetPlaylistsMetadata(ctx, []string{itemID})
+}
+
+// fetchWaveSample takes wave_track_count tracks from the wave and returns them as a playlist
+// named after the wave and the date, so every day's sample is saved into its own folder.
+func (s *ServiceImpl) fetchWaveSample(
+	ctx context.Context,
+	itemID, stationID string,
+	date time.Time,
+) (*zvuk.GetPlaylistsMetadataResponse, error) {
+	wave, err := s.zvukClient.GetWave(ctx, stationID, s.cfg.WaveTrackCount)
+	if err != nil {
+		return nil, err
+	}
+
+	title := wavePlaylistTitle(wave.Title, date)
+	logger.Infof(ctx, "Wave '%s' handed out %d track(s)", title, len(wave.TrackIDs))
+
+	trackIDs := make([]string, 0, len(wave.TrackIDs))
+	for _, trackID := range wave.TrackIDs {
+		trackIDs = append(trackIDs, strconv.FormatInt(trackID, 10))
+	}
+
+	tracks, err := s.zvukClient.GetTracksMetadata(ctx, trackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks of wave '%s': %w", title, err)
+	}
+
+	return &zvuk.GetPlaylistsMetadataResponse{
+		Tracks: tracks,
+		Playlists: map[string]*zvuk.Playlist{
+			itemID: {Title: title, TrackIDs: wave.TrackIDs},
+		},
+	}, nil
+}
+
+// wavePlaylistTitle returns the title of the playlist a wave sample is saved as, e.g. "My Wave 2026-10-15".
+func wavePlaylistTitle(waveTitle string, date time.Time) string {
+	return cmp.Or(strings.TrimSpace(waveTitle), defaultWaveTitle) + " " + date.Format(waveDateLayout)
+}
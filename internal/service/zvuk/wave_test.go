@@ -0,0 +1,77 @@
+package zvuk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/oshokin/zvuk-grabber/internal/client/zvuk"
+	mock_zvuk_client "github.com/oshokin/zvuk-grabber/internal/client/zvuk/mocks"
+	"github.com/oshokin/zvuk-grabber/internal/config"
+)
+
+// TestWaveURLs tests that wave URLs are parsed into wave items and that their station is found.
+func TestWaveURLs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		url               string
+		expectedItemID    string
+		expectedStationID string
+	}{
+		{name: "personal wave", url: "https://zvuk.com/wave", expectedItemID: "wave-personal"},
+		{
+			name:              "wave station",
+			url:               "https://zvuk.com/wave/energy",
+			expectedItemID:    "wave-energy",
+			expectedStationID: "energy",
+		},
+		{
+			name:              "radio station",
+			url:               "https://zvuk.com/radio/215",
+			expectedItemID:    "wave-215",
+			expectedStationID: "215",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			item := new(URLProcessorImpl).parseDownloadItem(tt.url)
+			assert.Equal(t, DownloadCategoryPlaylist, item.Category)
+			assert.Equal(t, tt.expectedItemID, item.ItemID)
+
+			stationID, isWave := waveStationID(item.ItemID)
+			assert.True(t, isWave)
+			assert.Equal(t, tt.expectedStationID, stationID)
+		})
+	}
+
+	_, isWave := waveStationID("9037842")
+	assert.False(t, isWave, "a playlist is not a wave")
+}
+
+// TestFetchWaveSample tests that the tracks handed out by a wave make up a dated playlist.
+func TestFetchWaveSample(t *testing.T) {
+	t.Parallel()
+
+	mockClient := mock_zvuk_client.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().GetWave(gomock.Any(), "", 2).
+		Return(&zvuk.Wave{Title: "", TrackIDs: []int64{101, 102}}, nil)
+	mockClient.EXPECT().GetTracksMetadata(gomock.Any(), []string{"101", "102"}).
+		Return(map[string]*zvuk.Track{"101": {ID: 101}, "102": {ID: 102}}, nil)
+
+	s := &ServiceImpl{cfg: &config.Config{WaveTrackCount: 2}, zvukClient: mockClient}
+
+	response, err := s.fetchWaveSample(t.Context(), "wave-personal", "", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Len(t, response.Tracks, 2)
+	assert.Equal(t, &zvuk.Playlist{Title: "My Wave 2026-10-15", TrackIDs: []int64{101, 102}},
+		response.Playlists["wave-personal"])
+}
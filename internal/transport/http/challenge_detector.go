@@ -0,0 +1,293 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/logger"
+)
+
+// challengeSniffLength is the number of body bytes inspected to recognize a challenge page.
+const challengeSniffLength = 8 * 1024
+
+var (
+	// ErrAntiBotChallenge indicates that the server answered with an anti-bot (JS or captcha) challenge page.
+	ErrAntiBotChallenge = errors.New("anti-bot challenge detected")
+	// ErrRequestBodyNotReplayable indicates that a request cannot be sent again after a challenge was solved.
+	ErrRequestBodyNotReplayable = errors.New("request body cannot be replayed")
+)
+
+// challengePageMarkers are fragments found in challenge pages of the anti-bot services used in front of Zvuk.
+// They name the services and their challenge scripts: a bare "captcha" would also match regular pages
+// that embed a captcha widget, and such a page would never count as passed.
+//
+//nolint:gochecknoglobals // It is a static list of markers.
+var challengePageMarkers = [][]byte{
+	[]byte("servicepipe"),
+	[]byte("qrator"),
+	[]byte("ddos-guard"),
+	[]byte("/cdn-cgi/challenge-platform/"),
+	[]byte("cf-chl"),
+	[]byte("checking your browser"),
+}
+
+// ChallengeSolver passes an anti-bot challenge and returns the cookies that prove it was passed.
+type ChallengeSolver interface {
+	// SolveChallenge opens the challenge URL, waits until the challenge is passed, and returns the site cookies.
+	SolveChallenge(ctx context.Context, challengeURL *url.URL) ([]*http.Cookie, error)
+}
+
+// ChallengeDetector is a custom http.RoundTripper that recognizes anti-bot challenge responses.
+// Without a solver it turns them into ErrAntiBotChallenge; with a solver it hands the challenge off,
+// stores the obtained cookies, and replays the request once.
+type ChallengeDetector struct {
+	// next is the underlying HTTP round tripper.
+	next http.RoundTripper
+	// jar is the cookie jar of the HTTP client that receives the solved challenge cookies.
+	jar http.CookieJar
+	// cookieStore persists the solved challenge cookies between runs (may be nil).
+	cookieStore *CookieStore
+	// solver passes challenges (nil disables solving).
+	solver ChallengeSolver
+	// solveMutex ensures only one challenge is solved at a time.
+	solveMutex sync.Mutex
+	// solvedAt is the time the last challenge was solved, guarded by solveMutex.
+	solvedAt time.Time
+}
+
+// NewChallengeDetector creates and returns a new instance of ChallengeDetector.
+// The solver and the cookie store are optional.
+func NewChallengeDetector(
+	next http.RoundTripper,
+	jar http.CookieJar,
+	cookieStore *CookieStore,
+	solver ChallengeSolver,
+) http.RoundTripper {
+	return &ChallengeDetector{
+		next:        next,
+		jar:         jar,
+		cookieStore: cookieStore,
+		solver:      solver,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction and handles anti-bot challenge responses.
+// It implements the http.RoundTripper interface.
+func (t *ChallengeDetector) RoundTrip(req *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+
+	response, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	isChallenge, err := detectChallenge(response)
+	if err != nil || !isChallenge {
+		return response, err
+	}
+
+	_ = response.Body.Close()
+
+	challengeErr := fmt.Errorf("%w: HTTP %d from %s", ErrAntiBotChallenge, response.StatusCode, req.URL.Host)
+
+	if t.solver == nil || t.jar == nil {
+		return nil, fmt.Errorf("%w (enable solve_anti_bot_challenges to pass it in a browser, "+
+			"or wait and retry later)", challengeErr)
+	}
+
+	// The request context ends with the client timeout, which is far shorter than solving a captcha may take.
+	solveCtx, cancelSolve := withoutDeadline(req.Context(), MaxChallengeWaitTime)
+	err = t.solve(solveCtx, req.URL, startTime)
+
+	cancelSolve()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to solve it: %w", challengeErr, err)
+	}
+
+	// For the same reason, the request is replayed with a timeout of its own, lasting until its body is closed.
+	retryCtx, cancelRetry := withoutDeadline(req.Context(), DefaultTimeout)
+
+	retryRequest, err := t.cloneWithJarCookies(req.WithContext(retryCtx))
+	if err != nil {
+		cancelRetry()
+
+		return nil, fmt.Errorf("%w: %w", challengeErr, err)
+	}
+
+	// The client also cancels the request through its legacy Cancel channel when its timeout fires.
+	retryRequest.Cancel = nil //nolint:staticcheck // The channel is set by http.Client, not by this code.
+
+	response, err = t.next.RoundTrip(retryRequest)
+	if err != nil {
+		cancelRetry()
+
+		return nil, err
+	}
+
+	isChallenge, err = detectChallenge(response)
+	if err != nil {
+		cancelRetry()
+
+		return nil, err
+	}
+
+	if !isChallenge {
+		response.Body = &cancelOnCloseReadCloser{ReadCloser: response.Body, cancel: cancelRetry}
+
+		return response, nil
+	}
+
+	_ = response.Body.Close()
+
+	cancelRetry()
+
+	return nil, fmt.Errorf("%w: the challenge is still shown after solving it", challengeErr)
+}
+
+// solve hands the challenge off to the solver unless another request solved it
+// after this request had been sent.
+func (t *ChallengeDetector) solve(ctx context.Context, challengeURL *url.URL, requestTime time.Time) error {
+	t.solveMutex.Lock()
+	defer t.solveMutex.Unlock()
+
+	if t.solvedAt.After(requestTime) {
+		return nil
+	}
+
+	logger.Warnf(ctx, "Anti-bot challenge detected at %s, opening browser to solve it", challengeURL.Host)
+
+	cookies, err := t.solver.SolveChallenge(ctx, challengeURL)
+	if err != nil {
+		return err
+	}
+
+	siteURL := &url.URL{Scheme: challengeURL.Scheme, Host: challengeURL.Host, Path: "/"}
+	t.jar.SetCookies(siteURL, cookies)
+	t.solvedAt = time.Now()
+
+	if t.cookieStore != nil {
+		if err = t.cookieStore.Save(challengeURL.Host, cookies); err != nil {
+			logger.Warnf(ctx, "Failed to save anti-bot cookies: %v", err)
+		}
+	}
+
+	logger.Infof(ctx, "Anti-bot challenge at %s solved, resuming", challengeURL.Host)
+
+	return nil
+}
+
+// withoutDeadline returns a context with a timeout of its own that is canceled along with the parent,
+// e.g., on CTRL+C, but outlives the deadline of the parent.
+func withoutDeadline(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+
+	stop := context.AfterFunc(parent, func() {
+		// The parent ends at its deadline by itself or with the client timeout firing at the same time.
+		if deadline, ok := parent.Deadline(); ok && !time.Now().Before(deadline) {
+			return
+		}
+
+		cancel()
+	})
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// cloneWithJarCookies copies the request, replays its body, and replaces its cookies
+// with the current contents of the cookie jar.
+func (t *ChallengeDetector) cloneWithJarCookies(req *http.Request) (*http.Request, error) {
+	result := req.Clone(req.Context())
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, ErrRequestBodyNotReplayable
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+
+		result.Body = body
+	}
+
+	result.Header.Del("Cookie")
+
+	for _, cookie := range t.jar.Cookies(req.URL) {
+		result.AddCookie(cookie)
+	}
+
+	return result, nil
+}
+
+// detectChallenge reports whether the response is an anti-bot challenge page.
+// Only HTML responses are inspected; the sniffed bytes are put back into the body otherwise.
+func detectChallenge(response *http.Response) (bool, error) {
+	if response.Header.Get("Cf-Mitigated") == "challenge" {
+		return true, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType != "text/html" {
+		return false, nil
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(response.Body, challengeSniffLength))
+	if err != nil {
+		_ = response.Body.Close()
+
+		return false, err
+	}
+
+	response.Body = &prefixedReadCloser{
+		Reader: io.MultiReader(bytes.NewReader(prefix), response.Body),
+		Closer: response.Body,
+	}
+
+	return IsChallengePage(prefix), nil
+}
+
+// IsChallengePage reports whether the HTML content looks like an anti-bot challenge page.
+func IsChallengePage(content []byte) bool {
+	content = bytes.ToLower(content)
+
+	for _, marker := range challengePageMarkers {
+		if bytes.Contains(content, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cancelOnCloseReadCloser cancels the context of a replayed request once its body is closed.
+type cancelOnCloseReadCloser struct {
+	io.ReadCloser
+	// cancel cancels the context of the replayed request.
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the request.
+func (r *cancelOnCloseReadCloser) Close() error {
+	defer r.cancel()
+
+	return r.ReadCloser.Close()
+}
+
+// prefixedReadCloser reads the sniffed prefix followed by the rest of the original body.
+type prefixedReadCloser struct {
+	io.Reader
+	io.Closer
+}
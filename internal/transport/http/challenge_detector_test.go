@@ -0,0 +1,250 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// challengePage is a minimal anti-bot page served by the test servers.
+const challengePage = `<html><head><script src="/servicepipe/challenge.js"></script></head><body></body></html>`
+
+// fakeChallengeSolver returns a fixed cookie after the delay and counts how many times it was asked.
+type fakeChallengeSolver struct {
+	calls int
+	delay time.Duration
+}
+
+// SolveChallenge implements ChallengeSolver.
+func (s *fakeChallengeSolver) SolveChallenge(ctx context.Context, _ *url.URL) ([]*http.Cookie, error) {
+	s.calls++
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.delay):
+	}
+
+	return []*http.Cookie{{Name: "spsc", Value: "passed"}}, nil
+}
+
+// newChallengeServer serves the challenge page until the request carries the solved cookie.
+func newChallengeServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("spsc"); err == nil && cookie.Value == "passed" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"result":{}}`)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, challengePage)
+	}))
+}
+
+// TestChallengeDetector_WithoutSolver tests that a challenge page becomes ErrAntiBotChallenge.
+func TestChallengeDetector_WithoutSolver(t *testing.T) {
+	t.Parallel()
+
+	server := newChallengeServer()
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	client := &http.Client{
+		Transport: NewChallengeDetector(http.DefaultTransport, jar, nil, nil),
+		Jar:       jar,
+	}
+
+	response, err := client.Get(server.URL) //nolint:noctx // Test request.
+	if response != nil {
+		_ = response.Body.Close()
+	}
+
+	require.ErrorIs(t, err, ErrAntiBotChallenge)
+}
+
+// TestChallengeDetector_WithSolver tests that a solved challenge is persisted and the request is replayed.
+func TestChallengeDetector_WithSolver(t *testing.T) {
+	t.Parallel()
+
+	server := newChallengeServer()
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	var (
+		solver      = new(fakeChallengeSolver)
+		storePath   = filepath.Join(t.TempDir(), "cookies.json")
+		cookieStore = NewCookieStore(storePath)
+		client      = &http.Client{
+			Transport: NewChallengeDetector(http.DefaultTransport, jar, cookieStore, solver),
+			Jar:       jar,
+		}
+	)
+
+	for range 2 {
+		response, err := client.Get(server.URL) //nolint:noctx // Test request.
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"result":{}}`, string(body))
+	}
+
+	// The second request already carries the cookie from the jar.
+	assert.Equal(t, 1, solver.calls)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	stored, err := cookieStore.Load()
+	require.NoError(t, err)
+	require.Len(t, stored[serverURL.Host], 1)
+	assert.Equal(t, "passed", stored[serverURL.Host][0].Value)
+}
+
+// TestChallengeDetector_SolvingOutlastsClientTimeout tests that solving a challenge is not cut short
+// by the timeout of the HTTP client, and that the request is replayed with a timeout of its own.
+func TestChallengeDetector_SolvingOutlastsClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newChallengeServer()
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	var (
+		solver = &fakeChallengeSolver{delay: 300 * time.Millisecond}
+		client = &http.Client{
+			Transport: NewChallengeDetector(http.DefaultTransport, jar, nil, solver),
+			Jar:       jar,
+			Timeout:   100 * time.Millisecond,
+		}
+	)
+
+	response, err := client.Get(server.URL) //nolint:noctx // Test request.
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result":{}}`, string(body))
+}
+
+// TestChallengeDetector_CanceledWhileSolving tests that canceling the request, e.g., with CTRL+C,
+// stops solving the challenge even though it outlives the client timeout.
+func TestChallengeDetector_CanceledWhileSolving(t *testing.T) {
+	t.Parallel()
+
+	server := newChallengeServer()
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	var (
+		solver = &fakeChallengeSolver{delay: time.Minute}
+		client = &http.Client{
+			Transport: NewChallengeDetector(http.DefaultTransport, jar, nil, solver),
+			Jar:       jar,
+			Timeout:   time.Minute,
+		}
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	startTime := time.Now()
+
+	response, err := client.Do(request)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(startTime), 10*time.Second)
+	assert.Equal(t, 1, solver.calls)
+}
+
+// TestChallengeDetector_KeepsRegularHTML tests that regular HTML responses are passed through intact.
+func TestChallengeDetector_KeepsRegularHTML(t *testing.T) {
+	t.Parallel()
+
+	const page = "<html><body>Regular page</body></html>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, page)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: NewChallengeDetector(http.DefaultTransport, nil, nil, nil),
+	}
+
+	response, err := client.Get(server.URL) //nolint:noctx // Test request.
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, page, string(body))
+}
+
+// TestIsChallengePage tests recognition of challenge pages.
+func TestIsChallengePage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "servicepipe script", content: challengePage, expected: true},
+		{name: "qrator in upper case", content: "<title>QRATOR</title>", expected: true},
+		{name: "browser check text", content: "<p>Checking your browser before accessing</p>", expected: true},
+		{
+			name:     "cloudflare challenge script",
+			content:  `<script src="/cdn-cgi/challenge-platform/h/b/orchestrate/chl_page/v1">`,
+			expected: true,
+		},
+		{name: "regular page", content: "<html><body>Music</body></html>", expected: false},
+		{
+			name:     "regular page with a captcha widget",
+			content:  `<form><div class="captcha"></div><script src="https://captcha.example.com/api.js"></script></form>`,
+			expected: false,
+		},
+		{name: "page mentioning a captcha", content: "<p>No captcha is needed to listen</p>", expected: false},
+		{name: "empty", content: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, IsChallengePage([]byte(tt.content)))
+		})
+	}
+}
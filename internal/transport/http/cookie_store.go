@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
+)
+
+// CookieStore persists cookies per host in a JSON file, so solved anti-bot challenges
+// survive between runs.
+type CookieStore struct {
+	// path is the path to the JSON file.
+	path string
+	// mutex serializes reads and writes of the file.
+	mutex sync.Mutex
+}
+
+// storedCookie is the on-disk representation of a single cookie.
+type storedCookie struct {
+	// Name is the cookie name.
+	Name string `json:"name"`
+	// Value is the cookie value.
+	Value string `json:"value"`
+	// Path is the cookie path.
+	Path string `json:"path,omitempty"`
+	// Expires is the expiration time (zero for session cookies).
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// NewCookieStore creates a cookie store backed by the file at path.
+func NewCookieStore(path string) *CookieStore {
+	return &CookieStore{
+		path: path,
+	}
+}
+
+// Load returns the unexpired cookies of every host; a missing file yields an empty result.
+func (s *CookieStore) Load() (map[string][]*http.Cookie, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = make(map[string][]*http.Cookie, len(stored))
+		now    = time.Now()
+	)
+
+	for host, cookies := range stored {
+		for _, cookie := range cookies {
+			if !cookie.Expires.IsZero() && cookie.Expires.Before(now) {
+				continue
+			}
+
+			result[host] = append(result[host], &http.Cookie{
+				Name:    cookie.Name,
+				Value:   cookie.Value,
+				Path:    cookie.Path,
+				Expires: cookie.Expires,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Save replaces the stored cookies of the host, keeping the cookies of other hosts.
+func (s *CookieStore) Save(host string, cookies []*http.Cookie) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	hostCookies := make([]*storedCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		hostCookies = append(hostCookies, &storedCookie{
+			Name:    cookie.Name,
+			Value:   cookie.Value,
+			Path:    cookie.Path,
+			Expires: cookie.Expires,
+		})
+	}
+
+	stored[host] = hostCookies
+
+	content, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err = os.MkdirAll(dir, constants.DefaultFolderPermissions); err != nil {
+			return fmt.Errorf("failed to create cookie store folder: %w", err)
+		}
+	}
+
+	// Cookies are credentials, so the file is readable by the owner only.
+	if err = os.WriteFile(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write cookie store: %w", err)
+	}
+
+	return nil
+}
+
+// read parses the cookie file; the caller must hold the mutex.
+func (s *CookieStore) read() (map[string][]*storedCookie, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(map[string][]*storedCookie), nil
+		}
+
+		return nil, fmt.Errorf("failed to read cookie store: %w", err)
+	}
+
+	var result map[string][]*storedCookie
+	if err = json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse cookie store: %w", err)
+	}
+
+	if result == nil {
+		result = make(map[string][]*storedCookie)
+	}
+
+	return result, nil
+}
@@ -1,5 +1,5 @@
 // Package http provides custom HTTP transport utilities,
-// including request/response logging and User-Agent header injection.
+// including request/response logging, User-Agent header injection, and proxies.
 // It is designed to enhance HTTP client functionality
 // with debugging capabilities and request customization.
 package http
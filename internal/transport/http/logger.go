@@ -1,9 +1,13 @@
 package http
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httputil"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/oshokin/zvuk-grabber/internal/config"
@@ -11,6 +15,22 @@ import (
 	"github.com/oshokin/zvuk-grabber/internal/utils"
 )
 
+// BodyLogRule controls how the bodies of requests and responses of matching endpoints are logged.
+type BodyLogRule struct {
+	// PathPrefix is matched against the URL path of the request (e.g., "/api/v1/graphql").
+	PathPrefix string
+	// MaxLength is the maximum length of a logged body. 0 uses the maximum length of the transport.
+	MaxLength uint64
+	// IsBodyOmitted indicates that bodies are never logged, only the headers.
+	IsBodyOmitted bool
+	// IsErrorLoggedInFull indicates that a response reporting an error is logged without truncation:
+	// an HTTP error status or a JSON body with an "errors" field, as returned by GraphQL.
+	IsErrorLoggedInFull bool
+	// MinInterval is the minimum time between two logged bodies of the endpoint.
+	// Bodies of requests made in between are omitted; 0 logs every body.
+	MinInterval time.Duration
+}
+
 // LogTransport is a custom http.RoundTripper that logs HTTP requests and responses.
 // It wraps another http.RoundTripper and logs debug information for each request/response cycle.
 type LogTransport struct {
@@ -18,6 +38,12 @@ type LogTransport struct {
 	next http.RoundTripper
 	// maxLogLength is the maximum length of logged request/response data.
 	maxLogLength uint64
+	// rules are the per-endpoint body logging rules, the first matching rule applies.
+	rules []BodyLogRule
+	// mutex protects lastLoggedAt.
+	mutex sync.Mutex
+	// lastLoggedAt maps a rule index to the time a body of its endpoint was last logged.
+	lastLoggedAt map[int]time.Time
 }
 
 // Static error definitions for better error handling.
@@ -26,9 +52,16 @@ var (
 	ErrNilRequest = errors.New("request is nil")
 )
 
+// headerBodySeparator separates the headers from the body in an HTTP dump.
+var headerBodySeparator = []byte("\r\n\r\n") //nolint:gochecknoglobals // Immutable separator.
+
+// errorsField is the top-level field of a JSON body that reports errors (e.g., GraphQL errors).
+var errorsField = []byte(`"errors":`) //nolint:gochecknoglobals // Immutable marker.
+
 // NewLogTransport creates and returns a new instance of LogTransport.
 // If maxLogLength is less than or equal to 0, it defaults to config.DefaultMaxLogLength.
-func NewLogTransport(next http.RoundTripper, maxLogLength uint64) http.RoundTripper {
+// Bodies of endpoints matching one of the rules are logged according to the first matching rule.
+func NewLogTransport(next http.RoundTripper, maxLogLength uint64, rules ...BodyLogRule) http.RoundTripper {
 	if maxLogLength <= 0 {
 		maxLogLength = config.DefaultMaxLogLength
 	}
@@ -36,6 +69,8 @@ func NewLogTransport(next http.RoundTripper, maxLogLength uint64) http.RoundTrip
 	return &LogTransport{
 		next:         next,
 		maxLogLength: maxLogLength,
+		rules:        rules,
+		lastLoggedAt: make(map[int]time.Time, len(rules)),
 	}
 }
 
@@ -53,7 +88,9 @@ func (t *LogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	ctx := req.Context()
 
-	requestDump := t.dumpRequest(req)
+	rule, isBodyLogged := t.matchRule(req.URL.Path, time.Now())
+
+	requestDump := t.dumpRequest(req, rule, isBodyLogged)
 
 	// Record the start time to measure the duration of the request.
 	startTime := time.Now()
@@ -70,7 +107,7 @@ func (t *LogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
-	responseDump := t.dumpResponse(resp)
+	responseDump := t.dumpResponse(resp, rule, isBodyLogged)
 
 	logger.Debugf(ctx, "%s %s [%d] %s\nRequest: %s\nResponse: %s",
 		req.Method, req.URL.Path, resp.StatusCode, duration, requestDump, responseDump)
@@ -78,31 +115,96 @@ func (t *LogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
-func (t *LogTransport) dumpRequest(req *http.Request) string {
+// matchRule returns the rule of the endpoint and reports whether its bodies may be logged now.
+// Requests matching no rule are logged with the maximum length of the transport.
+func (t *LogTransport) matchRule(path string, now time.Time) (BodyLogRule, bool) {
+	for i, rule := range t.rules {
+		if !strings.HasPrefix(path, rule.PathPrefix) {
+			continue
+		}
+
+		if rule.IsBodyOmitted || rule.MinInterval <= 0 {
+			return rule, !rule.IsBodyOmitted
+		}
+
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+
+		if lastLoggedAt, ok := t.lastLoggedAt[i]; ok && now.Sub(lastLoggedAt) < rule.MinInterval {
+			return rule, false
+		}
+
+		t.lastLoggedAt[i] = now
+
+		return rule, true
+	}
+
+	return BodyLogRule{}, true
+}
+
+func (t *LogTransport) dumpRequest(req *http.Request, rule BodyLogRule, isBodyLogged bool) string {
 	// Include the request body in the dump.
-	dump, err := httputil.DumpRequest(req, true)
+	dump, err := httputil.DumpRequest(req, isBodyLogged)
 	if err != nil {
 		return err.Error()
 	}
 
-	return t.truncate(dump)
+	hasBody := req.Body != nil && req.Body != http.NoBody
+
+	return t.formatDump(dump, rule, isBodyLogged || !hasBody, false)
 }
 
-func (t *LogTransport) dumpResponse(resp *http.Response) string {
+func (t *LogTransport) dumpResponse(resp *http.Response, rule BodyLogRule, isBodyLogged bool) string {
 	// Check the Content-Type header to determine if the response body should be dumped.
 	contentType := resp.Header.Get("Content-Type")
+	isTextBody := utils.IsTextContentType(contentType)
 
-	dump, err := httputil.DumpResponse(resp, utils.IsTextContentType(contentType))
+	dump, err := httputil.DumpResponse(resp, isBodyLogged && isTextBody)
 	if err != nil {
 		return err.Error()
 	}
 
-	return t.truncate(dump)
+	// Binary bodies (e.g., audio streams) are never included in the dump.
+	if !isTextBody || resp.ContentLength == 0 {
+		return t.truncate(dump, t.maxLogLength)
+	}
+
+	return t.formatDump(dump, rule, isBodyLogged, resp.StatusCode >= http.StatusBadRequest)
+}
+
+// formatDump applies the body rule to a dump of headers and body.
+func (t *LogTransport) formatDump(dump []byte, rule BodyLogRule, isBodyLogged, isError bool) string {
+	if !isBodyLogged {
+		reason := "rate limited"
+		if rule.IsBodyOmitted {
+			reason = "not logged for this endpoint"
+		}
+
+		return string(bytes.TrimRight(dump, "\r\n")) + fmt.Sprintf("\r\n\r\n[body %s]", reason)
+	}
+
+	headers, body, isFound := bytes.Cut(dump, headerBodySeparator)
+	if !isFound {
+		return t.truncate(dump, t.maxLogLength)
+	}
+
+	if rule.IsErrorLoggedInFull && (isError || bytes.Contains(body, errorsField)) {
+		return string(dump)
+	}
+
+	maxLength := t.maxLogLength
+	if rule.MaxLength > 0 {
+		maxLength = rule.MaxLength
+	}
+
+	headerLength := uint64(len(headers) + len(headerBodySeparator))
+
+	return string(dump[:headerLength]) + t.truncate(body, maxLength)
 }
 
-func (t *LogTransport) truncate(data []byte) string {
-	if uint64(len(data)) > t.maxLogLength {
-		return string(data[:t.maxLogLength]) + "... [truncated]"
+func (t *LogTransport) truncate(data []byte, maxLength uint64) string {
+	if uint64(len(data)) > maxLength {
+		return string(data[:maxLength]) + "... [truncated]"
 	}
 
 	return string(data)
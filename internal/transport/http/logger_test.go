@@ -0,0 +1,102 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestResponse creates a JSON response with the given status and body.
+func newTestResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+// TestLogTransport_BodyLogRules tests per-endpoint body logging rules.
+func TestLogTransport_BodyLogRules(t *testing.T) {
+	t.Parallel()
+
+	longBody := `{"data":"` + strings.Repeat("x", 100) + `"}`
+
+	transport, ok := NewLogTransport(nil, 1000,
+		BodyLogRule{PathPrefix: "/api/tiny/track/stream", IsBodyOmitted: true},
+		BodyLogRule{PathPrefix: "/api/v1/graphql", MaxLength: 10, IsErrorLoggedInFull: true},
+	).(*LogTransport)
+	require.True(t, ok)
+
+	t.Run("omitted body", func(t *testing.T) {
+		t.Parallel()
+
+		rule, isBodyLogged := transport.matchRule("/api/tiny/track/stream", time.Now())
+		require.False(t, isBodyLogged)
+
+		dump := transport.dumpResponse(newTestResponse(http.StatusOK, `{"result":{"stream":"secret"}}`), rule, isBodyLogged)
+		assert.NotContains(t, dump, "secret")
+		assert.Contains(t, dump, "[body not logged for this endpoint]")
+	})
+
+	t.Run("truncated body", func(t *testing.T) {
+		t.Parallel()
+
+		rule, isBodyLogged := transport.matchRule("/api/v1/graphql", time.Now())
+		require.True(t, isBodyLogged)
+
+		dump := transport.dumpResponse(newTestResponse(http.StatusOK, longBody), rule, isBodyLogged)
+		assert.Contains(t, dump, `{"data":"x... [truncated]`)
+	})
+
+	t.Run("GraphQL errors are logged in full", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"errors":[{"message":"` + strings.Repeat("bad field ", 10) + `"}]}`
+		rule, isBodyLogged := transport.matchRule("/api/v1/graphql", time.Now())
+
+		dump := transport.dumpResponse(newTestResponse(http.StatusOK, body), rule, isBodyLogged)
+		assert.Contains(t, dump, body)
+	})
+
+	t.Run("unmatched endpoint uses the transport limit", func(t *testing.T) {
+		t.Parallel()
+
+		rule, isBodyLogged := transport.matchRule("/api/tiny/releases", time.Now())
+		require.True(t, isBodyLogged)
+
+		dump := transport.dumpResponse(newTestResponse(http.StatusOK, longBody), rule, isBodyLogged)
+		assert.Contains(t, dump, longBody)
+	})
+}
+
+// TestLogTransport_MinInterval tests that bodies of an endpoint are rate limited.
+func TestLogTransport_MinInterval(t *testing.T) {
+	t.Parallel()
+
+	transport, ok := NewLogTransport(nil, 0,
+		BodyLogRule{PathPrefix: "/api/tiny/lyrics", MinInterval: time.Minute},
+	).(*LogTransport)
+	require.True(t, ok)
+
+	now := time.Now()
+
+	_, isBodyLogged := transport.matchRule("/api/tiny/lyrics", now)
+	assert.True(t, isBodyLogged)
+
+	rule, isBodyLogged := transport.matchRule("/api/tiny/lyrics", now.Add(time.Second))
+	assert.False(t, isBodyLogged)
+
+	dump := transport.dumpResponse(newTestResponse(http.StatusOK, `{"result":"lyrics"}`), rule, isBodyLogged)
+	assert.Contains(t, dump, "[body rate limited]")
+
+	_, isBodyLogged = transport.matchRule("/api/tiny/lyrics", now.Add(2*time.Minute))
+	assert.True(t, isBodyLogged)
+}
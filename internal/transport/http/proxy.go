@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NewProxyTransport returns a copy of http.DefaultTransport that sends every request through the proxy,
+// except the ones to the hosts of noProxy, written as in the NO_PROXY environment variable.
+// An empty noProxy takes the NO_PROXY variable, and an empty proxy URL keeps the HTTP_PROXY,
+// HTTPS_PROXY, and NO_PROXY variables, like http.DefaultTransport does.
+// Requests to localhost never go through the proxy.
+func NewProxyTransport(proxyURL string, noProxy []string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck,forcetypeassert // It is always one.
+	if proxyURL == "" {
+		return transport
+	}
+
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    strings.Join(noProxy, ","),
+	}
+
+	if len(noProxy) == 0 {
+		proxyConfig.NoProxy = httpproxy.FromEnvironment().NoProxy
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+
+	transport.Proxy = func(request *http.Request) (*url.URL, error) {
+		return proxyFunc(request.URL)
+	}
+
+	return transport
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewProxyTransport tests that requests go through the proxy, except to the hosts of the no-proxy list.
+func TestNewProxyTransport(t *testing.T) {
+	t.Parallel()
+
+	transport := NewProxyTransport("socks5://127.0.0.1:1080", []string{".internal"})
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedProxy string
+	}{
+		{name: "proxied", url: "https://zvuk.com/api/v1/graphql", expectedProxy: "socks5://127.0.0.1:1080"},
+		{name: "no proxy list", url: "https://nas.internal/music"},
+		{name: "localhost", url: "http://localhost:8080/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+
+			proxyURL, err := transport.Proxy(request)
+			require.NoError(t, err)
+
+			if tt.expectedProxy == "" {
+				assert.Nil(t, proxyURL)
+
+				return
+			}
+
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, tt.expectedProxy, proxyURL.String())
+		})
+	}
+}
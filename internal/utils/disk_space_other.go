@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || windows)
+
+package utils
+
+import "errors"
+
+// ErrDiskSpaceUnsupported indicates that the free disk space cannot be determined on this platform.
+var ErrDiskSpaceUnsupported = errors.New("free disk space is not supported on this platform")
+
+// FreeDiskSpace returns the number of bytes available to the current user on the disk holding path.
+func FreeDiskSpace(_ string) (uint64, error) {
+	return 0, ErrDiskSpaceUnsupported
+}
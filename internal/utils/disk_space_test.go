@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd || windows
+
+//nolint:nolintlint,revive // utils is a common and acceptable package name for utility functions.
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFreeDiskSpace tests that the free space of an existing folder is reported.
+func TestFreeDiskSpace(t *testing.T) {
+	t.Parallel()
+
+	freeBytes, err := FreeDiskSpace(t.TempDir())
+	require.NoError(t, err)
+	assert.Positive(t, freeBytes)
+
+	_, err = FreeDiskSpace(t.TempDir() + "/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// FreeDiskSpace returns the number of bytes available to the current user on the disk holding path.
+func FreeDiskSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	//nolint:gosec,unconvert // The field types differ between the platforms.
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package utils
+
+import "golang.org/x/sys/windows"
+
+// FreeDiskSpace returns the number of bytes available to the current user on the disk holding path.
+func FreeDiskSpace(path string) (uint64, error) {
+	pathPointer, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeBytes uint64
+	if err = windows.GetDiskFreeSpaceEx(pathPointer, &freeBytes, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return freeBytes, nil
+}
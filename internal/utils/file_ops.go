@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 
 	"github.com/google/uuid"
+
+	"github.com/oshokin/zvuk-grabber/internal/constants"
 )
 
 // RenameFile renames sourcePath to destinationPath.
@@ -61,3 +63,45 @@ func replaceExistingDestination(sourcePath, destinationPath string) error {
 
 	return moveSourceErr
 }
+
+// WriteFileAtomically writes the content to a temporary file next to path, flushes it to disk,
+// and renames it over path, so an interrupted save leaves either the previous file or the new one.
+// The parent folders are created when missing.
+func WriteFileAtomically(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, constants.DefaultFolderPermissions); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	tempPath := file.Name()
+
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(constants.DefaultFilePermissions)
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = RenameFile(tempPath, path, true)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempPath)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import "strings"
+
+// WindowsVolumeName returns the leading drive ("D:") or UNC share ("\\server\share") of a Windows path.
+// Unlike filepath.VolumeName, it parses Windows syntax on every OS, so configuration can be checked
+// consistently. It returns an empty string if the path has no volume.
+func WindowsVolumeName(path string) string {
+	if len(path) >= 2 && path[1] == ':' && isASCIILetter(path[0]) {
+		return path[:2]
+	}
+
+	if len(path) < 2 || !isWindowsSeparator(path[0]) || !isWindowsSeparator(path[1]) {
+		return ""
+	}
+
+	// \\server\share: the volume ends right before the separator that follows the share.
+	server, rest, found := cutAtWindowsSeparator(path[2:])
+	if server == "" || !found {
+		return ""
+	}
+
+	share, _, _ := cutAtWindowsSeparator(rest)
+	if share == "" {
+		return ""
+	}
+
+	return path[:2+len(server)+1+len(share)]
+}
+
+// isASCIILetter reports whether c is an ASCII letter, the only characters allowed as drive letters.
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isWindowsSeparator reports whether c separates path elements on Windows.
+func isWindowsSeparator(c byte) bool {
+	return c == '\\' || c == '/'
+}
+
+// cutAtWindowsSeparator splits path around the first Windows separator.
+func cutAtWindowsSeparator(path string) (before, after string, found bool) {
+	index := strings.IndexAny(path, `\/`)
+	if index < 0 {
+		return path, "", false
+	}
+
+	return path[:index], path[index+1:], true
+}
@@ -0,0 +1,40 @@
+//nolint:nolintlint,revive // utils is a common and acceptable package name for utility functions.
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWindowsVolumeName tests the WindowsVolumeName function.
+func TestWindowsVolumeName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "drive with backslash", path: `D:\Music`, expected: "D:"},
+		{name: "drive with slash", path: "c:/Music", expected: "c:"},
+		{name: "bare drive", path: "D:", expected: "D:"},
+		{name: "UNC share", path: `\\nas\music`, expected: `\\nas\music`},
+		{name: "UNC share with folder", path: `\\nas\music\zvuk`, expected: `\\nas\music`},
+		{name: "UNC share with slashes", path: "//nas/music/zvuk", expected: "//nas/music"},
+		{name: "UNC server without share", path: `\\nas`, expected: ""},
+		{name: "UNC server with empty share", path: `\\nas\`, expected: ""},
+		{name: "relative path", path: "zvuk downloads", expected: ""},
+		{name: "Unix absolute path", path: "/home/user/Music", expected: ""},
+		{name: "digit is not a drive", path: `1:\Music`, expected: ""},
+		{name: "empty path", path: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, WindowsVolumeName(tt.path))
+		})
+	}
+}
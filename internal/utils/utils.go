@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"bufio"
 	"iter"
 	"math"
 	"math/rand/v2"
@@ -177,42 +176,6 @@ func IsFileExist(path string) (bool, error) {
 	return false, err
 }
 
-// ReadUniqueLinesFromFile reads a text file and returns a slice of unique non-empty lines.
-// It skips empty lines and ensures that each line in the returned slice is unique.
-func ReadUniqueLinesFromFile(path string) ([]string, error) {
-	file, err := os.Open(filepath.Clean(path))
-	if err != nil {
-		return nil, err
-	}
-
-	defer file.Close()
-
-	var (
-		uniqueLines = make(map[string]struct{})
-		lines       []string
-		scanner     = bufio.NewScanner(file)
-	)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		if _, exists := uniqueLines[line]; !exists {
-			uniqueLines[line] = struct{}{}
-
-			lines = append(lines, line)
-		}
-	}
-
-	if err = scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return lines, nil
-}
-
 // ExtractNamedGroup extracts the value of a named capturing group from a regex match.
 // It returns an empty string if the group is not found or if there is no match.
 func ExtractNamedGroup(re *regexp.Regexp, groupName, input string) string {
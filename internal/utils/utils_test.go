@@ -207,34 +207,6 @@ func TestIsFileExist(t *testing.T) {
 	assert.False(t, exists)
 }
 
-// TestReadUniqueLinesFromFile tests the ReadUniqueLinesFromFile function.
-func TestReadUniqueLinesFromFile(t *testing.T) {
-	t.Parallel()
-
-	// Create a temporary file with test content.
-	tempFile, err := os.CreateTemp(t.TempDir(), "test_lines")
-	require.NoError(t, err)
-
-	defer os.Remove(tempFile.Name()) //nolint:errcheck // Test cleanup, error is not critical.
-
-	content := "line1\nline2\nline1\nline3\nline2\n"
-	_, err = tempFile.WriteString(content)
-	require.NoError(t, err)
-	tempFile.Close() //nolint:errcheck,gosec // Test cleanup, error is not critical.
-
-	// Test reading unique lines.
-	lines, err := ReadUniqueLinesFromFile(tempFile.Name())
-	require.NoError(t, err)
-	assert.Len(t, lines, 3)
-	assert.Contains(t, lines, "line1")
-	assert.Contains(t, lines, "line2")
-	assert.Contains(t, lines, "line3")
-
-	// Test non-existing file.
-	_, err = ReadUniqueLinesFromFile("/non/existing/file")
-	require.Error(t, err)
-}
-
 // TestExtractNamedGroup tests the ExtractNamedGroup function.
 func TestExtractNamedGroup(t *testing.T) {
 	t.Parallel()